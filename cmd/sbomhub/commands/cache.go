@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/internal/cache"
+	"github.com/youichi-uda/sbomhub-cli/internal/scanner"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// Local result cache wiring. internal/cache owns the storage; this file
+// decides what goes into each key and reports hits under --verbose. The
+// cache is best-effort throughout: a cache that cannot be opened, read
+// or written degrades to the uncached path with a [DEBUG] line, never to
+// a command failure.
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "ローカルキャッシュの管理",
+	Long: `SBOM 生成結果と check 結果のローカルキャッシュを管理します。
+
+キャッシュの場所: <ユーザーキャッシュディレクトリ>/sbomhub
+(環境変数 SBOMHUB_CACHE_DIR で変更可)。 --no-cache で参照・ 保存を無効化します。
+
+使用例:
+  sbomhub cache prune                  # 24時間より古いエントリを削除
+  sbomhub cache prune --older-than 1h
+  sbomhub cache prune --all            # すべて削除`,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "古いキャッシュエントリを削除",
+	Args:  cobra.NoArgs,
+	RunE:  runCachePrune,
+}
+
+var (
+	cachePruneOlderThan time.Duration
+	cachePruneAll       bool
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cachePruneCmd.Flags().DurationVar(&cachePruneOlderThan, "older-than", cache.DefaultSBOMTTL, "この期間より古いエントリを削除")
+	cachePruneCmd.Flags().BoolVar(&cachePruneAll, "all", false, "期間に関係なくすべてのエントリを削除")
+}
+
+// cachePruneResult is the `cache prune --json` payload.
+type cachePruneResult struct {
+	Dir     string `json:"dir"`
+	Removed int    `json:"removed"`
+	Bytes   int64  `json:"bytes"`
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	out := GetOutputConfig()
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return fmt.Errorf("キャッシュディレクトリを特定できません: %w", err)
+	}
+	olderThan := cachePruneOlderThan
+	if cachePruneAll {
+		olderThan = 0
+	}
+	stats, err := cache.New(dir).Prune(olderThan)
+	if err != nil {
+		return fmt.Errorf("キャッシュの削除に失敗しました: %w", err)
+	}
+	res := cachePruneResult{Dir: dir, Removed: stats.Removed, Bytes: stats.Bytes}
+	return out.PrintResult(res, func() {
+		printSuccess("%d 件のキャッシュエントリを削除しました (%s, %d bytes)", stats.Removed, dir, stats.Bytes)
+	})
+}
+
+// openCache returns the cache for this run, or nil when --no-cache is set
+// or no cache directory can be determined.
+func openCache() *cache.Cache {
+	out := GetOutputConfig()
+	if noCacheFlag {
+		out.PrintVerbose("キャッシュ無効 (--no-cache)")
+		return nil
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		out.PrintVerbose("キャッシュを使用しません: %v", err)
+		return nil
+	}
+	return cache.New(dir)
+}
+
+// sbomFile is a generated SBOM on disk. Scanners write straight to a
+// file and scan streams it to the server, so a container SBOM of
+// hundreds of MB is never held in memory (only `check` reads it in, to
+// extract the component list). Close removes the file when this run
+// created it; a cache entry is left alone.
+type sbomFile struct {
+	path string
+	size int64
+	temp bool
+}
+
+func (f *sbomFile) Close() error {
+	if f == nil || !f.temp {
+		return nil
+	}
+	return os.Remove(f.path)
+}
+
+// runScanner runs s into a fresh temp file.
+func runScanner(ctx context.Context, s scanner.Scanner, absPath, format string) (*sbomFile, error) {
+	tmp, err := os.CreateTemp("", "sbomhub-sbom-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("一時ファイル作成エラー: %w", err)
+	}
+	tmp.Close()
+	f := &sbomFile{path: tmp.Name(), temp: true}
+	if err := s.Scan(ctx, absPath, format, f.path); err != nil {
+		f.Close()
+		return nil, err
+	}
+	st, err := os.Stat(f.path)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("SBOM読み込みエラー: %w", err)
+	}
+	f.size = st.Size()
+	return f, nil
+}
+
+// generateSBOM runs s against absPath, reusing a cached SBOM when the
+// manifest / lockfile inputs, tool, tool version, format and path are
+// unchanged. Trees without a recognised manifest are never cached (see
+// cache.InputsHash). The caller must Close the returned file.
+func generateSBOM(ctx context.Context, c *cache.Cache, s scanner.Scanner, absPath, format string) (*sbomFile, error) {
+	if c == nil {
+		return runScanner(ctx, s, absPath, format)
+	}
+	out := GetOutputConfig()
+	inputs, ok, err := cache.InputsHash(absPath)
+	if err != nil || !ok {
+		out.PrintVerbose("SBOM キャッシュ対象外: マニフェスト / ロックファイルが見つかりません")
+		return runScanner(ctx, s, absPath, format)
+	}
+	toolVersion, err := s.Version()
+	if err != nil {
+		out.PrintVerbose("SBOM キャッシュを使用しません: %v", err)
+		return runScanner(ctx, s, absPath, format)
+	}
+	key := cache.Key(cache.KindSBOM, s.Name(), toolVersion, format, absPath, inputs)
+	if p, ok := c.GetFile(cache.KindSBOM, key, cache.DefaultSBOMTTL); ok {
+		if st, err := os.Stat(p); err == nil {
+			out.PrintVerbose("キャッシュヒット: SBOM (%s, key=%s)", s.Name(), key[:12])
+			return &sbomFile{path: p, size: st.Size()}, nil
+		}
+	}
+	f, err := runScanner(ctx, s, absPath, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.PutFile(cache.KindSBOM, key, f.path); err != nil {
+		out.PrintVerbose("SBOM キャッシュの保存に失敗しました: %v", err)
+	}
+	return f, nil
+}
+
+// checkVulnerabilities runs the server-side check, reusing a cached
+// result for the same SBOM bytes against the same server within ttl.
+// The cached value is the raw server result: suppression, VEX and policy
+// are always re-applied on top, so changing those never needs a cache
+// flush.
+func checkVulnerabilities(ctx context.Context, c *cache.Cache, client *sbomhub.Client, apiURL string, sbomData []byte, opts sbomhub.CheckOptions, ttl time.Duration) (*sbomhub.CheckResult, error) {
+	if c == nil {
+		return client.CheckVulnerabilitiesWithOptions(ctx, sbomData, opts)
+	}
+	out := GetOutputConfig()
+	sum := sha256.Sum256(sbomData)
+	key := cache.Key(cache.KindCheck, apiURL, hex.EncodeToString(sum[:]))
+	if data, ok := c.Get(cache.KindCheck, key, ttl); ok {
+		var cached sbomhub.CheckResult
+		if err := json.Unmarshal(data, &cached); err == nil {
+			out.PrintVerbose("キャッシュヒット: check 結果 (key=%s)", key[:12])
+			return &cached, nil
+		}
+	}
+	result, err := client.CheckVulnerabilitiesWithOptions(ctx, sbomData, opts)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(result); err == nil {
+		if err := c.Put(cache.KindCheck, key, data); err != nil {
+			out.PrintVerbose("check 結果キャッシュの保存に失敗しました: %v", err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/cache"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// Server capability negotiation for commands. internal/api/capabilities.go
+// fetches the document; this file caches it per API URL, compares the
+// server's min_cli_version with this build and maps features onto the
+// commands that need them, for `doctor` and `version`.
+//
+// Negotiation is advisory: if the document cannot be fetched (network,
+// 5xx, malformed body) commands behave exactly as they did before it
+// existed. Only a feature the server explicitly leaves out of a
+// published document changes what a command does.
+
+// capabilitiesTimeout bounds the capabilities fetch so a slow server
+// costs a command seconds, not the full request timeout, before it falls
+// back to un-negotiated behaviour.
+const capabilitiesTimeout = 5 * time.Second
+
+// commandFeatures maps commands (or modes of a command) onto the server
+// feature they depend on. doctor and version list the entries a server
+// declares it lacks.
+var commandFeatures = []struct {
+	command string
+	feature string
+}{
+	{"scan (SBOM アップロード)", sbomhub.FeatureSBOMUpload},
+	{"scan --wait-for-scan / --fail-on / --policy", sbomhub.FeatureScanStatus},
+	{"triage", sbomhub.FeatureTriage},
+	{"check / scan (VEX 判定)", sbomhub.FeatureVEX},
+	{"cra", sbomhub.FeatureCRA},
+	{"meti", sbomhub.FeatureMETI},
+	{"llm test (provider / model 表示)", sbomhub.FeatureLLMHealth},
+}
+
+// optInFeatures are the features whose client behaviour stays off until
+// the server declares them. doctor lists their state.
+var optInFeatures = []string{
+	sbomhub.FeatureIdempotencyKey,
+	sbomhub.FeatureCursorPagination,
+	sbomhub.FeatureWhoami,
+	sbomhub.FeatureTokenExchange,
+}
+
+// serverCapabilities returns the capabilities of the server at apiURL,
+// from the local cache when a fresh entry exists, and hands them to
+// client so it can use the opt-in features they declare. It returns nil
+// when the document could not be fetched; a nil *sbomhub.Capabilities
+// answers "not declared" to every Supports call, so callers need no nil
+// check.
+func serverCapabilities(ctx context.Context, client *sbomhub.Client, apiURL string) *sbomhub.Capabilities {
+	out := GetOutputConfig()
+	c := openCache()
+	key := cache.Key(cache.KindCapabilities, apiURL)
+	if c != nil {
+		if data, ok := c.Get(cache.KindCapabilities, key, cache.DefaultCapabilitiesTTL); ok {
+			var cached sbomhub.Capabilities
+			if err := json.Unmarshal(data, &cached); err == nil {
+				out.PrintVerbose("キャッシュヒット: サーバ capabilities (key=%s)", key[:12])
+				client.SetCapabilities(&cached)
+				return &cached
+			}
+		}
+	}
+
+	caps, err := fetchCapabilities(ctx, client)
+	if err != nil {
+		out.PrintVerbose("サーバ capabilities を取得できませんでした (未ネゴシエーションで続行): %v", err)
+		return nil
+	}
+	if !caps.Published {
+		out.PrintVerbose("サーバは capabilities を公開していません (旧バージョン)")
+	}
+	if c != nil {
+		if data, err := json.Marshal(caps); err == nil {
+			if err := c.Put(cache.KindCapabilities, key, data); err != nil {
+				out.PrintVerbose("capabilities キャッシュの保存に失敗しました: %v", err)
+			}
+		}
+	}
+	client.SetCapabilities(caps)
+	return caps
+}
+
+// featureUnsupported returns the "server too old" error for what when caps
+// declares the server lacks feature. A feature that is merely undeclared
+// (no published document, fetch failure) returns nil: the command then
+// finds out from the API itself, as before negotiation existed.
+func featureUnsupported(caps *sbomhub.Capabilities, feature, what string) error {
+	if !caps.Lacks(feature) {
+		return nil
+	}
+	return &exitError{
+		code: exitPermanent,
+		msg:  fmt.Sprintf("サーバ (version %s) は %s (%s) に対応していません。 サーバを更新してください", orNA(caps.Version), what, feature),
+	}
+}
+
+// requireFeature is featureUnsupported for a command that has not
+// fetched the capabilities itself. Commands call it before their first
+// API call on the feature, so an old server is reported up front rather
+// than as a 404 halfway through.
+func requireFeature(ctx context.Context, client *sbomhub.Client, feature, what string) error {
+	return featureUnsupported(serverCapabilities(ctx, client, client.BaseURL()), feature, what)
+}
+
+// fetchCapabilities fetches the document under capabilitiesTimeout,
+// bypassing the cache (doctor and version always want the live answer).
+func fetchCapabilities(ctx context.Context, client *sbomhub.Client) (*sbomhub.Capabilities, error) {
+	ctx, cancel := context.WithTimeout(ctx, capabilitiesTimeout)
+	defer cancel()
+	return client.GetCapabilities(ctx)
+}
+
+// featureState renders caps' answer for feature as "supported",
+// "unsupported" or "unknown" (no published document).
+func featureState(caps *sbomhub.Capabilities, feature string) string {
+	supported, declared := caps.Supports(feature)
+	switch {
+	case !declared:
+		return "unknown"
+	case supported:
+		return "supported"
+	default:
+		return "unsupported"
+	}
+}
+
+// optInState renders the optInFeatures as "name=on|off" for doctor.
+func optInState(caps *sbomhub.Capabilities) string {
+	states := make([]string, 0, len(optInFeatures))
+	for _, f := range optInFeatures {
+		state := "off"
+		if caps.Declares(f) {
+			state = "on"
+		}
+		states = append(states, f+"="+state)
+	}
+	return strings.Join(states, ",")
+}
+
+// serverCompat is the CLI / server compatibility verdict shared by
+// doctor and version.
+type serverCompat struct {
+	// CLITooOld is set when the server's min_cli_version is newer than
+	// this build.
+	CLITooOld bool `json:"cli_too_old"`
+	// Unsupported lists the commandFeatures entries the server declares
+	// it lacks — the commands this server is too old for.
+	Unsupported []string `json:"unsupported_commands"`
+}
+
+// assessCompat compares caps with the running CLI version. Development
+// builds ("dev", or anything that is not a version) never count as too
+// old: there is nothing meaningful to compare.
+func assessCompat(caps *sbomhub.Capabilities, cliVersion string) serverCompat {
+	compat := serverCompat{Unsupported: []string{}}
+	if caps == nil || !caps.Published {
+		return compat
+	}
+	if caps.MinCLIVersion != "" {
+		if cmp, ok := compareVersions(cliVersion, caps.MinCLIVersion); ok && cmp < 0 {
+			compat.CLITooOld = true
+		}
+	}
+	for _, cf := range commandFeatures {
+		if caps.Lacks(cf.feature) {
+			compat.Unsupported = append(compat.Unsupported, cf.command)
+		}
+	}
+	return compat
+}
+
+// compareVersions compares two "vMAJOR.MINOR.PATCH" versions, ignoring
+// any pre-release or build suffix. ok is false when either side does not
+// parse.
+func compareVersions(a, b string) (cmp int, ok bool) {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, true
+		case pa[i] > pb[i]:
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if v == "" || len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
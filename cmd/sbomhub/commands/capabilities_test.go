@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/cache"
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+		ok   bool
+	}{
+		{"1.2.3", "1.2.3", 0, true},
+		{"v1.2.3", "1.10.0", -1, true},
+		{"2.0", "1.9.9", 1, true},
+		{"1.2.3-rc1", "1.2.3", 0, true},
+		{"dev", "1.0.0", 0, false},
+		{"1.0.0", "", 0, false},
+	}
+	for _, c := range cases {
+		got, ok := compareVersions(c.a, c.b)
+		if got != c.want || ok != c.ok {
+			t.Errorf("compareVersions(%q, %q) = %d, %v; want %d, %v", c.a, c.b, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestAssessCompat(t *testing.T) {
+	caps := &sbomhub.Capabilities{
+		Published:     true,
+		MinCLIVersion: "1.5.0",
+		Features:      []string{sbomhub.FeatureSBOMUpload, sbomhub.FeatureScanStatus, sbomhub.FeatureTriage, sbomhub.FeatureVEX, sbomhub.FeatureCRA, sbomhub.FeatureLLMHealth},
+	}
+	got := assessCompat(caps, "1.4.2")
+	if !got.CLITooOld {
+		t.Error("CLI 1.4.2 against min_cli_version 1.5.0 should be too old")
+	}
+	if len(got.Unsupported) != 1 || got.Unsupported[0] != "meti" {
+		t.Errorf("Unsupported = %q, want only meti", got.Unsupported)
+	}
+	if assessCompat(caps, "dev").CLITooOld {
+		t.Error("a dev build must never be reported as too old")
+	}
+	if got := assessCompat(&sbomhub.Capabilities{}, "1.0.0"); got.CLITooOld || len(got.Unsupported) != 0 {
+		t.Errorf("unpublished capabilities = %+v, want no verdict", got)
+	}
+}
+
+func TestServerCapabilities_CachedPerServer(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte(`{"version":"1.0.0","features":["sbom_upload"]}`))
+	}))
+	defer server.Close()
+	t.Setenv("SBOMHUB_CACHE_DIR", t.TempDir())
+	saved := noCacheFlag
+	t.Cleanup(func() { noCacheFlag = saved })
+	noCacheFlag = false
+
+	client := sbomhub.NewClient(server.URL, "k")
+	for i := 0; i < 2; i++ {
+		caps := serverCapabilities(context.Background(), client, server.URL)
+		if !caps.Published || caps.Version != "1.0.0" {
+			t.Fatalf("call %d: caps = %+v", i, caps)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("server hit %d times, want the second call served from cache", hits)
+	}
+
+	noCacheFlag = true
+	serverCapabilities(context.Background(), client, server.URL)
+	if hits != 2 {
+		t.Errorf("--no-cache should bypass the cache (hits=%d)", hits)
+	}
+}
+
+func TestServerCapabilities_FetchErrorIsAdvisory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	saved := noCacheFlag
+	t.Cleanup(func() { noCacheFlag = saved })
+	noCacheFlag = true
+
+	client := sbomhub.NewClient(server.URL, "k", sbomhub.WithRetryPolicy(sbomhub.NoRetry))
+	caps := serverCapabilities(context.Background(), client, server.URL)
+	if caps != nil {
+		t.Fatalf("caps = %+v, want nil on a fetch error", caps)
+	}
+	if caps.Lacks(sbomhub.FeatureScanStatus) {
+		t.Error("a failed fetch must not gate anything")
+	}
+}
+
+// capabilitiesServer answers /api/v1/capabilities with doc and records
+// every other request.
+func capabilitiesServer(t *testing.T, doc string, other *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/capabilities" {
+			_, _ = w.Write([]byte(doc))
+			return
+		}
+		*other = append(*other, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"01234567-0123-0123-0123-0123456789ab"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunScan_GateRefusedWhenServerLacksScanStatus(t *testing.T) {
+	fakeSyft(t, `{"bomFormat":"CycloneDX","components":[{"name":"a"}]}`)
+	var requests []string
+	server := capabilitiesServer(t, `{"version":"0.8.0","features":["sbom_upload"]}`, &requests)
+	setupStreamingScan(t, server.URL)
+	savedFailOn := scanFailOn
+	t.Cleanup(func() { scanFailOn = savedFailOn })
+	scanWaitForScan, scanFailOn = true, "high"
+
+	err := runScan(scanCmd, []string{t.TempDir()})
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitPermanent {
+		t.Fatalf("runScan() = %v, want exit %d", err, exitPermanent)
+	}
+	if !strings.Contains(err.Error(), "scan-status") {
+		t.Errorf("error %q should name the missing API", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("requests after capabilities = %q, want none (refuse before upload)", requests)
+	}
+}
+
+func TestRunScan_SkipsWaitAndGzipWhenUndeclared(t *testing.T) {
+	fakeSyft(t, `{"bomFormat":"CycloneDX","components":[{"name":"a"}]}`)
+	var requests []string
+	var encoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/capabilities" {
+			_, _ = w.Write([]byte(`{"version":"0.8.0","features":["sbom_upload"]}`))
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		encoding = r.Header.Get("Content-Encoding")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"01234567-0123-0123-0123-0123456789ab"}`))
+	}))
+	defer server.Close()
+	setupStreamingScan(t, server.URL)
+	var stderr bytes.Buffer
+	globalOutput.ErrWriter = &stderr
+	scanWaitForScan = true
+
+	if err := runScan(scanCmd, []string{t.TempDir()}); err != nil {
+		t.Fatalf("runScan() = %v", err)
+	}
+	if len(requests) != 1 || !strings.HasSuffix(requests[0], "/sbom") {
+		t.Errorf("requests = %q, want only the upload (no scan-status polling)", requests)
+	}
+	if encoding != "" {
+		t.Errorf("Content-Encoding = %q, want identity for a server without gzip_request_encoding", encoding)
+	}
+	if !strings.Contains(stderr.String(), "scan-status API に対応していない") {
+		t.Errorf("stderr should explain the skipped wait:\n%s", stderr.String())
+	}
+}
+
+// TestProjectCommands_RefusedWhenServerLacksFeature verifies that
+// triage, cra and meti stop with the "server too old" error before any
+// project-scoped request when the server declares it lacks their API.
+func TestProjectCommands_RefusedWhenServerLacksFeature(t *testing.T) {
+	const projectID = "01234567-0123-0123-0123-0123456789ab"
+	var requests []string
+	server := capabilitiesServer(t, `{"version":"0.8.0","features":["sbom_upload","scan_status"]}`, &requests)
+	t.Setenv(cache.DirEnv, t.TempDir())
+	for _, args := range [][]string{
+		{"triage", "--project", projectID, "--non-interactive"},
+		{"cra", "list", "--project", projectID},
+		{"meti", "list", "--project", projectID},
+	} {
+		requests = nil
+		err := runForExitCode(t, server.URL, args)
+		if ExitCode(err) != exitPermanent || err == nil || !strings.Contains(err.Error(), "対応していません") {
+			t.Errorf("%v: error = %v, want the server-too-old error (exit %d)", args, err, exitPermanent)
+		}
+		for _, r := range requests {
+			if strings.Contains(r, "/projects/") {
+				t.Errorf("%v: sent %s after the server declared the feature missing", args, r)
+			}
+		}
+	}
+}
+
+// TestLoadVEXIndex_RefusedWhenServerLacksVEX verifies the VEX lookup of
+// check / scan reports a server without the VEX API without the request,
+// so the caller's fail-closed warning names the real cause.
+func TestLoadVEXIndex_RefusedWhenServerLacksVEX(t *testing.T) {
+	var requests []string
+	server := capabilitiesServer(t, `{"version":"0.8.0","features":["sbom_upload"]}`, &requests)
+	client := sbomhub.NewClient(server.URL, "k")
+	caps, err := client.GetCapabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadVEXIndex(context.Background(), client, caps, "p1"); err == nil || !strings.Contains(err.Error(), sbomhub.FeatureVEX) {
+		t.Errorf("loadVEXIndex() = %v, want the server-too-old error naming %s", err, sbomhub.FeatureVEX)
+	}
+	if len(requests) != 0 {
+		t.Errorf("requests = %v, want none", requests)
+	}
+}
+
+func TestVersion_ReportsServerCompatibility(t *testing.T) {
+	var requests []string
+	server := capabilitiesServer(t, `{"version":"2.0.0","min_cli_version":"9.0.0","features":["sbom_upload","scan_status"]}`, &requests)
+	withCleanCredentialEnv(t)
+	apiURL = server.URL
+	savedVersion, savedOut := version, *globalOutput
+	t.Cleanup(func() { version, *globalOutput = savedVersion, savedOut })
+	version = "1.0.0"
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.ErrWriter, globalOutput.JSON = &stdout, io.Discard, true
+
+	if err := runVersion(versionCmd, nil); err != nil {
+		t.Fatalf("runVersion() = %v", err)
+	}
+	var got struct {
+		Version string `json:"version"`
+		Server  struct {
+			Version     string   `json:"version"`
+			CLITooOld   bool     `json:"cli_too_old"`
+			Unsupported []string `json:"unsupported_commands"`
+		} `json:"server"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("version --json is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.Version != "1.0.0" || got.Server.Version != "2.0.0" || !got.Server.CLITooOld {
+		t.Errorf("version --json = %+v", got)
+	}
+	if !strings.Contains(strings.Join(got.Server.Unsupported, ","), "triage") {
+		t.Errorf("unsupported_commands = %q, want triage listed", got.Server.Unsupported)
+	}
+
+	stdout.Reset()
+	globalOutput.JSON = false
+	apiURL = ""
+	if err := runVersion(versionCmd, nil); err != nil {
+		t.Fatalf("runVersion() without a server = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "server: 未設定") {
+		t.Errorf("version without an API URL should say so:\n%s", stdout.String())
+	}
+}
+
+func TestDoctorCompatCheck(t *testing.T) {
+	var requests []string
+	lacking := capabilitiesServer(t, `{"version":"1.0.0","features":["sbom_upload","scan_status","triage","vex","cra","llm_health_metadata"]}`, &requests)
+	if r := doctorCompatCheck(&config.Config{APIURL: lacking.URL}); r.status != doctorWarn || !strings.Contains(r.message, "meti") {
+		t.Errorf("server without meti = %s %s, want [WARN] naming meti", r.status, r.message)
+	}
+
+	old := httptest.NewServer(http.NotFoundHandler())
+	defer old.Close()
+	if r := doctorCompatCheck(&config.Config{APIURL: old.URL}); r.status != doctorOK {
+		t.Errorf("server without the document = %s %s, want [OK]", r.status, r.message)
+	}
+}
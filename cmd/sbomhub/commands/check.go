@@ -1,14 +1,19 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/internal/cache"
 	"github.com/youichi-uda/sbomhub-cli/internal/scanner"
+	"github.com/youichi-uda/sbomhub-cli/internal/severity"
+	"github.com/youichi-uda/sbomhub-cli/internal/suppress"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 var checkCmd = &cobra.Command{
@@ -19,16 +24,102 @@ var checkCmd = &cobra.Command{
 
 使用例:
   sbomhub check .                # カレントディレクトリ
-  sbomhub check ./sbom.json      # 既存のSBOMファイル`,
+  sbomhub check ./sbom.json      # 既存のSBOMファイル
+  sbomhub check . --fail-on high # high 以上があれば exit 1
+  sbomhub check . --policy ./security/policy.yaml
+  sbomhub check . --fail-on high --project <project-id>  # 承認済み VEX を反映
+
+大規模なSBOM (コンテナイメージ等) はコンポーネントを --chunk-size 件ずつに
+分割し、 最大 --concurrency 並列で送信します。 一時的な失敗 (429 / 5xx)
+はチャンク単位でリトライされます。
+
+カレントディレクトリまたは対象ディレクトリに .sbomhubignore があれば、
+期限内のエントリに一致する脆弱性を --fail-on の評価前に除外します。
+
+--project を指定すると、 そのプロジェクトで承認済みの VEX 判定
+(not_affected / resolved) に一致する脆弱性も閾値評価から除外し、
+誰が承認したかを表示します (--vex=false で無効化)。
+
+生成した SBOM (マニフェスト / ロックファイルとツールのバージョンが同じ場合)
+と check 結果 (同じ SBOM と API URL、 --cache-ttl 以内) はローカルに
+キャッシュされます。 --no-cache で無効化、 sbomhub cache prune で削除できます。`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runCheck,
 }
 
+var (
+	checkChunkSize   int
+	checkConcurrency int
+	checkFailOn      string
+	checkIgnoreFile  string
+	checkPolicyFile  string
+	checkProject     string
+	checkVEX         bool
+	checkCacheTTL    time.Duration
+)
+
 func init() {
 	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().IntVar(&checkChunkSize, "chunk-size", sbomhub.DefaultCheckChunkSize, "1リクエストあたりのコンポーネント数")
+	checkCmd.Flags().IntVar(&checkConcurrency, "concurrency", sbomhub.DefaultCheckConcurrency, "同時送信するチャンク数の上限")
+	checkCmd.Flags().DurationVar(&checkCacheTTL, "cache-ttl", cache.DefaultCheckTTL, "check 結果キャッシュの有効期間 (--no-cache で無効化)")
+	checkCmd.Flags().StringVar(&checkFailOn, "fail-on", "", "指定した重大度以上の脆弱性で exit 1 (critical/high/medium/low)")
+	checkCmd.Flags().StringVar(&checkPolicyFile, "policy", "", "fail/warn ルールを記述したポリシーファイル (YAML)")
+	checkCmd.Flags().StringVar(&checkIgnoreFile, "ignore-file", "", "抑制ファイルのパス (デフォルト: カレント / 対象ディレクトリの .sbomhubignore)")
+	checkCmd.Flags().StringVarP(&checkProject, "project", "p", "", "承認済み VEX 判定を参照するプロジェクト ID (UUID) または名前")
+	checkCmd.Flags().BoolVar(&checkVEX, "vex", true, "--project の承認済み VEX 判定 (not_affected/resolved) を閾値評価から除外する")
+}
+
+// checkJSONResult is the `sbomhub check --json` payload. It reuses the
+// scan summary / fail_on shapes so CI wrappers can parse both commands
+// with the same jq paths. KEV stays zero: the check endpoint does not
+// report KEV membership.
+type checkJSONResult struct {
+	ComponentCount       int                         `json:"component_count"`
+	VulnerabilitySummary scanJSONVulnSummary         `json:"vulnerability_summary"`
+	Vulnerabilities      []sbomhub.VulnerabilityItem `json:"vulnerabilities"`
+	FailOn               scanJSONFailOn              `json:"fail_on"`
+	Suppressions         *suppressionJSON            `json:"suppressions,omitempty"`
+	VEX                  *vexJSON                    `json:"vex,omitempty"`
+	Policy               *policyJSON                 `json:"policy,omitempty"`
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
+	if checkChunkSize <= 0 {
+		return fmt.Errorf("--chunk-size は1以上を指定してください (指定値: %d)", checkChunkSize)
+	}
+	if checkConcurrency <= 0 {
+		return fmt.Errorf("--concurrency は1以上を指定してください (指定値: %d)", checkConcurrency)
+	}
+	if err := applySettingDefaults(cmd, map[string]string{"fail-on": "fail_on"}); err != nil {
+		return err
+	}
+	failOnLevel := severity.LevelNone
+	if checkFailOn != "" {
+		failOnLevel = severity.Parse(checkFailOn)
+		if failOnLevel == severity.LevelNone {
+			return fmt.Errorf("--fail-on の値が不正です: %q (有効値: critical/high/medium/low)", checkFailOn)
+		}
+	}
+	policy, err := loadPolicy(checkPolicyFile)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// 人間向けの進捗表示。 --json では stderr に逃がして stdout を JSON
+	// 専用にする (scan と同じ方針)。
+	out := GetOutputConfig()
+	checkPrintf := func(format string, a ...interface{}) {
+		if out.Quiet {
+			return
+		}
+		fmt.Fprintf(out.humanWriter(), format, a...)
+	}
+
 	// チェック対象パスの決定
 	checkPath := "."
 	if len(args) > 0 {
@@ -46,24 +137,37 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("パスが存在しません: %s", absPath)
 	}
 
+	supFile, err := loadSuppressions(checkIgnoreFile, suppressionSearchDirs(absPath)...)
+	if err != nil {
+		return err
+	}
+
 	var sbomData []byte
+	resultCache := openCache()
 
 	// ファイルかディレクトリかで処理を分岐
 	if info.IsDir() {
-		fmt.Printf("📦 スキャン中: %s\n", absPath)
-		
+		checkPrintf("📦 スキャン中: %s\n", absPath)
+
 		s, err := scanner.New("")
 		if err != nil {
 			return fmt.Errorf("スキャナーの初期化に失敗しました: %w", err)
 		}
 
-		sbomData, err = s.Scan(absPath, "cyclonedx")
+		f, err := generateSBOM(ctx, resultCache, s, absPath, "cyclonedx")
 		if err != nil {
 			return fmt.Errorf("スキャンに失敗しました: %w", err)
 		}
+		// check sends the component list, not the SBOM, so the file is
+		// read into memory here; only scan needs to stream it.
+		sbomData, err = os.ReadFile(f.path)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("SBOM読み込みエラー: %w", err)
+		}
 	} else {
 		// SBOMファイルを読み込み
-		fmt.Printf("📄 SBOMファイル読み込み: %s\n", absPath)
+		checkPrintf("📄 SBOMファイル読み込み: %s\n", absPath)
 		sbomData, err = os.ReadFile(absPath)
 		if err != nil {
 			return fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
@@ -72,8 +176,7 @@ func runCheck(cmd *cobra.Command, args []string) error {
 
 	// コンポーネント数を表示
 	componentCount := countComponentsCheck(sbomData)
-	fmt.Printf("📋 コンポーネント数: %d\n", componentCount)
-	fmt.Println()
+	checkPrintf("📋 コンポーネント数: %d\n\n", componentCount)
 
 	// 設定の解決: config file → env → CLI flag の precedence で merge する
 	// (Codex R9 fix)。 R2-2e で scan に導入した resolveCredentials を check
@@ -84,7 +187,7 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
 	}
-	if cfg.APIKey == "" {
+	if !hasCredentials(cfg) {
 		return fmt.Errorf("API Keyが設定されていません。 'sbomhub login' で対話設定するか、 --api-key フラグ・ 環境変数 SBOMHUB_API_KEY を指定してください")
 	}
 	if cfg.APIURL == "" {
@@ -92,39 +195,180 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	}
 
 	// API クライアントの作成
-	client := api.NewClient(cfg.APIURL, cfg.APIKey)
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("🔍 脆弱性チェック中...")
-	fmt.Println()
+	checkPrintf("🔍 脆弱性チェック中...\n\n")
 
-	// チェック
-	result, err := client.CheckVulnerabilities(sbomData)
+	// チェック。 進捗はチャンクが複数ある場合のみ表示する (単一チャンクの
+	// 小さな SBOM で "1/1" を出してもノイズになるだけ)。
+	progressShown := false
+	opts := sbomhub.CheckOptions{
+		ChunkSize:   checkChunkSize,
+		Concurrency: checkConcurrency,
+		Progress: func(done, total int) {
+			if total <= 1 || out.Quiet || out.JSON {
+				return
+			}
+			progressShown = true
+			fmt.Fprintf(out.humanWriter(), "\r   進捗: %d/%d チャンク", done, total)
+		},
+	}
+	result, err := checkVulnerabilities(ctx, resultCache, client, cfg.APIURL, sbomData, opts, checkCacheTTL)
+	if progressShown {
+		fmt.Fprintln(out.humanWriter())
+	}
 	if err != nil {
 		return fmt.Errorf("脆弱性チェックに失敗しました: %w", err)
 	}
 
+	counts := severity.Counts{
+		Critical: result.Critical,
+		High:     result.High,
+		Medium:   result.Medium,
+		Low:      result.Low,
+		Unknown:  result.Unknown,
+	}
+	vulns := result.Vulnerabilities
+
+	comps, _ := sbomhub.ExtractComponents(sbomData)
+	findings := findingsFromCheckResult(result, comps)
+
+	// --project 指定時は承認済み VEX 判定 (not_affected / resolved) を
+	// .sbomhubignore より先に適用する。 取得に失敗した場合は警告のみで、
+	// 除外せずに閾値評価する (fail closed)。
+	var (
+		vexIdx   *vexIndex
+		vexHits  []vexSuppressed
+		excluded []suppress.Finding
+	)
+	if checkProject != "" && checkVEX {
+		projectID, err := resolveProject(ctx, client, checkProject)
+		var idx *vexIndex
+		if err == nil {
+			idx, err = loadVEXIndex(ctx, client, serverCapabilities(ctx, client, client.BaseURL()), projectID)
+		}
+		if err != nil {
+			fmt.Fprintf(out.ErrWriter, "⚠️  VEX 判定を取得できませんでした。 除外せずに評価します: %v\n", err)
+		} else {
+			vexIdx = idx
+			findings, vexHits = applyVEX(findings, vexIdx)
+			excluded = append(excluded, vexFindings(vexHits)...)
+			printVEXReport(out.ErrWriter, vexIdx, vexHits)
+		}
+	}
+
+	// .sbomhubignore の適用。 check の結果はコンポーネント単位なので
+	// purl/version 付きのエントリも評価できる。
+	var supResult *suppress.Result
+	if supFile != nil {
+		res := supFile.Apply(findings, time.Now(), true)
+		supResult = &res
+		excluded = append(excluded, suppressedFindings(supResult)...)
+		printSuppressionReport(out.ErrWriter, supFile, supResult)
+	}
+	if len(excluded) > 0 {
+		counts = subtractSuppressed(counts, excluded)
+		vulns = keptVulnerabilities(result.Vulnerabilities, excluded)
+	}
+	var policyOutcome *severity.Outcome
+	if policy != nil {
+		o := policy.Evaluate(policyFindingsFromCheckResult(result, comps, suppressedKeys(excluded)))
+		policyOutcome = &o
+		printPolicyWarnings(out.ErrWriter, policyOutcome)
+	}
+
+	var (
+		exitErr         error
+		failOnTriggered bool
+		exitCode        = exitSuccess
+	)
+	if severity.ShouldFail(counts, failOnLevel) {
+		failOnTriggered = true
+		exitCode = exitThresholdExceeded
+		exitErr = &exitError{
+			code: exitThresholdExceeded,
+			msg:  fmt.Sprintf("--fail-on %s: 指定された重大度以上の脆弱性が検出されました (critical=%d high=%d medium=%d low=%d unknown=%d)", checkFailOn, counts.Critical, counts.High, counts.Medium, counts.Low, counts.Unknown),
+		}
+	} else if policyOutcome != nil && policyOutcome.HasFailure() {
+		failOnTriggered = true
+		exitCode = policyOutcome.ExitCode
+		exitErr = &exitError{code: policyOutcome.ExitCode, msg: policyFailureMessage(policyOutcome)}
+	} else if (failOnLevel != severity.LevelNone || policy != nil) && supResult != nil && supFile.FailOnExpired(*supResult) {
+		failOnTriggered = true
+		exitCode = exitThresholdExceeded
+		exitErr = &exitError{code: exitThresholdExceeded, msg: expiredSuppressionMessage(supResult)}
+	}
+
+	if out.JSON {
+		res := checkJSONResult{
+			ComponentCount: componentCount,
+			VulnerabilitySummary: scanJSONVulnSummary{
+				Critical: counts.Critical,
+				High:     counts.High,
+				Medium:   counts.Medium,
+				Low:      counts.Low,
+				Unknown:  counts.Unknown,
+				Total:    counts.Critical + counts.High + counts.Medium + counts.Low + counts.Unknown,
+			},
+			Vulnerabilities: vulns,
+			FailOn:          scanJSONFailOn{Triggered: failOnTriggered, ExitCode: exitCode},
+			Suppressions:    buildSuppressionJSON(supFile, supResult),
+			VEX:             buildVEXJSON(vexIdx, vexHits),
+			Policy:          buildPolicyJSON(policy, policyOutcome),
+		}
+		if res.Vulnerabilities == nil {
+			res.Vulnerabilities = []sbomhub.VulnerabilityItem{}
+		}
+		if checkFailOn != "" {
+			s := checkFailOn
+			res.FailOn.Threshold = &s
+		}
+		_ = out.PrintJSON(res)
+		return exitErr
+	}
+
 	// 結果表示
-	if result.Total == 0 {
+	total := counts.Critical + counts.High + counts.Medium + counts.Low + counts.Unknown
+	if total == 0 {
 		printSuccess("脆弱性は検出されませんでした！")
 	} else {
-		fmt.Printf("⚠️  %d件の脆弱性が検出されました\n", result.Total)
-		fmt.Println()
+		checkPrintf("⚠️  %d件の脆弱性が検出されました\n\n", total)
 
-		if result.Critical > 0 {
-			fmt.Printf("  🔴 Critical: %d\n", result.Critical)
+		if counts.Critical > 0 {
+			checkPrintf("  🔴 Critical: %d\n", counts.Critical)
 		}
-		if result.High > 0 {
-			fmt.Printf("  🟠 High: %d\n", result.High)
+		if counts.High > 0 {
+			checkPrintf("  🟠 High: %d\n", counts.High)
 		}
-		if result.Medium > 0 {
-			fmt.Printf("  🟡 Medium: %d\n", result.Medium)
+		if counts.Medium > 0 {
+			checkPrintf("  🟡 Medium: %d\n", counts.Medium)
 		}
-		if result.Low > 0 {
-			fmt.Printf("  🟢 Low: %d\n", result.Low)
+		if counts.Low > 0 {
+			checkPrintf("  🟢 Low: %d\n", counts.Low)
 		}
 	}
 
-	return nil
+	return exitErr
+}
+
+// keptVulnerabilities filters the excluded findings out of the check
+// vulnerability list, matching on (id, package, version).
+func keptVulnerabilities(all []sbomhub.VulnerabilityItem, excluded []suppress.Finding) []sbomhub.VulnerabilityItem {
+	if len(excluded) == 0 {
+		return all
+	}
+	drop := suppressedKeys(excluded)
+	kept := make([]sbomhub.VulnerabilityItem, 0, len(all))
+	for _, v := range all {
+		if drop[findingKey(v.ID, v.Package, v.Version)] {
+			continue
+		}
+		kept = append(kept, v)
+	}
+	return kept
 }
 
 func countComponentsCheck(sbomData []byte) int {
@@ -1,13 +1,18 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/cache"
 )
 
 // TestRunCheck_HonorsAPIURLFromEnv verifies the Codex R9 fix for the
@@ -56,6 +61,7 @@ func TestRunCheck_HonorsAPIURLFromEnv(t *testing.T) {
 
 	t.Setenv("SBOMHUB_API_URL", server.URL)
 	t.Setenv("SBOMHUB_API_KEY", "sbh_env_check")
+	t.Setenv(cache.DirEnv, t.TempDir())
 
 	if err := runCheck(checkCmd, []string{sbomPath}); err != nil {
 		t.Fatalf("runCheck() error = %v; expected env-only credentials to satisfy the command", err)
@@ -96,3 +102,301 @@ func TestRunCheck_MissingCredentialsErrorMentionsAllSources(t *testing.T) {
 		}
 	}
 }
+
+// withCheckFlags sets the check-specific flag globals for one test and
+// restores them afterwards, along with the shared output config.
+func withCheckFlags(t *testing.T, failOn, ignoreFile string, jsonOut bool) *bytes.Buffer {
+	t.Helper()
+	saveFailOn, saveIgnore, savePolicy := checkFailOn, checkIgnoreFile, checkPolicyFile
+	saveProject, saveVEX := checkProject, checkVEX
+	saveJSON, saveWriter, saveErr := globalOutput.JSON, globalOutput.Writer, globalOutput.ErrWriter
+	t.Cleanup(func() {
+		checkFailOn, checkIgnoreFile, checkPolicyFile = saveFailOn, saveIgnore, savePolicy
+		checkProject, checkVEX = saveProject, saveVEX
+		globalOutput.JSON, globalOutput.Writer, globalOutput.ErrWriter = saveJSON, saveWriter, saveErr
+	})
+	checkFailOn, checkIgnoreFile = failOn, ignoreFile
+	// Keep check results out of the developer's real cache directory; each
+	// test starts cold.
+	t.Setenv(cache.DirEnv, t.TempDir())
+	var stdout bytes.Buffer
+	globalOutput.JSON = jsonOut
+	globalOutput.Writer = &stdout
+	globalOutput.ErrWriter = io.Discard
+	return &stdout
+}
+
+// newCheckFixtureServer returns a check endpoint reporting one HIGH
+// lodash finding and one LOW minimist finding.
+func newCheckFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"total_components":      2,
+			"total_vulnerabilities": 2,
+			"by_severity":           map[string]int{"HIGH": 1, "LOW": 1},
+			"vulnerabilities": []map[string]interface{}{
+				{"id": "CVE-2021-23337", "package": "lodash", "version": "4.17.20", "severity": "HIGH"},
+				{"id": "CVE-2021-44906", "package": "minimist", "version": "1.2.5", "severity": "LOW"},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeCheckFixtures(t *testing.T, ignore string) (sbomPath, ignorePath string) {
+	t.Helper()
+	dir := t.TempDir()
+	sbomPath = filepath.Join(dir, "sbom.json")
+	sbom := `{"bomFormat":"CycloneDX","components":[
+		{"name":"lodash","version":"4.17.20","purl":"pkg:npm/lodash@4.17.20"},
+		{"name":"minimist","version":"1.2.5","purl":"pkg:npm/minimist@1.2.5"}]}`
+	if err := os.WriteFile(sbomPath, []byte(sbom), 0o644); err != nil {
+		t.Fatalf("write sbom: %v", err)
+	}
+	ignorePath = filepath.Join(dir, ".sbomhubignore")
+	if err := os.WriteFile(ignorePath, []byte(ignore), 0o644); err != nil {
+		t.Fatalf("write ignore file: %v", err)
+	}
+	return sbomPath, ignorePath
+}
+
+// TestRunCheck_SuppressionAppliedBeforeFailOn verifies that a live
+// `.sbomhubignore` entry removes the HIGH finding before --fail-on high
+// is evaluated, and that the suppressed finding is reported separately
+// in the JSON payload rather than silently dropped.
+func TestRunCheck_SuppressionAppliedBeforeFailOn(t *testing.T) {
+	withCleanCredentialEnv(t)
+	server := newCheckFixtureServer(t)
+	t.Setenv("SBOMHUB_API_URL", server.URL)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_test")
+
+	sbomPath, ignorePath := writeCheckFixtures(t, `
+suppressions:
+  - id: CVE-2021-23337
+    purl: pkg:npm/lodash
+    reason: template() is never called with user input
+    owner: platform-team
+    expires: 2999-12-31
+`)
+	stdout := withCheckFlags(t, "high", ignorePath, true)
+
+	if err := runCheck(checkCmd, []string{sbomPath}); err != nil {
+		t.Fatalf("runCheck() error = %v; suppressed HIGH must not trip --fail-on high", err)
+	}
+
+	var got checkJSONResult
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.VulnerabilitySummary.High != 0 || got.VulnerabilitySummary.Low != 1 {
+		t.Errorf("summary = %+v, want high=0 low=1 after suppression", got.VulnerabilitySummary)
+	}
+	if len(got.Vulnerabilities) != 1 || got.Vulnerabilities[0].ID != "CVE-2021-44906" {
+		t.Errorf("vulnerabilities = %+v, want only the unsuppressed LOW finding", got.Vulnerabilities)
+	}
+	if got.Suppressions == nil || len(got.Suppressions.Suppressed) != 1 {
+		t.Fatalf("suppressions = %+v, want one suppressed finding", got.Suppressions)
+	}
+	s := got.Suppressions.Suppressed[0]
+	if s.ID != "CVE-2021-23337" || s.Owner != "platform-team" || s.Purl != "pkg:npm/lodash@4.17.20" {
+		t.Errorf("suppressed[0] = %+v", s)
+	}
+	if got.FailOn.Triggered || got.FailOn.ExitCode != exitSuccess {
+		t.Errorf("fail_on = %+v, want not triggered / exit 0", got.FailOn)
+	}
+}
+
+// TestRunCheck_ExpiredSuppressionFailsWhenConfigured verifies that an
+// expired entry is not applied (the HIGH finding counts again) and that
+// `expired: fail` surfaces as a threshold-family failure even with a
+// threshold the remaining findings would not trip.
+func TestRunCheck_ExpiredSuppressionFailsWhenConfigured(t *testing.T) {
+	withCleanCredentialEnv(t)
+	server := newCheckFixtureServer(t)
+	t.Setenv("SBOMHUB_API_URL", server.URL)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_test")
+
+	sbomPath, ignorePath := writeCheckFixtures(t, `
+expired: fail
+suppressions:
+  - id: CVE-2021-23337
+    reason: waiting on upstream fix
+    owner: platform-team
+    expires: 2020-01-01
+`)
+	withCheckFlags(t, "critical", ignorePath, true)
+
+	err := runCheck(checkCmd, []string{sbomPath})
+	var se *exitError
+	if !errors.As(err, &se) || se.ExitCode() != exitThresholdExceeded {
+		t.Fatalf("err = %v, want exitError with exit %d", err, exitThresholdExceeded)
+	}
+	if !strings.Contains(err.Error(), "CVE-2021-23337") {
+		t.Errorf("error = %q, want the expired entry's ID", err.Error())
+	}
+}
+
+// TestRunCheck_InvalidSuppressionFileRejected verifies that a file with
+// a missing owner / expiry is rejected before any network call.
+func TestRunCheck_InvalidSuppressionFileRejected(t *testing.T) {
+	withCleanCredentialEnv(t)
+	sbomPath, ignorePath := writeCheckFixtures(t, `
+suppressions:
+  - id: CVE-2021-23337
+    reason: trust me
+`)
+	withCheckFlags(t, "", ignorePath, false)
+
+	err := runCheck(checkCmd, []string{sbomPath})
+	if err == nil {
+		t.Fatal("runCheck() = nil, want validation error")
+	}
+	for _, want := range []string{"owner", "expires"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want mention of %q", err.Error(), want)
+		}
+	}
+}
+
+// TestRunCheck_PolicyExitCode verifies that a matching fail rule sets the
+// process exit code from the rule's exit_code, that warn rules do not
+// fail the run, and that both are reported under `policy` in the JSON.
+// The fixture's HIGH finding has no fixed_in (no fix available) and its
+// component carries no explicit scope (CycloneDX default: required).
+func TestRunCheck_PolicyExitCode(t *testing.T) {
+	withCleanCredentialEnv(t)
+	server := newCheckFixtureServer(t)
+	t.Setenv("SBOMHUB_API_URL", server.URL)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_test")
+
+	sbomPath, ignorePath := writeCheckFixtures(t, "suppressions: []\n")
+	policyPath := filepath.Join(filepath.Dir(sbomPath), "policy.yaml")
+	if err := os.WriteFile(policyPath, []byte(`
+rules:
+  - name: high-in-prod-without-fix
+    when: {severity_gte: high, fix_available: false, scope: [required]}
+    action: fail
+    exit_code: 7
+  - name: any-low
+    when: {severity_gte: low}
+    action: warn
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout := withCheckFlags(t, "", ignorePath, true)
+	checkPolicyFile = policyPath
+
+	err := runCheck(checkCmd, []string{sbomPath})
+	var se *exitError
+	if !errors.As(err, &se) || se.ExitCode() != 7 {
+		t.Fatalf("err = %v, want exit code 7 from the policy rule", err)
+	}
+
+	var got checkJSONResult
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.Policy == nil || got.Policy.ExitCode != 7 || len(got.Policy.Failed) != 1 || len(got.Policy.Warned) != 1 {
+		t.Errorf("policy = %+v, want one failed (exit 7) and one warned rule", got.Policy)
+	}
+	if got.FailOn.ExitCode != 7 || !got.FailOn.Triggered {
+		t.Errorf("fail_on = %+v, want triggered with exit 7", got.FailOn)
+	}
+}
+
+// TestRunCheck_ApprovedVEXExcludedFromFailOn verifies that with --project
+// an approved not_affected draft removes the HIGH finding before
+// --fail-on high, that only approved drafts are requested, and that the
+// JSON payload names who approved the decision.
+func TestRunCheck_ApprovedVEXExcludedFromFailOn(t *testing.T) {
+	withCleanCredentialEnv(t)
+	const projectID = "0b7c64a2-5d4e-4f8a-9c3b-1e2f3a4b5c6d"
+	check := newCheckFixtureServer(t)
+	var vexQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/capabilities" {
+			http.NotFound(w, r) // a server that predates negotiation
+			return
+		}
+		if r.URL.Path != "/api/v1/projects/"+projectID+"/vex-drafts" {
+			check.Config.Handler.ServeHTTP(w, r)
+			return
+		}
+		vexQuery = r.URL.RawQuery
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"drafts": []map[string]interface{}{
+				{"id": "d-1", "cve_id": "CVE-2021-23337", "state": "not_affected", "justification": "code_not_reachable",
+					"decision": "approved", "decided_by": "alice@example.com", "decided_at": "2026-09-01T00:00:00Z"},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("SBOMHUB_API_URL", server.URL)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_test")
+
+	sbomPath, ignorePath := writeCheckFixtures(t, "suppressions: []\n")
+	stdout := withCheckFlags(t, "high", ignorePath, true)
+	checkProject, checkVEX = projectID, true
+
+	if err := runCheck(checkCmd, []string{sbomPath}); err != nil {
+		t.Fatalf("runCheck() = %v, want nil (HIGH finding covered by approved VEX)", err)
+	}
+	if !strings.Contains(vexQuery, "decision=approved") {
+		t.Errorf("vex-drafts query = %q, want decision=approved", vexQuery)
+	}
+
+	var got checkJSONResult
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.VulnerabilitySummary.High != 0 || got.VulnerabilitySummary.Low != 1 {
+		t.Errorf("summary = %+v, want high=0 low=1", got.VulnerabilitySummary)
+	}
+	if got.VEX == nil || len(got.VEX.Suppressed) != 1 {
+		t.Fatalf("vex = %+v, want one suppressed finding", got.VEX)
+	}
+	if s := got.VEX.Suppressed[0]; s.ID != "CVE-2021-23337" || s.DecidedBy != "alice@example.com" || s.State != "not_affected" {
+		t.Errorf("vex.suppressed[0] = %+v, want lodash CVE approved by alice", s)
+	}
+}
+
+// TestRunCheck_CachesResultPerSBOMAndServer verifies that a repeated
+// check of the same SBOM against the same server is answered from the
+// local cache, and that --no-cache bypasses it.
+func TestRunCheck_CachesResultPerSBOMAndServer(t *testing.T) {
+	withCleanCredentialEnv(t)
+	check := newCheckFixtureServer(t)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		check.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("SBOMHUB_API_URL", server.URL)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_test")
+
+	sbomPath, ignorePath := writeCheckFixtures(t, "suppressions: []\n")
+	withCheckFlags(t, "", ignorePath, true)
+	saveNoCache := noCacheFlag
+	t.Cleanup(func() { noCacheFlag = saveNoCache })
+	noCacheFlag = false
+
+	for i := 0; i < 2; i++ {
+		if err := runCheck(checkCmd, []string{sbomPath}); err != nil {
+			t.Fatalf("run %d: runCheck() = %v", i+1, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("server calls = %d, want 1 (second run served from cache)", calls)
+	}
+
+	noCacheFlag = true
+	if err := runCheck(checkCmd, []string{sbomPath}); err != nil {
+		t.Fatalf("--no-cache run: runCheck() = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("server calls = %d, want 2 (--no-cache bypasses the cache)", calls)
+	}
+}
@@ -1,10 +1,16 @@
 package commands
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/youichi-uda/sbomhub-cli/internal/config"
@@ -15,21 +21,40 @@ var configCmd = &cobra.Command{
 	Short: "現在の設定を表示",
 	Long: `設定を表示または変更します。
 
+設定は ~/.sbomhub/config.yaml (または $XDG_CONFIG_HOME/sbomhub/config.yaml)
+にプロファイル単位で保存されます。 管理者が /etc/sbomhub/config.yaml に
+置いた設定 (サーバ URL・ CA バンドル等) はその下の層として読み込まれます。
+対象のプロファイルは --profile、 環境変数 SBOMHUB_PROFILE、
+config.yaml の current_context (config use-context で変更) の順に決まり、
+どれも無ければ default プロファイルです。
+
+コマンドのデフォルト (project / tool / format / fail_on / タイムアウト) や
+TLS 設定もプロファイルに保存できます。 実際に使われる値は
+フラグ > 環境変数 > リポジトリの .sbomhub.yaml > プロファイル > 組み込み
+デフォルトの順に決まり、 config list --show-origin で確認できます。
+
 使用例:
   sbomhub config              # 現在の設定を表示
   sbomhub config get api_url  # api_urlの値を取得
-  sbomhub config set api_url https://api.sbomhub.app  # api_urlを設定`,
+  sbomhub config set api_url https://api.sbomhub.app  # api_urlを設定
+  sbomhub config set fail_on high        # scan / check のデフォルト
+  sbomhub config unset fail_on           # 設定を削除
+  sbomhub config list --show-origin      # 全設定と値の出所
+  sbomhub config edit                    # $EDITOR で編集 (保存時に検証)
+  sbomhub config get-contexts            # プロファイル一覧
+  sbomhub config use-context staging     # 既定のプロファイルを切り替え
+  sbomhub --profile staging config set api_key sbh_xxx  # staging に設定`,
 	RunE: runConfig,
 }
 
 var configGetCmd = &cobra.Command{
 	Use:   "get <key>",
 	Short: "設定値を取得",
-	Long: `指定したキーの設定値を取得します。
+	Long: `指定したキーの実際に使われる値 (フラグ・ 環境変数・ .sbomhub.yaml・
+プロファイル・ デフォルトを反映したもの) を表示します。 api_key はマスク
+表示 (キーチェーン等に保存した場合は参照先) です。
 
-取得可能なキー:
-  api_url  - SBOMHub API URL
-  api_key  - API Key (マスク表示)`,
+キーの一覧は 'sbomhub config list' で確認できます。`,
 	Args: cobra.ExactArgs(1),
 	RunE: runConfigGet,
 }
@@ -37,103 +62,510 @@ var configGetCmd = &cobra.Command{
 var configSetCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "設定値を設定",
-	Long: `指定したキーに値を設定します。
+	Long: `プロファイルのキーに値を設定します。 値はキーの型 (URL、 列挙値、
+期間、 整数、 真偽値、 ファイルパス) に従って検証されます。
+
+api_key はキーチェーン / credential_helper に保存済みのプロファイルでは
+そちらを更新します。 credential_helper の反映は次回の login からです。
 
-設定可能なキー:
-  api_url  - SBOMHub API URL
-  api_key  - API Key`,
+キーの一覧は 'sbomhub config list' で確認できます。`,
 	Args: cobra.ExactArgs(2),
 	RunE: runConfigSet,
 }
 
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "設定値を削除",
+	Long: `プロファイルからキーを削除し、 デフォルトに戻します。
+api_key はキーチェーン / credential_helper に保存したものも削除します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigUnset,
+}
+
+var configListShowOrigin bool
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "全設定の値を一覧表示",
+	Long: `すべてのキーについて、 この実行で使われる値を表示します。
+
+--show-origin を付けると、 値の出所 (flag / env / project / profile /
+default) と具体的な指定元 (フラグ名、 環境変数名、 .sbomhub.yaml のパス、
+プロファイル名) も表示します。`,
+	Args: cobra.NoArgs,
+	RunE: runConfigList,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "設定ファイルをエディタで編集",
+	Long: `config.yaml を $EDITOR (未設定なら vi) で開きます。
+
+保存後に内容を検証し、 未知のキー・ 不正な値・ 存在しない current_context が
+あれば保存せずに再編集を促します。 検証を通るまで元のファイルは変更されません。`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEdit,
+}
+
+var configUseContextCmd = &cobra.Command{
+	Use:   "use-context <profile>",
+	Short: "既定のプロファイルを切り替え",
+	Long: `config.yaml の current_context を変更し、 --profile / SBOMHUB_PROFILE を
+指定しないときに使うプロファイルを切り替えます。
+
+プロファイルは login または config set (--profile 付き) で作成します。
+default プロファイル (config.yaml 直下の api_url / api_key) は常に選択できます。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigUseContext,
+}
+
+var configGetContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "プロファイル一覧を表示",
+	Long: `config.yaml に定義されているプロファイルを一覧表示します。
+CURRENT 列の * は、 この実行で使われるプロファイルです。`,
+	Args: cobra.NoArgs,
+	RunE: runConfigGetContexts,
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configUseContextCmd)
+	configCmd.AddCommand(configGetContextsCmd)
+
+	configListCmd.Flags().BoolVar(&configListShowOrigin, "show-origin", false, "値の出所 (flag / env / project / profile / default) も表示")
 }
 
+// getConfigDir returns the user's config directory, the one login /
+// config set / logout write: the XDG directory when its config.yaml
+// exists, else ~/.sbomhub when its config.yaml exists, else (a first
+// run) the XDG directory if XDG_CONFIG_HOME is set and ~/.sbomhub if not.
+// Existing installs therefore keep writing where they always did, and a
+// container with a read-only HOME only needs XDG_CONFIG_HOME. Reads merge
+// every layer; see loadConfigStack.
 func getConfigDir() string {
-	configDir := filepath.Join(os.Getenv("HOME"), ".sbomhub")
-	if os.Getenv("USERPROFILE") != "" {
-		configDir = filepath.Join(os.Getenv("USERPROFILE"), ".sbomhub")
+	legacy, xdg := legacyConfigDir(), xdgConfigDir()
+	for _, dir := range []string{xdg, legacy} {
+		if _, err := os.Stat(filepath.Join(dir, "config.yaml")); err == nil {
+			return dir
+		}
+	}
+	if os.Getenv("XDG_CONFIG_HOME") != "" {
+		return xdg
+	}
+	return legacy
+}
+
+func homeDir() string {
+	if home := os.Getenv("USERPROFILE"); home != "" {
+		return home
+	}
+	return os.Getenv("HOME")
+}
+
+// legacyConfigDir is ~/.sbomhub, where every CLI version so far kept
+// config.yaml.
+func legacyConfigDir() string {
+	return filepath.Join(homeDir(), ".sbomhub")
+}
+
+// xdgConfigDir is $XDG_CONFIG_HOME/sbomhub, defaulting to
+// ~/.config/sbomhub as the XDG base directory spec says.
+func xdgConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "sbomhub")
 	}
-	return configDir
+	return filepath.Join(homeDir(), ".config", "sbomhub")
+}
+
+// systemConfigDir holds the administrator's config.yaml:
+// SBOMHUB_SYSTEM_CONFIG_DIR when set (packagers, tests), else
+// /etc/sbomhub, or %ProgramData%\sbomhub on Windows.
+func systemConfigDir() string {
+	if dir := os.Getenv("SBOMHUB_SYSTEM_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		if pd := os.Getenv("ProgramData"); pd != "" {
+			return filepath.Join(pd, "sbomhub")
+		}
+		return ""
+	}
+	return "/etc/sbomhub"
+}
+
+// loadConfigStack reads every config.yaml layer (system, ~/.sbomhub,
+// XDG) for a command that reads settings. configDir is the user
+// directory in use — normally getConfigDir(); tests pass their own —
+// and always takes its scope's place in the stack.
+func loadConfigStack(configDir string) (*config.Stack, error) {
+	legacy, xdg := legacyConfigDir(), xdgConfigDir()
+	scopes := []config.Scope{{Name: config.ScopeSystem, Dir: systemConfigDir()}}
+	switch configDir {
+	case legacy, xdg:
+		scopes = append(scopes, config.Scope{Name: config.ScopeUser, Dir: legacy}, config.Scope{Name: config.ScopeXDG, Dir: xdg})
+	default:
+		scopes = append(scopes, config.Scope{Name: config.ScopeUser, Dir: configDir})
+	}
+	return config.LoadStack(scopes...)
 }
 
 func runConfig(cmd *cobra.Command, args []string) error {
 	configDir := getConfigDir()
 
-	cfg, err := config.Load(configDir)
+	stack, err := loadConfigStack(configDir)
+	if err != nil {
+		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	if len(stack.Layers) == 0 {
+		return fmt.Errorf("設定ファイルが見つかりません (%s)。 'sbomhub login' で作成できます", strings.Join(stack.Searched, ", "))
+	}
+	profile, _ := activeProfile()
+	cfg, err := stack.LoadProfile(profile)
 	if err != nil {
 		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
 	}
 
 	fmt.Println("SBOMHub CLI 設定")
 	fmt.Println("-----------------")
+	fmt.Printf("プロファイル: %s\n", stack.File().ResolveName(profile))
 	fmt.Printf("API URL: %s\n", cfg.APIURL)
 
-	// API Keyをマスク表示
-	maskedKey := maskAPIKey(cfg.APIKey)
-	fmt.Printf("API Key: %s\n", maskedKey)
+	// API Keyをマスク表示 (キーチェーン等に保存している場合は参照先)
+	fmt.Printf("API Key: %s\n", describeAPIKey(cfg))
+	if cfg.CredentialHelper != "" {
+		fmt.Printf("Credential Helper: %s\n", cfg.CredentialHelper)
+	}
 
-	fmt.Printf("設定ファイル: %s/config.yaml\n", configDir)
+	// 読み込んだ順 (後ろほど優先) に表示する。 書き込み先はユーザーのファイル。
+	for _, l := range stack.Layers {
+		fmt.Printf("設定ファイル: %s (%s)\n", l.Path, l.Scope)
+	}
+	fmt.Printf("書き込み先: %s\n", filepath.Join(configDir, "config.yaml"))
 
 	return nil
 }
 
 func runConfigGet(cmd *cobra.Command, args []string) error {
-	key := strings.ToLower(args[0])
+	k, err := lookupConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	l, err := loadSettingLayers()
+	if err != nil {
+		return err
+	}
+	fmt.Println(displaySetting(l, k, l.resolve(k)).Value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	k, err := lookupConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	value := args[1]
 	configDir := getConfigDir()
 
-	cfg, err := config.Load(configDir)
+	// 設定ファイルや対象プロファイルがない場合は新規作成
+	f, name, cfg, err := loadProfileForEdit(configDir)
 	if err != nil {
-		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+		return err
 	}
 
-	switch key {
-	case "api_url":
-		fmt.Println(cfg.APIURL)
-	case "api_key":
-		maskedKey := maskAPIKey(cfg.APIKey)
-		fmt.Println(maskedKey)
-	default:
-		return fmt.Errorf("不明なキー: %s (有効なキー: api_url, api_key)", key)
+	if k.Name == "api_key" {
+		// 保存先は変えない: キーチェーン / helper に置いているプロファイルは
+		// そちらを更新し、 平文に戻さない。
+		mode := credStoreFile
+		if cfg.APIKeyRef != "" {
+			mode = refBackend(cfg.APIKeyRef)
+		}
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		storedIn, err := storeAPIKey(ctx, cfg, name, value, mode)
+		if err != nil {
+			return err
+		}
+		printSuccess("api_key を設定しました: %s (保存先: %s)", maskAPIKey(value), storedIn)
+	} else {
+		v, err := k.Parse(value)
+		if err != nil {
+			return err
+		}
+		if k.Kind == config.KindPath {
+			p := v
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(configDir, p)
+			}
+			if _, err := os.Stat(p); err != nil {
+				return fmt.Errorf("%s: ファイルを確認できません: %w", k.Name, err)
+			}
+		}
+		k.Set(cfg, v)
+		printSuccess("%s を設定しました: %s", k.Name, v)
 	}
 
+	f.SetProfile(name, cfg)
+	if err := config.WriteFile(f, configDir); err != nil {
+		return fmt.Errorf("設定の保存に失敗しました: %w", err)
+	}
+	noteOverride(k)
 	return nil
 }
 
-func runConfigSet(cmd *cobra.Command, args []string) error {
-	key := strings.ToLower(args[0])
-	value := args[1]
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	k, err := lookupConfigKey(args[0])
+	if err != nil {
+		return err
+	}
 	configDir := getConfigDir()
+	f, name, cfg, err := loadProfileForEdit(configDir)
+	if err != nil {
+		return err
+	}
+
+	if k.Name == "api_key" {
+		if cfg.APIKey == "" && cfg.APIKeyRef == "" {
+			printInfo("api_key は設定されていません (プロファイル %s)", name)
+			return nil
+		}
+		if cfg.APIKeyRef != "" {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if err := eraseStoredAPIKey(ctx, cfg); err != nil {
+				printInfo("※ 保存先 (%s) の API Key を削除できませんでした: %v", cfg.APIKeyRef, err)
+			}
+		}
+		cfg.APIKey, cfg.APIKeyRef = "", ""
+	} else {
+		if k.Get(cfg) == "" {
+			printInfo("%s は設定されていません (プロファイル %s)", k.Name, name)
+			return nil
+		}
+		k.Set(cfg, "")
+	}
+
+	f.SetProfile(name, cfg)
+	if err := config.WriteFile(f, configDir); err != nil {
+		return fmt.Errorf("設定の保存に失敗しました: %w", err)
+	}
+	printSuccess("%s を削除しました (プロファイル %s)", k.Name, name)
+	noteOverride(k)
+	return nil
+}
 
-	cfg, err := config.Load(configDir)
+func runConfigList(cmd *cobra.Command, args []string) error {
+	l, err := loadSettingLayers()
 	if err != nil {
-		// 設定ファイルがない場合は新規作成
-		cfg = &config.Config{
-			APIURL: "https://api.sbomhub.app",
+		return err
+	}
+	values := make([]settingValue, 0, len(config.Keys()))
+	for _, k := range config.Keys() {
+		sv := displaySetting(l, k, l.resolve(k))
+		if !configListShowOrigin {
+			sv.Origin, sv.Source = "", ""
 		}
+		values = append(values, sv)
 	}
 
-	switch key {
-	case "api_url":
-		cfg.APIURL = value
-		printSuccess("api_url を設定しました: %s", value)
-	case "api_key":
-		cfg.APIKey = value
-		printSuccess("api_key を設定しました: %s", maskAPIKey(value))
-	default:
-		return fmt.Errorf("不明なキー: %s (有効なキー: api_url, api_key)", key)
+	out := GetOutputConfig()
+	return out.PrintResult(map[string]interface{}{
+		"profile":  l.profileName,
+		"settings": values,
+	}, func() {
+		w := tabwriter.NewWriter(out.Writer, 0, 0, 2, ' ', 0)
+		if configListShowOrigin {
+			fmt.Fprintln(w, "KEY\tVALUE\tORIGIN\tSOURCE")
+		} else {
+			fmt.Fprintln(w, "KEY\tVALUE")
+		}
+		for _, sv := range values {
+			if configListShowOrigin {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", sv.Key, orDash(sv.Value), orDash(sv.Origin), sv.Source)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\n", sv.Key, orDash(sv.Value))
+			}
+		}
+		w.Flush()
+	})
+}
+
+// lookupConfigKey is config.LookupKey with the error the config
+// subcommands report for an unknown key.
+func lookupConfigKey(name string) (*config.Key, error) {
+	k := config.LookupKey(name)
+	if k == nil {
+		return nil, fmt.Errorf("不明なキー: %s (有効なキー: %s)", name, strings.Join(config.KeyNames(), ", "))
+	}
+	return k, nil
+}
+
+// displaySetting masks api_key for output: the profile's key is
+// described as stored (masked, or the keychain / helper reference), a
+// flag or env key is masked.
+func displaySetting(l *settingLayers, k *config.Key, sv settingValue) settingValue {
+	if k.Name != "api_key" {
+		return sv
+	}
+	if sv.Origin == originProfile {
+		sv.Value = describeAPIKey(l.profile)
+	} else if sv.Value != "" {
+		sv.Value = maskAPIKey(sv.Value)
+	}
+	return sv
+}
+
+// noteOverride tells the operator when the value just written to the
+// profile is shadowed in this shell by a flag, env var or .sbomhub.yaml,
+// since otherwise `config set` appears to have no effect.
+func noteOverride(k *config.Key) {
+	l, err := loadSettingLayers()
+	if err != nil {
+		return
+	}
+	switch sv := l.resolve(k); sv.Origin {
+	case originFlag, originEnv, originProject:
+		printInfo("※ この実行では %s の値が優先されます", sv.Source)
+	}
+}
+
+// loadProfileForEdit returns config.yaml (empty when missing) together
+// with the active profile's name and settings, for the commands that
+// write a profile (config set, login, logout). Unlike LoadProfile, a
+// named profile that does not exist yet is not an error: writing to it
+// is how profiles get created. The name is validated here, since this is
+// the only path that adds new keys under profiles:. No DefaultAPIURL is
+// filled in: writing it back would pin the user's file to the built-in
+// URL and shadow an api_url from the system-wide config.
+func loadProfileForEdit(configDir string) (*config.File, string, *config.Config, error) {
+	f, err := config.ReadFileOrEmpty(configDir)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	profile, _ := activeProfile()
+	name := f.ResolveName(profile)
+	if !config.ValidProfileName(name) {
+		return nil, "", nil, fmt.Errorf("プロファイル名が不正です: %q (英数字と . _ - のみ、 64 文字以内)", name)
+	}
+	if !f.HasProfile(name) {
+		return f, name, &config.Config{}, nil
+	}
+	cfg, err := f.Profile(name)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return f, name, cfg, nil
+}
+
+func runConfigUseContext(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	configDir := getConfigDir()
+
+	// Any layer's profile can be selected; the choice is written to the
+	// user's own file.
+	stack, err := loadConfigStack(configDir)
+	if err != nil {
+		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	if merged := stack.File(); !merged.HasProfile(name) {
+		return fmt.Errorf("プロファイル %q が見つかりません (定義済み: %s)。 'sbomhub --profile %s login' で作成できます",
+			name, strings.Join(merged.Names(), ", "), name)
+	}
+	f, err := config.ReadFileOrEmpty(configDir)
+	if err != nil {
+		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
 	}
 
-	if err := config.Save(cfg, configDir); err != nil {
+	// default は current_context 未設定と同じ意味なので、 キーごと消して
+	// プロファイル導入前と同じ形の config.yaml に戻す。
+	if name == config.DefaultProfile {
+		f.CurrentContext = ""
+	} else {
+		f.CurrentContext = name
+	}
+	if err := config.WriteFile(f, configDir); err != nil {
 		return fmt.Errorf("設定の保存に失敗しました: %w", err)
 	}
 
+	printSuccess("既定のプロファイルを %s に切り替えました", name)
+	if profile, source := activeProfile(); profile != "" && profile != name {
+		printInfo("※ この実行では %s (%s) が優先されます", profile, profileSourceLabel(source))
+	}
 	return nil
 }
 
+// contextInfo is one row of `config get-contexts`.
+type contextInfo struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	APIURL  string `json:"api_url"`
+	APIKey  string `json:"api_key"`
+}
+
+func runConfigGetContexts(cmd *cobra.Command, args []string) error {
+	stack, err := loadConfigStack(getConfigDir())
+	if err != nil {
+		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	f := stack.File()
+	profile, _ := activeProfile()
+	current := f.ResolveName(profile)
+
+	contexts := make([]contextInfo, 0, len(f.Profiles)+1)
+	for _, name := range f.Names() {
+		cfg, err := f.Profile(name)
+		if err != nil {
+			return err
+		}
+		if cfg.APIURL == "" {
+			cfg.APIURL = config.DefaultAPIURL
+		}
+		contexts = append(contexts, contextInfo{
+			Name:    name,
+			Current: name == current,
+			APIURL:  cfg.APIURL,
+			APIKey:  describeAPIKey(cfg),
+		})
+	}
+
+	out := GetOutputConfig()
+	return out.PrintResult(map[string]interface{}{
+		"current_context": current,
+		"contexts":        contexts,
+	}, func() {
+		w := tabwriter.NewWriter(out.Writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CURRENT\tNAME\tAPI URL\tAPI KEY")
+		for _, c := range contexts {
+			mark := ""
+			if c.Current {
+				mark = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, c.Name, c.APIURL, c.APIKey)
+		}
+		w.Flush()
+	})
+}
+
+// profileSourceLabel renders an activeProfile source for humans.
+func profileSourceLabel(source string) string {
+	switch source {
+	case "flag":
+		return "--profile"
+	case "env":
+		return "SBOMHUB_PROFILE"
+	}
+	return "config.yaml の current_context"
+}
+
 func maskAPIKey(key string) string {
 	if key == "" {
 		return "(未設定)"
@@ -143,3 +575,90 @@ func maskAPIKey(key string) string {
 	}
 	return key[:4] + strings.Repeat("*", len(key)-8) + key[len(key)-4:]
 }
+
+// configTemplate seeds `config edit` when there is no config.yaml yet.
+const configTemplate = `# SBOMHub CLI 設定 (sbomhub config list で全キーを確認できます)
+api_url: ` + config.DefaultAPIURL + `
+# fail_on: high
+# profiles:
+#   staging:
+#     api_url: https://staging.sbomhub.example.com
+`
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	return editConfigFile(getConfigDir(), defaultEditor(), os.Stdin)
+}
+
+// editConfigFile runs editor on a copy of config.yaml and replaces the
+// file only once the copy passes config.CheckFile, so a typo cannot leave
+// the CLI without a readable config. On a validation error the operator
+// is asked (on in) whether to edit again; anything but yes gives up and
+// keeps the original. The edited bytes are written as is, comments and
+// all.
+func editConfigFile(configDir string, editor editorFunc, in io.Reader) error {
+	path := filepath.Join(configDir, "config.yaml")
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	content := original
+	if content == nil {
+		content = []byte(configTemplate)
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return fmt.Errorf("ディレクトリの作成に失敗しました: %w", err)
+	}
+	tmp, err := os.CreateTemp(configDir, "config-*.yaml")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	// current_context may name a profile another layer defines.
+	var inherited []string
+	if stack, err := loadConfigStack(configDir); err == nil {
+		inherited = stack.File().Names()
+	}
+
+	out := GetOutputConfig()
+	reader := bufio.NewReader(in)
+	for {
+		if err := editor(tmpPath); err != nil {
+			return fmt.Errorf("エディタの実行に失敗しました: %w", err)
+		}
+		edited, err := os.ReadFile(tmpPath)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, original) || (original == nil && bytes.Equal(edited, []byte(configTemplate))) {
+			printInfo("変更はありません")
+			return nil
+		}
+		_, checkErr := config.CheckFile(edited, inherited...)
+		if checkErr == nil {
+			if err := os.WriteFile(path, edited, 0600); err != nil {
+				return fmt.Errorf("設定の保存に失敗しました: %w", err)
+			}
+			printSuccess("%s を保存しました", path)
+			return nil
+		}
+
+		fmt.Fprintf(out.ErrWriter, "設定ファイルに誤りがあります:\n%v\n", checkErr)
+		fmt.Fprint(out.ErrWriter, "再編集しますか? [Y/n]: ")
+		// EOF (no terminal to answer) counts as no.
+		answer, readErr := reader.ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); readErr == nil && (a == "" || a == "y" || a == "yes") {
+			continue
+		}
+		return fmt.Errorf("設定を保存しませんでした (元のファイルは変更していません): %w", checkErr)
+	}
+}
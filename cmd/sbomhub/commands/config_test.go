@@ -0,0 +1,371 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+)
+
+// writeProfilesConfig seeds $HOME/.sbomhub/config.yaml with a default
+// profile and a "staging" one, and returns the config dir.
+func writeProfilesConfig(t *testing.T, currentContext string) string {
+	t.Helper()
+	withCleanCredentialEnv(t)
+	dir := getConfigDir()
+	f := &config.File{
+		CurrentContext: currentContext,
+		Config:         config.Config{APIURL: "https://prod.example.com", APIKey: "sbh_prod_key"},
+		Profiles: map[string]*config.Config{
+			"staging": {APIURL: "https://staging.example.com", APIKey: "sbh_staging_key"},
+		},
+	}
+	if err := config.WriteFile(f, dir); err != nil {
+		t.Fatalf("config.WriteFile() error = %v", err)
+	}
+	return dir
+}
+
+func TestResolveCredentials_ProfileSelection(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		currentContext string
+		flag, env      string
+		wantKey        string
+	}{
+		{name: "default", wantKey: "sbh_prod_key"},
+		{name: "current_context", currentContext: "staging", wantKey: "sbh_staging_key"},
+		{name: "env beats current_context", currentContext: "staging", env: "default", wantKey: "sbh_prod_key"},
+		{name: "flag beats env", env: "default", flag: "staging", wantKey: "sbh_staging_key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeProfilesConfig(t, tc.currentContext)
+			profileFlag = tc.flag
+			t.Setenv("SBOMHUB_PROFILE", tc.env)
+
+			cfg, err := resolveCredentials(dir)
+			if err != nil {
+				t.Fatalf("resolveCredentials() error = %v", err)
+			}
+			if cfg.APIKey != tc.wantKey {
+				t.Errorf("APIKey = %q, want %q", cfg.APIKey, tc.wantKey)
+			}
+		})
+	}
+}
+
+func TestResolveCredentials_UnknownProfileErrors(t *testing.T) {
+	dir := writeProfilesConfig(t, "")
+	profileFlag = "customer-x"
+
+	if _, err := resolveCredentials(dir); err == nil || !strings.Contains(err.Error(), "customer-x") {
+		t.Fatalf("resolveCredentials() error = %v, want unknown-profile error", err)
+	}
+}
+
+func TestRunConfigUseContext(t *testing.T) {
+	dir := writeProfilesConfig(t, "")
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	globalOutput.Writer, globalOutput.ErrWriter = io.Discard, io.Discard
+
+	if err := runConfigUseContext(configUseContextCmd, []string{"staging"}); err != nil {
+		t.Fatalf("use-context staging: %v", err)
+	}
+	f, err := config.ReadFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.CurrentContext != "staging" {
+		t.Errorf("current_context = %q, want staging", f.CurrentContext)
+	}
+
+	if err := runConfigUseContext(configUseContextCmd, []string{"nope"}); err == nil {
+		t.Error("use-context nope: error = nil, want unknown-profile error")
+	}
+
+	// Switching back to default drops the key, restoring the legacy layout.
+	if err := runConfigUseContext(configUseContextCmd, []string{"default"}); err != nil {
+		t.Fatalf("use-context default: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "current_context") {
+		t.Errorf("config.yaml still has current_context:\n%s", data)
+	}
+}
+
+func TestRunConfigGetContexts_JSON(t *testing.T) {
+	writeProfilesConfig(t, "staging")
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.ErrWriter, globalOutput.JSON = &stdout, io.Discard, true
+
+	if err := runConfigGetContexts(configGetContextsCmd, nil); err != nil {
+		t.Fatalf("runConfigGetContexts() = %v", err)
+	}
+	var got struct {
+		CurrentContext string        `json:"current_context"`
+		Contexts       []contextInfo `json:"contexts"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("get-contexts --json is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.CurrentContext != "staging" || len(got.Contexts) != 2 {
+		t.Fatalf("got %+v, want 2 contexts with staging current", got)
+	}
+	if got.Contexts[0].Name != "default" || got.Contexts[0].Current || !got.Contexts[1].Current {
+		t.Errorf("contexts = %+v, want default then staging (current)", got.Contexts)
+	}
+	if strings.Contains(stdout.String(), "sbh_staging_key") {
+		t.Errorf("get-contexts leaked an unmasked API key:\n%s", stdout.String())
+	}
+}
+
+func TestGetConfigDir_PrefersExistingFile(t *testing.T) {
+	withCleanCredentialEnv(t)
+	home := os.Getenv("HOME")
+	legacy, xdg := filepath.Join(home, ".sbomhub"), filepath.Join(home, ".config", "sbomhub")
+
+	if got := getConfigDir(); got != legacy {
+		t.Errorf("fresh HOME: getConfigDir() = %q, want %q", got, legacy)
+	}
+	if err := config.WriteFile(&config.File{}, xdg); err != nil {
+		t.Fatal(err)
+	}
+	if got := getConfigDir(); got != xdg {
+		t.Errorf("with XDG config.yaml: getConfigDir() = %q, want %q", got, xdg)
+	}
+
+	// An explicit XDG_CONFIG_HOME wins on a fresh install, but an existing
+	// ~/.sbomhub/config.yaml keeps being written where it is.
+	custom := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", custom)
+	if got := getConfigDir(); got != filepath.Join(custom, "sbomhub") {
+		t.Errorf("XDG_CONFIG_HOME set: getConfigDir() = %q, want %q", got, filepath.Join(custom, "sbomhub"))
+	}
+	if err := config.WriteFile(&config.File{}, legacy); err != nil {
+		t.Fatal(err)
+	}
+	if got := getConfigDir(); got != legacy {
+		t.Errorf("legacy config.yaml: getConfigDir() = %q, want %q", got, legacy)
+	}
+}
+
+func TestResolveCredentials_SystemLayer(t *testing.T) {
+	dir := writeProfilesConfig(t, "staging")
+	system := os.Getenv("SBOMHUB_SYSTEM_CONFIG_DIR")
+	if err := os.WriteFile(filepath.Join(system, "corp-ca.pem"), []byte("-"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := &config.File{Config: config.Config{APIURL: "https://corp.example.com", APIKey: "sbh_host_key", CACert: "corp-ca.pem", NoProxy: "internal"}}
+	if err := config.WriteFile(f, system); err != nil {
+		t.Fatal(err)
+	}
+
+	// The user's staging profile keeps its URL and key but inherits the
+	// administrator's CA bundle, resolved against /etc.
+	cfg, err := resolveCredentials(dir)
+	if err != nil {
+		t.Fatalf("resolveCredentials() error = %v", err)
+	}
+	if cfg.APIURL != "https://staging.example.com" || cfg.APIKey != "sbh_staging_key" ||
+		cfg.CACert != filepath.Join(system, "corp-ca.pem") || cfg.NoProxy != "internal" {
+		t.Errorf("resolveCredentials() = %+v", cfg)
+	}
+
+	// config set must not copy the built-in URL into the user's file,
+	// where it would shadow the system one.
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	globalOutput.Writer, globalOutput.ErrWriter = io.Discard, io.Discard
+	profileFlag = "qa"
+	if err := runConfigSet(configSetCmd, []string{"fail_on", "high"}); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if cfg, err = resolveCredentials(dir); err != nil || cfg.APIURL != "https://corp.example.com" || cfg.APIKey != "" {
+		t.Errorf("new profile qa = %+v, %v; want the system URL and no inherited key", cfg, err)
+	}
+	l, err := loadSettingLayers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sv := l.resolve(config.LookupKey("api_url")); !strings.Contains(sv.Source, filepath.Join(system, "config.yaml")) {
+		t.Errorf("api_url source = %q, want the system file", sv.Source)
+	}
+}
+
+// chdirTemp moves the test into a fresh directory, for the settings read
+// from the working directory's .sbomhub.yaml.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(prev) })
+	return dir
+}
+
+func TestRunConfigList_ShowOrigin(t *testing.T) {
+	writeProfilesConfig(t, "")
+	if err := runConfigSet(configSetCmd, []string{"fail_on", "critical"}); err != nil {
+		t.Fatalf("config set fail_on: %v", err)
+	}
+	if err := runConfigSet(configSetCmd, []string{"format", "spdx"}); err != nil {
+		t.Fatalf("config set format: %v", err)
+	}
+	dir := chdirTemp(t)
+	if err := os.WriteFile(filepath.Join(dir, config.ProjectFileName), []byte("project: repo-app\nformat: cyclonedx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SBOMHUB_PROJECT", "env-app")
+	saveShow := configListShowOrigin
+	t.Cleanup(func() { configListShowOrigin = saveShow })
+	configListShowOrigin = true
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.ErrWriter, globalOutput.JSON = &stdout, io.Discard, true
+
+	if err := runConfigList(configListCmd, nil); err != nil {
+		t.Fatalf("runConfigList() = %v", err)
+	}
+	var got struct {
+		Settings []settingValue `json:"settings"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("config list --json is not JSON: %v\n%s", err, stdout.String())
+	}
+	byKey := map[string]settingValue{}
+	for _, sv := range got.Settings {
+		byKey[sv.Key] = sv
+	}
+	for key, want := range map[string][2]string{
+		"project":      {"env-app", originEnv},
+		"format":       {"cyclonedx", originProject},
+		"fail_on":      {"critical", originProfile},
+		"wait_timeout": {"5m0s", originDefault},
+		"api_url":      {"https://prod.example.com", originProfile},
+	} {
+		if sv := byKey[key]; sv.Value != want[0] || sv.Origin != want[1] {
+			t.Errorf("%s = %+v, want value %q from %s", key, sv, want[0], want[1])
+		}
+	}
+	if v := byKey["api_key"].Value; strings.Contains(v, "sbh_prod_key") {
+		t.Errorf("config list leaked the API key: %q", v)
+	}
+}
+
+func TestRunConfigSet_ValidatesAndUnsets(t *testing.T) {
+	dir := writeProfilesConfig(t, "")
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	globalOutput.Writer, globalOutput.ErrWriter = io.Discard, io.Discard
+
+	for _, args := range [][]string{{"fail_on", "urgent"}, {"poll_interval", "0s"}, {"ca_cert", "missing.pem"}, {"colour", "blue"}} {
+		if err := runConfigSet(configSetCmd, args); err == nil {
+			t.Errorf("config set %v succeeded, want a validation error", args)
+		}
+	}
+	if err := runConfigSet(configSetCmd, []string{"max-retries", "0"}); err != nil {
+		t.Fatalf("config set max-retries 0: %v", err)
+	}
+	cfg, err := config.LoadProfile(dir, "")
+	if err != nil || cfg.MaxRetries == nil || *cfg.MaxRetries != 0 {
+		t.Fatalf("max_retries after set = %v, %v; want 0", cfg.MaxRetries, err)
+	}
+	if got := effectiveMaxRetries(cfg); got != 0 {
+		t.Errorf("effectiveMaxRetries() = %d, want the profile's 0", got)
+	}
+	if err := runConfigUnset(configUnsetCmd, []string{"max_retries"}); err != nil {
+		t.Fatalf("config unset: %v", err)
+	}
+	if cfg, _ := config.LoadProfile(dir, ""); cfg.MaxRetries != nil {
+		t.Errorf("max_retries after unset = %d, want unset", *cfg.MaxRetries)
+	}
+}
+
+func TestApplySettingDefaults(t *testing.T) {
+	writeProfilesConfig(t, "")
+	chdirTemp(t)
+	if err := runConfigSet(configSetCmd, []string{"fail_on", "high"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SBOMHUB_WAIT_TIMEOUT", "45s")
+	saveFailOn, saveTimeout, saveTool := scanFailOn, scanWaitTimeout, scanTool
+	t.Cleanup(func() {
+		scanFailOn, scanWaitTimeout, scanTool = saveFailOn, saveTimeout, saveTool
+		_ = scanCmd.Flags().Set("tool", saveTool)
+		scanCmd.Flags().Lookup("tool").Changed = false
+	})
+	// An explicit flag wins over every setting.
+	if err := scanCmd.Flags().Set("tool", "trivy"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SBOMHUB_TOOL", "syft")
+
+	if err := applySettingDefaults(scanCmd, map[string]string{"fail-on": "fail_on", "wait-timeout": "wait_timeout", "tool": "tool"}); err != nil {
+		t.Fatalf("applySettingDefaults() = %v", err)
+	}
+	if scanFailOn != "high" || scanWaitTimeout.String() != "45s" || scanTool != "trivy" {
+		t.Errorf("fail-on=%q wait-timeout=%s tool=%q, want high / 45s / trivy", scanFailOn, scanWaitTimeout, scanTool)
+	}
+	if scanCmd.Flags().Changed("fail-on") {
+		t.Error("a setting must not mark the flag as passed")
+	}
+
+	t.Setenv("SBOMHUB_WAIT_TIMEOUT", "later")
+	if err := applySettingDefaults(scanCmd, map[string]string{"wait-timeout": "wait_timeout"}); err == nil || !strings.Contains(err.Error(), "SBOMHUB_WAIT_TIMEOUT") {
+		t.Errorf("applySettingDefaults() error = %v, want the bad env var named", err)
+	}
+}
+
+func TestEditConfigFile(t *testing.T) {
+	dir := writeProfilesConfig(t, "")
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	globalOutput.Writer, globalOutput.ErrWriter = io.Discard, io.Discard
+	original, _ := os.ReadFile(filepath.Join(dir, "config.yaml"))
+
+	// First save has a typo; the operator answers "y" and fixes it.
+	edits := []string{"api_url: https://prod.example.com\nfail_onn: high\n", "api_url: https://prod.example.com\nfail_on: high\n"}
+	calls := 0
+	editor := func(path string) error {
+		calls++
+		return os.WriteFile(path, []byte(edits[calls-1]), 0o600)
+	}
+	if err := editConfigFile(dir, editor, strings.NewReader("y\n")); err != nil {
+		t.Fatalf("editConfigFile() = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("editor ran %d times, want 2", calls)
+	}
+	if cfg, _ := config.LoadProfile(dir, ""); cfg.FailOn != "high" {
+		t.Errorf("fail_on after edit = %q, want high", cfg.FailOn)
+	}
+
+	// Declining (or no terminal) keeps the file as it was.
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), original, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	calls = 0
+	if err := editConfigFile(dir, editor, strings.NewReader("")); err == nil {
+		t.Fatal("editConfigFile() with an invalid edit and no answer succeeded")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "config.yaml")); !bytes.Equal(data, original) {
+		t.Errorf("config.yaml changed after a rejected edit:\n%s", data)
+	}
+}
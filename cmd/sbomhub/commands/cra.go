@@ -22,7 +22,7 @@ package commands
 //
 // All three flow through the same M1-aligned regime — credentials via
 // resolveCredentials, output via GetOutputConfig, exit codes via
-// craExitError (3=permanent, 4=transient) — so the operator gets the
+// the shared table in exitcode.go (3=permanent, 4=transient) — so the operator gets the
 // same UX they learned from `sbomhub triage`.
 //
 // AI-disabled fallback (BYOK not configured) flows the M1 #F4 / #F22
@@ -55,7 +55,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // Decision constants — values mirror the server-side
@@ -84,17 +84,6 @@ const (
 // operators see the same actionable message across both AI surfaces.
 const craAIDisabledHintJa = "APIキー未設定のため AI 解析 skip。 `/settings/llm` で BYOK 設定するか、 template-only draft で運用してください"
 
-// craExitError lets a runCraXxx return a specific exit code through
-// the cobra error path. Mirrors triageExitError so main.go's exitCoder
-// hook covers both surfaces without a second type assertion branch.
-type craExitError struct {
-	code int
-	msg  string
-}
-
-func (e *craExitError) Error() string { return e.msg }
-func (e *craExitError) ExitCode() int { return e.code }
-
 // ---------------------------------------------------------------------------
 // Flag globals (per subcommand) — kept package-scoped to match the existing
 // triage / scan command conventions
@@ -266,8 +255,8 @@ func init() {
 
 func runCraDraft(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
-	if strings.TrimSpace(craProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &craProject); err != nil {
+		return err
 	}
 	if strings.TrimSpace(craDraftCVE) == "" {
 		return fmt.Errorf("--cve は必須です / --cve is required (e.g. --cve CVE-2024-12345)")
@@ -287,6 +276,16 @@ func runCraDraft(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if err := requireScope(ctx, client, sbomhub.ScopeCRAWrite, "cra draft"); err != nil {
+		return err
+	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureCRA, "CRA 報告書 API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, craProject)
+	if err != nil {
+		return err
+	}
 
 	// Resolve CVE → vulnerability_id (UUID required by the server).
 	// We page through the project's vulnerabilities and match on CVEID
@@ -294,12 +293,12 @@ func runCraDraft(cmd *cobra.Command, args []string) error {
 	// operator for two IDs. A miss surfaces as exit 3 (permanent: the
 	// operator must scan an SBOM that surfaces this CVE before they
 	// can draft a CRA report for it).
-	vulnID, err := resolveVulnIDForCVE(ctx, client, craProject, craDraftCVE)
+	vulnID, err := resolveVulnIDForCVE(ctx, client, projectID, craDraftCVE)
 	if err != nil {
 		return err
 	}
 
-	req := api.CRARunReportRequest{
+	req := sbomhub.CRARunReportRequest{
 		VulnerabilityID:  vulnID,
 		CVEID:            craDraftCVE,
 		SourceVEXDraftID: craDraftSourceVEX,
@@ -315,10 +314,10 @@ func runCraDraft(cmd *cobra.Command, args []string) error {
 		AwarenessTime:    craDraftAwarenessTime,
 	}
 
-	res, err := client.RunReport(ctx, craProject, req)
+	res, err := client.RunReport(ctx, projectID, req)
 	if err != nil {
 		// AI-disabled fallback paths
-		var ce *api.CRAError
+		var ce *sbomhub.Error
 		if errors.As(err, &ce) && ce.IsAIDisabled() {
 			// Legacy 503 path — server has not yet shipped the F4 fix.
 			// Print hint + return exit 0 so CI does not break.
@@ -329,7 +328,7 @@ func runCraDraft(cmd *cobra.Command, args []string) error {
 			fmt.Fprintln(out.ErrWriter, "  → AI 解析がスキップされたためドラフトは保存されませんでした")
 			return nil
 		}
-		return craFailureToExitError("cra draft", err)
+		return apiFailure("cra draft", err)
 	}
 
 	// AI-disabled fast path (canonical 2xx + ai_disabled=true).
@@ -350,12 +349,12 @@ func runCraDraft(cmd *cobra.Command, args []string) error {
 // ※要確認: an alternative API would be a dedicated GET /vulnerabilities/
 // by-cve endpoint; until that lands the CLI walks the same list the
 // `sbomhub triage` loop uses (paginated via #F26).
-func resolveVulnIDForCVE(ctx context.Context, client *api.Client, projectID, cveID string) (string, error) {
+func resolveVulnIDForCVE(ctx context.Context, client *sbomhub.Client, projectID, cveID string) (string, error) {
 	vulns, err := client.ListVulnerabilities(ctx, projectID)
 	if err != nil {
 		// Most likely a permanent setup error (bad project, bad
 		// auth) — surface as exit 3.
-		return "", &craExitError{code: 3, msg: fmt.Sprintf("脆弱性一覧の取得に失敗しました: %v", err)}
+		return "", &exitError{code: apiExitCode(err), err: err, msg: fmt.Sprintf("脆弱性一覧の取得に失敗しました: %v", err)}
 	}
 	normalized := strings.ToUpper(strings.TrimSpace(cveID))
 	for _, v := range vulns {
@@ -363,8 +362,8 @@ func resolveVulnIDForCVE(ctx context.Context, client *api.Client, projectID, cve
 			return v.ID, nil
 		}
 	}
-	return "", &craExitError{
-		code: 3,
+	return "", &exitError{
+		code: exitPermanent,
 		msg: fmt.Sprintf("CVE %s が project %s に存在しません — まず `sbomhub scan` で SBOM をアップロードしてください",
 			cveID, projectID),
 	}
@@ -373,13 +372,13 @@ func resolveVulnIDForCVE(ctx context.Context, client *api.Client, projectID, cve
 // renderDraftResult writes the draft to --output (if set) and prints
 // the metadata block. In --json mode the whole CRARunReportResult lands
 // on stdout (humanWriter routes progress to stderr automatically).
-func renderDraftResult(out *OutputConfig, res *api.CRARunReportResult, outputPath string) error {
+func renderDraftResult(out *OutputConfig, res *sbomhub.CRARunReportResult, outputPath string) error {
 	if res == nil || res.Report == nil {
 		// Defensive: decodeRunReportResult guards this in the API
 		// layer, so reaching here would mean a future caller-side
 		// transformation dropped Report. Surface as a transient so CI
 		// can retry rather than silently exit 0 on an empty draft.
-		return &craExitError{code: 4, msg: "サーバから report が返ってきませんでした (protocol error)"}
+		return &exitError{code: exitTransient, msg: "サーバから report が返ってきませんでした (protocol error)"}
 	}
 
 	if out.IsJSON() {
@@ -448,8 +447,8 @@ func renderDraftResult(out *OutputConfig, res *api.CRARunReportResult, outputPat
 
 func runCraList(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
-	if strings.TrimSpace(craProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &craProject); err != nil {
+		return err
 	}
 	client, err := loadCraClient()
 	if err != nil {
@@ -459,8 +458,15 @@ func runCraList(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureCRA, "CRA 報告書 API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, craProject)
+	if err != nil {
+		return err
+	}
 
-	reports, total, err := client.ListReports(ctx, craProject, api.CRAReportListFilter{
+	reports, total, err := client.ListReports(ctx, projectID, sbomhub.CRAReportListFilter{
 		CVEID:      craListCVE,
 		ReportType: craListReportType,
 		Lang:       craListLang,
@@ -468,7 +474,7 @@ func runCraList(cmd *cobra.Command, args []string) error {
 		Decision:   craListDecision,
 	})
 	if err != nil {
-		return craFailureToExitError("cra list", err)
+		return apiFailure("cra list", err)
 	}
 
 	// Apply --limit cap on the client side. The server still returned
@@ -523,8 +529,8 @@ func runCraList(cmd *cobra.Command, args []string) error {
 
 func runCraApprove(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
-	if strings.TrimSpace(craProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &craProject); err != nil {
+		return err
 	}
 	if strings.TrimSpace(craApproveReportID) == "" {
 		return fmt.Errorf("--report-id は必須です / --report-id is required")
@@ -537,13 +543,23 @@ func runCraApprove(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if err := requireScope(ctx, client, sbomhub.ScopeCRAWrite, "cra approve"); err != nil {
+		return err
+	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureCRA, "CRA 報告書 API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, craProject)
+	if err != nil {
+		return err
+	}
 
-	fresh, err := client.DecideReport(ctx, craProject, craApproveReportID, api.CRADecisionRequest{
+	fresh, err := client.DecideReport(ctx, projectID, craApproveReportID, sbomhub.CRADecisionRequest{
 		Decision:     craDecisionApproved,
 		DecisionNote: craApproveNote,
 	})
 	if err != nil {
-		return craFailureToExitError("cra approve", err)
+		return apiFailure("cra approve", err)
 	}
 
 	if out.IsJSON() {
@@ -572,48 +588,10 @@ func runCraApprove(cmd *cobra.Command, args []string) error {
 // loadCraClient is a thin wrapper around loadConfigAndClient so the
 // CRA command paths share the same credential precedence (CLI flag >
 // env > config file > default) as the other commands.
-func loadCraClient() (*api.Client, error) {
+func loadCraClient() (*sbomhub.Client, error) {
 	return loadConfigAndClient()
 }
 
-// craFailureToExitError translates a CRA API error into the exit-code
-// envelope (3=permanent, 4=transient) following the M1 #F21 pattern.
-// Network / unknown errors fall into the transient bucket because the
-// operator's correct response (retry) is the same.
-//
-// AIDisabled is intentionally NOT routed through this helper — the
-// CLI handles BYOK fallback inline so it can render the hint + return
-// exit 0 on the legacy 503 path. By the time a caller reaches this
-// helper, AIDisabled has already been excluded.
-func craFailureToExitError(op string, err error) error {
-	if err == nil {
-		return nil
-	}
-	var ce *api.CRAError
-	if errors.As(err, &ce) {
-		switch {
-		case ce.IsAIDisabled():
-			// Should be unreachable — callers branch on AIDisabled
-			// before calling this helper. Defensive: classify as
-			// transient so a regression that drops the inline branch
-			// does not silently inflate permanentFailures.
-			return &craExitError{code: 4, msg: fmt.Sprintf("%s failed: %v", op, err)}
-		case ce.IsPermanent():
-			return &craExitError{code: 3, msg: fmt.Sprintf("%s 恒久エラー / permanent failure: %v", op, err)}
-		case ce.IsTransient():
-			return &craExitError{code: 4, msg: fmt.Sprintf("%s 一時エラー / transient failure (retry): %v", op, err)}
-		default:
-			// Unknown 4xx that the helpers do not classify (e.g. a
-			// future 451 / 418) — treat as permanent because the
-			// operator's response is "fix something", not "retry".
-			return &craExitError{code: 3, msg: fmt.Sprintf("%s 不明な失敗: %v", op, err)}
-		}
-	}
-	// Network / JSON parse / context errors — retry is the right
-	// response.
-	return &craExitError{code: 4, msg: fmt.Sprintf("%s 一時エラー / transient failure (retry): %v", op, err)}
-}
-
 // validateReportType returns nil iff t is in the M2 allow-list.
 func validateReportType(t string) error {
 	switch t {
@@ -650,4 +628,3 @@ func orDash(s string) string {
 	}
 	return s
 }
-
@@ -26,7 +26,7 @@ import (
 	"sync/atomic"
 	"testing"
 
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // ---------------------------------------------------------------------------
@@ -36,7 +36,7 @@ import (
 type craFakeServer struct {
 	t            *testing.T
 	server       *httptest.Server
-	vulns        []api.VulnerabilityRecord
+	vulns        []sbomhub.VulnerabilityRecord
 	runResp      func(call int, body []byte) (status int, payload interface{})
 	listResp     func(call int, q map[string][]string) (status int, headers map[string]string, payload interface{})
 	getResp      func(call int, reportID string) (status int, payload interface{})
@@ -58,7 +58,7 @@ type capturedCRADecision struct {
 	EditedDraftText *string
 }
 
-func newCraFakeServer(t *testing.T, vulns []api.VulnerabilityRecord) *craFakeServer {
+func newCraFakeServer(t *testing.T, vulns []sbomhub.VulnerabilityRecord) *craFakeServer {
 	t.Helper()
 	tf := &craFakeServer{t: t, vulns: vulns}
 	tf.server = httptest.NewServer(http.HandlerFunc(tf.handle))
@@ -119,7 +119,7 @@ func (tf *craFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 		if tf.getResp != nil {
 			status, payload = tf.getResp(int(n), reportID)
 		} else {
-			payload = api.CRAReport{ID: reportID, ProjectID: "p"}
+			payload = sbomhub.CRAReport{ID: reportID, ProjectID: "p"}
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(status)
@@ -128,7 +128,7 @@ func (tf *craFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 	case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/decision"):
 		n := atomic.AddInt32(&tf.decisionHits, 1)
 		body, _ := io.ReadAll(r.Body)
-		var dec api.CRADecisionRequest
+		var dec sbomhub.CRADecisionRequest
 		_ = json.Unmarshal(body, &dec)
 		// path: /api/v1/projects/<pid>/cra-reports/<rid>/decision
 		parts := strings.Split(r.URL.Path, "/")
@@ -152,7 +152,7 @@ func (tf *craFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 		if tf.decisionResp != nil {
 			status, payload = tf.decisionResp(int(n), reportID, body)
 		} else {
-			payload = api.CRAReport{ID: reportID, Decision: dec.Decision}
+			payload = sbomhub.CRAReport{ID: reportID, Decision: dec.Decision}
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(status)
@@ -197,10 +197,10 @@ func fakeCraReportID(n int) string {
 // run request, prints the metadata block. Output captured via the
 // shared OutputConfig.
 func TestCraDraft_HappyPath(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	res, stdout, stderr := runDraftAndCapture(t, client, draftArgs{
 		project:    "00000000-0000-0000-0000-000000000aaa",
@@ -230,10 +230,10 @@ func TestCraDraft_HappyPath(t *testing.T) {
 // vulnerability list — exit code 3 (permanent) with an actionable
 // "run sbomhub scan" hint.
 func TestCraDraft_CVENotFound(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(2), CVEID: "CVE-2024-OTHER", Severity: "LOW"},
 	})
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	res, _, _ := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
@@ -241,9 +241,9 @@ func TestCraDraft_CVENotFound(t *testing.T) {
 		reportType: "early_warning",
 		lang:       "ja",
 	})
-	exitErr, ok := res.err.(*craExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *craExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3 (CVE not found = permanent)", exitErr.ExitCode())
@@ -257,7 +257,7 @@ func TestCraDraft_CVENotFound(t *testing.T) {
 // with a template-only report. CLI prints the BYOK hint to stderr and
 // exits 0 (the report is still persisted).
 func TestCraDraft_AIDisabled2xx(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
 	tf.runResp = func(call int, body []byte) (int, interface{}) {
@@ -275,7 +275,7 @@ func TestCraDraft_AIDisabled2xx(t *testing.T) {
 			"ai_disabled": true,
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	res, stdout, stderr := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
@@ -299,7 +299,7 @@ func TestCraDraft_AIDisabled2xx(t *testing.T) {
 // and exits 0 (no report persisted by server, but operator gets the
 // actionable message).
 func TestCraDraft_AIDisabled503Legacy(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
 	tf.runResp = func(call int, body []byte) (int, interface{}) {
@@ -308,7 +308,7 @@ func TestCraDraft_AIDisabled503Legacy(t *testing.T) {
 			"reason": "no LLM provider configured",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	res, _, stderr := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
@@ -328,7 +328,7 @@ func TestCraDraft_AIDisabled503Legacy(t *testing.T) {
 // a real outage, must surface exit 4 and MUST NOT print the misleading
 // AI-disabled hint.
 func TestCraDraft_503GenericGateway_F22(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
 	tf.runResp = func(call int, body []byte) (int, interface{}) {
@@ -336,7 +336,7 @@ func TestCraDraft_503GenericGateway_F22(t *testing.T) {
 			"error": "Service Unavailable",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	res, _, stderr := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
@@ -344,9 +344,9 @@ func TestCraDraft_503GenericGateway_F22(t *testing.T) {
 		reportType: "early_warning",
 		lang:       "ja",
 	})
-	exitErr, ok := res.err.(*craExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F22: err = %v (%T), want *craExitError", res.err, res.err)
+		t.Fatalf("F22: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F22: ExitCode = %d, want 4 (generic 503 = transient outage)", exitErr.ExitCode())
@@ -361,7 +361,7 @@ func TestCraDraft_503GenericGateway_F22(t *testing.T) {
 // permanent classification so CI can distinguish "operator must
 // triage first" from a retryable outage.
 func TestCraDraft_PermanentExit3_409(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
 	tf.runResp = func(call int, body []byte) (int, interface{}) {
@@ -369,16 +369,16 @@ func TestCraDraft_PermanentExit3_409(t *testing.T) {
 			"error": "no approved vex_draft available — approve a VEX triage decision for this (project, cve) first",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
 		cve:        fakeCVE(1),
 		reportType: "early_warning",
 		lang:       "ja",
 	})
-	exitErr, ok := res.err.(*craExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *craExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3 (409 = permanent)", exitErr.ExitCode())
@@ -388,22 +388,22 @@ func TestCraDraft_PermanentExit3_409(t *testing.T) {
 // TestCraDraft_TransientExit4_429 — server returns 429 (rate-limited).
 // CLI must exit 4 so CI knows to retry.
 func TestCraDraft_TransientExit4_429(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
 	tf.runResp = func(call int, body []byte) (int, interface{}) {
 		return http.StatusTooManyRequests, map[string]string{"error": "rate limited"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
 		cve:        fakeCVE(1),
 		reportType: "early_warning",
 		lang:       "ja",
 	})
-	exitErr, ok := res.err.(*craExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *craExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("ExitCode = %d, want 4 (429 = transient)", exitErr.ExitCode())
@@ -414,22 +414,22 @@ func TestCraDraft_TransientExit4_429(t *testing.T) {
 // as transient (exit 4) so CI does not silently green-light a run
 // that persisted nothing.
 func TestCraDraft_ProtocolError_F23(t *testing.T) {
-	tf := newCraFakeServer(t, []api.VulnerabilityRecord{
+	tf := newCraFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
 	tf.runResp = func(call int, body []byte) (int, interface{}) {
 		return http.StatusOK, map[string]interface{}{}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
 		cve:        fakeCVE(1),
 		reportType: "early_warning",
 		lang:       "ja",
 	})
-	exitErr, ok := res.err.(*craExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F23: err = %v (%T), want *craExitError", res.err, res.err)
+		t.Fatalf("F23: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F23: ExitCode = %d, want 4 (protocol violation = transient)", exitErr.ExitCode())
@@ -441,7 +441,7 @@ func TestCraDraft_ProtocolError_F23(t *testing.T) {
 // network round-trip needed).
 func TestCraDraft_InvalidReportType_Validation(t *testing.T) {
 	tf := newCraFakeServer(t, nil)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runDraftAndCapture(t, client, draftArgs{
 		project:    "p",
 		cve:        "CVE-2024-1",
@@ -474,7 +474,7 @@ func TestCraList_HappyPath_F28(t *testing.T) {
 				},
 			}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runListAndCapture(t, client, listArgs{project: "p"})
 	if res.err != nil {
 		t.Fatalf("list err: %v", res.err)
@@ -515,7 +515,7 @@ func TestCraList_Pagination_F26(t *testing.T) {
 			map[string]string{"X-Total-Count": strconv.Itoa(pageSize + tailSize)},
 			map[string]interface{}{"reports": rows}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runListAndCapture(t, client, listArgs{project: "p", limit: 5}) // limit just trims display
 	if res.err != nil {
 		t.Fatalf("list err: %v", res.err)
@@ -542,7 +542,7 @@ func TestCraList_FilterEncoding(t *testing.T) {
 			map[string]string{"X-Total-Count": "0"},
 			map[string]interface{}{"reports": []map[string]interface{}{}}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	_, _, _ = runListAndCapture(t, client, listArgs{
 		project:    "p",
 		cveID:      "CVE-2024-1",
@@ -573,7 +573,7 @@ func TestCraList_EmptyProject(t *testing.T) {
 			map[string]string{"X-Total-Count": "0"},
 			map[string]interface{}{"reports": []map[string]interface{}{}}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runListAndCapture(t, client, listArgs{project: "p"})
 	if res.err != nil {
 		t.Fatalf("list err: %v", res.err)
@@ -589,11 +589,11 @@ func TestCraList_PermanentExit3(t *testing.T) {
 	tf.listResp = func(call int, q map[string][]string) (int, map[string]string, interface{}) {
 		return http.StatusUnauthorized, nil, map[string]string{"error": "invalid api key"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runListAndCapture(t, client, listArgs{project: "p"})
-	exitErr, ok := res.err.(*craExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *craExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3", exitErr.ExitCode())
@@ -610,7 +610,7 @@ func TestCraApprove_HappyPath(t *testing.T) {
 	tf := newCraFakeServer(t, nil)
 	tf.decisionResp = func(call int, reportID string, body []byte) (int, interface{}) {
 		decAt := "2026-07-01T00:00:00Z"
-		return http.StatusOK, api.CRAReport{
+		return http.StatusOK, sbomhub.CRAReport{
 			ID:         reportID,
 			ProjectID:  "p",
 			CVEID:      "CVE-2024-1",
@@ -619,7 +619,7 @@ func TestCraApprove_HappyPath(t *testing.T) {
 			DecisionAt: &decAt,
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runApproveAndCapture(t, client, approveArgs{
 		project:  "p",
 		reportID: fakeCraReportID(1),
@@ -655,14 +655,14 @@ func TestCraApprove_ForbiddenPermanent(t *testing.T) {
 	tf.decisionResp = func(call int, reportID string, body []byte) (int, interface{}) {
 		return http.StatusForbidden, map[string]string{"error": "write permission required"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runApproveAndCapture(t, client, approveArgs{
 		project:  "p",
 		reportID: fakeCraReportID(1),
 	})
-	exitErr, ok := res.err.(*craExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *craExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3 (403 = permanent)", exitErr.ExitCode())
@@ -672,7 +672,7 @@ func TestCraApprove_ForbiddenPermanent(t *testing.T) {
 // TestCraApprove_MissingReportID — flag validation.
 func TestCraApprove_MissingReportID(t *testing.T) {
 	tf := newCraFakeServer(t, nil)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runApproveAndCapture(t, client, approveArgs{project: "p"})
 	if res.err == nil {
 		t.Fatal("expected error for missing --report-id")
@@ -728,7 +728,7 @@ func TestValidateLang(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// craFailureToExitError unit-tests
+// apiFailure unit-tests
 // ---------------------------------------------------------------------------
 
 // TestCraFailureToExitError_Classification pins the helper's permanent
@@ -740,25 +740,25 @@ func TestCraFailureToExitError_Classification(t *testing.T) {
 		err      error
 		wantCode int
 	}{
-		{"401 → 3", &api.CRAError{StatusCode: 401}, 3},
-		{"403 → 3", &api.CRAError{StatusCode: 403}, 3},
-		{"404 → 3", &api.CRAError{StatusCode: 404}, 3},
-		{"409 → 3", &api.CRAError{StatusCode: 409}, 3},
-		{"422 → 3", &api.CRAError{StatusCode: 422}, 3},
-		{"429 → 4", &api.CRAError{StatusCode: 429}, 4},
-		{"500 → 4", &api.CRAError{StatusCode: 500}, 4},
-		{"502 → 4", &api.CRAError{StatusCode: 502}, 4},
-		{"503 generic → 4", &api.CRAError{StatusCode: 503, Message: "Service Unavailable"}, 4},
-		{"protocol → 4", &api.CRAError{StatusCode: 200, ProtocolError: true}, 4},
-		{"unknown 418 → 3", &api.CRAError{StatusCode: 418}, 3},
+		{"401 → 3", &sbomhub.Error{StatusCode: 401}, 3},
+		{"403 → 3", &sbomhub.Error{StatusCode: 403}, 3},
+		{"404 → 3", &sbomhub.Error{StatusCode: 404}, 3},
+		{"409 → 3", &sbomhub.Error{StatusCode: 409}, 3},
+		{"422 → 3", &sbomhub.Error{StatusCode: 422}, 3},
+		{"429 → 4", &sbomhub.Error{StatusCode: 429}, 4},
+		{"500 → 4", &sbomhub.Error{StatusCode: 500}, 4},
+		{"502 → 4", &sbomhub.Error{StatusCode: 502}, 4},
+		{"503 generic → 4", &sbomhub.Error{StatusCode: 503, Message: "Service Unavailable"}, 4},
+		{"protocol → 4", &sbomhub.Error{StatusCode: 200, ProtocolError: true}, 4},
+		{"unknown 418 → 3", &sbomhub.Error{StatusCode: 418}, 3},
 		{"network → 4", io.ErrUnexpectedEOF, 4},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := craFailureToExitError("op", tc.err)
-			exitErr, ok := err.(*craExitError)
+			err := apiFailure("op", tc.err)
+			exitErr, ok := err.(*exitError)
 			if !ok {
-				t.Fatalf("err = %v (%T), want *craExitError", err, err)
+				t.Fatalf("err = %v (%T), want *exitError", err, err)
 			}
 			if exitErr.ExitCode() != tc.wantCode {
 				t.Errorf("ExitCode = %d, want %d", exitErr.ExitCode(), tc.wantCode)
@@ -809,7 +809,7 @@ type capturedResult struct {
 // package globals between cases. The wrapper deliberately mirrors
 // runCraDraft step-by-step so a refactor that splits runCraDraft will
 // surface here.
-func runDraftAndCapture(t *testing.T, client *api.Client, args draftArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
+func runDraftAndCapture(t *testing.T, client *sbomhub.Client, args draftArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr}
@@ -832,7 +832,7 @@ func runDraftAndCapture(t *testing.T, client *api.Client, args draftArgs) (captu
 	if err != nil {
 		return capturedResult{err: err}, &stdout, &stderr
 	}
-	req := api.CRARunReportRequest{
+	req := sbomhub.CRARunReportRequest{
 		VulnerabilityID:  vulnID,
 		CVEID:            args.cve,
 		SourceVEXDraftID: args.sourceVEX,
@@ -841,7 +841,7 @@ func runDraftAndCapture(t *testing.T, client *api.Client, args draftArgs) (captu
 	}
 	res, err := client.RunReport(ctx, args.project, req)
 	if err != nil {
-		var ce *api.CRAError
+		var ce *sbomhub.Error
 		if errAsCRA(err, &ce) && ce.IsAIDisabled() {
 			fmt.Fprintln(out.ErrWriter, craAIDisabledHintJa)
 			if ce.Reason != "" {
@@ -850,7 +850,7 @@ func runDraftAndCapture(t *testing.T, client *api.Client, args draftArgs) (captu
 			fmt.Fprintln(out.ErrWriter, "  → AI 解析がスキップされたためドラフトは保存されませんでした")
 			return capturedResult{err: nil}, &stdout, &stderr
 		}
-		return capturedResult{err: craFailureToExitError("cra draft", err)}, &stdout, &stderr
+		return capturedResult{err: apiFailure("cra draft", err)}, &stdout, &stderr
 	}
 	if res.AIDisabled {
 		fmt.Fprintln(out.ErrWriter, craAIDisabledHintJa)
@@ -860,7 +860,7 @@ func runDraftAndCapture(t *testing.T, client *api.Client, args draftArgs) (captu
 	return capturedResult{err: renderErr}, &stdout, &stderr
 }
 
-func runListAndCapture(t *testing.T, client *api.Client, args listArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
+func runListAndCapture(t *testing.T, client *sbomhub.Client, args listArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr}
@@ -868,7 +868,7 @@ func runListAndCapture(t *testing.T, client *api.Client, args listArgs) (capture
 		return capturedResult{err: fmt.Errorf("--project は必須")}, &stdout, &stderr
 	}
 	ctx := context.Background()
-	reports, total, err := client.ListReports(ctx, args.project, api.CRAReportListFilter{
+	reports, total, err := client.ListReports(ctx, args.project, sbomhub.CRAReportListFilter{
 		CVEID:      args.cveID,
 		ReportType: args.reportType,
 		Lang:       args.lang,
@@ -876,7 +876,7 @@ func runListAndCapture(t *testing.T, client *api.Client, args listArgs) (capture
 		Decision:   args.decision,
 	})
 	if err != nil {
-		return capturedResult{err: craFailureToExitError("cra list", err)}, &stdout, &stderr
+		return capturedResult{err: apiFailure("cra list", err)}, &stdout, &stderr
 	}
 	rendered := reports
 	if args.limit > 0 && len(rendered) > args.limit {
@@ -903,7 +903,7 @@ func runListAndCapture(t *testing.T, client *api.Client, args listArgs) (capture
 	return capturedResult{err: nil}, &stdout, &stderr
 }
 
-func runApproveAndCapture(t *testing.T, client *api.Client, args approveArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
+func runApproveAndCapture(t *testing.T, client *sbomhub.Client, args approveArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr}
@@ -914,12 +914,12 @@ func runApproveAndCapture(t *testing.T, client *api.Client, args approveArgs) (c
 		return capturedResult{err: fmt.Errorf("--report-id は必須")}, &stdout, &stderr
 	}
 	ctx := context.Background()
-	fresh, err := client.DecideReport(ctx, args.project, args.reportID, api.CRADecisionRequest{
+	fresh, err := client.DecideReport(ctx, args.project, args.reportID, sbomhub.CRADecisionRequest{
 		Decision:     craDecisionApproved,
 		DecisionNote: args.note,
 	})
 	if err != nil {
-		return capturedResult{err: craFailureToExitError("cra approve", err)}, &stdout, &stderr
+		return capturedResult{err: apiFailure("cra approve", err)}, &stdout, &stderr
 	}
 	fmt.Fprintf(out.Writer, "CRA report %s を承認しました\n", fresh.ID)
 	fmt.Fprintf(out.Writer, "  Decision: %s\n", fresh.Decision)
@@ -928,12 +928,12 @@ func runApproveAndCapture(t *testing.T, client *api.Client, args approveArgs) (c
 
 // errAsCRA is a tiny errors.As shim that keeps the test scaffolding
 // readable. Returns true + populates target on match.
-func errAsCRA(err error, target **api.CRAError) bool {
+func errAsCRA(err error, target **sbomhub.Error) bool {
 	for {
 		if err == nil {
 			return false
 		}
-		if ce, ok := err.(*api.CRAError); ok {
+		if ce, ok := err.(*sbomhub.Error); ok {
 			*target = ce
 			return true
 		}
@@ -0,0 +1,413 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/ciidentity"
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/internal/credential"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// Where `login` (and `config set api_key`) put the API key. "auto" picks
+// credential_helper when the profile configures one, else the OS
+// keychain when its tool is installed, else the config file.
+const (
+	credStoreAuto    = "auto"
+	credStoreKeyring = credential.BackendKeyring
+	credStoreHelper  = credential.BackendHelper
+	credStoreFile    = "file"
+)
+
+// lookupStoredAPIKey fetches the key cfg.APIKeyRef points at.
+func lookupStoredAPIKey(ctx context.Context, cfg *config.Config) (string, error) {
+	ref, store, err := openRef(cfg, cfg.APIKeyRef)
+	if err != nil {
+		return "", err
+	}
+	key, err := store.Get(ctx, ref.Account)
+	if err != nil {
+		return "", fmt.Errorf("API Key を%sから取得できませんでした (api_key_ref: %s): %w — 'sbomhub login' で再設定できます",
+			credStoreLabel(ref.Backend), cfg.APIKeyRef, err)
+	}
+	return key, nil
+}
+
+// openRef opens the store a reference of cfg (api_key_ref or token_ref)
+// names.
+func openRef(cfg *config.Config, refStr string) (credential.Ref, credential.Store, error) {
+	ref, err := credential.ParseRef(refStr)
+	if err != nil {
+		return ref, nil, err
+	}
+	store, err := credential.Open(ref, cfg.CredentialHelper, cfg.APIURL)
+	return ref, store, err
+}
+
+// resolveStoreMode turns credStoreAuto into the concrete mode for cfg.
+func resolveStoreMode(cfg *config.Config, mode string) string {
+	if mode != credStoreAuto {
+		return mode
+	}
+	switch {
+	case cfg.CredentialHelper != "":
+		return credStoreHelper
+	case credential.KeyringAvailable():
+		return credStoreKeyring
+	}
+	return credStoreFile
+}
+
+// storeAPIKey saves key for the named profile according to mode and
+// points cfg at it: APIKeyRef for a store, APIKey for the file. The
+// caller writes cfg back to config.yaml. A key previously held in a
+// different store is erased so logging in again does not strand it.
+//
+// In auto mode a keychain that fails to store (locked, no Secret Service
+// on a headless Linux box) falls back to the file with a notice rather
+// than failing the login.
+func storeAPIKey(ctx context.Context, cfg *config.Config, profile, key, mode string) (string, error) {
+	prev := *cfg
+	auto := mode == credStoreAuto
+	mode = resolveStoreMode(cfg, mode)
+
+	switch mode {
+	case credStoreFile:
+		cfg.APIKey, cfg.APIKeyRef = key, ""
+	case credStoreKeyring, credStoreHelper:
+		ref := credential.Ref{Backend: mode, Account: credential.Account(profile, cfg.APIURL)}
+		store, err := credential.Open(ref, cfg.CredentialHelper, cfg.APIURL)
+		if err == nil {
+			err = store.Store(ctx, ref.Account, key)
+		}
+		if err != nil {
+			if !auto || mode != credStoreKeyring {
+				return "", fmt.Errorf("API Key を%sに保存できませんでした: %w", credStoreLabel(mode), err)
+			}
+			printInfo("※ OS キーチェーンに保存できなかったため、 設定ファイルに保存します: %v", err)
+			mode = credStoreFile
+			cfg.APIKey, cfg.APIKeyRef = key, ""
+			break
+		}
+		cfg.APIKey, cfg.APIKeyRef = "", ref.String()
+	default:
+		return "", fmt.Errorf("不明な保存先: %s (auto / keyring / helper / file)", mode)
+	}
+
+	if prev.APIKeyRef != "" && prev.APIKeyRef != cfg.APIKeyRef {
+		if err := eraseStoredAPIKey(ctx, &prev); err != nil {
+			printInfo("※ 以前の保存先 (%s) の API Key を削除できませんでした: %v", prev.APIKeyRef, err)
+		}
+	}
+	return credStoreLabel(mode), nil
+}
+
+// eraseStoredAPIKey removes the key cfg.APIKeyRef points at. cfg itself
+// is left alone.
+func eraseStoredAPIKey(ctx context.Context, cfg *config.Config) error {
+	ref, store, err := openRef(cfg, cfg.APIKeyRef)
+	if err != nil {
+		return err
+	}
+	return store.Erase(ctx, ref.Account)
+}
+
+// tokenAccountSuffix tells a profile's device-login tokens apart from
+// its API key in the same keychain / helper.
+const tokenAccountSuffix = "#oauth"
+
+// hasDeviceSession reports whether cfg holds device-login tokens, in
+// the file or behind token_ref.
+func hasDeviceSession(cfg *config.Config) bool {
+	return cfg.RefreshToken != "" || cfg.AccessToken != "" || cfg.TokenRef != ""
+}
+
+// deviceToken returns the tokens stored in cfg's fields.
+func deviceToken(cfg *config.Config) sbomhub.Token {
+	return sbomhub.Token{AccessToken: cfg.AccessToken, RefreshToken: cfg.RefreshToken, Expiry: cfg.TokenExpiry}
+}
+
+// setDeviceToken is the inverse of deviceToken.
+func setDeviceToken(cfg *config.Config, tok sbomhub.Token) {
+	cfg.AccessToken, cfg.RefreshToken, cfg.TokenExpiry = tok.AccessToken, tok.RefreshToken, tok.Expiry
+}
+
+// lookupStoredTokens fills cfg's token fields from the entry
+// cfg.TokenRef points at.
+func lookupStoredTokens(ctx context.Context, cfg *config.Config) error {
+	ref, store, err := openRef(cfg, cfg.TokenRef)
+	if err == nil {
+		var raw string
+		if raw, err = store.Get(ctx, ref.Account); err == nil {
+			var tok sbomhub.Token
+			if err = json.Unmarshal([]byte(raw), &tok); err == nil {
+				setDeviceToken(cfg, tok)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("ログイントークンを%sから取得できませんでした (token_ref: %s): %w — 'sbomhub login --device' で再ログインできます",
+		credStoreLabel(refBackend(cfg.TokenRef)), cfg.TokenRef, err)
+}
+
+// storeTokens is storeAPIKey for a device-login token: the access and
+// refresh tokens go to the store as one JSON entry (token_ref), or into
+// the file. It is also how a refreshed token is written back, so a
+// failing keychain in auto mode falls back to the file the same way.
+func storeTokens(ctx context.Context, cfg *config.Config, profile string, tok sbomhub.Token, mode string) (string, error) {
+	prev := *cfg
+	auto := mode == credStoreAuto
+	mode = resolveStoreMode(cfg, mode)
+
+	switch mode {
+	case credStoreFile:
+		setDeviceToken(cfg, tok)
+		cfg.TokenRef = ""
+	case credStoreKeyring, credStoreHelper:
+		ref := credential.Ref{Backend: mode, Account: credential.Account(profile, cfg.APIURL) + tokenAccountSuffix}
+		data, err := json.Marshal(tok)
+		if err != nil {
+			return "", err
+		}
+		store, err := credential.Open(ref, cfg.CredentialHelper, cfg.APIURL)
+		if err == nil {
+			err = store.Store(ctx, ref.Account, string(data))
+		}
+		if err != nil {
+			if !auto || mode != credStoreKeyring {
+				return "", fmt.Errorf("ログイントークンを%sに保存できませんでした: %w", credStoreLabel(mode), err)
+			}
+			printInfo("※ OS キーチェーンに保存できなかったため、 設定ファイルに保存します: %v", err)
+			mode = credStoreFile
+			setDeviceToken(cfg, tok)
+			cfg.TokenRef = ""
+			break
+		}
+		setDeviceToken(cfg, sbomhub.Token{})
+		cfg.TokenRef = ref.String()
+	default:
+		return "", fmt.Errorf("不明な保存先: %s (auto / keyring / helper / file)", mode)
+	}
+
+	if prev.TokenRef != "" && prev.TokenRef != cfg.TokenRef {
+		if err := eraseStoredTokens(ctx, &prev); err != nil {
+			printInfo("※ 以前の保存先 (%s) のログイントークンを削除できませんでした: %v", prev.TokenRef, err)
+		}
+	}
+	return credStoreLabel(mode), nil
+}
+
+// eraseStoredTokens removes the entry cfg.TokenRef points at.
+func eraseStoredTokens(ctx context.Context, cfg *config.Config) error {
+	ref, store, err := openRef(cfg, cfg.TokenRef)
+	if err != nil {
+		return err
+	}
+	return store.Erase(ctx, ref.Account)
+}
+
+// clearDeviceSession drops cfg's device-login tokens, erasing a stored
+// entry best-effort (as logout does for a stored key).
+func clearDeviceSession(ctx context.Context, name string, cfg *config.Config) {
+	if cfg.TokenRef != "" {
+		target := *cfg
+		if target.APIURL == "" {
+			target.APIURL = config.DefaultAPIURL
+		}
+		if err := eraseStoredTokens(ctx, &target); err != nil {
+			printInfo("※ %s のログイントークンを%sから削除できませんでした: %v", name, credStoreLabel(refBackend(cfg.TokenRef)), err)
+		}
+	}
+	setDeviceToken(cfg, sbomhub.Token{})
+	cfg.TokenRef, cfg.OAuthTokenURL, cfg.OAuthClientID = "", "", ""
+}
+
+// credStoreLabel names a storage mode in messages.
+func credStoreLabel(mode string) string {
+	switch mode {
+	case credStoreKeyring:
+		return "OS キーチェーン"
+	case credStoreHelper:
+		return "credential_helper"
+	}
+	return "設定ファイル"
+}
+
+// refBackend returns the backend of an api_key_ref, "" when malformed.
+func refBackend(ref string) string {
+	r, err := credential.ParseRef(ref)
+	if err != nil {
+		return ""
+	}
+	return r.Backend
+}
+
+// describeAPIKey renders a profile's key for display without reading
+// the store (which may prompt): the masked key, or where it lives.
+func describeAPIKey(cfg *config.Config) string {
+	if cfg.APIKeyRef != "" && cfg.APIKey == "" {
+		return "(" + cfg.APIKeyRef + ")"
+	}
+	if cfg.APIKey == "" && hasDeviceSession(cfg) {
+		return "(device login)"
+	}
+	return maskAPIKey(cfg.APIKey)
+}
+
+// persistRefreshedToken returns the onRefresh callback for a device
+// session loaded with refresh token loaded: it writes the new token to
+// the active profile of the config directory. The write is skipped when
+// that profile no longer holds loaded — another run refreshed first, or
+// the user logged in again meanwhile — so a stale process never
+// overwrites a newer session. Failures only cost a refresh on the next
+// run and are reported as notices.
+func persistRefreshedToken(loaded string) func(sbomhub.Token) {
+	return func(tok sbomhub.Token) {
+		ctx := context.Background()
+		configDir := getConfigDir()
+		f, err := config.ReadFile(configDir)
+		if err != nil {
+			return
+		}
+		profile, _ := activeProfile()
+		name := f.ResolveName(profile)
+		cfg, err := f.Profile(name)
+		if err != nil {
+			return
+		}
+		current := *cfg
+		if current.APIURL == "" {
+			current.APIURL = config.DefaultAPIURL
+		}
+		if current.TokenRef != "" && lookupStoredTokens(ctx, &current) != nil {
+			return
+		}
+		if current.RefreshToken != loaded {
+			return
+		}
+		mode := credStoreFile
+		if cfg.TokenRef != "" {
+			mode = refBackend(cfg.TokenRef)
+		}
+		if _, err := storeTokens(ctx, cfg, name, tok, mode); err != nil {
+			printInfo("※ 更新したログイントークンを保存できませんでした: %v", err)
+			return
+		}
+		f.SetProfile(name, cfg)
+		if err := config.WriteFile(f, configDir); err != nil {
+			printInfo("※ 更新したログイントークンを保存できませんでした: %v", err)
+		}
+	}
+}
+
+// ciIdentityProvider names the CI system whose identity token
+// newAPIClient will exchange for cfg, or returns "" when there is none:
+// cfg already has a key, the run is not such a CI job, or no API URL was
+// configured. The built-in default URL is the sunset SaaS instance, and a
+// job that set nothing must not mint an ID token for it.
+func ciIdentityProvider(cfg *config.Config) string {
+	if cfg.APIKey != "" || cfg.APIURL == "" || cfg.APIURL == config.DefaultAPIURL {
+		return ""
+	}
+	return ciidentity.Detect(os.Getenv)
+}
+
+// hasCredentials reports whether cfg can authenticate: with its key or
+// login token, or with the CI identity newAPIClient exchanges.
+func hasCredentials(cfg *config.Config) bool {
+	return cfg.APIKey != "" || ciIdentityProvider(cfg) != ""
+}
+
+// ciTokenSource supplies the API token exchanged for the CI job's
+// identity. The exchange happens on the first authenticated request, with
+// that request's context, so commands that never authenticate never mint
+// an ID token and Ctrl-C cancels a slow exchange; it is redone when the
+// token expires or the server rejects it.
+type ciTokenSource struct {
+	mu  sync.Mutex
+	cfg *config.Config
+	tok *sbomhub.Token
+}
+
+func (s *ciTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok == nil || s.tok.Expired(time.Now()) {
+		if err := s.exchangeLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+	return s.tok.AccessToken, nil
+}
+
+func (s *ciTokenSource) Refresh(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != nil && s.tok.AccessToken != rejected {
+		return s.tok.AccessToken, nil
+	}
+	if err := s.exchangeLocked(ctx); err != nil {
+		return "", err
+	}
+	return s.tok.AccessToken, nil
+}
+
+func (s *ciTokenSource) exchangeLocked(ctx context.Context) error {
+	tok, err := exchangeCIIdentity(ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	s.tok = tok
+	return nil
+}
+
+// exchangeCIIdentity trades the running CI job's identity token (see
+// internal/ciidentity) for an API token at cfg's token endpoint, taken
+// from oauth_token_url or the server's OAuth metadata. The audience
+// asked of GitHub Actions is SBOMHUB_OIDC_AUDIENCE, else the API URL;
+// SBOMHUB_OIDC_SCOPE narrows what the exchanged token may do. Nothing is
+// minted unless the server declares FeatureTokenExchange.
+func exchangeCIIdentity(ctx context.Context, cfg *config.Config) (*sbomhub.Token, error) {
+	conn := *cfg
+	conn.APIKey = ""
+	client, err := buildAPIClient(&conn)
+	if err != nil {
+		return nil, err
+	}
+	if !serverCapabilities(ctx, client, cfg.APIURL).Declares(sbomhub.FeatureTokenExchange) {
+		return nil, fmt.Errorf("サーバ %s は CI の ID トークン交換 (%s) を宣言していません — SBOMHUB_API_KEY を設定してください",
+			cfg.APIURL, sbomhub.FeatureTokenExchange)
+	}
+
+	audience := os.Getenv("SBOMHUB_OIDC_AUDIENCE")
+	if audience == "" {
+		audience = cfg.APIURL
+	}
+	id, err := ciidentity.Fetch(ctx, os.Getenv, nil, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURL := cfg.OAuthTokenURL
+	if tokenURL == "" {
+		ep, err := client.DiscoverOAuth(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("CI の ID トークン (%s) を交換できませんでした: %w", id.Provider, err)
+		}
+		tokenURL = ep.TokenEndpoint
+	}
+	clientID := cfg.OAuthClientID
+	if clientID == "" {
+		clientID = sbomhub.DefaultOAuthClientID
+	}
+	tok, err := client.ExchangeToken(ctx, tokenURL, clientID, id.Value, sbomhub.JWTTokenType, os.Getenv("SBOMHUB_OIDC_SCOPE"))
+	if err != nil {
+		return nil, fmt.Errorf("CI の ID トークン (%s) を API トークンに交換できませんでした: %w", id.Provider, err)
+	}
+	return tok, nil
+}
@@ -0,0 +1,358 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+)
+
+// writeTestHelper writes a git-credential style helper script that keeps
+// the password in a file next to it, and returns the script path and the
+// secret file. Every invocation is appended to a log so tests can assert
+// the helper was (or was not) consulted.
+func writeTestHelper(t *testing.T) (script, secret, log string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script credential helper")
+	}
+	dir := t.TempDir()
+	script = filepath.Join(dir, "helper.sh")
+	secret = filepath.Join(dir, "secret")
+	log = filepath.Join(dir, "calls")
+	body := `#!/bin/sh
+echo "$1" >> "` + log + `"
+case "$1" in
+get)   [ -f "` + secret + `" ] && printf 'password=%s\n' "$(cat "` + secret + `")" ;;
+store) sed -n 's/^password=//p' > "` + secret + `" ;;
+erase) rm -f "` + secret + `" ;;
+esac
+exit 0
+`
+	if err := os.WriteFile(script, []byte(body), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return script, secret, log
+}
+
+func readCalls(t *testing.T, log string) string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestStoreAPIKey_HelperRoundTrip(t *testing.T) {
+	withCleanCredentialEnv(t)
+	script, secret, log := writeTestHelper(t)
+	dir := getConfigDir()
+
+	cfg := &config.Config{APIURL: "https://sbomhub.example.com", APIKey: "sbh_old_plaintext", CredentialHelper: script}
+	storedIn, err := storeAPIKey(context.Background(), cfg, "default", "sbh_helper_key", credStoreAuto)
+	if err != nil {
+		t.Fatalf("storeAPIKey() error = %v", err)
+	}
+	if storedIn != "credential_helper" {
+		t.Errorf("stored in %q, want credential_helper (auto prefers a configured helper)", storedIn)
+	}
+	if cfg.APIKey != "" || cfg.APIKeyRef != "helper:default@sbomhub.example.com" {
+		t.Fatalf("cfg = %+v, want only a helper reference", cfg)
+	}
+	if data, _ := os.ReadFile(secret); strings.TrimSpace(string(data)) != "sbh_helper_key" {
+		t.Fatalf("helper holds %q, want sbh_helper_key", data)
+	}
+	if err := config.Save(cfg, dir); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(dir, "config.yaml")); strings.Contains(string(raw), "sbh_") {
+		t.Errorf("config.yaml still contains a key:\n%s", raw)
+	}
+
+	got, err := resolveCredentials(dir)
+	if err != nil {
+		t.Fatalf("resolveCredentials() error = %v", err)
+	}
+	if got.APIKey != "sbh_helper_key" {
+		t.Errorf("APIKey = %q, want the helper's key", got.APIKey)
+	}
+
+	// An env key wins without consulting the helper at all.
+	before := readCalls(t, log)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_env_key")
+	got, err = resolveCredentials(dir)
+	if err != nil {
+		t.Fatalf("resolveCredentials() with env key error = %v", err)
+	}
+	if got.APIKey != "sbh_env_key" {
+		t.Errorf("APIKey = %q, want sbh_env_key", got.APIKey)
+	}
+	if after := readCalls(t, log); after != before {
+		t.Errorf("helper was called although SBOMHUB_API_KEY was set: %q -> %q", before, after)
+	}
+}
+
+func TestResolveCredentials_MissingStoredKeyErrors(t *testing.T) {
+	withCleanCredentialEnv(t)
+	script, _, _ := writeTestHelper(t)
+	dir := getConfigDir()
+	if err := config.Save(&config.Config{
+		APIURL:           "https://sbomhub.example.com",
+		APIKeyRef:        "helper:default@sbomhub.example.com",
+		CredentialHelper: script,
+	}, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := resolveCredentials(dir)
+	if err == nil || !strings.Contains(err.Error(), "helper:default@sbomhub.example.com") {
+		t.Fatalf("resolveCredentials() error = %v, want an error naming the api_key_ref", err)
+	}
+}
+
+func TestRunLogout_ErasesStoredKey(t *testing.T) {
+	withCleanCredentialEnv(t)
+	script, secret, _ := writeTestHelper(t)
+	dir := getConfigDir()
+	cfg := &config.Config{APIURL: "https://sbomhub.example.com", CredentialHelper: script}
+	if _, err := storeAPIKey(context.Background(), cfg, "default", "sbh_helper_key", credStoreHelper); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Save(cfg, dir); err != nil {
+		t.Fatal(err)
+	}
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	globalOutput.Writer, globalOutput.ErrWriter = io.Discard, io.Discard
+
+	if err := runLogout(logoutCmd, nil); err != nil {
+		t.Fatalf("runLogout() error = %v", err)
+	}
+	if _, err := os.Stat(secret); !os.IsNotExist(err) {
+		t.Errorf("helper secret still present after logout (stat err = %v)", err)
+	}
+	f, err := config.ReadFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.APIKeyRef != "" || f.CredentialHelper != script {
+		t.Errorf("after logout: api_key_ref = %q, credential_helper = %q; want the ref dropped and the helper kept", f.APIKeyRef, f.CredentialHelper)
+	}
+}
+
+func TestDoctor_ReportsCredentialHelperSource(t *testing.T) {
+	resetCredentialGlobals(t)
+	clearCredentialEnv(t)
+	script, _, _ := writeTestHelper(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := &config.Config{APIURL: srv.URL, CredentialHelper: script}
+	if _, err := storeAPIKey(context.Background(), cfg, "default", "sbh_helper_key", credStoreHelper); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Save(cfg, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	results := doctorChecks(dir, newTestHTTPClient(), false, false)
+
+	ak := findResult(results, "api-key")
+	if ak == nil || ak.status != doctorOK || !strings.Contains(ak.message, "source: credential-helper default@") {
+		t.Errorf("api-key result = %+v, want OK with source: credential-helper", ak)
+	}
+}
+
+// clearCIIdentityEnv hides the runner's own identity token variables, so
+// tests run inside GitHub Actions or GitLab CI do not try to exchange
+// them.
+func clearCIIdentityEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{"SBOMHUB_OIDC_TOKEN_FILE", "SBOMHUB_OIDC_TOKEN", "SBOMHUB_OIDC_AUDIENCE", "SBOMHUB_OIDC_SCOPE",
+		"ACTIONS_ID_TOKEN_REQUEST_URL", "ACTIONS_ID_TOKEN_REQUEST_TOKEN", "CI_JOB_JWT_V2", "CI_JOB_JWT", "GITLAB_CI"} {
+		t.Setenv(k, "")
+	}
+}
+
+// newTokenExchangeServer fakes both sides of keyless CI auth: the GitHub
+// Actions ID token endpoint (/gh-token) and an SBOMHub server that
+// declares token_exchange and whose token endpoint swaps that ID token
+// for "sbh_exchanged", the only key its project list accepts. It points
+// the Actions env vars at itself.
+func newTokenExchangeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newTokenExchangeServerDeclaring(t, `["token_exchange"]`)
+}
+
+// newTokenExchangeServerDeclaring is newTokenExchangeServer with an
+// explicit capabilities feature list.
+func newTokenExchangeServerDeclaring(t *testing.T, features string) *httptest.Server {
+	t.Helper()
+	t.Setenv("SBOMHUB_CACHE_DIR", t.TempDir())
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version":"1.0.0","features":%s}`, features)
+	})
+	mux.HandleFunc("GET /gh-token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer runtime-token" || r.URL.Query().Get("audience") != srv.URL {
+			http.Error(w, "bad runtime token or audience", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"value":"gh-id-token"}`)
+	})
+	mux.HandleFunc("GET /.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issuer":%q,"token_endpoint":%q}`, srv.URL, srv.URL+"/oauth/token")
+	})
+	mux.HandleFunc("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("grant_type") != "urn:ietf:params:oauth:grant-type:token-exchange" ||
+			r.PostForm.Get("subject_token") != "gh-id-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"sbh_exchanged","token_type":"Bearer","expires_in":900}`)
+	})
+	mux.HandleFunc("GET /api/v1/cli/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sbh_exchanged" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", srv.URL+"/gh-token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "runtime-token")
+	return srv
+}
+
+func TestNewAPIClient_ExchangesCIIdentityOnFirstCall(t *testing.T) {
+	withCleanCredentialEnv(t)
+	srv := newTokenExchangeServer(t)
+	t.Setenv("SBOMHUB_API_URL", srv.URL)
+
+	cfg, err := resolveCredentials(getConfigDir())
+	if err != nil {
+		t.Fatalf("resolveCredentials() error = %v", err)
+	}
+	if cfg.APIKey != "" || !hasCredentials(cfg) {
+		t.Fatalf("APIKey = %q, hasCredentials = %v; want no key yet, exchangeable", cfg.APIKey, hasCredentials(cfg))
+	}
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		t.Fatalf("newAPIClient() error = %v", err)
+	}
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Errorf("ListProjects() with the exchanged token = %v", err)
+	}
+
+	// A static key still wins, and then nothing is exchanged.
+	t.Setenv("SBOMHUB_API_KEY", "sbh_static")
+	if cfg, err = resolveCredentials(getConfigDir()); err != nil || cfg.APIKey != "sbh_static" || ciIdentityProvider(cfg) != "" {
+		t.Errorf("with SBOMHUB_API_KEY: APIKey = %q, err = %v", cfg.APIKey, err)
+	}
+}
+
+func TestCIIdentity_NotExchangedForDefaultAPIURL(t *testing.T) {
+	withCleanCredentialEnv(t)
+	newTokenExchangeServer(t)
+
+	cfg, err := resolveCredentials(getConfigDir())
+	if err != nil {
+		t.Fatalf("resolveCredentials() error = %v", err)
+	}
+	if cfg.APIURL != config.DefaultAPIURL || hasCredentials(cfg) {
+		t.Errorf("APIURL = %q, hasCredentials = %v; want the default URL and no credentials", cfg.APIURL, hasCredentials(cfg))
+	}
+}
+
+func TestCIIdentity_ExchangeFailureIsReportedOnFirstCall(t *testing.T) {
+	withCleanCredentialEnv(t)
+	srv := newTokenExchangeServer(t)
+	t.Setenv("SBOMHUB_API_URL", srv.URL)
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "wrong")
+
+	// Resolving and building the client touch nothing; only the first
+	// authenticated call asks for the ID token.
+	cfg, err := resolveCredentials(getConfigDir())
+	if err != nil {
+		t.Fatalf("resolveCredentials() error = %v, want the exchange deferred", err)
+	}
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		t.Fatalf("newAPIClient() error = %v", err)
+	}
+	_, err = client.ListProjects(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "GitHub Actions") {
+		t.Fatalf("ListProjects() error = %v, want the ID token failure", err)
+	}
+}
+
+func TestCIIdentity_NotExchangedUnlessDeclared(t *testing.T) {
+	withCleanCredentialEnv(t)
+	srv := newTokenExchangeServerDeclaring(t, `["sbom_upload"]`)
+	t.Setenv("SBOMHUB_API_URL", srv.URL)
+	// A wrong runtime token makes any attempt to mint an ID token fail
+	// with a GitHub Actions error; the gate must stop before that.
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "wrong")
+
+	cfg, err := resolveCredentials(getConfigDir())
+	if err != nil {
+		t.Fatalf("resolveCredentials() error = %v", err)
+	}
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		t.Fatalf("newAPIClient() error = %v", err)
+	}
+	_, err = client.ListProjects(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "token_exchange") || strings.Contains(err.Error(), "GitHub Actions") {
+		t.Fatalf("ListProjects() error = %v, want the undeclared token_exchange reported before minting", err)
+	}
+}
+
+func TestDoctor_ReportsCIIdentitySource(t *testing.T) {
+	resetCredentialGlobals(t)
+	clearCredentialEnv(t)
+	srv := newTokenExchangeServer(t)
+	t.Setenv("SBOMHUB_API_URL", srv.URL)
+
+	results := doctorChecks(t.TempDir(), newTestHTTPClient(), false, false)
+
+	ak := findResult(results, "api-key")
+	if ak == nil || ak.status != doctorOK || !strings.Contains(ak.message, "ci-oidc github-actions") {
+		t.Errorf("api-key result = %+v, want OK with source: ci-oidc github-actions", ak)
+	}
+}
+
+func TestDoctor_ReportsCIExchangeFailureAgainstAPIKey(t *testing.T) {
+	resetCredentialGlobals(t)
+	clearCredentialEnv(t)
+	srv := newTokenExchangeServer(t)
+	t.Setenv("SBOMHUB_API_URL", srv.URL)
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "wrong")
+
+	results := doctorChecks(t.TempDir(), newTestHTTPClient(), false, false)
+
+	if cr := findResult(results, "credentials"); cr != nil {
+		t.Errorf("credentials result = %+v, want none (resolution no longer exchanges)", cr)
+	}
+	ak := findResult(results, "api-key")
+	if ak == nil || ak.status != doctorFail || !strings.Contains(ak.message, "ci-oidc github-actions") {
+		t.Errorf("api-key result = %+v, want FAIL naming the ci-oidc source", ak)
+	}
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/internal/mockserver"
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "開発・テスト用のツール",
+	Long: `CLI の開発や CI テンプレートの検証に使うツールです。
+本番のサーバやデータには一切接続しません。`,
+}
+
+var devMockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "インメモリのモック SBOMHub サーバを起動",
+	Long: `SBOMHub API のモックサーバをローカルで起動します。
+
+projects / SBOM アップロード / scan-status / check / vulnerabilities /
+triage・VEX ドラフト / CRA レポート / METI / health を実装し、 状態は
+メモリ上にのみ保持します (停止すると消えます)。 脆弱性は log4j-core 2.14.1 /
+lodash 4.17.20 など固定のアドバイザリと照合し、 triage / CRA は LLM を
+呼ばずに定型のドラフトを返します。 CI テンプレートや CLI の E2E テストを
+オフラインで実行するためのもので、 Ctrl+C (SIGINT / SIGTERM) で停止します
+(exit 0)。
+
+--fault で障害を注入できます (複数指定可、 先に指定したものが優先):
+  429                                       すべてのリクエストに 429
+  status=429,path=/api/v1/cli/check,times=2 check の最初の 2 回だけ 429
+  status=503,retry-after=5s                 Retry-After 付きの 503
+  ai-disabled,path=/api/v1/projects/        旧サーバの AI 無効 503
+  delay=3s,method=GET                       GET を 3 秒遅延 (応答は正常)
+
+--id-token を指定すると、 その値を CI の ID トークンとして受け付けて --api-key
+と交換するトークン発行エンドポイント (/oauth/token) も提供します。 キー無しの
+CI 認証 (SBOMHUB_OIDC_TOKEN など) をオフラインで試すためのものです。
+
+使用例:
+  sbomhub dev mock-server --seed
+  sbomhub dev mock-server --listen 127.0.0.1:0 --api-key sbh_test --json
+  SBOMHUB_API_URL=http://127.0.0.1:8080 SBOMHUB_API_KEY=sbh_test sbomhub scan . --fail-on critical
+  sbomhub dev mock-server --api-key sbh_test --id-token fake-ci-jwt
+  SBOMHUB_API_URL=http://127.0.0.1:8080 SBOMHUB_OIDC_TOKEN=fake-ci-jwt sbomhub projects list`,
+	Args: cobra.NoArgs,
+	RunE: runDevMockServer,
+}
+
+var (
+	devMockListen       string
+	devMockAPIKey       string
+	devMockSeed         bool
+	devMockScanDuration time.Duration
+	devMockAIDisabled   bool
+	devMockFaults       []string
+	devMockIDToken      string
+)
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+	devCmd.AddCommand(devMockServerCmd)
+	devMockServerCmd.Flags().StringVar(&devMockListen, "listen", "127.0.0.1:8080", "待ち受けアドレス (ポート 0 で空きポートを自動選択)")
+	devMockServerCmd.Flags().StringVar(&devMockAPIKey, "api-key", "", "受け付ける API キー (未指定なら認証なし)")
+	devMockServerCmd.Flags().BoolVar(&devMockSeed, "seed", false, "脆弱性を含む SBOM をアップロード済みの demo プロジェクトを作成")
+	devMockServerCmd.Flags().DurationVar(&devMockScanDuration, "scan-duration", 0, "アップロード後に scan-status が running を返す時間")
+	devMockServerCmd.Flags().BoolVar(&devMockAIDisabled, "ai-disabled", false, "BYOK 未設定のサーバとして triage / CRA に ai_disabled=true を返す")
+	devMockServerCmd.Flags().StringVar(&devMockIDToken, "id-token", "", "API キーと交換できる CI の ID トークン (指定するとトークン発行エンドポイントを有効化)")
+	devMockServerCmd.Flags().StringArrayVar(&devMockFaults, "fault", nil, "障害注入の指定 (例: status=429,path=/api/v1/cli/check,times=2)。 複数指定可")
+}
+
+// devMockServerInfo is the `dev mock-server --json` line printed once the
+// listener is up, so scripts can start the server with --listen :0 and
+// read back where it went.
+type devMockServerInfo struct {
+	URL    string   `json:"url"`
+	APIKey string   `json:"api_key,omitempty"`
+	Seed   bool     `json:"seed"`
+	Faults []string `json:"faults"`
+}
+
+func runDevMockServer(cmd *cobra.Command, args []string) error {
+	out := GetOutputConfig()
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	srv := mockserver.New(mockserver.Options{
+		APIKey:       devMockAPIKey,
+		ScanDuration: devMockScanDuration,
+		AIDisabled:   devMockAIDisabled,
+		Seed:         devMockSeed,
+		IDToken:      devMockIDToken,
+	})
+	for _, spec := range devMockFaults {
+		f, err := mockserver.ParseFault(spec)
+		if err != nil {
+			return fmt.Errorf("--fault: %w", err)
+		}
+		srv.Inject(f)
+	}
+
+	ln, err := net.Listen("tcp", devMockListen)
+	if err != nil {
+		return fmt.Errorf("%s で待ち受けできません: %w", devMockListen, err)
+	}
+	url := "http://" + ln.Addr().String()
+	info := devMockServerInfo{URL: url, APIKey: devMockAPIKey, Seed: devMockSeed, Faults: devMockFaults}
+	if info.Faults == nil {
+		info.Faults = []string{}
+	}
+	if err := out.PrintResult(info, func() {
+		w := out.humanWriter()
+		fmt.Fprintf(w, "🧪 モック SBOMHub サーバを起動しました: %s\n", url)
+		if devMockAPIKey != "" {
+			fmt.Fprintf(w, "   API キー: %s\n", devMockAPIKey)
+		}
+		for _, spec := range devMockFaults {
+			fmt.Fprintf(w, "   障害注入: %s\n", spec)
+		}
+		fmt.Fprintf(w, "   例: SBOMHUB_API_URL=%s sbomhub projects list\n", url)
+		fmt.Fprintln(w, "   Ctrl+C で停止します")
+	}); err != nil {
+		ln.Close()
+		return err
+	}
+
+	httpServer := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+	served := make(chan error, 1)
+	go func() { served <- httpServer.Serve(ln) }()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("モックサーバが停止しました: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("モックサーバの停止に失敗しました: %w", err)
+	}
+	out.PrintVerbose("モックサーバを停止しました (%d リクエスト処理)", len(srv.Requests()))
+	return nil
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/mockserver"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// lockedBuffer lets the test read what a command goroutine is writing.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func TestDevMockServer_ServesUntilCancelled(t *testing.T) {
+	saved := struct {
+		listen, key string
+		seed        bool
+		faults      []string
+		out         OutputConfig
+	}{devMockListen, devMockAPIKey, devMockSeed, devMockFaults, *globalOutput}
+	t.Cleanup(func() {
+		devMockListen, devMockAPIKey, devMockSeed, devMockFaults = saved.listen, saved.key, saved.seed, saved.faults
+		*globalOutput = saved.out
+		devMockServerCmd.SetContext(nil)
+	})
+	devMockListen, devMockAPIKey, devMockSeed = "127.0.0.1:0", "sbh_dev", true
+	devMockFaults = []string{"status=503,path=/api/v1/cli/projects,times=1"}
+	var stdout lockedBuffer
+	globalOutput.Writer, globalOutput.ErrWriter, globalOutput.JSON = &stdout, io.Discard, true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	devMockServerCmd.SetContext(ctx)
+	done := make(chan error, 1)
+	go func() { done <- runDevMockServer(devMockServerCmd, nil) }()
+
+	var info devMockServerInfo
+	deadline := time.Now().Add(5 * time.Second)
+	for json.Unmarshal(stdout.Bytes(), &info) != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("mock-server never printed its address: %q", stdout.Bytes())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	client := sbomhub.NewClient(info.URL, "sbh_dev",
+		sbomhub.WithRetryPolicy(sbomhub.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	projects, err := client.ListProjects(context.Background())
+	if err != nil || len(projects) != 1 || projects[0].Name != "demo" {
+		t.Errorf("ListProjects() through one injected 503 = %+v, %v; want the seeded demo project", projects, err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("runDevMockServer() after cancel = %v, want nil (exit 0)", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("mock-server did not stop on cancel")
+	}
+}
+
+func TestDevMockServer_RejectsBadFault(t *testing.T) {
+	saved := devMockFaults
+	t.Cleanup(func() { devMockFaults = saved })
+	devMockFaults = []string{"path=/only"}
+	if err := runDevMockServer(devMockServerCmd, nil); err == nil {
+		t.Fatal("a fault without status / ai-disabled / delay should be rejected")
+	}
+}
+
+// TestRunScan_EndToEndAgainstMockServer runs the scan gate against the
+// mock instead of a hand-written handler: upload, capabilities, scan
+// status and the VEX lookup all go through the real client.
+func TestRunScan_EndToEndAgainstMockServer(t *testing.T) {
+	fakeSyft(t, `{"bomFormat":"CycloneDX","specVersion":"1.5","components":[{"name":"log4j-core","version":"2.14.1"},{"name":"express","version":"4.18.2"}]}`)
+	mock := mockserver.New(mockserver.Options{APIKey: "sbh_test"})
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	setupStreamingScan(t, server.URL)
+	savedFailOn, savedPoll := scanFailOn, scanPollInterval
+	t.Cleanup(func() { scanFailOn, scanPollInterval = savedFailOn, savedPoll })
+	scanProject, scanWaitForScan, scanFailOn, scanPollInterval = "e2e", true, "critical", 10*time.Millisecond
+
+	err := runScan(scanCmd, []string{t.TempDir()})
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitThresholdExceeded {
+		t.Fatalf("runScan() = %v, want exit %d for the log4j finding", err, exitThresholdExceeded)
+	}
+
+	var uploads, encoded int
+	for _, r := range mock.Requests() {
+		if r.Method == http.MethodPost && r.IdempotencyKey != "" {
+			uploads++
+			if r.Encoding == "gzip" {
+				encoded++
+			}
+		}
+	}
+	if uploads != 1 || encoded != 1 {
+		t.Errorf("uploads = %d (gzip %d), want one gzip upload negotiated from capabilities", uploads, encoded)
+	}
+}
@@ -8,9 +8,14 @@ package commands
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -19,7 +24,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/youichi-uda/sbomhub-cli/internal/config"
-	"gopkg.in/yaml.v3"
+	"github.com/youichi-uda/sbomhub-cli/internal/credential"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // apiKeyPrefix mirrors the server-side constant in
@@ -77,8 +83,8 @@ var doctorCmd = &cobra.Command{
 	Short: "CLI 環境のセルフチェック",
 	Long: `sbomhub doctor は CLI の動作環境を診断します。
 
-設定ファイル / API キー / API URL / API 到達性 / 認証 / scanner 検出 を
-順にチェックし、 [OK] / [WARN] / [FAIL] で 1 行ずつ報告します。
+設定ファイル / プロファイル / API キー / API URL / TLS (証明書チェーン・有効期限) /
+API 到達性 / 認証 / サーバとのバージョン互換性 / scanner 検出 を順にチェックし、 [OK] / [WARN] / [FAIL] で 1 行ずつ報告します。
 [FAIL] が 1 つでもあれば exit 1 を返します。
 
   sbomhub doctor                # 通常実行
@@ -132,15 +138,10 @@ func doctorConfigDir() string {
 	return defaultConfigDir()
 }
 
-// defaultConfigDir mirrors the lookup used by loadConfigAndClient in
-// projects.go so `doctor` reports against the same path that real commands
-// will read.
+// defaultConfigDir is the user config directory real commands use
+// (getConfigDir), so `doctor` reports against the same files they read.
 func defaultConfigDir() string {
-	dir := filepath.Join(os.Getenv("HOME"), ".sbomhub")
-	if os.Getenv("USERPROFILE") != "" {
-		dir = filepath.Join(os.Getenv("USERPROFILE"), ".sbomhub")
-	}
-	return dir
+	return getConfigDir()
 }
 
 // runDoctorWith is the testable seam: it takes an explicit config dir and HTTP
@@ -189,54 +190,68 @@ func doctorChecks(configDir string, client *http.Client, apiKeyFromFlag, apiURLF
 	// --api-key) are equally valid credential sources (Trust Rescue
 	// R2-2e / R9-9b: resolveCredentials is the canonical merge). Without
 	// this downgrade, `SBOMHUB_API_KEY=... sbomhub doctor` in a clean CI
-	// runner always [FAIL]s before reaching the real checks.
+	// runner always [FAIL]s before reaching the real checks. Every layer
+	// found (system, user, XDG) is listed, since an administrator's
+	// /etc/sbomhub/config.yaml is otherwise an invisible source of the
+	// server URL or CA bundle.
 	configPath := filepath.Join(configDir, "config.yaml")
-	configFileExists := false
-	if _, err := os.Stat(configPath); err == nil {
-		configFileExists = true
-		results = append(results, doctorResult{
-			name:    "config-file",
-			status:  doctorOK,
-			message: fmt.Sprintf("設定ファイル存在 (%s)", configPath),
+	stack, err := loadConfigStack(configDir)
+	if err != nil {
+		// A file exists but is unreadable or malformed; downstream
+		// merges will also fail. Surface the error and stop.
+		return append(results, doctorResult{
+			name:    "config-parse",
+			status:  doctorFail,
+			message: err.Error(),
 		})
-	} else if os.IsNotExist(err) {
+	}
+	if len(stack.Layers) == 0 {
 		results = append(results, doctorResult{
 			name:    "config-file",
 			status:  doctorOK,
 			message: fmt.Sprintf("設定ファイル無し (%s) — env / flag 経路で動作", configPath),
 		})
 	} else {
-		// stat returned something other than ENOENT (permission denied,
-		// I/O error, ...) — bail out, the rest is undefined.
-		return append(results, doctorResult{
+		paths := make([]string, len(stack.Layers))
+		for i, l := range stack.Layers {
+			paths[i] = fmt.Sprintf("%s [%s]", l.Path, l.Scope)
+		}
+		results = append(results, doctorResult{
 			name:    "config-file",
-			status:  doctorFail,
-			message: fmt.Sprintf("設定ファイル参照エラー (%s): %v", configPath, err),
+			status:  doctorOK,
+			message: fmt.Sprintf("設定ファイル存在 (%s)", strings.Join(paths, ", ")),
 		})
 	}
 
-	// Raw file values (no DefaultAPIURL injection) for source attribution.
-	// config.Load() rewrites an empty api_url to DefaultAPIURL, which would
-	// make us mislabel "default" as "config"; reading the file directly
-	// preserves the distinction.
-	var fileAPIURL, fileAPIKey string
-	if configFileExists {
-		if data, readErr := os.ReadFile(configPath); readErr == nil {
-			var raw config.Config
-			if yamlErr := yaml.Unmarshal(data, &raw); yamlErr != nil {
-				// File exists but is malformed; downstream merges will
-				// also fail. Surface the parse error and stop.
-				return append(results, doctorResult{
-					name:    "config-parse",
-					status:  doctorFail,
-					message: fmt.Sprintf("設定ファイルの解析に失敗しました: %v", yamlErr),
-				})
-			}
-			fileAPIURL = raw.APIURL
-			fileAPIKey = raw.APIKey
-		}
+	// Raw merged values (no DefaultAPIURL injection) for source
+	// attribution. LoadProfile rewrites an empty api_url to DefaultAPIURL,
+	// which would make us mislabel "default" as "config"; the merged File
+	// leaves the profile as written and preserves the distinction.
+	profile, profileSource := activeProfile()
+	f := stack.File()
+	profileName := f.ResolveName(profile)
+	var fileAPIURL, fileAPIKey, fileAPIKeyRef string
+	if raw, profErr := f.Profile(profileName); profErr == nil {
+		fileAPIURL = raw.APIURL
+		fileAPIKey = raw.APIKey
+		fileAPIKeyRef = raw.APIKeyRef
 	}
 
+	// 1b. Profile. Commands resolve credentials from exactly one profile,
+	// so name it — "wrong profile" is the usual cause of "wrong tenant".
+	if _, err := stack.LoadProfile(profile); err != nil {
+		return append(results, doctorResult{
+			name:    "profile",
+			status:  doctorFail,
+			message: fmt.Sprintf("%v (source: %s)", err, profileSourceLabel(profileSource)),
+		})
+	}
+	results = append(results, doctorResult{
+		name:    "profile",
+		status:  doctorOK,
+		message: fmt.Sprintf("プロファイル %s (source: %s)", profileName, profileSourceLabel(profileSource)),
+	})
+
 	// Resolve credentials through the same path real commands use, so
 	// what doctor reports is exactly what e.g. `scan` / `projects` would
 	// see (flag > env > file > default).
@@ -249,18 +264,86 @@ func doctorChecks(configDir string, client *http.Client, apiKeyFromFlag, apiURLF
 		})
 	}
 
+	// The probes below must dial exactly like the real commands do: same
+	// CA bundle, client certificate and proxy. A broken TLS / proxy
+	// setting is fatal here because every later probe would otherwise
+	// silently use Go's defaults and report something the real commands
+	// never see.
+	if err := configureHTTPClient(client, cfg); err != nil {
+		return append(results, doctorResult{
+			name:    "tls-config",
+			status:  doctorFail,
+			message: err.Error(),
+		})
+	}
+
 	envKey := os.Getenv("SBOMHUB_API_KEY")
 	envURL := os.Getenv("SBOMHUB_API_URL")
 
 	// 2. API key (prefix sanity check — the real auth probe is #5).
 	keySource := doctorCredSource(apiKeyFromFlag, envKey, fileAPIKey, "", cfg.APIKey)
+	if fileAPIKey == "" && fileAPIKeyRef != "" && !apiKeyFromFlag && envKey == "" && cfg.APIKey != "" {
+		// resolveCredentials read the key through api_key_ref. Name the
+		// store and the entry so a stale keychain item is easy to find.
+		keySource = doctorStoreSource(fileAPIKeyRef)
+	}
+	deviceLogin := fileAPIKey == "" && fileAPIKeyRef == "" && !apiKeyFromFlag && envKey == "" &&
+		cfg.APIKey != "" && cfg.APIKey == cfg.AccessToken
+	// With no key at all, a CI job's identity token is the only way to
+	// one. Real commands exchange it on their first authenticated call;
+	// doctor does so here, so auth-verify below can use the result and a
+	// failed exchange is reported against the key rather than aborting.
+	ciProvider := ciIdentityProvider(cfg)
+	var ciErr error
+	if ciProvider != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorHTTPTimeout)
+		tok, err := exchangeCIIdentity(ctx, cfg)
+		cancel()
+		if err != nil {
+			ciErr = err
+		} else {
+			cfg.APIKey = tok.AccessToken
+		}
+	}
 	switch {
+	case ciErr != nil:
+		results = append(results, doctorResult{
+			name:    "api-key",
+			status:  doctorFail,
+			message: fmt.Sprintf("%v (source: ci-oidc %s)", ciErr, ciProvider),
+		})
 	case cfg.APIKey == "":
 		results = append(results, doctorResult{
 			name:    "api-key",
 			status:  doctorFail,
 			message: "api_key が未設定です — SBOMHUB_API_KEY env / --api-key flag / `sbomhub login` のいずれかが必要",
 		})
+	case deviceLogin:
+		// `login --device` tokens are not sbh_ keys; the prefix check does
+		// not apply. An expired access token is fine as long as the
+		// refresh token works, which real commands do on their own.
+		source := "device-login"
+		if cfg.TokenRef != "" {
+			source += ", " + doctorStoreSource(cfg.TokenRef)
+		}
+		expiry := "有効期限なし"
+		if !cfg.TokenExpiry.IsZero() {
+			expiry = "有効期限 " + cfg.TokenExpiry.Local().Format(time.RFC3339)
+			if time.Now().After(cfg.TokenExpiry) {
+				expiry += " (期限切れ — 次のコマンド実行時に自動更新)"
+			}
+		}
+		results = append(results, doctorResult{
+			name:    "api-key",
+			status:  doctorOK,
+			message: fmt.Sprintf("ログイントークン設定済み (source: %s, %s)", source, expiry),
+		})
+	case ciProvider != "":
+		results = append(results, doctorResult{
+			name:    "api-key",
+			status:  doctorOK,
+			message: fmt.Sprintf("CI の ID トークンを API トークンに交換しました (source: ci-oidc %s)", ciProvider),
+		})
 	case !strings.HasPrefix(cfg.APIKey, doctorAPIKeyPrefix):
 		results = append(results, doctorResult{
 			name: "api-key",
@@ -304,7 +387,14 @@ func doctorChecks(configDir string, client *http.Client, apiKeyFromFlag, apiURLF
 		})
 	}
 
-	// 4. API reachability via /api/v1/health (public, no auth needed). See
+	// 4. TLS: chain and leaf expiry of the API endpoint. Runs before the
+	// reachability probe so an internal-CA failure is reported with a
+	// --ca-cert hint instead of a bare "x509: unknown authority".
+	if cfg.APIURL != "" {
+		results = append(results, doctorTLSCheck(client, cfg))
+	}
+
+	// 5. API reachability via /api/v1/health (public, no auth needed). See
 	// sbomhub/apps/api/cmd/server/main.go where it is wired as the only
 	// no-auth GET under the /api/v1 group.
 	if cfg.APIURL != "" {
@@ -341,7 +431,7 @@ func doctorChecks(configDir string, client *http.Client, apiKeyFromFlag, apiURLF
 		}
 	}
 
-	// 5. Auth verify against a tenant-scoped endpoint the CLI actually uses.
+	// 6. Auth verify against a tenant-scoped endpoint the CLI actually uses.
 	// Successful 200 here proves the api_key resolves to a tenant and the
 	// MultiAuth middleware accepts it (apps/api/internal/middleware/multiauth.go).
 	if cfg.APIURL != "" && cfg.APIKey != "" {
@@ -391,7 +481,15 @@ func doctorChecks(configDir string, client *http.Client, apiKeyFromFlag, apiURLF
 		}
 	}
 
-	// 6. Scanner binaries. Missing all 3 is WARN, not FAIL: `sbomhub scan`
+	// 7. Server version / capabilities and CLI compatibility. Public
+	// endpoint like /health, so it runs without an api_key too. Servers
+	// that predate the document are [OK] with a note: nothing is known to
+	// be incompatible, the CLI just cannot tell in advance.
+	if cfg.APIURL != "" {
+		results = append(results, doctorCompatCheck(cfg))
+	}
+
+	// 8. Scanner binaries. Missing all 3 is WARN, not FAIL: `sbomhub scan`
 	// breaks but uploading an existing SBOM keeps working.
 	var found, missing []string
 	for _, s := range doctorScanners {
@@ -421,6 +519,162 @@ func doctorChecks(configDir string, client *http.Client, apiKeyFromFlag, apiURLF
 	return results
 }
 
+// doctorCompatCheck reports the server version and whether this CLI
+// build can use it: [FAIL] when the server's min_cli_version is newer
+// than this build, [WARN] when it declares it lacks features some
+// commands need.
+func doctorCompatCheck(cfg *config.Config) doctorResult {
+	// One attempt: doctor is a smoke test, and reachability was already
+	// reported above.
+	client, err := buildAPIClient(cfg, sbomhub.WithRetryPolicy(sbomhub.NoRetry))
+	if err != nil {
+		return doctorResult{name: "server-compat", status: doctorFail, message: err.Error()}
+	}
+	caps, err := fetchCapabilities(context.Background(), client)
+	if err != nil {
+		return doctorResult{
+			name:    "server-compat",
+			status:  doctorWarn,
+			message: fmt.Sprintf("サーバ capabilities を取得できません — 互換性は未確認: %v", err),
+		}
+	}
+	if !caps.Published {
+		return doctorResult{
+			name:    "server-compat",
+			status:  doctorOK,
+			message: "サーバは capabilities を公開していません (旧バージョン) — 互換性は事前確認できないため、 各コマンドは従来どおり動作します (" + strings.Join(optInFeatures, ", ") + " は無効)",
+		}
+	}
+
+	compat := assessCompat(caps, version)
+	// The legacy multipart upload is listed for the sunset bookkeeping
+	// only; the CLI itself no longer calls it.
+	detail := fmt.Sprintf("features=%s min_cli_version=%s %s=%s opt-in=%s",
+		strings.Join(caps.Features, ","), orNA(caps.MinCLIVersion),
+		sbomhub.FeatureLegacyUpload, featureState(caps, sbomhub.FeatureLegacyUpload), optInState(caps))
+	switch {
+	case compat.CLITooOld:
+		return doctorResult{
+			name:    "server-compat",
+			status:  doctorFail,
+			message: fmt.Sprintf("CLI %s はサーバ %s の要求する最小バージョン %s より古いです — CLI を更新してください", version, orNA(caps.Version), caps.MinCLIVersion),
+			detail:  detail,
+		}
+	case len(compat.Unsupported) > 0:
+		return doctorResult{
+			name:    "server-compat",
+			status:  doctorWarn,
+			message: fmt.Sprintf("サーバ %s は次のコマンドに対応していません (サーバが古い): %s", orNA(caps.Version), strings.Join(compat.Unsupported, ", ")),
+			detail:  detail,
+		}
+	default:
+		return doctorResult{
+			name:    "server-compat",
+			status:  doctorOK,
+			message: fmt.Sprintf("サーバ %s と互換 (CLI %s)", orNA(caps.Version), version),
+			detail:  detail,
+		}
+	}
+}
+
+// doctorTLSExpiryWarn is how close to NotAfter the leaf certificate may get
+// before `doctor` warns. Two weeks leaves time for a manual renewal on
+// gateways that are not covered by ACME automation.
+const doctorTLSExpiryWarn = 14 * 24 * time.Hour
+
+// doctorTLSCheck reports the TLS posture of cfg.APIURL: the negotiated
+// version, the certificate chain (in the verbose detail) and the leaf
+// expiry. Plain http:// is only acceptable on loopback — anywhere else the
+// API key crosses the network in clear text.
+func doctorTLSCheck(client *http.Client, cfg *config.Config) doctorResult {
+	u, err := url.Parse(cfg.APIURL)
+	if err != nil {
+		return doctorResult{name: "tls", status: doctorFail, message: fmt.Sprintf("api_url を解析できません: %v", err)}
+	}
+	if u.Scheme != "https" {
+		if doctorIsLoopback(u.Hostname()) {
+			return doctorResult{name: "tls", status: doctorOK, message: "TLS 無し (loopback への平文 HTTP)"}
+		}
+		return doctorResult{
+			name:    "tls",
+			status:  doctorWarn,
+			message: fmt.Sprintf("api_url が平文 HTTP です (%s) — API Key がネットワーク上を暗号化されずに流れます", u.Host),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorHTTPTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(cfg.APIURL, "/")+"/api/v1/health", nil)
+	if err != nil {
+		return doctorResult{name: "tls", status: doctorFail, message: fmt.Sprintf("TLS 診断リクエストを作成できません: %v", err)}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if doctorIsCertError(err) {
+			return doctorResult{
+				name:    "tls",
+				status:  doctorFail,
+				message: fmt.Sprintf("サーバ証明書を検証できません: %v — 社内 CA の場合は --ca-cert / ca_cert で CA 証明書を指定してください", err),
+			}
+		}
+		// Not a certificate problem (refused, DNS, timeout, proxy): the
+		// reachability check right after reports it as [FAIL].
+		return doctorResult{name: "tls", status: doctorWarn, message: fmt.Sprintf("TLS 診断をスキップしました (接続失敗: %v)", err)}
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return doctorResult{name: "tls", status: doctorWarn, message: "TLS 接続情報を取得できませんでした"}
+	}
+
+	chain := resp.TLS.PeerCertificates
+	if len(resp.TLS.VerifiedChains) > 0 {
+		chain = resp.TLS.VerifiedChains[0]
+	}
+	var links []string
+	for i, c := range chain {
+		links = append(links, fmt.Sprintf("[%d] %s (issuer: %s, 期限: %s)",
+			i, c.Subject.String(), c.Issuer.String(), c.NotAfter.UTC().Format("2006-01-02")))
+	}
+	detail := "chain: " + strings.Join(links, " ← ")
+
+	leaf := chain[0]
+	remaining := time.Until(leaf.NotAfter)
+	summary := fmt.Sprintf("%s, %s, 有効期限 %s (残り %d 日)",
+		tls.VersionName(resp.TLS.Version), leaf.Subject.CommonName,
+		leaf.NotAfter.UTC().Format("2006-01-02"), int(remaining.Hours()/24))
+
+	switch {
+	case remaining <= 0:
+		return doctorResult{name: "tls", status: doctorFail, message: "サーバ証明書の有効期限が切れています: " + summary, detail: detail}
+	case cfg.InsecureSkipVerify:
+		return doctorResult{name: "tls", status: doctorWarn, message: "insecure_skip_verify によりサーバ証明書を検証していません: " + summary, detail: detail}
+	case remaining < doctorTLSExpiryWarn:
+		return doctorResult{name: "tls", status: doctorWarn, message: "サーバ証明書の有効期限が近づいています: " + summary, detail: detail}
+	default:
+		return doctorResult{name: "tls", status: doctorOK, message: "TLS OK: " + summary, detail: detail}
+	}
+}
+
+// doctorIsCertError reports whether err is a server certificate
+// verification failure (unknown authority, hostname mismatch, expired).
+func doctorIsCertError(err error) bool {
+	var verr *tls.CertificateVerificationError
+	var uerr x509.UnknownAuthorityError
+	var herr x509.HostnameError
+	var ierr x509.CertificateInvalidError
+	return errors.As(err, &verr) || errors.As(err, &uerr) || errors.As(err, &herr) || errors.As(err, &ierr)
+}
+
+// doctorIsLoopback reports whether host is localhost or a loopback IP.
+func doctorIsLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // doctorGet performs a single bounded GET, capping body reads so a hostile or
 // misconfigured upstream cannot make `doctor` hang or eat memory.
 func doctorGet(client *http.Client, url, bearer string) (int, string, error) {
@@ -445,6 +699,19 @@ func doctorGet(client *http.Client, url, bearer string) (int, string, error) {
 	return resp.StatusCode, string(body), nil
 }
 
+// doctorStoreSource labels a key read through api_key_ref: "keyring" or
+// "credential-helper", with the account it is filed under.
+func doctorStoreSource(ref string) string {
+	r, err := credential.ParseRef(ref)
+	if err != nil {
+		return ref
+	}
+	if r.Backend == credential.BackendHelper {
+		return "credential-helper " + r.Account
+	}
+	return "keyring " + r.Account
+}
+
 // doctorCredSource reports which precedence layer ultimately supplied the
 // credential, mirroring resolveCredentials (flag > env > config > default).
 // defaultVal is the built-in fallback (e.g. config.DefaultAPIURL for api_url;
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bytes"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -52,10 +53,11 @@ func newTestHTTPClient() *http.Client {
 // would otherwise leak across the suite.
 func resetCredentialGlobals(t *testing.T) {
 	t.Helper()
-	prevKey, prevURL := apiKey, apiURL
+	prevKey, prevURL, prevProfile := apiKey, apiURL, profileFlag
 	t.Cleanup(func() {
 		apiKey = prevKey
 		apiURL = prevURL
+		profileFlag = prevProfile
 	})
 }
 
@@ -67,6 +69,11 @@ func clearCredentialEnv(t *testing.T) {
 	t.Helper()
 	t.Setenv("SBOMHUB_API_KEY", "")
 	t.Setenv("SBOMHUB_API_URL", "")
+	t.Setenv("SBOMHUB_PROFILE", "")
+	clearCIIdentityEnv(t)
+	for _, k := range []string{"SBOMHUB_CA_CERT", "SBOMHUB_CLIENT_CERT", "SBOMHUB_CLIENT_KEY", "SBOMHUB_PROXY", "SBOMHUB_NO_PROXY", "SBOMHUB_INSECURE_SKIP_VERIFY"} {
+		t.Setenv(k, "")
+	}
 }
 
 func TestDoctor_NoConfig(t *testing.T) {
@@ -192,7 +199,7 @@ func TestDoctor_FullyOK(t *testing.T) {
 			t.Errorf("unexpected FAIL on %s: %s", r.name, r.message)
 		}
 	}
-	for _, name := range []string{"config-file", "api-key", "api-url", "api-reachability", "auth-verify"} {
+	for _, name := range []string{"config-file", "api-key", "api-url", "tls", "api-reachability", "auth-verify"} {
 		got := findResult(results, name)
 		if got == nil {
 			t.Errorf("expected result %q in output", name)
@@ -220,6 +227,65 @@ func TestDoctor_FullyOK(t *testing.T) {
 	}
 }
 
+// TestDoctor_TLSInternalCA covers the self-host-behind-internal-CA shape:
+// without ca_cert the TLS check fails with a --ca-cert hint; with a
+// relative ca_cert in config.yaml (resolved against the config dir) every
+// probe, including auth-verify, goes through and the chain is reported.
+func TestDoctor_TLSInternalCA(t *testing.T) {
+	dir := t.TempDir()
+	resetCredentialGlobals(t)
+	clearCredentialEnv(t)
+	const validKey = "sbh_abcd1234validtoken"
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok","projects":[]}`))
+	}))
+	defer srv.Close()
+
+	writeDoctorConfig(t, dir, srv.URL, validKey)
+	results := doctorChecks(dir, newTestHTTPClient(), false, false)
+	tlsRes := findResult(results, "tls")
+	if tlsRes == nil || tlsRes.status != doctorFail || !strings.Contains(tlsRes.message, "--ca-cert") {
+		t.Fatalf("untrusted server: tls result = %+v, want FAIL with --ca-cert hint", tlsRes)
+	}
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(dir, "corp-ca.pem"), caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfgBody := "api_url: " + srv.URL + "\napi_key: " + validKey + "\nca_cert: corp-ca.pem\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(cfgBody), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	results = doctorChecks(dir, newTestHTTPClient(), false, false)
+	for _, name := range []string{"tls", "api-reachability", "auth-verify"} {
+		got := findResult(results, name)
+		if got == nil || got.status != doctorOK {
+			t.Errorf("with ca_cert: %s = %+v, want OK", name, got)
+		}
+	}
+	if got := findResult(results, "tls"); got != nil && !strings.Contains(got.detail, "chain:") {
+		t.Errorf("tls detail = %q, want the certificate chain", got.detail)
+	}
+}
+
+func TestDoctor_TLSConfigError(t *testing.T) {
+	dir := t.TempDir()
+	resetCredentialGlobals(t)
+	clearCredentialEnv(t)
+	t.Setenv("SBOMHUB_CLIENT_CERT", filepath.Join(dir, "client.pem"))
+
+	writeDoctorConfig(t, dir, "http://127.0.0.1:1", "sbh_abcd1234validtoken")
+	results := doctorChecks(dir, newTestHTTPClient(), false, false)
+	got := findResult(results, "tls-config")
+	if got == nil || got.status != doctorFail {
+		t.Fatalf("tls-config = %+v, want FAIL for a client cert without a key", got)
+	}
+	if findResult(results, "api-reachability") != nil {
+		t.Error("probes ran despite the broken TLS configuration")
+	}
+}
+
 func TestDoctor_AuthFails(t *testing.T) {
 	dir := t.TempDir()
 	resetCredentialGlobals(t)
@@ -533,3 +599,50 @@ func TestDoctor_FlagOnlyNoConfigFile(t *testing.T) {
 		t.Errorf("runDoctorWith returned error for flag-only setup: %v\noutput:\n%s", err, buf.String())
 	}
 }
+
+// TestDoctor_ReportsProfile checks that doctor names the profile it
+// diagnosed and attributes the key to that profile, not to the default
+// one at the top of config.yaml.
+func TestDoctor_ReportsProfile(t *testing.T) {
+	dir := t.TempDir()
+	resetCredentialGlobals(t)
+	clearCredentialEnv(t)
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`api_url: http://127.0.0.1:1
+profiles:
+  staging:
+    api_url: http://127.0.0.1:1
+    api_key: sbh_staging_key
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SBOMHUB_PROFILE", "staging")
+
+	results := doctorChecks(dir, newTestHTTPClient(), false, false)
+
+	pr := findResult(results, "profile")
+	if pr == nil || pr.status != doctorOK || !strings.Contains(pr.message, "staging") || !strings.Contains(pr.message, "SBOMHUB_PROFILE") {
+		t.Errorf("profile result = %+v, want OK naming staging via SBOMHUB_PROFILE", pr)
+	}
+	ak := findResult(results, "api-key")
+	if ak == nil || ak.status != doctorOK || !strings.Contains(ak.message, "source: config") {
+		t.Errorf("api-key result = %+v, want OK with source: config", ak)
+	}
+}
+
+func TestDoctor_UnknownProfileFails(t *testing.T) {
+	dir := t.TempDir()
+	resetCredentialGlobals(t)
+	clearCredentialEnv(t)
+	writeDoctorConfig(t, dir, "http://127.0.0.1:1", "sbh_default_key")
+	profileFlag = "customer-x"
+
+	results := doctorChecks(dir, newTestHTTPClient(), false, false)
+
+	pr := findResult(results, "profile")
+	if pr == nil || pr.status != doctorFail || !strings.Contains(pr.message, "customer-x") {
+		t.Fatalf("profile result = %+v, want FAIL naming customer-x", pr)
+	}
+	if ak := findResult(results, "api-key"); ak != nil {
+		t.Errorf("api-key should not be checked against another profile's key, got %+v", ak)
+	}
+}
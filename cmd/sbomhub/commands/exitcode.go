@@ -0,0 +1,134 @@
+package commands
+
+// Exit-code contract.
+//
+// Every command shares one table; main.go applies it through ExitCode.
+// Before this, scan used 3 for every API failure while triage / cra /
+// meti / llm used 3 for permanent and 4 for transient, and each command
+// carried its own copy of the exit-error type and the classifier. CI
+// scripts can now branch on the same numbers whichever command failed:
+//
+//	0   — success
+//	1   — gate tripped (--fail-on / --policy / expired suppression) or a
+//	      local failure (bad flags, unreadable file, scanner error)
+//	2   — wait-for-scan timed out, or the server-side scan failed
+//	3   — permanent API / configuration error: 4xx other than 429,
+//	      AI features disabled (BYOK not configured), missing API key,
+//	      invalid TLS / proxy settings. Retrying will not help.
+//	4   — transient API error: 429, 5xx, network failure, or a server
+//	      response that violated the API contract. Retry later.
+//	130 — interrupted by SIGINT / SIGTERM
+//
+// Commands return an *exitError for the codes they decide themselves;
+// any other error that wraps an *sbomhub.Error / *sbomhub.RequestError is
+// classified here, so a plain `return fmt.Errorf("...: %w", err)` after
+// an API call still yields 3 or 4.
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+const (
+	exitSuccess           = 0
+	exitFailure           = 1
+	exitThresholdExceeded = 1
+	exitScanTimeout       = 2
+	exitPermanent         = 3
+	exitTransient         = 4
+	exitInterrupted       = 130
+)
+
+// exitError carries an explicit exit code through the cobra error path.
+// msg, when set, replaces err's text as the user-facing message; err is
+// kept for errors.Is / errors.As.
+type exitError struct {
+	code int
+	msg  string
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.msg != "" || e.err == nil {
+		return e.msg
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+func (e *exitError) ExitCode() int { return e.code }
+
+// ExitCode maps an error returned by Execute onto the exit-code table.
+// An explicit code (exitError, interruptedError, policy rules) wins;
+// API failures are classified by apiExitCode; anything else is 1.
+func ExitCode(err error) int {
+	if err == nil {
+		return exitSuccess
+	}
+	var ec interface{ ExitCode() int }
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	if code, ok := classifyAPIError(err); ok {
+		return code
+	}
+	return exitFailure
+}
+
+// classifyAPIError returns the exit code for err when it wraps an API
+// failure, and ok=false otherwise.
+func classifyAPIError(err error) (code int, ok bool) {
+	var ae *sbomhub.Error
+	if errors.As(err, &ae) {
+		if ae.IsTransient() {
+			return exitTransient, true
+		}
+		// Permanent, AI-disabled and unclassified statuses all need the
+		// operator to change something before a retry can succeed.
+		return exitPermanent, true
+	}
+	var re *sbomhub.RequestError
+	if errors.As(err, &re) {
+		return exitTransient, true
+	}
+	return 0, false
+}
+
+// apiExitCode is classifyAPIError for an error known to come from an API
+// call. Errors without a typed API cause (JSON decode of a truncated
+// body, context deadline) count as transient: the operator's correct
+// response is retry-or-investigate.
+func apiExitCode(err error) int {
+	if code, ok := classifyAPIError(err); ok {
+		return code
+	}
+	return exitTransient
+}
+
+// apiFailure wraps the failure of API operation op in an *exitError with
+// the classified code and a message that tells the operator whether to
+// retry, fix configuration, or configure BYOK.
+func apiFailure(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var ae *sbomhub.Error
+	errors.As(err, &ae)
+	switch {
+	case ae.IsAIDisabled():
+		return &exitError{
+			code: exitPermanent,
+			err:  err,
+			msg: fmt.Sprintf("%s BYOK 未設定 / BYOK provider not configured: %v\n  → Web UI /settings/llm で provider を設定してください / configure a provider in /settings/llm",
+				op, err),
+		}
+	case ae != nil && ae.Kind() == sbomhub.KindUnclassified:
+		return &exitError{code: exitPermanent, err: err, msg: fmt.Sprintf("%s 不明な失敗: %v", op, err)}
+	}
+	if code := apiExitCode(err); code == exitPermanent {
+		return &exitError{code: code, err: err, msg: fmt.Sprintf("%s 恒久エラー / permanent failure: %v", op, err)}
+	}
+	return &exitError{code: exitTransient, err: err, msg: fmt.Sprintf("%s 一時エラー / transient failure (retry): %v", op, err)}
+}
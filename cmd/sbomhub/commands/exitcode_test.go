@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// TestExitCode_CrossCommand runs several API-backed commands through the
+// real cobra tree against the same failing server and checks that every
+// one of them lands on the same row of the exit-code table.
+func TestExitCode_CrossCommand(t *testing.T) {
+	const projectID = "01234567-0123-0123-0123-0123456789ab"
+	fakeSyft(t, `{"bomFormat":"CycloneDX","components":[{"name":"a"}]}`)
+	commands := map[string][]string{
+		"projects list": {"projects", "list"},
+		"scan":          {"scan", t.TempDir(), "--project", projectID, "--no-cache"},
+		"triage":        {"triage", "--project", projectID, "--non-interactive"},
+		"cra list":      {"cra", "list", "--project", projectID},
+		"meti list":     {"meti", "list", "--project", projectID},
+		"llm test":      {"llm", "test"},
+	}
+	responses := []struct {
+		name   string
+		status int
+		body   string
+		want   int
+	}{
+		{"403 permanent", http.StatusForbidden, `{"error":"forbidden"}`, exitPermanent},
+		{"404 permanent", http.StatusNotFound, `{"error":"not found"}`, exitPermanent},
+		{"429 transient", http.StatusTooManyRequests, `{"error":"slow down"}`, exitTransient},
+		{"502 transient", http.StatusBadGateway, `<html>bad gateway</html>`, exitTransient},
+	}
+
+	for _, resp := range responses {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(resp.status)
+			_, _ = w.Write([]byte(resp.body))
+		}))
+		for name, args := range commands {
+			t.Run(resp.name+"/"+name, func(t *testing.T) {
+				got := ExitCode(runForExitCode(t, server.URL, args))
+				if got != resp.want {
+					t.Errorf("exit code = %d, want %d", got, resp.want)
+				}
+			})
+		}
+		server.Close()
+	}
+
+	// A server that is not there at all is a transient network failure
+	// for every command.
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+	for name, args := range commands {
+		t.Run("unreachable/"+name, func(t *testing.T) {
+			if got := ExitCode(runForExitCode(t, downURL, args)); got != exitTransient {
+				t.Errorf("exit code = %d, want %d", got, exitTransient)
+			}
+		})
+	}
+}
+
+// runForExitCode executes args against serverURL with retries disabled
+// and the per-command flag globals restored afterwards.
+func runForExitCode(t *testing.T, serverURL string, args []string) error {
+	t.Helper()
+	withCleanCredentialEnv(t)
+	savedRetries, savedCra, savedMeti, savedQuiet := maxRetriesFlag, craProject, metiProject, quietFlag
+	savedScan, savedNoCache, savedTriage, savedTriageCI := scanProject, noCacheFlag, triageProject, triageNonInteractive
+	t.Cleanup(func() {
+		maxRetriesFlag, craProject, metiProject, quietFlag = savedRetries, savedCra, savedMeti, savedQuiet
+		scanProject, noCacheFlag, triageProject, triageNonInteractive = savedScan, savedNoCache, savedTriage, savedTriageCI
+		rootCmd.SetArgs(nil)
+		clearCommandContexts(rootCmd)
+		clearFlagsChanged(rootCmd, args)
+	})
+	clearCommandContexts(rootCmd)
+	args = append(args, "--api-url", serverURL, "--api-key", "sbh_test", "--max-retries", "0", "--quiet")
+	rootCmd.SetArgs(args)
+	return executeContext(context.Background())
+}
+
+// clearCommandContexts undoes cobra's habit of keeping the context of
+// the first execution on each subcommand, so a later executeContext in
+// the same test binary (TestExecuteContext_InterruptExitCode) sees its
+// own context.
+func clearCommandContexts(cmd *cobra.Command) {
+	cmd.SetContext(nil)
+	for _, c := range cmd.Commands() {
+		clearCommandContexts(c)
+	}
+}
+
+// clearFlagsChanged forgets that an execution set the flags in args, so
+// a later test that resolves settings (--api-url over env and config
+// files) does not see them as given.
+func clearFlagsChanged(cmd *cobra.Command, args []string) {
+	for _, arg := range args {
+		name, ok := strings.CutPrefix(arg, "--")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "=")
+		if f := cmd.Flags().Lookup(name); f != nil {
+			f.Changed = false
+		}
+		if f := cmd.PersistentFlags().Lookup(name); f != nil {
+			f.Changed = false
+		}
+	}
+	for _, c := range cmd.Commands() {
+		clearFlagsChanged(c, args)
+	}
+}
+
+func TestExitCode_Table(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitSuccess},
+		{"local failure", errors.New("bad flag"), exitFailure},
+		{"explicit code wins", &exitError{code: exitThresholdExceeded, err: &sbomhub.Error{StatusCode: 500}}, exitThresholdExceeded},
+		{"wrapped permanent", fmt.Errorf("op: %w", &sbomhub.Error{StatusCode: 401}), exitPermanent},
+		{"AI disabled", &sbomhub.Error{StatusCode: 503, Reason: "AI features are disabled"}, exitPermanent},
+		{"unclassified status", &sbomhub.Error{StatusCode: 302}, exitPermanent},
+		{"generic 503", &sbomhub.Error{StatusCode: 503, Raw: "gateway down"}, exitTransient},
+		{"protocol violation", &sbomhub.Error{StatusCode: 200, ProtocolError: true}, exitTransient},
+		{"network", &sbomhub.RequestError{Err: errors.New("connection refused")}, exitTransient},
+		{"interrupted", &interruptedError{err: context.Canceled}, exitInterrupted},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ExitCode(tc.err); got != tc.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -47,7 +47,7 @@ package commands
 //
 // Both subcommands flow through the same M1/M2/M3-aligned regime —
 // credentials via resolveCredentials, output via GetOutputConfig,
-// exit codes via llmExitError (3=permanent, 4=transient) — so the
+// exit codes via the shared table in exitcode.go (3=permanent, 4=transient) — so the
 // operator gets the same UX they learned from `sbomhub triage` /
 // `sbomhub cra` / `sbomhub meti`.
 //
@@ -152,21 +152,9 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
-// llmExitError lets a runLlmXxx return a specific exit code through
-// the cobra error path. Mirrors metiExitError / craExitError /
-// triageExitError so main.go's exitCoder hook covers every M4 surface
-// without a branching type assertion.
-type llmExitError struct {
-	code int
-	msg  string
-}
-
-func (e *llmExitError) Error() string { return e.msg }
-func (e *llmExitError) ExitCode() int { return e.code }
-
 // ---------------------------------------------------------------------------
 // Default constants
 // ---------------------------------------------------------------------------
@@ -416,8 +404,12 @@ func runLLMTest(cmd *cobra.Command, args []string) error {
 	// API key is optional for the health probe (the endpoint is
 	// public). We don't require it because the operator's first
 	// connectivity check should not need a key — they're literally
-	// asking "can I reach the server" before bothering to set one.
-	client := api.NewClient(cfg.APIURL, cfg.APIKey)
+	// asking "can I reach the server" before bothering to set one —
+	// nor a CI identity exchange, hence buildAPIClient.
+	client, err := buildAPIClient(cfg)
+	if err != nil {
+		return err
+	}
 
 	// --provider is accepted but currently advisory — sbomhub OSS has
 	// at most one provider configured at a time (per tenant), so the
@@ -438,16 +430,22 @@ func runLLMTest(cmd *cobra.Command, args []string) error {
 
 	res, err := client.Health(ctx)
 	if err != nil {
-		return llmHealthFailureToExitError("llm test", err)
+		return apiFailure("llm test", err)
 	}
 
-	return renderLLMTest(out, res, cfg.APIURL)
+	// Whether the server publishes provider / model at all comes from its
+	// capabilities document; without one the N/A fields stay ambiguous
+	// (not published vs not configured) and the note below says so.
+	caps := serverCapabilities(ctx, client, cfg.APIURL)
+
+	return renderLLMTest(out, res, cfg.APIURL, caps)
 }
 
 // renderLLMTest factors the output path out of runLLMTest so the
 // command logic can be reused in tests that drive a fake server +
-// captured OutputConfig without re-implementing the rendering.
-func renderLLMTest(out *OutputConfig, res *api.LLMHealthResponse, baseURL string) error {
+// captured OutputConfig without re-implementing the rendering. caps may
+// be nil (capabilities not fetched).
+func renderLLMTest(out *OutputConfig, res *sbomhub.LLMHealthResponse, baseURL string, caps *sbomhub.Capabilities) error {
 	if out.IsJSON() {
 		// Synthesise "connectivity": "ok" so machine consumers can
 		// branch on a single boolean. We do NOT collapse server-
@@ -463,6 +461,9 @@ func renderLLMTest(out *OutputConfig, res *api.LLMHealthResponse, baseURL string
 			"model":         res.Model,
 			"llm_connected": nil,
 			"llm_reason":    res.Reason,
+			// "supported" / "unsupported" / "unknown": whether the
+			// server declares that it publishes provider / model.
+			"llm_health_metadata": featureState(caps, sbomhub.FeatureLLMHealth),
 		}
 		if res.Connected != nil {
 			payload["llm_connected"] = *res.Connected
@@ -482,7 +483,17 @@ func renderLLMTest(out *OutputConfig, res *api.LLMHealthResponse, baseURL string
 	if res.Reason != "" {
 		fmt.Fprintf(w, "  LLM reason       : %s\n", res.Reason)
 	}
-	if res.Provider == "" {
+	switch {
+	case res.Provider != "":
+	case featureState(caps, sbomhub.FeatureLLMHealth) == "supported":
+		// The server publishes the fields, so empty means no provider
+		// is configured on it — not a server limitation.
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "注: サーバは LLM provider 情報を公開していますが、 provider が設定されていません。")
+		fmt.Fprintln(w, "    /settings/llm (Web UI) で provider を設定してください。")
+		fmt.Fprintln(w, "    Note: the server publishes LLM provider info but no provider is")
+		fmt.Fprintln(w, "    configured — set one up under /settings/llm in the Web UI.")
+	default:
 		// Honest disclosure so the operator does not interpret
 		// "N/A" as "server is broken" (※要確認 above).
 		fmt.Fprintln(w, "")
@@ -515,46 +526,6 @@ func renderConnected(b *bool) string {
 	return "no"
 }
 
-// llmHealthFailureToExitError translates an LLM health API error
-// into the exit-code envelope (3=permanent, 4=transient) following
-// the M1 #F21 pattern. Network / unknown errors fall into the
-// transient bucket because the operator's correct response (retry)
-// is the same.
-//
-// The AI-disabled 503 case (IsAIDisabled) is folded into permanent
-// because the operator's correct response is "configure BYOK in
-// /settings/llm", NOT "retry"; we surface a dedicated hint message
-// so the operator gets a direct action item rather than a generic
-// "permanent failure" line.
-func llmHealthFailureToExitError(op string, err error) error {
-	if err == nil {
-		return nil
-	}
-	var le *api.LLMError
-	if errors.As(err, &le) {
-		switch {
-		case le.IsAIDisabled():
-			return &llmExitError{
-				code: 3,
-				msg: fmt.Sprintf("%s BYOK 未設定 / BYOK provider not configured: %v\n  → Web UI /settings/llm で provider を設定してください / configure a provider in /settings/llm",
-					op, err),
-			}
-		case le.IsPermanent():
-			return &llmExitError{code: 3, msg: fmt.Sprintf("%s 恒久エラー / permanent failure: %v", op, err)}
-		case le.IsTransient():
-			return &llmExitError{code: 4, msg: fmt.Sprintf("%s 一時エラー / transient failure (retry): %v", op, err)}
-		default:
-			// Unknown 4xx the helpers do not classify — treat as
-			// permanent because the operator's response is "fix
-			// something", not "retry".
-			return &llmExitError{code: 3, msg: fmt.Sprintf("%s 不明な失敗: %v", op, err)}
-		}
-	}
-	// Network / JSON parse / context errors — retry is the right
-	// response.
-	return &llmExitError{code: 4, msg: fmt.Sprintf("%s 一時エラー / transient failure (retry): %v", op, err)}
-}
-
 // ---------------------------------------------------------------------------
 // llm bench
 // ---------------------------------------------------------------------------
@@ -572,14 +543,14 @@ func runLLMBench(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
 
 	if llmBenchMaxCases <= 0 {
-		return &llmExitError{
-			code: 3,
+		return &exitError{
+			code: exitPermanent,
 			msg:  fmt.Sprintf("--max-cases は 1 以上で指定してください (got %d)", llmBenchMaxCases),
 		}
 	}
 	if llmBenchTimeoutSec <= 0 {
-		return &llmExitError{
-			code: 3,
+		return &exitError{
+			code: exitPermanent,
 			msg:  fmt.Sprintf("--timeout は 1 以上で指定してください (got %d)", llmBenchTimeoutSec),
 		}
 	}
@@ -591,8 +562,8 @@ func runLLMBench(cmd *cobra.Command, args []string) error {
 	case "source":
 		return runLLMBenchSourceMode(cmd, out)
 	default:
-		return &llmExitError{
-			code: 3,
+		return &exitError{
+			code: exitPermanent,
 			msg:  fmt.Sprintf("SBOMHUB_BENCH_MODE must be binary or source (got %q)", mode),
 		}
 	}
@@ -601,12 +572,12 @@ func runLLMBench(cmd *cobra.Command, args []string) error {
 func runLLMBenchSourceMode(cmd *cobra.Command, out *OutputConfig) error {
 	source, err := resolveSbomhubSource(llmBenchSbomhubSource)
 	if err != nil {
-		return &llmExitError{code: 3, msg: err.Error()}
+		return &exitError{code: exitPermanent, msg: err.Error()}
 	}
 
 	evalSetPath, err := resolveEvalSetPath(llmBenchEvalSet, source)
 	if err != nil {
-		return &llmExitError{code: 3, msg: err.Error()}
+		return &exitError{code: exitPermanent, msg: err.Error()}
 	}
 
 	// `go` toolchain pre-flight. Surfacing this before we exec gives
@@ -614,8 +585,8 @@ func runLLMBenchSourceMode(cmd *cobra.Command, out *OutputConfig) error {
 	// from os/exec. ENOENT here is permanent (operator must install
 	// Go) so we map to exit-3.
 	if _, lookErr := exec.LookPath("go"); lookErr != nil {
-		return &llmExitError{
-			code: 3,
+		return &exitError{
+			code: exitPermanent,
 			msg: fmt.Sprintf("`go` toolchain が見つかりません: %v\n"+
 				"  SBOMHUB_BENCH_MODE=source は sbomhub source の M4-3 bench を `go build` してから実行します。\n"+
 				"  Install Go from https://go.dev/dl/ and ensure `go` is in PATH.",
@@ -655,8 +626,8 @@ func runLLMBenchSourceMode(cmd *cobra.Command, out *OutputConfig) error {
 	// apps/api (where the containing go.mod lives).
 	workDir := filepath.Join(source, "apps", "api")
 	if _, statErr := os.Stat(filepath.Join(workDir, "go.mod")); os.IsNotExist(statErr) {
-		return &llmExitError{
-			code: 3,
+		return &exitError{
+			code: exitPermanent,
 			msg: fmt.Sprintf(
 				"sbomhub source の apps/api/go.mod が見つかりません (looked under %s)\n"+
 					"  --sbomhub-source が正しい sbomhub OSS checkout を指していますか?\n"+
@@ -677,8 +648,8 @@ func runLLMBenchSourceMode(cmd *cobra.Command, out *OutputConfig) error {
 	// defer regardless of whether build / exec succeeds.
 	tmpDir, mkErr := os.MkdirTemp("", "sbomhub-llm-bench-*")
 	if mkErr != nil {
-		return &llmExitError{
-			code: 3,
+		return &exitError{
+			code: exitPermanent,
 			msg:  fmt.Sprintf("llm bench 一時ディレクトリ作成に失敗: %v", mkErr),
 		}
 	}
@@ -720,7 +691,7 @@ func runLLMBenchSourceMode(cmd *cobra.Command, out *OutputConfig) error {
 		if snippet != "" {
 			msg += "\n  stderr (truncated):\n" + snippet
 		}
-		return &llmExitError{code: 3, msg: msg}
+		return &exitError{code: exitPermanent, msg: msg}
 	}
 
 	// Step 2 (F61): exec the built binary directly so its
@@ -748,26 +719,37 @@ func runLLMBenchBinaryMode(cmd *cobra.Command, out *OutputConfig) error {
 		ctx = context.Background()
 	}
 
+	// Release downloads go through the same CA bundle / proxy as the API
+	// client: the hosts that need an internal CA to reach the API are
+	// usually the same ones that can only reach GitHub via a proxy.
+	cfg, err := resolveCredentials(getConfigDir())
+	if err != nil {
+		return &exitError{code: exitPermanent, msg: fmt.Sprintf("設定の読み込みに失敗しました: %v", err)}
+	}
+	if err := configureHTTPClient(llmBenchHTTPClient, cfg); err != nil {
+		return &exitError{code: exitPermanent, msg: err.Error()}
+	}
+
 	benchBin := strings.TrimSpace(llmBenchBinary)
 	var workDir string
 	if benchBin != "" {
 		abs, err := filepath.Abs(benchBin)
 		if err != nil {
-			return &llmExitError{code: 3, msg: fmt.Sprintf("--bench-binary の絶対パス解決に失敗: %v", err)}
+			return &exitError{code: exitPermanent, msg: fmt.Sprintf("--bench-binary の絶対パス解決に失敗: %v", err)}
 		}
 		info, err := os.Stat(abs)
 		if err != nil {
-			return &llmExitError{code: 3, msg: fmt.Sprintf("--bench-binary が見つかりません (%s): %v", abs, err)}
+			return &exitError{code: exitPermanent, msg: fmt.Sprintf("--bench-binary が見つかりません (%s): %v", abs, err)}
 		}
 		if info.IsDir() {
-			return &llmExitError{code: 3, msg: fmt.Sprintf("--bench-binary は file を指定してください: %s", abs)}
+			return &exitError{code: exitPermanent, msg: fmt.Sprintf("--bench-binary は file を指定してください: %s", abs)}
 		}
 		benchBin = abs
 		workDir = filepath.Dir(abs)
 	} else {
 		resolved, err := ensureCachedLLMBenchBinary(ctx, out)
 		if err != nil {
-			return &llmExitError{code: 3, msg: err.Error()}
+			return &exitError{code: exitPermanent, msg: err.Error()}
 		}
 		benchBin = resolved.BinaryPath
 		workDir = resolved.WorkDir
@@ -775,7 +757,7 @@ func runLLMBenchBinaryMode(cmd *cobra.Command, out *OutputConfig) error {
 
 	evalSetPath, err := resolveBinaryEvalSetPath(llmBenchEvalSet, workDir)
 	if err != nil {
-		return &llmExitError{code: 3, msg: err.Error()}
+		return &exitError{code: exitPermanent, msg: err.Error()}
 	}
 
 	return execLLMBenchBinary(ctx, out, benchBin, workDir, buildLLMBenchArgs(evalSetPath))
@@ -1260,8 +1242,8 @@ func (c *cappedBuffer) Bytes() []byte {
 }
 
 // mapBenchSubprocessError translates the error returned by
-// execCmd.Run() into the llmExitError envelope that main.go's
-// exitCoder hook will surface to the OS.
+// execCmd.Run() into the exitError envelope that the shared exit-code table
+// (exitcode.go) will surface to the OS.
 //
 // M4 Codex review #F46 fix — option (a) transparent pass-through:
 // for *exec.ExitError we forward the M4-3 typed contract code
@@ -1297,7 +1279,7 @@ func (c *cappedBuffer) Bytes() []byte {
 // *exec.ExitError values (sh -c "exit N") without a populated
 // sbomhub OSS checkout. stderrTail may be nil for tests that do
 // not exercise the F57 path.
-func mapBenchSubprocessError(runErr error, stderrTail []byte) *llmExitError {
+func mapBenchSubprocessError(runErr error, stderrTail []byte) *exitError {
 	if runErr == nil {
 		return nil
 	}
@@ -1314,8 +1296,8 @@ func mapBenchSubprocessError(runErr error, stderrTail []byte) *llmExitError {
 			// because the M1/F21 convention treats "no typed exit
 			// code" as transient and operators have learned that
 			// mapping.
-			return &llmExitError{
-				code: 4,
+			return &exitError{
+				code: exitTransient,
 				msg: fmt.Sprintf("llm bench abnormally terminated (signal / no exit code): %v",
 					runErr),
 			}
@@ -1346,14 +1328,14 @@ func mapBenchSubprocessError(runErr error, stderrTail []byte) *llmExitError {
 			if snippet != "" {
 				msg += "\n  stderr (truncated):\n" + snippet
 			}
-			return &llmExitError{code: 3, msg: msg}
+			return &exitError{code: exitPermanent, msg: msg}
 		}
 		// Forward M4-3 (sbomhub apps/api/cmd/llm-bench) F42 typed
 		// contract verbatim: 2=usage / 3=config / 4=no providers /
 		// 5=execution. The wrapper does not re-interpret these
 		// codes — see the file-header ※要確認 for the option
 		// (a) vs (c) trade-off rationale.
-		return &llmExitError{
+		return &exitError{
 			code: subExit,
 			msg: fmt.Sprintf("llm bench exited with code %d — see sbomhub M4-3 doc "+
 				"(apps/api/cmd/llm-bench) for the typed exit-code contract "+
@@ -1363,8 +1345,8 @@ func mapBenchSubprocessError(runErr error, stderrTail []byte) *llmExitError {
 	}
 	// Non-ExitError = launch failure (file not found, fork
 	// failure). Permanent — operator must fix env.
-	return &llmExitError{
-		code: 3,
+	return &exitError{
+		code: exitPermanent,
 		msg:  fmt.Sprintf("llm bench 起動失敗: %v", runErr),
 	}
 }
@@ -39,7 +39,7 @@ import (
 	"testing"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // ---------------------------------------------------------------------------
@@ -103,7 +103,7 @@ type llmTestResult struct {
 // runLLMTestAndCapture replays the runLLMTest body using an
 // injected client + buffered OutputConfig so the test does not have
 // to leak globals. Mirrors the M3 meti_test pattern.
-func runLLMTestAndCapture(t *testing.T, client *api.Client, apiURL string, args llmTestArgs, jsonOutput bool) (llmTestResult, *bytes.Buffer, *bytes.Buffer) {
+func runLLMTestAndCapture(t *testing.T, client *sbomhub.Client, apiURL string, args llmTestArgs, jsonOutput bool) (llmTestResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr, JSON: jsonOutput}
@@ -117,9 +117,9 @@ func runLLMTestAndCapture(t *testing.T, client *api.Client, apiURL string, args
 
 	res, err := client.Health(context.Background())
 	if err != nil {
-		return llmTestResult{err: llmHealthFailureToExitError("llm test", err)}, &stdout, &stderr
+		return llmTestResult{err: apiFailure("llm test", err)}, &stdout, &stderr
 	}
-	if err := renderLLMTest(out, res, apiURL); err != nil {
+	if err := renderLLMTest(out, res, apiURL, nil); err != nil {
 		return llmTestResult{err: err}, &stdout, &stderr
 	}
 	return llmTestResult{}, &stdout, &stderr
@@ -133,7 +133,7 @@ func TestLLMTest_HappyPath_MinimalServer(t *testing.T) {
 	tf.healthResp = func(r *http.Request) (int, interface{}, string) {
 		return http.StatusOK, map[string]string{"status": "ok", "mode": "byok"}, ""
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runLLMTestAndCapture(t, client, tf.server.URL, llmTestArgs{}, false)
 	if res.err != nil {
 		t.Fatalf("llm test err: %v", res.err)
@@ -168,7 +168,7 @@ func TestLLMTest_HappyPath_JSONOutput(t *testing.T) {
 			"connected": connected,
 		}, ""
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runLLMTestAndCapture(t, client, tf.server.URL, llmTestArgs{}, true)
 	if res.err != nil {
 		t.Fatalf("llm test err: %v", res.err)
@@ -194,11 +194,11 @@ func TestLLMTest_PermanentExit3_F21(t *testing.T) {
 	tf.healthResp = func(r *http.Request) (int, interface{}, string) {
 		return http.StatusUnauthorized, map[string]string{"error": "invalid api key"}, ""
 	}
-	client := api.NewClient(tf.server.URL, "k")
+	client := sbomhub.NewClient(tf.server.URL, "k")
 	res, _, _ := runLLMTestAndCapture(t, client, tf.server.URL, llmTestArgs{}, false)
-	exitErr, ok := res.err.(*llmExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F21: err = %v (%T), want *llmExitError", res.err, res.err)
+		t.Fatalf("F21: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F21: ExitCode = %d, want 3 (401 permanent)", exitErr.ExitCode())
@@ -212,11 +212,11 @@ func TestLLMTest_TransientExit4_503Generic_F22(t *testing.T) {
 	tf.healthResp = func(r *http.Request) (int, interface{}, string) {
 		return http.StatusServiceUnavailable, nil, "<html>503 Service Unavailable</html>"
 	}
-	client := api.NewClient(tf.server.URL, "k")
+	client := sbomhub.NewClient(tf.server.URL, "k")
 	res, _, _ := runLLMTestAndCapture(t, client, tf.server.URL, llmTestArgs{}, false)
-	exitErr, ok := res.err.(*llmExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F22: err = %v (%T), want *llmExitError", res.err, res.err)
+		t.Fatalf("F22: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F22: ExitCode = %d, want 4 (generic 503 = transient outage)", exitErr.ExitCode())
@@ -233,11 +233,11 @@ func TestLLMTest_PermanentExit3_503AIDisabled_F22(t *testing.T) {
 			map[string]string{"error": "BYOK key not configured", "reason": "BYOK key not configured"},
 			""
 	}
-	client := api.NewClient(tf.server.URL, "k")
+	client := sbomhub.NewClient(tf.server.URL, "k")
 	res, _, _ := runLLMTestAndCapture(t, client, tf.server.URL, llmTestArgs{}, false)
-	exitErr, ok := res.err.(*llmExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F22: err = %v (%T), want *llmExitError", res.err, res.err)
+		t.Fatalf("F22: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F22: ExitCode = %d, want 3 (BYOK-not-configured = permanent)", exitErr.ExitCode())
@@ -254,11 +254,11 @@ func TestLLMTest_TransientExit4_ProtocolError_F23(t *testing.T) {
 	tf.healthResp = func(r *http.Request) (int, interface{}, string) {
 		return http.StatusOK, map[string]interface{}{}, ""
 	}
-	client := api.NewClient(tf.server.URL, "k")
+	client := sbomhub.NewClient(tf.server.URL, "k")
 	res, _, _ := runLLMTestAndCapture(t, client, tf.server.URL, llmTestArgs{}, false)
-	exitErr, ok := res.err.(*llmExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F23: err = %v (%T), want *llmExitError", res.err, res.err)
+		t.Fatalf("F23: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F23: ExitCode = %d, want 4 (protocol violation = transient)", exitErr.ExitCode())
@@ -269,11 +269,11 @@ func TestLLMTest_TransientExit4_ProtocolError_F23(t *testing.T) {
 // closed) maps to exit 4 (transient).
 func TestLLMTest_NetworkError_Exit4(t *testing.T) {
 	// Use an unroutable URL so the dial fails cleanly.
-	client := api.NewClient("http://127.0.0.1:1", "k")
+	client := sbomhub.NewClient("http://127.0.0.1:1", "k")
 	res, _, _ := runLLMTestAndCapture(t, client, "http://127.0.0.1:1", llmTestArgs{}, false)
-	exitErr, ok := res.err.(*llmExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *llmExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("ExitCode = %d, want 4 (network error = transient)", exitErr.ExitCode())
@@ -290,33 +290,33 @@ func TestLLMHealthFailureToExitError_Classification_F21(t *testing.T) {
 		wantCode int
 	}{
 		{"nil error", nil, 0},
-		{"401 permanent", &api.LLMError{StatusCode: 401}, 3},
-		{"403 permanent", &api.LLMError{StatusCode: 403}, 3},
-		{"404 permanent", &api.LLMError{StatusCode: 404}, 3},
-		{"429 transient", &api.LLMError{StatusCode: 429}, 4},
-		{"500 transient", &api.LLMError{StatusCode: 500}, 4},
-		{"503 generic transient", &api.LLMError{StatusCode: 503, Raw: "gateway down"}, 4},
-		{"503 AI-disabled permanent", &api.LLMError{StatusCode: 503, Reason: "BYOK key not configured"}, 3},
-		{"protocol error transient", &api.LLMError{StatusCode: 200, ProtocolError: true}, 4},
+		{"401 permanent", &sbomhub.Error{StatusCode: 401}, 3},
+		{"403 permanent", &sbomhub.Error{StatusCode: 403}, 3},
+		{"404 permanent", &sbomhub.Error{StatusCode: 404}, 3},
+		{"429 transient", &sbomhub.Error{StatusCode: 429}, 4},
+		{"500 transient", &sbomhub.Error{StatusCode: 500}, 4},
+		{"503 generic transient", &sbomhub.Error{StatusCode: 503, Raw: "gateway down"}, 4},
+		{"503 AI-disabled permanent", &sbomhub.Error{StatusCode: 503, Reason: "BYOK key not configured"}, 3},
+		{"protocol error transient", &sbomhub.Error{StatusCode: 200, ProtocolError: true}, 4},
 		// F39 regression: 204 / 206 with ProtocolError=true must
 		// still surface as transient exit-4 (not the default exit-3
 		// permanent bucket) at the classifier layer.
-		{"204 protocol error transient (F39)", &api.LLMError{StatusCode: 204, ProtocolError: true}, 4},
-		{"206 protocol error transient (F39)", &api.LLMError{StatusCode: 206, ProtocolError: true}, 4},
+		{"204 protocol error transient (F39)", &sbomhub.Error{StatusCode: 204, ProtocolError: true}, 4},
+		{"206 protocol error transient (F39)", &sbomhub.Error{StatusCode: 206, ProtocolError: true}, 4},
 		{"network error transient", errors.New("connection refused"), 4},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := llmHealthFailureToExitError("op", tc.err)
+			err := apiFailure("op", tc.err)
 			if tc.wantCode == 0 {
 				if err != nil {
 					t.Errorf("want nil, got %v", err)
 				}
 				return
 			}
-			exitErr, ok := err.(*llmExitError)
+			exitErr, ok := err.(*exitError)
 			if !ok {
-				t.Fatalf("err = %v (%T), want *llmExitError", err, err)
+				t.Fatalf("err = %v (%T), want *exitError", err, err)
 			}
 			if exitErr.ExitCode() != tc.wantCode {
 				t.Errorf("ExitCode = %d, want %d", exitErr.ExitCode(), tc.wantCode)
@@ -533,7 +533,7 @@ func runShExit(t *testing.T, code int) error {
 
 // TestRunLLMBench_ExitCodePropagation_F46 — `mapBenchSubprocessError`
 // must forward subprocess exit codes 2/3/4/5 verbatim into the
-// llmExitError envelope so CI pipelines can branch on the M4-3 F42
+// exitError envelope so CI pipelines can branch on the M4-3 F42
 // typed contract. Codes OUTSIDE that band (notably exit 1 from a
 // `go run` toolchain mismatch) are renormalised by F57 — that
 // behaviour is covered in TestMapBenchSubprocessError_ContractExitNormalization_F57
@@ -1271,9 +1271,9 @@ func TestLLMBenchBinaryModeExitCodePropagation_M5(t *testing.T) {
 	llmBenchTimeoutSec = 1
 	t.Setenv("FAKE_BENCH_EXIT", "5")
 	got := runLLMBenchBinaryMode(&cobra.Command{}, &OutputConfig{Writer: io.Discard, ErrWriter: io.Discard})
-	exitErr, ok := got.(*llmExitError)
+	exitErr, ok := got.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *llmExitError", got, got)
+		t.Fatalf("err = %v (%T), want *exitError", got, got)
 	}
 	if exitErr.ExitCode() != 5 {
 		t.Errorf("binary-mode exit = %d, want 5", exitErr.ExitCode())
@@ -2,13 +2,16 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
+	"os/exec"
+	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 var loginCmd = &cobra.Command{
@@ -16,15 +19,62 @@ var loginCmd = &cobra.Command{
 	Short: "SBOMHubにログイン（API Keyを設定）",
 	Long: `SBOMHubのAPI Keyを設定します。
 
-API Keyは https://sbomhub.app/settings/api-keys から取得できます。`,
+API Keyは https://sbomhub.app/settings/api-keys から取得できます。
+
+--profile (または SBOMHUB_PROFILE) を指定すると、 そのプロファイルに保存します。
+存在しないプロファイルは新規作成されます。
+
+API Key の保存先は --credential-store で選べます (デフォルト: auto):
+  auto     credential_helper が設定されていればそれ、 無ければ OS キーチェーン
+           (macOS: security / Linux: secret-tool)、 どちらも使えなければ設定ファイル
+  keyring  OS キーチェーン
+  helper   --credential-helper (または設定済みの credential_helper) のコマンド
+  file     設定ファイル (~/.sbomhub/config.yaml、 0600) に平文で保存
+キーチェーン / helper に保存した場合、 設定ファイルには参照 (api_key_ref) のみ残ります。
+
+--device を指定すると API Key の代わりにブラウザでログインします (OAuth 2.0
+デバイス認可フロー)。 表示されたコードをブラウザで承認すると、 短期間有効な
+アクセストークンとリフレッシュトークンが上記と同じ保存先に保存され、 期限切れ
+や 401 の際は自動で更新されます。 サーバが外部の IdP に認証を委ねている場合は
+--oauth-issuer で IdP を指定できます。
+
+使用例:
+  sbomhub login                       # 現在のプロファイルに保存
+  sbomhub --profile staging login     # staging プロファイルを作成 / 更新
+  sbomhub login --credential-helper "pass-sbomhub"   # 外部 helper に保存
+  sbomhub login --device              # ブラウザでログイン (SSO)`,
 	RunE: runLogin,
 }
 
+var (
+	loginCredentialStore  string
+	loginCredentialHelper string
+	loginDevice           bool
+	loginNoBrowser        bool
+	loginOAuthIssuer      string
+	loginOAuthClientID    string
+)
+
 func init() {
 	rootCmd.AddCommand(loginCmd)
+	loginCmd.Flags().StringVar(&loginCredentialStore, "credential-store", credStoreAuto, "API Key の保存先 (auto / keyring / helper / file)")
+	loginCmd.Flags().StringVar(&loginCredentialHelper, "credential-helper", "", "git-credential 形式の外部コマンド (get / store / erase) を API Key の保存先にする")
+	loginCmd.Flags().BoolVar(&loginDevice, "device", false, "API Key の代わりにブラウザでログイン (OAuth 2.0 デバイス認可フロー)")
+	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "--device でブラウザを自動で開かない (URL とコードの表示のみ)")
+	loginCmd.Flags().StringVar(&loginOAuthIssuer, "oauth-issuer", "", "--device で使う認可サーバ (IdP) の issuer URL (デフォルト: SBOMHub サーバ)")
+	loginCmd.Flags().StringVar(&loginOAuthClientID, "oauth-client-id", sbomhub.DefaultOAuthClientID, "--device で使う OAuth クライアント ID")
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
+	switch loginCredentialStore {
+	case credStoreAuto, credStoreKeyring, credStoreHelper, credStoreFile:
+	default:
+		return fmt.Errorf("--credential-store が不正です: %s (auto / keyring / helper / file)", loginCredentialStore)
+	}
+	if loginDevice {
+		return runDeviceLogin(cmd)
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println("SBOMHub CLI ログイン")
@@ -54,24 +104,159 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		apiURLInput = urlInput
 	}
 
-	// 設定を保存
-	cfg := &config.Config{
-		APIURL: apiURLInput,
-		APIKey: apiKeyInput,
-	}
+	configDir := getConfigDir()
 
-	configDir := filepath.Join(os.Getenv("HOME"), ".sbomhub")
-	if os.Getenv("USERPROFILE") != "" {
-		configDir = filepath.Join(os.Getenv("USERPROFILE"), ".sbomhub")
+	// 設定を保存。 既存の config.yaml があれば対象プロファイルの
+	// api_url / api_key 以外のキー (ca_cert / proxy 等) と、 他の
+	// プロファイルを残したまま上書きする。
+	f, name, cfg, err := loadProfileForEdit(configDir)
+	if err != nil {
+		return err
+	}
+	cfg.APIURL = apiURLInput
+	if loginCredentialHelper != "" {
+		cfg.CredentialHelper = loginCredentialHelper
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	storedIn, err := storeAPIKey(ctx, cfg, name, apiKeyInput, loginCredentialStore)
+	if err != nil {
+		return err
 	}
+	// An API key replaces a previous device login of this profile.
+	clearDeviceSession(ctx, name, cfg)
+	f.SetProfile(name, cfg)
 
-	if err := config.Save(cfg, configDir); err != nil {
+	if err := config.WriteFile(f, configDir); err != nil {
 		return fmt.Errorf("設定の保存に失敗しました: %w", err)
 	}
 
 	fmt.Println()
-	printSuccess("ログインが完了しました！")
+	printSuccess("ログインが完了しました！ (プロファイル: %s)", name)
 	printInfo("設定ファイル: %s/config.yaml", configDir)
+	printInfo("API Key の保存先: %s", storedIn)
+	if name != f.ResolveName("") {
+		printInfo("このプロファイルを既定にするには 'sbomhub config use-context %s' を実行してください", name)
+	}
+
+	return nil
+}
+
+// runDeviceLogin signs the profile in with the OAuth device flow: show
+// the user code, wait for the browser approval, store the tokens where
+// an API key would go and drop any API key the profile had.
+func runDeviceLogin(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	configDir := getConfigDir()
+	f, name, cfg, err := loadProfileForEdit(configDir)
+	if err != nil {
+		return err
+	}
+
+	// API URL: --api-url / SBOMHUB_API_URL, else ask as the key login does.
+	url := apiURL
+	if url == "" {
+		url = cfg.APIURL
+		if url == "" {
+			url = config.DefaultAPIURL
+		}
+		fmt.Printf("API URL [%s]: ", url)
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if input = strings.TrimSpace(input); input != "" {
+			url = input
+		}
+	}
+	cfg.APIURL = url
+	if loginCredentialHelper != "" {
+		cfg.CredentialHelper = loginCredentialHelper
+	}
+
+	// The flow itself runs unauthenticated, over the profile's TLS /
+	// proxy settings.
+	conn := *cfg
+	conn.APIKey = ""
+	setDeviceToken(&conn, sbomhub.Token{})
+	resolveTransportSettings(&conn, configDir)
+	client, err := buildAPIClient(&conn)
+	if err != nil {
+		return err
+	}
+	ep, err := client.DiscoverOAuth(ctx, loginOAuthIssuer)
+	if err != nil {
+		return fmt.Errorf("認可サーバの情報を取得できませんでした: %w", err)
+	}
+	da, err := client.StartDeviceAuthorization(ctx, ep, loginOAuthClientID, "")
+	if err != nil {
+		return fmt.Errorf("デバイス認可を開始できませんでした: %w", err)
+	}
+
+	verifyURL := da.VerificationURIComplete
+	if verifyURL == "" {
+		verifyURL = da.VerificationURI
+	}
+	fmt.Println()
+	fmt.Printf("ブラウザで %s を開き、 次のコードを入力してください:\n", verifyURL)
+	fmt.Println()
+	fmt.Printf("    %s\n", da.UserCode)
+	fmt.Println()
+	if !loginNoBrowser {
+		if err := openBrowser(verifyURL); err != nil {
+			printInfo("※ ブラウザを開けませんでした: %v", err)
+		}
+	}
+	fmt.Println("承認を待っています... (Ctrl-C で中止)")
+
+	tok, err := client.PollDeviceToken(ctx, ep, loginOAuthClientID, da)
+	if err != nil {
+		return fmt.Errorf("ログインできませんでした: %w", err)
+	}
 
+	storedIn, err := storeTokens(ctx, cfg, name, *tok, loginCredentialStore)
+	if err != nil {
+		return err
+	}
+	cfg.OAuthTokenURL = ep.TokenEndpoint
+	cfg.OAuthClientID = ""
+	if loginOAuthClientID != sbomhub.DefaultOAuthClientID {
+		cfg.OAuthClientID = loginOAuthClientID
+	}
+	// The tokens replace an API key: drop it, and the stored copy.
+	if cfg.APIKeyRef != "" {
+		eraseProfileKey(ctx, name, cfg)
+	}
+	cfg.APIKey, cfg.APIKeyRef = "", ""
+	f.SetProfile(name, cfg)
+
+	if err := config.WriteFile(f, configDir); err != nil {
+		return fmt.Errorf("設定の保存に失敗しました: %w", err)
+	}
+
+	fmt.Println()
+	printSuccess("ログインが完了しました！ (プロファイル: %s)", name)
+	printInfo("設定ファイル: %s/config.yaml", configDir)
+	printInfo("ログイントークンの保存先: %s", storedIn)
+	if name != f.ResolveName("") {
+		printInfo("このプロファイルを既定にするには 'sbomhub config use-context %s' を実行してください", name)
+	}
 	return nil
 }
+
+// openBrowser opens url in the desktop's default browser. On a headless
+// machine this simply fails and the printed URL is the way in.
+func openBrowser(url string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", url)
+	case "windows":
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		c = exec.Command("xdg-open", url)
+	}
+	return c.Start()
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+)
+
+// deviceLoginServer fakes an SBOMHub server that is its own
+// authorization server. Tokens are "access-<n>" / "refresh-<n>"; only the
+// newest pair is valid, and revoked makes the API reject the current
+// access token until the next one is issued.
+type deviceLoginServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	issued  int
+	revoked bool
+}
+
+func newDeviceLoginServer(t *testing.T) *deviceLoginServer {
+	t.Helper()
+	d := &deviceLoginServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issuer":%q,"device_authorization_endpoint":%q,"token_endpoint":%q}`,
+			d.URL, d.URL+"/oauth/device", d.URL+"/oauth/token")
+	})
+	mux.HandleFunc("POST /oauth/device", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"device_code":"dev-1","user_code":"WXYZ-1234","verification_uri":%q,"expires_in":600,"interval":1}`, d.URL+"/device")
+	})
+	mux.HandleFunc("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if r.PostForm.Get("grant_type") == "refresh_token" && r.PostForm.Get("refresh_token") != fmt.Sprintf("refresh-%d", d.issued) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+		d.issued++
+		d.revoked = false
+		fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","refresh_token":"refresh-%d","expires_in":3600}`, d.issued, d.issued)
+	})
+	mux.HandleFunc("GET /api/v1/cli/projects", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.revoked || r.Header.Get("Authorization") != fmt.Sprintf("Bearer access-%d", d.issued) {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	d.Server = srv
+	return d
+}
+
+func TestRunLogin_DeviceStoresAndRefreshesTokens(t *testing.T) {
+	withCleanCredentialEnv(t)
+	srv := newDeviceLoginServer(t)
+	apiURL = srv.URL
+	saveDevice, saveStore, saveNoBrowser := loginDevice, loginCredentialStore, loginNoBrowser
+	t.Cleanup(func() { loginDevice, loginCredentialStore, loginNoBrowser = saveDevice, saveStore, saveNoBrowser })
+	loginDevice, loginCredentialStore, loginNoBrowser = true, credStoreFile, true
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	globalOutput.Writer, globalOutput.ErrWriter = io.Discard, io.Discard
+
+	dir := getConfigDir()
+	if err := config.Save(&config.Config{APIURL: srv.URL, APIKey: "sbh_old_key"}, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLogin(loginCmd, nil); err != nil {
+		t.Fatalf("runLogin(--device) error = %v", err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	if strings.Contains(string(raw), "sbh_old_key") || !strings.Contains(string(raw), "refresh-1") {
+		t.Fatalf("config.yaml after device login:\n%s\nwant the API key replaced by the tokens", raw)
+	}
+
+	// The server revokes access-1 early; the next command refreshes on
+	// the 401, succeeds, and writes the new tokens back.
+	srv.mu.Lock()
+	srv.revoked = true
+	srv.mu.Unlock()
+	apiURL = ""
+	cfg, err := resolveCredentials(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "access-1" {
+		t.Fatalf("APIKey = %q, want the stored access token", cfg.APIKey)
+	}
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects() = %v, want success after a refresh", err)
+	}
+	f, err := config.ReadFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.AccessToken != "access-2" || f.RefreshToken != "refresh-2" || f.OAuthTokenURL != srv.URL+"/oauth/token" {
+		t.Errorf("stored session = %q / %q / %q, want the refreshed tokens", f.AccessToken, f.RefreshToken, f.OAuthTokenURL)
+	}
+
+	// logout drops the session.
+	if err := runLogout(logoutCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ = config.ReadFile(dir); f.RefreshToken != "" || f.AccessToken != "" || f.OAuthTokenURL != "" {
+		t.Errorf("tokens left after logout: %+v", f.Config)
+	}
+}
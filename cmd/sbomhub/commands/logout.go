@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,8 +19,11 @@ var logoutCmd = &cobra.Command{
 	Short: "SBOMHubからログアウト（API Keyをクリア）",
 	Long: `保存されているAPI Keyをクリアします。
 
-デフォルトではAPI Keyのみをクリアします。
---all フラグを使用すると設定ファイル全体を削除します。`,
+デフォルトでは現在のプロファイル (--profile / SBOMHUB_PROFILE /
+current_context) のAPI Key (と 'login --device' のログイントークン) のみを
+クリアします。 OS キーチェーンや credential_helper に保存したものはそちらから
+も削除します。
+--all フラグを使用すると全プロファイルを含む設定ファイル全体を削除します。`,
 	RunE: runLogout,
 }
 
@@ -29,14 +33,26 @@ func init() {
 }
 
 func runLogout(cmd *cobra.Command, args []string) error {
-	configDir := filepath.Join(os.Getenv("HOME"), ".sbomhub")
-	if os.Getenv("USERPROFILE") != "" {
-		configDir = filepath.Join(os.Getenv("USERPROFILE"), ".sbomhub")
-	}
-
+	configDir := getConfigDir()
 	configPath := filepath.Join(configDir, "config.yaml")
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	if logoutAll {
+		// 設定ファイルを消すと api_key_ref も失われるので、 先に
+		// キーチェーン / helper 側の API Key を削除しておく。
+		if f, err := config.ReadFile(configDir); err == nil {
+			for _, name := range f.Names() {
+				if cfg, err := f.Profile(name); err == nil {
+					if cfg.APIKeyRef != "" {
+						eraseProfileKey(ctx, name, cfg)
+					}
+					clearDeviceSession(ctx, name, cfg)
+				}
+			}
+		}
 		// 設定ファイル全体を削除
 		if err := os.Remove(configPath); err != nil {
 			if os.IsNotExist(err) {
@@ -49,30 +65,60 @@ func runLogout(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// API Keyのみをクリア
-	cfg, err := config.Load(configDir)
+	// 現在のプロファイルのAPI Keyのみをクリア
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		printInfo("設定ファイルが見つかりません")
+		return nil
+	}
+	f, err := config.ReadFile(configDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			printInfo("設定ファイルが見つかりません")
-			return nil
-		}
 		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
 	}
+	profile, _ := activeProfile()
+	name := f.ResolveName(profile)
+	cfg, err := f.Profile(name)
+	if err != nil {
+		return err
+	}
 
-	if cfg.APIKey == "" {
-		printInfo("API Keyは既にクリアされています")
+	if cfg.APIKey == "" && cfg.APIKeyRef == "" && !hasDeviceSession(cfg) {
+		printInfo("API Keyは既にクリアされています (プロファイル: %s)", name)
 		return nil
 	}
 
 	// API Keyをクリアして保存
-	cfg.APIKey = ""
-	if err := config.Save(cfg, configDir); err != nil {
+	if cfg.APIKeyRef != "" {
+		eraseProfileKey(ctx, name, cfg)
+	}
+	cfg.APIKey, cfg.APIKeyRef = "", ""
+	clearDeviceSession(ctx, name, cfg)
+	f.SetProfile(name, cfg)
+	if err := config.WriteFile(f, configDir); err != nil {
 		return fmt.Errorf("設定の保存に失敗しました: %w", err)
 	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = config.DefaultAPIURL
+	}
 
-	printSuccess("ログアウトしました")
+	printSuccess("ログアウトしました (プロファイル: %s)", name)
 	printInfo("API URLは保持されています: %s", cfg.APIURL)
 	printInfo("再度ログインするには 'sbomhub login' を実行してください")
 
 	return nil
 }
+
+// eraseProfileKey removes a profile's key from the store its api_key_ref
+// names. Failure is reported but does not stop the logout: the reference
+// is dropped from config.yaml either way, and the leftover entry can be
+// removed with the keychain's own tools.
+func eraseProfileKey(ctx context.Context, name string, cfg *config.Config) {
+	target := *cfg
+	if target.APIURL == "" {
+		target.APIURL = config.DefaultAPIURL
+	}
+	if err := eraseStoredAPIKey(ctx, &target); err != nil {
+		printInfo("※ %s の API Key を%sから削除できませんでした: %v", name, credStoreLabel(refBackend(cfg.APIKeyRef)), err)
+		return
+	}
+	printInfo("%sから API Key を削除しました (プロファイル: %s)", credStoreLabel(refBackend(cfg.APIKeyRef)), name)
+}
@@ -21,7 +21,7 @@ package commands
 //
 // All four flow through the same M1/M2-aligned regime — credentials
 // via resolveCredentials, output via GetOutputConfig, exit codes via
-// metiExitError (3=permanent, 4=transient) — so the operator gets the
+// the shared table in exitcode.go (3=permanent, 4=transient) — so the operator gets the
 // same UX they learned from `sbomhub triage` / `sbomhub cra`.
 //
 // AI-disabled fallback is NOT applicable to METI: the evaluator is
@@ -44,12 +44,11 @@ package commands
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // Phase allow-list — mirrors handler.isValidPhase / migration 039
@@ -69,18 +68,6 @@ const (
 	metiStatusNotApplicable = "not_applicable"
 )
 
-// metiExitError lets a runMetiXxx return a specific exit code through
-// the cobra error path. Mirrors craExitError / triageExitError so
-// main.go's exitCoder hook covers all three surfaces without a
-// branching type assertion.
-type metiExitError struct {
-	code int
-	msg  string
-}
-
-func (e *metiExitError) Error() string { return e.msg }
-func (e *metiExitError) ExitCode() int { return e.code }
-
 // ---------------------------------------------------------------------------
 // Flag globals (per subcommand) — kept package-scoped to match the
 // existing triage / cra command conventions
@@ -243,10 +230,10 @@ func init() {
 	// shared --project flag — duplicated on each subcommand rather than
 	// a persistent flag on metiCmd because cobra persistent flags
 	// inherit upward, which would pollute the rootCmd help output.
-	metiListCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) / project ID (UUID)")
-	metiRefreshCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) / project ID (UUID)")
-	metiOverrideCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) / project ID (UUID)")
-	metiClearOverrideCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) / project ID (UUID)")
+	metiListCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) または名前 / project ID (UUID) or name")
+	metiRefreshCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) または名前 / project ID (UUID) or name")
+	metiOverrideCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) または名前 / project ID (UUID) or name")
+	metiClearOverrideCmd.Flags().StringVarP(&metiProject, "project", "p", "", "対象プロジェクト ID (UUID) または名前 / project ID (UUID) or name")
 
 	// list
 	metiListCmd.Flags().StringVar(&metiListPhase, "phase", "", "phase で絞り込み / filter by phase (env_setup|sbom_creation|sbom_operation)")
@@ -271,8 +258,8 @@ func init() {
 
 func runMetiList(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
-	if strings.TrimSpace(metiProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &metiProject); err != nil {
+		return err
 	}
 	if metiListPhase != "" {
 		if err := validateMetiPhase(metiListPhase); err != nil {
@@ -292,8 +279,15 @@ func runMetiList(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureMETI, "METI 評価 API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, metiProject)
+	if err != nil {
+		return err
+	}
 
-	filter := api.MetiAssessmentListFilter{
+	filter := sbomhub.MetiAssessmentListFilter{
 		Phase:  metiListPhase,
 		Status: metiListStatus,
 	}
@@ -305,9 +299,9 @@ func runMetiList(cmd *cobra.Command, args []string) error {
 		filter.HasOverride = &v
 	}
 
-	rows, total, err := client.GetAssessment(ctx, metiProject, filter)
+	rows, total, err := client.GetAssessment(ctx, projectID, filter)
 	if err != nil {
-		return metiFailureToExitError("meti list", err)
+		return apiFailure("meti list", err)
 	}
 
 	// Apply --limit cap on the client side. The server still returned
@@ -368,8 +362,8 @@ func runMetiList(cmd *cobra.Command, args []string) error {
 
 func runMetiRefresh(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
-	if strings.TrimSpace(metiProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &metiProject); err != nil {
+		return err
 	}
 	client, err := loadConfigAndClient()
 	if err != nil {
@@ -379,10 +373,20 @@ func runMetiRefresh(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if err := requireScope(ctx, client, sbomhub.ScopeMETIWrite, "meti refresh"); err != nil {
+		return err
+	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureMETI, "METI 評価 API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, metiProject)
+	if err != nil {
+		return err
+	}
 
-	res, err := client.RefreshAssessment(ctx, metiProject)
+	res, err := client.RefreshAssessment(ctx, projectID)
 	if err != nil {
-		return metiFailureToExitError("meti refresh", err)
+		return apiFailure("meti refresh", err)
 	}
 
 	if out.IsJSON() {
@@ -391,7 +395,7 @@ func runMetiRefresh(cmd *cobra.Command, args []string) error {
 
 	w := out.humanWriter()
 	fmt.Fprintf(w, "METI evaluator を再実行しました\n")
-	fmt.Fprintf(w, "  Project           : %s\n", metiProject)
+	fmt.Fprintf(w, "  Project           : %s\n", projectID)
 	fmt.Fprintf(w, "  Refreshed         : %d criterion\n", res.Refreshed)
 	if res.EvaluatorVersion != "" {
 		fmt.Fprintf(w, "  Evaluator version : %s\n", res.EvaluatorVersion)
@@ -421,8 +425,8 @@ func runMetiRefresh(cmd *cobra.Command, args []string) error {
 
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "次のステップ / Next steps:")
-	fmt.Fprintf(w, "  - sbomhub meti list --project %s --status not_achieved  # 残課題を確認\n", metiProject)
-	fmt.Fprintf(w, "  - sbomhub meti override --project %s --criterion <id> --status <status> --note <text>\n", metiProject)
+	fmt.Fprintf(w, "  - sbomhub meti list --project %s --status not_achieved  # 残課題を確認\n", projectID)
+	fmt.Fprintf(w, "  - sbomhub meti override --project %s --criterion <id> --status <status> --note <text>\n", projectID)
 	return nil
 }
 
@@ -432,8 +436,8 @@ func runMetiRefresh(cmd *cobra.Command, args []string) error {
 
 func runMetiOverride(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
-	if strings.TrimSpace(metiProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &metiProject); err != nil {
+		return err
 	}
 	if strings.TrimSpace(metiOverrideCriterion) == "" {
 		return fmt.Errorf("--criterion は必須です / --criterion is required")
@@ -452,8 +456,18 @@ func runMetiOverride(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if err := requireScope(ctx, client, sbomhub.ScopeMETIWrite, "meti override"); err != nil {
+		return err
+	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureMETI, "METI 評価 API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, metiProject)
+	if err != nil {
+		return err
+	}
 
-	req := api.MetiOverrideRequest{
+	req := sbomhub.MetiOverrideRequest{
 		OverrideStatus: metiOverrideStatus,
 		OverrideNote:   metiOverrideNote,
 	}
@@ -466,9 +480,9 @@ func runMetiOverride(cmd *cobra.Command, args []string) error {
 		req.ImprovementAction = &v
 	}
 
-	fresh, err := client.OverrideCriterion(ctx, metiProject, metiOverrideCriterion, req)
+	fresh, err := client.OverrideCriterion(ctx, projectID, metiOverrideCriterion, req)
 	if err != nil {
-		return metiFailureToExitError("meti override", err)
+		return apiFailure("meti override", err)
 	}
 
 	if out.IsJSON() {
@@ -505,8 +519,8 @@ const metiClearOverrideNoteMaxLen = 4096
 
 func runMetiClearOverride(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
-	if strings.TrimSpace(metiProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &metiProject); err != nil {
+		return err
 	}
 	if strings.TrimSpace(metiClearOverrideCriterion) == "" {
 		return fmt.Errorf("--criterion は必須です / --criterion is required")
@@ -527,16 +541,26 @@ func runMetiClearOverride(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if err := requireScope(ctx, client, sbomhub.ScopeMETIWrite, "meti clear-override"); err != nil {
+		return err
+	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureMETI, "METI 評価 API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, metiProject)
+	if err != nil {
+		return err
+	}
 
-	req := api.MetiClearOverrideRequest{Note: cleanedNote}
-	if err := client.ClearOverrideCriterion(ctx, metiProject, metiClearOverrideCriterion, req); err != nil {
-		return metiFailureToExitError("meti clear-override", err)
+	req := sbomhub.MetiClearOverrideRequest{Note: cleanedNote}
+	if err := client.ClearOverrideCriterion(ctx, projectID, metiClearOverrideCriterion, req); err != nil {
+		return apiFailure("meti clear-override", err)
 	}
 
 	if out.IsJSON() {
 		return out.PrintJSON(map[string]interface{}{
 			"cleared":      true,
-			"project_id":   metiProject,
+			"project_id":   projectID,
 			"criterion_id": metiClearOverrideCriterion,
 			"note":         cleanedNote,
 		})
@@ -544,13 +568,13 @@ func runMetiClearOverride(cmd *cobra.Command, args []string) error {
 
 	w := out.humanWriter()
 	fmt.Fprintf(w, "METI criterion %s の上書きを取り消しました\n", metiClearOverrideCriterion)
-	fmt.Fprintf(w, "  Project           : %s\n", metiProject)
+	fmt.Fprintf(w, "  Project           : %s\n", projectID)
 	fmt.Fprintf(w, "  Cleared note      : %s\n", cleanedNote)
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "次のステップ / Next steps:")
-	fmt.Fprintf(w, "  - sbomhub meti list --project %s --has-override   # 取り消し結果を確認\n", metiProject)
+	fmt.Fprintf(w, "  - sbomhub meti list --project %s --has-override   # 取り消し結果を確認\n", projectID)
 	fmt.Fprintf(w, "  - sbomhub meti override --project %s --criterion %s --status <status> --note <text>  # 必要なら新しい上書きを適用\n",
-		metiProject, metiClearOverrideCriterion)
+		projectID, metiClearOverrideCriterion)
 	return nil
 }
 
@@ -558,36 +582,6 @@ func runMetiClearOverride(cmd *cobra.Command, args []string) error {
 // Helpers
 // ---------------------------------------------------------------------------
 
-// metiFailureToExitError translates a METI API error into the exit-code
-// envelope (3=permanent, 4=transient) following the M1 #F21 pattern.
-// Network / unknown errors fall into the transient bucket because the
-// operator's correct response (retry) is the same.
-//
-// Unlike craFailureToExitError there is no AI-disabled branch — the
-// METI evaluator is fully local.
-func metiFailureToExitError(op string, err error) error {
-	if err == nil {
-		return nil
-	}
-	var me *api.MetiError
-	if errors.As(err, &me) {
-		switch {
-		case me.IsPermanent():
-			return &metiExitError{code: 3, msg: fmt.Sprintf("%s 恒久エラー / permanent failure: %v", op, err)}
-		case me.IsTransient():
-			return &metiExitError{code: 4, msg: fmt.Sprintf("%s 一時エラー / transient failure (retry): %v", op, err)}
-		default:
-			// Unknown 4xx that the helpers do not classify (e.g. a
-			// future 451 / 418) — treat as permanent because the
-			// operator's response is "fix something", not "retry".
-			return &metiExitError{code: 3, msg: fmt.Sprintf("%s 不明な失敗: %v", op, err)}
-		}
-	}
-	// Network / JSON parse / context errors — retry is the right
-	// response.
-	return &metiExitError{code: 4, msg: fmt.Sprintf("%s 一時エラー / transient failure (retry): %v", op, err)}
-}
-
 // validateMetiPhase returns nil iff p is in the M3 phase allow-list.
 func validateMetiPhase(p string) error {
 	switch p {
@@ -26,7 +26,7 @@ import (
 	"sync/atomic"
 	"testing"
 
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // ---------------------------------------------------------------------------
@@ -124,7 +124,7 @@ func (tf *metiFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 				break
 			}
 		}
-		var req api.MetiOverrideRequest
+		var req sbomhub.MetiOverrideRequest
 		_ = json.Unmarshal(body, &req)
 		tf.mu.Lock()
 		tf.seenOverrides = append(tf.seenOverrides, capturedMetiOverride{
@@ -139,7 +139,7 @@ func (tf *metiFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 		if tf.overrideResp != nil {
 			status, payload = tf.overrideResp(int(n), criterion, body)
 		} else {
-			payload = api.MetiAssessment{
+			payload = sbomhub.MetiAssessment{
 				ID:             "rid",
 				ProjectID:      "p",
 				CriterionID:    criterion,
@@ -165,7 +165,7 @@ func (tf *metiFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 				break
 			}
 		}
-		var req api.MetiClearOverrideRequest
+		var req sbomhub.MetiClearOverrideRequest
 		_ = json.Unmarshal(body, &req)
 		tf.mu.Lock()
 		tf.seenClearOverrides = append(tf.seenClearOverrides, capturedMetiClearOverride{
@@ -179,7 +179,7 @@ func (tf *metiFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 			status, payload = tf.clearOverrideResp(int(n), criterion, body)
 		} else {
 			// default success: post-clear row with override_* nulled.
-			payload = api.MetiAssessment{
+			payload = sbomhub.MetiAssessment{
 				ID:             "rid",
 				ProjectID:      "p",
 				CriterionID:    criterion,
@@ -246,7 +246,7 @@ func TestMetiList_HappyPath_F28(t *testing.T) {
 				},
 			}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runMetiListAndCapture(t, client, metiListArgs{project: "p"})
 	if res.err != nil {
 		t.Fatalf("list err: %v", res.err)
@@ -287,7 +287,7 @@ func TestMetiList_Pagination_F26(t *testing.T) {
 			map[string]string{"X-Total-Count": strconv.Itoa(pageSize + tailSize)},
 			map[string]interface{}{"assessments": rows}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiListAndCapture(t, client, metiListArgs{project: "p", limit: 5}) // limit just trims display
 	if res.err != nil {
 		t.Fatalf("list err: %v", res.err)
@@ -314,7 +314,7 @@ func TestMetiList_FilterEncoding(t *testing.T) {
 			map[string]string{"X-Total-Count": "0"},
 			map[string]interface{}{"assessments": []map[string]interface{}{}}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	hasOverride := true
 	_, _, _ = runMetiListAndCapture(t, client, metiListArgs{
 		project:     "p",
@@ -342,7 +342,7 @@ func TestMetiList_EmptyProject(t *testing.T) {
 			map[string]string{"X-Total-Count": "0"},
 			map[string]interface{}{"assessments": []map[string]interface{}{}}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runMetiListAndCapture(t, client, metiListArgs{project: "p"})
 	if res.err != nil {
 		t.Fatalf("list err: %v", res.err)
@@ -362,11 +362,11 @@ func TestMetiList_PermanentExit3(t *testing.T) {
 	tf.listResp = func(call int, q map[string][]string) (int, map[string]string, interface{}) {
 		return http.StatusUnauthorized, nil, map[string]string{"error": "invalid api key"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiListAndCapture(t, client, metiListArgs{project: "p"})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3", exitErr.ExitCode())
@@ -380,11 +380,11 @@ func TestMetiList_TransientExit4_503Generic_F22(t *testing.T) {
 	tf.listResp = func(call int, q map[string][]string) (int, map[string]string, interface{}) {
 		return http.StatusServiceUnavailable, nil, map[string]string{"error": "Service Unavailable"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiListAndCapture(t, client, metiListArgs{project: "p"})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F22: err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("F22: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F22: ExitCode = %d, want 4 (generic 503 = transient outage)", exitErr.ExitCode())
@@ -395,7 +395,7 @@ func TestMetiList_TransientExit4_503Generic_F22(t *testing.T) {
 // BEFORE any API call.
 func TestMetiList_InvalidPhase_Validation(t *testing.T) {
 	tf := newMetiFakeServer(t)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiListAndCapture(t, client, metiListArgs{project: "p", phase: "bogus"})
 	if res.err == nil {
 		t.Fatal("expected validation error for invalid --phase")
@@ -412,7 +412,7 @@ func TestMetiList_InvalidPhase_Validation(t *testing.T) {
 // TestMetiRefresh_HappyPath — operator gets the histogram + next steps.
 func TestMetiRefresh_HappyPath(t *testing.T) {
 	tf := newMetiFakeServer(t)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runMetiRefreshAndCapture(t, client, "p")
 	if res.err != nil {
 		t.Fatalf("refresh err: %v", res.err)
@@ -438,11 +438,11 @@ func TestMetiRefresh_ForbiddenPermanent(t *testing.T) {
 	tf.refreshResp = func(call int, body []byte) (int, interface{}) {
 		return http.StatusForbidden, map[string]string{"error": "write permission required"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiRefreshAndCapture(t, client, "p")
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3 (403 = permanent)", exitErr.ExitCode())
@@ -455,11 +455,11 @@ func TestMetiRefresh_TransientExit4_500(t *testing.T) {
 	tf.refreshResp = func(call int, body []byte) (int, interface{}) {
 		return http.StatusInternalServerError, map[string]string{"error": "internal"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiRefreshAndCapture(t, client, "p")
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("ExitCode = %d, want 4 (500 = transient)", exitErr.ExitCode())
@@ -474,11 +474,11 @@ func TestMetiRefresh_ProtocolError_F23(t *testing.T) {
 	tf.refreshResp = func(call int, body []byte) (int, interface{}) {
 		return http.StatusOK, map[string]interface{}{}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiRefreshAndCapture(t, client, "p")
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F23: err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("F23: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F23: ExitCode = %d, want 4 (protocol violation = transient)", exitErr.ExitCode())
@@ -492,7 +492,7 @@ func TestMetiRefresh_ProtocolError_F23(t *testing.T) {
 // TestMetiOverride_HappyPath — PUT body shape + stdout confirmation.
 func TestMetiOverride_HappyPath(t *testing.T) {
 	tf := newMetiFakeServer(t)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runMetiOverrideAndCapture(t, client, metiOverrideArgs{
 		project:    "p",
 		criterion:  "env_setup.policy_documented",
@@ -533,7 +533,7 @@ func TestMetiOverride_HappyPath(t *testing.T) {
 // survives (mirrors CRA EditedDraftText).
 func TestMetiOverride_ImprovementAction_PointerSemantics(t *testing.T) {
 	tf := newMetiFakeServer(t)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiOverrideAndCapture(t, client, metiOverrideArgs{
 		project:           "p",
 		criterion:         "c1",
@@ -564,15 +564,15 @@ func TestMetiOverride_409Permanent_F31(t *testing.T) {
 			"error": "meti assessment has already been overridden; clear the existing override first",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiOverrideAndCapture(t, client, metiOverrideArgs{
 		project:   "p",
 		criterion: "c1",
 		status:    "achieved",
 	})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F31: ExitCode = %d, want 3 (409 already-overridden = permanent)", exitErr.ExitCode())
@@ -585,15 +585,15 @@ func TestMetiOverride_ForbiddenPermanent(t *testing.T) {
 	tf.overrideResp = func(call int, criterion string, body []byte) (int, interface{}) {
 		return http.StatusForbidden, map[string]string{"error": "write permission required"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiOverrideAndCapture(t, client, metiOverrideArgs{
 		project:   "p",
 		criterion: "c1",
 		status:    "achieved",
 	})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3 (403 = permanent)", exitErr.ExitCode())
@@ -604,7 +604,7 @@ func TestMetiOverride_ForbiddenPermanent(t *testing.T) {
 // CLI BEFORE hitting the server.
 func TestMetiOverride_MissingFlags(t *testing.T) {
 	tf := newMetiFakeServer(t)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	cases := []metiOverrideArgs{
 		{project: "p"},                                              // no criterion / status
 		{project: "p", criterion: "c1"},                             // no status
@@ -672,7 +672,7 @@ func TestValidateMetiStatus(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// metiFailureToExitError unit-tests
+// apiFailure unit-tests
 // ---------------------------------------------------------------------------
 
 // TestMetiFailureToExitError_Classification pins the helper's
@@ -684,24 +684,24 @@ func TestMetiFailureToExitError_Classification(t *testing.T) {
 		err      error
 		wantCode int
 	}{
-		{"401 → 3", &api.MetiError{StatusCode: 401}, 3},
-		{"403 → 3", &api.MetiError{StatusCode: 403}, 3},
-		{"404 → 3", &api.MetiError{StatusCode: 404}, 3},
-		{"409 → 3", &api.MetiError{StatusCode: 409}, 3},
-		{"429 → 4", &api.MetiError{StatusCode: 429}, 4},
-		{"500 → 4", &api.MetiError{StatusCode: 500}, 4},
-		{"502 → 4", &api.MetiError{StatusCode: 502}, 4},
-		{"503 generic → 4", &api.MetiError{StatusCode: 503, Message: "Service Unavailable"}, 4},
-		{"protocol → 4", &api.MetiError{StatusCode: 200, ProtocolError: true}, 4},
-		{"unknown 418 → 3", &api.MetiError{StatusCode: 418}, 3},
+		{"401 → 3", &sbomhub.Error{StatusCode: 401}, 3},
+		{"403 → 3", &sbomhub.Error{StatusCode: 403}, 3},
+		{"404 → 3", &sbomhub.Error{StatusCode: 404}, 3},
+		{"409 → 3", &sbomhub.Error{StatusCode: 409}, 3},
+		{"429 → 4", &sbomhub.Error{StatusCode: 429}, 4},
+		{"500 → 4", &sbomhub.Error{StatusCode: 500}, 4},
+		{"502 → 4", &sbomhub.Error{StatusCode: 502}, 4},
+		{"503 generic → 4", &sbomhub.Error{StatusCode: 503, Message: "Service Unavailable"}, 4},
+		{"protocol → 4", &sbomhub.Error{StatusCode: 200, ProtocolError: true}, 4},
+		{"unknown 418 → 3", &sbomhub.Error{StatusCode: 418}, 3},
 		{"network → 4", io.ErrUnexpectedEOF, 4},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := metiFailureToExitError("op", tc.err)
-			exitErr, ok := err.(*metiExitError)
+			err := apiFailure("op", tc.err)
+			exitErr, ok := err.(*exitError)
 			if !ok {
-				t.Fatalf("err = %v (%T), want *metiExitError", err, err)
+				t.Fatalf("err = %v (%T), want *exitError", err, err)
 			}
 			if exitErr.ExitCode() != tc.wantCode {
 				t.Errorf("ExitCode = %d, want %d", exitErr.ExitCode(), tc.wantCode)
@@ -742,7 +742,7 @@ func strPtr(s string) *string { return &s }
 // package globals between cases. The wrapper deliberately mirrors
 // runMetiList step-by-step so a refactor that splits runMetiList will
 // surface here.
-func runMetiListAndCapture(t *testing.T, client *api.Client, args metiListArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
+func runMetiListAndCapture(t *testing.T, client *sbomhub.Client, args metiListArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr}
@@ -762,14 +762,14 @@ func runMetiListAndCapture(t *testing.T, client *api.Client, args metiListArgs)
 	}
 
 	ctx := context.Background()
-	filter := api.MetiAssessmentListFilter{
+	filter := sbomhub.MetiAssessmentListFilter{
 		Phase:       args.phase,
 		Status:      args.status,
 		HasOverride: args.hasOverride,
 	}
 	rows, total, err := client.GetAssessment(ctx, args.project, filter)
 	if err != nil {
-		return capturedResult{err: metiFailureToExitError("meti list", err)}, &stdout, &stderr
+		return capturedResult{err: apiFailure("meti list", err)}, &stdout, &stderr
 	}
 	rendered := rows
 	if args.limit > 0 && len(rendered) > args.limit {
@@ -799,7 +799,7 @@ func runMetiListAndCapture(t *testing.T, client *api.Client, args metiListArgs)
 }
 
 // runMetiRefreshAndCapture mirrors runMetiRefresh step-by-step.
-func runMetiRefreshAndCapture(t *testing.T, client *api.Client, project string) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
+func runMetiRefreshAndCapture(t *testing.T, client *sbomhub.Client, project string) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr}
@@ -809,7 +809,7 @@ func runMetiRefreshAndCapture(t *testing.T, client *api.Client, project string)
 	ctx := context.Background()
 	res, err := client.RefreshAssessment(ctx, project)
 	if err != nil {
-		return capturedResult{err: metiFailureToExitError("meti refresh", err)}, &stdout, &stderr
+		return capturedResult{err: apiFailure("meti refresh", err)}, &stdout, &stderr
 	}
 	fmt.Fprintf(out.Writer, "METI evaluator を再実行しました\n")
 	fmt.Fprintf(out.Writer, "  Refreshed: %d\n", res.Refreshed)
@@ -833,7 +833,7 @@ func runMetiRefreshAndCapture(t *testing.T, client *api.Client, project string)
 }
 
 // runMetiOverrideAndCapture mirrors runMetiOverride step-by-step.
-func runMetiOverrideAndCapture(t *testing.T, client *api.Client, args metiOverrideArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
+func runMetiOverrideAndCapture(t *testing.T, client *sbomhub.Client, args metiOverrideArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr}
@@ -850,14 +850,14 @@ func runMetiOverrideAndCapture(t *testing.T, client *api.Client, args metiOverri
 		return capturedResult{err: err}, &stdout, &stderr
 	}
 	ctx := context.Background()
-	req := api.MetiOverrideRequest{
+	req := sbomhub.MetiOverrideRequest{
 		OverrideStatus:    args.status,
 		OverrideNote:      args.note,
 		ImprovementAction: args.improvementAction,
 	}
 	fresh, err := client.OverrideCriterion(ctx, args.project, args.criterion, req)
 	if err != nil {
-		return capturedResult{err: metiFailureToExitError("meti override", err)}, &stdout, &stderr
+		return capturedResult{err: apiFailure("meti override", err)}, &stdout, &stderr
 	}
 	fmt.Fprintf(out.Writer, "METI criterion %s に上書きを適用しました\n", fresh.CriterionID)
 	fmt.Fprintf(out.Writer, "  Override status: %s\n", fresh.OverrideStatus)
@@ -877,7 +877,7 @@ type metiClearOverrideArgs struct {
 // runMetiClearOverrideAndCapture mirrors runMetiClearOverride step-by-step.
 // Same pattern as runMetiOverrideAndCapture so the test does not have
 // to leak package globals between cases.
-func runMetiClearOverrideAndCapture(t *testing.T, client *api.Client, args metiClearOverrideArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
+func runMetiClearOverrideAndCapture(t *testing.T, client *sbomhub.Client, args metiClearOverrideArgs) (capturedResult, *bytes.Buffer, *bytes.Buffer) {
 	t.Helper()
 	var stdout, stderr bytes.Buffer
 	out := &OutputConfig{Writer: &stdout, ErrWriter: &stderr}
@@ -895,9 +895,9 @@ func runMetiClearOverrideAndCapture(t *testing.T, client *api.Client, args metiC
 		return capturedResult{err: fmt.Errorf("--note は %d 文字以内", metiClearOverrideNoteMaxLen)}, &stdout, &stderr
 	}
 	ctx := context.Background()
-	req := api.MetiClearOverrideRequest{Note: cleanedNote}
+	req := sbomhub.MetiClearOverrideRequest{Note: cleanedNote}
 	if err := client.ClearOverrideCriterion(ctx, args.project, args.criterion, req); err != nil {
-		return capturedResult{err: metiFailureToExitError("meti clear-override", err)}, &stdout, &stderr
+		return capturedResult{err: apiFailure("meti clear-override", err)}, &stdout, &stderr
 	}
 	fmt.Fprintf(out.Writer, "METI criterion %s の上書きを取り消しました\n", args.criterion)
 	fmt.Fprintf(out.Writer, "  Cleared note: %s\n", cleanedNote)
@@ -909,7 +909,7 @@ func runMetiClearOverrideAndCapture(t *testing.T, client *api.Client, args metiC
 // captured request and that the success path returns nil error.
 func TestMetiClearOverride_HappyPath_F36(t *testing.T) {
 	tf := newMetiFakeServer(t)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, stdout, _ := runMetiClearOverrideAndCapture(t, client, metiClearOverrideArgs{
 		project:   "p",
 		criterion: "env_setup.policy_documented",
@@ -946,15 +946,15 @@ func TestMetiClearOverride_404Permanent_F36(t *testing.T) {
 	tf.clearOverrideResp = func(call int, criterion string, body []byte) (int, interface{}) {
 		return http.StatusNotFound, map[string]string{"error": "meti assessment override not found"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiClearOverrideAndCapture(t, client, metiClearOverrideArgs{
 		project:   "p",
 		criterion: "c1",
 		note:      "trying to clear something that is not there",
 	})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F36: err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("F36: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F36: ExitCode = %d, want 3 (404 no override = permanent)", exitErr.ExitCode())
@@ -970,15 +970,15 @@ func TestMetiClearOverride_400NoteRejected_F36(t *testing.T) {
 	tf.clearOverrideResp = func(call int, criterion string, body []byte) (int, interface{}) {
 		return http.StatusBadRequest, map[string]string{"error": "override_note is required and must be 1-4096 characters"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiClearOverrideAndCapture(t, client, metiClearOverrideArgs{
 		project:   "p",
 		criterion: "c1",
 		note:      "non-empty so CLI-side validator lets it through",
 	})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F36: err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("F36: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F36: ExitCode = %d, want 3 (400 note validation = permanent)", exitErr.ExitCode())
@@ -990,7 +990,7 @@ func TestMetiClearOverride_400NoteRejected_F36(t *testing.T) {
 // MissingFlags table-test.
 func TestMetiClearOverride_MissingFlags_F36(t *testing.T) {
 	tf := newMetiFakeServer(t)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	cases := []metiClearOverrideArgs{
 		{project: "p"},                                              // no criterion / note
 		{project: "p", criterion: "c1"},                             // no note
@@ -1020,15 +1020,15 @@ func TestMetiClearOverride_ForbiddenPermanent_F36(t *testing.T) {
 	tf.clearOverrideResp = func(call int, criterion string, body []byte) (int, interface{}) {
 		return http.StatusForbidden, map[string]string{"error": "write permission required"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiClearOverrideAndCapture(t, client, metiClearOverrideArgs{
 		project:   "p",
 		criterion: "c1",
 		note:      "ok",
 	})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F36: err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("F36: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F36: ExitCode = %d, want 3 (403 = permanent)", exitErr.ExitCode())
@@ -1042,15 +1042,15 @@ func TestMetiClearOverride_TransientExit4_500_F36(t *testing.T) {
 	tf.clearOverrideResp = func(call int, criterion string, body []byte) (int, interface{}) {
 		return http.StatusInternalServerError, map[string]string{"error": "internal"}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	res, _, _ := runMetiClearOverrideAndCapture(t, client, metiClearOverrideArgs{
 		project:   "p",
 		criterion: "c1",
 		note:      "ok",
 	})
-	exitErr, ok := res.err.(*metiExitError)
+	exitErr, ok := res.err.(*exitError)
 	if !ok {
-		t.Fatalf("F36: err = %v (%T), want *metiExitError", res.err, res.err)
+		t.Fatalf("F36: err = %v (%T), want *exitError", res.err, res.err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F36: ExitCode = %d, want 4 (500 = transient)", exitErr.ExitCode())
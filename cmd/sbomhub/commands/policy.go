@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/severity"
+	"github.com/youichi-uda/sbomhub-cli/internal/suppress"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// Shared `--policy` plumbing for `scan` and `check`. The rule language
+// lives in internal/severity (policy.go); this file adapts each command's
+// data source into severity.Finding and renders the outcome. Policies are
+// evaluated after `.sbomhubignore` suppression, on the same finding set,
+// so an accepted risk never trips a policy rule either.
+
+// policyJSON is the `policy` object in `scan --json` / `check --json`.
+// Omitted when --policy was not given.
+type policyJSON struct {
+	File     string             `json:"file"`
+	Failed   []severity.RuleHit `json:"failed"`
+	Warned   []severity.RuleHit `json:"warned"`
+	ExitCode int                `json:"exit_code"`
+}
+
+func loadPolicy(path string) (*severity.Policy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	p, err := severity.LoadPolicy(path)
+	if err != nil {
+		return nil, fmt.Errorf("ポリシーファイルの読み込みに失敗しました: %w", err)
+	}
+	return p, nil
+}
+
+// suppressedKeys indexes excluded findings (`.sbomhubignore` and VEX) by
+// (id, package, version) so the policy view of the same data can drop
+// them too.
+func suppressedKeys(excluded []suppress.Finding) map[string]bool {
+	keys := make(map[string]bool, len(excluded))
+	for _, f := range excluded {
+		keys[findingKey(f.ID, f.Package, f.Version)] = true
+	}
+	return keys
+}
+
+func findingKey(id, pkg, version string) string {
+	return strings.ToUpper(id) + "\x00" + pkg + "\x00" + version
+}
+
+// policyFindingsFromVulnRecords adapts the project vulnerability rows
+// used by `scan`. They carry CVSS and KEV but no fix or scope data.
+func policyFindingsFromVulnRecords(recs []sbomhub.VulnerabilityRecord, suppressed map[string]bool) []severity.Finding {
+	out := make([]severity.Finding, 0, len(recs))
+	for _, r := range recs {
+		if suppressed[findingKey(r.CVEID, "", "")] {
+			continue
+		}
+		out = append(out, severity.Finding{
+			ID:        r.CVEID,
+			Severity:  severity.Parse(r.Severity),
+			CVSSScore: r.CVSSScore,
+			// cvss_score is omitempty on the wire; 0 means "not scored".
+			HasCVSS: r.CVSSScore > 0,
+			InKEV:   r.InKEV,
+			HasKEV:  true,
+		})
+	}
+	return out
+}
+
+// policyFindingsFromCheckResult adapts the `check` result. Fix
+// availability comes from fixed_in and scope from the SBOM component;
+// CVSS / KEV only when the server reports them.
+func policyFindingsFromCheckResult(result *sbomhub.CheckResult, comps []sbomhub.ComponentInput, suppressed map[string]bool) []severity.Finding {
+	scopes := make(map[string]string, len(comps))
+	for _, c := range comps {
+		scopes[c.Name+"@"+c.Version] = c.Scope
+	}
+	out := make([]severity.Finding, 0, len(result.Vulnerabilities))
+	for _, v := range result.Vulnerabilities {
+		if suppressed[findingKey(v.ID, v.Package, v.Version)] {
+			continue
+		}
+		f := severity.Finding{
+			ID:           v.ID,
+			Package:      v.Package,
+			Version:      v.Version,
+			Severity:     severity.Parse(v.Severity),
+			CVSSScore:    v.CVSSScore,
+			HasCVSS:      v.CVSSScore > 0,
+			FixAvailable: strings.TrimSpace(v.FixedIn) != "",
+			HasFix:       true,
+			Scope:        scopes[v.Package+"@"+v.Version],
+		}
+		if v.InKEV != nil {
+			f.InKEV, f.HasKEV = *v.InKEV, true
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func buildPolicyJSON(p *severity.Policy, o *severity.Outcome) *policyJSON {
+	if p == nil || o == nil {
+		return nil
+	}
+	return &policyJSON{File: p.Path, Failed: o.Failed, Warned: o.Warned, ExitCode: o.ExitCode}
+}
+
+// printPolicyWarnings reports matched warn rules. Fail rules are reported
+// through the returned exit error instead.
+func printPolicyWarnings(w io.Writer, o *severity.Outcome) {
+	if o == nil {
+		return
+	}
+	for _, h := range o.Warned {
+		fmt.Fprintf(w, "⚠️  policy %q: %d 件 (%s)\n", h.Rule, len(h.Findings), abbreviateList(h.Findings, 5))
+	}
+}
+
+// policyFailureMessage summarises the failing rules for the exit error.
+func policyFailureMessage(o *severity.Outcome) string {
+	parts := make([]string, 0, len(o.Failed))
+	for _, h := range o.Failed {
+		parts = append(parts, fmt.Sprintf("%s (%d 件: %s)", h.Rule, len(h.Findings), abbreviateList(h.Findings, 3)))
+	}
+	return fmt.Sprintf("policy 違反: %s", strings.Join(parts, "; "))
+}
+
+func abbreviateList(items []string, max int) string {
+	if len(items) <= max {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s, … 他 %d 件", strings.Join(items[:max], ", "), len(items)-max)
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/internal/cache"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// Project references for project-scoped commands.
+//
+// triage, cra, meti, sboms and the projects subcommands all take a
+// project as an ID or a name, and used to disagree on what that meant:
+// triage and cra documented names but sent them to /projects/<name>/...,
+// which 404s, and meti only took UUIDs. Every command now goes through
+// projectResolver, which applies sbomhub.FindProject's rules: a UUID is
+// used as is, anything else must be the exact name of one project (an
+// ambiguous or missing name is an error listing the candidates).
+//
+// Resolving a name needs the tenant's project list. It is cached per API
+// URL and profile, so a CI job that runs several commands against the
+// same project walks the list once per TTL. A name found in the cached
+// list is confirmed with a GET of that project before use, and any
+// mismatch (renamed, deleted, another tenant's list) falls back to a
+// fresh list; a stale cache can cost a request, never the wrong project.
+
+// projectResolver resolves project references for one command run. It
+// lists the tenant's projects at most once, however many references the
+// command resolves.
+type projectResolver struct {
+	client *sbomhub.Client
+	cache  *cache.Cache
+	key    string
+
+	projects []sbomhub.Project
+	// fresh is set once projects came from the server during this run.
+	fresh bool
+}
+
+func newProjectResolver(client *sbomhub.Client) *projectResolver {
+	profile, _ := activeProfile()
+	return &projectResolver{
+		client: client,
+		cache:  openCache(),
+		key:    cache.Key(cache.KindProjects, client.BaseURL(), profile),
+	}
+}
+
+// resolveProject resolves a single reference; see projectResolver.
+func resolveProject(ctx context.Context, client *sbomhub.Client, ref string) (string, error) {
+	return newProjectResolver(client).resolve(ctx, ref)
+}
+
+// resolve returns the ID of the project ref names.
+func (r *projectResolver) resolve(ctx context.Context, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if sbomhub.IsProjectID(ref) {
+		return ref, nil
+	}
+	out := GetOutputConfig()
+	if !r.fresh {
+		if p, err := sbomhub.FindProject(r.cached(), ref); err == nil && r.confirm(ctx, p.ID, ref) {
+			out.PrintVerbose("プロジェクト %q → %s (キャッシュ)", ref, p.ID)
+			return p.ID, nil
+		}
+		if err := r.refresh(ctx); err != nil {
+			return "", apiFailure("プロジェクト一覧の取得", err)
+		}
+	}
+	p, err := sbomhub.FindProject(r.projects, ref)
+	if err != nil {
+		return "", projectRefFailure(err)
+	}
+	out.PrintVerbose("プロジェクト %q → %s", ref, p.ID)
+	return p.ID, nil
+}
+
+// cached returns the project list from this run or the local cache, or
+// nil when there is neither.
+func (r *projectResolver) cached() []sbomhub.Project {
+	if r.projects != nil || r.cache == nil {
+		return r.projects
+	}
+	if data, ok := r.cache.Get(cache.KindProjects, r.key, cache.DefaultProjectsTTL); ok {
+		if err := json.Unmarshal(data, &r.projects); err != nil {
+			r.projects = nil
+		}
+	}
+	return r.projects
+}
+
+// confirm reports whether project id still carries ref as its name (or
+// ID) on the server.
+func (r *projectResolver) confirm(ctx context.Context, id, ref string) bool {
+	p, err := r.client.GetProject(ctx, id)
+	return err == nil && (p.ID == ref || p.Name == ref)
+}
+
+// refresh lists the projects from the server and caches the list.
+func (r *projectResolver) refresh(ctx context.Context) error {
+	projects, err := r.client.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	r.projects, r.fresh = projects, true
+	if r.cache != nil {
+		if data, err := json.Marshal(projects); err == nil {
+			if err := r.cache.Put(cache.KindProjects, r.key, data); err != nil {
+				GetOutputConfig().PrintVerbose("プロジェクト一覧キャッシュの保存に失敗しました: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// projectRefFailure renders a *sbomhub.ProjectRefError with its
+// candidates. Either way the reference itself is wrong, so it is a
+// permanent failure.
+func projectRefFailure(err error) error {
+	var refErr *sbomhub.ProjectRefError
+	if !errors.As(err, &refErr) {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString(refErr.Error())
+	switch {
+	case refErr.Ambiguous:
+		b.WriteString(":")
+	case len(refErr.Candidates) > 0:
+		b.WriteString("。 大文字・小文字だけが異なるプロジェクトがあります:")
+	default:
+		b.WriteString(" (ID か正確な名前を指定してください。 一覧: sbomhub projects list)")
+	}
+	for _, p := range refErr.Candidates {
+		fmt.Fprintf(&b, "\n  %s  %s", p.ID, p.Name)
+	}
+	return &exitError{code: exitPermanent, err: err, msg: b.String()}
+}
+
+// requireProjectFlag fills an empty --project of cmd from SBOMHUB_PROJECT,
+// .sbomhub.yaml or the profile (as scan does) and fails when there is
+// still no project.
+func requireProjectFlag(cmd *cobra.Command, value *string) error {
+	if strings.TrimSpace(*value) == "" {
+		if err := applySettingDefaults(cmd, map[string]string{"project": "project"}); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(*value) == "" {
+		return fmt.Errorf("--project は必須です / --project is required (.sbomhub.yaml の project でも指定できます)")
+	}
+	return nil
+}
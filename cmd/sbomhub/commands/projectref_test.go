@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+func TestResolveProject_NamesAndCache(t *testing.T) {
+	client, srv, ids := projectsMock(t, "app", "web")
+	ctx := context.Background()
+	// requests returns the requests made since the previous call.
+	seen := len(srv.Requests())
+	requests := func() []string {
+		all := srv.Requests()
+		var got []string
+		for _, r := range all[seen:] {
+			got = append(got, r.Method+" "+r.Path)
+		}
+		seen = len(all)
+		return got
+	}
+
+	if id, err := resolveProject(ctx, client, ids[1]); err != nil || id != ids[1] {
+		t.Errorf("resolveProject(UUID) = %q, %v", id, err)
+	}
+	if got := requests(); len(got) != 0 {
+		t.Errorf("resolving a UUID sent %v, want no request", got)
+	}
+
+	if id, err := resolveProject(ctx, client, "app"); err != nil || id != ids[0] {
+		t.Fatalf("resolveProject(app) = %q, %v; want %s", id, err, ids[0])
+	}
+	if got := requests(); len(got) != 1 || got[0] != "GET /api/v1/cli/projects" {
+		t.Errorf("first resolution sent %v, want one list", got)
+	}
+
+	// The next run takes the ID from the cached list and only confirms it.
+	if id, err := resolveProject(ctx, client, "web"); err != nil || id != ids[1] {
+		t.Fatalf("resolveProject(web) = %q, %v; want %s", id, err, ids[1])
+	}
+	if got := requests(); len(got) != 1 || got[0] != "GET /api/v1/cli/projects/"+ids[1] {
+		t.Errorf("cached resolution sent %v, want one GET of the project", got)
+	}
+
+	// After a rename the cached ID no longer carries the name; the
+	// resolver must notice and re-list rather than act on the old project.
+	oldName, newName := "web-old", "web"
+	if _, err := client.UpdateProject(ctx, ids[1], sbomhub.UpdateProjectRequest{Name: &oldName}); err != nil {
+		t.Fatal(err)
+	}
+	p, _, err := client.CreateProject(ctx, newName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests()
+	if id, err := resolveProject(ctx, client, "web"); err != nil || id != p.ID {
+		t.Errorf("resolveProject(web) after rename = %q, %v; want the new project %s", id, err, p.ID)
+	}
+	if got := requests(); len(got) != 2 || got[1] != "GET /api/v1/cli/projects" {
+		t.Errorf("stale-cache resolution sent %v, want a GET then a fresh list", got)
+	}
+
+	// A near miss is refused, with the project it probably meant.
+	_, err = resolveProject(ctx, client, "APP")
+	if err == nil || ExitCode(err) != exitPermanent || !strings.Contains(err.Error(), ids[0]) {
+		t.Errorf("resolveProject(APP) = %v, want a permanent error naming %s", err, ids[0])
+	}
+}
+
+func TestProjectRefFailure_ListsAmbiguousCandidates(t *testing.T) {
+	err := projectRefFailure(&sbomhub.ProjectRefError{Ref: "app", Ambiguous: true,
+		Candidates: []sbomhub.Project{{ID: "id-1", Name: "app"}, {ID: "id-2", Name: "app"}}})
+	if ExitCode(err) != exitPermanent {
+		t.Errorf("ExitCode = %d, want %d", ExitCode(err), exitPermanent)
+	}
+	for _, want := range []string{"2 件", "id-1  app", "id-2  app"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err.Error(), want)
+		}
+	}
+}
+
+func TestRunSBOMsList_ProjectFromEnvByName(t *testing.T) {
+	_, _, sbomIDs := sbomsMock(t, 1)
+	var stdout strings.Builder
+	globalOutput.Writer, globalOutput.Quiet = &stdout, false
+
+	// No --project: SBOMHUB_PROJECT supplies it, by name.
+	sbomsProject = ""
+	t.Setenv("SBOMHUB_PROJECT", "app")
+	if err := runSBOMsList(sbomsListCmd, nil); err != nil {
+		t.Fatalf("runSBOMsList() = %v", err)
+	}
+	if !strings.Contains(stdout.String(), sbomIDs[0]) {
+		t.Errorf("list = %q, want the SBOM of project app", stdout.String())
+	}
+
+	sbomsProject = ""
+	t.Setenv("SBOMHUB_PROJECT", "")
+	if err := runSBOMsList(sbomsListCmd, nil); err == nil || !strings.Contains(err.Error(), "--project") {
+		t.Errorf("runSBOMsList() without a project = %v, want the --project error", err)
+	}
+}
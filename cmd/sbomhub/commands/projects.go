@@ -1,26 +1,42 @@
 package commands
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 var (
 	projectDescription string
+
+	projectsUpdateName        string
+	projectsUpdateDescription string
+	projectsDeleteYes         bool
 )
 
 var projectsCmd = &cobra.Command{
 	Use:   "projects",
 	Short: "プロジェクト管理",
-	Long: `プロジェクトの一覧表示、詳細表示、作成を行います。
+	Long: `プロジェクトの一覧表示、詳細表示、作成、更新、アーカイブ、削除を行います。
 
 使用例:
-  sbomhub projects list              # プロジェクト一覧を表示
-  sbomhub projects show <id>         # プロジェクト詳細を表示
-  sbomhub projects create <name>     # プロジェクトを作成`,
+  sbomhub projects list                   # プロジェクト一覧を表示
+  sbomhub projects show <id|name>         # プロジェクト詳細を表示
+  sbomhub projects create <name>          # プロジェクトを作成
+  sbomhub projects update <id|name> --name new-name -d "説明"
+  sbomhub projects archive <id|name>...   # アーカイブ (閲覧のみ、 新規 SBOM は不可)
+  sbomhub projects unarchive <id|name>...
+  sbomhub projects delete <id|name>...    # 削除 (確認あり。 CI では --yes)
+
+プロジェクトは ID (UUID) か正確な名前で指定します。 同じ名前のプロジェクトが
+複数ある場合は ID で指定してください。`,
 }
 
 var projectsListCmd = &cobra.Command{
@@ -30,7 +46,7 @@ var projectsListCmd = &cobra.Command{
 }
 
 var projectsShowCmd = &cobra.Command{
-	Use:   "show <id>",
+	Use:   "show <id|name>",
 	Short: "プロジェクト詳細を表示",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runProjectsShow,
@@ -43,17 +59,60 @@ var projectsCreateCmd = &cobra.Command{
 	RunE:  runProjectsCreate,
 }
 
+var projectsUpdateCmd = &cobra.Command{
+	Use:   "update <id|name>",
+	Short: "プロジェクトの名前・説明を変更",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProjectsUpdate,
+}
+
+var projectsArchiveCmd = &cobra.Command{
+	Use:   "archive <id|name>...",
+	Short: "プロジェクトをアーカイブ",
+	Long: `プロジェクトをアーカイブします。 データは残り閲覧できますが、
+新しい SBOM はアップロードできなくなります。 unarchive で元に戻せます。`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runProjectsArchive,
+}
+
+var projectsUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive <id|name>...",
+	Short: "プロジェクトのアーカイブを解除",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runProjectsUnarchive,
+}
+
+var projectsDeleteCmd = &cobra.Command{
+	Use:   "delete <id|name>...",
+	Short: "プロジェクトを削除",
+	Long: `プロジェクトを SBOM・ 脆弱性・ レポートごと削除します。 元に戻せません。
+
+削除前に対象を表示して確認します。 確認できない環境 (CI 等) では
+--yes を指定してください。 残しておきたい場合は archive を使えます。`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runProjectsDelete,
+}
+
 func init() {
 	rootCmd.AddCommand(projectsCmd)
 	projectsCmd.AddCommand(projectsListCmd)
 	projectsCmd.AddCommand(projectsShowCmd)
 	projectsCmd.AddCommand(projectsCreateCmd)
+	projectsCmd.AddCommand(projectsUpdateCmd)
+	projectsCmd.AddCommand(projectsArchiveCmd)
+	projectsCmd.AddCommand(projectsUnarchiveCmd)
+	projectsCmd.AddCommand(projectsDeleteCmd)
 
 	projectsCreateCmd.Flags().StringVarP(&projectDescription, "description", "d", "", "プロジェクトの説明")
+
+	projectsUpdateCmd.Flags().StringVar(&projectsUpdateName, "name", "", "新しいプロジェクト名")
+	projectsUpdateCmd.Flags().StringVarP(&projectsUpdateDescription, "description", "d", "", "新しい説明 (空文字で削除)")
+
+	projectsDeleteCmd.Flags().BoolVarP(&projectsDeleteYes, "yes", "y", false, "確認せずに削除する (CI 向け)")
 }
 
 // loadConfigAndClient resolves credentials with the documented precedence
-// (CLI flag > env var > config file > default) and returns an api.Client.
+// (CLI flag > env var > config file > default) and returns an sbomhub.Client.
 //
 // Codex R9 fix: previously this used config.Load + a manual --api-key
 // override, which silently ignored SBOMHUB_API_URL and the --api-url flag.
@@ -65,19 +124,19 @@ func init() {
 // where the CLI would still talk to https://api.sbomhub.app. Routing
 // through resolveCredentials (introduced in R2-2e for scan) gives every
 // API-backed command the same precedence semantics.
-func loadConfigAndClient() (*api.Client, error) {
+func loadConfigAndClient() (*sbomhub.Client, error) {
 	cfg, err := resolveCredentials(getConfigDir())
 	if err != nil {
 		return nil, fmt.Errorf("設定の読み込みに失敗しました: %w", err)
 	}
-	if cfg.APIKey == "" {
+	if !hasCredentials(cfg) {
 		return nil, fmt.Errorf("API Keyが設定されていません。 'sbomhub login' で対話設定するか、 --api-key フラグ・ 環境変数 SBOMHUB_API_KEY を指定してください")
 	}
 	if cfg.APIURL == "" {
 		return nil, fmt.Errorf("API URLが設定されていません。 'sbomhub login' で設定するか、 --api-url フラグ・ 環境変数 SBOMHUB_API_URL を指定してください")
 	}
 
-	return api.NewClient(cfg.APIURL, cfg.APIKey), nil
+	return newAPIClient(cfg)
 }
 
 func runProjectsList(cmd *cobra.Command, args []string) error {
@@ -86,49 +145,76 @@ func runProjectsList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	projects, err := client.ListProjects()
-	if err != nil {
-		return fmt.Errorf("プロジェクト一覧の取得に失敗しました: %w", err)
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
 	}
-
 	out := GetOutputConfig()
+	it := client.Projects()
 
 	// JSON output
 	if out.IsJSON() {
+		projects, err := it.All(ctx)
+		if err != nil {
+			return fmt.Errorf("プロジェクト一覧の取得に失敗しました: %w", err)
+		}
 		return out.PrintJSON(map[string]interface{}{
 			"projects": projects,
 			"total":    len(projects),
 		})
 	}
 
-	// Human-readable output
-	if len(projects) == 0 {
+	// Human-readable output is printed page by page, so a large tenant
+	// sees the first rows while later pages are still in flight.
+	count := 0
+	for {
+		projects, ok := it.NextPage(ctx)
+		if !ok {
+			break
+		}
+		if count == 0 && len(projects) > 0 {
+			out.Println("プロジェクト一覧")
+			out.Println("----------------")
+		}
+		for _, p := range projects {
+			state := ""
+			if p.Archived {
+				state = "  (アーカイブ済み)"
+			}
+			out.Print("  %s  %s%s\n", p.ID, p.Name, state)
+			if p.Description != "" {
+				out.Print("      %s\n", p.Description)
+			}
+		}
+		count += len(projects)
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("プロジェクト一覧の取得に失敗しました: %w", err)
+	}
+	if count == 0 {
 		printInfo("プロジェクトがありません")
 		return nil
 	}
-
-	out.Println("プロジェクト一覧")
-	out.Println("----------------")
-	for _, p := range projects {
-		out.Print("  %s  %s\n", p.ID, p.Name)
-		if p.Description != "" {
-			out.Print("      %s\n", p.Description)
-		}
-	}
-	out.Print("\n合計: %d プロジェクト\n", len(projects))
+	out.Print("\n合計: %d プロジェクト\n", count)
 
 	return nil
 }
 
 func runProjectsShow(cmd *cobra.Command, args []string) error {
-	projectID := args[0]
-
 	client, err := loadConfigAndClient()
 	if err != nil {
 		return err
 	}
 
-	project, err := client.GetProject(projectID)
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	projectID, err := resolveProject(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+	project, err := client.GetProject(ctx, projectID)
 	if err != nil {
 		return fmt.Errorf("プロジェクトの取得に失敗しました: %w", err)
 	}
@@ -138,6 +224,9 @@ func runProjectsShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("ID:          %s\n", project.ID)
 	fmt.Printf("名前:        %s\n", project.Name)
 	fmt.Printf("説明:        %s\n", project.Description)
+	if project.Archived {
+		fmt.Printf("状態:        アーカイブ済み %s\n", project.ArchivedAt)
+	}
 	if project.CreatedAt != "" {
 		if t, err := time.Parse(time.RFC3339, project.CreatedAt); err == nil {
 			fmt.Printf("作成日時:    %s\n", t.Format("2006-01-02 15:04:05"))
@@ -160,7 +249,11 @@ func runProjectsCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	project, created, err := client.CreateProject(projectName, projectDescription)
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	project, created, err := client.CreateProject(ctx, projectName, projectDescription)
 	if err != nil {
 		return fmt.Errorf("プロジェクトの作成に失敗しました: %w", err)
 	}
@@ -179,3 +272,210 @@ func runProjectsCreate(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runProjectsUpdate(cmd *cobra.Command, args []string) error {
+	var req sbomhub.UpdateProjectRequest
+	if cmd.Flags().Changed("name") {
+		req.Name = &projectsUpdateName
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = &projectsUpdateDescription
+	}
+	if req.Name == nil && req.Description == nil {
+		return fmt.Errorf("--name または --description を指定してください")
+	}
+
+	client, err := loadConfigAndClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := requireScope(ctx, client, sbomhub.ScopeProjectsWrite, "projects update"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+	project, err := client.UpdateProject(ctx, projectID, req)
+	if err != nil {
+		return projectFailure("projects update", projectID, err)
+	}
+
+	out := GetOutputConfig()
+	return out.PrintResult(project, func() {
+		printSuccess("プロジェクトを更新しました")
+		out.Print("  ID:   %s\n", project.ID)
+		out.Print("  名前: %s\n", project.Name)
+		if project.Description != "" {
+			out.Print("  説明: %s\n", project.Description)
+		}
+	})
+}
+
+func runProjectsArchive(cmd *cobra.Command, args []string) error {
+	return setProjectsArchived(cmd, args, true)
+}
+
+func runProjectsUnarchive(cmd *cobra.Command, args []string) error {
+	return setProjectsArchived(cmd, args, false)
+}
+
+// setProjectsArchived archives (or unarchives) every project in refs.
+// One failure does not stop the rest: cleaning up a tenant's stale
+// projects should not halt on the first ID someone already removed.
+func setProjectsArchived(cmd *cobra.Command, refs []string, archive bool) error {
+	op, done := "projects unarchive", "アーカイブを解除しました"
+	call := (*sbomhub.Client).UnarchiveProject
+	if archive {
+		op, done = "projects archive", "アーカイブしました"
+		call = (*sbomhub.Client).ArchiveProject
+	}
+
+	client, err := loadConfigAndClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := requireScope(ctx, client, sbomhub.ScopeProjectsWrite, op); err != nil {
+		return err
+	}
+
+	out := GetOutputConfig()
+	resolver := newProjectResolver(client)
+	projects := make([]*sbomhub.Project, 0, len(refs))
+	var failures []error
+	for _, ref := range refs {
+		id, err := resolver.resolve(ctx, ref)
+		if err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		project, err := call(client, ctx, id)
+		if err != nil {
+			failures = append(failures, projectFailure(op, id, err))
+			continue
+		}
+		projects = append(projects, project)
+		printSuccess("%s  %s: %s", project.ID, project.Name, done)
+	}
+	if out.IsJSON() {
+		if err := out.PrintJSON(map[string]interface{}{"projects": projects}); err != nil {
+			return err
+		}
+	}
+	return joinProjectFailures(op, len(refs), failures)
+}
+
+func runProjectsDelete(cmd *cobra.Command, args []string) error {
+	client, err := loadConfigAndClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := requireScope(ctx, client, sbomhub.ScopeProjectsWrite, "projects delete"); err != nil {
+		return err
+	}
+
+	// Resolve every reference first, so the prompt shows names and a typo
+	// fails before anything is deleted.
+	resolver := newProjectResolver(client)
+	projects := make([]*sbomhub.Project, 0, len(args))
+	for _, ref := range args {
+		id, err := resolver.resolve(ctx, ref)
+		if err != nil {
+			return err
+		}
+		project, err := client.GetProject(ctx, id)
+		if err != nil {
+			return projectFailure("projects delete", id, err)
+		}
+		projects = append(projects, project)
+	}
+
+	out := GetOutputConfig()
+	if !projectsDeleteYes {
+		fmt.Fprintf(out.ErrWriter, "次の %d 件のプロジェクトを SBOM・ 脆弱性・ レポートごと削除します。 元に戻せません:\n", len(projects))
+		for _, p := range projects {
+			fmt.Fprintf(out.ErrWriter, "  %s  %s\n", p.ID, p.Name)
+		}
+		if !confirm(cmd.InOrStdin(), out.ErrWriter, "削除しますか? [y/N]: ") {
+			return &exitError{code: exitFailure, msg: "削除を中止しました (確認なしで削除するには --yes を指定してください)"}
+		}
+	}
+
+	deleted := make([]string, 0, len(projects))
+	var failures []error
+	for _, p := range projects {
+		if err := client.DeleteProject(ctx, p.ID); err != nil {
+			failures = append(failures, projectFailure("projects delete", p.ID, err))
+			continue
+		}
+		deleted = append(deleted, p.ID)
+		printSuccess("%s  %s: 削除しました", p.ID, p.Name)
+	}
+	if out.IsJSON() {
+		if err := out.PrintJSON(map[string]interface{}{"deleted": deleted}); err != nil {
+			return err
+		}
+	}
+	return joinProjectFailures("projects delete", len(projects), failures)
+}
+
+// confirm asks prompt on w and reads the answer from in. Only y / yes
+// agree; EOF (no terminal to answer) counts as no.
+func confirm(in io.Reader, w io.Writer, prompt string) bool {
+	fmt.Fprint(w, prompt)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	a := strings.ToLower(strings.TrimSpace(answer))
+	return (err == nil || err == io.EOF) && (a == "y" || a == "yes")
+}
+
+// projectFailure is apiFailure for a call on project id, with the two
+// answers an operator can act on without reading the status code: the
+// project is not there (or not visible to this key), or the change
+// conflicts with another project.
+func projectFailure(op, id string, err error) error {
+	if ae, ok := sbomhub.AsError(err); ok {
+		switch {
+		case ae.IsNotFound():
+			return &exitError{code: exitPermanent, err: err,
+				msg: fmt.Sprintf("%s: プロジェクト %s が見つかりません (この API Key のテナントにありません)", op, id)}
+		case ae.IsConflict():
+			return &exitError{code: exitPermanent, err: err,
+				msg: fmt.Sprintf("%s: プロジェクト %s を変更できません: %s", op, id, ae.Detail())}
+		}
+	}
+	return apiFailure(op, err)
+}
+
+// joinProjectFailures turns the per-project failures of a batch into the
+// command's error: each one's message, and the exit code of the first.
+func joinProjectFailures(op string, total int, failures []error) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	if total == 1 {
+		return failures[0]
+	}
+	msgs := make([]string, len(failures))
+	for i, f := range failures {
+		msgs[i] = "  " + f.Error()
+	}
+	code := exitFailure
+	var ee *exitError
+	if errors.As(failures[0], &ee) {
+		code = ee.code
+	}
+	return &exitError{code: code, err: failures[0],
+		msg: fmt.Sprintf("%s: %d 件中 %d 件が失敗しました:\n%s", op, total, len(failures), strings.Join(msgs, "\n"))}
+}
@@ -1,14 +1,20 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/internal/cache"
 	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/internal/mockserver"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // withCleanCredentialEnv snapshots and restores the credential package
@@ -16,16 +22,22 @@ import (
 // don't inherit stale values from the developer's shell).
 func withCleanCredentialEnv(t *testing.T) {
 	t.Helper()
-	saveURL, saveKey := apiURL, apiKey
-	t.Cleanup(func() { apiURL, apiKey = saveURL, saveKey })
+	saveURL, saveKey, saveProfile := apiURL, apiKey, profileFlag
+	t.Cleanup(func() { apiURL, apiKey, profileFlag = saveURL, saveKey, saveProfile })
 	apiURL = ""
 	apiKey = ""
+	profileFlag = ""
 	t.Setenv("SBOMHUB_API_URL", "")
 	t.Setenv("SBOMHUB_API_KEY", "")
+	t.Setenv("SBOMHUB_PROFILE", "")
+	clearCIIdentityEnv(t)
 	// Point credential lookups at an empty fake HOME so we don't accidentally
 	// read the developer's real ~/.sbomhub/config.yaml.
 	t.Setenv("HOME", t.TempDir())
 	t.Setenv("USERPROFILE", "")
+	// Likewise the host's /etc/sbomhub and any XDG directory.
+	t.Setenv("SBOMHUB_SYSTEM_CONFIG_DIR", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
 }
 
 // TestLoadConfigAndClient_HonorsAPIURLFromEnv verifies the Codex R9 fix:
@@ -56,7 +68,7 @@ func TestLoadConfigAndClient_HonorsAPIURLFromEnv(t *testing.T) {
 		if got := r.Header.Get("Authorization"); got != "Bearer sbh_env_key" {
 			t.Errorf("Authorization header = %q, want %q (env API key not propagated)", got, "Bearer sbh_env_key")
 		}
-		_ = json.NewEncoder(w).Encode(api.ProjectsListResponse{Projects: []api.Project{}, Total: 0})
+		_ = json.NewEncoder(w).Encode(sbomhub.ProjectsListResponse{Projects: []sbomhub.Project{}, Total: 0})
 	}))
 	defer server.Close()
 
@@ -68,7 +80,7 @@ func TestLoadConfigAndClient_HonorsAPIURLFromEnv(t *testing.T) {
 		t.Fatalf("loadConfigAndClient() error = %v on second resolve", err)
 	}
 
-	if _, err := client.ListProjects(); err != nil {
+	if _, err := client.ListProjects(context.Background()); err != nil {
 		t.Fatalf("ListProjects() error = %v; expected the env-configured server to be reached", err)
 	}
 	if !hit {
@@ -90,7 +102,7 @@ func TestLoadConfigAndClient_FlagBeatsEnv(t *testing.T) {
 		if got := r.Header.Get("Authorization"); got != "Bearer sbh_flag_key" {
 			t.Errorf("Authorization header = %q, want %q (CLI flag key not propagated)", got, "Bearer sbh_flag_key")
 		}
-		_ = json.NewEncoder(w).Encode(api.ProjectsListResponse{Projects: []api.Project{}, Total: 0})
+		_ = json.NewEncoder(w).Encode(sbomhub.ProjectsListResponse{Projects: []sbomhub.Project{}, Total: 0})
 	}))
 	defer server.Close()
 
@@ -102,7 +114,7 @@ func TestLoadConfigAndClient_FlagBeatsEnv(t *testing.T) {
 	if err != nil {
 		t.Fatalf("loadConfigAndClient() error = %v", err)
 	}
-	if _, err := client.ListProjects(); err != nil {
+	if _, err := client.ListProjects(context.Background()); err != nil {
 		t.Fatalf("ListProjects() error = %v", err)
 	}
 	if !hit {
@@ -154,7 +166,7 @@ func TestLoadConfigAndClient_ConfigFileFallback(t *testing.T) {
 	var hit bool
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		hit = true
-		_ = json.NewEncoder(w).Encode(api.ProjectsListResponse{Projects: []api.Project{}, Total: 0})
+		_ = json.NewEncoder(w).Encode(sbomhub.ProjectsListResponse{Projects: []sbomhub.Project{}, Total: 0})
 	}))
 	defer server.Close()
 
@@ -167,10 +179,169 @@ func TestLoadConfigAndClient_ConfigFileFallback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("loadConfigAndClient() error = %v", err)
 	}
-	if _, err := client.ListProjects(); err != nil {
+	if _, err := client.ListProjects(context.Background()); err != nil {
 		t.Fatalf("ListProjects() error = %v", err)
 	}
 	if !hit {
 		t.Error("server never received request — env URL did not override file URL")
 	}
 }
+
+// TestRunProjectsList_StreamsPagesBeforeFailure pins the page-by-page
+// rendering: rows from the first page are already printed when a later
+// page fails, and the failure still surfaces as the command's error.
+func TestRunProjectsList_StreamsPagesBeforeFailure(t *testing.T) {
+	withCleanCredentialEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"forbidden"}`))
+			return
+		}
+		projects := make([]sbomhub.Project, 100)
+		for i := range projects {
+			projects[i] = sbomhub.Project{ID: "id-" + strconv.Itoa(i), Name: "p" + strconv.Itoa(i)}
+		}
+		_ = json.NewEncoder(w).Encode(sbomhub.ProjectsListResponse{Projects: projects, Total: 150})
+	}))
+	defer server.Close()
+	t.Setenv("SBOMHUB_API_URL", server.URL)
+	t.Setenv("SBOMHUB_API_KEY", "k")
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.ErrWriter, globalOutput.JSON, globalOutput.Quiet = &stdout, io.Discard, false, false
+
+	err := runProjectsList(projectsListCmd, nil)
+	if err == nil {
+		t.Fatal("runProjectsList() = nil, want the second page's 403")
+	}
+	if !strings.Contains(stdout.String(), "id-99  p99") || strings.Contains(stdout.String(), "合計") {
+		t.Errorf("stdout = %q, want the first page's rows and no total", stdout.String())
+	}
+}
+
+// projectsMock starts a mock server holding the named projects, points
+// the CLI (and the project cache) at it and returns a client on it, the
+// server, so tests can count the requests a command costs, and the
+// projects' IDs.
+func projectsMock(t *testing.T, names ...string) (*sbomhub.Client, *mockserver.Server, []string) {
+	t.Helper()
+	withCleanCredentialEnv(t)
+	t.Setenv(cache.DirEnv, t.TempDir())
+	srv := mockserver.New(mockserver.Options{APIKey: "sbh_test"})
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	t.Setenv("SBOMHUB_API_URL", ts.URL)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_test")
+	client := sbomhub.NewClient(ts.URL, "sbh_test")
+	ids := make([]string, len(names))
+	for i, name := range names {
+		p, _, err := client.CreateProject(context.Background(), name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = p.ID
+	}
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	globalOutput.Writer, globalOutput.ErrWriter, globalOutput.JSON = io.Discard, io.Discard, false
+	return client, srv, ids
+}
+
+func projectNames(t *testing.T, client *sbomhub.Client) []string {
+	t.Helper()
+	projects, err := client.ListProjects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func TestRunProjectsDelete_Confirms(t *testing.T) {
+	client, _, ids := projectsMock(t, "keep", "stale-1", "stale-2")
+	saveYes := projectsDeleteYes
+	t.Cleanup(func() { projectsDeleteYes = saveYes; projectsDeleteCmd.SetIn(nil) })
+
+	// No answer (EOF) and "n" both keep everything.
+	for _, answer := range []string{"", "n\n"} {
+		projectsDeleteCmd.SetIn(strings.NewReader(answer))
+		if err := runProjectsDelete(projectsDeleteCmd, ids[1:]); err == nil || ExitCode(err) != exitFailure {
+			t.Errorf("answer %q: runProjectsDelete() = %v, want an abort", answer, err)
+		}
+	}
+	// An unknown ID fails before anything is deleted.
+	projectsDeleteYes = true
+	err := runProjectsDelete(projectsDeleteCmd, []string{ids[1], "no-such-project"})
+	if err == nil || ExitCode(err) != exitPermanent || !strings.Contains(err.Error(), "no-such-project") {
+		t.Errorf("unknown ID: runProjectsDelete() = %v, want a permanent not-found error", err)
+	}
+	if got := projectNames(t, client); len(got) != 3 {
+		t.Fatalf("projects after aborted deletes = %v, want all 3", got)
+	}
+
+	projectsDeleteYes = false
+	projectsDeleteCmd.SetIn(strings.NewReader("y\n"))
+	if err := runProjectsDelete(projectsDeleteCmd, ids[1:]); err != nil {
+		t.Fatalf("runProjectsDelete() after yes = %v", err)
+	}
+	if got := projectNames(t, client); len(got) != 1 || got[0] != "keep" {
+		t.Errorf("projects after delete = %v, want [keep]", got)
+	}
+}
+
+func TestRunProjectsUpdate_SendsChangedFlagsOnly(t *testing.T) {
+	client, _, ids := projectsMock(t, "app")
+	if _, err := client.UpdateProject(context.Background(), ids[0], sbomhub.UpdateProjectRequest{Description: strPtr("old")}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, name := range []string{"name", "description"} {
+			_ = projectsUpdateCmd.Flags().Set(name, "")
+			projectsUpdateCmd.Flags().Lookup(name).Changed = false
+		}
+	})
+
+	if err := runProjectsUpdate(projectsUpdateCmd, ids); err == nil {
+		t.Error("runProjectsUpdate() without flags: want an error")
+	}
+	if err := projectsUpdateCmd.Flags().Set("name", "app-renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runProjectsUpdate(projectsUpdateCmd, ids); err != nil {
+		t.Fatalf("runProjectsUpdate() = %v", err)
+	}
+	p, err := client.GetProject(context.Background(), ids[0])
+	if err != nil || p.Name != "app-renamed" || p.Description != "old" {
+		t.Errorf("project after --name = %+v, %v; want the description untouched", p, err)
+	}
+}
+
+func TestRunProjectsArchive_ContinuesPastFailures(t *testing.T) {
+	client, _, ids := projectsMock(t, "a", "b")
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.JSON = &stdout, true
+
+	err := runProjectsArchive(projectsArchiveCmd, []string{ids[0], "missing", ids[1]})
+	if err == nil || ExitCode(err) != exitPermanent || !strings.Contains(err.Error(), "3 件中 1 件") {
+		t.Errorf("runProjectsArchive() = %v, want one permanent failure out of 3", err)
+	}
+	var got struct {
+		Projects []sbomhub.Project `json:"projects"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil || len(got.Projects) != 2 || !got.Projects[1].Archived {
+		t.Errorf("archive --json = %s (%v), want both archived projects", stdout.String(), err)
+	}
+
+	globalOutput.Writer, globalOutput.JSON = io.Discard, false
+	if err := runProjectsUnarchive(projectsUnarchiveCmd, ids[:1]); err != nil {
+		t.Fatalf("runProjectsUnarchive() = %v", err)
+	}
+	if p, err := client.GetProject(context.Background(), ids[0]); err != nil || p.Archived {
+		t.Errorf("project after unarchive = %+v, %v", p, err)
+	}
+}
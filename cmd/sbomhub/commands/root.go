@@ -1,10 +1,19 @@
 package commands
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 var (
@@ -16,10 +25,30 @@ var (
 	apiURL  string
 	apiKey  string
 
+	// profileFlag selects a named profile in config.yaml (see
+	// activeProfile). Empty means SBOMHUB_PROFILE, then current_context.
+	profileFlag string
+
 	// Global output flags
 	quietFlag   bool
 	verboseFlag bool
 	jsonFlag    bool
+
+	// noCacheFlag disables the local SBOM / check result cache (cache.go).
+	noCacheFlag bool
+
+	// maxRetriesFlag is the automatic retry budget applied to every API
+	// request (internal/api/request.go). 0 disables retries.
+	maxRetriesFlag int
+
+	// TLS / proxy flags (transport.go in internal/api). Empty / false
+	// means "fall through to env, then config file".
+	caCertFlag             string
+	clientCertFlag         string
+	clientKeyFlag          string
+	proxyFlag              string
+	noProxyFlag            string
+	insecureSkipVerifyFlag bool
 )
 
 var rootCmd = &cobra.Command{
@@ -36,15 +65,53 @@ var rootCmd = &cobra.Command{
 グローバルフラグ:
   --quiet, -q    エラー以外の出力を抑制
   --verbose, -v  詳細出力
-  --json         JSON形式で出力`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+  --json         JSON形式で出力
+  --no-cache     ローカルキャッシュを使用しない
+  --max-retries  API リクエストの自動リトライ回数 (0 で無効)
+  --trace[=FILE] API リクエスト / レスポンスを stderr (または FILE) に記録
+  --trace-har    API リクエスト / レスポンスを HAR ファイルに書き出す`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Initialize output configuration from global flags
 		InitOutputConfig(quietFlag, verboseFlag, jsonFlag)
+		return startTrace()
 	},
 }
 
+// interruptedError replaces whatever error a command returned after the
+// run was interrupted; the command's own error (usually a wrapped
+// context.Canceled) is kept for the message.
+type interruptedError struct{ err error }
+
+func (e *interruptedError) Error() string { return "中断されました: " + e.err.Error() }
+func (e *interruptedError) Unwrap() error { return e.err }
+func (e *interruptedError) ExitCode() int { return exitInterrupted }
+
+// Execute runs the root command with a context that is cancelled on
+// SIGINT / SIGTERM. Commands read it via cmd.Context() and pass it to
+// the API client and scanner, so Ctrl-C aborts an in-flight upload or
+// poll and stops the SBOM tool's subprocess instead of waiting them out.
+// After the first signal the default handler is restored, so a second
+// Ctrl-C kills the process immediately.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return executeContext(ctx)
+}
+
+// executeContext runs the root command under ctx and maps a failure
+// that happened after ctx was cancelled to exitInterrupted.
+func executeContext(ctx context.Context) error {
+	defer finishTrace()
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		return &interruptedError{err: err}
+	}
+	return err
 }
 
 func SetVersion(v, c, d string) {
@@ -60,11 +127,25 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "設定ファイルのパス (デフォルト: ~/.sbomhub/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "SBOMHub API URL")
 	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "SBOMHub API Key (環境変数 SBOMHUB_API_KEY でも指定可)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "使用する設定プロファイル (環境変数 SBOMHUB_PROFILE でも指定可。 デフォルト: config.yaml の current_context)")
 
 	// Global output flags
 	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "エラー以外の出力を抑制")
 	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "詳細出力")
 	rootCmd.PersistentFlags().BoolVar(&jsonFlag, "json", false, "JSON形式で出力")
+	rootCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "SBOM 生成結果・ check 結果のローカルキャッシュを使用しない")
+	rootCmd.PersistentFlags().StringVar(&traceFlag, "trace", "", "API リクエスト / レスポンスを記録 (--trace で stderr、 --trace=FILE でファイル。 Authorization は伏字)")
+	rootCmd.PersistentFlags().Lookup("trace").NoOptDefVal = "-"
+	rootCmd.PersistentFlags().StringVar(&traceHARFlag, "trace-har", "", "API リクエスト / レスポンスを HAR 1.2 形式で書き出すファイル")
+	rootCmd.PersistentFlags().IntVar(&maxRetriesFlag, "max-retries", sbomhub.DefaultRetryPolicy.MaxRetries, "429 / 5xx / 通信エラー時の API リクエスト自動リトライ回数 (0 で無効)")
+
+	// TLS / proxy flags
+	rootCmd.PersistentFlags().StringVar(&caCertFlag, "ca-cert", "", "追加で信頼する CA 証明書 (PEM) のパス (環境変数 SBOMHUB_CA_CERT でも指定可)")
+	rootCmd.PersistentFlags().StringVar(&clientCertFlag, "client-cert", "", "mTLS 用クライアント証明書 (PEM) のパス")
+	rootCmd.PersistentFlags().StringVar(&clientKeyFlag, "client-key", "", "mTLS 用クライアント秘密鍵 (PEM) のパス")
+	rootCmd.PersistentFlags().StringVar(&proxyFlag, "proxy", "", "HTTP(S) プロキシ URL (HTTPS_PROXY より優先)")
+	rootCmd.PersistentFlags().StringVar(&noProxyFlag, "no-proxy", "", "プロキシを経由しないホスト (カンマ区切り、 NO_PROXY と同じ書式)")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerifyFlag, "insecure-skip-verify", false, "サーバ証明書の検証を無効化 (危険: 診断目的のみ)")
 }
 
 func initConfig() {
@@ -90,30 +171,173 @@ func printInfo(msg string, args ...interface{}) {
 	out.PrintInfo(msg, args...)
 }
 
+// newAPIClient builds the SDK client for cfg with the retry budget
+// (--max-retries, SBOMHUB_MAX_RETRIES or the profile's max_retries), the --trace tracer, the TLS / proxy settings and the CLI's
+// User-Agent applied. Every API-backed command goes through here so the
+// retry and dial behaviour is the same whichever command hits a 502 or an
+// internal-CA gateway. opts are applied last, so a caller can narrow the
+// defaults (doctor / version use a single attempt). The error is a
+// transport configuration problem (unreadable CA bundle, half an mTLS
+// pair, bad proxy URL) and is reported before any request is made.
+// A profile signed in with `login --device` also gets a token source
+// that refreshes the access token and writes the new one back, and a CI
+// job with no key one that exchanges its identity token on first use
+// (see ciTokenSource).
+func newAPIClient(cfg *config.Config, opts ...sbomhub.Option) (*sbomhub.Client, error) {
+	if ciIdentityProvider(cfg) != "" {
+		opts = append([]sbomhub.Option{sbomhub.WithTokenSource(&ciTokenSource{cfg: cfg})}, opts...)
+	}
+	return buildAPIClient(cfg, opts...)
+}
+
+// buildAPIClient is newAPIClient without the CI identity exchange, for
+// clients that make no authenticated call: the exchange itself, device
+// login and capability or health probes.
+func buildAPIClient(cfg *config.Config, opts ...sbomhub.Option) (*sbomhub.Client, error) {
+	policy := sbomhub.DefaultRetryPolicy
+	policy.MaxRetries = effectiveMaxRetries(cfg)
+	if policy.MaxRetries < 0 {
+		policy.MaxRetries = 0
+	}
+	base := []sbomhub.Option{
+		sbomhub.WithRetryPolicy(policy),
+		sbomhub.WithUserAgent(cliUserAgent()),
+	}
+	if activeTracer != nil {
+		base = append(base, sbomhub.WithTracer(activeTracer))
+	}
+
+	rt, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if rt != nil {
+		base = append(base, sbomhub.WithTransport(rt))
+	}
+	if cfg.RefreshToken != "" && cfg.OAuthTokenURL != "" && cfg.APIKey == cfg.AccessToken {
+		clientID := cfg.OAuthClientID
+		if clientID == "" {
+			clientID = sbomhub.DefaultOAuthClientID
+		}
+		base = append(base, sbomhub.WithOAuthToken(cfg.OAuthTokenURL, clientID, deviceToken(cfg), persistRefreshedToken(cfg.RefreshToken)))
+	}
+	return sbomhub.NewClient(cfg.APIURL, cfg.APIKey, append(base, opts...)...), nil
+}
+
+// cliUserAgent identifies this build in server logs, e.g.
+// "sbomhub-cli/1.4.0 (linux/amd64)".
+func cliUserAgent() string {
+	return fmt.Sprintf("sbomhub-cli/%s (%s/%s)", version, runtime.GOOS, runtime.GOARCH)
+}
+
+// transportOptions maps the resolved TLS / proxy settings of cfg onto
+// sbomhub.TransportOptions.
+func transportOptions(cfg *config.Config) sbomhub.TransportOptions {
+	return sbomhub.TransportOptions{
+		CACertFile:         cfg.CACert,
+		ClientCertFile:     cfg.ClientCert,
+		ClientKeyFile:      cfg.ClientKey,
+		Proxy:              cfg.Proxy,
+		NoProxy:            cfg.NoProxy,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+}
+
+// newTransport builds the shared transport for cfg, or returns (nil, nil)
+// when nothing is configured so callers keep Go's default transport.
+//
+// insecure_skip_verify is deliberately loud: it prints a warning on every
+// invocation (even with --quiet) because a forgotten opt-in silently
+// exposes the API key to anyone who can intercept the connection.
+func newTransport(cfg *config.Config) (*http.Transport, error) {
+	opts := transportOptions(cfg)
+	if opts.IsZero() {
+		return nil, nil
+	}
+	if opts.InsecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "⚠️  警告: insecure_skip_verify が有効です。 サーバ証明書を検証しません (API Key が盗聴・改ざんされる恐れがあります)")
+	}
+	tr, err := sbomhub.NewTransport(opts)
+	if err != nil {
+		return nil, fmt.Errorf("TLS / プロキシ設定が不正です: %w", err)
+	}
+	return tr, nil
+}
+
+// configureHTTPClient applies cfg's TLS / proxy settings to a non-API
+// HTTP client (doctor probes, llm bench downloads) so it dials exactly
+// like the API client does.
+func configureHTTPClient(c *http.Client, cfg *config.Config) error {
+	rt, err := newTransport(cfg)
+	if err != nil {
+		return err
+	}
+	if rt != nil {
+		c.Transport = rt
+	}
+	return nil
+}
+
 // resolveCredentials merges credential sources into a single *config.Config
-// suitable for instantiating api.Client.
+// suitable for instantiating sbomhub.Client.
 //
 // Source precedence (highest wins):
 //  1. CLI flag       (--api-url / --api-key)
 //  2. Environment    (SBOMHUB_API_URL / SBOMHUB_API_KEY)
-//  3. Config file    (~/.sbomhub/config.yaml)
+//  3. Config files   (the active profile merged over the system, user and
+//     XDG config.yaml — see loadConfigStack — or the OS keychain /
+//     credential helper its api_key_ref names)
 //  4. Built-in       (config.DefaultAPIURL for APIURL; APIKey has none)
 //
-// The config file is loaded fail-soft: a missing ~/.sbomhub/config.yaml is
-// NOT an error here, so noninteractive callers (CI runners that only set
+// The config files are loaded fail-soft: having none at all is NOT an
+// error here, so noninteractive callers (CI runners that only set
 // env vars / flags) work without first running `sbomhub login`. Parse
 // failures on an existing file are still surfaced.
 //
 // The returned *config.Config is non-nil on success but its APIKey may be
 // empty — callers are responsible for the final "is the credential
-// actually present" check so they can produce a command-specific message
-// (and exit code).
+// actually present" check (hasCredentials, which also counts a CI identity
+// newAPIClient will exchange) so they can produce a command-specific
+// message (and exit code).
+//
+// Naming a profile that does not exist (--profile / SBOMHUB_PROFILE) is an
+// error even without a config file, rather than a silent fall back to the
+// default profile's credentials.
 func resolveCredentials(configDir string) (*config.Config, error) {
-	cfg, err := config.LoadOrDefault(configDir)
+	profile, _ := activeProfile()
+	stack, err := loadConfigStack(configDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := stack.LoadProfile(profile)
 	if err != nil {
 		return nil, err
 	}
 
+	// Store layer: a profile whose key lives in the OS keychain or a
+	// credential helper only has api_key_ref. It is read only when no
+	// flag / env key will override it, so CI runs never touch (or get
+	// prompted by) the keychain.
+	if cfg.APIKey == "" && cfg.APIKeyRef != "" && apiKey == "" && os.Getenv("SBOMHUB_API_KEY") == "" {
+		key, err := lookupStoredAPIKey(context.Background(), cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg.APIKey = key
+	}
+
+	// Device-login layer: a profile without a key but with tokens from
+	// `login --device` authenticates with the access token; newAPIClient
+	// recognises that and installs a refreshing token source.
+	if cfg.APIKey == "" && cfg.APIKeyRef == "" && hasDeviceSession(cfg) && apiKey == "" && os.Getenv("SBOMHUB_API_KEY") == "" {
+		if cfg.TokenRef != "" {
+			if err := lookupStoredTokens(context.Background(), cfg); err != nil {
+				return nil, err
+			}
+		}
+		cfg.APIKey = cfg.AccessToken
+	}
+
 	// Env layer (only used when the config file did not already set the
 	// value; the CLI-flag layer below still wins over both).
 	if envURL := os.Getenv("SBOMHUB_API_URL"); envURL != "" {
@@ -135,8 +359,61 @@ func resolveCredentials(configDir string) (*config.Config, error) {
 		cfg.APIKey = apiKey
 	}
 
+	resolveTransportSettings(cfg, configDir)
+
 	if cfg.APIURL == "" {
 		cfg.APIURL = config.DefaultAPIURL
 	}
+
 	return cfg, nil
 }
+
+// activeProfile returns the profile name selected for this run and where
+// the choice came from: --profile, then SBOMHUB_PROFILE. An empty name
+// means "the config file's current_context" (source "config"), which
+// only config.File can resolve.
+func activeProfile() (name, source string) {
+	if profileFlag != "" {
+		return profileFlag, "flag"
+	}
+	if env := os.Getenv("SBOMHUB_PROFILE"); env != "" {
+		return env, "env"
+	}
+	return "", "config"
+}
+
+// resolveTransportSettings layers the TLS / proxy flags and SBOMHUB_*
+// env vars over the config-file values in cfg, with the same flag > env >
+// file precedence as the credentials. Relative certificate paths from the
+// config file are resolved against configDir so a config.yaml that says
+// `ca_cert: corp-ca.pem` works regardless of the working directory;
+// flag / env paths are left relative to the working directory, as the
+// shell that supplied them expects.
+func resolveTransportSettings(cfg *config.Config, configDir string) {
+	for _, p := range []*string{&cfg.CACert, &cfg.ClientCert, &cfg.ClientKey} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(configDir, *p)
+		}
+	}
+
+	layer := func(dst *string, env, flag string) {
+		if v := os.Getenv(env); v != "" {
+			*dst = v
+		}
+		if flag != "" {
+			*dst = flag
+		}
+	}
+	layer(&cfg.CACert, "SBOMHUB_CA_CERT", caCertFlag)
+	layer(&cfg.ClientCert, "SBOMHUB_CLIENT_CERT", clientCertFlag)
+	layer(&cfg.ClientKey, "SBOMHUB_CLIENT_KEY", clientKeyFlag)
+	layer(&cfg.Proxy, "SBOMHUB_PROXY", proxyFlag)
+	layer(&cfg.NoProxy, "SBOMHUB_NO_PROXY", noProxyFlag)
+
+	if v, err := strconv.ParseBool(os.Getenv("SBOMHUB_INSECURE_SKIP_VERIFY")); err == nil {
+		cfg.InsecureSkipVerify = v
+	}
+	if insecureSkipVerifyFlag {
+		cfg.InsecureSkipVerify = true
+	}
+}
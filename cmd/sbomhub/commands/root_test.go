@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// TestMain shrinks the API client's default backoff so the transient
+// exit-code tests (which answer 5xx / 429 on purpose) exercise the real
+// retry path without sleeping for seconds each.
+func TestMain(m *testing.M) {
+	sbomhub.DefaultRetryPolicy.BaseDelay = time.Millisecond
+	sbomhub.DefaultRetryPolicy.MaxDelay = 5 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+func TestNewAPIClient_HonoursMaxRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	saved := maxRetriesFlag
+	defer func() { maxRetriesFlag = saved }()
+
+	for _, tc := range []struct {
+		retries   int
+		wantCalls int
+	}{
+		{retries: 0, wantCalls: 1},
+		{retries: 2, wantCalls: 3},
+	} {
+		calls = 0
+		maxRetriesFlag = tc.retries
+		client, err := newAPIClient(&config.Config{APIURL: server.URL, APIKey: "k"})
+		if err != nil {
+			t.Fatalf("newAPIClient() = %v", err)
+		}
+		if _, err := client.ListProjects(context.Background()); err == nil {
+			t.Fatalf("--max-retries %d: ListProjects() succeeded against a 502 server", tc.retries)
+		}
+		if calls != tc.wantCalls {
+			t.Errorf("--max-retries %d: calls = %d, want %d", tc.retries, calls, tc.wantCalls)
+		}
+	}
+}
+
+func TestNewAPIClient_SendsCLIUserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	saved := version
+	t.Cleanup(func() { version = saved })
+	version = "1.2.3"
+
+	client, err := newAPIClient(&config.Config{APIURL: server.URL, APIKey: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "sbomhub-cli/1.2.3 (") {
+		t.Errorf("User-Agent = %q, want sbomhub-cli/1.2.3 (<os>/<arch>)", got)
+	}
+}
+
+// TestExecuteContext_InterruptExitCode checks that cancelling the run's
+// context (what SIGINT does via Execute) aborts an in-flight API request
+// and surfaces exit code 130 rather than the command's own failure code.
+func TestExecuteContext_InterruptExitCode(t *testing.T) {
+	withCleanCredentialEnv(t)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	savedRetries := maxRetriesFlag
+	t.Cleanup(func() {
+		maxRetriesFlag = savedRetries
+		rootCmd.SetArgs(nil)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	rootCmd.SetArgs([]string{"projects", "list", "--api-url", server.URL, "--api-key", "k", "--max-retries", "0"})
+
+	start := time.Now()
+	err := executeContext(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("interrupted command ran for %v", elapsed)
+	}
+	var ie *interruptedError
+	if !errors.As(err, &ie) || ie.ExitCode() != exitInterrupted {
+		t.Fatalf("err = %v, want *interruptedError with exit %d", err, exitInterrupted)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want it to wrap context.Canceled", err)
+	}
+}
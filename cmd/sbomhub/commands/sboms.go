@@ -0,0 +1,334 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+var (
+	sbomsProject   string
+	sbomsListLimit int
+	sbomsOutput    string
+	sbomsDeleteYes bool
+)
+
+var sbomsCmd = &cobra.Command{
+	Use:   "sboms",
+	Short: "プロジェクトの SBOM 履歴を管理",
+	Long: `プロジェクトにアップロード済みの SBOM を一覧・ ダウンロード・ 削除・ 再スキャンします。
+
+過去のリリースで出荷した SBOM を取り出したり、 古いビルドの SBOM に
+最新の脆弱性情報で再度マッチングをかけたりするのに使います。
+
+使用例:
+  sbomhub sboms list -p <project>                   # 新しい順に一覧 (ID または名前)
+  sbomhub sboms download <sbom-id> -p <project-id> -o release-1.2.cdx.json
+  sbomhub sboms rescan <sbom-id>... -p <project-id> # 最新の脆弱性情報で再スキャン
+  sbomhub sboms delete <sbom-id>... -p <project-id> # 削除 (確認あり。 CI では --yes)`,
+}
+
+var sbomsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "SBOM 一覧を表示 (新しい順)",
+	Args:  cobra.NoArgs,
+	RunE:  runSBOMsList,
+}
+
+var sbomsDownloadCmd = &cobra.Command{
+	Use:   "download <sbom-id>",
+	Short: "SBOM をアップロード時のまま取得",
+	Long: `SBOM ドキュメントをアップロードされたときのバイト列のまま取得します。
+-o を省略すると stdout に書き出します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSBOMsDownload,
+}
+
+var sbomsDeleteCmd = &cobra.Command{
+	Use:   "delete <sbom-id>...",
+	Short: "SBOM を削除",
+	Long: `SBOM を削除します。 その SBOM だけから検出された脆弱性も消えます。
+元に戻せません。
+
+削除前に対象を表示して確認します。 確認できない環境 (CI 等) では
+--yes を指定してください。`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSBOMsDelete,
+}
+
+var sbomsRescanCmd = &cobra.Command{
+	Use:   "rescan <sbom-id>...",
+	Short: "SBOM を最新の脆弱性情報で再スキャン",
+	Long: `保存済みの SBOM を現在の脆弱性データベースで再スキャンします。
+スキャンはサーバ側で非同期に行われます。 進捗は sboms list の
+SCAN 列で確認できます。`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSBOMsRescan,
+}
+
+func init() {
+	rootCmd.AddCommand(sbomsCmd)
+	sbomsCmd.AddCommand(sbomsListCmd)
+	sbomsCmd.AddCommand(sbomsDownloadCmd)
+	sbomsCmd.AddCommand(sbomsDeleteCmd)
+	sbomsCmd.AddCommand(sbomsRescanCmd)
+
+	// --project is set per subcommand rather than persistent, as in cra.
+	for _, c := range []*cobra.Command{sbomsListCmd, sbomsDownloadCmd, sbomsDeleteCmd, sbomsRescanCmd} {
+		c.Flags().StringVarP(&sbomsProject, "project", "p", "", "対象プロジェクト ID (UUID) または名前")
+	}
+	sbomsListCmd.Flags().IntVar(&sbomsListLimit, "limit", 0, "表示する最大件数 (0 = すべて)")
+	sbomsDownloadCmd.Flags().StringVarP(&sbomsOutput, "output", "o", "", "書き出すファイル (省略時は stdout)")
+	sbomsDeleteCmd.Flags().BoolVarP(&sbomsDeleteYes, "yes", "y", false, "確認せずに削除する (CI 向け)")
+}
+
+// sbomsClient validates --project and returns the client, ctx and project
+// ID every sboms subcommand starts from. A non-empty scope is checked
+// before the project is resolved, so a read-only key stops before any
+// lookup.
+func sbomsClient(cmd *cobra.Command, scope, op string) (*sbomhub.Client, context.Context, string, error) {
+	if err := requireProjectFlag(cmd, &sbomsProject); err != nil {
+		return nil, nil, "", err
+	}
+	client, err := loadConfigAndClient()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if scope != "" {
+		if err := requireScope(ctx, client, scope, op); err != nil {
+			return nil, nil, "", err
+		}
+	}
+	projectID, err := resolveProject(ctx, client, sbomsProject)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return client, ctx, projectID, nil
+}
+
+func runSBOMsList(cmd *cobra.Command, args []string) error {
+	client, ctx, projectID, err := sbomsClient(cmd, "", "sboms list")
+	if err != nil {
+		return err
+	}
+	it := client.SBOMs(projectID)
+	sboms := []sbomhub.SBOM{}
+	for (sbomsListLimit <= 0 || len(sboms) < sbomsListLimit) && it.Next(ctx) {
+		sboms = append(sboms, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return sbomFailure("sboms list", projectID, "", err)
+	}
+	total := it.Total()
+	if total < len(sboms) {
+		total = len(sboms)
+	}
+
+	out := GetOutputConfig()
+	return out.PrintResult(map[string]interface{}{
+		"sboms": sboms,
+		"total": total,
+	}, func() {
+		if len(sboms) == 0 {
+			printInfo("SBOM がありません")
+			return
+		}
+		w := tabwriter.NewWriter(out.Writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tFORMAT\tVERSION\tCREATED\tCOMPONENTS\tSCAN")
+		for _, s := range sboms {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", s.ID, orDash(s.Format), orDash(s.Version),
+				orDash(formatSBOMTime(s.CreatedAt)), s.ComponentCount, orDash(s.ScanStatus))
+		}
+		w.Flush()
+		if total > len(sboms) {
+			out.Print("\n%d / %d 件を表示 (--limit)\n", len(sboms), total)
+		} else {
+			out.Print("\n合計: %d 件\n", total)
+		}
+	})
+}
+
+func runSBOMsDownload(cmd *cobra.Command, args []string) error {
+	client, ctx, projectID, err := sbomsClient(cmd, "", "sboms download")
+	if err != nil {
+		return err
+	}
+
+	// The document is streamed from the response to its destination: an
+	// image's SBOM can be as large as the ones scan refuses to buffer.
+	out := GetOutputConfig()
+	if sbomsOutput == "" {
+		// The document is the output, --json or not: wrapping it would
+		// change the bytes a release signature was taken over.
+		if _, err := client.DownloadSBOM(ctx, projectID, args[0], out.Writer); err != nil {
+			return sbomFailure("sboms download", projectID, args[0], err)
+		}
+		return nil
+	}
+	var n int64
+	err = writeFileAtomic(sbomsOutput, 0o644, func(w io.Writer) error {
+		var err error
+		n, err = client.DownloadSBOM(ctx, projectID, args[0], w)
+		return err
+	})
+	if err != nil {
+		// A file error is local; anything else came from the API.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return fmt.Errorf("SBOM の書き込みに失敗しました (%s): %w", sbomsOutput, err)
+		}
+		return sbomFailure("sboms download", projectID, args[0], err)
+	}
+	if out.IsJSON() {
+		return out.PrintJSON(map[string]interface{}{"sbom_id": args[0], "path": sbomsOutput, "bytes": n})
+	}
+	printSuccess("SBOM %s を %s に保存しました (%d bytes)", args[0], sbomsOutput, n)
+	return nil
+}
+
+// writeFileAtomic has write fill a temporary file next to path and
+// renames it into place only once write succeeded, so an interrupted
+// download never leaves a truncated SBOM where a release pipeline would
+// pick it up.
+func writeFileAtomic(path string, perm os.FileMode, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	err = write(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func runSBOMsDelete(cmd *cobra.Command, args []string) error {
+	client, ctx, projectID, err := sbomsClient(cmd, sbomhub.ScopeSBOMWrite, "sboms delete")
+	if err != nil {
+		return err
+	}
+
+	// Look every ID up first, so the prompt shows what each SBOM is and
+	// a typo fails before anything is deleted.
+	want := map[string]bool{}
+	for _, id := range args {
+		want[id] = true
+	}
+	found := map[string]sbomhub.SBOM{}
+	it := client.SBOMs(projectID)
+	for len(found) < len(want) && it.Next(ctx) {
+		if s := it.Value(); want[s.ID] {
+			found[s.ID] = s
+		}
+	}
+	if err := it.Err(); err != nil {
+		return sbomFailure("sboms delete", projectID, "", err)
+	}
+	for _, id := range args {
+		if _, ok := found[id]; !ok {
+			return &exitError{code: exitPermanent,
+				msg: fmt.Sprintf("sboms delete: SBOM %s がプロジェクト %s にありません", id, projectID)}
+		}
+	}
+
+	out := GetOutputConfig()
+	if !sbomsDeleteYes {
+		fmt.Fprintf(out.ErrWriter, "プロジェクト %s の次の %d 件の SBOM を削除します。 元に戻せません:\n", projectID, len(found))
+		for _, id := range args {
+			s := found[id]
+			fmt.Fprintf(out.ErrWriter, "  %s  %s %s  %s\n", s.ID, s.Format, s.Version, formatSBOMTime(s.CreatedAt))
+		}
+		if !confirm(cmd.InOrStdin(), out.ErrWriter, "削除しますか? [y/N]: ") {
+			return &exitError{code: exitFailure, msg: "削除を中止しました (確認なしで削除するには --yes を指定してください)"}
+		}
+	}
+
+	deleted := make([]string, 0, len(args))
+	var failures []error
+	for _, id := range args {
+		if err := client.DeleteSBOM(ctx, projectID, id); err != nil {
+			failures = append(failures, sbomFailure("sboms delete", projectID, id, err))
+			continue
+		}
+		deleted = append(deleted, id)
+		printSuccess("%s: 削除しました", id)
+	}
+	if out.IsJSON() {
+		if err := out.PrintJSON(map[string]interface{}{"deleted": deleted}); err != nil {
+			return err
+		}
+	}
+	return joinProjectFailures("sboms delete", len(args), failures)
+}
+
+func runSBOMsRescan(cmd *cobra.Command, args []string) error {
+	client, ctx, projectID, err := sbomsClient(cmd, sbomhub.ScopeSBOMWrite, "sboms rescan")
+	if err != nil {
+		return err
+	}
+
+	out := GetOutputConfig()
+	statuses := make([]*sbomhub.ScanStatusResponse, 0, len(args))
+	var failures []error
+	for _, id := range args {
+		status, err := client.RescanSBOM(ctx, projectID, id)
+		if err != nil {
+			failures = append(failures, sbomFailure("sboms rescan", projectID, id, err))
+			continue
+		}
+		statuses = append(statuses, status)
+		printSuccess("%s: 再スキャンを開始しました (状態: %s)", id, status.Status)
+	}
+	if out.IsJSON() {
+		if err := out.PrintJSON(map[string]interface{}{"scans": statuses}); err != nil {
+			return err
+		}
+	} else if len(statuses) > 0 {
+		printInfo("進捗は 'sbomhub sboms list -p %s' で確認できます", projectID)
+	}
+	return joinProjectFailures("sboms rescan", len(args), failures)
+}
+
+// sbomFailure is projectFailure for a call on SBOM id of projectID ("" for
+// calls on the project's SBOM list). A 404 names whichever of the two the
+// operator has to fix; the server does not say which one it missed.
+func sbomFailure(op, projectID, id string, err error) error {
+	if ae, ok := sbomhub.AsError(err); ok && ae.IsNotFound() {
+		msg := fmt.Sprintf("%s: プロジェクト %s が見つかりません (この API Key のテナントにありません)", op, projectID)
+		if id != "" {
+			msg = fmt.Sprintf("%s: SBOM %s がプロジェクト %s に見つかりません", op, id, projectID)
+		}
+		return &exitError{code: exitPermanent, err: err, msg: msg}
+	}
+	return projectFailure(op, projectID, err)
+}
+
+// formatSBOMTime renders an RFC 3339 timestamp in local time, or returns
+// it unchanged when it does not parse.
+func formatSBOMTime(ts string) string {
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t.Local().Format("2006-01-02 15:04:05")
+	}
+	return ts
+}
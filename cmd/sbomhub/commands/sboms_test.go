@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+const sbomsTestDocument = `{"bomFormat":"CycloneDX","specVersion":"1.5","components":[{"name":"lodash","version":"4.17.20"}]}`
+
+// sbomsMock is projectsMock with one project holding n uploads of
+// sbomsTestDocument; it returns the client, the project ID and the SBOM
+// IDs oldest first.
+func sbomsMock(t *testing.T, n int) (*sbomhub.Client, string, []string) {
+	t.Helper()
+	client, _, ids := projectsMock(t, "app")
+	saveProject, saveOutput, saveLimit, saveYes := sbomsProject, sbomsOutput, sbomsListLimit, sbomsDeleteYes
+	t.Cleanup(func() {
+		sbomsProject, sbomsOutput, sbomsListLimit, sbomsDeleteYes = saveProject, saveOutput, saveLimit, saveYes
+		sbomsDeleteCmd.SetIn(nil)
+	})
+	sbomsProject, sbomsOutput, sbomsListLimit, sbomsDeleteYes = ids[0], "", 0, false
+
+	sbomIDs := make([]string, n)
+	for i := range sbomIDs {
+		res, err := client.UploadSBOM(context.Background(), ids[0], true, []byte(sbomsTestDocument), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sbomIDs[i] = res.SBOMID
+	}
+	return client, ids[0], sbomIDs
+}
+
+func TestRunSBOMsListAndDownload(t *testing.T) {
+	_, _, sbomIDs := sbomsMock(t, 3)
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.JSON = &stdout, true
+
+	sbomsListLimit = 2
+	if err := runSBOMsList(sbomsListCmd, nil); err != nil {
+		t.Fatalf("runSBOMsList() = %v", err)
+	}
+	var got struct {
+		SBOMs []sbomhub.SBOM `json:"sboms"`
+		Total int            `json:"total"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("list --json = %s: %v", stdout.String(), err)
+	}
+	if len(got.SBOMs) != 2 || got.Total != 3 || got.SBOMs[0].ID != sbomIDs[2] || got.SBOMs[0].ComponentCount != 1 {
+		t.Errorf("list --json --limit 2 = %+v, want the two newest of 3", got)
+	}
+
+	// Without -o the document goes to stdout as is, even with --json.
+	stdout.Reset()
+	if err := runSBOMsDownload(sbomsDownloadCmd, sbomIDs[:1]); err != nil {
+		t.Fatalf("runSBOMsDownload() = %v", err)
+	}
+	if stdout.String() != sbomsTestDocument {
+		t.Errorf("download to stdout = %q, want the uploaded document", stdout.String())
+	}
+
+	globalOutput.JSON = false
+	sbomsOutput = filepath.Join(t.TempDir(), "release.cdx.json")
+	if err := runSBOMsDownload(sbomsDownloadCmd, sbomIDs[:1]); err != nil {
+		t.Fatalf("runSBOMsDownload(-o) = %v", err)
+	}
+	if data, err := os.ReadFile(sbomsOutput); err != nil || string(data) != sbomsTestDocument {
+		t.Errorf("downloaded file = %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(sbomsOutput))
+	if len(entries) != 1 {
+		t.Errorf("output directory holds %d entries, want no leftover temp file", len(entries))
+	}
+
+	err := runSBOMsDownload(sbomsDownloadCmd, []string{"no-such-sbom"})
+	if err == nil || ExitCode(err) != exitPermanent || !strings.Contains(err.Error(), "no-such-sbom") {
+		t.Errorf("download of an unknown SBOM = %v, want a permanent not-found error", err)
+	}
+	// The failed download was streamed into a temp file that is gone again.
+	if entries, _ := os.ReadDir(filepath.Dir(sbomsOutput)); len(entries) != 1 {
+		t.Errorf("output directory holds %d entries after a failed download, want 1", len(entries))
+	}
+}
+
+func TestRunSBOMsDelete_Confirms(t *testing.T) {
+	client, projectID, sbomIDs := sbomsMock(t, 2)
+	count := func() int {
+		sboms, err := client.ListSBOMs(context.Background(), projectID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(sboms)
+	}
+
+	sbomsDeleteCmd.SetIn(strings.NewReader("n\n"))
+	if err := runSBOMsDelete(sbomsDeleteCmd, sbomIDs[:1]); err == nil || ExitCode(err) != exitFailure {
+		t.Errorf("answer n: runSBOMsDelete() = %v, want an abort", err)
+	}
+	sbomsDeleteYes = true
+	err := runSBOMsDelete(sbomsDeleteCmd, []string{sbomIDs[0], "no-such-sbom"})
+	if err == nil || ExitCode(err) != exitPermanent || !strings.Contains(err.Error(), "no-such-sbom") {
+		t.Errorf("unknown ID: runSBOMsDelete() = %v, want a permanent not-found error", err)
+	}
+	if n := count(); n != 2 {
+		t.Fatalf("SBOMs after aborted deletes = %d, want 2", n)
+	}
+
+	if err := runSBOMsDelete(sbomsDeleteCmd, sbomIDs[:1]); err != nil {
+		t.Fatalf("runSBOMsDelete(--yes) = %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("SBOMs after delete = %d, want 1", n)
+	}
+}
+
+func TestRunSBOMsRescan_ContinuesPastFailures(t *testing.T) {
+	_, _, sbomIDs := sbomsMock(t, 1)
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.JSON = &stdout, true
+
+	err := runSBOMsRescan(sbomsRescanCmd, []string{"missing", sbomIDs[0]})
+	if err == nil || ExitCode(err) != exitPermanent || !strings.Contains(err.Error(), "2 件中 1 件") {
+		t.Errorf("runSBOMsRescan() = %v, want one permanent failure out of 2", err)
+	}
+	var got struct {
+		Scans []sbomhub.ScanStatusResponse `json:"scans"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil || len(got.Scans) != 1 || got.Scans[0].SbomID != sbomIDs[0] {
+		t.Errorf("rescan --json = %s (%v), want the started scan", stdout.String(), err)
+	}
+}
@@ -2,48 +2,25 @@ package commands
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
 	"github.com/youichi-uda/sbomhub-cli/internal/scanner"
 	"github.com/youichi-uda/sbomhub-cli/internal/severity"
+	"github.com/youichi-uda/sbomhub-cli/internal/suppress"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
-// Exit codes used by `scan`. Documented here so CI authors can branch on
-// them. See also the `--fail-on` documentation in init().
-//
-//	0 — success (no threshold violation, or --fail-on not set)
-//	1 — threshold violation: vulnerabilities at or above --fail-on found
-//	2 — wait-for-scan timed out, or background scan reported "failed"
-//	3 — API / upload / configuration error
-//
-// Callers should not rely on exit codes outside [0,3]; cobra may map
-// validation errors to 1 itself, but the runScan body uses ScanError to
-// pick the intentional code.
-const (
-	exitSuccess           = 0
-	exitThresholdExceeded = 1
-	exitScanTimeout       = 2
-	exitAPIError          = 3
-)
-
-// scanExitError lets runScan signal a specific exit code to main() while
-// still using the normal cobra/errors return path. main.go inspects it
-// via errors.As.
-type scanExitError struct {
-	code int
-	msg  string
-}
-
-func (e *scanExitError) Error() string { return e.msg }
-func (e *scanExitError) ExitCode() int { return e.code }
+// Exit codes used by `scan` follow the shared table in exitcode.go:
+// 1 for a tripped --fail-on / --policy gate, 2 when wait-for-scan timed
+// out or the server-side scan failed, 3 / 4 for permanent / transient
+// API errors, 130 on interrupt.
 
 // scanJSONResult is the canonical schema for `sbomhub scan --json`.
 // Keep this in sync with sbomhub-internal/draft-sbomhub-action/entrypoint.sh
@@ -79,9 +56,24 @@ func (e *scanExitError) ExitCode() int { return e.code }
 //     double-counting against the CVSS buckets).
 //   - FailOn: echoes the --fail-on threshold the run was configured with
 //     (null when unset), whether the threshold tripped, and the exit code
-//     the process will return. ExitCode mirrors the documented set:
-//     0 success / 1 threshold exceeded / 2 scan timeout or server failure
-//     / 3 API or config error.
+//     the process will return, from the shared table in exitcode.go:
+//     0 success / 1 --fail-on tripped / 2 scan timeout or server-side scan
+//     failure / 3 permanent API or configuration error / 4 transient API
+//     error. A tripped --policy rule returns that rule's exit_code (1 by
+//     default; never 2, 3 or 4).
+//   - Suppressions: present only when a `.sbomhubignore` file was loaded.
+//     Lists the findings it suppressed (with reason / owner / expiry) and
+//     any expired or unevaluated entries. When present,
+//     VulnerabilitySummary is the post-suppression view — the counts the
+//     --fail-on gate actually evaluated.
+//   - VEX: present only when approved VEX decisions were consulted (a
+//     gate was configured and --vex was not disabled). Lists the findings
+//     excluded by a not_affected / resolved decision, with the draft and
+//     who approved it; VulnerabilitySummary excludes them as well.
+//   - Policy: present only when --policy was given. Lists the fail / warn
+//     rules that matched (with the matching findings) and the exit code of
+//     the first failing rule; FailOn.ExitCode carries the same value when
+//     the policy decided the run's outcome.
 type scanJSONResult struct {
 	SBOMID               string              `json:"sbom_id"`
 	ProjectID            string              `json:"project_id"`
@@ -93,9 +85,12 @@ type scanJSONResult struct {
 	ScanStatus           string              `json:"scan_status"`
 	VulnerabilitySummary scanJSONVulnSummary `json:"vulnerability_summary"`
 	FailOn               scanJSONFailOn      `json:"fail_on"`
+	Suppressions         *suppressionJSON    `json:"suppressions,omitempty"`
+	VEX                  *vexJSON            `json:"vex,omitempty"`
+	Policy               *policyJSON         `json:"policy,omitempty"`
 }
 
-// scanJSONVulnSummary mirrors api.VulnerabilitySummary but pins JSON
+// scanJSONVulnSummary mirrors sbomhub.VulnerabilitySummary but pins JSON
 // field naming and ordering for stable wire output. Total is computed
 // locally as c+h+m+l+u (CVSS-rated buckets only) rather than trusting
 // the server's `total` field — matches the formatScanVulnSummary
@@ -132,8 +127,8 @@ type scanJSONFailOn struct {
 type scanFinalState struct {
 	componentCount  int
 	format          string
-	uploadResult    *api.UploadResult
-	summary         *api.VulnerabilitySummary
+	uploadResult    *sbomhub.UploadResult
+	summary         *sbomhub.VulnerabilitySummary
 	waitForScan     bool
 	dryRun          bool
 	scanTimedOut    bool
@@ -142,6 +137,9 @@ type scanFinalState struct {
 	failOnStr       string // original CLI value, empty when not set
 	failOnTriggered bool
 	exitCode        int
+	suppressions    *suppressionJSON
+	vex             *vexJSON
+	policy          *policyJSON
 }
 
 // computeScanStatus maps the final pipeline state to one of the
@@ -232,6 +230,9 @@ func buildScanJSONResult(in scanFinalState) scanJSONResult {
 		s := in.failOnStr
 		r.FailOn.Threshold = &s
 	}
+	r.Suppressions = in.suppressions
+	r.VEX = in.vex
+	r.Policy = in.policy
 
 	return r
 }
@@ -247,6 +248,10 @@ var (
 	scanWaitForScan  bool
 	scanWaitTimeout  time.Duration
 	scanPollInterval time.Duration
+	scanIgnoreFile   string
+	scanPolicyFile   string
+	scanVEX          bool
+	scanMaxSBOMSize  string
 )
 
 var scanCmd = &cobra.Command{
@@ -261,12 +266,28 @@ var scanCmd = &cobra.Command{
   sbomhub scan ./image.tar                       # コンテナイメージ
   sbomhub scan . --fail-on critical              # critical あれば exit 1
   sbomhub scan . --fail-on high --wait-timeout 10m
+  sbomhub scan . --fail-on high --ignore-file ./security/.sbomhubignore
+  sbomhub scan . --policy ./security/policy.yaml
+  sbomhub scan ./image.tar --max-sbom-size 500MB
+
+抑制ファイル (.sbomhubignore):
+  カレントディレクトリまたはスキャン対象ディレクトリの .sbomhubignore を
+  自動で読み込み、 期限内のエントリに一致する脆弱性を --fail-on の評価前に
+  除外します。 scan の脆弱性データにはコンポーネント情報が無いため、
+  purl/version を指定したエントリは評価されません (check では評価されます)。
+
+承認済み VEX 判定:
+  --fail-on / --policy を指定した場合、 プロジェクトで承認済みの VEX 判定
+  (not_affected / resolved) に一致する脆弱性を閾値評価から除外し、 誰が
+  承認したかを表示します。 --vex=false で無効化できます。
 
 Exit codes:
   0  正常終了 (脆弱性 threshold 違反なし、 もしくは --fail-on 未指定)
-  1  --fail-on で指定した重大度以上の脆弱性を検出
+  1  --fail-on で指定した重大度以上の脆弱性を検出 (--policy の fail ルールは
+     exit_code で上書き可)、 または SBOM が --max-sbom-size を超過
   2  スキャン待機タイムアウト or サーバ側スキャンが失敗
-  3  API / アップロード / 設定エラー`,
+  3  API の恒久エラー / 設定エラー
+  4  API の一時エラー (429 / 5xx / 通信エラー)`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runScan,
 }
@@ -284,12 +305,25 @@ func init() {
 	scanCmd.Flags().BoolVar(&scanWaitForScan, "wait-for-scan", true, "アップロード後にサーバ側の脆弱性スキャン完了を待つ (--fail-on と併用する場合は true 必須、 false を渡すと起動拒否)")
 	scanCmd.Flags().DurationVar(&scanWaitTimeout, "wait-timeout", 5*time.Minute, "サーバ側スキャン完了を待つ最大時間")
 	scanCmd.Flags().DurationVar(&scanPollInterval, "poll-interval", 5*time.Second, "スキャン状態の polling 間隔")
+	scanCmd.Flags().StringVar(&scanPolicyFile, "policy", "", "fail/warn ルールを記述したポリシーファイル (YAML)。 --wait-for-scan=true が必須")
+	scanCmd.Flags().BoolVar(&scanVEX, "vex", true, "承認済み VEX 判定 (not_affected/resolved) を --fail-on / --policy の評価から除外する")
+	scanCmd.Flags().StringVar(&scanMaxSBOMSize, "max-sbom-size", "", "生成した SBOM がこのサイズを超えたらアップロードせずに exit 1 (例: 500MB, 1GiB。 デフォルト: 無制限)")
+	scanCmd.Flags().StringVar(&scanIgnoreFile, "ignore-file", "", "抑制ファイルのパス (デフォルト: カレント / スキャン対象ディレクトリの .sbomhubignore)")
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
 	outputJSON := out.IsJSON()
 
+	// ctx is cancelled on SIGINT / SIGTERM (see Execute). It is threaded
+	// through the scanner subprocess, the upload and the scan-status
+	// polling so Ctrl-C stops whichever is in flight; Execute then maps
+	// the returned error to exitInterrupted.
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// scanPrintf routes the legacy bare fmt.Printf progress lines to
 	// stderr when --json is on (so stdout stays clean for the JSON
 	// payload) and to stdout otherwise. Equivalent to out.Print but
@@ -318,6 +352,19 @@ func runScan(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(w)
 	}
 
+	// 未指定のフラグは env / .sbomhub.yaml / プロファイルの設定で補う
+	// (sbomhub config list --show-origin で確認できる)。
+	if err := applySettingDefaults(cmd, map[string]string{
+		"project":       "project",
+		"tool":          "tool",
+		"format":        "format",
+		"fail-on":       "fail_on",
+		"wait-timeout":  "wait_timeout",
+		"poll-interval": "poll_interval",
+	}); err != nil {
+		return err
+	}
+
 	// スキャン対象パスの決定
 	scanPath := "."
 	if len(args) > 0 {
@@ -355,6 +402,29 @@ func runScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--fail-on requires --wait-for-scan=true; either drop --wait-for-scan=false (it defaults to true) or remove --fail-on")
 	}
 
+	maxSBOMSize, err := parseByteSize(scanMaxSBOMSize)
+	if err != nil {
+		return fmt.Errorf("--max-sbom-size: %w", err)
+	}
+
+	// --policy is a gate just like --fail-on and needs the same server-side
+	// results, so it gets the same startup guard.
+	policy, err := loadPolicy(scanPolicyFile)
+	if err != nil {
+		return err
+	}
+	if policy != nil && !scanWaitForScan {
+		return fmt.Errorf("--policy requires --wait-for-scan=true; either drop --wait-for-scan=false (it defaults to true) or remove --policy")
+	}
+	gateConfigured := failOnLevel != severity.LevelNone || policy != nil
+
+	// 抑制ファイルも早期に読み込む: 検証エラー (reason/owner/expires 欠落)
+	// はスキャン・ アップロードの前に報告したい。
+	supFile, err := loadSuppressions(scanIgnoreFile, suppressionSearchDirs(absPath)...)
+	if err != nil {
+		return err
+	}
+
 	scanPrintf("📦 スキャン開始: %s\n", absPath)
 	scanPrintln()
 
@@ -366,24 +436,38 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 	scanPrintf("🔍 ツール: %s\n", s.Name())
 
-	// スキャン実行
+	// スキャン実行。 SBOM はスキャナーが一時ファイル (またはキャッシュ) に
+	// 書き出し、 以降はファイルからストリームする (メモリに載せない)。
 	startTime := time.Now()
-	sbomData, err := s.Scan(absPath, scanFormat)
+	sbom, err := generateSBOM(ctx, openCache(), s, absPath, scanFormat)
 	if err != nil {
 		return fmt.Errorf("スキャンに失敗しました: %w", err)
 	}
+	defer sbom.Close()
 	elapsed := time.Since(startTime)
 
 	scanPrintf("⏱️  スキャン時間: %s\n", elapsed.Round(time.Millisecond))
+	out.PrintVerbose("SBOM サイズ: %s (%s)", formatBytes(sbom.size), sbom.path)
+
+	// --max-sbom-size: upload 前 (件数カウントや保存よりも前) に止める。
+	// サーバ側の上限に当たってからアップロード全体をやり直すより早く、
+	// 何が大きすぎたのかが分かる。
+	if maxSBOMSize > 0 && sbom.size > maxSBOMSize {
+		return &exitError{
+			code: exitFailure,
+			msg: fmt.Sprintf("SBOM のサイズ %s が --max-sbom-size (%s) を超えています。 スキャン対象を絞る (例: ベースイメージのレイヤーを除外する) か、 上限を引き上げてください",
+				formatBytes(sbom.size), formatBytes(maxSBOMSize)),
+		}
+	}
 
 	// コンポーネント数を表示
-	componentCount := countComponents(sbomData)
+	componentCount := countComponentsFile(sbom.path)
 	scanPrintf("📋 コンポーネント数: %d\n", componentCount)
 	scanPrintln()
 
 	// ローカル保存
 	if scanOutput != "" {
-		if err := os.WriteFile(scanOutput, sbomData, 0644); err != nil {
+		if err := copySBOMFile(sbom.path, scanOutput); err != nil {
 			return fmt.Errorf("ファイルの保存に失敗しました: %w", err)
 		}
 		printSuccess("SBOMを保存しました: %s", scanOutput)
@@ -412,33 +496,53 @@ func runScan(cmd *cobra.Command, args []string) error {
 	// 設定の解決: config file → env → CLI flag の precedence で merge する。
 	// config file が無くても flag/env だけで動く (Codex R2 fix): CI runner
 	// のような ephemeral 環境向け。
-	configDir := filepath.Join(os.Getenv("HOME"), ".sbomhub")
-	if os.Getenv("USERPROFILE") != "" {
-		configDir = filepath.Join(os.Getenv("USERPROFILE"), ".sbomhub")
-	}
-
-	cfg, err := resolveCredentials(configDir)
+	cfg, err := resolveCredentials(getConfigDir())
 	if err != nil {
-		return &scanExitError{
-			code: exitAPIError,
+		return &exitError{
+			code: exitPermanent,
 			msg:  fmt.Sprintf("設定の読み込みに失敗しました: %v", err),
 		}
 	}
-	if cfg.APIKey == "" {
-		return &scanExitError{
-			code: exitAPIError,
+	if !hasCredentials(cfg) {
+		return &exitError{
+			code: exitPermanent,
 			msg:  "API Keyが設定されていません。 'sbomhub login' で対話設定するか、 --api-key フラグ・ 環境変数 SBOMHUB_API_KEY を指定してください",
 		}
 	}
 	if cfg.APIURL == "" {
-		return &scanExitError{
-			code: exitAPIError,
+		return &exitError{
+			code: exitPermanent,
 			msg:  "API URLが設定されていません。 'sbomhub login' で設定するか、 --api-url フラグ・ 環境変数 SBOMHUB_API_URL を指定してください",
 		}
 	}
 
 	// API クライアントの作成
-	client := api.NewClient(cfg.APIURL, cfg.APIKey)
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return &exitError{code: exitPermanent, msg: err.Error()}
+	}
+
+	// サーバの capabilities でアップロード / scan-status / gzip の可否を
+	// 決める。 capabilities を公開していない (旧) サーバや取得に失敗した
+	// 場合は従来どおり試行して、 404 / 415 で判断する。 サーバが明示的に
+	// 非対応と宣言した機能だけ、 アップロード前に進路を変える。
+	caps := serverCapabilities(ctx, client, cfg.APIURL)
+	if err := featureUnsupported(caps, sbomhub.FeatureSBOMUpload, "SBOM アップロード API"); err != nil {
+		return err
+	}
+	waitForScan := scanWaitForScan
+	if waitForScan && caps.Lacks(sbomhub.FeatureScanStatus) {
+		if gateConfigured {
+			// Failing after the upload would leave an SBOM on the server
+			// that the gate can never evaluate; refuse before sending it.
+			return &exitError{
+				code: exitPermanent,
+				msg:  fmt.Sprintf("サーバ (version %s) は scan-status API に対応していないため --fail-on / --policy を評価できません。 サーバを更新するか、 ゲートを外して --wait-for-scan=false で実行してください", orNA(caps.Version)),
+			}
+		}
+		fmt.Fprintf(out.ErrWriter, "⚠️  サーバが scan-status API に対応していないため、 スキャン完了を待たずに終了します\n")
+		waitForScan = false
+	}
 
 	// プロジェクト名の決定。
 	//
@@ -471,9 +575,27 @@ func runScan(cmd *cobra.Command, args []string) error {
 	// アップロード。 projectExplicit=false (= dir-basename fallback) のときは
 	// UploadSBOM は projectName が UUID 形式であっても ID として扱わず、
 	// CreateProject(get-or-create) 経由で安全に name として登録する。
-	result, err := client.UploadSBOM(projectName, projectExplicit, sbomData, scanFormat)
+	// SBOM はファイルから gzip 圧縮しながらストリーム送信する (サーバが
+	// 圧縮を拒否した場合は非圧縮で再送)。
+	src, err := sbomhub.SBOMFile(sbom.path)
+	if err != nil {
+		return err
+	}
+	result, err := client.UploadSBOMFrom(ctx, projectName, projectExplicit, src, scanFormat, sbomhub.UploadOptions{
+		Gzip:     !caps.Lacks(sbomhub.FeatureGzipUpload),
+		Progress: uploadProgress(out),
+	})
 	if err != nil {
-		return &scanExitError{code: exitAPIError, msg: fmt.Sprintf("アップロードに失敗しました: %v", err)}
+		if ctx.Err() != nil {
+			return fmt.Errorf("アップロードを中断しました: %w", ctx.Err())
+		}
+		return &exitError{code: apiExitCode(err), err: err, msg: fmt.Sprintf("アップロードに失敗しました: %v", err)}
+	}
+
+	if result.Encoding == "gzip" {
+		out.PrintVerbose("アップロード: gzip 圧縮で送信しました")
+	} else {
+		out.PrintVerbose("アップロード: 非圧縮で送信しました")
 	}
 
 	scanPrintln()
@@ -494,20 +616,78 @@ func runScan(cmd *cobra.Command, args []string) error {
 	// is the explicit opt-out, and the upload response's zero counts are
 	// the user's stated intent. --wait-for-scan=false with --fail-on is
 	// already rejected at startup by the R3 guard above.
-	var summary *api.VulnerabilitySummary
+	var summary *sbomhub.VulnerabilitySummary
 	var scanTimedOut bool
 	var scanFailedMsg string
 	var scanAPIErrMsg string
 	var scanLastFetchedAt time.Time
-	if scanWaitForScan {
+	if waitForScan {
 		// Bind the polling loop's deadline to a context so the in-flight
 		// HTTP request can be cancelled the moment --wait-timeout expires
 		// (Codex R4 finding 2). The httpClient default 60s timeout was
 		// the only thing in effect before this — meaning --wait-timeout=10s
 		// could still hang for up to 60s on a slow server.
-		ctx, cancel := context.WithTimeout(context.Background(), scanWaitTimeout)
-		summary, scanTimedOut, scanFailedMsg, scanAPIErrMsg, scanLastFetchedAt = waitForScanCompletion(ctx, client, result.ProjectID, result.SBOMID)
+		//
+		// The timeout is derived from the run's ctx, so an interrupt also
+		// ends the loop; waitForScanCompletion cannot tell the two apart
+		// and reports both as timedOut, hence the parent check after it.
+		waitCtx, cancel := context.WithTimeout(ctx, scanWaitTimeout)
+		summary, scanTimedOut, scanFailedMsg, scanAPIErrMsg, scanLastFetchedAt = waitForScanCompletion(waitCtx, client, result.ProjectID, result.SBOMID)
 		cancel()
+		if ctx.Err() != nil {
+			return fmt.Errorf("スキャン結果の待機を中断しました (SBOM はアップロード済み: %s): %w", result.SBOMID, ctx.Err())
+		}
+	}
+
+	// Apply approved VEX decisions, .sbomhubignore and --policy before
+	// the gate. scan-status only returns aggregate counts, so the per-CVE
+	// view comes from the project's vulnerability rows; excluded rows are
+	// subtracted from the snapshot. If the rows (or the VEX drafts) cannot
+	// be fetched we keep the unsuppressed counts (fail closed) and say so,
+	// rather than guessing; a policy that cannot be evaluated is an API
+	// error below.
+	var (
+		supResult     *suppress.Result
+		vexIdx        *vexIndex
+		vexHits       []vexSuppressed
+		policyOutcome *severity.Outcome
+		policyErr     error
+	)
+	useVEX := scanVEX && gateConfigured
+	if (supFile != nil || useVEX || policy != nil) && summary != nil && scanAPIErrMsg == "" {
+		recs, err := client.ListVulnerabilities(ctx, result.ProjectID)
+		if err != nil {
+			policyErr = err
+			if supFile != nil || useVEX {
+				fmt.Fprintf(out.ErrWriter, "⚠️  VEX 判定 / 抑制ファイルを適用できませんでした (脆弱性一覧の取得に失敗): %v\n", err)
+			}
+		} else {
+			findings := findingsFromVulnRecords(recs)
+			var excluded []suppress.Finding
+			if useVEX {
+				idx, err := loadVEXIndex(ctx, client, caps, result.ProjectID)
+				if err != nil {
+					fmt.Fprintf(out.ErrWriter, "⚠️  VEX 判定を取得できませんでした。 除外せずに評価します: %v\n", err)
+				} else {
+					vexIdx = idx
+					findings, vexHits = applyVEX(findings, vexIdx)
+					excluded = append(excluded, vexFindings(vexHits)...)
+					printVEXReport(out.ErrWriter, vexIdx, vexHits)
+				}
+			}
+			if supFile != nil {
+				res := supFile.Apply(findings, time.Now(), false)
+				supResult = &res
+				excluded = append(excluded, suppressedFindings(supResult)...)
+				printSuppressionReport(out.ErrWriter, supFile, supResult)
+			}
+			summary = suppressedSummary(summary, excluded)
+			if policy != nil {
+				o := policy.Evaluate(policyFindingsFromVulnRecords(recs, suppressedKeys(excluded)))
+				policyOutcome = &o
+				printPolicyWarnings(out.ErrWriter, policyOutcome)
+			}
+		}
 	}
 
 	// Compute the final state of the run. From this point we have a
@@ -529,27 +709,28 @@ func runScan(cmd *cobra.Command, args []string) error {
 	case scanAPIErrMsg != "":
 		// Codex R7 fix: scan-status polling hit a permanent client-side
 		// error (typically 401/403 from bad auth, or 404 from a server
-		// that does not implement scan-status). Fast-fail with exit-3
-		// (API error) regardless of --fail-on — a broken polling
-		// endpoint means we cannot trust ANY downstream counts.
-		exitCode = exitAPIError
-		exitErr = &scanExitError{
-			code: exitAPIError,
+		// that does not implement scan-status). Fast-fail with exit 3
+		// (permanent API error) regardless of --fail-on — a broken
+		// polling endpoint means we cannot trust ANY downstream counts.
+		// Transient failures never get here: polling rides them out.
+		exitCode = exitPermanent
+		exitErr = &exitError{
+			code: exitPermanent,
 			msg:  fmt.Sprintf("scan-status polling aborted: %s", scanAPIErrMsg),
 		}
 
-	case failOnLevel == severity.LevelNone:
-		// No threshold configured. --wait-for-scan timeout / failure
-		// is surfaced as a stderr warning but does not block CI.
+	case !gateConfigured:
+		// No threshold or policy configured. --wait-for-scan timeout /
+		// failure is surfaced as a stderr warning but does not block CI.
 		exitCode = exitSuccess
 
-	case !scanWaitForScan:
+	case !waitForScan:
 		// Defensive guard: the startup check already rejects --fail-on
 		// with --wait-for-scan=false; this branch is unreachable except
 		// under future refactor regression.
-		exitCode = exitAPIError
-		exitErr = &scanExitError{
-			code: exitAPIError,
+		exitCode = exitPermanent
+		exitErr = &exitError{
+			code: exitPermanent,
 			msg:  "--fail-on requires --wait-for-scan=true (internal invariant violated)",
 		}
 
@@ -558,21 +739,23 @@ func runScan(cmd *cobra.Command, args []string) error {
 		// negative tolerated, false positive avoided). Surface exit-2
 		// so CI can branch on it explicitly.
 		exitCode = exitScanTimeout
-		exitErr = &scanExitError{
+		exitErr = &exitError{
 			code: exitScanTimeout,
 			msg:  fmt.Sprintf("--wait-timeout %s 以内にサーバ側脆弱性スキャンが完了しませんでした。 --fail-on は評価されていません", scanWaitTimeout),
 		}
 
 	case scanFailedMsg != "":
 		exitCode = exitScanTimeout
-		exitErr = &scanExitError{
+		exitErr = &exitError{
 			code: exitScanTimeout,
 			msg:  fmt.Sprintf("サーバ側脆弱性スキャンが失敗しました: %s。 --fail-on は評価されていません", scanFailedMsg),
 		}
 
 	case summary == nil:
-		exitCode = exitAPIError
-		exitErr = &scanExitError{code: exitAPIError, msg: "スキャン結果の取得に失敗しました"}
+		// Polling ended without a timeout, a failure or an error but
+		// also without counts; nothing the operator can fix, so retry.
+		exitCode = exitTransient
+		exitErr = &exitError{code: exitTransient, msg: "スキャン結果の取得に失敗しました"}
 
 	default:
 		// Codex R1 fix: KEV is sourced from the scan-status response.
@@ -587,10 +770,28 @@ func runScan(cmd *cobra.Command, args []string) error {
 		if severity.ShouldFail(counts, failOnLevel) {
 			failOnTriggered = true
 			exitCode = exitThresholdExceeded
-			exitErr = &scanExitError{
+			exitErr = &exitError{
 				code: exitThresholdExceeded,
 				msg:  fmt.Sprintf("--fail-on %s: 指定された重大度以上の脆弱性が検出されました (critical=%d high=%d medium=%d low=%d unknown=%d kev=%d)", scanFailOn, counts.Critical, counts.High, counts.Medium, counts.Low, counts.Unknown, counts.KEV),
 			}
+		} else if policy != nil && policyErr != nil {
+			exitCode = apiExitCode(policyErr)
+			exitErr = &exitError{
+				code: exitCode,
+				err:  policyErr,
+				msg:  fmt.Sprintf("--policy を評価できませんでした (脆弱性一覧の取得に失敗): %v", policyErr),
+			}
+		} else if policyOutcome != nil && policyOutcome.HasFailure() {
+			failOnTriggered = true
+			exitCode = policyOutcome.ExitCode
+			exitErr = &exitError{code: policyOutcome.ExitCode, msg: policyFailureMessage(policyOutcome)}
+		} else if supResult != nil && supFile.FailOnExpired(*supResult) {
+			// `expired: fail` only bites under a gate (--fail-on /
+			// --policy): without one there is nothing for a stale
+			// acceptance to undermine, and the warning above names it.
+			failOnTriggered = true
+			exitCode = exitThresholdExceeded
+			exitErr = &exitError{code: exitThresholdExceeded, msg: expiredSuppressionMessage(supResult)}
 		}
 	}
 
@@ -602,7 +803,7 @@ func runScan(cmd *cobra.Command, args []string) error {
 		format:          scanFormat,
 		uploadResult:    result,
 		summary:         summary,
-		waitForScan:     scanWaitForScan,
+		waitForScan:     waitForScan,
 		dryRun:          false,
 		scanTimedOut:    scanTimedOut,
 		scanFailedMsg:   scanFailedMsg,
@@ -610,6 +811,9 @@ func runScan(cmd *cobra.Command, args []string) error {
 		failOnStr:       scanFailOn,
 		failOnTriggered: failOnTriggered,
 		exitCode:        exitCode,
+		suppressions:    buildSuppressionJSON(supFile, supResult),
+		vex:             buildVEXJSON(vexIdx, vexHits),
+		policy:          buildPolicyJSON(policy, policyOutcome),
 	}
 
 	// Emit either the JSON payload (machine consumers — GitHub Action,
@@ -625,7 +829,7 @@ func runScan(cmd *cobra.Command, args []string) error {
 	// Operator warnings always go to stderr (independent of --json mode)
 	// so CI logs surface context even when stdout is being captured for
 	// JSON parsing.
-	if exitErr == nil && failOnLevel == severity.LevelNone {
+	if exitErr == nil && !gateConfigured {
 		if scanTimedOut {
 			// Codex R5 fix: when polling timed out we now return the
 			// most recently observed status snapshot (if any). Surface
@@ -661,7 +865,8 @@ func runScan(cmd *cobra.Command, args []string) error {
 //     could not enforce --fail-on against it.
 //   - apiErrMsg:      non-empty if scan-status polling aborted because the
 //     endpoint returned a permanent client-side error (HTTP 4xx). Maps to
-//     exit-3 (API error). See Codex R7 fix below for the full rationale.
+//     exit 3 (permanent API error); transient errors (exit 4 elsewhere)
+//     keep polling instead. See Codex R7 fix below for the full rationale.
 //   - lastFetchedAt:  wall-clock timestamp of the most recent successful
 //     poll; zero time.Time when no poll ever succeeded. Caller surfaces
 //     this to the operator alongside the timeout warning so the partial
@@ -704,11 +909,11 @@ func runScan(cmd *cobra.Command, args []string) error {
 // including 429 Too Many Requests. An upstream gateway or API rate
 // limiter throttling the polling loop therefore fast-failed CIs that
 // would have succeeded on the next poll. We now delegate the
-// transient/permanent classification to APIError.IsRetryable (429 +
+// transient/permanent classification to sbomhub.Error.IsRetryable (429 +
 // 5xx → retryable, other 4xx → permanent) and honour the server's
 // Retry-After hint when present (capped only by ctx deadline, so a
 // rogue large Retry-After never outlives --wait-timeout).
-func waitForScanCompletion(ctx context.Context, client *api.Client, projectID, sbomID string) (summary *api.VulnerabilitySummary, timedOut bool, failedErrorMsg, apiErrMsg string, lastFetchedAt time.Time) {
+func waitForScanCompletion(ctx context.Context, client *sbomhub.Client, projectID, sbomID string) (summary *sbomhub.VulnerabilitySummary, timedOut bool, failedErrorMsg, apiErrMsg string, lastFetchedAt time.Time) {
 	tick := scanPollInterval
 	if tick <= 0 {
 		tick = 5 * time.Second
@@ -726,7 +931,7 @@ func waitForScanCompletion(ctx context.Context, client *api.Client, projectID, s
 	fmt.Fprintf(progressW(), "⏳ サーバ側脆弱性スキャン待機中 (timeout=%s, interval=%s)...\n", scanWaitTimeout, tick)
 
 	startTime := time.Now()
-	var latest *api.VulnerabilitySummary
+	var latest *sbomhub.VulnerabilitySummary
 	var latestAt time.Time
 	for {
 		if ctx.Err() != nil {
@@ -734,7 +939,7 @@ func waitForScanCompletion(ctx context.Context, client *api.Client, projectID, s
 		}
 
 		// nextSleep defaults to the configured poll cadence. A retryable
-		// APIError carrying a Retry-After hint may bump it up for this
+		// *sbomhub.Error carrying a Retry-After hint may bump it up for this
 		// iteration only (so a one-off 429 with "Retry-After: 30" doesn't
 		// permanently slow the loop down). ctx-bound select below caps
 		// any inflated sleep at the remaining --wait-timeout budget.
@@ -751,14 +956,16 @@ func waitForScanCompletion(ctx context.Context, client *api.Client, projectID, s
 
 			// Classify the API error. 429 / 5xx are retryable transient
 			// failures; other 4xx are permanent (bad auth, missing
-			// endpoint, malformed path) and fast-fail to exit-3 so the
+			// endpoint, malformed path) and fast-fail to exit 3 so the
 			// operator sees the real failure mode rather than a misleading
-			// "scan timed out".
-			var apiErr *api.APIError
-			if errors.As(err, &apiErr) {
+			// "scan timed out". A response that violated the contract is
+			// transient in the exit-code table, so it is polled past like
+			// a network flap rather than reported as permanent.
+			var apiErr *sbomhub.Error
+			if errors.As(err, &apiErr) && apiErr.Kind() != sbomhub.KindProtocol {
 				if !apiErr.IsRetryable() {
 					fmt.Fprintf(progressW(), "   ✗ scan-status 取得エラー (HTTP %d): 永続的なエラーのため即座に中断します\n", apiErr.StatusCode)
-					return latest, false, "", fmt.Sprintf("HTTP %d %s: %s", apiErr.StatusCode, apiErr.URL, strings.TrimSpace(apiErr.Message)), latestAt
+					return latest, false, "", fmt.Sprintf("HTTP %d %s: %s", apiErr.StatusCode, apiErr.URL, apiErr.Detail()), latestAt
 				}
 				// Retryable (429 / 5xx). Honour Retry-After when the
 				// server supplied it AND it is longer than our default
@@ -774,8 +981,9 @@ func waitForScanCompletion(ctx context.Context, client *api.Client, projectID, s
 					fmt.Fprintf(progressW(), "   ⚠️  scan-status server error (HTTP %d), retrying after %s...\n", apiErr.StatusCode, nextSleep.Round(time.Second))
 				}
 			} else {
-				// Non-APIError transient (network flap, JSON parse
-				// glitch, …): log and keep polling within ctx budget.
+				// Non-API-status transient (network flap, JSON parse
+				// glitch, protocol violation, …): log and keep polling
+				// within ctx budget.
 				fmt.Fprintf(progressW(), "   ⚠️  scan-status 取得エラー (継続して再試行): %v\n", err)
 			}
 		} else {
@@ -805,7 +1013,7 @@ func waitForScanCompletion(ctx context.Context, client *api.Client, projectID, s
 		// Sleep until next poll tick or ctx cancellation, whichever
 		// comes first. select prevents a fixed-tick Sleep from outliving
 		// the timeout. nextSleep equals `tick` on the happy path; a
-		// retryable APIError carrying Retry-After may have bumped it up
+		// retryable *sbomhub.Error carrying Retry-After may have bumped it up
 		// for this single iteration (Codex R13 P2).
 		timer := time.NewTimer(nextSleep)
 		select {
@@ -824,7 +1032,7 @@ func fallbackString(s, fallback string) string {
 	return s
 }
 
-func printResultBox(componentCount int, result *api.UploadResult, summary *api.VulnerabilitySummary) {
+func printResultBox(componentCount int, result *sbomhub.UploadResult, summary *sbomhub.VulnerabilitySummary) {
 	fmt.Println("┌─────────────────────────────────────────────────────────┐")
 	fmt.Println("│ スキャン完了                                            │")
 	fmt.Println("├─────────────────────────────────────────────────────────┤")
@@ -836,23 +1044,23 @@ func printResultBox(componentCount int, result *api.UploadResult, summary *api.V
 	fmt.Println("└─────────────────────────────────────────────────────────┘")
 }
 
-func countComponents(sbomData []byte) int {
-	var sbom map[string]interface{}
-	if err := json.Unmarshal(sbomData, &sbom); err != nil {
-		return 0
+// copySBOMFile writes the SBOM at src to dst (--output) without reading
+// it into memory.
+func copySBOMFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
 	}
-
-	// CycloneDX
-	if components, ok := sbom["components"].([]interface{}); ok {
-		return len(components)
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
 	}
-
-	// SPDX
-	if packages, ok := sbom["packages"].([]interface{}); ok {
-		return len(packages)
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
 	}
-
-	return 0
+	return out.Close()
 }
 
 // formatScanVulnSummary builds the per-severity line shown in the result
@@ -897,7 +1105,7 @@ func countComponents(sbomData []byte) int {
 // free. The legacy `result.*` fallback is preserved when those fields
 // are non-zero (future server versions that populate counts on upload),
 // so we only emit the "未完了" marker when there is truly no signal.
-func formatScanVulnSummary(result *api.UploadResult, summary *api.VulnerabilitySummary) string {
+func formatScanVulnSummary(result *sbomhub.UploadResult, summary *sbomhub.VulnerabilitySummary) string {
 	c, h, m, l, u, kev := 0, 0, 0, 0, 0, 0
 	switch {
 	case summary != nil:
@@ -14,8 +14,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
 	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // TestWaitForScanCompletion_Completes verifies the polling loop reaches a
@@ -29,17 +29,17 @@ func TestWaitForScanCompletion_Completes(t *testing.T) {
 	var hits int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		n := atomic.AddInt32(&hits, 1)
-		resp := api.ScanStatusResponse{
+		resp := sbomhub.ScanStatusResponse{
 			Status:    "running",
 			SbomID:    sbomID,
 			ProjectID: projectID,
-			Vulnerabilities: api.VulnerabilitySummary{
+			Vulnerabilities: sbomhub.VulnerabilitySummary{
 				Critical: 0, High: 1, Medium: 0, Low: 0, Total: 1,
 			},
 		}
 		if n >= 2 {
 			resp.Status = "completed"
-			resp.Vulnerabilities = api.VulnerabilitySummary{
+			resp.Vulnerabilities = sbomhub.VulnerabilitySummary{
 				Critical: 2, High: 3, Medium: 0, Low: 0, KEV: 1, Total: 5,
 			}
 		}
@@ -47,7 +47,7 @@ func TestWaitForScanCompletion_Completes(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	// Force a fast polling cadence so the test does not idle.
 	scanWaitTimeout = 5 * time.Second
@@ -91,14 +91,14 @@ func TestWaitForScanCompletion_Completes(t *testing.T) {
 // timedOut=true with no summary, which scan.go maps to exit code 2.
 func TestWaitForScanCompletion_TimesOut(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp := api.ScanStatusResponse{
+		resp := sbomhub.ScanStatusResponse{
 			Status: "running",
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	scanWaitTimeout = 80 * time.Millisecond
 	scanPollInterval = 10 * time.Millisecond
@@ -123,7 +123,7 @@ func TestWaitForScanCompletion_TimesOut(t *testing.T) {
 // treated as success. scan.go maps this to exit code 2 as well.
 func TestWaitForScanCompletion_Failed(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp := api.ScanStatusResponse{
+		resp := sbomhub.ScanStatusResponse{
 			Status: "failed",
 			Error:  "nvd: rate limited",
 		}
@@ -131,7 +131,7 @@ func TestWaitForScanCompletion_Failed(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	scanWaitTimeout = 5 * time.Second
 	scanPollInterval = 10 * time.Millisecond
@@ -175,11 +175,11 @@ func TestWaitForScanCompletion_TimeoutPreservesPartialCounts(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		n := atomic.AddInt32(&hits, 1)
 		if n <= 2 {
-			resp := api.ScanStatusResponse{
+			resp := sbomhub.ScanStatusResponse{
 				Status:    "running",
 				SbomID:    sbomID,
 				ProjectID: projectID,
-				Vulnerabilities: api.VulnerabilitySummary{
+				Vulnerabilities: sbomhub.VulnerabilitySummary{
 					Critical: 1, High: 2, Medium: 0, Low: 0, KEV: 1, Total: 3,
 				},
 			}
@@ -194,7 +194,7 @@ func TestWaitForScanCompletion_TimeoutPreservesPartialCounts(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	// Tight ctx deadline so the test finishes fast, but long enough for
 	// the first two polls (10ms interval) to land.
@@ -254,7 +254,7 @@ func TestWaitForScanCompletion_TimeoutNoSuccessfulPoll(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	scanWaitTimeout = 80 * time.Millisecond
 	scanPollInterval = 10 * time.Millisecond
@@ -299,7 +299,7 @@ func TestWaitForScanCompletion_ContextCancelAbortsHungRequest(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	// Set the package-level wait timeout high enough that, if the HTTP
 	// request were bound to httpClient.Timeout (the broken behavior) the
@@ -338,7 +338,7 @@ func TestWaitForScanCompletion_ContextCancelAbortsHungRequest(t *testing.T) {
 // SBOMHUB_API_URL / SBOMHUB_API_KEY env vars are honoured anyway.
 //
 // Before the fix, scan.go called config.Load which returned an error for
-// the missing file, killing the run with exitAPIError before the flag /
+// the missing file, killing the run with exitPermanent before the flag /
 // env layer could even be inspected.
 func TestResolveCredentials_FailSoftMissingConfig(t *testing.T) {
 	tmpDir := t.TempDir() // intentionally no config.yaml
@@ -433,24 +433,24 @@ func TestResolveCredentials_Precedence(t *testing.T) {
 func TestFormatScanVulnSummary_UnknownNotDropped(t *testing.T) {
 	cases := []struct {
 		name    string
-		summary *api.VulnerabilitySummary
+		summary *sbomhub.VulnerabilitySummary
 		wantHas string
 		wantNot string
 	}{
 		{
 			name:    "unknown only",
-			summary: &api.VulnerabilitySummary{Unknown: 5, Total: 5},
+			summary: &sbomhub.VulnerabilitySummary{Unknown: 5, Total: 5},
 			wantHas: "5 Unknown",
 			wantNot: "なし",
 		},
 		{
 			name:    "unknown + critical both shown",
-			summary: &api.VulnerabilitySummary{Critical: 2, Unknown: 3, Total: 5},
+			summary: &sbomhub.VulnerabilitySummary{Critical: 2, Unknown: 3, Total: 5},
 			wantHas: "3 Unknown",
 		},
 		{
 			name:    "all zero still says なし",
-			summary: &api.VulnerabilitySummary{},
+			summary: &sbomhub.VulnerabilitySummary{},
 			wantHas: "なし",
 		},
 	}
@@ -480,13 +480,13 @@ func TestFormatScanVulnSummary_UnknownNotDropped(t *testing.T) {
 func TestFormatScanVulnSummary_ServerTotalZeroButBucketsPopulated(t *testing.T) {
 	cases := []struct {
 		name    string
-		summary *api.VulnerabilitySummary
+		summary *sbomhub.VulnerabilitySummary
 		wantHas string // substring that MUST appear
 		wantNot string // substring that MUST NOT appear
 	}{
 		{
 			name: "total=0 + critical=1 surfaces critical",
-			summary: &api.VulnerabilitySummary{
+			summary: &sbomhub.VulnerabilitySummary{
 				Critical: 1, Total: 0,
 			},
 			wantHas: "1 Critical",
@@ -494,7 +494,7 @@ func TestFormatScanVulnSummary_ServerTotalZeroButBucketsPopulated(t *testing.T)
 		},
 		{
 			name: "total=0 + high=2 + medium=3 surfaces both",
-			summary: &api.VulnerabilitySummary{
+			summary: &sbomhub.VulnerabilitySummary{
 				High: 2, Medium: 3, Total: 0,
 			},
 			wantHas: "2 High",
@@ -502,7 +502,7 @@ func TestFormatScanVulnSummary_ServerTotalZeroButBucketsPopulated(t *testing.T)
 		},
 		{
 			name: "total=0 + low=1 surfaces low",
-			summary: &api.VulnerabilitySummary{
+			summary: &sbomhub.VulnerabilitySummary{
 				Low: 1, Total: 0,
 			},
 			wantHas: "1 Low",
@@ -510,7 +510,7 @@ func TestFormatScanVulnSummary_ServerTotalZeroButBucketsPopulated(t *testing.T)
 		},
 		{
 			name: "all buckets zero + total=0 still says なし",
-			summary: &api.VulnerabilitySummary{
+			summary: &sbomhub.VulnerabilitySummary{
 				Total: 0,
 			},
 			wantHas: "なし",
@@ -520,7 +520,7 @@ func TestFormatScanVulnSummary_ServerTotalZeroButBucketsPopulated(t *testing.T)
 			// Pathological server: claims findings exist but does not
 			// itemize them. We render "なし" because the per-severity
 			// breakdown is what the operator actually consumes.
-			summary: &api.VulnerabilitySummary{
+			summary: &sbomhub.VulnerabilitySummary{
 				Total: 42,
 			},
 			wantHas: "なし",
@@ -561,8 +561,8 @@ func TestFormatScanVulnSummary_ServerTotalZeroButBucketsPopulated(t *testing.T)
 func TestFormatScanVulnSummary_NilSummaryDoesNotShowClean(t *testing.T) {
 	cases := []struct {
 		name    string
-		result  *api.UploadResult
-		summary *api.VulnerabilitySummary
+		result  *sbomhub.UploadResult
+		summary *sbomhub.VulnerabilitySummary
 		wantHas string
 		wantNot string
 	}{
@@ -583,7 +583,7 @@ func TestFormatScanVulnSummary_NilSummaryDoesNotShowClean(t *testing.T) {
 			// "なし ✅" — that's exactly the silent-clean signal we are
 			// killing.
 			name:    "summary nil + result with all-zero counts renders 未完了, not なし",
-			result:  &api.UploadResult{},
+			result:  &sbomhub.UploadResult{},
 			summary: nil,
 			wantHas: "未完了",
 			wantNot: "なし",
@@ -593,7 +593,7 @@ func TestFormatScanVulnSummary_NilSummaryDoesNotShowClean(t *testing.T) {
 			// UploadResult including KEVCount/Component fields — the
 			// formatter must still detect "no signal" and emit 未完了.
 			name:    "summary nil + result populated zeros renders 未完了, not なし",
-			result:  &api.UploadResult{Success: true, ProjectID: "p", SBOMID: "s", ComponentCount: 17},
+			result:  &sbomhub.UploadResult{Success: true, ProjectID: "p", SBOMID: "s", ComponentCount: 17},
 			summary: nil,
 			wantHas: "未完了",
 			wantNot: "なし",
@@ -605,7 +605,7 @@ func TestFormatScanVulnSummary_NilSummaryDoesNotShowClean(t *testing.T) {
 			// them behind 未完了. The "未完了" marker is for the
 			// genuinely-no-signal case only.
 			name:    "summary nil + result with real counts surfaces counts",
-			result:  &api.UploadResult{Critical: 1, High: 2, KEVCount: 1},
+			result:  &sbomhub.UploadResult{Critical: 1, High: 2, KEVCount: 1},
 			summary: nil,
 			wantHas: "1 Critical",
 			wantNot: "未完了",
@@ -619,7 +619,7 @@ func TestFormatScanVulnSummary_NilSummaryDoesNotShowClean(t *testing.T) {
 			// pass silently.
 			name:    "summary non-nil with all zeros still renders なし (legit clean scan)",
 			result:  nil,
-			summary: &api.VulnerabilitySummary{},
+			summary: &sbomhub.VulnerabilitySummary{},
 			wantHas: "なし",
 			wantNot: "未完了",
 		},
@@ -796,7 +796,7 @@ func TestWaitForScanCompletion_PermanentAPIErrorFastFails(t *testing.T) {
 			}))
 			defer server.Close()
 
-			client := api.NewClient(server.URL, "test-key")
+			client := sbomhub.NewClient(server.URL, "test-key")
 
 			// Generous wait-timeout: a regressed loop that retries the
 			// 4xx would burn the full budget. Fast-fail must land in
@@ -871,11 +871,11 @@ func TestWaitForScanCompletion_RateLimit429RetriesThenCompletes(t *testing.T) {
 			_, _ = w.Write([]byte(`{"error":"rate limited"}`))
 			return
 		}
-		resp := api.ScanStatusResponse{
+		resp := sbomhub.ScanStatusResponse{
 			Status:    "completed",
 			SbomID:    sbomID,
 			ProjectID: projectID,
-			Vulnerabilities: api.VulnerabilitySummary{
+			Vulnerabilities: sbomhub.VulnerabilitySummary{
 				Critical: 1, High: 0, Medium: 0, Low: 0, Total: 1,
 			},
 		}
@@ -883,7 +883,7 @@ func TestWaitForScanCompletion_RateLimit429RetriesThenCompletes(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	scanWaitTimeout = 5 * time.Second
 	scanPollInterval = 10 * time.Millisecond
@@ -948,18 +948,18 @@ func TestWaitForScanCompletion_RateLimitRetryAfterHonored(t *testing.T) {
 			_, _ = w.Write([]byte(`{"error":"rate limited"}`))
 			return
 		}
-		_ = json.NewEncoder(w).Encode(api.ScanStatusResponse{
+		_ = json.NewEncoder(w).Encode(sbomhub.ScanStatusResponse{
 			Status:    "completed",
 			SbomID:    sbomID,
 			ProjectID: projectID,
-			Vulnerabilities: api.VulnerabilitySummary{
+			Vulnerabilities: sbomhub.VulnerabilitySummary{
 				Critical: 0, Total: 0,
 			},
 		})
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	// Poll interval intentionally much smaller than Retry-After so that
 	// without the R13 fix (Retry-After ignored) the gap would be ~10ms
@@ -1017,7 +1017,7 @@ func TestWaitForScanCompletion_TransientServerErrorRetries(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 	scanWaitTimeout = 120 * time.Millisecond
 	scanPollInterval = 10 * time.Millisecond
 	t.Cleanup(func() {
@@ -1045,7 +1045,7 @@ func TestWaitForScanCompletion_TransientServerErrorRetries(t *testing.T) {
 
 // TestRunScan_PermanentScanStatusErrorReturnsExit3 verifies the end-to-end
 // path through runScan: a permanent 4xx during scan-status polling must
-// surface as a scanExitError with code=exitAPIError (3), so main.go
+// surface as a exitError with code=exitPermanent (3), so main.go
 // routes it to os.Exit(3). This is what makes the R7 fix observable to
 // CI workflows.
 //
@@ -1067,6 +1067,9 @@ func TestRunScan_PermanentScanStatusErrorReturnsExit3(t *testing.T) {
 			// Upload succeeds so we reach the polling loop.
 			w.WriteHeader(http.StatusCreated)
 			_, _ = w.Write([]byte(`{"id":"` + sbomID + `","project_id":"` + projectID + `","format":"cyclonedx","version":"1.4","created_at":"2026-06-24T00:00:00Z"}`))
+		case r.URL.Path == "/api/v1/capabilities":
+			// A server that predates capability negotiation.
+			w.WriteHeader(http.StatusNotFound)
 		case r.URL.Path == "/api/v1/projects/"+projectID+"/sboms/"+sbomID+"/scan-status":
 			// Permanent error: should fast-fail on the first hit.
 			w.WriteHeader(http.StatusUnauthorized)
@@ -1132,14 +1135,14 @@ func TestRunScan_PermanentScanStatusErrorReturnsExit3(t *testing.T) {
 	tmpDir := t.TempDir()
 	err := runScan(scanCmd, []string{tmpDir})
 	if err == nil {
-		t.Fatal("runScan returned nil; expected scanExitError with exit code 3")
+		t.Fatal("runScan returned nil; expected exitError with exit code 3")
 	}
-	var exitErr *scanExitError
+	var exitErr *exitError
 	if !errors.As(err, &exitErr) {
-		t.Fatalf("error is not *scanExitError: %T %v", err, err)
+		t.Fatalf("error is not *exitError: %T %v", err, err)
 	}
-	if exitErr.ExitCode() != exitAPIError {
-		t.Errorf("exit code = %d, want %d (exitAPIError); permanent 4xx must not map to exit-2 (timeout)", exitErr.ExitCode(), exitAPIError)
+	if exitErr.ExitCode() != exitPermanent {
+		t.Errorf("exit code = %d, want %d (exitPermanent); permanent 4xx must not map to exit-2 (timeout)", exitErr.ExitCode(), exitPermanent)
 	}
 	if !strings.Contains(exitErr.Error(), "scan-status polling aborted") {
 		t.Errorf("exit message = %q, want substring %q so the operator sees the actual failure mode", exitErr.Error(), "scan-status polling aborted")
@@ -1182,7 +1185,7 @@ func TestComputeScanStatus(t *testing.T) {
 			in: scanFinalState{
 				waitForScan:  true,
 				scanTimedOut: true,
-				summary:      &api.VulnerabilitySummary{High: 1, Total: 1},
+				summary:      &sbomhub.VulnerabilitySummary{High: 1, Total: 1},
 			},
 			want: "running",
 		},
@@ -1195,7 +1198,7 @@ func TestComputeScanStatus(t *testing.T) {
 			name: "clean completion reports completed",
 			in: scanFinalState{
 				waitForScan: true,
-				summary:     &api.VulnerabilitySummary{},
+				summary:     &sbomhub.VulnerabilitySummary{},
 			},
 			want: "completed",
 		},
@@ -1203,7 +1206,7 @@ func TestComputeScanStatus(t *testing.T) {
 			name: "completion with findings reports completed",
 			in: scanFinalState{
 				waitForScan: true,
-				summary:     &api.VulnerabilitySummary{Critical: 2, KEV: 1, Total: 2},
+				summary:     &sbomhub.VulnerabilitySummary{Critical: 2, KEV: 1, Total: 2},
 			},
 			want: "completed",
 		},
@@ -1229,14 +1232,14 @@ func TestBuildScanJSONResult_Schema(t *testing.T) {
 	in := scanFinalState{
 		componentCount: 42,
 		format:         "cyclonedx",
-		uploadResult: &api.UploadResult{
+		uploadResult: &sbomhub.UploadResult{
 			SBOMID:         "sbom-uuid",
 			ProjectID:      "project-uuid",
 			ProjectName:    "my-app",
 			ProjectCreated: true,
 			URL:            "https://sbomhub.app/projects/project-uuid",
 		},
-		summary: &api.VulnerabilitySummary{
+		summary: &sbomhub.VulnerabilitySummary{
 			Critical: 1, High: 2, Medium: 3, Low: 4, Unknown: 5, KEV: 1, Total: 15,
 		},
 		waitForScan:     true,
@@ -1329,10 +1332,10 @@ func TestBuildScanJSONResult_NullThreshold(t *testing.T) {
 	in := scanFinalState{
 		componentCount: 0,
 		format:         "cyclonedx",
-		uploadResult: &api.UploadResult{
+		uploadResult: &sbomhub.UploadResult{
 			SBOMID: "s", ProjectID: "p",
 		},
-		summary:     &api.VulnerabilitySummary{},
+		summary:     &sbomhub.VulnerabilitySummary{},
 		waitForScan: true,
 		failOnStr:   "", // no --fail-on supplied
 		exitCode:    exitSuccess,
@@ -1368,8 +1371,8 @@ func TestBuildScanJSONResult_Scenarios(t *testing.T) {
 			in: scanFinalState{
 				componentCount: 10,
 				format:         "cyclonedx",
-				uploadResult:   &api.UploadResult{SBOMID: "s", ProjectID: "p"},
-				summary:        &api.VulnerabilitySummary{},
+				uploadResult:   &sbomhub.UploadResult{SBOMID: "s", ProjectID: "p"},
+				summary:        &sbomhub.VulnerabilitySummary{},
 				waitForScan:    true,
 				exitCode:       exitSuccess,
 			},
@@ -1381,8 +1384,8 @@ func TestBuildScanJSONResult_Scenarios(t *testing.T) {
 			in: scanFinalState{
 				componentCount: 100,
 				format:         "cyclonedx",
-				uploadResult:   &api.UploadResult{SBOMID: "s", ProjectID: "p"},
-				summary:        &api.VulnerabilitySummary{Critical: 3, High: 1, Total: 4},
+				uploadResult:   &sbomhub.UploadResult{SBOMID: "s", ProjectID: "p"},
+				summary:        &sbomhub.VulnerabilitySummary{Critical: 3, High: 1, Total: 4},
 				waitForScan:    true,
 				failOnStr:      "critical",
 				failOnTriggered: true,
@@ -1398,8 +1401,8 @@ func TestBuildScanJSONResult_Scenarios(t *testing.T) {
 			in: scanFinalState{
 				componentCount: 50,
 				format:         "cyclonedx",
-				uploadResult:   &api.UploadResult{SBOMID: "s", ProjectID: "p"},
-				summary:        &api.VulnerabilitySummary{High: 2, Total: 2},
+				uploadResult:   &sbomhub.UploadResult{SBOMID: "s", ProjectID: "p"},
+				summary:        &sbomhub.VulnerabilitySummary{High: 2, Total: 2},
 				waitForScan:    true,
 				scanTimedOut:   true,
 				failOnStr:      "high",
@@ -1413,7 +1416,7 @@ func TestBuildScanJSONResult_Scenarios(t *testing.T) {
 			in: scanFinalState{
 				componentCount: 5,
 				format:         "cyclonedx",
-				uploadResult:   &api.UploadResult{SBOMID: "s", ProjectID: "p"},
+				uploadResult:   &sbomhub.UploadResult{SBOMID: "s", ProjectID: "p"},
 				scanFailedMsg:  "nvd unreachable",
 				waitForScan:    true,
 				failOnStr:      "high",
@@ -1427,20 +1430,20 @@ func TestBuildScanJSONResult_Scenarios(t *testing.T) {
 			in: scanFinalState{
 				componentCount: 5,
 				format:         "cyclonedx",
-				uploadResult:   &api.UploadResult{SBOMID: "s", ProjectID: "p"},
+				uploadResult:   &sbomhub.UploadResult{SBOMID: "s", ProjectID: "p"},
 				scanAPIErrMsg:  "HTTP 401 unauthorized",
 				waitForScan:    true,
-				exitCode:       exitAPIError,
+				exitCode:       exitPermanent,
 			},
 			wantStatus:   "unknown",
-			wantExitCode: exitAPIError,
+			wantExitCode: exitPermanent,
 		},
 		{
 			name: "--wait-for-scan=false: status=skipped, exit=0",
 			in: scanFinalState{
 				componentCount: 5,
 				format:         "cyclonedx",
-				uploadResult:   &api.UploadResult{SBOMID: "s", ProjectID: "p"},
+				uploadResult:   &sbomhub.UploadResult{SBOMID: "s", ProjectID: "p"},
 				waitForScan:    false,
 				exitCode:       exitSuccess,
 			},
@@ -1498,17 +1501,17 @@ func TestBuildScanJSONResult_NilUploadResult(t *testing.T) {
 	}
 }
 
-// TestScanExitError_ExitCode verifies the scanExitError contract that
+// TestScanExitError_ExitCode verifies the exitError contract that
 // main.go relies on for exit-code routing.
 func TestScanExitError_ExitCode(t *testing.T) {
 	cases := []struct {
 		name string
-		err  *scanExitError
+		err  *exitError
 		want int
 	}{
-		{"threshold", &scanExitError{code: exitThresholdExceeded, msg: "x"}, 1},
-		{"timeout", &scanExitError{code: exitScanTimeout, msg: "x"}, 2},
-		{"api error", &scanExitError{code: exitAPIError, msg: "x"}, 3},
+		{"threshold", &exitError{code: exitThresholdExceeded, msg: "x"}, 1},
+		{"timeout", &exitError{code: exitScanTimeout, msg: "x"}, 2},
+		{"api error", &exitError{code: exitPermanent, msg: "x"}, 3},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
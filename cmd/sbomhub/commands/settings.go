@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+)
+
+// Where an effective setting came from, highest precedence first.
+const (
+	originFlag    = "flag"
+	originEnv     = "env"
+	originProject = "project"
+	originProfile = "profile"
+	originDefault = "default"
+)
+
+// settingValue is the effective value of one setting and the layer that
+// supplied it. Source names the layer precisely: the flag, the variable,
+// the file or the profile.
+type settingValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+// settingLayers holds the file-backed layers of one run: the active
+// profile merged over the config.yaml stack and the .sbomhub.yaml that
+// applies to the working directory. Flags and environment variables are
+// read at resolve time.
+type settingLayers struct {
+	stack       *config.Stack
+	profileName string
+	profile     *config.Config
+	project     *config.Config
+	projectPath string
+}
+
+// loadSettingLayers reads the profile selected for this run and the
+// repository's .sbomhub.yaml. Both are optional; a malformed file or an
+// unknown --profile is an error.
+func loadSettingLayers() (*settingLayers, error) {
+	stack, err := loadConfigStack(getConfigDir())
+	if err != nil {
+		return nil, fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	f := stack.File()
+	profile, _ := activeProfile()
+	l := &settingLayers{stack: stack, profileName: f.ResolveName(profile), profile: &config.Config{}}
+	if f.HasProfile(l.profileName) {
+		if l.profile, err = f.Profile(l.profileName); err != nil {
+			return nil, err
+		}
+	} else if profile != "" && profile != config.DefaultProfile {
+		return nil, fmt.Errorf("プロファイル %q が見つかりません (定義済み: %s)", l.profileName, strings.Join(f.Names(), ", "))
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	dir := config.FindProjectDir(cwd)
+	if dir == "" {
+		return l, nil
+	}
+	if l.project, err = config.LoadProjectFile(dir); err != nil {
+		return nil, err
+	}
+	if l.project != nil {
+		l.projectPath = filepath.Join(dir, config.ProjectFileName)
+	}
+	return l, nil
+}
+
+// resolve returns the effective value of k: global flag, SBOMHUB_* env
+// var, .sbomhub.yaml, profile, built-in default. Values are returned as
+// stored; callers mask api_key for display.
+func (l *settingLayers) resolve(k *config.Key) settingValue {
+	sv := settingValue{Key: k.Name}
+	if k.Flag != "" {
+		if fl := rootCmd.PersistentFlags().Lookup(k.Flag); fl != nil && fl.Changed {
+			sv.Value, sv.Origin, sv.Source = fl.Value.String(), originFlag, "--"+k.Flag
+			return sv
+		}
+	}
+	if k.Env != "" {
+		if v := os.Getenv(k.Env); v != "" {
+			sv.Value, sv.Origin, sv.Source = v, originEnv, k.Env
+			return sv
+		}
+	}
+	if k.Project && l.project != nil {
+		if v := k.Get(l.project); v != "" {
+			sv.Value, sv.Origin, sv.Source = v, originProject, l.projectPath
+			return sv
+		}
+	}
+	if v := k.Get(l.profile); v != "" || (k.Name == "api_key" && describeAPIKey(l.profile) != maskAPIKey("")) {
+		sv.Value, sv.Origin, sv.Source = v, originProfile, "profile "+l.profileName
+		if path := l.stack.Source(l.profileName, k); path != "" {
+			sv.Source += " (" + path + ")"
+		}
+		return sv
+	}
+	if k.Default != "" {
+		sv.Value, sv.Origin = k.Default, originDefault
+	}
+	return sv
+}
+
+// applySettingDefaults fills the flags of cmd the operator did not pass
+// from the env, .sbomhub.yaml and profile layers, so `config set fail_on
+// high` or a repository's .sbomhub.yaml applies to every later run.
+// bindings maps flag names to setting keys. The value is validated like
+// `config set`, then set through the flag's own parser; the flag is not
+// marked as changed, since the operator did not pass it.
+func applySettingDefaults(cmd *cobra.Command, bindings map[string]string) error {
+	var l *settingLayers
+	for flag, key := range bindings {
+		fl := cmd.Flags().Lookup(flag)
+		if fl == nil || fl.Changed {
+			continue
+		}
+		if l == nil {
+			var err error
+			if l, err = loadSettingLayers(); err != nil {
+				return err
+			}
+		}
+		k := config.LookupKey(key)
+		sv := l.resolve(k)
+		if sv.Origin == "" || sv.Origin == originDefault {
+			continue
+		}
+		v, err := k.Parse(sv.Value)
+		if err == nil {
+			err = fl.Value.Set(v)
+		}
+		if err != nil {
+			return fmt.Errorf("%s の設定値が不正です (%s): %w", key, sv.Source, err)
+		}
+		GetOutputConfig().PrintVerbose("--%s=%s (%s)", flag, v, sv.Source)
+	}
+	return nil
+}
+
+// effectiveMaxRetries is the retry budget for cfg's client: --max-retries
+// when given, else SBOMHUB_MAX_RETRIES, else the profile's max_retries,
+// else the flag default.
+func effectiveMaxRetries(cfg *config.Config) int {
+	if rootCmd.PersistentFlags().Changed("max-retries") {
+		return maxRetriesFlag
+	}
+	if n, err := strconv.Atoi(os.Getenv("SBOMHUB_MAX_RETRIES")); err == nil && n >= 0 {
+		return n
+	}
+	if cfg.MaxRetries != nil {
+		return *cfg.MaxRetries
+	}
+	return maxRetriesFlag
+}
@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/severity"
+	"github.com/youichi-uda/sbomhub-cli/internal/suppress"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// Shared `.sbomhubignore` plumbing for `scan` and `check`. The matching
+// rules live in internal/suppress; this file only adapts the two
+// commands' data sources into suppress.Finding and renders the outcome.
+//
+// Both commands apply suppressions BEFORE severity.ShouldFail, and the
+// vulnerability_summary they emit is the post-suppression view — i.e.
+// exactly what the gate evaluated. The suppressed findings themselves are
+// listed separately under `suppressions` so nothing disappears silently.
+
+// suppressionJSON is the `suppressions` object in `scan --json` /
+// `check --json`. Omitted entirely when no suppression file was loaded.
+// Slices are always non-nil so consumers get `[]` rather than `null`.
+type suppressionJSON struct {
+	File           string                  `json:"file"`
+	Suppressed     []suppressedFindingJSON `json:"suppressed"`
+	Expired        []suppress.Entry        `json:"expired"`
+	ExpiredMatched []suppress.Entry        `json:"expired_matched"`
+	Unevaluated    []suppress.Entry        `json:"unevaluated"`
+}
+
+type suppressedFindingJSON struct {
+	ID       string `json:"id"`
+	Package  string `json:"package,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Purl     string `json:"purl,omitempty"`
+	Severity string `json:"severity"`
+	Reason   string `json:"reason"`
+	Owner    string `json:"owner"`
+	Expires  string `json:"expires"`
+}
+
+// loadSuppressions resolves the suppression file for a run. An explicit
+// --ignore-file must exist; otherwise DefaultFileName is discovered in
+// dirs (first hit wins) and its absence is not an error.
+func loadSuppressions(explicit string, dirs ...string) (*suppress.File, error) {
+	path := explicit
+	if path == "" {
+		path = suppress.Discover(dirs...)
+		if path == "" {
+			return nil, nil
+		}
+	}
+	f, err := suppress.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("抑制ファイルの読み込みに失敗しました: %w", err)
+	}
+	return f, nil
+}
+
+// suppressionSearchDirs returns the directories searched for an implicit
+// `.sbomhubignore`: the working directory first (repo root in CI), then
+// the scan target itself (or the directory holding an SBOM file).
+func suppressionSearchDirs(target string) []string {
+	dirs := []string{}
+	if cwd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, cwd)
+	}
+	if st, err := os.Stat(target); err == nil && st.IsDir() {
+		dirs = append(dirs, target)
+	} else {
+		dirs = append(dirs, filepath.Dir(target))
+	}
+	return dirs
+}
+
+// findingsFromVulnRecords adapts the project-level vulnerability rows
+// `scan` fetches after upload. They carry no component information, so
+// callers must pass componentData=false to Apply.
+func findingsFromVulnRecords(recs []sbomhub.VulnerabilityRecord) []suppress.Finding {
+	out := make([]suppress.Finding, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, suppress.Finding{
+			ID:       r.CVEID,
+			Severity: r.Severity,
+			InKEV:    r.InKEV,
+		})
+	}
+	return out
+}
+
+// findingsFromCheckResult adapts CheckResult.Vulnerabilities. The check
+// endpoint reports package/version but not the purl, so the purl is
+// looked up from the components that were sent.
+func findingsFromCheckResult(result *sbomhub.CheckResult, comps []sbomhub.ComponentInput) []suppress.Finding {
+	purls := make(map[string]string, len(comps))
+	for _, c := range comps {
+		if c.Purl != "" {
+			purls[c.Name+"@"+c.Version] = c.Purl
+		}
+	}
+	out := make([]suppress.Finding, 0, len(result.Vulnerabilities))
+	for _, v := range result.Vulnerabilities {
+		out = append(out, suppress.Finding{
+			ID:       v.ID,
+			Aliases:  v.Aliases,
+			Package:  v.Package,
+			Version:  v.Version,
+			Purl:     purls[v.Package+"@"+v.Version],
+			Severity: v.Severity,
+		})
+	}
+	return out
+}
+
+// suppressedFindings returns the findings a suppression result removed.
+func suppressedFindings(res *suppress.Result) []suppress.Finding {
+	if res == nil {
+		return nil
+	}
+	out := make([]suppress.Finding, 0, len(res.Suppressed))
+	for _, s := range res.Suppressed {
+		out = append(out, s.Finding)
+	}
+	return out
+}
+
+// subtractSuppressed removes the excluded findings (`.sbomhubignore` and
+// VEX alike) from c. Buckets are clamped at zero: the counts and the
+// finding list come from different server projections in the `scan`
+// path, and a suppressed CVE that is in the project but not in this SBOM
+// must not drive a bucket negative.
+func subtractSuppressed(c severity.Counts, sup []suppress.Finding) severity.Counts {
+	dec := func(n *int) {
+		if *n > 0 {
+			*n--
+		}
+	}
+	for _, s := range sup {
+		switch strings.ToUpper(s.Severity) {
+		case "CRITICAL":
+			dec(&c.Critical)
+		case "HIGH":
+			dec(&c.High)
+		case "MEDIUM":
+			dec(&c.Medium)
+		case "LOW":
+			dec(&c.Low)
+		default:
+			dec(&c.Unknown)
+		}
+		if s.InKEV {
+			dec(&c.KEV)
+		}
+	}
+	return c
+}
+
+// suppressedSummary returns a copy of summary with the excluded findings
+// removed and Total recomputed from the CVSS buckets.
+func suppressedSummary(summary *sbomhub.VulnerabilitySummary, sup []suppress.Finding) *sbomhub.VulnerabilitySummary {
+	if summary == nil || len(sup) == 0 {
+		return summary
+	}
+	c := subtractSuppressed(severity.Counts{
+		Critical: summary.Critical,
+		High:     summary.High,
+		Medium:   summary.Medium,
+		Low:      summary.Low,
+		Unknown:  summary.Unknown,
+		KEV:      summary.KEV,
+	}, sup)
+	return &sbomhub.VulnerabilitySummary{
+		Critical: c.Critical,
+		High:     c.High,
+		Medium:   c.Medium,
+		Low:      c.Low,
+		Unknown:  c.Unknown,
+		KEV:      c.KEV,
+		Total:    c.Critical + c.High + c.Medium + c.Low + c.Unknown,
+	}
+}
+
+func buildSuppressionJSON(f *suppress.File, res *suppress.Result) *suppressionJSON {
+	if f == nil || res == nil {
+		return nil
+	}
+	j := &suppressionJSON{
+		File:           f.Path,
+		Suppressed:     []suppressedFindingJSON{},
+		Expired:        append([]suppress.Entry{}, res.Expired...),
+		ExpiredMatched: append([]suppress.Entry{}, res.ExpiredMatched...),
+		Unevaluated:    append([]suppress.Entry{}, res.Unevaluated...),
+	}
+	for _, s := range res.Suppressed {
+		j.Suppressed = append(j.Suppressed, suppressedFindingJSON{
+			ID:       s.Finding.ID,
+			Package:  s.Finding.Package,
+			Version:  s.Finding.Version,
+			Purl:     s.Finding.Purl,
+			Severity: s.Finding.Severity,
+			Reason:   s.Entry.Reason,
+			Owner:    s.Entry.Owner,
+			Expires:  s.Entry.Expires,
+		})
+	}
+	return j
+}
+
+// printSuppressionReport writes the human summary of what was suppressed
+// plus warnings for expired / unevaluated entries. Everything goes to w
+// (stderr in practice) so it shows up in CI logs even under --json.
+func printSuppressionReport(w io.Writer, f *suppress.File, res *suppress.Result) {
+	if f == nil || res == nil {
+		return
+	}
+	if len(res.Suppressed) > 0 {
+		fmt.Fprintf(w, "🙈 %s により %d 件の脆弱性を抑制しました:\n", f.Path, len(res.Suppressed))
+		for _, s := range res.Suppressed {
+			target := s.Finding.ID
+			if s.Finding.Package != "" {
+				target += " (" + s.Finding.Package + "@" + s.Finding.Version + ")"
+			}
+			fmt.Fprintf(w, "   - %s: %s [owner=%s, expires=%s]\n", target, s.Entry.Reason, s.Entry.Owner, s.Entry.Expires)
+		}
+	}
+	for _, e := range res.Expired {
+		fmt.Fprintf(w, "⚠️  抑制エントリ %s (owner=%s) は %s に期限切れです。 適用されていません\n", e.ID, e.Owner, e.Expires)
+	}
+	for _, e := range res.Unevaluated {
+		fmt.Fprintf(w, "⚠️  抑制エントリ %s は purl/version を指定していますが、 このコマンドの脆弱性データにはコンポーネント情報が無いため評価されていません\n", e.ID)
+	}
+}
+
+// expiredSuppressionMessage is the gate-failure message for
+// `expired: fail` when a stale entry still matches a live finding.
+func expiredSuppressionMessage(res *suppress.Result) string {
+	ids := make([]string, 0, len(res.ExpiredMatched))
+	for _, e := range res.ExpiredMatched {
+		ids = append(ids, fmt.Sprintf("%s (expires=%s, owner=%s)", e.ID, e.Expires, e.Owner))
+	}
+	return fmt.Sprintf("期限切れの抑制エントリが現存する脆弱性に一致しました (expired: fail): %s", strings.Join(ids, ", "))
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/suppress"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// TestSuppressedSummary_ClampsAndRecomputesTotal covers the scan path:
+// suppressed project rows are subtracted from the scan-status snapshot,
+// KEV is decremented alongside the CVSS bucket, and a suppressed row
+// whose bucket is already empty (CVE in the project but not in this
+// SBOM) does not go negative.
+func TestSuppressedSummary_ClampsAndRecomputesTotal(t *testing.T) {
+	in := &sbomhub.VulnerabilitySummary{Critical: 1, High: 2, Low: 0, KEV: 1, Total: 3}
+	sup := []suppress.Finding{
+		{ID: "CVE-A", Severity: "critical", InKEV: true},
+		{ID: "CVE-B", Severity: "HIGH"},
+		{ID: "CVE-C", Severity: "LOW"},
+	}
+	got := suppressedSummary(in, sup)
+	if got.Critical != 0 || got.High != 1 || got.Low != 0 || got.KEV != 0 {
+		t.Errorf("suppressedSummary() = %+v, want critical=0 high=1 low=0 kev=0", got)
+	}
+	if got.Total != 1 {
+		t.Errorf("Total = %d, want 1 (recomputed from buckets)", got.Total)
+	}
+	if in.Critical != 1 {
+		t.Error("input summary was mutated")
+	}
+}
+
+// TestBuildScanJSONResult_SuppressionsOmittedWithoutFile pins that the
+// `suppressions` key only appears when a suppression file was loaded, so
+// existing consumers see an unchanged payload.
+func TestBuildScanJSONResult_SuppressionsOmittedWithoutFile(t *testing.T) {
+	r := buildScanJSONResult(scanFinalState{waitForScan: true})
+	if r.Suppressions != nil {
+		t.Errorf("Suppressions = %+v, want nil without a suppression file", r.Suppressions)
+	}
+	f, err := suppress.Parse([]byte("suppressions: []"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := f.Apply(nil, time.Now(), false)
+	r = buildScanJSONResult(scanFinalState{waitForScan: true, suppressions: buildSuppressionJSON(f, &res)})
+	if r.Suppressions == nil || r.Suppressions.Suppressed == nil {
+		t.Errorf("Suppressions = %+v, want non-nil with empty (not null) lists", r.Suppressions)
+	}
+}
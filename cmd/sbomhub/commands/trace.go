@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// --trace / --trace-har wiring. internal/api/trace.go does the recording;
+// this file owns the destinations for one run. The tracer is created in
+// the root PersistentPreRunE, attached by newAPIClient to every client
+// the command builds, and flushed by executeContext after the command
+// returns — so the HAR is written even when the command failed, which is
+// exactly when it is wanted.
+
+var (
+	// traceFlag is the text trace destination: "-" (the bare --trace)
+	// for stderr, otherwise a file path.
+	traceFlag string
+	// traceHARFlag is the HAR output path.
+	traceHARFlag string
+
+	// activeTracer is the tracer for this run, nil when tracing is off.
+	activeTracer *sbomhub.Tracer
+	// traceFile is the --trace=FILE destination, closed by finishTrace.
+	traceFile *os.File
+)
+
+// startTrace sets up activeTracer from the flags.
+func startTrace() error {
+	if traceFlag == "" && traceHARFlag == "" {
+		return nil
+	}
+	var w io.Writer
+	switch traceFlag {
+	case "":
+	case "-":
+		w = os.Stderr
+	default:
+		// 0600: the trace holds request / response bodies (SBOMs,
+		// vulnerability data) even though credentials are redacted.
+		f, err := os.OpenFile(traceFlag, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("--trace の出力先を開けません: %w", err)
+		}
+		traceFile, w = f, f
+	}
+	activeTracer = sbomhub.NewTracer(w)
+	if traceHARFlag != "" {
+		activeTracer.EnableHAR(version)
+	}
+	return nil
+}
+
+// finishTrace writes the HAR and closes the trace file. Failures are
+// reported on stderr but never change the command's exit code.
+func finishTrace() {
+	if activeTracer == nil {
+		return
+	}
+	if traceHARFlag != "" {
+		if err := writeHARFile(activeTracer, traceHARFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  HAR ファイルを書き込めませんでした: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "HAR を書き出しました: %s\n", traceHARFlag)
+		}
+	}
+	if traceFile != nil {
+		traceFile.Close()
+		traceFile = nil
+	}
+	activeTracer = nil
+}
+
+func writeHARFile(t *sbomhub.Tracer, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := t.WriteHAR(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
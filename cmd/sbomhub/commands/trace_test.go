@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTraceFlags_WriteLogAndHAR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "srv-"+r.Header.Get("X-Request-ID"))
+		http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+	t.Cleanup(func() { traceFlag, traceHARFlag = "", "" })
+
+	dir := t.TempDir()
+	logPath, harPath := filepath.Join(dir, "trace.log"), filepath.Join(dir, "trace.har")
+	err := runForExitCode(t, server.URL, []string{"projects", "list", "--trace=" + logPath, "--trace-har", harPath})
+	if err == nil || !strings.Contains(err.Error(), "[request-id: srv-") {
+		t.Fatalf("err = %v, want the server's request ID in the message", err)
+	}
+	if activeTracer != nil {
+		t.Error("the tracer outlived the run")
+	}
+
+	log, readErr := os.ReadFile(logPath)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	for _, want := range []string{"[trace] → GET " + server.URL, "[trace] ← 403 Forbidden", "Authorization: Bearer [REDACTED]"} {
+		if !strings.Contains(string(log), want) {
+			t.Errorf("trace log missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(string(log), "sbh_test") {
+		t.Error("trace log leaks the API key")
+	}
+
+	har, readErr := os.ReadFile(harPath)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	var doc struct {
+		Log struct {
+			Entries []struct {
+				Response struct{ Status int } `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(har, &doc); err != nil {
+		t.Fatalf("HAR is not JSON: %v", err)
+	}
+	if len(doc.Log.Entries) != 1 || doc.Log.Entries[0].Response.Status != http.StatusForbidden {
+		t.Errorf("HAR entries = %+v, want the single 403", doc.Log.Entries)
+	}
+}
@@ -58,7 +58,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // Decision outcome constants — values mirror the server-side
@@ -146,22 +146,11 @@ func init() {
 	triageCmd.Flags().Float64Var(&triageConfidenceThreshold, "confidence-threshold", 0.7, "AI confidence の最小しきい値 / minimum AI confidence threshold (server-side SBOMHUB_AI_CONFIDENCE_THRESHOLD が真の source of truth / the server-side env var is the source of truth)")
 }
 
-// triageExitError lets runTriage signal a specific exit code to
-// main() while still returning through the cobra error path. Mirrors
-// the scanExitError pattern in scan.go.
-type triageExitError struct {
-	code int
-	msg  string
-}
-
-func (e *triageExitError) Error() string { return e.msg }
-func (e *triageExitError) ExitCode() int { return e.code }
-
 // runTriage is the cobra entrypoint. Kept thin: input validation +
 // API client wiring + delegating to the loop. Everything that needs
 // to be unit-tested is reachable via the lower-level helpers
 // (interactWith, triageOneVuln, applyDecision) that take an injected
-// io.Reader / io.Writer / *api.Client so the test does not have to
+// io.Reader / io.Writer / *sbomhub.Client so the test does not have to
 // shell out cobra.
 func runTriage(cmd *cobra.Command, args []string) error {
 	out := GetOutputConfig()
@@ -169,8 +158,8 @@ func runTriage(cmd *cobra.Command, args []string) error {
 	// --project is required: every API endpoint we call is scoped to
 	// projects/:id. Surface this before any API round-trip so the
 	// operator sees the actionable error immediately.
-	if strings.TrimSpace(triageProject) == "" {
-		return fmt.Errorf("--project は必須です / --project is required")
+	if err := requireProjectFlag(cmd, &triageProject); err != nil {
+		return err
 	}
 
 	// --ecosystem warning: M1 supports Go only. Other values are
@@ -211,17 +200,36 @@ func runTriage(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
 	}
-	if cfg.APIKey == "" {
+	if !hasCredentials(cfg) {
 		return fmt.Errorf("API Keyが設定されていません。 'sbomhub login' で対話設定するか、 --api-key フラグ・ 環境変数 SBOMHUB_API_KEY を指定してください")
 	}
 	if cfg.APIURL == "" {
 		return fmt.Errorf("API URLが設定されていません。 'sbomhub login' で設定するか、 --api-url フラグ・ 環境変数 SBOMHUB_API_URL を指定してください")
 	}
 
-	client := api.NewClient(cfg.APIURL, cfg.APIKey)
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Every item in the loop costs an LLM run before its write, so a
+	// read-only key is turned away here rather than at the first 403.
+	if err := requireScope(ctx, client, sbomhub.ScopeTriageWrite, "triage"); err != nil {
+		return err
+	}
+	if err := requireFeature(ctx, client, sbomhub.FeatureTriage, "triage API"); err != nil {
+		return err
+	}
+	projectID, err := resolveProject(ctx, client, triageProject)
+	if err != nil {
+		return err
+	}
 
-	return runTriageLoop(cmd.Context(), client, triageOpts{
-		projectID:           triageProject,
+	return runTriageLoop(ctx, client, triageOpts{
+		projectID:           projectID,
 		ecosystem:           ecosystem,
 		nonInteractive:      triageNonInteractive,
 		confidenceThreshold: triageConfidenceThreshold,
@@ -267,13 +275,13 @@ type editorFunc func(path string) error
 // splitting per-vuln steps into named helpers (already done for
 // interactWith / applyDecision / triageOneVuln) keeps the function
 // length within reason without obscuring the control flow.
-func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) error {
+func runTriageLoop(ctx context.Context, client *sbomhub.Client, opts triageOpts) error {
 	// Step 1: enumerate vulnerabilities for the project.
 	vulns, err := client.ListVulnerabilities(ctx, opts.projectID)
 	if err != nil {
 		// Treat fetching the work list as a permanent setup failure —
 		// no auto-fallback. The operator must fix --project / auth.
-		return &triageExitError{code: 3, msg: fmt.Sprintf("脆弱性一覧の取得に失敗しました: %v", err)}
+		return &exitError{code: apiExitCode(err), err: err, msg: fmt.Sprintf("脆弱性一覧の取得に失敗しました: %v", err)}
 	}
 	if len(vulns) == 0 {
 		fmt.Fprintln(opts.stdout, "脆弱性は検出されませんでした。 何もすることがありません。")
@@ -309,7 +317,7 @@ func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) err
 		fmt.Fprintf(opts.stdout, "\n[%d/%d] %s\n", i+1, len(vulns), formatVulnHeader(v))
 
 		// Step 2: run the AI cycle.
-		runResp, err := client.RunTriage(ctx, opts.projectID, api.TriageRunRequest{
+		runResp, err := client.RunTriage(ctx, opts.projectID, sbomhub.TriageRunRequest{
 			VulnerabilityID: v.ID,
 			CVEID:           v.CVEID,
 		})
@@ -332,7 +340,7 @@ func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) err
 			continue
 		}
 
-		var apiErr *api.TriageError
+		var apiErr *sbomhub.Error
 		if errors.As(err, &apiErr) && apiErr.IsAIDisabled() {
 			// Legacy 503 path — kept for backward compat against servers
 			// that have not yet shipped the F4 fix. New servers return
@@ -374,8 +382,8 @@ func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) err
 
 		if runResp == nil || runResp.Draft == nil {
 			// Defensive belt-and-braces: M1 Codex review #F23 added a
-			// contract guard in api.Client.RunTriage that turns a 2xx
-			// with no draft into a *TriageError (ProtocolError=true),
+			// contract guard in sbomhub.Client.RunTriage that turns a 2xx
+			// with no draft into a *sbomhub.Error (ProtocolError=true),
 			// so this branch should be unreachable. If a future refactor
 			// drops the guard, surface the regression as a transient
 			// protocol failure (exit 4) instead of silently bucketing
@@ -405,7 +413,7 @@ func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) err
 			if quit {
 				fmt.Fprintln(opts.stdout, "ユーザーが終了を選択しました。")
 				printTriageSummary(opts.stdout, len(vulns), i, approved, edited, rejected, skipped, underInvestigation)
-				return &triageExitError{code: 1, msg: "ユーザーが triage を中断しました"}
+				return &exitError{code: exitFailure, msg: "ユーザーが triage を中断しました"}
 			}
 			if act == "" { // skip
 				skipped++
@@ -419,7 +427,7 @@ func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) err
 		}
 
 		// Step 5: persist the decision.
-		if _, err := client.DecideDraft(ctx, opts.projectID, draft.ID, api.DecisionRequest{
+		if _, err := client.DecideDraft(ctx, opts.projectID, draft.ID, sbomhub.DecisionRequest{
 			Decision:            decision,
 			EditedState:         editedState,
 			EditedJustification: editedJustification,
@@ -463,14 +471,14 @@ func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) err
 	// behaviour above so the partial summary is still printed; only
 	// the exit code changes.
 	if permanentFailures > 0 {
-		return &triageExitError{
-			code: 3,
+		return &exitError{
+			code: exitPermanent,
 			msg:  fmt.Sprintf("%d 件の脆弱性が恒久エラーで処理失敗しました (permanent: 401 / 403 / 404 / 422 — fix --api-key / project / role)", permanentFailures),
 		}
 	}
 	if transientFailures > 0 {
-		return &triageExitError{
-			code: 4,
+		return &exitError{
+			code: exitTransient,
 			msg:  fmt.Sprintf("%d 件の脆弱性が一時エラーで処理失敗しました (transient: 429 / 5xx — retry recommended)", transientFailures),
 		}
 	}
@@ -478,11 +486,9 @@ func runTriageLoop(ctx context.Context, client *api.Client, opts triageOpts) err
 }
 
 // classifyTriageFailure buckets a per-vuln API failure into permanent
-// vs transient by consulting the typed *api.TriageError (and the
-// shared *api.APIError on the off chance the future scan-status-style
-// client extends here). Unclassified / network errors fall into the
-// transient bucket because the operator's correct response (retry) is
-// the same.
+// vs transient with the shared exit-code table (apiExitCode in
+// exitcode.go). Unclassified / network errors fall into the transient
+// bucket because the operator's correct response (retry) is the same.
 //
 // M1 Codex review #F21: extracted into a helper so both call sites
 // (RunTriage failure + DecideDraft failure) share the exact same
@@ -494,32 +500,10 @@ func classifyTriageFailure(err error, permanent *int, transient *int) {
 	if err == nil {
 		return
 	}
-	var apiErr *api.TriageError
-	if errors.As(err, &apiErr) {
-		switch {
-		case apiErr.IsAIDisabled():
-			// Should never reach here — the AI-disabled branches above
-			// short-circuit before classifyTriageFailure runs. Treat as
-			// transient defensively so a future regression that drops
-			// the short-circuit does not inflate permanentFailures.
-			*transient++
-		case apiErr.IsPermanent():
-			*permanent++
-		case apiErr.IsTransient():
-			*transient++
-		default:
-			// Unknown 4xx that the helpers do not classify (e.g. a
-			// 418, a future 451) — treat as permanent because the
-			// operator's response is "fix something", not "retry".
-			*permanent++
-		}
+	if apiExitCode(err) == exitPermanent {
+		*permanent++
 		return
 	}
-	// Network failures, JSON parse errors, context.Canceled, etc. —
-	// the operator's correct response is retry-or-investigate, so
-	// they go into the transient bucket. The summary line printed
-	// above still surfaces the underlying error so the operator can
-	// see whether it's worth retrying.
 	*transient++
 }
 
@@ -527,7 +511,7 @@ func classifyTriageFailure(err error, permanent *int, transient *int) {
 // matching the issue's example UX ("CVE-2024-XXXXX (github.com/foo/bar
 // 1.2.3)"). The component portion uses Source as a stand-in until the
 // server projection exposes the linked component name + version.
-func formatVulnHeader(v api.VulnerabilityRecord) string {
+func formatVulnHeader(v sbomhub.VulnerabilityRecord) string {
 	header := v.CVEID
 	if v.Severity != "" {
 		header = fmt.Sprintf("%s [%s]", header, v.Severity)
@@ -541,7 +525,7 @@ func formatVulnHeader(v api.VulnerabilityRecord) string {
 // renderDraft prints the AI suggestion block in the format documented
 // in the issue example. Confidence is rendered as a percentage so a
 // 0.85 reads as "85%" — the audit log persists the raw 0.85 verbatim.
-func renderDraft(w io.Writer, r *api.TriageRunResult) {
+func renderDraft(w io.Writer, r *sbomhub.TriageRunResult) {
 	if r == nil || r.Draft == nil {
 		return
 	}
@@ -590,7 +574,7 @@ func renderDraft(w io.Writer, r *api.TriageRunResult) {
 // formatEvidence renders one evidence pointer as a single line. The
 // shape mirrors the issue example: "github.com/foo/bar/pkg/x.go:42
 // (vulnerable func)".
-func formatEvidence(e api.TriageEvidence) string {
+func formatEvidence(e sbomhub.TriageEvidence) string {
 	var primary string
 	switch {
 	case e.FilePath != "":
@@ -619,7 +603,7 @@ func formatEvidence(e api.TriageEvidence) string {
 // present). Confidence is intentionally NOT reported here — the
 // per-decision confidence is the AI verdict, not a reachability
 // confidence; surfacing both as separate numbers would mislead.
-func summarizeReachability(ev []api.TriageEvidence) string {
+func summarizeReachability(ev []sbomhub.TriageEvidence) string {
 	hasImport := false
 	hasSymbol := false
 	for _, e := range ev {
@@ -649,7 +633,7 @@ func summarizeReachability(ev []api.TriageEvidence) string {
 // Invalid inputs reprompt rather than failing the loop — that matches
 // the operator expectation that mistyping does not abort an
 // hour-long triage session.
-func promptDecision(reader *bufio.Reader, stdout, stderr io.Writer, editor editorFunc, draft *api.VEXDraft) (string, string, string, string, bool) {
+func promptDecision(reader *bufio.Reader, stdout, stderr io.Writer, editor editorFunc, draft *sbomhub.VEXDraft) (string, string, string, string, bool) {
 	for {
 		fmt.Fprint(stdout, "  [a]pprove / [e]dit / [r]eject / [s]kip / [q]uit ? ")
 		line, err := reader.ReadString('\n')
@@ -707,7 +691,7 @@ type editorFile struct {
 // returns the parsed values + ok=true; on any error (editor failure,
 // JSON parse failure, empty file) returns ok=false and the caller
 // reprompts.
-func editVEXContent(stderr io.Writer, editor editorFunc, draft *api.VEXDraft) (string, string, string, bool) {
+func editVEXContent(stderr io.Writer, editor editorFunc, draft *sbomhub.VEXDraft) (string, string, string, bool) {
 	tmp, err := os.CreateTemp("", "sbomhub-triage-*.json")
 	if err != nil {
 		fmt.Fprintf(stderr, "  一時ファイル作成失敗: %v\n", err)
@@ -31,7 +31,7 @@ import (
 	"sync/atomic"
 	"testing"
 
-	"github.com/youichi-uda/sbomhub-cli/internal/api"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 // triageFakeServer is the shared fake — each test seeds different
@@ -40,7 +40,7 @@ import (
 type triageFakeServer struct {
 	t              *testing.T
 	server         *httptest.Server
-	vulns          []api.VulnerabilityRecord
+	vulns          []sbomhub.VulnerabilityRecord
 	runTriageResp  func(call int, body []byte) (status int, payload interface{})
 	decisionResp   func(call int, draftID string, body []byte) (status int, payload interface{})
 	vulnListHits   int32
@@ -58,7 +58,7 @@ type capturedDecision struct {
 	EditedDetail        string
 }
 
-func newTriageFakeServer(t *testing.T, vulns []api.VulnerabilityRecord) *triageFakeServer {
+func newTriageFakeServer(t *testing.T, vulns []sbomhub.VulnerabilityRecord) *triageFakeServer {
 	t.Helper()
 	tf := &triageFakeServer{t: t, vulns: vulns}
 	tf.server = httptest.NewServer(http.HandlerFunc(tf.handle))
@@ -95,7 +95,7 @@ func (tf *triageFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 	case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/vex-drafts/") && strings.HasSuffix(r.URL.Path, "/decision"):
 		n := atomic.AddInt32(&tf.decisionHits, 1)
 		body, _ := io.ReadAll(r.Body)
-		var dec api.DecisionRequest
+		var dec sbomhub.DecisionRequest
 		_ = json.Unmarshal(body, &dec)
 		// Extract draftID from path: /api/v1/projects/<pid>/vex-drafts/<did>/decision
 		parts := strings.Split(r.URL.Path, "/")
@@ -121,7 +121,7 @@ func (tf *triageFakeServer) handle(w http.ResponseWriter, r *http.Request) {
 		if tf.decisionResp != nil {
 			status, payload = tf.decisionResp(int(n), draftID, body)
 		} else {
-			payload = api.VEXDraft{ID: draftID, Decision: dec.Decision}
+			payload = sbomhub.VEXDraft{ID: draftID, Decision: dec.Decision}
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(status)
@@ -187,8 +187,8 @@ func itoa(n int) string {
 	return "9" // not expected in tests
 }
 
-func threeVulns() []api.VulnerabilityRecord {
-	return []api.VulnerabilityRecord{
+func threeVulns() []sbomhub.VulnerabilityRecord {
+	return []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 		{ID: fakeVulnID(2), CVEID: fakeCVE(2), Severity: "MEDIUM"},
 		{ID: fakeVulnID(3), CVEID: fakeCVE(3), Severity: "LOW"},
@@ -200,7 +200,7 @@ func threeVulns() []api.VulnerabilityRecord {
 // loop quit before reaching the third vuln.
 func TestTriageLoop_HappyPath(t *testing.T) {
 	tf := newTriageFakeServer(t, threeVulns())
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	stdin := strings.NewReader("a\nr\nq\n")
 	var stdout, stderr bytes.Buffer
@@ -218,9 +218,9 @@ func TestTriageLoop_HappyPath(t *testing.T) {
 	})
 
 	// q → exit code 1
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *triageExitError", err, err)
+		t.Fatalf("err = %v (%T), want *exitError", err, err)
 	}
 	if exitErr.ExitCode() != 1 {
 		t.Errorf("ExitCode = %d, want 1 (user quit)", exitErr.ExitCode())
@@ -289,7 +289,7 @@ func TestTriageLoop_AIDisabledServerPersisted(t *testing.T) {
 			"ai_disabled": true,
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	// stdin must NOT be consulted — the AI-disabled path skips the
 	// per-vuln prompt because there is no AI verdict to decide on.
@@ -342,7 +342,7 @@ func TestTriageLoop_AIDisabledFallback(t *testing.T) {
 			"reason": "no LLM provider configured (OPENAI_API_KEY/ANTHROPIC_API_KEY unset)",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	// stdin should never be consulted in the AI-disabled fallback —
 	// we still pass a non-empty reader so a regression that DOES read
@@ -390,7 +390,7 @@ func TestTriageLoop_AIDisabledFallback(t *testing.T) {
 // draft.
 func TestTriageLoop_NonInteractive(t *testing.T) {
 	tf := newTriageFakeServer(t, threeVulns())
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	// stdin must NOT be read in non-interactive mode. A regression
 	// that does read would block on the empty reader.
@@ -433,7 +433,7 @@ func TestTriageLoop_NonInteractive(t *testing.T) {
 // vulnerabilities.
 func TestTriageLoop_EmptyVulnList(t *testing.T) {
 	tf := newTriageFakeServer(t, nil)
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -467,7 +467,7 @@ func TestTriageLoop_ListVulnsError(t *testing.T) {
 		_, _ = w.Write([]byte(`{"error":"invalid api key"}`))
 	}))
 	defer server.Close()
-	client := api.NewClient(server.URL, "test-key")
+	client := sbomhub.NewClient(server.URL, "test-key")
 
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -481,9 +481,9 @@ func TestTriageLoop_ListVulnsError(t *testing.T) {
 		stderr:              &stderr,
 		editor:              nil,
 	})
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("err = %v (%T), want *triageExitError", err, err)
+		t.Fatalf("err = %v (%T), want *exitError", err, err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("ExitCode = %d, want 3 (permanent setup error)", exitErr.ExitCode())
@@ -496,12 +496,12 @@ func TestTriageLoop_ListVulnsError(t *testing.T) {
 // regression that drops either field.
 func TestTriageRunRequestShape(t *testing.T) {
 	var seenBody []byte
-	tf := newTriageFakeServer(t, []api.VulnerabilityRecord{{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"}})
+	tf := newTriageFakeServer(t, []sbomhub.VulnerabilityRecord{{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"}})
 	tf.runTriageResp = func(call int, body []byte) (int, interface{}) {
 		seenBody = body
 		return http.StatusCreated, defaultRunResp(call)
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	err := runTriageLoop(context.Background(), client, triageOpts{
 		projectID:           "00000000-0000-0000-0000-000000000aaa",
@@ -518,7 +518,7 @@ func TestTriageRunRequestShape(t *testing.T) {
 		t.Fatalf("runTriageLoop returned %v", err)
 	}
 
-	var got api.TriageRunRequest
+	var got sbomhub.TriageRunRequest
 	if err := json.Unmarshal(seenBody, &got); err != nil {
 		t.Fatalf("body parse error: %v (raw=%s)", err, string(seenBody))
 	}
@@ -543,7 +543,7 @@ func TestTriageEcosystemWarning(t *testing.T) {
 	// (it's purely a CLI-side concept right now), so a regression
 	// that starts wiring ecosystem into TriageRunRequest must extend
 	// this test.
-	req := api.TriageRunRequest{
+	req := sbomhub.TriageRunRequest{
 		VulnerabilityID: fakeVulnID(1),
 		CVEID:           fakeCVE(1),
 	}
@@ -574,7 +574,7 @@ func TestPromptDecision_AllChoices(t *testing.T) {
 		// helper returns approved on the second loop.
 		{"invalid then approve", "x\na\n", decisionApproved, false},
 	}
-	draft := &api.VEXDraft{
+	draft := &sbomhub.VEXDraft{
 		ID:    fakeDraftID(1),
 		State: "not_affected",
 	}
@@ -597,7 +597,7 @@ func TestPromptDecision_AllChoices(t *testing.T) {
 // the temp file with a fresh JSON payload. The test verifies the
 // edited values round-trip into the returned tuple.
 func TestPromptDecision_EditPath(t *testing.T) {
-	draft := &api.VEXDraft{
+	draft := &sbomhub.VEXDraft{
 		ID:            fakeDraftID(1),
 		State:         "not_affected",
 		Justification: "code_not_reachable",
@@ -641,12 +641,12 @@ func TestPromptDecision_EditPath(t *testing.T) {
 func TestFormatVulnHeader(t *testing.T) {
 	cases := []struct {
 		name string
-		in   api.VulnerabilityRecord
+		in   sbomhub.VulnerabilityRecord
 		want string
 	}{
-		{"plain", api.VulnerabilityRecord{CVEID: "CVE-2024-1"}, "CVE-2024-1"},
-		{"with severity", api.VulnerabilityRecord{CVEID: "CVE-2024-2", Severity: "HIGH"}, "CVE-2024-2 [HIGH]"},
-		{"kev", api.VulnerabilityRecord{CVEID: "CVE-2024-3", Severity: "CRITICAL", InKEV: true}, "CVE-2024-3 [CRITICAL] [KEV]"},
+		{"plain", sbomhub.VulnerabilityRecord{CVEID: "CVE-2024-1"}, "CVE-2024-1"},
+		{"with severity", sbomhub.VulnerabilityRecord{CVEID: "CVE-2024-2", Severity: "HIGH"}, "CVE-2024-2 [HIGH]"},
+		{"kev", sbomhub.VulnerabilityRecord{CVEID: "CVE-2024-3", Severity: "CRITICAL", InKEV: true}, "CVE-2024-3 [CRITICAL] [KEV]"},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -661,13 +661,13 @@ func TestFormatVulnHeader(t *testing.T) {
 func TestSummarizeReachability(t *testing.T) {
 	cases := []struct {
 		name string
-		in   []api.TriageEvidence
+		in   []sbomhub.TriageEvidence
 		want string
 	}{
 		{"empty", nil, ""},
-		{"import only", []api.TriageEvidence{{Kind: "import_path"}}, "import_only"},
-		{"symbol", []api.TriageEvidence{{Kind: "import_path"}, {Kind: "symbol_ref"}}, "symbol_ref"},
-		{"advisory only", []api.TriageEvidence{{Kind: "advisory_excerpt"}}, ""},
+		{"import only", []sbomhub.TriageEvidence{{Kind: "import_path"}}, "import_only"},
+		{"symbol", []sbomhub.TriageEvidence{{Kind: "import_path"}, {Kind: "symbol_ref"}}, "symbol_ref"},
+		{"advisory only", []sbomhub.TriageEvidence{{Kind: "advisory_excerpt"}}, ""},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -714,7 +714,7 @@ func TestTriageLoop_PermanentFailures_ExitCode3(t *testing.T) {
 			"error": "forbidden",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -729,9 +729,9 @@ func TestTriageLoop_PermanentFailures_ExitCode3(t *testing.T) {
 		editor:              nil,
 	})
 
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("F21: err = %v (%T), want *triageExitError with exit code 3", err, err)
+		t.Fatalf("F21: err = %v (%T), want *exitError with exit code 3", err, err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F21: ExitCode = %d, want 3 (permanent failures must NOT exit 0)", exitErr.ExitCode())
@@ -770,7 +770,7 @@ func TestTriageLoop_TransientFailures_ExitCode4(t *testing.T) {
 			"error": "upstream timeout",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -785,9 +785,9 @@ func TestTriageLoop_TransientFailures_ExitCode4(t *testing.T) {
 		editor:              nil,
 	})
 
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("F21: err = %v (%T), want *triageExitError with exit code 4", err, err)
+		t.Fatalf("F21: err = %v (%T), want *exitError with exit code 4", err, err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F21: ExitCode = %d, want 4 (transient failures must surface as retry-recommended)", exitErr.ExitCode())
@@ -827,7 +827,7 @@ func TestTriageLoop_AllAIDisabled_ExitCode0(t *testing.T) {
 			"ai_disabled": true,
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -865,7 +865,7 @@ func TestTriageLoop_MixedSuccessAndPermanent_ExitCode3(t *testing.T) {
 		}
 		return http.StatusCreated, defaultRunResp(call)
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -880,9 +880,9 @@ func TestTriageLoop_MixedSuccessAndPermanent_ExitCode3(t *testing.T) {
 		editor:              nil,
 	})
 
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("F21: err = %v (%T), want *triageExitError (permanent must win over partial success)", err, err)
+		t.Fatalf("F21: err = %v (%T), want *exitError (permanent must win over partial success)", err, err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F21: ExitCode = %d, want 3 (one permanent failure in a mixed run must surface)", exitErr.ExitCode())
@@ -900,7 +900,7 @@ func TestTriageLoop_MixedSuccessAndPermanent_ExitCode3(t *testing.T) {
 // that updated the RunTriage classifier without the DecideDraft one
 // would mask 403 on the decision PUT path.
 func TestTriageLoop_DecideDraftPermanent_ExitCode3(t *testing.T) {
-	tf := newTriageFakeServer(t, []api.VulnerabilityRecord{
+	tf := newTriageFakeServer(t, []sbomhub.VulnerabilityRecord{
 		{ID: fakeVulnID(1), CVEID: fakeCVE(1), Severity: "HIGH"},
 	})
 	tf.decisionResp = func(call int, draftID string, body []byte) (int, interface{}) {
@@ -908,7 +908,7 @@ func TestTriageLoop_DecideDraftPermanent_ExitCode3(t *testing.T) {
 			"error": "forbidden",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -923,9 +923,9 @@ func TestTriageLoop_DecideDraftPermanent_ExitCode3(t *testing.T) {
 		editor:              nil,
 	})
 
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("F21: err = %v (%T), want *triageExitError (decide 403 must surface)", err, err)
+		t.Fatalf("F21: err = %v (%T), want *exitError (decide 403 must surface)", err, err)
 	}
 	if exitErr.ExitCode() != 3 {
 		t.Errorf("F21: ExitCode = %d, want 3 (DecideDraft permanent failure)", exitErr.ExitCode())
@@ -934,7 +934,7 @@ func TestTriageLoop_DecideDraftPermanent_ExitCode3(t *testing.T) {
 
 // TestClassifyTriageFailure_F21 unit-tests the helper directly so the
 // per-status classification stays pinned even if the call sites get
-// refactored. The matrix mirrors api.TriageError.IsPermanent /
+// refactored. The matrix mirrors sbomhub.Error.IsPermanent /
 // IsTransient + the network-error default.
 func TestClassifyTriageFailure_F21(t *testing.T) {
 	cases := []struct {
@@ -945,56 +945,56 @@ func TestClassifyTriageFailure_F21(t *testing.T) {
 	}{
 		{
 			name: "401 unauthorized → permanent",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusUnauthorized,
 			},
 			wantPermInc: 1,
 		},
 		{
 			name: "403 forbidden → permanent",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusForbidden,
 			},
 			wantPermInc: 1,
 		},
 		{
 			name: "404 not found → permanent",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusNotFound,
 			},
 			wantPermInc: 1,
 		},
 		{
 			name: "422 unprocessable → permanent",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusUnprocessableEntity,
 			},
 			wantPermInc: 1,
 		},
 		{
 			name: "429 too many → transient",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusTooManyRequests,
 			},
 			wantTranInc: 1,
 		},
 		{
 			name: "500 server → transient",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusInternalServerError,
 			},
 			wantTranInc: 1,
 		},
 		{
 			name: "502 bad gateway → transient",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusBadGateway,
 			},
 			wantTranInc: 1,
 		},
 		{
 			name: "503 AI disabled → transient (defensive — caller short-circuits)",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: http.StatusServiceUnavailable,
 				Reason:     "no LLM provider configured",
 			},
@@ -1002,7 +1002,7 @@ func TestClassifyTriageFailure_F21(t *testing.T) {
 		},
 		{
 			name: "418 unknown 4xx → permanent (operator must fix)",
-			err: &api.TriageError{
+			err: &sbomhub.Error{
 				StatusCode: 418,
 			},
 			wantPermInc: 1,
@@ -1051,7 +1051,7 @@ func TestTriageLoop_503GatewayError_ExitCode4_F22(t *testing.T) {
 			"error": "Service Unavailable",
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	// stdin must NOT be consulted — we are not prompting on failures.
 	stdin := strings.NewReader("")
@@ -1067,9 +1067,9 @@ func TestTriageLoop_503GatewayError_ExitCode4_F22(t *testing.T) {
 		stderr:              &stderr,
 		editor:              nil,
 	})
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("F22: err = %v (%T), want *triageExitError (generic 503 must surface, not silently exit 0)", err, err)
+		t.Fatalf("F22: err = %v (%T), want *exitError (generic 503 must surface, not silently exit 0)", err, err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F22: ExitCode = %d, want 4 (generic 503 = transient outage, not AI-disabled)", exitErr.ExitCode())
@@ -1101,7 +1101,7 @@ func TestTriageLoop_AllMalformed2xx_ExitCode4_F23(t *testing.T) {
 			"threshold": 0.7,
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 
 	stdin := strings.NewReader("")
 	var stdout, stderr bytes.Buffer
@@ -1116,9 +1116,9 @@ func TestTriageLoop_AllMalformed2xx_ExitCode4_F23(t *testing.T) {
 		stderr:              &stderr,
 		editor:              nil,
 	})
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("F23: err = %v (%T), want *triageExitError (malformed 2xx must surface)", err, err)
+		t.Fatalf("F23: err = %v (%T), want *exitError (malformed 2xx must surface)", err, err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F23: ExitCode = %d, want 4 (malformed 2xx = server protocol error, retry recommended)", exitErr.ExitCode())
@@ -1142,7 +1142,7 @@ func TestTriageLoop_2xxWithErrorField_ExitCode4_F23(t *testing.T) {
 			"threshold": 0.7,
 		}
 	}
-	client := api.NewClient(tf.server.URL, "test-key")
+	client := sbomhub.NewClient(tf.server.URL, "test-key")
 	stdin := strings.NewReader("")
 	var stdout, stderr bytes.Buffer
 	err := runTriageLoop(context.Background(), client, triageOpts{
@@ -1156,9 +1156,9 @@ func TestTriageLoop_2xxWithErrorField_ExitCode4_F23(t *testing.T) {
 		stderr:              &stderr,
 		editor:              nil,
 	})
-	exitErr, ok := err.(*triageExitError)
+	exitErr, ok := err.(*exitError)
 	if !ok {
-		t.Fatalf("F23: err = %v (%T), want *triageExitError", err, err)
+		t.Fatalf("F23: err = %v (%T), want *exitError", err, err)
 	}
 	if exitErr.ExitCode() != 4 {
 		t.Errorf("F23: ExitCode = %d, want 4 (2xx + error field = protocol violation, transient)", exitErr.ExitCode())
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Helpers for streaming a generated SBOM from disk: the --max-sbom-size
+// guard, verbose upload progress, and a component count that does not
+// load the whole document.
+
+// byteUnits are the suffixes parseByteSize accepts: decimal KB/MB/GB as
+// printed by most CI dashboards, binary KiB/MiB/GiB for those who mean it.
+var byteUnits = []struct {
+	suffix string
+	n      float64
+}{
+	{"kib", 1 << 10},
+	{"mib", 1 << 20},
+	{"gib", 1 << 30},
+	{"kb", 1e3},
+	{"mb", 1e6},
+	{"gb", 1e9},
+	{"b", 1},
+}
+
+// parseByteSize parses sizes like "500MB", "1.5GiB" or "1048576". An
+// empty string or "0" means no limit and returns 0.
+func parseByteSize(s string) (int64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	if v == "" {
+		return 0, nil
+	}
+	mult := 1.0
+	for _, u := range byteUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, mult = strings.TrimSpace(strings.TrimSuffix(v, u.suffix)), u.n
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("サイズの形式が不正です: %q (例: 500MB, 1GiB, 1048576)", s)
+	}
+	return int64(n * mult), nil
+}
+
+// formatBytes renders n with a decimal unit for messages.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1f GB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1f MB", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1f KB", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// uploadProgressChunk is the reporting step when the SBOM size is unknown.
+const uploadProgressChunk = 10 << 20
+
+// uploadProgress returns an api.UploadOptions.Progress callback that
+// prints a [DEBUG] line per 10% of the SBOM sent, or nil outside
+// --verbose. A retried attempt restarts from zero and is reported again.
+func uploadProgress(out *OutputConfig) func(sent, total int64) {
+	if !out.Verbose {
+		return nil
+	}
+	var (
+		mu   sync.Mutex
+		last int64 = -1
+	)
+	return func(sent, total int64) {
+		var bucket int64
+		if total > 0 {
+			bucket = sent * 10 / total
+		} else {
+			bucket = sent / uploadProgressChunk
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if bucket < last {
+			last = -1
+		}
+		if bucket == last {
+			return
+		}
+		last = bucket
+		if total > 0 {
+			out.PrintVerbose("アップロード中: %s / %s (%d%%)", formatBytes(sent), formatBytes(total), sent*100/total)
+			return
+		}
+		out.PrintVerbose("アップロード中: %s", formatBytes(sent))
+	}
+}
+
+// countComponentsFile counts CycloneDX components[] (or SPDX packages[])
+// in the SBOM at path, token by token so a large SBOM is never decoded
+// into memory. Any read or parse error yields 0, as countComponents did
+// for the in-memory SBOM.
+func countComponentsFile(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	return countComponentsStream(f)
+}
+
+func countComponentsStream(r io.Reader) int {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return 0
+	}
+	components, packages := -1, -1
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return 0
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return 0
+		}
+		switch key, _ := keyTok.(string); {
+		case key == "components" && tok == json.Delim('['):
+			if components, err = countArray(dec); err != nil {
+				return 0
+			}
+		case key == "packages" && tok == json.Delim('['):
+			if packages, err = countArray(dec); err != nil {
+				return 0
+			}
+		default:
+			if err := skipValue(dec, tok); err != nil {
+				return 0
+			}
+		}
+	}
+	switch {
+	case components >= 0:
+		return components
+	case packages >= 0:
+		return packages
+	}
+	return 0
+}
+
+// countArray counts the elements of an array whose '[' was just read
+// and consumes its ']'.
+func countArray(dec *json.Decoder) (int, error) {
+	n := 0
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, err
+		}
+		if err := skipValue(dec, tok); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	_, err := dec.Token()
+	return n, err
+}
+
+// skipValue consumes the rest of a value whose first token is tok.
+func skipValue(dec *json.Decoder, tok json.Token) error {
+	depth := 0
+	for {
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+		var err error
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"1048576", 1 << 20, false},
+		{"500MB", 500_000_000, false},
+		{"500 mb", 500_000_000, false},
+		{"1.5GiB", 3 << 29, false},
+		{"64KiB", 64 << 10, false},
+		{"10B", 10, false},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, tc := range cases {
+		got, err := parseByteSize(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d, err=%v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestCountComponentsStream(t *testing.T) {
+	cases := []struct {
+		name string
+		sbom string
+		want int
+	}{
+		{"cyclonedx", `{"bomFormat":"CycloneDX","metadata":{"tools":[{"name":"syft"}]},"components":[{"name":"a","hashes":[{"alg":"SHA-1"}]},{"name":"b"}]}`, 2},
+		{"spdx", `{"spdxVersion":"SPDX-2.3","packages":[{"name":"a"},{"name":"b"},{"name":"c"}]}`, 3},
+		{"components win", `{"packages":[{}],"components":[{},{}]}`, 2},
+		{"no components", `{"bomFormat":"CycloneDX"}`, 0},
+		{"components not an array", `{"components":{"name":"a"}}`, 0},
+		{"truncated", `{"components":[{"name":"a"},{"na`, 0},
+		{"not json", `trivy says no`, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := countComponentsStream(strings.NewReader(tc.sbom)); got != tc.want {
+				t.Errorf("countComponentsStream() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUploadProgress(t *testing.T) {
+	var buf bytes.Buffer
+	out := &OutputConfig{Verbose: true, Writer: &buf, ErrWriter: io.Discard}
+	if uploadProgress(&OutputConfig{}) != nil {
+		t.Error("uploadProgress() outside --verbose should be nil")
+	}
+	report := uploadProgress(out)
+	for sent := int64(0); sent <= 1000; sent += 50 {
+		report(sent, 1000)
+	}
+	// A retry starts over and is reported again.
+	report(50, 1000)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("got %d progress lines, want one per 10%% plus the restart:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[10], "(100%)") || !strings.Contains(lines[11], "(5%)") {
+		t.Errorf("unexpected progress lines:\n%s", buf.String())
+	}
+}
+
+// fakeSyft puts a `syft` on PATH that writes sbom to the file named by
+// `-o <format>=<file>`, the way scan invokes the real one.
+func fakeSyft(t *testing.T, sbom string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake scanner is a shell script")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+if [ "$1" = "version" ]; then echo "syft 0.0.0-test"; exit 0; fi
+while [ $# -gt 0 ]; do
+  case "$1" in -o) out="${2#*=}"; shift;; esac
+  shift
+done
+cat "$FAKE_SBOM_FILE" > "$out"
+`
+	if err := os.WriteFile(filepath.Join(dir, "syft"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sbomFile := filepath.Join(dir, "sbom.json")
+	if err := os.WriteFile(sbomFile, []byte(sbom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_SBOM_FILE", sbomFile)
+}
+
+// setupStreamingScan points runScan at serverURL with the fake scanner,
+// no cache and no polling, and captures --verbose output.
+func setupStreamingScan(t *testing.T, serverURL string) *bytes.Buffer {
+	t.Helper()
+	withCleanCredentialEnv(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", "")
+	saved := struct {
+		project, tool, maxSize, url, key string
+		wait, dry, noCache               bool
+		out                              OutputConfig
+	}{scanProject, scanTool, scanMaxSBOMSize, apiURL, apiKey, scanWaitForScan, scanDryRun, noCacheFlag, *globalOutput}
+	t.Cleanup(func() {
+		scanProject, scanTool, scanMaxSBOMSize, apiURL, apiKey = saved.project, saved.tool, saved.maxSize, saved.url, saved.key
+		scanWaitForScan, scanDryRun, noCacheFlag = saved.wait, saved.dry, saved.noCache
+		*globalOutput = saved.out
+	})
+	scanProject = "01234567-0123-0123-0123-0123456789ab"
+	scanTool = "syft"
+	scanMaxSBOMSize = ""
+	scanWaitForScan = false
+	scanDryRun = false
+	noCacheFlag = true
+	apiURL, apiKey = serverURL, "sbh_test"
+	var buf bytes.Buffer
+	globalOutput.Quiet, globalOutput.Verbose, globalOutput.JSON = true, true, false
+	globalOutput.Writer, globalOutput.ErrWriter = &buf, io.Discard
+	return &buf
+}
+
+func TestRunScan_StreamsGzipUpload(t *testing.T) {
+	sbom := `{"bomFormat":"CycloneDX","components":[` + strings.Repeat(`{"name":"pkg","version":"1.0.0"},`, 2000) + `{"name":"last"}]}`
+	fakeSyft(t, sbom)
+
+	var got []byte
+	var encoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "not gzip", http.StatusBadRequest)
+			return
+		}
+		got, _ = io.ReadAll(zr)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"01234567-0123-0123-0123-0123456789ab"}`))
+	}))
+	defer server.Close()
+	verbose := setupStreamingScan(t, server.URL)
+
+	if err := runScan(scanCmd, []string{t.TempDir()}); err != nil {
+		t.Fatalf("runScan() = %v", err)
+	}
+	if encoding != "gzip" || string(got) != sbom {
+		t.Errorf("server got Content-Encoding %q and %d bytes, want gzip and the %d-byte SBOM", encoding, len(got), len(sbom))
+	}
+	for _, want := range []string{"(100%)", "gzip 圧縮で送信しました"} {
+		if !strings.Contains(verbose.String(), want) {
+			t.Errorf("verbose output missing %q:\n%s", want, verbose.String())
+		}
+	}
+}
+
+func TestRunScan_MaxSBOMSizeFailsBeforeUpload(t *testing.T) {
+	fakeSyft(t, `{"bomFormat":"CycloneDX","components":[{"name":"a"},{"name":"b"}]}`)
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+	setupStreamingScan(t, server.URL)
+	scanMaxSBOMSize = "10B"
+
+	err := runScan(scanCmd, []string{t.TempDir()})
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitFailure {
+		t.Fatalf("runScan() = %v, want exit %d", err, exitFailure)
+	}
+	if !strings.Contains(err.Error(), "--max-sbom-size") {
+		t.Errorf("error %q should name the flag", err)
+	}
+	if called {
+		t.Error("the server was contacted although the SBOM exceeded --max-sbom-size")
+	}
+
+	scanMaxSBOMSize = "ten"
+	if err := runScan(scanCmd, []string{t.TempDir()}); err == nil || !strings.Contains(err.Error(), "--max-sbom-size") {
+		t.Errorf("runScan() with a malformed size = %v, want a --max-sbom-size error", err)
+	}
+}
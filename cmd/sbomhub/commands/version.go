@@ -1,21 +1,116 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
 )
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "バージョン情報を表示",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("sbomhub version %s\n", version)
-		fmt.Printf("  commit: %s\n", commit)
-		fmt.Printf("  built:  %s\n", date)
-	},
+	Long: `CLI のバージョン情報を表示します。
+
+API URL が設定されていれば (--api-url / SBOMHUB_API_URL / config.yaml)、
+サーバのバージョンと capabilities も取得し、 CLI との互換性
+(サーバが要求する最小 CLI バージョン、 サーバが古くて使えないコマンド) を
+表示します。 サーバに到達できなくても exit 0 です。`,
+	RunE: runVersion,
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
 }
+
+// versionServerJSON is the "server" object of `version --json`.
+type versionServerJSON struct {
+	APIURL        string   `json:"api_url"`
+	Version       string   `json:"version,omitempty"`
+	MinCLIVersion string   `json:"min_cli_version,omitempty"`
+	Features      []string `json:"features,omitempty"`
+	Published     bool     `json:"capabilities_published"`
+	Error         string   `json:"error,omitempty"`
+	serverCompat
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	out := GetOutputConfig()
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	server := versionServer(ctx)
+	if out.IsJSON() {
+		payload := map[string]interface{}{
+			"version": version,
+			"commit":  commit,
+			"built":   date,
+			"server":  server,
+		}
+		return out.PrintJSON(payload)
+	}
+
+	w := out.humanWriter()
+	fmt.Fprintf(w, "sbomhub version %s\n", version)
+	fmt.Fprintf(w, "  commit: %s\n", commit)
+	fmt.Fprintf(w, "  built:  %s\n", date)
+	if server == nil {
+		fmt.Fprintln(w, "  server: 未設定 (--api-url / SBOMHUB_API_URL / 'sbomhub login' で設定)")
+		return nil
+	}
+	fmt.Fprintf(w, "  server: %s\n", server.APIURL)
+	switch {
+	case server.Error != "":
+		fmt.Fprintf(w, "    ⚠️  サーバ情報を取得できません: %s\n", server.Error)
+	case !server.Published:
+		fmt.Fprintln(w, "    version: N/A (サーバは capabilities を公開していません — 旧バージョン)")
+	default:
+		fmt.Fprintf(w, "    version:         %s\n", orNA(server.Version))
+		fmt.Fprintf(w, "    min CLI version: %s\n", orNA(server.MinCLIVersion))
+		fmt.Fprintf(w, "    features:        %s\n", orNA(strings.Join(server.Features, ", ")))
+		if server.CLITooOld {
+			fmt.Fprintf(w, "    ⚠️  この CLI (%s) はサーバの要求する最小バージョン %s より古いです。 CLI を更新してください\n", version, server.MinCLIVersion)
+		}
+		if len(server.Unsupported) > 0 {
+			fmt.Fprintf(w, "    ⚠️  サーバが古いため使えないコマンド: %s\n", strings.Join(server.Unsupported, ", "))
+		}
+		if !server.CLITooOld && len(server.Unsupported) == 0 {
+			fmt.Fprintln(w, "    ✓ CLI とサーバは互換です")
+		}
+	}
+	return nil
+}
+
+// versionServer fetches the configured server's capabilities. It returns
+// nil when no API URL is configured: the built-in default points at the
+// sunset SaaS instance, and `version` must not phone home just because
+// nothing was set. Failures are reported in Error, never returned —
+// `version` has to work offline.
+func versionServer(ctx context.Context) *versionServerJSON {
+	cfg, err := resolveCredentials(getConfigDir())
+	if err != nil || cfg.APIURL == "" || cfg.APIURL == config.DefaultAPIURL {
+		return nil
+	}
+	server := &versionServerJSON{APIURL: cfg.APIURL, serverCompat: serverCompat{Unsupported: []string{}}}
+	client, err := buildAPIClient(cfg, sbomhub.WithRetryPolicy(sbomhub.NoRetry))
+	if err != nil {
+		server.Error = err.Error()
+		return server
+	}
+	caps, err := fetchCapabilities(ctx, client)
+	if err != nil {
+		server.Error = err.Error()
+		return server
+	}
+	server.Version = caps.Version
+	server.MinCLIVersion = caps.MinCLIVersion
+	server.Features = caps.Features
+	server.Published = caps.Published
+	server.serverCompat = assessCompat(caps, version)
+	return server
+}
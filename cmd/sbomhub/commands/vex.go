@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/suppress"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// VEX-aware gating for `scan` / `check`. Once a CVE has been triaged and
+// the draft approved as not_affected / resolved, the project has made a
+// reviewed statement that the finding is not a live risk; a --fail-on /
+// --policy gate that keeps failing on it teaches teams to ignore the
+// gate. Approved decisions are therefore removed from the gated finding
+// set BEFORE `.sbomhubignore` is applied, and listed separately under
+// `vex` (with who approved them) so nothing disappears silently.
+//
+// Matching is by CVE ID (or alias). The CLI triages per vulnerability —
+// the server picks or fans out to the affected components — so the
+// decision is effectively project-wide. To keep that from widening a
+// partial statement, a CVE with approved drafts in BOTH a covered state
+// and another state (affected / under_investigation) is not suppressed
+// and is reported as conflicting instead.
+//
+// ※要確認: VEXDraft carries component_id but neither the scan rows nor
+// the check result carry a server component ID, so per-component
+// matching is not possible yet. Switch to (component, vuln) matching once
+// either projection exposes it.
+
+// vexCoveredStates are the CycloneDX VEX states that take a finding out
+// of the gate.
+var vexCoveredStates = map[string]bool{
+	"not_affected": true,
+	"resolved":     true,
+}
+
+// vexIndex maps upper-cased CVE IDs to the approved draft that covers
+// them. conflicting lists CVEs whose approved drafts disagree.
+type vexIndex struct {
+	covered     map[string]sbomhub.VEXDraft
+	conflicting []string
+	drafts      int
+}
+
+// vexSuppressed pairs a finding with the approved draft that removed it.
+type vexSuppressed struct {
+	Finding suppress.Finding
+	Draft   sbomhub.VEXDraft
+}
+
+// vexJSON is the `vex` object in `scan --json` / `check --json`. Omitted
+// when VEX decisions were not consulted. Slices are always non-nil.
+type vexJSON struct {
+	ApprovedDrafts int                 `json:"approved_drafts"`
+	Suppressed     []vexSuppressedJSON `json:"suppressed"`
+	Conflicting    []string            `json:"conflicting"`
+}
+
+type vexSuppressedJSON struct {
+	ID            string `json:"id"`
+	Package       string `json:"package,omitempty"`
+	Version       string `json:"version,omitempty"`
+	Severity      string `json:"severity"`
+	State         string `json:"state"`
+	Justification string `json:"justification,omitempty"`
+	DraftID       string `json:"draft_id"`
+	DecidedBy     string `json:"decided_by,omitempty"`
+	DecidedAt     string `json:"decided_at,omitempty"`
+}
+
+// loadVEXIndex fetches the project's approved drafts and indexes them.
+// Errors are returned to the caller, which warns and gates on the
+// unsuppressed findings (fail closed). A server whose capabilities lack
+// the VEX API is reported the same way, without the request.
+func loadVEXIndex(ctx context.Context, client *sbomhub.Client, caps *sbomhub.Capabilities, projectID string) (*vexIndex, error) {
+	if err := featureUnsupported(caps, sbomhub.FeatureVEX, "VEX 判定 API"); err != nil {
+		return nil, err
+	}
+	drafts, err := client.ListAllVEXDrafts(ctx, projectID, sbomhub.VEXDraftListFilter{Decision: "approved"})
+	if err != nil {
+		return nil, err
+	}
+	return buildVEXIndex(drafts), nil
+}
+
+func buildVEXIndex(drafts []sbomhub.VEXDraft) *vexIndex {
+	idx := &vexIndex{covered: map[string]sbomhub.VEXDraft{}}
+	uncovered := map[string]bool{}
+	for _, d := range drafts {
+		// The list is filtered server-side; re-check so a server that
+		// ignores ?decision= cannot turn pending drafts into suppressions.
+		if d.Decision != "approved" || d.CVEID == "" {
+			continue
+		}
+		idx.drafts++
+		key := strings.ToUpper(d.CVEID)
+		if !vexCoveredStates[strings.ToLower(d.State)] {
+			uncovered[key] = true
+			continue
+		}
+		if _, ok := idx.covered[key]; !ok {
+			idx.covered[key] = d
+		}
+	}
+	for key := range uncovered {
+		if d, ok := idx.covered[key]; ok {
+			idx.conflicting = append(idx.conflicting, d.CVEID)
+			delete(idx.covered, key)
+		}
+	}
+	sort.Strings(idx.conflicting)
+	return idx
+}
+
+// lookup returns the covering draft for f, matching its ID or aliases.
+func (idx *vexIndex) lookup(f suppress.Finding) (sbomhub.VEXDraft, bool) {
+	if d, ok := idx.covered[strings.ToUpper(f.ID)]; ok {
+		return d, true
+	}
+	for _, a := range f.Aliases {
+		if d, ok := idx.covered[strings.ToUpper(a)]; ok {
+			return d, true
+		}
+	}
+	return sbomhub.VEXDraft{}, false
+}
+
+// applyVEX partitions findings into those still gated and those covered
+// by an approved decision. A nil index keeps everything.
+func applyVEX(findings []suppress.Finding, idx *vexIndex) (kept []suppress.Finding, hits []vexSuppressed) {
+	if idx == nil {
+		return findings, nil
+	}
+	kept = make([]suppress.Finding, 0, len(findings))
+	for _, f := range findings {
+		if d, ok := idx.lookup(f); ok {
+			hits = append(hits, vexSuppressed{Finding: f, Draft: d})
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, hits
+}
+
+func vexFindings(hits []vexSuppressed) []suppress.Finding {
+	out := make([]suppress.Finding, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.Finding)
+	}
+	return out
+}
+
+func buildVEXJSON(idx *vexIndex, hits []vexSuppressed) *vexJSON {
+	if idx == nil {
+		return nil
+	}
+	j := &vexJSON{
+		ApprovedDrafts: idx.drafts,
+		Suppressed:     []vexSuppressedJSON{},
+		Conflicting:    append([]string{}, idx.conflicting...),
+	}
+	for _, h := range hits {
+		j.Suppressed = append(j.Suppressed, vexSuppressedJSON{
+			ID:            h.Finding.ID,
+			Package:       h.Finding.Package,
+			Version:       h.Finding.Version,
+			Severity:      h.Finding.Severity,
+			State:         h.Draft.State,
+			Justification: h.Draft.Justification,
+			DraftID:       h.Draft.ID,
+			DecidedBy:     h.Draft.DecidedBy,
+			DecidedAt:     h.Draft.DecidedAt,
+		})
+	}
+	return j
+}
+
+// printVEXReport lists the VEX-suppressed findings with their approver,
+// plus a warning per conflicting CVE. Written to w (stderr in practice).
+func printVEXReport(w io.Writer, idx *vexIndex, hits []vexSuppressed) {
+	if idx == nil {
+		return
+	}
+	if len(hits) > 0 {
+		fmt.Fprintf(w, "🛡️  承認済み VEX 判定により %d 件の脆弱性を閾値評価から除外しました:\n", len(hits))
+		for _, h := range hits {
+			target := h.Finding.ID
+			if h.Finding.Package != "" {
+				target += " (" + h.Finding.Package + "@" + h.Finding.Version + ")"
+			}
+			by := h.Draft.DecidedBy
+			if by == "" {
+				by = "不明"
+			}
+			line := fmt.Sprintf("   - %s: %s", target, h.Draft.State)
+			if h.Draft.Justification != "" {
+				line += " / " + h.Draft.Justification
+			}
+			line += " [approved by " + by
+			if h.Draft.DecidedAt != "" {
+				line += ", " + h.Draft.DecidedAt
+			}
+			fmt.Fprintln(w, line+"]")
+		}
+	}
+	for _, id := range idx.conflicting {
+		fmt.Fprintf(w, "⚠️  %s には not_affected/resolved 以外の承認済み VEX 判定もあるため、 除外していません\n", id)
+	}
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/suppress"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// TestBuildVEXIndex_ConflictsAndPending pins the matching rules: only
+// approved drafts count, resolved covers like not_affected, and a CVE
+// with an approved draft in another state is reported as conflicting
+// instead of being suppressed.
+func TestBuildVEXIndex_ConflictsAndPending(t *testing.T) {
+	idx := buildVEXIndex([]sbomhub.VEXDraft{
+		{ID: "a", CVEID: "CVE-1", State: "not_affected", Decision: "approved"},
+		{ID: "b", CVEID: "cve-2", State: "resolved", Decision: "approved"},
+		{ID: "c", CVEID: "CVE-3", State: "not_affected", Decision: "approved"},
+		{ID: "d", CVEID: "CVE-3", State: "affected", Decision: "approved"},
+		{ID: "e", CVEID: "CVE-4", State: "not_affected", Decision: "pending"},
+	})
+
+	findings := []suppress.Finding{
+		{ID: "CVE-1", Severity: "HIGH"},
+		{ID: "GHSA-xxxx", Aliases: []string{"CVE-2"}, Severity: "LOW"},
+		{ID: "CVE-3", Severity: "CRITICAL"},
+		{ID: "CVE-4", Severity: "HIGH"},
+	}
+	kept, hits := applyVEX(findings, idx)
+
+	if len(hits) != 2 || hits[0].Draft.ID != "a" || hits[1].Draft.ID != "b" {
+		t.Errorf("hits = %+v, want drafts a and b", hits)
+	}
+	if len(kept) != 2 || kept[0].ID != "CVE-3" || kept[1].ID != "CVE-4" {
+		t.Errorf("kept = %+v, want CVE-3 (conflicting) and CVE-4 (pending)", kept)
+	}
+	if len(idx.conflicting) != 1 || idx.conflicting[0] != "CVE-3" {
+		t.Errorf("conflicting = %v, want [CVE-3]", idx.conflicting)
+	}
+	if idx.drafts != 4 {
+		t.Errorf("drafts = %d, want 4 approved", idx.drafts)
+	}
+}
+
+// TestApplyVEX_NilIndexKeepsEverything covers the fail-closed path used
+// when the drafts could not be fetched.
+func TestApplyVEX_NilIndexKeepsEverything(t *testing.T) {
+	in := []suppress.Finding{{ID: "CVE-1"}}
+	kept, hits := applyVEX(in, nil)
+	if len(kept) != 1 || hits != nil {
+		t.Errorf("applyVEX(nil) = %v, %v; want input unchanged", kept, hits)
+	}
+	if buildVEXJSON(nil, nil) != nil {
+		t.Error("buildVEXJSON(nil) should be nil so the `vex` key is omitted")
+	}
+}
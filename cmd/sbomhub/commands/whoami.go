@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/youichi-uda/sbomhub-cli/internal/config"
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "使用中の認証情報 (テナント・ ロール・ 権限) を表示",
+	Long: `現在の認証情報でサーバに問い合わせ、 テナント、 ユーザー、 API Key 名、
+ロール / スコープ、 有効期限を表示します。
+
+triage / cra approve / meti override は、 LLM 呼び出しや書き込みの前に
+同じ情報で書き込み権限を確認し、 権限が無ければ開始前に終了します
+(exit 3)。 サーバが capabilities で whoami を宣言していない場合は
+確認を省略します。
+
+使用例:
+  sbomhub whoami
+  sbomhub --profile staging whoami --json`,
+	Args: cobra.NoArgs,
+	RunE: runWhoami,
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
+
+// whoamiJSON is the `whoami --json` document: the server's view of the
+// credential plus where the CLI sent it.
+type whoamiJSON struct {
+	APIURL   string            `json:"api_url"`
+	Profile  string            `json:"profile"`
+	Identity *sbomhub.Identity `json:"identity"`
+}
+
+func runWhoami(cmd *cobra.Command, args []string) error {
+	cfg, err := resolveCredentials(getConfigDir())
+	if err != nil {
+		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
+	}
+	if !hasCredentials(cfg) {
+		return fmt.Errorf("API Keyが設定されていません。 'sbomhub login' で対話設定するか、 --api-key フラグ・ 環境変数 SBOMHUB_API_KEY を指定してください")
+	}
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// An undeclared endpoint is reported like a 404 from it, without
+	// sending the credential to a path the server never published.
+	id := &sbomhub.Identity{}
+	if serverCapabilities(ctx, client, cfg.APIURL).Declares(sbomhub.FeatureWhoami) {
+		if id, err = client.WhoAmI(ctx); err != nil {
+			return apiFailure("whoami", err)
+		}
+	}
+
+	profile, _ := activeProfile()
+	if profile == "" {
+		profile = resolvedProfileName()
+	}
+	out := GetOutputConfig()
+	return out.PrintResult(whoamiJSON{APIURL: cfg.APIURL, Profile: profile, Identity: id}, func() {
+		out.Print("API URL     : %s\n", cfg.APIURL)
+		out.Print("プロファイル: %s\n", profile)
+		if !id.Published {
+			out.Println("")
+			printInfo("サーバが whoami に対応していない (capabilities で未宣言) ため、 認証情報の詳細は取得できません")
+			return
+		}
+		tenant := id.TenantID
+		if id.TenantName != "" {
+			tenant = fmt.Sprintf("%s (%s)", id.TenantName, id.TenantID)
+		}
+		user := id.UserEmail
+		if user == "" {
+			user = id.UserID
+		}
+		key := id.KeyName
+		if id.KeyID != "" {
+			key = strings.TrimSpace(fmt.Sprintf("%s (%s)", id.KeyName, id.KeyID))
+		}
+		out.Print("テナント    : %s\n", orDash(tenant))
+		out.Print("ユーザー    : %s\n", orDash(user))
+		out.Print("API Key     : %s\n", orDash(key))
+		out.Print("ロール      : %s\n", orDash(id.Role))
+		out.Print("スコープ    : %s\n", orDash(strings.Join(id.Scopes, ", ")))
+		out.Print("有効期限    : %s\n", describeExpiry(id.ExpiresAt, time.Now()))
+		out.Println("")
+		out.Println("書き込み権限:")
+		for _, w := range writeScopes {
+			out.Print("  %-24s %s\n", w.command, describeCan(id, w.scope))
+		}
+	})
+}
+
+// writeScopes are the permission checks the write commands pre-flight,
+// listed by whoami.
+var writeScopes = []struct {
+	command string
+	scope   string
+}{
+	{"triage", sbomhub.ScopeTriageWrite},
+	{"cra approve", sbomhub.ScopeCRAWrite},
+	{"meti override", sbomhub.ScopeMETIWrite},
+}
+
+// describeCan renders Identity.Can for the whoami listing.
+func describeCan(id *sbomhub.Identity, scope string) string {
+	allowed, known := id.Can(scope)
+	switch {
+	case !known:
+		return "不明 (" + scope + ")"
+	case allowed:
+		return "可 (" + scope + ")"
+	}
+	return "不可 (" + scope + ")"
+}
+
+// describeExpiry renders an expiry with the time left, or "なし".
+func describeExpiry(at *time.Time, now time.Time) string {
+	if at == nil {
+		return "なし"
+	}
+	s := at.Local().Format(time.RFC3339)
+	if left := at.Sub(now); left > 0 {
+		return fmt.Sprintf("%s (残り %s)", s, left.Round(time.Minute))
+	}
+	return s + " (期限切れ)"
+}
+
+// resolvedProfileName is the profile resolveCredentials used when
+// neither --profile nor SBOMHUB_PROFILE chose one: the config files'
+// current_context, else the default profile.
+func resolvedProfileName() string {
+	stack, err := loadConfigStack(getConfigDir())
+	if err != nil {
+		return config.DefaultProfile
+	}
+	return stack.File().ResolveName("")
+}
+
+// requireScope is the pre-flight of a write command: it asks the server
+// who the credential is and stops the command when the answer says the
+// credential lacks scope or has expired, before any LLM run or write.
+// Whenever the server cannot tell — whoami not declared, an error, a
+// role the CLI does not know — the command goes ahead and the server's
+// own check still applies; the pre-flight only saves time, it is not the
+// authority.
+func requireScope(ctx context.Context, client *sbomhub.Client, scope, op string) error {
+	out := GetOutputConfig()
+	if !serverCapabilities(ctx, client, client.BaseURL()).Declares(sbomhub.FeatureWhoami) {
+		out.PrintVerbose("権限の事前確認をスキップします (サーバが whoami を宣言していません)")
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, capabilitiesTimeout)
+	defer cancel()
+	id, err := client.WhoAmI(ctx)
+	if err != nil {
+		out.PrintVerbose("権限の事前確認をスキップします (whoami 失敗): %v", err)
+		return nil
+	}
+	if id.Expired(time.Now()) {
+		return &exitError{
+			code: exitPermanent,
+			msg:  fmt.Sprintf("%s: 認証情報の有効期限が切れています (%s)\n  → 'sbomhub whoami' で確認し、 API Key を更新してください", op, describeExpiry(id.ExpiresAt, time.Now())),
+		}
+	}
+	allowed, known := id.Can(scope)
+	if !known {
+		out.PrintVerbose("権限の事前確認をスキップします (サーバが %s の可否を示していません)", scope)
+		return nil
+	}
+	if !allowed {
+		return &exitError{
+			code: exitPermanent,
+			msg: fmt.Sprintf("%s には %s 権限が必要ですが、 この認証情報にはありません (role: %s, scopes: %s)\n  → 'sbomhub whoami' で確認し、 書き込み権限のある API Key を使用してください",
+				op, scope, orDash(id.Role), orDash(strings.Join(id.Scopes, ", "))),
+		}
+	}
+	return nil
+}
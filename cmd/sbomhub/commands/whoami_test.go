@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/youichi-uda/sbomhub-cli/pkg/sbomhub"
+)
+
+// whoamiServer answers GET /api/v1/cli/whoami with body (404 when empty),
+// declares whoami in its capabilities unless body is empty, and records
+// every other request, so a test can check that a denied command never
+// reached its write endpoint.
+func whoamiServer(t *testing.T, body string) (*httptest.Server, func() []string) {
+	t.Helper()
+	features := `["whoami","triage","cra","meti","vex"]`
+	if body == "" {
+		features = `["triage","cra","meti","vex"]`
+	}
+	return whoamiServerDeclaring(t, body, features)
+}
+
+// whoamiServerDeclaring is whoamiServer with an explicit capabilities
+// feature list.
+func whoamiServerDeclaring(t *testing.T, body, features string) (*httptest.Server, func() []string) {
+	t.Helper()
+	t.Setenv("SBOMHUB_CACHE_DIR", t.TempDir())
+	var (
+		mu    sync.Mutex
+		other []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/capabilities" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"version":"1.0.0","features":` + features + `}`))
+			return
+		}
+		if r.URL.Path == "/api/v1/cli/whoami" {
+			if body == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		mu.Lock()
+		other = append(other, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"r1","project_id":"p1","decision":"approved"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), other...)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		denied bool
+	}{
+		{"unpublished", "", false},
+		{"scoped writer", `{"tenant_id":"t1","scopes":["triage:*"]}`, false},
+		{"scoped reader", `{"tenant_id":"t1","scopes":["triage:read","cra:write"]}`, true},
+		{"viewer role", `{"tenant_id":"t1","role":"viewer"}`, true},
+		{"unknown role", `{"tenant_id":"t1","role":"auditor"}`, false},
+		{"expired", `{"tenant_id":"t1","role":"owner","expires_at":"2020-01-01T00:00:00Z"}`, true},
+	}
+	for _, tt := range tests {
+		srv, _ := whoamiServer(t, tt.body)
+		client := sbomhub.NewClient(srv.URL, "sbh_test")
+		err := requireScope(context.Background(), client, sbomhub.ScopeTriageWrite, "triage")
+		if (err != nil) != tt.denied {
+			t.Errorf("%s: requireScope() error = %v, denied want %v", tt.name, err, tt.denied)
+			continue
+		}
+		if err != nil {
+			if ee, ok := err.(*exitError); !ok || ee.code != exitPermanent {
+				t.Errorf("%s: error = %#v, want a permanent exitError", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestRequireScope_SkippedUnlessDeclared(t *testing.T) {
+	// The endpoint would deny the write, but a server that does not
+	// declare whoami is never asked.
+	srv, _ := whoamiServerDeclaring(t, `{"tenant_id":"t1","role":"viewer"}`, `["triage"]`)
+	client := sbomhub.NewClient(srv.URL, "sbh_test")
+	if err := requireScope(context.Background(), client, sbomhub.ScopeTriageWrite, "triage"); err != nil {
+		t.Errorf("requireScope() = %v, want the pre-flight skipped", err)
+	}
+}
+
+func TestWriteCommands_ReadOnlyKeyStopsBeforeWrite(t *testing.T) {
+	saveCra, saveReport, saveCVE, saveMeti := craProject, craApproveReportID, craDraftCVE, metiProject
+	t.Cleanup(func() {
+		craProject, craApproveReportID, craDraftCVE, metiProject = saveCra, saveReport, saveCVE, saveMeti
+	})
+	craProject, craApproveReportID, craDraftCVE, metiProject = "p1", "r1", "CVE-2024-12345", "p1"
+
+	tests := []struct {
+		name  string
+		run   func() error
+		scope string
+	}{
+		{"cra approve", func() error { return runCraApprove(craApproveCmd, nil) }, sbomhub.ScopeCRAWrite},
+		{"cra draft", func() error { return runCraDraft(craDraftCmd, nil) }, sbomhub.ScopeCRAWrite},
+		{"meti refresh", func() error { return runMetiRefresh(metiRefreshCmd, nil) }, sbomhub.ScopeMETIWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withCleanCredentialEnv(t)
+			srv, requests := whoamiServer(t, `{"tenant_id":"t1","key_name":"ci","role":"viewer"}`)
+			t.Setenv("SBOMHUB_API_URL", srv.URL)
+			t.Setenv("SBOMHUB_API_KEY", "sbh_readonly")
+			saved := *globalOutput
+			t.Cleanup(func() { *globalOutput = saved })
+			globalOutput.Writer, globalOutput.ErrWriter = io.Discard, io.Discard
+
+			err := tt.run()
+			if err == nil || !strings.Contains(err.Error(), tt.scope) {
+				t.Fatalf("%s error = %v, want a %s denial", tt.name, err, tt.scope)
+			}
+			if got := requests(); len(got) != 0 {
+				t.Errorf("requests after denial = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestRunWhoami_JSON(t *testing.T) {
+	withCleanCredentialEnv(t)
+	srv, _ := whoamiServer(t, `{"tenant_id":"t1","tenant_name":"Acme","key_name":"ci","role":"member","scopes":["cra:write"]}`)
+	t.Setenv("SBOMHUB_API_URL", srv.URL)
+	t.Setenv("SBOMHUB_API_KEY", "sbh_test")
+	saved := *globalOutput
+	t.Cleanup(func() { *globalOutput = saved })
+	var stdout bytes.Buffer
+	globalOutput.Writer, globalOutput.ErrWriter, globalOutput.JSON = &stdout, io.Discard, true
+
+	if err := runWhoami(whoamiCmd, nil); err != nil {
+		t.Fatalf("runWhoami() error = %v", err)
+	}
+	var got whoamiJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.APIURL != srv.URL || got.Profile != "default" || got.Identity == nil ||
+		got.Identity.TenantName != "Acme" || !got.Identity.Published {
+		t.Errorf("whoami --json = %+v (identity %+v)", got, got.Identity)
+	}
+}
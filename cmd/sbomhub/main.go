@@ -1,7 +1,6 @@
 package main
 
 import (
-	"errors"
 	"os"
 
 	"github.com/youichi-uda/sbomhub-cli/cmd/sbomhub/commands"
@@ -13,27 +12,18 @@ var (
 	date    = "unknown"
 )
 
-// exitCoder lets a command request a specific process exit code while
-// still returning an error through the cobra RunE contract. Used by
-// `sbomhub scan --fail-on` so CI workflows can distinguish:
+// main applies the shared exit-code table (commands/exitcode.go) so CI
+// workflows can branch on the same codes for every command:
 //
-//   - 1 threshold violation (vulnerabilities at/above --fail-on)
+//   - 0 success
+//   - 1 gate tripped (--fail-on / --policy) or a local failure
 //   - 2 wait-for-scan timed out (or background scan failed server-side)
-//   - 3 API / upload / configuration error
-//
-// Commands that don't implement this fall back to exit 1, preserving the
-// previous behaviour.
-type exitCoder interface {
-	ExitCode() int
-}
-
+//   - 3 permanent API / configuration error (4xx, BYOK not configured)
+//   - 4 transient API error (429 / 5xx / network) — retry later
+//   - 130 interrupted by SIGINT / SIGTERM (any command)
 func main() {
 	commands.SetVersion(version, commit, date)
 	if err := commands.Execute(); err != nil {
-		var ec exitCoder
-		if errors.As(err, &ec) {
-			os.Exit(ec.ExitCode())
-		}
-		os.Exit(1)
+		os.Exit(commands.ExitCode(err))
 	}
 }
@@ -0,0 +1,157 @@
+package api
+
+// Server capability negotiation.
+//
+// The CLI used to infer what a self-hosted server supports from how it
+// failed: a 404 on scan-status meant "older server", empty provider /
+// model on /health meant "not published yet", and gzip uploads were
+// tried and rolled back on 400 / 415, each guess flagged as unconfirmed.
+// GET /api/v1/capabilities lets the server declare its version and
+// feature set instead, and commands gate on Capabilities.Supports.
+//
+// Servers that predate the document answer 404. That is not an error:
+// Capabilities comes back with Published=false and Supports reports
+// "not declared", so every caller keeps its pre-negotiation fallback for
+// those servers.
+//
+// Two kinds of gate sit on top of the document. Lacks switches off an
+// endpoint the CLI has always called once a server says it is gone; the
+// default stays on. Declares switches ON client behaviour that depends
+// on a server contract beyond those endpoints (Idempotency-Key
+// de-duplication, cursor pagination, whoami, token exchange); the
+// default stays off, so a server that publishes no document, or leaves
+// the feature out, never sees it. The probe itself is a GET without
+// side effects, and answering 404 to it is a complete implementation.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Feature names the CLI understands in Capabilities.Features.
+const (
+	// FeatureSBOMUpload is the canonical POST /projects/{id}/sbom.
+	FeatureSBOMUpload = "sbom_upload"
+	// FeatureScanStatus is GET /projects/{id}/sboms/{sbom}/scan-status,
+	// which --wait-for-scan / --fail-on / --policy depend on.
+	FeatureScanStatus = "scan_status"
+	// FeatureGzipUpload means request bodies with Content-Encoding: gzip
+	// are decoded (upload.go).
+	FeatureGzipUpload = "gzip_request_encoding"
+	// FeatureLLMHealth means /api/v1/health publishes provider / model /
+	// connected for `llm test`.
+	FeatureLLMHealth = "llm_health_metadata"
+	// FeatureLegacyUpload is the multipart POST /api/v1/cli/upload that
+	// is being sunset. The CLI no longer calls it; doctor reports when a
+	// server still advertises it.
+	FeatureLegacyUpload = "legacy_cli_upload"
+	// FeatureTriage, FeatureCRA, FeatureMETI and FeatureVEX are the
+	// endpoint families behind the commands of the same names.
+	FeatureTriage = "triage"
+	FeatureCRA    = "cra"
+	FeatureMETI   = "meti"
+	FeatureVEX    = "vex"
+
+	// The features below are opt-in: client behaviour behind them is
+	// off unless the server declares them (Capabilities.Declares).
+
+	// FeatureIdempotencyKey means a POST repeated with the same
+	// Idempotency-Key inside the retry window returns the first
+	// response instead of acting twice. Only then do uploads and other
+	// writes carry a key and get retried on 5xx / transport errors
+	// (request.go).
+	FeatureIdempotencyKey = "idempotency_key"
+	// FeatureCursorPagination means list endpoints may answer with a
+	// next_cursor and accept it back as ?cursor=. Without it the CLI
+	// ignores any next_cursor and walks limit / offset (paginate.go).
+	FeatureCursorPagination = "cursor_pagination"
+	// FeatureWhoami is GET /api/v1/cli/whoami with the scope / role
+	// model described in whoami.go. Write commands only pre-flight
+	// their permission against a server that declares it.
+	FeatureWhoami = "whoami"
+	// FeatureTokenExchange means the token endpoint of the server's
+	// OAuth metadata accepts the RFC 8693 token-exchange grant for a CI
+	// job's OIDC identity token and returns a Bearer token for /api/v1.
+	// The CLI only mints and sends an identity token to a server that
+	// declares it (token_exchange.go).
+	FeatureTokenExchange = "token_exchange"
+)
+
+// Capabilities is the server's self-description.
+type Capabilities struct {
+	// Version is the server's release version (semver, "v" optional).
+	Version string `json:"version"`
+	// MinCLIVersion is the oldest CLI release the server supports;
+	// empty when it does not say.
+	MinCLIVersion string `json:"min_cli_version,omitempty"`
+	// Features lists the optional capabilities the server implements.
+	Features []string `json:"features"`
+
+	// Published is false when the server does not serve the document
+	// (404 from a server that predates it). Only the zero values above
+	// are available then.
+	Published bool `json:"published"`
+}
+
+// Supports reports whether the server declares feature. declared is
+// false when the server did not publish a capabilities document at all,
+// in which case callers keep their pre-negotiation behaviour; supported
+// is only meaningful when declared is true.
+func (c *Capabilities) Supports(feature string) (supported, declared bool) {
+	if c == nil || !c.Published {
+		return false, false
+	}
+	for _, f := range c.Features {
+		if f == feature {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// Lacks reports whether the server declared its features and feature is
+// not among them — the one case where a command should change course
+// before trying.
+func (c *Capabilities) Lacks(feature string) bool {
+	supported, declared := c.Supports(feature)
+	return declared && !supported
+}
+
+// Declares reports whether the server published a capabilities
+// document listing feature. Behaviour gated on it is off by default:
+// undeclared, unpublished and unfetched (nil) all answer false.
+func (c *Capabilities) Declares(feature string) bool {
+	supported, declared := c.Supports(feature)
+	return declared && supported
+}
+
+// SetCapabilities tells the client what the server declared, enabling
+// the opt-in behaviour its features allow. Without it (or with nil) the
+// client assumes nothing beyond the endpoints themselves.
+func (c *Client) SetCapabilities(caps *Capabilities) {
+	c.caps = caps
+}
+
+// GetCapabilities fetches GET /api/v1/capabilities. A 404 yields
+// Published=false and no error; any other failure is returned so the
+// caller can decide to proceed without negotiation.
+func (c *Client) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	url := fmt.Sprintf("%s/api/v1/capabilities", c.baseURL)
+	resp, err := c.send(ctx, apiRequest{method: http.MethodGet, url: url, omitAuthIfEmpty: true})
+	if err != nil {
+		var ae *Error
+		if errors.As(err, &ae) && ae.StatusCode == http.StatusNotFound {
+			return &Capabilities{}, nil
+		}
+		return nil, err
+	}
+	var caps Capabilities
+	if err := json.Unmarshal(resp.Body, &caps); err != nil {
+		return nil, fmt.Errorf("capabilities レスポンス解析エラー: %w", err)
+	}
+	caps.Published = true
+	return &caps, nil
+}
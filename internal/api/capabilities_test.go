@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetCapabilities_Published(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/capabilities" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("capabilities is public; no Authorization header expected without a key")
+		}
+		_, _ = w.Write([]byte(`{"version":"1.4.0","min_cli_version":"0.9.0","features":["sbom_upload","gzip_request_encoding"]}`))
+	}))
+	defer server.Close()
+
+	caps, err := NewClient(server.URL, "").GetCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("GetCapabilities() = %v", err)
+	}
+	if !caps.Published || caps.Version != "1.4.0" || caps.MinCLIVersion != "0.9.0" {
+		t.Errorf("caps = %+v", caps)
+	}
+	if ok, declared := caps.Supports(FeatureGzipUpload); !ok || !declared {
+		t.Errorf("Supports(gzip) = %v, %v; want supported", ok, declared)
+	}
+	if !caps.Lacks(FeatureScanStatus) {
+		t.Error("Lacks(scan_status) = false for a published document without it")
+	}
+	if !caps.Declares(FeatureGzipUpload) || caps.Declares(FeatureScanStatus) {
+		t.Error("Declares must follow the published feature list")
+	}
+}
+
+func TestGetCapabilities_NotFoundIsUnpublished(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	caps, err := NewClient(server.URL, "k").GetCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("GetCapabilities() = %v, want no error for a 404", err)
+	}
+	if caps.Published {
+		t.Error("Published = true for a 404")
+	}
+	if _, declared := caps.Supports(FeatureScanStatus); declared || caps.Lacks(FeatureScanStatus) {
+		t.Error("an unpublished document must not declare anything either way")
+	}
+	if caps.Declares(FeatureScanStatus) {
+		t.Error("Declares() = true without a published document; opt-in behaviour must default to off")
+	}
+}
+
+func TestCapabilities_NilIsUndeclared(t *testing.T) {
+	var caps *Capabilities
+	if supported, declared := caps.Supports(FeatureSBOMUpload); supported || declared {
+		t.Errorf("nil Supports() = %v, %v", supported, declared)
+	}
+	if caps.Lacks(FeatureSBOMUpload) {
+		t.Error("nil Lacks() = true")
+	}
+	if caps.Declares(FeatureSBOMUpload) {
+		t.Error("nil Declares() = true")
+	}
+}
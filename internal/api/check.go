@@ -0,0 +1,321 @@
+package api
+
+// Chunked vulnerability check — splits the component list extracted from
+// an SBOM into fixed-size batches and posts each batch to
+//
+//	POST /api/v1/cli/check
+//
+// with bounded concurrency. The endpoint itself is stateless (it matches
+// the posted components against the advisory DB and returns counts plus
+// the matched vulnerability list), so N small requests are semantically
+// identical to one big request as long as the batches are disjoint and
+// the results are summed.
+//
+// Why: container SBOMs routinely carry 8k+ components. A single POST of
+// that size either trips the 60s client timeout (the server matches
+// components sequentially) or the reverse proxy's request-body limit in
+// front of self-host deployments. Batching keeps every request well
+// inside both limits, and a transient 502 on one batch only costs a
+// retry of that batch instead of the whole run.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCheckChunkSize is the number of components posted per
+	// request. 500 keeps the JSON body well under 1 MiB for typical purls
+	// and matches the page size the rest of the client uses for list
+	// endpoints.
+	DefaultCheckChunkSize = 500
+	// DefaultCheckConcurrency bounds the number of in-flight chunk
+	// requests. Kept deliberately small — the server matches each chunk
+	// against the same advisory DB, and fanning out wider mostly moves
+	// the queue from the client to the server.
+	DefaultCheckConcurrency = 4
+	// DefaultCheckMaxRetries is the number of additional attempts per
+	// chunk after a retryable failure (429 / 5xx / network error).
+	DefaultCheckMaxRetries = 2
+	// defaultCheckRetryBackoff is the base delay before the first retry;
+	// subsequent retries double it. A server-supplied Retry-After wins
+	// when it is longer.
+	defaultCheckRetryBackoff = 1 * time.Second
+)
+
+// CheckOptions tunes CheckVulnerabilitiesWithOptions. The zero value
+// selects the defaults above, so callers only set what they override.
+type CheckOptions struct {
+	// ChunkSize is the maximum number of components per request.
+	ChunkSize int
+	// Concurrency is the maximum number of chunk requests in flight.
+	Concurrency int
+	// MaxRetries is the number of retries per chunk on a retryable
+	// failure. Negative disables retries; zero selects the default.
+	MaxRetries int
+	// RetryBackoff is the base delay between retries (doubled per
+	// attempt). Zero selects the default; tests set it to a few ms.
+	RetryBackoff time.Duration
+	// Progress, when non-nil, is called after each chunk completes with
+	// the number of finished chunks and the total. Calls are serialised,
+	// so the callback does not need its own locking.
+	Progress func(done, total int)
+}
+
+func (o CheckOptions) withDefaults() CheckOptions {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = DefaultCheckChunkSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultCheckConcurrency
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultCheckMaxRetries
+	} else if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = defaultCheckRetryBackoff
+	}
+	return o
+}
+
+// CheckVulnerabilitiesWithOptions checks the components in sbomData for
+// vulnerabilities without uploading, splitting them into chunks that are
+// sent concurrently and merged into a single CheckResult.
+//
+// Components are de-duplicated (by purl, falling back to name@version)
+// before chunking so the batches are disjoint and summed counts stay
+// accurate; the merged vulnerability list is additionally de-duplicated
+// by (id, package, version) in case the server maps two distinct purls
+// onto the same advisory row.
+//
+// The first chunk that fails permanently (or exhausts its retries)
+// cancels the remaining chunks and its error is returned — a partial
+// result would under-report vulnerabilities, which is worse than no
+// result for a CI gate.
+func (c *Client) CheckVulnerabilitiesWithOptions(ctx context.Context, sbomData []byte, opts CheckOptions) (*CheckResult, error) {
+	components, err := parseSBOMToComponents(sbomData)
+	if err != nil {
+		return nil, fmt.Errorf("SBOM解析エラー: %w", err)
+	}
+	opts = opts.withDefaults()
+
+	chunks := chunkComponents(dedupeComponents(components), opts.ChunkSize)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results  = make([]*CheckResult, len(chunks))
+		sem      = make(chan struct{}, opts.Concurrency)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		done     int
+	)
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			res, err := c.checkChunk(ctx, chunk, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			results[i] = res
+			done++
+			if opts.Progress != nil {
+				opts.Progress(done, len(chunks))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		if len(chunks) > 1 {
+			return nil, fmt.Errorf("チャンク送信に失敗しました (%d/%d 完了): %w", done, len(chunks), firstErr)
+		}
+		return nil, firstErr
+	}
+	// The parent ctx may have been cancelled before any worker got a
+	// semaphore slot; in that case no error was recorded but results are
+	// incomplete.
+	if err := ctx.Err(); err != nil && done < len(chunks) {
+		return nil, err
+	}
+	return mergeCheckResults(results), nil
+}
+
+// dedupeComponents drops repeated components while preserving order.
+// SBOM generators frequently list the same package more than once (e.g.
+// once per layer in a container image), and posting duplicates to
+// different chunks would double-count their vulnerabilities.
+func dedupeComponents(in []ComponentInput) []ComponentInput {
+	seen := make(map[string]struct{}, len(in))
+	out := make([]ComponentInput, 0, len(in))
+	for _, comp := range in {
+		key := comp.Purl
+		if key == "" {
+			key = comp.Name + "@" + comp.Version
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, comp)
+	}
+	return out
+}
+
+// chunkComponents splits components into slices of at most size items.
+// An empty input still yields one (empty) chunk so the request shape is
+// unchanged for zero-component SBOMs — the server answers with a zeroed
+// CheckResult, which keeps TotalComponents / counts authoritative.
+func chunkComponents(components []ComponentInput, size int) [][]ComponentInput {
+	if len(components) == 0 {
+		return [][]ComponentInput{{}}
+	}
+	var chunks [][]ComponentInput
+	for start := 0; start < len(components); start += size {
+		end := start + size
+		if end > len(components) {
+			end = len(components)
+		}
+		chunks = append(chunks, components[start:end])
+	}
+	return chunks
+}
+
+// checkRetryPolicy maps the per-check retry options onto the client's
+// pipeline policy, keeping the client's delay caps.
+func (c *Client) checkRetryPolicy(opts CheckOptions) *RetryPolicy {
+	p := c.retry
+	p.MaxRetries = opts.MaxRetries
+	p.BaseDelay = opts.RetryBackoff
+	return &p
+}
+
+// checkChunk posts a single batch of components. The endpoint is a pure
+// read (nothing is persisted server-side), so the POST is marked
+// idempotent and retried on 429 / 5xx / network errors per opts.
+func (c *Client) checkChunk(ctx context.Context, components []ComponentInput, opts CheckOptions) (*CheckResult, error) {
+	if components == nil {
+		components = []ComponentInput{}
+	}
+	jsonData, err := json.Marshal(CheckVulnerabilitiesRequest{Components: components})
+	if err != nil {
+		return nil, fmt.Errorf("リクエストのシリアライズに失敗: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/cli/check", c.baseURL)
+
+	resp, err := c.send(ctx, apiRequest{
+		method:     http.MethodPost,
+		url:        url,
+		body:       jsonData,
+		idempotent: true,
+		retry:      c.checkRetryPolicy(opts),
+	})
+	if err != nil {
+		return nil, err
+	}
+	body := resp.Body
+
+	var result CheckResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("レスポンス解析エラー: %w", err)
+	}
+
+	// Populate severity counts from BySeverity map
+	if result.BySeverity != nil {
+		result.Critical = result.BySeverity["CRITICAL"]
+		result.High = result.BySeverity["HIGH"]
+		result.Medium = result.BySeverity["MEDIUM"]
+		result.Low = result.BySeverity["LOW"]
+		result.Unknown = result.BySeverity["UNKNOWN"]
+	}
+
+	return &result, nil
+}
+
+// mergeCheckResults sums per-chunk counts and concatenates the
+// vulnerability lists, dropping repeated (id, package, version) entries
+// and decrementing the matching severity bucket for each drop so Total
+// and the per-severity counters stay consistent with the list.
+func mergeCheckResults(results []*CheckResult) *CheckResult {
+	merged := &CheckResult{BySeverity: map[string]int{}}
+	seen := make(map[string]struct{})
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		merged.TotalComponents += r.TotalComponents
+		merged.Total += r.Total
+		merged.Critical += r.Critical
+		merged.High += r.High
+		merged.Medium += r.Medium
+		merged.Low += r.Low
+		merged.Unknown += r.Unknown
+		for k, v := range r.BySeverity {
+			merged.BySeverity[strings.ToUpper(k)] += v
+		}
+		for _, v := range r.Vulnerabilities {
+			key := v.ID + "\x00" + v.Package + "\x00" + v.Version
+			if _, dup := seen[key]; dup {
+				merged.dropDuplicate(v.Severity)
+				continue
+			}
+			seen[key] = struct{}{}
+			merged.Vulnerabilities = append(merged.Vulnerabilities, v)
+		}
+	}
+	return merged
+}
+
+// dropDuplicate undoes the count contribution of one duplicate finding.
+// Counters are clamped at zero because a server that reports a list but
+// no counts (or vice versa) must not drive them negative.
+func (r *CheckResult) dropDuplicate(sev string) {
+	dec := func(n *int) {
+		if *n > 0 {
+			*n--
+		}
+	}
+	dec(&r.Total)
+	key := strings.ToUpper(sev)
+	switch key {
+	case "CRITICAL":
+		dec(&r.Critical)
+	case "HIGH":
+		dec(&r.High)
+	case "MEDIUM":
+		dec(&r.Medium)
+	case "LOW":
+		dec(&r.Low)
+	default:
+		key = "UNKNOWN"
+		dec(&r.Unknown)
+	}
+	if r.BySeverity[key] > 0 {
+		r.BySeverity[key]--
+	}
+}
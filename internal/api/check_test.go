@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// buildCycloneDX returns a CycloneDX SBOM with n distinct components
+// (pkg-0@1.0.0 … pkg-(n-1)@1.0.0).
+func buildCycloneDX(t *testing.T, n int) []byte {
+	t.Helper()
+	comps := make([]map[string]string, 0, n)
+	for i := 0; i < n; i++ {
+		comps = append(comps, map[string]string{
+			"name":    fmt.Sprintf("pkg-%d", i),
+			"version": "1.0.0",
+			"purl":    fmt.Sprintf("pkg:npm/pkg-%d@1.0.0", i),
+		})
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"bomFormat":  "CycloneDX",
+		"components": comps,
+	})
+	if err != nil {
+		t.Fatalf("marshal fixture: %v", err)
+	}
+	return data
+}
+
+// TestCheckVulnerabilitiesWithOptions_ChunksAndMerges verifies that a
+// large SBOM is split into ChunkSize batches, that every component is
+// sent exactly once, and that per-chunk counts are summed. Each chunk
+// reports one HIGH finding for its first component plus a shared
+// CRITICAL advisory row that the merge must collapse to a single entry.
+func TestCheckVulnerabilitiesWithOptions_ChunksAndMerges(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		seen     = map[string]int{}
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CheckVulnerabilitiesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		requests++
+		for _, c := range req.Components {
+			seen[c.Purl]++
+		}
+		mu.Unlock()
+		if len(req.Components) > 10 {
+			t.Errorf("chunk carried %d components, want <= 10", len(req.Components))
+		}
+		_ = json.NewEncoder(w).Encode(CheckResult{
+			TotalComponents: len(req.Components),
+			Total:           2,
+			BySeverity:      map[string]int{"HIGH": 1, "CRITICAL": 1},
+			Vulnerabilities: []VulnerabilityItem{
+				{ID: "CVE-2024-0001", Package: req.Components[0].Name, Version: "1.0.0", Severity: "HIGH"},
+				{ID: "CVE-2024-9999", Package: "shared", Version: "0.1.0", Severity: "CRITICAL"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	// 25 components + a duplicate of pkg-0 that must be dropped before
+	// chunking → 3 chunks (10, 10, 5).
+	var sbom map[string]interface{}
+	_ = json.Unmarshal(buildCycloneDX(t, 25), &sbom)
+	comps := sbom["components"].([]interface{})
+	sbom["components"] = append(comps, comps[0])
+	data, _ := json.Marshal(sbom)
+
+	var progress []int
+	client := NewClient(server.URL, "k")
+	res, err := client.CheckVulnerabilitiesWithOptions(context.Background(), data, CheckOptions{
+		ChunkSize:   10,
+		Concurrency: 2,
+		Progress: func(done, total int) {
+			if total != 3 {
+				t.Errorf("progress total = %d, want 3", total)
+			}
+			progress = append(progress, done)
+		},
+	})
+	if err != nil {
+		t.Fatalf("CheckVulnerabilitiesWithOptions() error = %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+	if len(seen) != 25 {
+		t.Errorf("distinct components sent = %d, want 25", len(seen))
+	}
+	for purl, n := range seen {
+		if n != 1 {
+			t.Errorf("component %s sent %d times, want 1", purl, n)
+		}
+	}
+	if fmt.Sprint(progress) != "[1 2 3]" {
+		t.Errorf("progress = %v, want [1 2 3]", progress)
+	}
+
+	if res.TotalComponents != 25 {
+		t.Errorf("TotalComponents = %d, want 25", res.TotalComponents)
+	}
+	// 3 HIGH (one per chunk) + 1 CRITICAL (shared row de-duplicated).
+	if res.Total != 4 || res.High != 3 || res.Critical != 1 {
+		t.Errorf("counts = total %d high %d critical %d, want 4/3/1", res.Total, res.High, res.Critical)
+	}
+	if res.BySeverity["CRITICAL"] != 1 {
+		t.Errorf("BySeverity[CRITICAL] = %d, want 1", res.BySeverity["CRITICAL"])
+	}
+	if len(res.Vulnerabilities) != 4 {
+		t.Errorf("len(Vulnerabilities) = %d, want 4", len(res.Vulnerabilities))
+	}
+}
+
+// TestCheckVulnerabilitiesWithOptions_BoundedConcurrency asserts no more
+// than Concurrency chunk requests are ever in flight at once.
+func TestCheckVulnerabilitiesWithOptions_BoundedConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		_ = json.NewEncoder(w).Encode(CheckResult{})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "k")
+	_, err := client.CheckVulnerabilitiesWithOptions(context.Background(), buildCycloneDX(t, 40), CheckOptions{
+		ChunkSize:   5,
+		Concurrency: 3,
+	})
+	if err != nil {
+		t.Fatalf("CheckVulnerabilitiesWithOptions() error = %v", err)
+	}
+	if peak > 3 {
+		t.Errorf("peak in-flight requests = %d, want <= 3", peak)
+	}
+}
+
+// TestCheckVulnerabilitiesWithOptions_RetriesTransientChunk verifies a
+// 502 on one chunk is retried and the run still succeeds.
+func TestCheckVulnerabilitiesWithOptions_RetriesTransientChunk(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream blip"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(CheckResult{Total: 1, BySeverity: map[string]int{"LOW": 1}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "k")
+	res, err := client.CheckVulnerabilitiesWithOptions(context.Background(), buildCycloneDX(t, 3), CheckOptions{
+		RetryBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("CheckVulnerabilitiesWithOptions() error = %v; expected the 502 to be retried", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if res.Low != 1 {
+		t.Errorf("Low = %d, want 1", res.Low)
+	}
+}
+
+// TestCheckVulnerabilitiesWithOptions_PermanentErrorNotRetried verifies
+// a 401 fails fast with a typed *Error and no retries.
+func TestCheckVulnerabilitiesWithOptions_PermanentErrorNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("bad key"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "k")
+	_, err := client.CheckVulnerabilitiesWithOptions(context.Background(), buildCycloneDX(t, 3), CheckOptions{
+		RetryBackoff: time.Millisecond,
+	})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("err = %v, want *Error with 401", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (401 must not be retried)", calls)
+	}
+}
+
+// TestCheckVulnerabilitiesWithOptions_ExhaustedRetriesReportProgress
+// verifies that when one chunk keeps failing, the error names how many
+// chunks had completed so the operator can tell a partial outage from a
+// total one.
+func TestCheckVulnerabilitiesWithOptions_ExhaustedRetriesReportProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CheckVulnerabilitiesRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Components[0].Name == "pkg-0" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(CheckResult{})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "k")
+	_, err := client.CheckVulnerabilitiesWithOptions(context.Background(), buildCycloneDX(t, 4), CheckOptions{
+		ChunkSize:    2,
+		Concurrency:  1,
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	})
+	if err == nil {
+		t.Fatal("expected error when a chunk exhausts its retries")
+	}
+	if !strings.Contains(err.Error(), "/2 完了") {
+		t.Errorf("error = %q, want chunk progress in message", err.Error())
+	}
+}
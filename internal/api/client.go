@@ -1,14 +1,14 @@
 package api
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,65 +17,24 @@ type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
-}
-
-// APIError represents a non-2xx HTTP response from the SBOMHub API.
-//
-// Codex R7 fix: returning a typed error (instead of a wrapped
-// fmt.Errorf "APIエラー (%d): %s") lets callers — specifically the
-// scan-status polling loop in cmd/sbomhub/commands/scan.go — use
-// errors.As to inspect the status code and decide whether to retry
-// (transient: network flap, 5xx, ctx cancellation) or fast-fail
-// (permanent: 401/403 bad auth, 404 endpoint missing on older server).
-//
-// Before this change, every non-2xx response was treated as transient
-// by waitForScanCompletion's `continue` branch, so a permanent 401
-// would silently retry for the full --wait-timeout (default 5 min) and
-// then report the failure as a "scan timed out" exit-2 — misleading
-// the operator into thinking the scan was slow when the real failure
-// mode was a misconfigured API key.
-//
-// Codex R13 fix (P2): the R7-7c classification split treated EVERY 4xx
-// (including 429 Too Many Requests) as permanent, so an upstream gateway
-// or API rate limiter throttling the polling loop would fast-fail the CI
-// run with exit-3 even though the next poll would have succeeded. The
-// IsRetryable method now isolates the truly-permanent client errors
-// (auth failure, missing endpoint, malformed path) from the transient
-// throttling case. When the server emits Retry-After (RFC 7231 §7.1.3),
-// RetryAfter carries the parsed wait hint so the polling loop can honour
-// the server's back-off instruction instead of hammering at the default
-// --poll-interval. We accept both delta-seconds and HTTP-date forms; an
-// unparseable header leaves RetryAfter at zero and the caller falls back
-// to its normal cadence.
-//
-// Currently only emitted by GetScanStatus; other client methods still
-// return wrapped sentinel errors. They can adopt APIError incrementally
-// as their callers gain a need to classify failures.
-type APIError struct {
-	StatusCode int
-	Message    string
-	URL        string
-	// RetryAfter is the duration parsed from the Retry-After response
-	// header on retryable responses (429 / 5xx). Zero when the header was
-	// absent or unparseable; callers should fall back to their own polling
-	// cadence in that case.
-	RetryAfter time.Duration
-}
-
-func (e *APIError) Error() string {
-	return fmt.Sprintf("APIエラー (%d) %s: %s", e.StatusCode, e.URL, e.Message)
-}
-
-// IsRetryable reports whether the status code represents a transient
-// failure that the caller may safely retry. 429 (rate limit) and 5xx
-// (server error / upstream blip) are retryable; all other 4xx are
-// permanent (the operator must fix config / upgrade the server before
-// any number of retries can succeed). Codex R13 fix (P2).
-func (e *APIError) IsRetryable() bool {
-	if e == nil {
-		return false
-	}
-	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	// retry is the automatic retry policy applied by send (request.go).
+	retry RetryPolicy
+	// sleep waits between retries; tests replace it to avoid real delays.
+	sleep func(ctx context.Context, d time.Duration) error
+	// gzipRejected remembers that the server refused a compressed
+	// upload, so later uploads through this client skip the attempt.
+	gzipRejected atomic.Bool
+	// tracer, when set, records every attempt (trace.go).
+	tracer *Tracer
+	// userAgent, when set, replaces Go's default User-Agent so server
+	// logs can tell CLI releases and SDK consumers apart.
+	userAgent string
+	// tokens, when set, supplies the bearer token in place of apiKey
+	// (device login; oauth.go).
+	tokens TokenSource
+	// caps is what the server declared (SetCapabilities); nil declares
+	// nothing, which keeps every opt-in feature off.
+	caps *Capabilities
 }
 
 // parseRetryAfter decodes the Retry-After header (RFC 7231 §7.1.3),
@@ -110,9 +69,28 @@ func NewClient(baseURL, apiKey string) *Client {
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
+		retry: DefaultRetryPolicy,
+		sleep: sleepContext,
 	}
 }
 
+// SetUserAgent sets the User-Agent header sent with every request. An
+// empty string restores Go's default.
+func (c *Client) SetUserAgent(ua string) {
+	c.userAgent = ua
+}
+
+// SetHTTPClient replaces the underlying *http.Client. The struct is
+// copied so that a later SetTransport (or the no-timeout upload path)
+// never mutates a client the caller may share with other code.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	if hc == nil {
+		hc = &http.Client{Timeout: 60 * time.Second}
+	}
+	copied := *hc
+	c.httpClient = &copied
+}
+
 // UploadResult represents the result of an SBOM upload
 type UploadResult struct {
 	Success        bool   `json:"success"`
@@ -131,6 +109,9 @@ type UploadResult struct {
 	Low                int `json:"low"`
 	// KEV (Known Exploited Vulnerabilities) count
 	KEVCount int `json:"kev_count"`
+	// Encoding is the Content-Encoding the accepted upload used ("gzip"
+	// or "" for identity). Diagnostic only; not part of --json output.
+	Encoding string `json:"-"`
 }
 
 // sbomUploadResponse mirrors the JSON returned by the canonical SBOM upload
@@ -159,7 +140,9 @@ type sbomUploadResponse struct {
 // otherwise be silently routed to a random project ID — UploadSBOM cannot
 // distinguish "user gave me this UUID on purpose" from "I synthesized this
 // from a directory name" without the explicit bit. Keeping looksLikeUUID
-// unexported keeps that gate co-located with the only legitimate use.
+// unexported keeps that gate co-located with its legitimate uses: this
+// one and IsProjectID (projectref.go), whose references are always given
+// explicitly, never synthesized from a path.
 //
 // We do strict format matching rather than pulling in github.com/google/uuid
 // to keep the CLI's dependency surface minimal (the project's go.mod has
@@ -222,7 +205,14 @@ func looksLikeUUID(s string) bool {
 // The legacy multipart POST /api/v1/cli/upload still exists with a Sunset of
 // 2026-09-24, but new requests MUST go through the canonical endpoint so the
 // product has one source of truth on auth + tenant scoping.
-func (c *Client) UploadSBOM(projectRef string, allowAsID bool, sbomData []byte, format string) (*UploadResult, error) {
+func (c *Client) UploadSBOM(ctx context.Context, projectRef string, allowAsID bool, sbomData []byte, format string) (*UploadResult, error) {
+	return c.UploadSBOMFrom(ctx, projectRef, allowAsID, SBOMBytes(sbomData), format, UploadOptions{})
+}
+
+// UploadSBOMFrom is UploadSBOM for an SBOM streamed from src, so a
+// multi-hundred-MB container SBOM never has to be held in memory. See
+// upload.go for compression and progress reporting.
+func (c *Client) UploadSBOMFrom(ctx context.Context, projectRef string, allowAsID bool, src SBOMSource, format string, opts UploadOptions) (*UploadResult, error) {
 	// Step 1: resolve projectRef to a project ID.
 	//
 	// If projectRef is an explicitly-supplied canonical UUID
@@ -249,7 +239,7 @@ func (c *Client) UploadSBOM(projectRef string, allowAsID bool, sbomData []byte,
 		projectName = ""
 		projectCreated = false
 	} else {
-		project, created, err := c.CreateProject(projectRef, "")
+		project, created, err := c.CreateProject(ctx, projectRef, "")
 		if err != nil {
 			return nil, fmt.Errorf("プロジェクト解決エラー: %w", err)
 		}
@@ -262,29 +252,19 @@ func (c *Client) UploadSBOM(projectRef string, allowAsID bool, sbomData []byte,
 	}
 
 	// Step 2: POST raw body to the canonical SBOM upload endpoint.
-	url := fmt.Sprintf("%s/api/v1/projects/%s/sbom", c.baseURL, projectID)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(sbomData))
-	if err != nil {
-		return nil, err
-	}
-
+	//
 	// Send raw JSON; the server reads body via io.ReadAll and detects
 	// CycloneDX vs SPDX from content, so the exact JSON media type is
-	// informational rather than dispatch-driving.
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
+	// informational rather than dispatch-driving. The upload creates a
+	// new SBOM row, so it asks for an Idempotency-Key: when the server
+	// declares it de-duplicates by key, a 502 from a proxy is retried with
+	// the same key instead of failing the CI run.
+	url := fmt.Sprintf("%s/api/v1/projects/%s/sbom", c.baseURL, projectID)
+	resp, encoding, err := c.uploadBody(ctx, url, src, opts)
 	if err != nil {
-		return nil, fmt.Errorf("リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("APIエラー (%d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
+	body := resp.Body
 
 	var sbomResp sbomUploadResponse
 	if err := json.Unmarshal(body, &sbomResp); err != nil {
@@ -303,6 +283,7 @@ func (c *Client) UploadSBOM(projectRef string, allowAsID bool, sbomData []byte,
 		ProjectCreated: projectCreated,
 		SBOMID:         sbomResp.ID,
 		Format:         sbomResp.Format,
+		Encoding:       encoding,
 	}
 
 	// Best-effort: pick up the web URL on the same host as the API. If the
@@ -342,6 +323,13 @@ type VulnerabilityItem struct {
 	FixedIn    string   `json:"fixed_in"`
 	Aliases    []string `json:"aliases"`
 	References []string `json:"references"`
+	// CVSSScore / InKEV are optional enrichment fields. ※要確認: the
+	// check endpoint does not emit them today; they are decoded so
+	// policy rules on cvss_gte / kev start working against `check` as
+	// soon as the server adds them, without a CLI release. Nil / zero
+	// means "not reported", not "not in KEV" / "CVSS 0".
+	CVSSScore float64 `json:"cvss_score,omitempty"`
+	InKEV     *bool   `json:"in_kev,omitempty"`
 }
 
 // ComponentInput represents a component for vulnerability check
@@ -350,6 +338,10 @@ type ComponentInput struct {
 	Version   string `json:"version"`
 	Purl      string `json:"purl,omitempty"`
 	Ecosystem string `json:"ecosystem,omitempty"`
+	// Scope is the CycloneDX component scope (required / optional /
+	// excluded). Local-only: it feeds policy rules and is not sent to
+	// the check endpoint.
+	Scope string `json:"-"`
 }
 
 // CheckVulnerabilitiesRequest represents the request body
@@ -357,60 +349,22 @@ type CheckVulnerabilitiesRequest struct {
 	Components []ComponentInput `json:"components"`
 }
 
-// CheckVulnerabilities checks components for vulnerabilities without uploading
-func (c *Client) CheckVulnerabilities(sbomData []byte) (*CheckResult, error) {
-	// Parse SBOM to extract components
-	components, err := parseSBOMToComponents(sbomData)
-	if err != nil {
-		return nil, fmt.Errorf("SBOM解析エラー: %w", err)
-	}
-
-	reqBody := CheckVulnerabilitiesRequest{
-		Components: components,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("リクエストのシリアライズに失敗: %w", err)
-	}
-
-	url := fmt.Sprintf("%s/api/v1/cli/check", c.baseURL)
-
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("APIエラー (%d): %s", resp.StatusCode, string(body))
-	}
-
-	var result CheckResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("レスポンス解析エラー: %w", err)
-	}
-
-	// Populate severity counts from BySeverity map
-	if result.BySeverity != nil {
-		result.Critical = result.BySeverity["CRITICAL"]
-		result.High = result.BySeverity["HIGH"]
-		result.Medium = result.BySeverity["MEDIUM"]
-		result.Low = result.BySeverity["LOW"]
-		result.Unknown = result.BySeverity["UNKNOWN"]
-	}
+// CheckVulnerabilities checks components for vulnerabilities without uploading.
+//
+// It is a thin wrapper around CheckVulnerabilitiesWithOptions using the
+// default chunk size / concurrency (see check.go); callers that need
+// progress reporting or tuning should call the options variant directly.
+func (c *Client) CheckVulnerabilities(ctx context.Context, sbomData []byte) (*CheckResult, error) {
+	return c.CheckVulnerabilitiesWithOptions(ctx, sbomData, CheckOptions{})
+}
 
-	return &result, nil
+// ExtractComponents returns the (name, version, purl) tuples the check
+// endpoint would be sent for sbomData. Exported so commands can map the
+// package/version pairs in CheckResult.Vulnerabilities back to purls
+// (e.g. for `.sbomhubignore` purl patterns) without re-implementing the
+// CycloneDX / SPDX walk.
+func ExtractComponents(sbomData []byte) ([]ComponentInput, error) {
+	return parseSBOMToComponents(sbomData)
 }
 
 // parseSBOMToComponents extracts components from SBOM data
@@ -433,6 +387,11 @@ func parseSBOMToComponents(sbomData []byte) ([]ComponentInput, error) {
 				Name:    getString(comp, "name"),
 				Version: getString(comp, "version"),
 				Purl:    getString(comp, "purl"),
+				Scope:   getString(comp, "scope"),
+			}
+			// CycloneDX: an absent scope SHOULD be read as "required".
+			if input.Scope == "" {
+				input.Scope = "required"
 			}
 			if input.Name != "" && input.Version != "" {
 				components = append(components, input)
@@ -536,39 +495,27 @@ type ScanStatusResponse struct {
 // still hung for up to 60s on a slow server — violating the documented
 // timeout contract.
 //
-// Codex R7 fix: non-2xx HTTP responses are returned as *APIError (instead
+// Codex R7 fix: non-2xx HTTP responses are returned as *Error (instead
 // of a stringly-wrapped fmt.Errorf) so the polling loop can classify
 // 4xx as permanent (fast-fail with exit 3) vs 5xx / network as transient
-// (retry within --wait-timeout). See APIError doc for the full rationale.
+// (retry within --wait-timeout). See IsRetryable in errors.go.
 //
-// Codex R13 fix (P2): APIError carries IsRetryable() and a parsed
+// Codex R13 fix (P2): *Error carries IsRetryable() and a parsed
 // Retry-After value so the polling loop can treat 429 (Too Many Requests)
 // as transient — previously folded under "all 4xx are permanent" which
 // caused upstream-throttled CIs to fast-fail incorrectly.
 func (c *Client) GetScanStatus(ctx context.Context, projectID, sbomID string) (*ScanStatusResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/projects/%s/sboms/%s/scan-status", c.baseURL, projectID, sbomID)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	// No pipeline retries here: waitForScanCompletion is itself a retry
+	// loop bounded by --wait-timeout that already classifies *Error
+	// and honours RetryAfter, so retrying inside each poll would only
+	// multiply its cadence.
+	resp, err := c.send(ctx, apiRequest{method: http.MethodGet, url: url, retry: &NoRetry})
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    string(body),
-			URL:        url,
-			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
-		}
-	}
+	body := resp.Body
 
 	var out ScanStatusResponse
 	if err := json.Unmarshal(body, &out); err != nil {
@@ -584,72 +531,84 @@ type Project struct {
 	Description string `json:"description"`
 	CreatedAt   string `json:"created_at,omitempty"`
 	UpdatedAt   string `json:"updated_at,omitempty"`
+	// Archived is set by ArchiveProject; servers without archiving
+	// never send it.
+	Archived   bool   `json:"archived,omitempty"`
+	ArchivedAt string `json:"archived_at,omitempty"`
 }
 
 // ProjectsListResponse represents the response for listing projects
 type ProjectsListResponse struct {
 	Projects []Project `json:"projects"`
 	Total    int       `json:"total"`
+	// NextCursor is followed only when the server declares
+	// FeatureCursorPagination (see Client.pageCursor).
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ListProjects retrieves all projects
-func (c *Client) ListProjects() ([]Project, error) {
-	url := fmt.Sprintf("%s/api/v1/cli/projects", c.baseURL)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	return c.PaginateProjects().All(ctx)
+}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+// projectsPageSize / listProjectsMaxPages bound the projects walk.
+//
+// ※要確認: /api/v1/cli/projects has never documented ?limit= /
+// ?offset=. A server that ignores them answers the first request with
+// every project; ListProjectsPage only continues past a page when the
+// body's total says more rows exist, so such a server still costs one
+// request and never yields duplicates.
+const (
+	projectsPageSize     = 100
+	listProjectsMaxPages = 200
+)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
+// PaginateProjects walks the projects visible to the API key lazily.
+func (c *Client) PaginateProjects() *Paginator[Project] {
+	return NewPaginator("projects", projectsPageSize, listProjectsMaxPages, c.ListProjectsPage)
+}
 
-	body, _ := io.ReadAll(resp.Body)
+// ListProjectsPage fetches the single page req describes.
+func (c *Client) ListProjectsPage(ctx context.Context, req PageRequest) (Page[Project], error) {
+	q := url.Values{}
+	setPageQuery(q, req)
+	endpoint := fmt.Sprintf("%s/api/v1/cli/projects?%s", c.baseURL, q.Encode())
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("APIエラー (%d): %s", resp.StatusCode, string(body))
+	resp, err := c.send(ctx, apiRequest{method: http.MethodGet, url: endpoint})
+	if err != nil {
+		return Page[Project]{}, err
 	}
+	body := resp.Body
 
 	var listResp ProjectsListResponse
 	if err := json.Unmarshal(body, &listResp); err != nil {
-		// Try parsing as array for backwards compatibility
+		// Try parsing as array for backwards compatibility. The bare
+		// array predates paging, so it is always the whole list.
 		var projects []Project
 		if err2 := json.Unmarshal(body, &projects); err2 != nil {
-			return nil, fmt.Errorf("レスポンス解析エラー: %w", err)
+			return Page[Project]{}, fmt.Errorf("レスポンス解析エラー: %w", err)
 		}
-		return projects, nil
+		return Page[Project]{Items: projects, Last: true}, nil
 	}
-
-	return listResp.Projects, nil
+	if listResp.Projects == nil {
+		listResp.Projects = []Project{}
+	}
+	page := Page[Project]{Items: listResp.Projects, Total: listResp.Total, NextCursor: c.pageCursor(listResp.NextCursor)}
+	if page.NextCursor == "" && (listResp.Total == 0 || req.Offset+len(listResp.Projects) >= listResp.Total) {
+		page.Last = true
+	}
+	return page, nil
 }
 
 // GetProject retrieves a project by ID
-func (c *Client) GetProject(id string) (*Project, error) {
+func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
 	url := fmt.Sprintf("%s/api/v1/cli/projects/%s", c.baseURL, id)
 
-	req, err := http.NewRequest("GET", url, nil)
+	resp, err := c.send(ctx, apiRequest{method: http.MethodGet, url: url})
 	if err != nil {
 		return nil, err
 	}
-
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("APIエラー (%d): %s", resp.StatusCode, string(body))
-	}
+	body := resp.Body
 
 	var project Project
 	if err := json.Unmarshal(body, &project); err != nil {
@@ -672,7 +631,7 @@ type CreateProjectResponse struct {
 }
 
 // CreateProject creates a new project or returns existing one
-func (c *Client) CreateProject(name, description string) (*Project, bool, error) {
+func (c *Client) CreateProject(ctx context.Context, name, description string) (*Project, bool, error) {
 	url := fmt.Sprintf("%s/api/v1/cli/projects", c.baseURL)
 
 	reqBody := CreateProjectRequest{
@@ -685,25 +644,20 @@ func (c *Client) CreateProject(name, description string) (*Project, bool, error)
 		return nil, false, fmt.Errorf("リクエストのシリアライズに失敗: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
+	// Get-or-create by name: repeating the call after a lost response
+	// returns the project the first attempt created, so it is safe to
+	// retry without an Idempotency-Key.
+	resp, err := c.send(ctx, apiRequest{
+		method:     http.MethodPost,
+		url:        url,
+		body:       jsonData,
+		okStatus:   []int{http.StatusOK, http.StatusCreated},
+		idempotent: true,
+	})
 	if err != nil {
 		return nil, false, err
 	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, false, fmt.Errorf("リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, false, fmt.Errorf("APIエラー (%d): %s", resp.StatusCode, string(body))
-	}
+	body := resp.Body
 
 	var createResp CreateProjectResponse
 	if err := json.Unmarshal(body, &createResp); err != nil {
@@ -104,7 +104,7 @@ func TestUploadSBOM(t *testing.T) {
 	// supply; the value is a name, so the UUID short-circuit does not
 	// fire and we still take the get-or-create path. allowAsID toggles
 	// "may treat as ID if it happens to be a UUID", not "is an ID".
-	result, err := client.UploadSBOM("my-project", true, sbomData, "cyclonedx")
+	result, err := client.UploadSBOM(context.Background(), "my-project", true, sbomData, "cyclonedx")
 
 	if err != nil {
 		t.Fatalf("UploadSBOM() error = %v", err)
@@ -187,7 +187,7 @@ func TestUploadSBOM_ProjectRefIsUUID(t *testing.T) {
 	sbomData := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4"}`)
 	// allowAsID=true → the caller (scan.go) saw an explicit --project
 	// flag, so a UUID-format value is honored as a project ID.
-	result, err := client.UploadSBOM(projectID, true, sbomData, "cyclonedx")
+	result, err := client.UploadSBOM(context.Background(), projectID, true, sbomData, "cyclonedx")
 	if err != nil {
 		t.Fatalf("UploadSBOM() error = %v", err)
 	}
@@ -297,7 +297,7 @@ func TestUploadSBOM_UUIDImplicitTreatedAsName(t *testing.T) {
 	sbomData := []byte(`{"bomFormat": "CycloneDX", "specVersion": "1.4"}`)
 	// allowAsID=false: caller is the dir-basename fallback path in
 	// scan.go and has NOT been told to treat this value as a UUID.
-	result, err := client.UploadSBOM(dirBasenameUUID, false, sbomData, "cyclonedx")
+	result, err := client.UploadSBOM(context.Background(), dirBasenameUUID, false, sbomData, "cyclonedx")
 	if err != nil {
 		t.Fatalf("UploadSBOM() error = %v", err)
 	}
@@ -381,7 +381,7 @@ func TestUploadSBOMError(t *testing.T) {
 
 	// allowAsID=true is irrelevant here ("my-project" is not a UUID), but
 	// match the realistic explicit-flag case.
-	_, err := client.UploadSBOM("my-project", true, []byte(`{}`), "cyclonedx")
+	_, err := client.UploadSBOM(context.Background(), "my-project", true, []byte(`{}`), "cyclonedx")
 
 	if err == nil {
 		t.Error("UploadSBOM() expected error for 401 response from canonical endpoint")
@@ -412,7 +412,7 @@ func TestCheckVulnerabilities(t *testing.T) {
 	client := NewClient(server.URL, "test-key")
 
 	sbomData := []byte(`{"bomFormat": "CycloneDX"}`)
-	result, err := client.CheckVulnerabilities(sbomData)
+	result, err := client.CheckVulnerabilities(context.Background(), sbomData)
 
 	if err != nil {
 		t.Fatalf("CheckVulnerabilities() error = %v", err)
@@ -447,7 +447,7 @@ func TestListProjects(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	projects, err := client.ListProjects()
+	projects, err := client.ListProjects(context.Background())
 
 	if err != nil {
 		t.Fatalf("ListProjects() error = %v", err)
@@ -471,7 +471,7 @@ func TestListProjectsError(t *testing.T) {
 
 	client := NewClient(server.URL, "test-key")
 
-	_, err := client.ListProjects()
+	_, err := client.ListProjects(context.Background())
 
 	if err == nil {
 		t.Error("ListProjects() expected error for 500 response")
@@ -551,7 +551,7 @@ func TestGetScanStatus(t *testing.T) {
 // poll loop logs and retries on errors here, so the only contract we
 // need from the client is "return a non-nil error".
 //
-// Codex R7: the error must also be a typed *APIError so the polling
+// Codex R7: the error must also be a typed *Error so the polling
 // loop can inspect StatusCode and classify it (5xx → transient, retry
 // within --wait-timeout). Without the typed wrapper the loop has no
 // way to distinguish 5xx from network errors from 4xx.
@@ -567,18 +567,18 @@ func TestGetScanStatusError(t *testing.T) {
 	if err == nil {
 		t.Fatal("GetScanStatus() expected error for 500 response")
 	}
-	var apiErr *APIError
+	var apiErr *Error
 	if !errors.As(err, &apiErr) {
-		t.Fatalf("error is not *APIError: %T %v (R7: non-2xx must surface as typed APIError for classification)", err, err)
+		t.Fatalf("error is not *Error: %T %v (R7: non-2xx must surface as typed Error for classification)", err, err)
 	}
 	if apiErr.StatusCode != http.StatusInternalServerError {
-		t.Errorf("APIError.StatusCode = %d, want %d", apiErr.StatusCode, http.StatusInternalServerError)
+		t.Errorf("Error.StatusCode = %d, want %d", apiErr.StatusCode, http.StatusInternalServerError)
 	}
 	// Codex R13 P2 regression guard: 5xx must remain in the retryable
 	// bucket so the polling loop keeps riding out brief upstream blips
 	// within the --wait-timeout budget.
 	if !apiErr.IsRetryable() {
-		t.Error("APIError{500}.IsRetryable() = false; want true (5xx must remain retryable per R13 classification)")
+		t.Error("Error{500}.IsRetryable() = false; want true (5xx must remain retryable per R13 classification)")
 	}
 }
 
@@ -612,16 +612,16 @@ func TestAPIError_IsRetryable(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			e := &APIError{StatusCode: tc.code}
+			e := &Error{StatusCode: tc.code}
 			if got := e.IsRetryable(); got != tc.want {
-				t.Errorf("APIError{%d}.IsRetryable() = %v, want %v", tc.code, got, tc.want)
+				t.Errorf("Error{%d}.IsRetryable() = %v, want %v", tc.code, got, tc.want)
 			}
 		})
 	}
 	t.Run("nil receiver", func(t *testing.T) {
-		var e *APIError
+		var e *Error
 		if e.IsRetryable() {
-			t.Error("nil *APIError.IsRetryable() = true, want false")
+			t.Error("nil *Error.IsRetryable() = true, want false")
 		}
 	})
 }
@@ -675,7 +675,7 @@ func TestParseRetryAfter(t *testing.T) {
 
 // TestGetScanStatus_RateLimitedRetryAfter verifies the Codex R13 P2 fix
 // end-to-end at the client layer: a 429 response with a Retry-After header
-// must surface as a typed *APIError where IsRetryable() == true AND the
+// must surface as a typed *Error where IsRetryable() == true AND the
 // header is parsed into RetryAfter. Both fields are load-bearing for the
 // polling loop in scan.go — IsRetryable gates the transient retry path,
 // and RetryAfter lets the loop honour the server's back-off hint instead
@@ -693,18 +693,18 @@ func TestGetScanStatus_RateLimitedRetryAfter(t *testing.T) {
 	if err == nil {
 		t.Fatal("GetScanStatus() expected error for 429 response")
 	}
-	var apiErr *APIError
+	var apiErr *Error
 	if !errors.As(err, &apiErr) {
-		t.Fatalf("error is not *APIError: %T %v", err, err)
+		t.Fatalf("error is not *Error: %T %v", err, err)
 	}
 	if apiErr.StatusCode != http.StatusTooManyRequests {
 		t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, http.StatusTooManyRequests)
 	}
 	if !apiErr.IsRetryable() {
-		t.Error("APIError.IsRetryable() = false for 429; want true (Codex R13 P2: 429 must be retryable, not folded into permanent-4xx)")
+		t.Error("Error.IsRetryable() = false for 429; want true (Codex R13 P2: 429 must be retryable, not folded into permanent-4xx)")
 	}
 	if apiErr.RetryAfter != 45*time.Second {
-		t.Errorf("APIError.RetryAfter = %s, want 45s (parsed from Retry-After header)", apiErr.RetryAfter)
+		t.Errorf("Error.RetryAfter = %s, want 45s (parsed from Retry-After header)", apiErr.RetryAfter)
 	}
 }
 
@@ -723,20 +723,20 @@ func TestGetScanStatus_RateLimitedNoRetryAfter(t *testing.T) {
 	if err == nil {
 		t.Fatal("GetScanStatus() expected error for 429 response")
 	}
-	var apiErr *APIError
+	var apiErr *Error
 	if !errors.As(err, &apiErr) {
-		t.Fatalf("error is not *APIError: %T %v", err, err)
+		t.Fatalf("error is not *Error: %T %v", err, err)
 	}
 	if !apiErr.IsRetryable() {
-		t.Error("APIError.IsRetryable() = false for 429; want true even without Retry-After")
+		t.Error("Error.IsRetryable() = false for 429; want true even without Retry-After")
 	}
 	if apiErr.RetryAfter != 0 {
-		t.Errorf("APIError.RetryAfter = %s, want 0 (no Retry-After header sent)", apiErr.RetryAfter)
+		t.Errorf("Error.RetryAfter = %s, want 0 (no Retry-After header sent)", apiErr.RetryAfter)
 	}
 }
 
 // TestGetScanStatus_PermanentClientError verifies the Codex R7 fix:
-// 4xx responses from scan-status are returned as a typed *APIError so
+// 4xx responses from scan-status are returned as a typed *Error so
 // the polling loop in scan.go can recognise the permanent failure and
 // fast-fail (exit-3) instead of silently retrying for --wait-timeout
 // and then reporting it as a misleading exit-2 "scan timed out".
@@ -771,9 +771,9 @@ func TestGetScanStatus_PermanentClientError(t *testing.T) {
 			if err == nil {
 				t.Fatal("expected error")
 			}
-			var apiErr *APIError
+			var apiErr *Error
 			if !errors.As(err, &apiErr) {
-				t.Fatalf("error is not *APIError: %T %v", err, err)
+				t.Fatalf("error is not *Error: %T %v", err, err)
 			}
 			if apiErr.StatusCode != tc.code {
 				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tc.code)
@@ -781,12 +781,12 @@ func TestGetScanStatus_PermanentClientError(t *testing.T) {
 			// URL must round-trip so the operator-facing message in
 			// runScan can include the failing endpoint.
 			if apiErr.URL == "" {
-				t.Error("APIError.URL is empty; want the failing scan-status URL for diagnostics")
+				t.Error("Error.URL is empty; want the failing scan-status URL for diagnostics")
 			}
 			// The body must round-trip too so the server's error
 			// message (e.g. "invalid api key") is preserved end-to-end.
 			if !strings.Contains(apiErr.Message, "permanent") {
-				t.Errorf("APIError.Message = %q, want substring %q (server body lost)", apiErr.Message, "permanent")
+				t.Errorf("Error.Message = %q, want substring %q (server body lost)", apiErr.Message, "permanent")
 			}
 			// Codex R13 P2 regression guard: these codes must remain
 			// classified as permanent. A regression where 401/403/404
@@ -794,7 +794,7 @@ func TestGetScanStatus_PermanentClientError(t *testing.T) {
 			// missing-endpoint for the full --wait-timeout — the exact
 			// failure mode R7 was introduced to fix.
 			if apiErr.IsRetryable() {
-				t.Errorf("APIError{%d}.IsRetryable() = true; want false (R13: only 429+5xx are retryable, all other 4xx remain permanent)", tc.code)
+				t.Errorf("Error{%d}.IsRetryable() = true; want false (R13: only 429+5xx are retryable, all other 4xx remain permanent)", tc.code)
 			}
 		})
 	}
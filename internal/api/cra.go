@@ -15,12 +15,10 @@ package api
 // M2 — "approved な vex_drafts から取得").
 //
 // All five helpers share the existing api.Client (Bearer auth, base
-// URL, default 60s timeout). Non-2xx responses fall through to the
-// shared parsing helper decodeCRAError which mirrors the M1 triage
-// pattern: typed CRAError + IsAIDisabled / IsPermanent / IsTransient
-// helpers so the CLI loop can classify failures and surface the right
-// exit code without sprinkling status-code checks across the command
-// layer.
+// URL, default 60s timeout). Non-2xx responses surface as the shared
+// *Error (errors.go) so the CLI loop can classify failures with
+// IsAIDisabled / IsPermanent / IsTransient and surface the right exit
+// code without sprinkling status-code checks across the command layer.
 //
 // M1 fix patterns carried over to CRA (regression coverage in
 // cra_test.go):
@@ -31,15 +29,11 @@ package api
 //   - F28 X-Total-Count header captured into the list result
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"strconv"
-	"strings"
 )
 
 // ----------------------------------------------------------------------------
@@ -150,7 +144,7 @@ type CRARunReportResult struct {
 	// server NEVER emits this on a 2xx response — when present it is
 	// the F23 protocol-violation signal: the handler returned HTTP 200
 	// but logically failed. The CLI promotes this to a transient
-	// *CRAError in RunReport / ReanalyseReport so the exit-code path
+	// *Error in RunReport / ReanalyseReport so the exit-code path
 	// can flag the failure.
 	Error string `json:"error,omitempty"`
 }
@@ -171,6 +165,9 @@ type CRAReportListFilter struct {
 // craReportListResponse mirrors handler.craReportListResponse.
 type craReportListResponse struct {
 	Reports []CRAReport `json:"reports"`
+	// NextCursor is followed only when the server declares
+	// FeatureCursorPagination (see Client.pageCursor).
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // CRADecisionRequest is the body of PUT /cra-reports/:id/decision.
@@ -185,156 +182,6 @@ type CRADecisionRequest struct {
 	EditedDraftText *string `json:"edited_draft_text,omitempty"`
 }
 
-// ----------------------------------------------------------------------------
-// Error decoding
-// ----------------------------------------------------------------------------
-
-// CRAError is the typed error returned by the CRA helpers when the
-// server emits a non-2xx response. It carries the parsed JSON body
-// (`error` + optional `reason`) so the CLI can branch on:
-//
-//   - 503 + AI-disabled reason → llm.DisabledError → under_investigation fallback
-//   - 409                       → no approved VEX draft (operator must triage first)
-//   - other 4xx                 → permanent user / config error
-//   - 5xx (non-503)             → transient server error
-//
-// Callers test category with the helpers IsAIDisabled / IsPermanent /
-// IsTransient so the classification stays in one place — identical
-// rationale to the M1 *TriageError pattern.
-type CRAError struct {
-	StatusCode int
-	URL        string
-	Method     string
-	Message    string // top-level "error" field
-	Reason     string // "reason" field (only populated for 503 DisabledError)
-	Raw        string // original body, preserved for debug logging
-
-	// ProtocolError flags a synthesised CRAError raised for a 2xx
-	// response that violated the success contract — server returned
-	// HTTP 200/201 but the body had no report (and was not flagged
-	// ai_disabled), or carried an explicit "error" field. Callers
-	// inspect this via IsTransient (always true when set) so the CLI
-	// loop surfaces such bugs through the exit-4 transient path
-	// instead of silently bucketing the call as a success
-	// (mirrors M1 #F23).
-	ProtocolError bool
-}
-
-func (e *CRAError) Error() string {
-	if e.Reason != "" {
-		return fmt.Sprintf("cra API %s %s -> %d: %s (%s)", e.Method, e.URL, e.StatusCode, e.Message, e.Reason)
-	}
-	if e.Message != "" {
-		return fmt.Sprintf("cra API %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
-	}
-	return fmt.Sprintf("cra API %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Raw)
-}
-
-// craAIDisabledReasonMarkers are the substrings (case-insensitive) we
-// recognise as the BYOK-not-configured signal on a 503 response.
-//
-// M1 #F22 carry-over: only 503s with a recognised reason text classify
-// as AI-disabled. Anything else on 503 is treated as a real outage and
-// classifies as transient (see IsTransient). Without this strict check,
-// a gateway 503 page would silently be mis-classified as "BYOK off" and
-// the CLI would exit 0 with zero persisted reports.
-var craAIDisabledReasonMarkers = []string{
-	"ai features are disabled",
-	"byok key not configured",
-	"byok not configured",
-}
-
-// IsAIDisabled reports whether the server signalled the BYOK-not-
-// configured fallback path. The canonical M2-4 server may also signal
-// AI-disabled on a 2xx via CRARunReportResult.AIDisabled; the CLI
-// inspects that field directly on the 2xx path and consults this
-// helper only on the error path (legacy 503 compat).
-func (e *CRAError) IsAIDisabled() bool {
-	if e == nil || e.StatusCode != http.StatusServiceUnavailable {
-		return false
-	}
-	hay := strings.ToLower(e.Message + " " + e.Reason + " " + e.Raw)
-	for _, m := range craAIDisabledReasonMarkers {
-		if strings.Contains(hay, m) {
-			return true
-		}
-	}
-	return false
-}
-
-// IsPermanent reports whether the error is a permanent 4xx that the
-// caller cannot retry without fixing config (auth / input validation /
-// missing report / no approved VEX draft). 429 is intentionally
-// transient (matches scan polling loop classification).
-//
-// 503 is never permanent — it is either AI-disabled (handled by
-// IsAIDisabled) or a transient outage (handled by IsTransient).
-//
-// 409 (no approved VEX draft for this (project, cve)) is treated as
-// permanent because the operator's correct response is "go run
-// sbomhub triage on this CVE first", NOT "retry tomorrow".
-func (e *CRAError) IsPermanent() bool {
-	if e == nil {
-		return false
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return false
-	}
-	if e.StatusCode == http.StatusServiceUnavailable {
-		return false
-	}
-	return e.StatusCode >= 400 && e.StatusCode < 500
-}
-
-// IsTransient reports whether the error is a transient failure the
-// operator can resolve by retrying — 429 (rate limit) and 5xx (server
-// error / upstream blip). The bucket is symmetric with IsPermanent so
-// the CLI loop can categorise each failure into exactly one of:
-// AI-disabled (caller-handled), permanent, transient, or unclassified.
-//
-// ProtocolError joins the transient bucket because the operator's
-// correct response is the same (retry, possibly after a server
-// redeploy) — mirrors M1 #F23.
-func (e *CRAError) IsTransient() bool {
-	if e == nil {
-		return false
-	}
-	if e.ProtocolError {
-		return true
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return true
-	}
-	if e.StatusCode == http.StatusServiceUnavailable {
-		// AI-disabled wins via IsAIDisabled() and the caller short-
-		// circuit; everything else on 503 is a real outage that the
-		// operator can resolve by retrying.
-		return !e.IsAIDisabled()
-	}
-	return e.StatusCode >= 500 && e.StatusCode < 600
-}
-
-// decodeCRAError builds a CRAError from a non-2xx response body.
-// Tolerates non-JSON bodies (intermediate gateways sometimes send
-// HTML 502 pages) — Raw always carries the original text.
-func decodeCRAError(method, url string, status int, body []byte) *CRAError {
-	te := &CRAError{
-		StatusCode: status,
-		URL:        url,
-		Method:     method,
-		Raw:        string(body),
-	}
-	var parsed struct {
-		Error  string `json:"error"`
-		Reason string `json:"reason"`
-	}
-	if err := json.Unmarshal(body, &parsed); err == nil {
-		te.Message = parsed.Error
-		te.Reason = parsed.Reason
-	}
-	return te
-}
-
 // ----------------------------------------------------------------------------
 // RunReport — POST /api/v1/projects/:id/cra-reports/run
 // ----------------------------------------------------------------------------
@@ -342,7 +189,7 @@ func decodeCRAError(method, url string, status int, body []byte) *CRAError {
 // RunReport executes one CRA report drafting cycle for (project, cve)
 // on the server and returns the persisted report + AIDisabled flag.
 //
-// 409 from cra.ErrNoApprovedVEXDraft is returned as a *CRAError where
+// 409 from cra.ErrNoApprovedVEXDraft is returned as a *Error where
 // IsPermanent() == true — the CLI surfaces this with an actionable
 // "run sbomhub triage first" hint via mapCRARunReportError above the
 // CLI layer.
@@ -354,23 +201,18 @@ func (c *Client) RunReport(ctx context.Context, projectID string, req CRARunRepo
 		return nil, fmt.Errorf("cra-report リクエストのシリアライズに失敗: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	resp, err := c.send(ctx, apiRequest{
+		method:         http.MethodPost,
+		url:            endpoint,
+		body:           body,
+		okStatus:       []int{http.StatusCreated, http.StatusOK},
+		decodeError:    serviceDecoder("cra"),
+		idempotencyKey: true,
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("cra-report リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, decodeCRAError(http.MethodPost, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	return decodeRunReportResult(http.MethodPost, endpoint, resp.StatusCode, respBody)
 }
@@ -379,7 +221,7 @@ func (c *Client) RunReport(ctx context.Context, projectID string, req CRARunRepo
 // ListReports — GET /api/v1/projects/:id/cra-reports
 // ----------------------------------------------------------------------------
 
-// craReportsListPageSize is the per-page request size the CLI uses
+// CRAReportsPageSize is the per-page request size the CLI uses
 // when paging /cra-reports. M1 #F26 carry-over: the server caps
 // ?limit= at MaxCRAReportsListLimit (500) and returns 400 on out-of-
 // band values, so the CLI requests pages at the maximum allowed size
@@ -389,11 +231,11 @@ func (c *Client) RunReport(ctx context.Context, projectID string, req CRARunRepo
 // cra_reports.go MaxCRAReportsListLimit (500). If the server raises
 // the cap, the CLI can be updated independently, but a value above
 // the server's cap will start returning 400 on the first request.
-const craReportsListPageSize = 500
+const CRAReportsPageSize = 500
 
 // craReportsListMaxPages is a defensive ceiling on the number of pages
 // the CLI is willing to fetch before erroring out. Tuned to match
-// MaxCRAReportsListOffset (10000) / craReportsListPageSize (500) so
+// MaxCRAReportsListOffset (10000) / CRAReportsPageSize (500) so
 // the loop cannot iterate past the server's offset clamp.
 //
 // ※要確認: server caps offset at 10000 (cra_reports.go
@@ -409,44 +251,27 @@ const craReportsListMaxPages = 25
 //
 // Filter.Limit / Filter.Offset are IGNORED — the CLI always pages
 // through the full set. Callers that want a hard cap should slice the
-// result. This keeps the paging contract centralised here rather than
-// duplicated at every call site.
-//
-// M1 #F26 carry-over: pages through using craReportsListPageSize per
-// request and stops when either:
-//   - a page returns fewer rows than the requested page size (canonical
-//     "no more rows" signal), or
-//   - craReportsListMaxPages is reached (defensive ceiling).
+// result, or stop PaginateReports early.
 func (c *Client) ListReports(ctx context.Context, projectID string, filter CRAReportListFilter) ([]CRAReport, int, error) {
-	all := make([]CRAReport, 0, craReportsListPageSize)
-	offset := 0
-	total := 0
-	for page := 0; page < craReportsListMaxPages; page++ {
-		batch, pageTotal, err := c.listReportsPage(ctx, projectID, filter, craReportsListPageSize, offset)
-		if err != nil {
-			return nil, 0, err
-		}
-		all = append(all, batch...)
-		// X-Total-Count is stable across pages (it counts the matching
-		// filtered set, not the current page) — preserve the first
-		// page's value but accept later pages updating it if the
-		// server changes its mind mid-walk.
-		if pageTotal > 0 {
-			total = pageTotal
-		}
-		if len(batch) < craReportsListPageSize {
-			return all, total, nil
-		}
-		offset += len(batch)
-	}
-	return all, total, fmt.Errorf("cra-reports ページング上限 (%d ページ × %d 件) 到達: サーバーがページング終端を返していない可能性があります",
-		craReportsListMaxPages, craReportsListPageSize)
+	p := c.PaginateReports(projectID, filter)
+	all, err := p.All(ctx)
+	return all, p.Total(), err
 }
 
-// listReportsPage fetches a single (limit, offset) page from the
-// server. Extracted from ListReports so the paging loop stays readable
-// and so tests can drive the per-page decode independently.
-func (c *Client) listReportsPage(ctx context.Context, projectID string, filter CRAReportListFilter, limit, offset int) ([]CRAReport, int, error) {
+// PaginateReports walks the reports matching filter lazily,
+// CRAReportsPageSize rows per request. filter.Limit / filter.Offset are
+// ignored, as in ListReports.
+func (c *Client) PaginateReports(projectID string, filter CRAReportListFilter) *Paginator[CRAReport] {
+	return NewPaginator("cra-reports", CRAReportsPageSize, craReportsListMaxPages,
+		func(ctx context.Context, req PageRequest) (Page[CRAReport], error) {
+			return c.ListReportsPage(ctx, projectID, filter, req)
+		})
+}
+
+// ListReportsPage fetches the single page req describes. Page.Total is
+// X-Total-Count (M1 #F28 carry-over), the source of truth for the
+// matching filtered count.
+func (c *Client) ListReportsPage(ctx context.Context, projectID string, filter CRAReportListFilter, req PageRequest) (Page[CRAReport], error) {
 	endpoint := fmt.Sprintf("%s/api/v1/projects/%s/cra-reports", c.baseURL, projectID)
 
 	q := url.Values{}
@@ -465,49 +290,27 @@ func (c *Client) listReportsPage(ctx context.Context, projectID string, filter C
 	if filter.Decision != "" {
 		q.Set("decision", filter.Decision)
 	}
-	q.Set("limit", strconv.Itoa(limit))
-	if offset > 0 {
-		q.Set("offset", strconv.Itoa(offset))
-	}
+	setPageQuery(q, req)
 	endpoint = endpoint + "?" + q.Encode()
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodGet,
+		url:         endpoint,
+		decodeError: serviceDecoder("cra"),
+	})
 	if err != nil {
-		return nil, 0, err
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, 0, fmt.Errorf("cra-reports リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, 0, decodeCRAError(http.MethodGet, endpoint, resp.StatusCode, respBody)
+		return Page[CRAReport]{}, err
 	}
+	respBody := resp.Body
 
 	var out craReportListResponse
 	if err := json.Unmarshal(respBody, &out); err != nil {
-		return nil, 0, fmt.Errorf("cra-reports レスポンス解析エラー: %w", err)
+		return Page[CRAReport]{}, fmt.Errorf("cra-reports レスポンス解析エラー: %w", err)
 	}
 	if out.Reports == nil {
 		out.Reports = []CRAReport{}
 	}
-
-	// M1 #F28 carry-over: X-Total-Count is the source of truth for
-	// the matching filtered count. Falls back to 0 (caller treats 0
-	// as "no count available") when the header is missing or
-	// unparseable so a legacy server cannot break the page loop.
-	total := 0
-	if v := resp.Header.Get("X-Total-Count"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			total = n
-		}
-	}
-
-	return out.Reports, total, nil
+	return Page[CRAReport]{Items: out.Reports, Total: totalCount(resp.Header), NextCursor: c.pageCursor(out.NextCursor)}, nil
 }
 
 // ----------------------------------------------------------------------------
@@ -522,22 +325,15 @@ func (c *Client) listReportsPage(ctx context.Context, projectID string, filter C
 func (c *Client) GetReport(ctx context.Context, projectID, reportID string) (*CRAReport, error) {
 	endpoint := fmt.Sprintf("%s/api/v1/projects/%s/cra-reports/%s", c.baseURL, projectID, reportID)
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodGet,
+		url:         endpoint,
+		decodeError: serviceDecoder("cra"),
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("cra-report 取得エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, decodeCRAError(http.MethodGet, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	var out CRAReport
 	if err := json.Unmarshal(respBody, &out); err != nil {
@@ -562,23 +358,16 @@ func (c *Client) DecideReport(ctx context.Context, projectID, reportID string, d
 		return nil, fmt.Errorf("cra-decision リクエストのシリアライズに失敗: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodPut,
+		url:         endpoint,
+		body:        body,
+		decodeError: serviceDecoder("cra"),
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("cra-decision リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, decodeCRAError(http.MethodPut, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	var out CRAReport
 	if err := json.Unmarshal(respBody, &out); err != nil {
@@ -608,23 +397,18 @@ func (c *Client) ReanalyseReport(ctx context.Context, projectID, reportID string
 		return nil, fmt.Errorf("cra-reanalyse リクエストのシリアライズに失敗: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	resp, err := c.send(ctx, apiRequest{
+		method:         http.MethodPost,
+		url:            endpoint,
+		body:           body,
+		okStatus:       []int{http.StatusCreated, http.StatusOK},
+		decodeError:    serviceDecoder("cra"),
+		idempotencyKey: true,
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("cra-reanalyse リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, decodeCRAError(http.MethodPost, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	return decodeRunReportResult(http.MethodPost, endpoint, resp.StatusCode, respBody)
 }
@@ -646,7 +430,8 @@ func decodeRunReportResult(method, endpoint string, status int, body []byte) (*C
 	}
 
 	if out.Error != "" {
-		return nil, &CRAError{
+		return nil, &Error{
+			Service:       "cra",
 			StatusCode:    status,
 			URL:           endpoint,
 			Method:        method,
@@ -662,7 +447,8 @@ func decodeRunReportResult(method, endpoint string, status int, body []byte) (*C
 		} else {
 			msg = "cra-report success response missing report (server protocol error — F23 contract violation)"
 		}
-		return nil, &CRAError{
+		return nil, &Error{
+			Service:       "cra",
 			StatusCode:    status,
 			URL:           endpoint,
 			Method:        method,
@@ -154,9 +154,9 @@ func TestRunReport_AIDisabled503Legacy(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for 503 response")
 	}
-	var ce *CRAError
+	var ce *Error
 	if !errors.As(err, &ce) {
-		t.Fatalf("err = %v (%T), want *CRAError", err, err)
+		t.Fatalf("err = %v (%T), want *Error", err, err)
 	}
 	if !ce.IsAIDisabled() {
 		t.Errorf("IsAIDisabled = false, want true (legacy 503 with known reason)")
@@ -194,9 +194,9 @@ func TestRunReport_PermanentClientError(t *testing.T) {
 			_, err := client.RunReport(context.Background(), "p", CRARunReportRequest{
 				VulnerabilityID: "v", CVEID: "c", ReportType: "early_warning", Lang: "ja",
 			})
-			var ce *CRAError
+			var ce *Error
 			if !errors.As(err, &ce) {
-				t.Fatalf("err = %v, want *CRAError", err)
+				t.Fatalf("err = %v, want *Error", err)
 			}
 			if got := ce.IsPermanent(); got != tc.want {
 				t.Errorf("IsPermanent() = %v, want %v", got, tc.want)
@@ -224,7 +224,7 @@ func TestCRAError_IsAIDisabled_503GenericNotMatch_F22(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			ce := &CRAError{
+			ce := &Error{
 				StatusCode: http.StatusServiceUnavailable,
 				Message:    tc.message,
 			}
@@ -253,7 +253,7 @@ func TestCRAError_IsAIDisabled_503KnownReasonMatch_F22(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			ce := &CRAError{
+			ce := &Error{
 				StatusCode: http.StatusServiceUnavailable,
 				Message:    tc.message,
 			}
@@ -296,9 +296,9 @@ func TestRunReport_2xxEmptyReport_ReturnsError_F23(t *testing.T) {
 			if err == nil {
 				t.Fatalf("F23: expected error for 2xx with no report, got %+v", res)
 			}
-			var ce *CRAError
+			var ce *Error
 			if !errors.As(err, &ce) {
-				t.Fatalf("F23: err = %v (%T), want *CRAError", err, err)
+				t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 			}
 			if !ce.IsTransient() {
 				t.Errorf("F23: protocol error must classify transient")
@@ -322,9 +322,9 @@ func TestRunReport_2xxWithErrorField_ReturnsError_F23(t *testing.T) {
 	if err == nil {
 		t.Fatal("F23: expected error")
 	}
-	var ce *CRAError
+	var ce *Error
 	if !errors.As(err, &ce) {
-		t.Fatalf("F23: err = %v (%T), want *CRAError", err, err)
+		t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 	}
 	if !strings.Contains(ce.Message, "upstream LLM provider failure") {
 		t.Errorf("F23: error message must round-trip server error field, got %q", ce.Message)
@@ -545,9 +545,9 @@ func TestGetReport_404Permanent(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	_, err := client.GetReport(context.Background(), "p", "r")
-	var ce *CRAError
+	var ce *Error
 	if !errors.As(err, &ce) {
-		t.Fatalf("err = %v, want *CRAError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if !ce.IsPermanent() {
 		t.Errorf("IsPermanent = false, want true")
@@ -0,0 +1,252 @@
+package api
+
+// Error taxonomy.
+//
+// Every non-success response from every endpoint surfaces as *Error.
+// Before this existed the core client, triage, CRA, METI and LLM helpers
+// each carried a near-identical copy (APIError, TriageError, CRAError,
+// MetiError, LLMError) with subtly diverging classification — LLMError
+// counted the AI-disabled 503 as permanent, CRAError did not; only
+// APIError carried Retry-After — and every command re-implemented the
+// mapping to exit codes on top. Kind is now the one classification the
+// retry pipeline and the CLI's exit-code table both consult.
+//
+// Transport failures (no HTTP response at all) stay a separate
+// *RequestError: they have no status, body or server reason to carry.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrorKind classifies an *Error for retry and exit-code decisions.
+type ErrorKind int
+
+const (
+	// KindUnclassified is a status outside 4xx / 5xx (e.g. an
+	// unexpected 3xx or a 200 where 201 was required). Callers treat
+	// it like KindPermanent: retrying will not change the answer.
+	KindUnclassified ErrorKind = iota
+	// KindPermanent is a 4xx other than 429: auth, validation, missing
+	// resource, conflict. The operator must fix something first.
+	KindPermanent
+	// KindTransient is 429 or a 5xx (except the AI-disabled 503): the
+	// same request may succeed later.
+	KindTransient
+	// KindAIDisabled is the legacy 503 carrying the "AI features are
+	// disabled / BYOK not configured" reason. It needs operator action
+	// (configure BYOK in /settings/llm), never a retry.
+	KindAIDisabled
+	// KindProtocol is a 2xx whose body violated the success contract
+	// (missing payload, explicit "error" field). Grouped with transient
+	// for exit codes — the fix is a server redeploy, then a retry.
+	KindProtocol
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindPermanent:
+		return "permanent"
+	case KindTransient:
+		return "transient"
+	case KindAIDisabled:
+		return "ai-disabled"
+	case KindProtocol:
+		return "protocol-violation"
+	default:
+		return "unclassified"
+	}
+}
+
+// Error is the typed error for a non-success (or contract-violating)
+// API response.
+type Error struct {
+	// Service names the API surface ("triage", "cra", "meti", "llm");
+	// empty for the core CLI endpoints. It only prefixes Error().
+	Service    string
+	StatusCode int
+	URL        string
+	Method     string
+	Message    string // top-level "error" field
+	Reason     string // "reason" field (populated for the 503 DisabledError)
+	Raw        string // original body, preserved for debug logging
+
+	// RetryAfter is the duration parsed from the Retry-After response
+	// header. Zero when the header was absent or unparseable; callers
+	// should fall back to their own cadence in that case.
+	RetryAfter time.Duration
+
+	// ProtocolError flags an *Error synthesised for a 2xx response that
+	// violated the success contract (M1 #F23). It makes Kind report
+	// KindProtocol regardless of StatusCode.
+	ProtocolError bool
+
+	// RequestID is the X-Request-ID of the failed exchange: the server's
+	// echo when it sent one, otherwise the ID the client generated
+	// (trace.go). Error() appends it so the operator can grep server
+	// logs for the exact request.
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	return withRequestID(e.message(), e.RequestID)
+}
+
+func (e *Error) message() string {
+	prefix := "API"
+	if e.Service != "" {
+		prefix = e.Service + " API"
+	}
+	head := fmt.Sprintf("%s %s %s -> %d", prefix, e.Method, e.URL, e.StatusCode)
+	if e.Method == "" {
+		head = fmt.Sprintf("%s %s -> %d", prefix, e.URL, e.StatusCode)
+	}
+	switch {
+	case e.Reason != "":
+		return fmt.Sprintf("%s: %s (%s)", head, e.Message, e.Reason)
+	case e.Message != "":
+		return fmt.Sprintf("%s: %s", head, e.Message)
+	default:
+		return fmt.Sprintf("%s: %s", head, strings.TrimSpace(e.Raw))
+	}
+}
+
+// withRequestID appends the request ID to an error message.
+func withRequestID(msg, id string) string {
+	if id == "" {
+		return msg
+	}
+	return msg + " [request-id: " + id + "]"
+}
+
+// Detail returns the server's error message, falling back to the raw
+// body when the response was not the usual JSON envelope.
+func (e *Error) Detail() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return strings.TrimSpace(e.Raw)
+}
+
+// serviceMETI has no AI dependency (the evaluator is fully local), so a
+// 503 from it is always an outage even if the body happens to mention
+// BYOK.
+const serviceMETI = "meti"
+
+// aiDisabledReasonMarkers are the substrings (case-insensitive) we
+// recognise as the legacy BYOK-not-configured signal on a 503 response.
+// F19 moved the canonical AI-disabled path onto 2xx + ai_disabled=true,
+// so this list only catches OLD servers that still emit
+// llm.DisabledError on 503. Anything else on 503 is a real outage and
+// must classify as transient (M1 #F22).
+//
+// ※要確認: kept in lower-case for case-insensitive substring match.
+// The exact server reason text was `"AI features are disabled"` (with
+// reason `"no LLM provider configured"`) per the legacy DisabledError.
+// Synonyms ("BYOK key not configured") guard against minor copy edits.
+var aiDisabledReasonMarkers = []string{
+	"ai features are disabled",
+	"byok key not configured",
+	"byok not configured",
+}
+
+// Kind classifies e. A nil *Error is KindUnclassified.
+func (e *Error) Kind() ErrorKind {
+	switch {
+	case e == nil:
+		return KindUnclassified
+	case e.ProtocolError:
+		return KindProtocol
+	case e.StatusCode == http.StatusServiceUnavailable && e.Service != serviceMETI && e.hasAIDisabledReason():
+		return KindAIDisabled
+	case e.StatusCode == http.StatusTooManyRequests, e.StatusCode >= 500 && e.StatusCode < 600:
+		return KindTransient
+	case e.StatusCode >= 400 && e.StatusCode < 500:
+		return KindPermanent
+	default:
+		return KindUnclassified
+	}
+}
+
+func (e *Error) hasAIDisabledReason() bool {
+	// The server has historically emitted the reason text in any of
+	// the three slots depending on whether the JSON body parsed cleanly.
+	hay := strings.ToLower(e.Message + " " + e.Reason + " " + e.Raw)
+	for _, m := range aiDisabledReasonMarkers {
+		if strings.Contains(hay, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAIDisabled reports whether the server signalled the legacy
+// BYOK-not-configured 503.
+func (e *Error) IsAIDisabled() bool { return e.Kind() == KindAIDisabled }
+
+// IsPermanent reports whether retrying cannot help: a 4xx other than
+// 429, or the AI-disabled 503 (the operator must configure BYOK).
+func (e *Error) IsPermanent() bool {
+	k := e.Kind()
+	return k == KindPermanent || k == KindAIDisabled
+}
+
+// IsTransient reports whether the operator can resolve the failure by
+// retrying: 429, a 5xx other than the AI-disabled 503, or a protocol
+// violation. Exactly one of IsPermanent / IsTransient holds for every
+// 4xx / 5xx.
+func (e *Error) IsTransient() bool {
+	k := e.Kind()
+	return k == KindTransient || k == KindProtocol
+}
+
+// IsNotFound reports a 404: the resource does not exist, or belongs to
+// a tenant the key cannot see (the server does not tell them apart).
+func (e *Error) IsNotFound() bool { return e != nil && e.StatusCode == http.StatusNotFound }
+
+// IsConflict reports a 409: the request clashes with the resource's
+// current state (a duplicate name, a concurrent change).
+func (e *Error) IsConflict() bool { return e != nil && e.StatusCode == http.StatusConflict }
+
+// IsRetryable reports whether the request pipeline (or the scan-status
+// polling loop) may repeat the same request automatically.
+//
+// Codex R7 / R13 history: the polling loop used to retry every non-2xx
+// for the full --wait-timeout, so a bad API key surfaced as a misleading
+// "scan timed out"; the first fix then made every 4xx permanent, which
+// fast-failed CI runs on a rate limiter's 429. 429 and 5xx are
+// retryable, the remaining 4xx are not. Protocol violations are
+// excluded: the server answered 2xx, so repeating the call immediately
+// would only repeat the bug (and, for a write, the side effect).
+func (e *Error) IsRetryable() bool { return e.Kind() == KindTransient }
+
+// serviceDecoder returns the errorDecoder for one API surface. Bodies
+// that are not JSON (intermediate gateways send HTML 502 pages) leave
+// Message empty; Raw always carries the original text.
+func serviceDecoder(service string) errorDecoder {
+	return func(method, url string, status int, body []byte) error {
+		return decodeError(service, method, url, status, body)
+	}
+}
+
+func decodeError(service, method, url string, status int, body []byte) *Error {
+	e := &Error{
+		Service:    service,
+		StatusCode: status,
+		URL:        url,
+		Method:     method,
+		Raw:        string(body),
+	}
+	var parsed struct {
+		Error  string `json:"error"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		e.Message = parsed.Error
+		e.Reason = parsed.Reason
+	}
+	return e
+}
@@ -20,7 +20,10 @@ package api
 //     gracefully reports "N/A" for the missing fields until the
 //     server is extended (tracked as a separate M4-* issue, scoped
 //     out of this milestone). When the server adds a richer payload
-//     the matching fields below pick it up automatically.
+//     the matching fields below pick it up automatically. A server
+//     that does publish them declares FeatureLLMHealth in its
+//     capabilities document (capabilities.go), which `llm test` uses
+//     to tell "not published" from "not configured".
 //   - There is no LLM-specific health endpoint yet
 //     (/api/v1/health/llm). Adding one is the cleanest long-term
 //     answer but requires an API surface bump + auth decision
@@ -30,9 +33,8 @@ package api
 // M1 / M2 / M3 fix patterns carried over (regression coverage in
 // llm_test.go):
 //   - F21 exit code classification (3 permanent, 4 transient — done
-//         at the command layer; the typed LLMError + IsPermanent /
-//         IsTransient surface mirrors *MetiError so the command
-//         layer can branch consistently)
+//         by the shared exit-code table on the command layer via
+//         the typed *Error)
 //   - F22 strict 503 AI-disabled detection (only 503s with a
 //         recognised reason classify as ai_disabled; gateway 503s
 //         remain transient outages)
@@ -43,7 +45,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 )
@@ -99,153 +100,6 @@ type LLMHealthResponse struct {
 	Reason string `json:"reason,omitempty"`
 }
 
-// ----------------------------------------------------------------------------
-// Error decoding
-// ----------------------------------------------------------------------------
-
-// LLMError is the typed error returned by Health() when the server
-// emits a non-2xx response. Mirrors *MetiError so the CLI loop can
-// branch on the same IsPermanent / IsTransient surface (F21).
-//
-// IsAIDisabled is the M2-CRA-style oracle on 503: when the body
-// carries a recognised "AI features disabled / BYOK key not
-// configured" reason the CLI treats the failure as a soft signal
-// (still permanent, but the operator's correct action is "configure
-// BYOK in /settings/llm", not "retry"). A gateway 503 page without
-// the marker stays in the transient bucket (F22).
-type LLMError struct {
-	StatusCode int
-	URL        string
-	Method     string
-	Message    string // top-level "error" field
-	Reason     string // top-level "reason" field (only populated for 503 DisabledError)
-	Raw        string // original body, preserved for debug logging
-
-	// ProtocolError flags a synthesised LLMError raised for a 2xx
-	// response that violated the success contract — server returned
-	// HTTP 200 but the body had no status field, or carried an
-	// explicit "error" field. Callers inspect this via IsTransient
-	// (always true when set) so the CLI loop surfaces such bugs
-	// through the exit-4 transient path instead of silently
-	// bucketing the call as a success (mirrors M1 #F23).
-	ProtocolError bool
-}
-
-func (e *LLMError) Error() string {
-	if e.Reason != "" {
-		return fmt.Sprintf("llm health %s %s -> %d: %s (%s)", e.Method, e.URL, e.StatusCode, e.Message, e.Reason)
-	}
-	if e.Message != "" {
-		return fmt.Sprintf("llm health %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
-	}
-	return fmt.Sprintf("llm health %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Raw)
-}
-
-// llmAIDisabledReasonMarkers are the substrings (case-insensitive)
-// we recognise as the BYOK-not-configured signal on a 503 response.
-// Mirrors craAIDisabledReasonMarkers verbatim — keep them in sync if
-// the server changes its DisabledError reason wording.
-//
-// F22 carry-over: only 503s with a recognised reason text classify
-// as AI-disabled. Anything else on 503 is treated as a real outage
-// and classifies as transient (see IsTransient).
-var llmAIDisabledReasonMarkers = []string{
-	"ai features are disabled",
-	"byok key not configured",
-	"byok not configured",
-}
-
-// IsAIDisabled reports whether the server signalled the BYOK-not-
-// configured fallback path on a 503 response.
-//
-// The CLI's `sbomhub llm test` surfaces this as a permanent (exit-3)
-// failure with the operator-actionable hint "configure BYOK in
-// /settings/llm" — distinct from a generic 503 (which the CLI
-// surfaces as transient and tells the operator to retry).
-func (e *LLMError) IsAIDisabled() bool {
-	if e == nil || e.StatusCode != http.StatusServiceUnavailable {
-		return false
-	}
-	hay := strings.ToLower(e.Message + " " + e.Reason + " " + e.Raw)
-	for _, m := range llmAIDisabledReasonMarkers {
-		if strings.Contains(hay, m) {
-			return true
-		}
-	}
-	return false
-}
-
-// IsPermanent reports whether the error is a permanent failure that
-// the operator cannot retry without fixing config (auth / missing
-// endpoint / AI-disabled). 429 (rate limit) is transient. Generic
-// 503 is transient (the operator's correct response is "retry" — an
-// AI-disabled 503 is folded into permanent via IsAIDisabled which
-// the command layer checks first).
-func (e *LLMError) IsPermanent() bool {
-	if e == nil {
-		return false
-	}
-	if e.IsAIDisabled() {
-		// BYOK-not-configured needs operator action (configure key),
-		// not a retry — bucket as permanent.
-		return true
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return false
-	}
-	if e.StatusCode == http.StatusServiceUnavailable {
-		return false
-	}
-	return e.StatusCode >= 400 && e.StatusCode < 500
-}
-
-// IsTransient reports whether the error is a transient failure the
-// operator can resolve by retrying — 429 (rate limit), generic 5xx
-// (server error / upstream blip), and ProtocolError (server contract
-// violation that may be redeploy-fixed).
-//
-// Symmetric with IsPermanent: a properly-classified error must land
-// in exactly one bucket.
-func (e *LLMError) IsTransient() bool {
-	if e == nil {
-		return false
-	}
-	if e.ProtocolError {
-		return true
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return true
-	}
-	if e.StatusCode == http.StatusServiceUnavailable {
-		// AI-disabled wins via IsAIDisabled() + the command-layer
-		// short-circuit; everything else on 503 is a real outage
-		// that the operator can resolve by retrying.
-		return !e.IsAIDisabled()
-	}
-	return e.StatusCode >= 500 && e.StatusCode < 600
-}
-
-// decodeLLMError builds an LLMError from a non-2xx response body.
-// Tolerates non-JSON bodies (intermediate gateways sometimes send
-// HTML 502 pages) — Raw always carries the original text.
-func decodeLLMError(method, url string, status int, body []byte) *LLMError {
-	te := &LLMError{
-		StatusCode: status,
-		URL:        url,
-		Method:     method,
-		Raw:        string(body),
-	}
-	var parsed struct {
-		Error  string `json:"error"`
-		Reason string `json:"reason"`
-	}
-	if err := json.Unmarshal(body, &parsed); err == nil {
-		te.Message = parsed.Error
-		te.Reason = parsed.Reason
-	}
-	return te
-}
-
 // ----------------------------------------------------------------------------
 // Health — GET /api/v1/health
 // ----------------------------------------------------------------------------
@@ -260,32 +114,18 @@ func decodeLLMError(method, url string, status int, body []byte) *LLMError {
 // the header.
 //
 // F23 carry-over: a 2xx response with no "status" field is surfaced
-// as a transient *LLMError (ProtocolError=true) so the command layer
+// as a transient *Error (ProtocolError=true) so the command layer
 // catches the server bug rather than silently green-lighting a
 // no-payload probe.
 func (c *Client) Health(ctx context.Context) (*LLMHealthResponse, error) {
 	endpoint := fmt.Sprintf("%s/api/v1/health", c.baseURL)
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
 	// Auth header is informational on this endpoint (server ignores
 	// it) but sending it costs nothing and protects against gateway
 	// configurations that strip unauthenticated requests.
-	if c.apiKey != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("llm health リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
+	//
 	// M4 Codex review #F39 fix: treat any non-2xx response (not just
-	// the narrow != 200 case) as an LLMError. Previously a 204 No
+	// the narrow != 200 case) as an *Error. Previously a 204 No
 	// Content / 206 Partial Content would skip this branch, then
 	// fall into the JSON-parse / status-validation path and surface
 	// as a plain fmt.Errorf — which the command layer's default
@@ -293,9 +133,17 @@ func (c *Client) Health(ctx context.Context) (*LLMHealthResponse, error) {
 	// exit-4 (F23 contract). With the wider range, 2xx-but-not-200
 	// responses with empty / missing-status bodies now correctly
 	// flow through the ProtocolError=true transient bucket below.
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, decodeLLMError(http.MethodGet, endpoint, resp.StatusCode, respBody)
+	resp, err := c.send(ctx, apiRequest{
+		method:          http.MethodGet,
+		url:             endpoint,
+		any2xx:          true,
+		decodeError:     serviceDecoder("llm"),
+		omitAuthIfEmpty: true,
+	})
+	if err != nil {
+		return nil, err
 	}
+	respBody := resp.Body
 
 	var out LLMHealthResponse
 	// 204 No Content (and any other empty-body 2xx) must NOT be
@@ -319,7 +167,8 @@ func (c *Client) Health(ctx context.Context) (*LLMHealthResponse, error) {
 		// / any other "success but non-OK" response that the
 		// widened status check above lets through to the decode
 		// stage.
-		return nil, &LLMError{
+		return nil, &Error{
+			Service:       "llm",
 			StatusCode:    resp.StatusCode,
 			URL:           endpoint,
 			Method:        http.MethodGet,
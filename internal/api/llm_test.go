@@ -147,9 +147,9 @@ func TestHealth_PermanentClientError(t *testing.T) {
 			defer server.Close()
 			client := NewClient(server.URL, "k")
 			_, err := client.Health(context.Background())
-			var le *LLMError
+			var le *Error
 			if !errors.As(err, &le) {
-				t.Fatalf("err = %v, want *LLMError", err)
+				t.Fatalf("err = %v, want *Error", err)
 			}
 			if got := le.IsPermanent(); got != tc.want {
 				t.Errorf("IsPermanent() = %v, want %v", got, tc.want)
@@ -182,9 +182,9 @@ func TestHealth_TransientClassification(t *testing.T) {
 			defer server.Close()
 			client := NewClient(server.URL, "k")
 			_, err := client.Health(context.Background())
-			var le *LLMError
+			var le *Error
 			if !errors.As(err, &le) {
-				t.Fatalf("err = %v, want *LLMError", err)
+				t.Fatalf("err = %v, want *Error", err)
 			}
 			if got := le.IsTransient(); got != tc.want {
 				t.Errorf("IsTransient() = %v, want %v", got, tc.want)
@@ -248,9 +248,9 @@ func TestHealth_503AIDisabled_Strict(t *testing.T) {
 			defer server.Close()
 			client := NewClient(server.URL, "k")
 			_, err := client.Health(context.Background())
-			var le *LLMError
+			var le *Error
 			if !errors.As(err, &le) {
-				t.Fatalf("err = %v, want *LLMError", err)
+				t.Fatalf("err = %v, want *Error", err)
 			}
 			if got := le.IsAIDisabled(); got != tc.wantAIDisabled {
 				t.Errorf("F22: IsAIDisabled = %v, want %v (body=%s)", got, tc.wantAIDisabled, tc.body)
@@ -275,9 +275,9 @@ func TestHealth_NonJSONErrorBody(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	_, err := client.Health(context.Background())
-	var le *LLMError
+	var le *Error
 	if !errors.As(err, &le) {
-		t.Fatalf("err = %v, want *LLMError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if le.StatusCode != http.StatusBadGateway {
 		t.Errorf("StatusCode = %d, want 502", le.StatusCode)
@@ -305,9 +305,9 @@ func TestHealth_2xxEmptyStatus_F23(t *testing.T) {
 	if err == nil {
 		t.Fatal("F23: expected error for 2xx with no status field")
 	}
-	var le *LLMError
+	var le *Error
 	if !errors.As(err, &le) {
-		t.Fatalf("F23: err = %v (%T), want *LLMError", err, err)
+		t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 	}
 	if !le.ProtocolError {
 		t.Errorf("F23: ProtocolError must be true")
@@ -322,7 +322,7 @@ func TestHealth_2xxEmptyStatus_F23(t *testing.T) {
 
 // TestHealth_2xxMalformedJSON — a 200 whose body is not parseable
 // JSON should surface as a parse error (not silently bucket as
-// success). We do not require a typed *LLMError here because the
+// success). We do not require a typed *Error here because the
 // JSON parser failure is a stdlib error; the operator just needs
 // the round-trip to fail visibly.
 func TestHealth_2xxMalformedJSON(t *testing.T) {
@@ -364,9 +364,9 @@ func TestHealth_204NoContent_F39(t *testing.T) {
 	if err == nil {
 		t.Fatal("F39: expected error for 204 No Content (no status field)")
 	}
-	var le *LLMError
+	var le *Error
 	if !errors.As(err, &le) {
-		t.Fatalf("F39: err = %v (%T), want *LLMError", err, err)
+		t.Fatalf("F39: err = %v (%T), want *Error", err, err)
 	}
 	if le.StatusCode != http.StatusNoContent {
 		t.Errorf("F39: StatusCode = %d, want 204", le.StatusCode)
@@ -398,9 +398,9 @@ func TestHealth_206PartialContent_F39(t *testing.T) {
 	if err == nil {
 		t.Fatal("F39: expected error for 206 without status field")
 	}
-	var le *LLMError
+	var le *Error
 	if !errors.As(err, &le) {
-		t.Fatalf("F39: err = %v (%T), want *LLMError", err, err)
+		t.Fatalf("F39: err = %v (%T), want *Error", err, err)
 	}
 	if le.StatusCode != http.StatusPartialContent {
 		t.Errorf("F39: StatusCode = %d, want 206", le.StatusCode)
@@ -15,11 +15,10 @@ package api
 // drawn from PRODUCT_REBOOT_PLAN.md §13 M3.
 //
 // All four helpers share the existing api.Client (Bearer auth, base
-// URL, default 60s timeout). Non-2xx responses fall through to
-// decodeMetiError which mirrors the M2 CRA error pattern: typed
-// MetiError + IsPermanent / IsTransient helpers so the CLI loop can
-// classify failures and surface the right exit code without sprinkling
-// status-code checks across the command layer.
+// URL, default 60s timeout). Non-2xx responses surface as the shared
+// *Error (errors.go) so the CLI loop can classify failures with
+// IsPermanent / IsTransient and surface the right exit code without
+// sprinkling status-code checks across the command layer.
 //
 // M1 / M2 fix patterns carried over to METI (regression coverage in
 // meti_test.go):
@@ -37,11 +36,9 @@ package api
 // data" must hold even when the data set is currently small.
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -110,6 +107,9 @@ type MetiAssessmentListFilter struct {
 // metiAssessmentListResponse mirrors handler.metiAssessmentListResponse.
 type metiAssessmentListResponse struct {
 	Assessments []MetiAssessment `json:"assessments"`
+	// NextCursor is followed only when the server declares
+	// FeatureCursorPagination (see Client.pageCursor).
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // MetiRefreshResult mirrors handler.metiRefreshResponse.
@@ -126,7 +126,7 @@ type MetiRefreshResult struct {
 	// server NEVER emits this on a 2xx response — when present it is
 	// the F23 protocol-violation signal: the handler returned HTTP 200
 	// but logically failed. The CLI promotes this to a transient
-	// *MetiError in RefreshAssessment so the exit-code path can flag
+	// *Error in RefreshAssessment so the exit-code path can flag
 	// the failure.
 	Error string `json:"error,omitempty"`
 }
@@ -177,110 +177,11 @@ type metiImprovementActionsResponse struct {
 	Actions []ImprovementAction `json:"actions"`
 }
 
-// ----------------------------------------------------------------------------
-// Error decoding
-// ----------------------------------------------------------------------------
-
-// MetiError is the typed error returned by the METI helpers when the
-// server emits a non-2xx response. Mirrors *CRAError so the CLI loop
-// can branch on the same IsPermanent / IsTransient surface.
-//
-// The METI handler has no AI / LLM dependency — there is no analog of
-// CRAError.IsAIDisabled() because the evaluator is fully local. F19 /
-// F25 are intentionally not in scope at the handler layer for the
-// same reason (see meti.go MetiHandler doc comment).
-type MetiError struct {
-	StatusCode int
-	URL        string
-	Method     string
-	Message    string // top-level "error" field
-	Raw        string // original body, preserved for debug logging
-
-	// ProtocolError flags a synthesised MetiError raised for a 2xx
-	// response that violated the success contract — server returned
-	// HTTP 200/201 but the body had no assessments slice, or carried
-	// an explicit "error" field. Callers inspect this via IsTransient
-	// (always true when set) so the CLI loop surfaces such bugs
-	// through the exit-4 transient path instead of silently bucketing
-	// the call as a success (mirrors M1 #F23).
-	ProtocolError bool
-}
-
-func (e *MetiError) Error() string {
-	if e.Message != "" {
-		return fmt.Sprintf("meti API %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
-	}
-	return fmt.Sprintf("meti API %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Raw)
-}
-
-// IsPermanent reports whether the error is a permanent 4xx that the
-// caller cannot retry without fixing config / input (auth / unknown
-// criterion / already-overridden). 429 is intentionally transient.
-//
-// 409 (re-override rejected: row already overridden) is treated as
-// permanent because the operator's correct response is "clear the
-// existing override first", NOT "retry tomorrow" — mirrors the CRA
-// 409 classification (no approved VEX draft → operator must triage
-// first).
-func (e *MetiError) IsPermanent() bool {
-	if e == nil {
-		return false
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return false
-	}
-	if e.StatusCode == http.StatusServiceUnavailable {
-		return false
-	}
-	return e.StatusCode >= 400 && e.StatusCode < 500
-}
-
-// IsTransient reports whether the error is a transient failure the
-// operator can resolve by retrying — 429 (rate limit) and 5xx (server
-// error / upstream blip). Symmetric with IsPermanent so the CLI loop
-// can categorise each failure into exactly one of: permanent,
-// transient, or unclassified.
-//
-// ProtocolError joins the transient bucket because the operator's
-// correct response is the same (retry, possibly after a server
-// redeploy) — mirrors M1 #F23.
-func (e *MetiError) IsTransient() bool {
-	if e == nil {
-		return false
-	}
-	if e.ProtocolError {
-		return true
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return true
-	}
-	return e.StatusCode >= 500 && e.StatusCode < 600
-}
-
-// decodeMetiError builds a MetiError from a non-2xx response body.
-// Tolerates non-JSON bodies (intermediate gateways sometimes send HTML
-// 502 pages) — Raw always carries the original text.
-func decodeMetiError(method, url string, status int, body []byte) *MetiError {
-	te := &MetiError{
-		StatusCode: status,
-		URL:        url,
-		Method:     method,
-		Raw:        string(body),
-	}
-	var parsed struct {
-		Error string `json:"error"`
-	}
-	if err := json.Unmarshal(body, &parsed); err == nil {
-		te.Message = parsed.Error
-	}
-	return te
-}
-
 // ----------------------------------------------------------------------------
 // GetAssessment — GET /api/v1/projects/:id/meti/assessment
 // ----------------------------------------------------------------------------
 
-// metiAssessmentsListPageSize is the per-page request size the CLI
+// MetiAssessmentsPageSize is the per-page request size the CLI
 // uses when paging /meti/assessment. M1 #F26 carry-over: the server
 // caps ?limit= at MaxMetiAssessmentsListLimit (500), so the CLI
 // requests pages at the maximum allowed size to minimise round-trips.
@@ -289,53 +190,35 @@ func decodeMetiError(method, url string, status int, body []byte) *MetiError {
 // so this loop will always complete in one page. The pagination logic
 // is wired anyway because the F26 invariant ("CLI never silently
 // truncates server-paginated data") must hold across the surface.
-const metiAssessmentsListPageSize = 500
+const MetiAssessmentsPageSize = 500
 
 // metiAssessmentsListMaxPages is a defensive ceiling on the number of
 // pages the CLI is willing to fetch. Tuned to match the server's
-// MaxMetiAssessmentsListOffset (10000) / metiAssessmentsListPageSize
+// MaxMetiAssessmentsListOffset (10000) / MetiAssessmentsPageSize
 // (500) so the loop cannot iterate past the server's offset clamp.
 const metiAssessmentsListMaxPages = 25
 
 // GetAssessment returns the project's METI assessment rows
 // (paginated). Returns the joined slice + the server's X-Total-Count
 // (M1 #F28 carried over) + an error.
-//
-// M1 #F26 carry-over: pages through using metiAssessmentsListPageSize
-// per request and stops when either:
-//   - a page returns fewer rows than the requested page size (canonical
-//     "no more rows" signal), or
-//   - metiAssessmentsListMaxPages is reached (defensive ceiling).
 func (c *Client) GetAssessment(ctx context.Context, projectID string, filter MetiAssessmentListFilter) ([]MetiAssessment, int, error) {
-	all := make([]MetiAssessment, 0, metiAssessmentsListPageSize)
-	offset := 0
-	total := 0
-	for page := 0; page < metiAssessmentsListMaxPages; page++ {
-		batch, pageTotal, err := c.getAssessmentPage(ctx, projectID, filter, metiAssessmentsListPageSize, offset)
-		if err != nil {
-			return nil, 0, err
-		}
-		all = append(all, batch...)
-		// X-Total-Count is stable across pages (it counts the matching
-		// filtered set, not the current page) — preserve the first
-		// page's value but accept later pages updating it if the
-		// server changes its mind mid-walk.
-		if pageTotal > 0 {
-			total = pageTotal
-		}
-		if len(batch) < metiAssessmentsListPageSize {
-			return all, total, nil
-		}
-		offset += len(batch)
-	}
-	return nil, 0, fmt.Errorf("meti/assessment ページング上限 (%d ページ × %d 件) 到達: サーバーがページング終端を返していない可能性があります",
-		metiAssessmentsListMaxPages, metiAssessmentsListPageSize)
+	p := c.PaginateAssessment(projectID, filter)
+	all, err := p.All(ctx)
+	return all, p.Total(), err
 }
 
-// getAssessmentPage fetches a single (limit, offset) page from the
-// server. Extracted from GetAssessment so the paging loop stays
-// readable and so tests can drive the per-page decode independently.
-func (c *Client) getAssessmentPage(ctx context.Context, projectID string, filter MetiAssessmentListFilter, limit, offset int) ([]MetiAssessment, int, error) {
+// PaginateAssessment walks the assessment rows matching filter lazily,
+// MetiAssessmentsPageSize rows per request.
+func (c *Client) PaginateAssessment(projectID string, filter MetiAssessmentListFilter) *Paginator[MetiAssessment] {
+	return NewPaginator("meti/assessment", MetiAssessmentsPageSize, metiAssessmentsListMaxPages,
+		func(ctx context.Context, req PageRequest) (Page[MetiAssessment], error) {
+			return c.GetAssessmentPage(ctx, projectID, filter, req)
+		})
+}
+
+// GetAssessmentPage fetches the single page req describes. Page.Total
+// is X-Total-Count (M1 #F28 carry-over).
+func (c *Client) GetAssessmentPage(ctx context.Context, projectID string, filter MetiAssessmentListFilter, req PageRequest) (Page[MetiAssessment], error) {
 	endpoint := fmt.Sprintf("%s/api/v1/projects/%s/meti/assessment", c.baseURL, projectID)
 
 	q := url.Values{}
@@ -352,49 +235,27 @@ func (c *Client) getAssessmentPage(ctx context.Context, projectID string, filter
 			q.Set("has_override", "false")
 		}
 	}
-	q.Set("limit", strconv.Itoa(limit))
-	if offset > 0 {
-		q.Set("offset", strconv.Itoa(offset))
-	}
+	setPageQuery(q, req)
 	endpoint = endpoint + "?" + q.Encode()
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodGet,
+		url:         endpoint,
+		decodeError: serviceDecoder(serviceMETI),
+	})
 	if err != nil {
-		return nil, 0, err
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, 0, fmt.Errorf("meti/assessment リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, 0, decodeMetiError(http.MethodGet, endpoint, resp.StatusCode, respBody)
+		return Page[MetiAssessment]{}, err
 	}
+	respBody := resp.Body
 
 	var out metiAssessmentListResponse
 	if err := json.Unmarshal(respBody, &out); err != nil {
-		return nil, 0, fmt.Errorf("meti/assessment レスポンス解析エラー: %w", err)
+		return Page[MetiAssessment]{}, fmt.Errorf("meti/assessment レスポンス解析エラー: %w", err)
 	}
 	if out.Assessments == nil {
 		out.Assessments = []MetiAssessment{}
 	}
-
-	// M1 #F28 carry-over: X-Total-Count is the source of truth for
-	// the matching filtered count. Falls back to 0 (caller treats 0
-	// as "no count available") when the header is missing or
-	// unparseable so a legacy server cannot break the page loop.
-	total := 0
-	if v := resp.Header.Get("X-Total-Count"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			total = n
-		}
-	}
-
-	return out.Assessments, total, nil
+	return Page[MetiAssessment]{Items: out.Assessments, Total: totalCount(resp.Header), NextCursor: c.pageCursor(out.NextCursor)}, nil
 }
 
 // ----------------------------------------------------------------------------
@@ -407,7 +268,7 @@ func (c *Client) getAssessmentPage(ctx context.Context, projectID string, filter
 // operator's manual verdict survives a refresh cycle.
 //
 // F23 carry-over: a 2xx response with an "error" field set or a nil
-// Assessments slice is surfaced as a transient *MetiError so the CLI
+// Assessments slice is surfaced as a transient *Error so the CLI
 // exit-4 path catches such bugs rather than silently green-lighting a
 // no-op refresh.
 func (c *Client) RefreshAssessment(ctx context.Context, projectID string) (*MetiRefreshResult, error) {
@@ -417,23 +278,18 @@ func (c *Client) RefreshAssessment(ctx context.Context, projectID string) (*Meti
 	// the fan-out reads the project's evidence directly. We send an
 	// empty JSON object so any future server-side body validation
 	// (e.g. "{requested_evaluator_version}") finds a parseable payload.
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader([]byte("{}")))
+	resp, err := c.send(ctx, apiRequest{
+		method:         http.MethodPost,
+		url:            endpoint,
+		body:           []byte("{}"),
+		okStatus:       []int{http.StatusOK, http.StatusCreated},
+		decodeError:    serviceDecoder(serviceMETI),
+		idempotencyKey: true,
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("meti/assessment/refresh リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, decodeMetiError(http.MethodPost, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	return decodeRefreshResult(http.MethodPost, endpoint, resp.StatusCode, respBody)
 }
@@ -451,7 +307,8 @@ func decodeRefreshResult(method, endpoint string, status int, body []byte) (*Met
 	}
 
 	if out.Error != "" {
-		return nil, &MetiError{
+		return nil, &Error{
+			Service:       serviceMETI,
 			StatusCode:    status,
 			URL:           endpoint,
 			Method:        method,
@@ -461,7 +318,8 @@ func decodeRefreshResult(method, endpoint string, status int, body []byte) (*Met
 		}
 	}
 	if out.Assessments == nil {
-		return nil, &MetiError{
+		return nil, &Error{
+			Service:       serviceMETI,
 			StatusCode:    status,
 			URL:           endpoint,
 			Method:        method,
@@ -503,23 +361,16 @@ func (c *Client) OverrideCriterion(ctx context.Context, projectID, criterionID s
 		return nil, fmt.Errorf("meti override リクエストのシリアライズに失敗: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodPut,
+		url:         endpoint,
+		body:        body,
+		decodeError: serviceDecoder(serviceMETI),
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("meti override リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, decodeMetiError(http.MethodPut, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	var out MetiAssessment
 	if err := json.Unmarshal(respBody, &out); err != nil {
@@ -529,7 +380,8 @@ func (c *Client) OverrideCriterion(ctx context.Context, projectID, criterionID s
 		// F23 carry-over: 2xx with empty body is a server contract
 		// violation — surface as ProtocolError / transient so the CLI
 		// retries instead of silently confirming a no-op override.
-		return nil, &MetiError{
+		return nil, &Error{
+			Service:       serviceMETI,
 			StatusCode:    resp.StatusCode,
 			URL:           endpoint,
 			Method:        http.MethodPut,
@@ -585,24 +437,14 @@ func (c *Client) ClearOverrideCriterion(ctx context.Context, projectID, criterio
 		return fmt.Errorf("meti clear-override リクエストのシリアライズに失敗: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return err
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("meti clear-override リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return decodeMetiError(http.MethodDelete, endpoint, resp.StatusCode, respBody)
-	}
-	return nil
+	_, err = c.send(ctx, apiRequest{
+		method:      http.MethodDelete,
+		url:         endpoint,
+		body:        body,
+		okStatus:    []int{http.StatusOK, http.StatusNoContent},
+		decodeError: serviceDecoder(serviceMETI),
+	})
+	return err
 }
 
 // ----------------------------------------------------------------------------
@@ -622,22 +464,15 @@ func (c *Client) ClearOverrideCriterion(ctx context.Context, projectID, criterio
 func (c *Client) GetImprovementActions(ctx context.Context, projectID string) ([]ImprovementAction, int, error) {
 	endpoint := fmt.Sprintf("%s/api/v1/projects/%s/meti/improvement-actions", c.baseURL, projectID)
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodGet,
+		url:         endpoint,
+		decodeError: serviceDecoder(serviceMETI),
+	})
 	if err != nil {
 		return nil, 0, err
 	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, 0, fmt.Errorf("meti/improvement-actions リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, 0, decodeMetiError(http.MethodGet, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	var out metiImprovementActionsResponse
 	if err := json.Unmarshal(respBody, &out); err != nil {
@@ -268,9 +268,9 @@ func TestGetAssessment_PermanentClientError(t *testing.T) {
 			defer server.Close()
 			client := NewClient(server.URL, "k")
 			_, _, err := client.GetAssessment(context.Background(), "p", MetiAssessmentListFilter{})
-			var me *MetiError
+			var me *Error
 			if !errors.As(err, &me) {
-				t.Fatalf("err = %v, want *MetiError", err)
+				t.Fatalf("err = %v, want *Error", err)
 			}
 			if got := me.IsPermanent(); got != tc.want {
 				t.Errorf("IsPermanent() = %v, want %v", got, tc.want)
@@ -307,7 +307,7 @@ func TestMetiError_Classification_F22(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			me := &MetiError{StatusCode: tc.code, Message: tc.message, ProtocolError: tc.wantProto}
+			me := &Error{Service: serviceMETI, StatusCode: tc.code, Message: tc.message, ProtocolError: tc.wantProto}
 			if got := me.IsPermanent(); got != tc.wantPerm {
 				t.Errorf("IsPermanent = %v, want %v", got, tc.wantPerm)
 			}
@@ -369,9 +369,9 @@ func TestRefreshAssessment_2xxWithErrorField_F23(t *testing.T) {
 	if err == nil {
 		t.Fatal("F23: expected error for 2xx with error field")
 	}
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("F23: err = %v (%T), want *MetiError", err, err)
+		t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 	}
 	if !me.IsTransient() {
 		t.Errorf("F23: 2xx + error field must classify transient")
@@ -394,9 +394,9 @@ func TestRefreshAssessment_2xxEmptyAssessments_F23(t *testing.T) {
 	if err == nil {
 		t.Fatal("F23: expected error for 2xx with empty body")
 	}
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("F23: err = %v (%T), want *MetiError", err, err)
+		t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 	}
 	if !me.IsTransient() {
 		t.Errorf("F23: protocol error must classify transient")
@@ -417,9 +417,9 @@ func TestRefreshAssessment_403Permanent(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	_, err := client.RefreshAssessment(context.Background(), "p")
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("err = %v, want *MetiError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if !me.IsPermanent() {
 		t.Errorf("403 must classify permanent")
@@ -519,9 +519,9 @@ func TestOverrideCriterion_Conflict_F31_Permanent(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	_, err := client.OverrideCriterion(context.Background(), "p", "c1", MetiOverrideRequest{OverrideStatus: "achieved"})
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("err = %v, want *MetiError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if !me.IsPermanent() {
 		t.Errorf("409 (F31 already-overridden) must classify permanent")
@@ -539,9 +539,9 @@ func TestOverrideCriterion_404Permanent(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	_, err := client.OverrideCriterion(context.Background(), "p", "c1", MetiOverrideRequest{OverrideStatus: "achieved"})
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("err = %v, want *MetiError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if !me.IsPermanent() {
 		t.Errorf("404 must classify permanent")
@@ -582,9 +582,9 @@ func TestOverrideCriterion_2xxEmptyBody_F23(t *testing.T) {
 	if err == nil {
 		t.Fatal("F23: expected error for 2xx with empty body")
 	}
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("F23: err = %v (%T), want *MetiError", err, err)
+		t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 	}
 	if !me.IsTransient() {
 		t.Errorf("F23: empty body must classify transient")
@@ -721,9 +721,9 @@ func TestClearOverrideCriterion_404Permanent(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	err := client.ClearOverrideCriterion(context.Background(), "p", "c1", MetiClearOverrideRequest{Note: "ok"})
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("err = %v, want *MetiError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if !me.IsPermanent() {
 		t.Errorf("404 must classify permanent")
@@ -744,9 +744,9 @@ func TestClearOverrideCriterion_400NoteRejected(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	err := client.ClearOverrideCriterion(context.Background(), "p", "c1", MetiClearOverrideRequest{Note: "ok"})
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("err = %v, want *MetiError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if !me.IsPermanent() {
 		t.Errorf("400 (note validation) must classify permanent")
@@ -763,9 +763,9 @@ func TestClearOverrideCriterion_403Permanent(t *testing.T) {
 	defer server.Close()
 	client := NewClient(server.URL, "k")
 	err := client.ClearOverrideCriterion(context.Background(), "p", "c1", MetiClearOverrideRequest{Note: "ok"})
-	var me *MetiError
+	var me *Error
 	if !errors.As(err, &me) {
-		t.Fatalf("err = %v, want *MetiError", err)
+		t.Fatalf("err = %v, want *Error", err)
 	}
 	if !me.IsPermanent() {
 		t.Errorf("403 must classify permanent")
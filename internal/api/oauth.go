@@ -0,0 +1,334 @@
+package api
+
+// OAuth 2.0 device login.
+//
+// `sbomhub login --device` replaces pasting a long-lived sbh_ key with
+// the device authorization grant (RFC 8628): the CLI shows a short code,
+// the operator approves it in a browser (with SSO / MFA, whatever the
+// server or its IdP enforces), and the CLI receives a short-lived access
+// token plus a refresh token. A TokenSource then keeps the access token
+// fresh: proactively when it is about to expire, and once more when the
+// server answers 401 anyway (revoked early, clock skew).
+//
+// ※要確認: the SBOMHub server is assumed to publish RFC 8414 metadata at
+// /.well-known/oauth-authorization-server (or OIDC discovery at
+// /.well-known/openid-configuration) describing either its own device
+// endpoints or those of the IdP it delegates to, and to accept the
+// resulting access token as a Bearer token on /api/v1. The public client
+// ID defaults to "sbomhub-cli".
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultOAuthClientID is the public client the CLI registers as.
+const DefaultOAuthClientID = "sbomhub-cli"
+
+// deviceCodeGrant is the RFC 8628 grant type.
+const deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+// tokenExpirySkew refreshes an access token this long before it
+// expires, so a request does not leave with a token that dies in flight.
+const tokenExpirySkew = 30 * time.Second
+
+// OAuthEndpoints is the part of the authorization server metadata the
+// device flow needs.
+type OAuthEndpoints struct {
+	Issuer                      string `json:"issuer"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+}
+
+// DeviceAuthorization is the device authorization response (RFC 8628
+// §3.2): what to show the operator and how often to poll.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	// VerificationURL is the pre-RFC name some IdPs still send.
+	VerificationURL string `json:"verification_url,omitempty"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval,omitempty"`
+}
+
+// Token is an OAuth access token with the refresh token that renews it.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Expired reports whether the token is expired or about to be. A token
+// without an expiry never expires client-side; the server decides.
+func (t Token) Expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Add(tokenExpirySkew).Before(t.Expiry)
+}
+
+// OAuthError is an error response from the token or device authorization
+// endpoint (RFC 6749 §5.2).
+type OAuthError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("OAuth エラー (%d): %s — %s", e.StatusCode, e.Code, e.Description)
+	}
+	return fmt.Sprintf("OAuth エラー (%d): %s", e.StatusCode, e.Code)
+}
+
+// IsRetryable reports whether the endpoint failed transiently (5xx).
+// Protocol answers such as invalid_grant are never retried.
+func (e *OAuthError) IsRetryable() bool {
+	return e.StatusCode >= 500
+}
+
+func decodeOAuthError(method, url string, status int, body []byte) error {
+	e := &OAuthError{StatusCode: status}
+	if json.Unmarshal(body, e) != nil || e.Code == "" {
+		e.Code = http.StatusText(status)
+		e.Description = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// DiscoverOAuth fetches the authorization server metadata of issuer, or
+// of the SBOMHub server itself when issuer is empty. RFC 8414 metadata is
+// tried first, then OIDC discovery.
+func (c *Client) DiscoverOAuth(ctx context.Context, issuer string) (*OAuthEndpoints, error) {
+	if issuer == "" {
+		issuer = c.baseURL
+	}
+	issuer = strings.TrimRight(issuer, "/")
+	var lastErr error
+	for _, path := range []string{"/.well-known/oauth-authorization-server", "/.well-known/openid-configuration"} {
+		resp, err := c.send(ctx, apiRequest{method: http.MethodGet, url: issuer + path, noAuth: true})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var ep OAuthEndpoints
+		if err := json.Unmarshal(resp.Body, &ep); err != nil {
+			lastErr = fmt.Errorf("%s の解析に失敗しました: %w", issuer+path, err)
+			continue
+		}
+		if ep.TokenEndpoint == "" {
+			return nil, fmt.Errorf("%s のメタデータに token_endpoint がありません", issuer)
+		}
+		return &ep, nil
+	}
+	return nil, fmt.Errorf("OAuth メタデータを取得できませんでした (%s): %w", issuer, lastErr)
+}
+
+// StartDeviceAuthorization requests a device and user code.
+func (c *Client) StartDeviceAuthorization(ctx context.Context, ep *OAuthEndpoints, clientID, scope string) (*DeviceAuthorization, error) {
+	if ep.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("%s はデバイス認可フロー (device_authorization_endpoint) に対応していません", ep.Issuer)
+	}
+	form := url.Values{"client_id": {clientID}}
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+	resp, err := c.postForm(ctx, ep.DeviceAuthorizationEndpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	var da DeviceAuthorization
+	if err := json.Unmarshal(resp.Body, &da); err != nil {
+		return nil, fmt.Errorf("デバイス認可レスポンスの解析に失敗しました: %w", err)
+	}
+	if da.VerificationURI == "" {
+		da.VerificationURI = da.VerificationURL
+	}
+	if da.DeviceCode == "" || da.UserCode == "" || da.VerificationURI == "" {
+		return nil, fmt.Errorf("デバイス認可レスポンスに device_code / user_code / verification_uri がありません")
+	}
+	return &da, nil
+}
+
+// PollDeviceToken polls the token endpoint at the server's interval until
+// the operator approves or denies the request, or the code expires.
+func (c *Client) PollDeviceToken(ctx context.Context, ep *OAuthEndpoints, clientID string, da *DeviceAuthorization) (*Token, error) {
+	interval := time.Duration(da.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(da.ExpiresIn) * time.Second)
+	form := url.Values{
+		"grant_type":  {deviceCodeGrant},
+		"device_code": {da.DeviceCode},
+		"client_id":   {clientID},
+	}
+	for {
+		if err := c.sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+		tok, err := c.requestToken(ctx, ep.TokenEndpoint, form)
+		if err == nil {
+			return tok, nil
+		}
+		var oe *OAuthError
+		switch {
+		case errors.As(err, &oe) && oe.Code == "authorization_pending":
+		case errors.As(err, &oe) && oe.Code == "slow_down":
+			interval += 5 * time.Second
+		case errors.As(err, &oe) && oe.Code == "access_denied":
+			return nil, fmt.Errorf("ログインが拒否されました: %w", err)
+		case errors.As(err, &oe) && oe.Code == "expired_token":
+			return nil, fmt.Errorf("確認コードの有効期限が切れました。 もう一度 login を実行してください: %w", err)
+		case isRetryable(err):
+			// A blip while waiting for the operator is not fatal.
+		default:
+			return nil, err
+		}
+		if da.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, fmt.Errorf("確認コードの有効期限 (%d 秒) が切れました。 もう一度 login を実行してください", da.ExpiresIn)
+		}
+	}
+}
+
+// RefreshOAuthToken exchanges refreshToken for a new access token. When
+// the server does not rotate the refresh token, the old one is kept.
+func (c *Client) RefreshOAuthToken(ctx context.Context, tokenURL, clientID, refreshToken string) (*Token, error) {
+	tok, err := c.requestToken(ctx, tokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {clientID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = refreshToken
+	}
+	return tok, nil
+}
+
+// requestToken posts form to the token endpoint.
+func (c *Client) requestToken(ctx context.Context, tokenURL string, form url.Values) (*Token, error) {
+	resp, err := c.postForm(ctx, tokenURL, form)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("トークンレスポンスの解析に失敗しました: %w", err)
+	}
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("トークンレスポンスに access_token がありません")
+	}
+	tok := &Token{AccessToken: body.AccessToken, TokenType: body.TokenType, RefreshToken: body.RefreshToken}
+	if body.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+// postForm sends an unauthenticated form POST to an OAuth endpoint. It
+// is marked idempotent so a 5xx / network failure is retried: a device
+// code or refresh token exchange that never completed can be repeated.
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values) (*apiResponse, error) {
+	return c.send(ctx, apiRequest{
+		method:      http.MethodPost,
+		url:         endpoint,
+		body:        []byte(form.Encode()),
+		contentType: "application/x-www-form-urlencoded",
+		noAuth:      true,
+		idempotent:  true,
+		decodeError: decodeOAuthError,
+	})
+}
+
+// TokenSource supplies the bearer token in place of a static API key.
+// Token is called before every request; Refresh after the server
+// rejected the token Token returned, to obtain a new one.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Refresh(ctx context.Context, rejected string) (string, error)
+}
+
+// SetTokenSource makes the client authenticate with tokens from ts
+// instead of its API key; nil restores the API key.
+func (c *Client) SetTokenSource(ts TokenSource) {
+	c.tokens = ts
+}
+
+// OAuthTokenSource is a TokenSource backed by a refresh token. It is
+// safe for concurrent use; concurrent requests that all hit a 401
+// trigger a single refresh.
+type OAuthTokenSource struct {
+	mu        sync.Mutex
+	c         *Client
+	tokenURL  string
+	clientID  string
+	tok       Token
+	onRefresh func(Token)
+	now       func() time.Time
+}
+
+// NewOAuthTokenSource returns a source starting from tok and refreshing
+// through tokenURL with c (which supplies TLS / proxy / retry settings).
+// onRefresh, when set, is called with every new token so the caller can
+// persist it.
+func NewOAuthTokenSource(c *Client, tokenURL, clientID string, tok Token, onRefresh func(Token)) *OAuthTokenSource {
+	return &OAuthTokenSource{c: c, tokenURL: tokenURL, clientID: clientID, tok: tok, onRefresh: onRefresh, now: time.Now}
+}
+
+// Token returns the access token, refreshing it first when it is about
+// to expire.
+func (s *OAuthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok.Expired(s.now()) && s.tok.RefreshToken != "" {
+		if err := s.refreshLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+	return s.tok.AccessToken, nil
+}
+
+// Refresh renews the access token unless another request already
+// replaced the rejected one.
+func (s *OAuthTokenSource) Refresh(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok.AccessToken != rejected {
+		return s.tok.AccessToken, nil
+	}
+	if err := s.refreshLocked(ctx); err != nil {
+		return "", err
+	}
+	return s.tok.AccessToken, nil
+}
+
+func (s *OAuthTokenSource) refreshLocked(ctx context.Context) error {
+	if s.tok.RefreshToken == "" {
+		return fmt.Errorf("リフレッシュトークンがありません")
+	}
+	tok, err := s.c.RefreshOAuthToken(ctx, s.tokenURL, s.clientID, s.tok.RefreshToken)
+	if err != nil {
+		return fmt.Errorf("アクセストークンの更新に失敗しました: %w", err)
+	}
+	s.tok = *tok
+	if s.onRefresh != nil {
+		s.onRefresh(*tok)
+	}
+	return nil
+}
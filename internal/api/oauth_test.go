@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAuthServer is an authorization server plus one protected API
+// endpoint. Access tokens are "access-<n>"; only the latest is accepted.
+type fakeAuthServer struct {
+	mu       sync.Mutex
+	pending  int // authorization_pending answers before approval
+	issued   int
+	refresh  string // the refresh token the server accepts
+	denied   bool
+	apiCalls []string // Authorization headers seen on /api/v1/cli/projects
+}
+
+func (f *fakeAuthServer) handler(base func() string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issuer":%q,"device_authorization_endpoint":%q,"token_endpoint":%q}`,
+			base(), base()+"/oauth/device", base()+"/oauth/token")
+	})
+	mux.HandleFunc("POST /oauth/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			http.Error(w, "credentials sent to the device endpoint", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"device_code":"dev-1","user_code":"ABCD-EFGH","verification_uri":"https://sso.example.com/device","expires_in":600,"interval":1}`)
+	})
+	mux.HandleFunc("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		oauthErr := func(code string) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error":%q}`, code)
+		}
+		switch r.PostForm.Get("grant_type") {
+		case deviceCodeGrant:
+			if f.denied {
+				oauthErr("access_denied")
+				return
+			}
+			if f.pending > 0 {
+				f.pending--
+				oauthErr("authorization_pending")
+				return
+			}
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != f.refresh {
+				oauthErr("invalid_grant")
+				return
+			}
+		default:
+			oauthErr("unsupported_grant_type")
+			return
+		}
+		f.issued++
+		f.refresh = fmt.Sprintf("refresh-%d", f.issued)
+		fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","refresh_token":%q,"expires_in":3600}`, f.issued, f.refresh)
+	})
+	mux.HandleFunc("GET /api/v1/cli/projects", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		auth := r.Header.Get("Authorization")
+		f.apiCalls = append(f.apiCalls, auth)
+		if auth != fmt.Sprintf("Bearer access-%d", f.issued) {
+			http.Error(w, `{"error":"token expired"}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	})
+	return mux
+}
+
+func newFakeAuthServer(t *testing.T, f *fakeAuthServer) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(f.handler(func() string { return server.URL }))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDeviceFlow(t *testing.T) {
+	f := &fakeAuthServer{pending: 2}
+	server := newFakeAuthServer(t, f)
+	client := NewClient(server.URL, "")
+	waits := recordSleeps(client)
+	ctx := context.Background()
+
+	ep, err := client.DiscoverOAuth(ctx, "")
+	if err != nil {
+		t.Fatalf("DiscoverOAuth() = %v", err)
+	}
+	da, err := client.StartDeviceAuthorization(ctx, ep, DefaultOAuthClientID, "")
+	if err != nil {
+		t.Fatalf("StartDeviceAuthorization() = %v", err)
+	}
+	if da.UserCode != "ABCD-EFGH" {
+		t.Errorf("UserCode = %q", da.UserCode)
+	}
+	tok, err := client.PollDeviceToken(ctx, ep, DefaultOAuthClientID, da)
+	if err != nil {
+		t.Fatalf("PollDeviceToken() = %v", err)
+	}
+	if tok.AccessToken != "access-1" || tok.RefreshToken != "refresh-1" || tok.Expiry.IsZero() {
+		t.Errorf("token = %+v", tok)
+	}
+	if len(*waits) != 3 || (*waits)[0] != time.Second {
+		t.Errorf("poll waits = %v, want 3 × 1s (two pending answers, then success)", *waits)
+	}
+}
+
+func TestDeviceFlow_Denied(t *testing.T) {
+	f := &fakeAuthServer{denied: true}
+	server := newFakeAuthServer(t, f)
+	client := NewClient(server.URL, "")
+	recordSleeps(client)
+	ctx := context.Background()
+
+	ep, err := client.DiscoverOAuth(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.PollDeviceToken(ctx, ep, DefaultOAuthClientID, &DeviceAuthorization{DeviceCode: "dev-1", ExpiresIn: 600, Interval: 1})
+	var oe *OAuthError
+	if !errors.As(err, &oe) || oe.Code != "access_denied" {
+		t.Fatalf("PollDeviceToken() = %v, want access_denied", err)
+	}
+}
+
+// TestTokenSource_RefreshesOn401 has the server reject a token the client
+// still believes valid; the request must be replayed once with a
+// refreshed token and succeed, and the new token must be handed to
+// onRefresh for persisting.
+func TestTokenSource_RefreshesOn401(t *testing.T) {
+	f := &fakeAuthServer{issued: 2, refresh: "refresh-2"}
+	server := newFakeAuthServer(t, f)
+	client := NewClient(server.URL, "")
+	client.SetRetryPolicy(NoRetry)
+
+	var persisted []Token
+	stale := Token{AccessToken: "access-1", RefreshToken: "refresh-2", Expiry: time.Now().Add(time.Hour)}
+	client.SetTokenSource(NewOAuthTokenSource(client, server.URL+"/oauth/token", DefaultOAuthClientID, stale,
+		func(tok Token) { persisted = append(persisted, tok) }))
+
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects() = %v, want success after refresh", err)
+	}
+	if got := strings.Join(f.apiCalls, ","); got != "Bearer access-1,Bearer access-3" {
+		t.Errorf("Authorization headers = %s", got)
+	}
+	if len(persisted) != 1 || persisted[0].AccessToken != "access-3" || persisted[0].RefreshToken != "refresh-3" {
+		t.Errorf("persisted = %+v", persisted)
+	}
+}
+
+func TestTokenSource_RefreshesExpiredTokenUpFront(t *testing.T) {
+	f := &fakeAuthServer{issued: 1, refresh: "refresh-1"}
+	server := newFakeAuthServer(t, f)
+	client := NewClient(server.URL, "")
+
+	expired := Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(-time.Minute)}
+	client.SetTokenSource(NewOAuthTokenSource(client, server.URL+"/oauth/token", DefaultOAuthClientID, expired, nil))
+
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects() = %v", err)
+	}
+	if len(f.apiCalls) != 1 || f.apiCalls[0] != "Bearer access-2" {
+		t.Errorf("Authorization headers = %v, want a single call with the refreshed token", f.apiCalls)
+	}
+}
+
+// TestTokenSource_RevokedRefreshSurfaces401 checks that when the refresh
+// token is dead too, the caller gets the original typed 401 (so exit
+// codes stay "permanent") with the refresh failure attached.
+func TestTokenSource_RevokedRefreshSurfaces401(t *testing.T) {
+	f := &fakeAuthServer{issued: 5, refresh: "refresh-5"}
+	server := newFakeAuthServer(t, f)
+	client := NewClient(server.URL, "")
+	client.SetRetryPolicy(NoRetry)
+
+	revoked := Token{AccessToken: "access-1", RefreshToken: "refresh-1"}
+	client.SetTokenSource(NewOAuthTokenSource(client, server.URL+"/oauth/token", DefaultOAuthClientID, revoked, nil))
+
+	_, err := client.ListProjects(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("ListProjects() = %v, want *Error 401", err)
+	}
+	if !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("error %q does not mention the refresh failure", err)
+	}
+	if len(f.apiCalls) != 1 {
+		t.Errorf("API calls = %d, want 1 (no replay without a new token)", len(f.apiCalls))
+	}
+}
@@ -0,0 +1,227 @@
+package api
+
+// Pagination.
+//
+// Every list endpoint used to carry its own limit / offset loop:
+// ListVulnerabilities, ListReports and GetAssessment each re-implemented
+// the short-page check, the max-page ceiling and X-Total-Count handling
+// (and disagreed on whether hitting the ceiling returned the partial
+// result), while ListProjects did not page at all. Paginator is the one
+// loop; each endpoint only supplies a PageFunc that fetches and decodes
+// a single page.
+//
+// A Paginator is lazy: nothing is fetched until Next / NextPage is
+// called, so a command can render the first page while the server is
+// still producing the rest.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// PageRequest says where the next page starts. Offset is always kept up
+// to date; Cursor is set once the server has handed one out, and then
+// takes precedence over Offset on the wire (see setPageQuery).
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// Page is one decoded page.
+type Page[T any] struct {
+	Items []T
+	// Total is the size of the whole (filtered) result as reported by
+	// the server, or 0 when it did not say.
+	Total int
+	// NextCursor is the opaque position of the next page. A non-empty
+	// value keeps the walk going regardless of the page's length.
+	NextCursor string
+	// Last lets a PageFunc end the walk on its own knowledge, e.g. a
+	// response shape that proves the server ignored ?limit=.
+	Last bool
+}
+
+// PageFunc fetches the page described by req.
+type PageFunc[T any] func(ctx context.Context, req PageRequest) (Page[T], error)
+
+// PageLimitError is returned when a walk reaches its page ceiling while
+// the server still reports more rows. It is a runaway guard, not a
+// product limit: the ceilings sit far above any realistic project, so
+// hitting one means the server keeps returning full pages (ignoring
+// ?offset=, or looping a cursor). The rows fetched so far are returned
+// alongside it so the caller can decide whether to act on them.
+type PageLimitError struct {
+	Endpoint string
+	Pages    int
+	PageSize int
+}
+
+func (e *PageLimitError) Error() string {
+	return fmt.Sprintf("%s ページング上限 (%d ページ × %d 件) 到達: サーバーがページング終端を返していない可能性があります",
+		e.Endpoint, e.Pages, e.PageSize)
+}
+
+// Paginator walks a list endpoint page by page. It is not safe for
+// concurrent use.
+type Paginator[T any] struct {
+	endpoint string
+	fetch    PageFunc[T]
+	maxPages int
+
+	next  PageRequest
+	pages int
+	total int
+	done  bool
+	err   error
+
+	buf []T
+	cur T
+}
+
+// NewPaginator returns a paginator requesting pageSize rows at a time
+// and giving up with *PageLimitError after maxPages full pages. endpoint
+// only labels that error.
+func NewPaginator[T any](endpoint string, pageSize, maxPages int, fetch PageFunc[T]) *Paginator[T] {
+	return &Paginator[T]{
+		endpoint: endpoint,
+		fetch:    fetch,
+		maxPages: maxPages,
+		next:     PageRequest{Limit: pageSize},
+	}
+}
+
+// NextPage fetches the next page. It returns false once the walk is
+// over, either at the end of the data or on error (see Err). Items
+// already buffered by Next are not returned again.
+func (p *Paginator[T]) NextPage(ctx context.Context) ([]T, bool) {
+	if p.done || p.err != nil {
+		return nil, false
+	}
+	if p.pages == p.maxPages {
+		p.err = &PageLimitError{Endpoint: p.endpoint, Pages: p.maxPages, PageSize: p.next.Limit}
+		return nil, false
+	}
+	page, err := p.fetch(ctx, p.next)
+	if err != nil {
+		p.err = err
+		return nil, false
+	}
+	p.pages++
+	if page.Total > 0 {
+		// X-Total-Count counts the whole filtered set, so it is stable
+		// across pages; a later page may still update it if rows were
+		// added or removed mid-walk.
+		p.total = page.Total
+	}
+	n := len(page.Items)
+	inCursorWalk := p.next.Cursor != ""
+	p.next.Offset += n
+	p.next.Cursor = page.NextCursor
+	switch {
+	case page.Last:
+		p.done = true
+	case page.NextCursor != "":
+		// The server drives the walk; page length says nothing.
+	case inCursorWalk:
+		// ... and ends it by not handing out another cursor.
+		p.done = true
+	case n < p.next.Limit:
+		// A short page is the canonical end of an offset walk.
+		p.done = true
+	case n > p.next.Limit:
+		// More rows than asked for: the server ignored ?limit= and
+		// returned everything. Asking for the next offset would only
+		// repeat rows.
+		p.done = true
+	case p.total > 0 && p.next.Offset >= p.total:
+		// Saves the trailing empty request when the total is an exact
+		// multiple of the page size.
+		p.done = true
+	}
+	return page.Items, true
+}
+
+// Next advances to the next row, fetching pages as needed.
+func (p *Paginator[T]) Next(ctx context.Context) bool {
+	for len(p.buf) == 0 {
+		items, ok := p.NextPage(ctx)
+		if !ok {
+			return false
+		}
+		p.buf = items
+	}
+	p.cur, p.buf = p.buf[0], p.buf[1:]
+	return true
+}
+
+// Value returns the row Next advanced to.
+func (p *Paginator[T]) Value() T {
+	return p.cur
+}
+
+// Err returns the error that ended the walk, nil at a clean end.
+func (p *Paginator[T]) Err() error {
+	return p.err
+}
+
+// Total returns the latest server-reported total, 0 when unknown.
+func (p *Paginator[T]) Total() int {
+	return p.total
+}
+
+// All drains the remaining rows. The slice is never nil, and on error it
+// holds the rows fetched before the failure.
+func (p *Paginator[T]) All(ctx context.Context) ([]T, error) {
+	all := make([]T, 0, p.next.Limit)
+	all = append(all, p.buf...)
+	p.buf = nil
+	for {
+		items, ok := p.NextPage(ctx)
+		if !ok {
+			return all, p.err
+		}
+		all = append(all, items...)
+	}
+}
+
+// setPageQuery adds req to q; a zero Limit leaves the page size to the
+// server. A cursor replaces the offset: cursor servers treat the two as
+// alternatives, and sending both would let an older handler silently
+// prefer the offset. req only holds a cursor that pageCursor let
+// through.
+func setPageQuery(q url.Values, req PageRequest) {
+	if req.Limit > 0 {
+		q.Set("limit", strconv.Itoa(req.Limit))
+	}
+	switch {
+	case req.Cursor != "":
+		q.Set("cursor", req.Cursor)
+	case req.Offset > 0:
+		q.Set("offset", strconv.Itoa(req.Offset))
+	}
+}
+
+// pageCursor returns the next_cursor a list response carried, or "" when
+// the server has not declared FeatureCursorPagination. Dropping it keeps
+// the walk on limit / offset, the only paging every server implements.
+func (c *Client) pageCursor(next string) string {
+	if !c.caps.Declares(FeatureCursorPagination) {
+		return ""
+	}
+	return next
+}
+
+// totalCount reads X-Total-Count, 0 when absent or unparseable so a
+// legacy server cannot break the walk.
+func totalCount(h http.Header) int {
+	if v := h.Get("X-Total-Count"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
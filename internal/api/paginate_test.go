@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ints serves 0..n-1 through a PageFunc, recording each request.
+func ints(n int, reqs *[]PageRequest) PageFunc[int] {
+	return func(ctx context.Context, req PageRequest) (Page[int], error) {
+		*reqs = append(*reqs, req)
+		var items []int
+		for i := req.Offset; i < n && i < req.Offset+req.Limit; i++ {
+			items = append(items, i)
+		}
+		return Page[int]{Items: items}, nil
+	}
+}
+
+func TestPaginator_StopsOnShortPage(t *testing.T) {
+	var reqs []PageRequest
+	all, err := NewPaginator("ints", 10, 100, ints(25, &reqs)).All(context.Background())
+	if err != nil || len(all) != 25 || all[24] != 24 {
+		t.Fatalf("All() = %v, %v; want 0..24", all, err)
+	}
+	if len(reqs) != 3 || reqs[2].Offset != 20 {
+		t.Errorf("requests = %+v, want offsets 0 / 10 / 20", reqs)
+	}
+}
+
+func TestPaginator_TotalSavesTrailingRequest(t *testing.T) {
+	calls := 0
+	p := NewPaginator("ints", 10, 100, func(ctx context.Context, req PageRequest) (Page[int], error) {
+		calls++
+		return Page[int]{Items: make([]int, 10), Total: 20}, nil
+	})
+	all, err := p.All(context.Background())
+	if err != nil || len(all) != 20 || calls != 2 || p.Total() != 20 {
+		t.Errorf("All() = %d rows in %d calls (total %d), %v; want 20 in 2", len(all), calls, p.Total(), err)
+	}
+}
+
+func TestPaginator_OversizedPageEndsWalk(t *testing.T) {
+	calls := 0
+	p := NewPaginator("ints", 10, 100, func(ctx context.Context, req PageRequest) (Page[int], error) {
+		calls++
+		return Page[int]{Items: make([]int, 30)}, nil
+	})
+	if all, err := p.All(context.Background()); err != nil || len(all) != 30 || calls != 1 {
+		t.Errorf("All() = %d rows in %d calls, %v; a server ignoring ?limit= must not be asked again", len(all), calls, err)
+	}
+}
+
+func TestPaginator_FollowsCursor(t *testing.T) {
+	var reqs []PageRequest
+	cursors := map[string]string{"": "c1", "c1": "c2", "c2": ""}
+	p := NewPaginator("ints", 10, 100, func(ctx context.Context, req PageRequest) (Page[int], error) {
+		reqs = append(reqs, req)
+		// Short pages would end an offset walk; the cursor keeps it going.
+		return Page[int]{Items: []int{len(reqs)}, NextCursor: cursors[req.Cursor]}, nil
+	})
+	all, err := p.All(context.Background())
+	if err != nil || fmt.Sprint(all) != "[1 2 3]" {
+		t.Fatalf("All() = %v, %v; want three cursor pages", all, err)
+	}
+	if reqs[1].Cursor != "c1" || reqs[2].Cursor != "c2" {
+		t.Errorf("requests = %+v, want the server's cursors echoed back", reqs)
+	}
+}
+
+func TestPaginator_CeilingKeepsPartialResult(t *testing.T) {
+	p := NewPaginator("ints", 2, 3, func(ctx context.Context, req PageRequest) (Page[int], error) {
+		return Page[int]{Items: []int{req.Offset, req.Offset + 1}}, nil
+	})
+	all, err := p.All(context.Background())
+	var limitErr *PageLimitError
+	if !errors.As(err, &limitErr) || limitErr.Endpoint != "ints" || len(all) != 6 {
+		t.Errorf("All() = %v, %v; want 6 rows and *PageLimitError", all, err)
+	}
+}
+
+func TestPaginator_StreamsPageByPage(t *testing.T) {
+	var reqs []PageRequest
+	p := NewPaginator("ints", 10, 100, ints(15, &reqs))
+	first, ok := p.NextPage(context.Background())
+	if !ok || len(first) != 10 || len(reqs) != 1 {
+		t.Fatalf("NextPage() = %v, %v after %d requests; want the first page alone", first, ok, len(reqs))
+	}
+	n := 0
+	for p.Next(context.Background()) {
+		n++
+	}
+	if n != 5 || p.Err() != nil {
+		t.Errorf("Next() yielded %d more rows, err %v; want the 5 on the second page", n, p.Err())
+	}
+}
+
+func TestPaginator_ErrorStopsWalk(t *testing.T) {
+	boom := errors.New("boom")
+	calls := 0
+	p := NewPaginator("ints", 1, 100, func(ctx context.Context, req PageRequest) (Page[int], error) {
+		calls++
+		if calls == 2 {
+			return Page[int]{}, boom
+		}
+		return Page[int]{Items: []int{calls}}, nil
+	})
+	all, err := p.All(context.Background())
+	if !errors.Is(err, boom) || len(all) != 1 || p.Next(context.Background()) || calls != 2 {
+		t.Errorf("All() = %v, %v after %d calls; want the first row and the error, then nothing", all, err, calls)
+	}
+}
+
+func TestListProjects_PagesByBodyTotal(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		var rows []string
+		start := 0
+		if r.URL.Query().Get("offset") == "100" {
+			start = 100
+		}
+		for i := start; i < start+100 && i < 150; i++ {
+			rows = append(rows, fmt.Sprintf(`{"id":"p%d","name":"n%d"}`, i, i))
+		}
+		fmt.Fprintf(w, `{"projects":[%s],"total":150}`, strings.Join(rows, ","))
+	}))
+	defer server.Close()
+
+	projects, err := NewClient(server.URL, "k").ListProjects(context.Background())
+	if err != nil || len(projects) != 150 || projects[149].ID != "p149" {
+		t.Fatalf("ListProjects() = %d projects, %v; want all 150", len(projects), err)
+	}
+	if fmt.Sprint(queries) != "[limit=100 limit=100&offset=100]" {
+		t.Errorf("queries = %q", queries)
+	}
+}
+
+func TestListProjects_UnpagedServerCostsOneRequest(t *testing.T) {
+	for name, body := range map[string]string{
+		"bare array": `[{"id":"a"},{"id":"b"}]`,
+		"no total":   `{"projects":[{"id":"a"},{"id":"b"}]}`,
+		"short page": `{"projects":[{"id":"a"},{"id":"b"}],"total":2}`,
+	} {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Write([]byte(body))
+		}))
+		projects, err := NewClient(server.URL, "k").ListProjects(context.Background())
+		server.Close()
+		if err != nil || len(projects) != 2 || calls != 1 {
+			t.Errorf("%s: ListProjects() = %d projects in %d calls, %v; want 2 in 1", name, len(projects), calls, err)
+		}
+	}
+}
+
+func TestListProjects_CursorOnlyWhenDeclared(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		q := r.URL.Query()
+		start, next := 0, `"c1"`
+		if q.Get("cursor") == "c1" || q.Get("offset") == "100" {
+			start, next = 100, `""`
+		}
+		var rows []string
+		for i := start; i < start+100 && i < 150; i++ {
+			rows = append(rows, fmt.Sprintf(`{"id":"p%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"projects":[%s],"total":150,"next_cursor":%s}`, strings.Join(rows, ","), next)
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		caps *Capabilities
+		want string
+	}{
+		{nil, "[limit=100 limit=100&offset=100]"},
+		{&Capabilities{Published: true, Features: []string{FeatureSBOMUpload}}, "[limit=100 limit=100&offset=100]"},
+		{&Capabilities{Published: true, Features: []string{FeatureCursorPagination}}, "[limit=100 cursor=c1&limit=100]"},
+	} {
+		queries = nil
+		client := NewClient(server.URL, "k")
+		client.SetCapabilities(tc.caps)
+		projects, err := client.ListProjects(context.Background())
+		if err != nil || len(projects) != 150 {
+			t.Fatalf("caps %+v: ListProjects() = %d projects, %v; want 150", tc.caps, len(projects), err)
+		}
+		if fmt.Sprint(queries) != tc.want {
+			t.Errorf("caps %+v: queries = %q, want %s", tc.caps, queries, tc.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+// Project references.
+//
+// Every project-scoped endpoint takes the project's ID in its path, but
+// operators know their projects by name. A reference is resolved the
+// same way everywhere: a UUID is used as the ID without a lookup, and
+// anything else must be the exact name (or the exact ID, for servers
+// whose IDs are not UUIDs) of one project visible to the key.
+// Resolution never guesses: a near miss is reported with the candidates,
+// not silently taken.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ProjectRefError is returned when a project reference does not name
+// exactly one project.
+type ProjectRefError struct {
+	Ref string
+	// Ambiguous is set when several projects carry the name; Candidates
+	// then lists them. Otherwise nothing matched, and Candidates lists
+	// the projects whose name differs only in case.
+	Ambiguous  bool
+	Candidates []Project
+}
+
+func (e *ProjectRefError) Error() string {
+	if e.Ambiguous {
+		return fmt.Sprintf("プロジェクト名 %q に一致するプロジェクトが %d 件あります。 ID で指定してください", e.Ref, len(e.Candidates))
+	}
+	return fmt.Sprintf("プロジェクト %q が見つかりません", e.Ref)
+}
+
+// IsProjectID reports whether ref is used as a project ID as is, i.e.
+// is a UUID. Other references are names and need FindProject.
+func IsProjectID(ref string) bool {
+	return looksLikeUUID(strings.TrimSpace(ref))
+}
+
+// FindProject picks the project ref names out of projects: the one whose
+// ID or name is exactly ref. It returns *ProjectRefError when there is no
+// such project or more than one.
+func FindProject(projects []Project, ref string) (*Project, error) {
+	ref = strings.TrimSpace(ref)
+	var named, folded []Project
+	for i := range projects {
+		p := projects[i]
+		switch {
+		case p.ID == ref:
+			return &p, nil
+		case p.Name == ref:
+			named = append(named, p)
+		case strings.EqualFold(p.Name, ref):
+			folded = append(folded, p)
+		}
+	}
+	switch len(named) {
+	case 1:
+		return &named[0], nil
+	case 0:
+		return nil, &ProjectRefError{Ref: ref, Candidates: folded}
+	}
+	return nil, &ProjectRefError{Ref: ref, Ambiguous: true, Candidates: named}
+}
+
+// ResolveProjectID returns the ID of the project ref names. A UUID is
+// returned without a request; a name costs a walk of the project list.
+// Callers resolving many names should list once and use FindProject.
+func (c *Client) ResolveProjectID(ctx context.Context, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("プロジェクトが指定されていません")
+	}
+	if looksLikeUUID(ref) {
+		return ref, nil
+	}
+	projects, err := c.ListProjects(ctx)
+	if err != nil {
+		return "", err
+	}
+	p, err := FindProject(projects, ref)
+	if err != nil {
+		return "", err
+	}
+	return p.ID, nil
+}
+
+// BaseURL returns the API URL the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
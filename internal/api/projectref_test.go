@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindProject(t *testing.T) {
+	projects := []Project{
+		{ID: "id-1", Name: "app"},
+		{ID: "id-2", Name: "App"},
+		{ID: "id-3", Name: "dup"},
+		{ID: "id-4", Name: "dup"},
+	}
+	tests := []struct {
+		ref        string
+		want       string
+		ambiguous  bool
+		candidates int
+	}{
+		{ref: "app", want: "id-1"},
+		{ref: " App ", want: "id-2"},
+		{ref: "id-3", want: "id-3"},
+		{ref: "dup", ambiguous: true, candidates: 2},
+		{ref: "APP", candidates: 2},
+		{ref: "missing"},
+	}
+	for _, tt := range tests {
+		p, err := FindProject(projects, tt.ref)
+		if tt.want != "" {
+			if err != nil || p.ID != tt.want {
+				t.Errorf("FindProject(%q) = %+v, %v; want %s", tt.ref, p, err, tt.want)
+			}
+			continue
+		}
+		var refErr *ProjectRefError
+		if !errors.As(err, &refErr) || refErr.Ambiguous != tt.ambiguous || len(refErr.Candidates) != tt.candidates {
+			t.Errorf("FindProject(%q) = %+v, %v; want ambiguous=%v with %d candidates", tt.ref, p, err, tt.ambiguous, tt.candidates)
+		}
+	}
+}
+
+func TestResolveProjectID(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"projects":[{"id":"p-1","name":"app"}],"total":1}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "k")
+	ctx := context.Background()
+
+	uuid := "3f2b8c1e-0000-4000-8000-000000000001"
+	if id, err := client.ResolveProjectID(ctx, uuid); err != nil || id != uuid || requests != 0 {
+		t.Errorf("ResolveProjectID(uuid) = %q, %v after %d requests; want it as is without a lookup", id, err, requests)
+	}
+	if id, err := client.ResolveProjectID(ctx, "app"); err != nil || id != "p-1" {
+		t.Errorf("ResolveProjectID(app) = %q, %v", id, err)
+	}
+	var refErr *ProjectRefError
+	if _, err := client.ResolveProjectID(ctx, "other"); !errors.As(err, &refErr) {
+		t.Errorf("ResolveProjectID(other) = %v, want a *ProjectRefError", err)
+	}
+}
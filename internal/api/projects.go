@@ -0,0 +1,116 @@
+package api
+
+// Project lifecycle: rename / re-describe, archive, delete.
+//
+// The CLI could only list, show and get-or-create projects, so the
+// projects that `scan` auto-creates from directory names (one per
+// checkout path, per CI workspace) could only be cleaned up in the web
+// UI. These calls cover the rest of the lifecycle.
+//
+// ※要確認: the endpoints are the CLI's proposal, following the existing
+// /api/v1/cli/projects resource:
+//
+//	PATCH  /api/v1/cli/projects/:id            {name?, description?} → Project
+//	POST   /api/v1/cli/projects/:id/archive                          → Project
+//	POST   /api/v1/cli/projects/:id/unarchive                        → Project
+//	DELETE /api/v1/cli/projects/:id                                  → 204
+//
+// Server semantics assumed: 404 for an unknown (or other tenant's)
+// project, 409 when a rename collides with an existing name, 403 for a
+// read-only key. Archiving an archived project (and the reverse) is a
+// no-op 200, which is what makes the two POSTs safe to retry.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UpdateProjectRequest is the body of PATCH /cli/projects/:id. Nil fields
+// are left unchanged; an empty Description clears the description.
+type UpdateProjectRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// UpdateProject changes the name and/or description of a project and
+// returns it as stored.
+func (c *Client) UpdateProject(ctx context.Context, id string, req UpdateProjectRequest) (*Project, error) {
+	if req.Name == nil && req.Description == nil {
+		return nil, fmt.Errorf("更新する項目がありません")
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("プロジェクト名が空です")
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストのシリアライズに失敗: %w", err)
+	}
+	return c.sendProject(ctx, apiRequest{
+		method: http.MethodPatch,
+		url:    c.projectURL(id, ""),
+		body:   body,
+	})
+}
+
+// ArchiveProject marks a project archived: it stays readable but drops
+// out of the server's default views and accepts no new SBOMs.
+func (c *Client) ArchiveProject(ctx context.Context, id string) (*Project, error) {
+	return c.sendProject(ctx, apiRequest{
+		method:     http.MethodPost,
+		url:        c.projectURL(id, "/archive"),
+		idempotent: true,
+	})
+}
+
+// UnarchiveProject reverses ArchiveProject.
+func (c *Client) UnarchiveProject(ctx context.Context, id string) (*Project, error) {
+	return c.sendProject(ctx, apiRequest{
+		method:     http.MethodPost,
+		url:        c.projectURL(id, "/unarchive"),
+		idempotent: true,
+	})
+}
+
+// DeleteProject deletes a project together with its SBOMs, findings and
+// reports. It cannot be undone.
+func (c *Client) DeleteProject(ctx context.Context, id string) error {
+	_, err := c.send(ctx, apiRequest{
+		method:   http.MethodDelete,
+		url:      c.projectURL(id, ""),
+		okStatus: []int{http.StatusOK, http.StatusNoContent},
+	})
+	return err
+}
+
+func (c *Client) projectURL(id, suffix string) string {
+	return fmt.Sprintf("%s/api/v1/cli/projects/%s%s", c.baseURL, url.PathEscape(id), suffix)
+}
+
+// sendProject sends req and decodes the Project the server answers with.
+// A 2xx without one is a protocol error, as for the other writes: the
+// caller would otherwise report a change it cannot show.
+func (c *Client) sendProject(ctx context.Context, req apiRequest) (*Project, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var project Project
+	if err := json.Unmarshal(resp.Body, &project); err != nil {
+		return nil, fmt.Errorf("レスポンス解析エラー: %w", err)
+	}
+	if project.ID == "" {
+		return nil, &Error{
+			StatusCode:    resp.StatusCode,
+			URL:           req.url,
+			Method:        req.method,
+			Message:       "project response missing id (server protocol error)",
+			Raw:           string(resp.Body),
+			ProtocolError: true,
+		}
+	}
+	return &project, nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProjectLifecycle(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.EscapedPath())
+		switch r.Method + " " + r.URL.Path {
+		case "PATCH /api/v1/cli/projects/p 1":
+			var req map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("PATCH body: %v", err)
+			}
+			if _, ok := req["name"]; ok || req["description"] != "" {
+				t.Errorf("PATCH body = %v, want only an empty description", req)
+			}
+			_, _ = w.Write([]byte(`{"id":"p 1","name":"app"}`))
+		case "POST /api/v1/cli/projects/p 1/archive":
+			_, _ = w.Write([]byte(`{"id":"p 1","name":"app","archived":true}`))
+		case "POST /api/v1/cli/projects/p 1/unarchive":
+			_, _ = w.Write([]byte(`{}`))
+		case "DELETE /api/v1/cli/projects/p 1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"project not found"}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "k")
+	client.SetRetryPolicy(NoRetry)
+	ctx := context.Background()
+
+	empty := ""
+	if p, err := client.UpdateProject(ctx, "p 1", UpdateProjectRequest{Description: &empty}); err != nil || p.Name != "app" {
+		t.Errorf("UpdateProject() = %+v, %v", p, err)
+	}
+	if _, err := client.UpdateProject(ctx, "p 1", UpdateProjectRequest{}); err == nil {
+		t.Error("UpdateProject() with nothing to change: want an error")
+	}
+	if p, err := client.ArchiveProject(ctx, "p 1"); err != nil || !p.Archived {
+		t.Errorf("ArchiveProject() = %+v, %v", p, err)
+	}
+	var apiErr *Error
+	if _, err := client.UnarchiveProject(ctx, "p 1"); !errors.As(err, &apiErr) || apiErr.Kind() != KindProtocol {
+		t.Errorf("UnarchiveProject() with an empty body = %v, want a protocol error", err)
+	}
+	if err := client.DeleteProject(ctx, "p 1"); err != nil {
+		t.Errorf("DeleteProject() = %v", err)
+	}
+	if err := client.DeleteProject(ctx, "gone"); !errors.As(err, &apiErr) || !apiErr.IsNotFound() || apiErr.IsConflict() {
+		t.Errorf("DeleteProject(gone) = %v, want a 404", err)
+	}
+
+	want := []string{
+		"PATCH /api/v1/cli/projects/p%201",
+		"POST /api/v1/cli/projects/p%201/archive",
+		"POST /api/v1/cli/projects/p%201/unarchive",
+		"DELETE /api/v1/cli/projects/p%201",
+		"DELETE /api/v1/cli/projects/gone",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("requests = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
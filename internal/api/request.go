@@ -0,0 +1,518 @@
+package api
+
+// Request pipeline — the single path every Client method sends through.
+//
+// Before this existed each method hand-rolled NewRequest / Do / ReadAll /
+// status check, and only GetScanStatus returned a typed error; the
+// rest returned `fmt.Errorf("APIエラー (%d)")`, so a 502 during upload
+// killed a CI run that one retry would have saved, and callers could not
+// classify failures with errors.As. send centralises:
+//
+//   - auth / content-type headers,
+//   - typed errors: non-success statuses become *Error (errors.go);
+//     transport failures become *RequestError,
+//   - retries with exponential backoff and jitter on 429 / 5xx /
+//     transport errors, honouring Retry-After,
+//   - Idempotency-Key on POSTs, when the server declares it honours one,
+//     so a retried write is safe,
+//   - an X-Request-ID per call, carried into errors and the --trace log
+//     (trace.go),
+//   - streamed, optionally gzip-compressed bodies that are re-opened
+//     per attempt (SBOM upload; see upload.go).
+//
+// Retry safety: GET / PUT / DELETE are idempotent by HTTP semantics and
+// are retried on any transient failure. A POST is retried on 5xx or a
+// transport error only when it is marked idempotent (pure reads such as
+// /cli/check, get-or-create) or carries an Idempotency-Key; otherwise
+// only 429 is retried, because a rate-limited request was by definition
+// not processed.
+//
+// A key only makes a replay safe if the server de-duplicates by it, so
+// writes ask for one (apiRequest.idempotencyKey) but get it only when the
+// server declares FeatureIdempotencyKey. Against any other server they
+// go out unkeyed and, after a 502 from a proxy that may have forwarded
+// the first attempt, fail rather than risk a second SBOM row.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures automatic retries in the request pipeline.
+type RetryPolicy struct {
+	// MaxRetries is the number of additional attempts after a retryable
+	// failure. Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; each retry
+	// doubles it (with jitter) up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff.
+	MaxDelay time.Duration
+	// MaxRetryAfter caps how long a server-supplied Retry-After is
+	// honoured. A longer hint ends the retries and returns the error, so
+	// a CI job fails fast instead of sleeping for an hour.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy is used by NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:    3,
+	BaseDelay:     500 * time.Millisecond,
+	MaxDelay:      30 * time.Second,
+	MaxRetryAfter: 2 * time.Minute,
+}
+
+// NoRetry disables automatic retries.
+var NoRetry = RetryPolicy{}
+
+// SetRetryPolicy replaces the client's retry policy.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+// RequestError is a failure to get any HTTP response at all (DNS,
+// connection refused / reset, TLS, client timeout). It is always
+// retryable unless the caller's context ended.
+type RequestError struct {
+	Method string
+	URL    string
+	Err    error
+	// RequestID is the X-Request-ID the client sent; a proxy or the
+	// server may have logged it even though no response arrived.
+	RequestID string
+}
+
+func (e *RequestError) Error() string {
+	return withRequestID(fmt.Sprintf("リクエスト送信エラー (%s %s): %v", e.Method, e.URL, e.Err), e.RequestID)
+}
+
+func (e *RequestError) Unwrap() error { return e.Err }
+
+// IsRetryable reports whether another attempt may succeed.
+func (e *RequestError) IsRetryable() bool {
+	return e != nil && !errors.Is(e.Err, context.Canceled) && !errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// errorDecoder builds the typed error for a non-success response.
+type errorDecoder func(method, url string, status int, body []byte) error
+
+// apiRequest describes one logical API call.
+type apiRequest struct {
+	method string
+	url    string
+	body   []byte
+	// open, when set, supplies a fresh body stream for each attempt in
+	// place of body, so a large upload is never held in memory. size is
+	// its uncompressed length (-1 when unknown).
+	open func() (io.ReadCloser, error)
+	size int64
+	// gzip compresses the body on the fly (Content-Encoding: gzip).
+	gzip bool
+	// progress is called as the uncompressed body is read for sending.
+	progress func(sent, total int64)
+	// noTimeout lifts the http.Client timeout for this call, leaving
+	// ctx as the only bound (streamed uploads; see upload.go).
+	noTimeout bool
+	// contentType defaults to application/json when body is non-nil.
+	contentType string
+	// okStatus lists the success codes; empty means 200 only.
+	okStatus []int
+	// any2xx accepts every 2xx status as success (overrides okStatus).
+	any2xx bool
+	// decodeError overrides the core-API decoder (serviceDecoder("")).
+	decodeError errorDecoder
+	// idempotent marks a POST as safe to retry without a key.
+	idempotent bool
+	// idempotencyKey attaches an Idempotency-Key to a POST when the
+	// server declares FeatureIdempotencyKey, making 5xx / transport
+	// retries safe.
+	idempotencyKey bool
+	// omitAuthIfEmpty skips the Authorization header when no API key is
+	// configured (the unauthenticated health probe).
+	omitAuthIfEmpty bool
+	// noAuth never sends the client's credentials: OAuth endpoints,
+	// which may live on a third-party IdP.
+	noAuth bool
+	// retry overrides the client policy for this call (e.g. a polling
+	// loop that already retries on its own cadence).
+	retry *RetryPolicy
+	// sink, when set, receives a successful response's body as it
+	// arrives instead of it being read into apiResponse.Body, so a large
+	// download is never held in memory. Once anything has been written
+	// the call is not retried: the sink cannot take the bytes back.
+	sink io.Writer
+}
+
+// apiResponse is a successful response with its body fully read, or
+// with Streamed bytes of it written to the request's sink.
+type apiResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Streamed   int64
+}
+
+// sinkWriter wraps a request's sink for send: it counts what has been
+// written, so a retry is refused once output has started, and keeps the
+// sink's own failure (a full disk) apart from a failed read.
+type sinkWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (s *sinkWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	s.n += int64(n)
+	if err != nil {
+		s.err = err
+	}
+	return n, err
+}
+
+// send executes r with retries and returns the first success, or the
+// last typed error once retries are exhausted / not applicable.
+func (c *Client) send(ctx context.Context, r apiRequest) (*apiResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	policy := c.retry
+	if r.retry != nil {
+		policy = *r.retry
+	}
+	// One request ID per logical call: every retry of it carries the
+	// same X-Request-ID, so a grep of the server logs finds them all.
+	info := attemptInfo{requestID: newIdempotencyKey()}
+	if r.method == http.MethodPost && r.idempotencyKey && c.caps.Declares(FeatureIdempotencyKey) {
+		info.idempotencyKey = newIdempotencyKey()
+	}
+
+	var sink *sinkWriter
+	if r.sink != nil {
+		sink = &sinkWriter{w: r.sink}
+		r.sink = sink
+	}
+
+	useTokens := c.tokens != nil && !r.noAuth
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		info.n++
+		if useTokens {
+			tok, err := c.tokens.Token(ctx)
+			if err != nil {
+				return nil, err
+			}
+			info.token = tok
+		}
+		resp, err := c.attempt(ctx, r, info)
+		if err == nil {
+			return resp, nil
+		}
+		// A 401 with a token source gets one refresh and a replay that
+		// does not count against the retry budget; only if the new
+		// token is refused too (or cannot be had) does the auth error
+		// reach the caller.
+		if useTokens && !refreshed && statusOf(err) == http.StatusUnauthorized && ctx.Err() == nil {
+			refreshed = true
+			if _, rerr := c.tokens.Refresh(ctx, info.token); rerr != nil {
+				return nil, fmt.Errorf("%w (%v)", unwrapStatus(err), rerr)
+			}
+			attempt--
+			continue
+		}
+		if attempt >= policy.MaxRetries || ctx.Err() != nil || (sink != nil && sink.n > 0) || !c.shouldRetry(r, info.idempotencyKey != "", err) {
+			return nil, unwrapStatus(err)
+		}
+		wait := backoffDelay(policy, attempt)
+		if ra := retryAfterOf(err); ra > 0 {
+			if policy.MaxRetryAfter > 0 && ra > policy.MaxRetryAfter {
+				return nil, unwrapStatus(err)
+			}
+			if ra > wait {
+				wait = ra
+			}
+		}
+		if c.sleep(ctx, wait) != nil {
+			return nil, unwrapStatus(err)
+		}
+	}
+}
+
+// attemptInfo identifies one attempt of a logical call.
+type attemptInfo struct {
+	n              int // 1-based
+	requestID      string
+	idempotencyKey string
+	// token is the bearer token from the client's TokenSource, if any.
+	token string
+}
+
+// attempt performs a single HTTP round trip.
+func (c *Client) attempt(ctx context.Context, r apiRequest, info attemptInfo) (*apiResponse, error) {
+	tr := c.tracer.begin(r, info)
+	body, length, err := r.newBody(tr.bodyCapture())
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, body)
+	if err != nil {
+		if rc, ok := body.(io.Closer); ok {
+			rc.Close()
+		}
+		return nil, err
+	}
+	if length >= 0 && body != nil {
+		req.ContentLength = length
+	}
+	if r.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if body != nil {
+		ct := r.contentType
+		if ct == "" {
+			ct = "application/json"
+		}
+		req.Header.Set("Content-Type", ct)
+	}
+	credential := c.apiKey
+	if c.tokens != nil {
+		credential = info.token
+	}
+	if !r.noAuth && (credential != "" || !r.omitAuthIfEmpty) {
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+	if info.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", info.idempotencyKey)
+	}
+	req.Header.Set("X-Request-ID", info.requestID)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	hc := c.httpClient
+	if r.noTimeout && hc.Timeout != 0 {
+		copied := *hc
+		copied.Timeout = 0
+		hc = &copied
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		c.tracer.finish(tr, req, nil, nil, err)
+		return nil, &RequestError{Method: r.method, URL: r.url, Err: err, RequestID: info.requestID}
+	}
+	defer resp.Body.Close()
+	if sink, ok := r.sink.(*sinkWriter); ok && r.isOK(resp.StatusCode) {
+		return c.stream(tr, req, resp, r, info, sink)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	c.tracer.finish(tr, req, resp, respBody, err)
+	if err != nil {
+		return nil, &RequestError{Method: r.method, URL: r.url, Err: err, RequestID: info.requestID}
+	}
+
+	if !r.isOK(resp.StatusCode) {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		decode := r.decodeError
+		if decode == nil {
+			decode = serviceDecoder("")
+		}
+		typed := decode(r.method, r.url, resp.StatusCode, respBody)
+		if e, ok := typed.(*Error); ok {
+			e.RetryAfter = retryAfter
+			e.RequestID = info.requestID
+			if echoed := resp.Header.Get("X-Request-ID"); echoed != "" {
+				e.RequestID = echoed
+			}
+		}
+		return nil, &statusError{err: typed, retryAfter: retryAfter, status: resp.StatusCode}
+	}
+	return &apiResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
+
+// stream copies a successful response's body to sink, keeping the first
+// harBodyLimit bytes for the trace.
+func (c *Client) stream(tr *traceRecord, req *http.Request, resp *http.Response, r apiRequest, info attemptInfo, sink *sinkWriter) (*apiResponse, error) {
+	var body io.Reader = resp.Body
+	var capture *cappedBuffer
+	if tr != nil {
+		capture = &cappedBuffer{limit: harBodyLimit}
+		body = io.TeeReader(body, capture)
+	}
+	n, err := io.Copy(sink, body)
+	var prefix []byte
+	if capture != nil {
+		prefix, _ = capture.snapshot()
+	}
+	c.tracer.finish(tr, req, resp, prefix, err)
+	if sink.err != nil {
+		return nil, sink.err
+	}
+	if err != nil {
+		return nil, &RequestError{Method: r.method, URL: r.url, Err: err, RequestID: info.requestID}
+	}
+	return &apiResponse{StatusCode: resp.StatusCode, Header: resp.Header, Streamed: n}, nil
+}
+
+// newBody returns the body for one attempt and its Content-Length (-1
+// for chunked). A plain byte body is handed to net/http as-is so it keeps
+// its automatic length and GetBody. capture, when non-nil, receives a
+// copy of a streamed body as it is sent, before compression (--trace).
+func (r apiRequest) newBody(capture io.Writer) (io.Reader, int64, error) {
+	if r.open == nil && !r.gzip && r.progress == nil {
+		if r.body == nil {
+			return nil, -1, nil
+		}
+		return bytes.NewReader(r.body), int64(len(r.body)), nil
+	}
+	var (
+		src  io.ReadCloser
+		size = r.size
+	)
+	switch {
+	case r.open != nil:
+		rc, err := r.open()
+		if err != nil {
+			return nil, -1, err
+		}
+		src = rc
+	case r.body != nil:
+		src, size = io.NopCloser(bytes.NewReader(r.body)), int64(len(r.body))
+	default:
+		return nil, -1, nil
+	}
+	if capture != nil {
+		src = &teeReadCloser{ReadCloser: src, w: capture}
+	}
+	if r.progress != nil {
+		src = &progressReader{ReadCloser: src, total: size, fn: r.progress}
+	}
+	if r.gzip {
+		return gzipStream(src), -1, nil
+	}
+	return src, size, nil
+}
+
+func (r apiRequest) isOK(status int) bool {
+	if r.any2xx {
+		return status >= 200 && status < 300
+	}
+	if len(r.okStatus) == 0 {
+		return status == http.StatusOK
+	}
+	for _, s := range r.okStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldRetry applies the retry-safety rules in the file comment.
+func (c *Client) shouldRetry(r apiRequest, keyed bool, err error) bool {
+	if !isRetryable(err) {
+		return false
+	}
+	if r.method != http.MethodPost || r.idempotent || keyed {
+		return true
+	}
+	var se *statusError
+	return errors.As(err, &se) && se.status == http.StatusTooManyRequests
+}
+
+// isRetryable classifies a failure with the error type's own notion of
+// transience (*Error, *RequestError); anything else is not retried.
+func isRetryable(err error) bool {
+	var r interface{ IsRetryable() bool }
+	return errors.As(err, &r) && r.IsRetryable()
+}
+
+// statusError carries the Retry-After hint alongside a typed status
+// error while an attempt is in flight. unwrapStatus strips it before the
+// error leaves the package, so callers only ever see the typed error.
+type statusError struct {
+	err        error
+	retryAfter time.Duration
+	status     int
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+func unwrapStatus(err error) error {
+	if se, ok := err.(*statusError); ok {
+		return se.err
+	}
+	return err
+}
+
+// statusOf returns the HTTP status of an in-flight attempt error, 0 for
+// transport failures.
+func statusOf(err error) int {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status
+	}
+	return 0
+}
+
+func retryAfterOf(err error) time.Duration {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.retryAfter
+	}
+	return 0
+}
+
+// backoffDelay returns BaseDelay·2^attempt capped at MaxDelay, with
+// "equal jitter" (half fixed, half random) so parallel CI jobs that
+// failed together do not retry in lock-step.
+func backoffDelay(p RetryPolicy, attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(mathrand.Int63n(int64(half)+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// newIdempotencyKey returns a random UUIDv4-formatted key.
+func newIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to
+		// a time-derived key rather than sending none.
+		return fmt.Sprintf("sbomhub-%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
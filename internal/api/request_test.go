@@ -0,0 +1,289 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMain shrinks the default backoff so the many tests that answer
+// 5xx / 429 (and predate the retrying pipeline) do not sleep for real.
+// Tests that care about the policy set their own via SetRetryPolicy.
+func TestMain(m *testing.M) {
+	DefaultRetryPolicy.BaseDelay = time.Millisecond
+	DefaultRetryPolicy.MaxDelay = 5 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+// recordSleeps replaces c's sleep hook and returns the recorded waits.
+func recordSleeps(c *Client) *[]time.Duration {
+	var (
+		mu    sync.Mutex
+		waits []time.Duration
+	)
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		waits = append(waits, d)
+		mu.Unlock()
+		return ctx.Err()
+	}
+	return &waits
+}
+
+func TestUploadSBOM_RetriesWithSameIdempotencyKey(t *testing.T) {
+	const projectID = "00000000-0000-0000-0000-000000000abc"
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"` + projectID + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	client.SetCapabilities(&Capabilities{Published: true, Features: []string{FeatureIdempotencyKey}})
+	recordSleeps(client)
+	res, err := client.UploadSBOM(context.Background(), projectID, true, []byte(`{"bomFormat":"CycloneDX"}`), "cyclonedx")
+	if err != nil {
+		t.Fatalf("UploadSBOM() = %v, want success after one retry", err)
+	}
+	if res.SBOMID != "sbom-1" {
+		t.Errorf("SBOMID = %q, want sbom-1", res.SBOMID)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Idempotency-Key per attempt = %q, want one non-empty key reused", keys)
+	}
+}
+
+func TestUploadSBOM_UnkeyedUnlessServerDeclaresIdempotency(t *testing.T) {
+	const projectID = "00000000-0000-0000-0000-000000000abc"
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	for _, caps := range []*Capabilities{nil, {}, {Published: true, Features: []string{FeatureSBOMUpload}}} {
+		keys = nil
+		client := NewClient(server.URL, "test-key")
+		client.SetCapabilities(caps)
+		recordSleeps(client)
+		_, err := client.UploadSBOM(context.Background(), projectID, true, []byte(`{"bomFormat":"CycloneDX"}`), "cyclonedx")
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+			t.Fatalf("caps %+v: err = %v, want *Error 502", caps, err)
+		}
+		if len(keys) != 1 || keys[0] != "" {
+			t.Errorf("caps %+v: Idempotency-Key per attempt = %q, want one unkeyed attempt", caps, keys)
+		}
+	}
+}
+
+func TestSend_HonoursRetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"projects":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	waits := recordSleeps(client)
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects() = %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 7*time.Second {
+		t.Errorf("waits = %v, want [7s] from Retry-After", *waits)
+	}
+}
+
+func TestSend_RetryAfterBeyondCapGivesUp(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	recordSleeps(client)
+	_, err := client.GetProject(context.Background(), "p1")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want *Error 503", err)
+	}
+	if apiErr.RetryAfter != time.Hour {
+		t.Errorf("RetryAfter = %v, want 1h", apiErr.RetryAfter)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (an hour-long Retry-After must not be waited out)", calls)
+	}
+}
+
+func TestSend_UnkeyedPostNotRetriedOn5xx(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	recordSleeps(client)
+	_, err := client.send(context.Background(), apiRequest{method: http.MethodPost, url: server.URL, body: []byte("{}")})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want *Error 500", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (a POST without an idempotency key must not be replayed)", calls)
+	}
+}
+
+func TestSend_PermanentErrorNotRetried(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "bad-key")
+	recordSleeps(client)
+	_, _, err := client.CreateProject(context.Background(), "demo", "")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.IsRetryable() {
+		t.Fatalf("err = %v, want non-retryable *Error 401", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestSend_TransportErrorIsTyped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	client := NewClient(url, "test-key")
+	waits := recordSleeps(client)
+	_, err := client.ListProjects(context.Background())
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || !reqErr.IsRetryable() {
+		t.Fatalf("err = %v, want retryable *RequestError", err)
+	}
+	if len(*waits) != DefaultRetryPolicy.MaxRetries {
+		t.Errorf("retries = %d, want %d", len(*waits), DefaultRetryPolicy.MaxRetries)
+	}
+}
+
+func TestSend_DomainErrorClassification(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"AI features are disabled","code":"ai_disabled"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+	recordSleeps(client)
+	_, err := client.ListVEXDrafts(context.Background(), "p1", VEXDraftListFilter{})
+	var te *Error
+	if !errors.As(err, &te) {
+		t.Fatalf("err = %v, want *Error", err)
+	}
+	if !te.IsAIDisabled() {
+		t.Fatalf("IsAIDisabled() = false for %+v", te)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (AI-disabled is not transient)", calls)
+	}
+}
+
+func TestBackoffDelay_Bounds(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		for i := 0; i < 20; i++ {
+			got := backoffDelay(p, attempt)
+			if got < want/2 || got > want {
+				t.Fatalf("backoffDelay(attempt=%d) = %v, want within [%v, %v]", attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestUploadSBOM_ContextCancelAbortsInFlightRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL, "test-key")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.UploadSBOM(ctx, "00000000-0000-0000-0000-000000000abc", true, []byte(`{}`), "cyclonedx")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("UploadSBOM ran for %v after cancel", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestSend_UserAgent(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("User-Agent"))
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "k")
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	client.SetUserAgent("sbomhub-cli/1.2.3")
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] == "sbomhub-cli/1.2.3" || got[1] != "sbomhub-cli/1.2.3" {
+		t.Errorf("User-Agent = %q, want Go's default then the configured value", got)
+	}
+}
+
+func TestSetHTTPClient_DoesNotMutateCallerClient(t *testing.T) {
+	shared := &http.Client{Timeout: time.Second}
+	client := NewClient("http://example.invalid", "k")
+	client.SetHTTPClient(shared)
+	client.SetTransport(http.DefaultTransport)
+	if shared.Transport != nil {
+		t.Error("SetTransport mutated the *http.Client passed to SetHTTPClient")
+	}
+	if client.httpClient.Timeout != time.Second {
+		t.Errorf("timeout = %s, want the caller's 1s", client.httpClient.Timeout)
+	}
+}
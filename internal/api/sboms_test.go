@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSBOMHistory(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/projects/p1/sboms":
+			if r.URL.Query().Get("offset") == "1" {
+				_, _ = w.Write([]byte(`{"sboms":[{"id":"s/1","format":"cyclonedx","scan_status":"completed"}],"total":2}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"sboms":[{"id":"s2","format":"spdx","component_count":3,"scan_status":"running"}],"total":2}`))
+		case "GET /api/v1/projects/p1/sboms/s/1/document":
+			_, _ = w.Write([]byte("{\"bomFormat\": \"CycloneDX\"}\n"))
+		case "DELETE /api/v1/projects/p1/sboms/s/1":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /api/v1/projects/p1/sboms/s/1/rescan":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status":"running","sbom_id":"s/1"}`))
+		case "POST /api/v1/projects/p1/sboms/bad/rescan":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"sbom not found"}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "k")
+	client.SetRetryPolicy(NoRetry)
+	ctx := context.Background()
+
+	p := NewPaginator("sboms", 1, 10, func(ctx context.Context, req PageRequest) (Page[SBOM], error) {
+		return client.ListSBOMsPage(ctx, "p1", req)
+	})
+	sboms, err := p.All(ctx)
+	if err != nil || len(sboms) != 2 || sboms[0].ComponentCount != 3 || sboms[1].ScanStatus != "completed" {
+		t.Fatalf("SBOM walk = %+v, %v", sboms, err)
+	}
+	if p.Total() != 2 {
+		t.Errorf("Total() = %d, want the body's total", p.Total())
+	}
+	var doc bytes.Buffer
+	if n, err := client.DownloadSBOM(ctx, "p1", "s/1", &doc); err != nil || doc.String() != "{\"bomFormat\": \"CycloneDX\"}\n" || n != int64(doc.Len()) {
+		t.Errorf("DownloadSBOM() = %d, %q, %v; want the document unchanged", n, doc.String(), err)
+	}
+	if err := client.DeleteSBOM(ctx, "p1", "s/1"); err != nil {
+		t.Errorf("DeleteSBOM() = %v", err)
+	}
+	if st, err := client.RescanSBOM(ctx, "p1", "s/1"); err != nil || st.Status != "running" {
+		t.Errorf("RescanSBOM() = %+v, %v", st, err)
+	}
+	var apiErr *Error
+	if _, err := client.RescanSBOM(ctx, "p1", "bad"); !errors.As(err, &apiErr) || apiErr.Kind() != KindProtocol {
+		t.Errorf("RescanSBOM() without a status = %v, want a protocol error", err)
+	}
+	if _, err := client.DownloadSBOM(ctx, "p1", "gone", io.Discard); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("DownloadSBOM(gone) = %v, want a 404", err)
+	}
+
+	want := []string{
+		"GET /api/v1/projects/p1/sboms?limit=1",
+		"GET /api/v1/projects/p1/sboms?limit=1&offset=1",
+		"GET /api/v1/projects/p1/sboms/s%2F1/document?",
+		"DELETE /api/v1/projects/p1/sboms/s%2F1?",
+		"POST /api/v1/projects/p1/sboms/s%2F1/rescan?",
+		"POST /api/v1/projects/p1/sboms/bad/rescan?",
+		"GET /api/v1/projects/p1/sboms/gone/document?",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("requests = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDownloadSBOM_NoRetryAfterPartialBody(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// A 502 before any byte is sent is retried as usual.
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		// Promise more than is sent, then drop the connection.
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte(`{"bomFormat":`))
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "k")
+	recordSleeps(client)
+
+	var doc bytes.Buffer
+	_, err := client.DownloadSBOM(context.Background(), "p1", "s1", &doc)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("DownloadSBOM() = %v, want the read failure", err)
+	}
+	if attempts != 2 || doc.String() != `{"bomFormat":` {
+		t.Errorf("attempts = %d, written %q; want the 502 retried and the cut body not", attempts, doc.String())
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+// Keyless CI authentication.
+//
+// A CI job that can mint an identity token for itself (GitHub Actions,
+// GitLab id_tokens, a Kubernetes projected service account token) trades
+// it at the server's token endpoint for a short-lived SBOMHub token with
+// the RFC 8693 token exchange grant, so repositories need no static
+// SBOMHUB_API_KEY secret. The server decides, from the token's claims
+// (repository, branch, ...), which tenant and scopes the job gets.
+//
+// The grant goes to the token_endpoint of the server's RFC 8414 metadata
+// with the CI token as a JWT subject_token. The CLI only attempts it
+// against a server that declares FeatureTokenExchange: an identity token
+// is a credential in its own right, and one sent to an endpoint that
+// does not know the grant is exposed for nothing.
+
+import (
+	"context"
+	"net/url"
+)
+
+const (
+	tokenExchangeGrant = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// JWTTokenType is the subject_token_type of an OIDC identity token.
+	JWTTokenType = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// ExchangeToken trades subjectToken (a CI identity token of type
+// subjectTokenType) for an SBOMHub access token. scope is optional and
+// lets the job ask for less than the server would grant.
+func (c *Client) ExchangeToken(ctx context.Context, tokenURL, clientID, subjectToken, subjectTokenType, scope string) (*Token, error) {
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrant},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {subjectTokenType},
+		"client_id":          {clientID},
+	}
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+	return c.requestToken(ctx, tokenURL, form)
+}
@@ -0,0 +1,433 @@
+package api
+
+// HTTP tracing (--trace / --trace-har).
+//
+// When a self-hosted server misbehaves, the operator needs to see what
+// the CLI actually sent and received. A Tracer attached with SetTracer
+// sees every attempt the request pipeline makes, retries included, and
+// can write:
+//
+//   - a text log: method, URL, attempt, request ID, selected headers,
+//     status, timing and bodies truncated to traceBodyLimit;
+//   - a HAR 1.2 document (WriteHAR) with all headers and bodies
+//     truncated to harBodyLimit, loadable in browser dev tools.
+//
+// Credentials never reach either output: Authorization / Cookie headers
+// are redacted, and JSON string fields whose name looks like a secret
+// (api_key, token, password, ...) are masked in logged bodies. Streamed
+// uploads are captured before compression, so the trace shows the SBOM
+// rather than gzip bytes.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// traceBodyLimit caps each body in the text log.
+	traceBodyLimit = 2 << 10
+	// harBodyLimit caps each body in the HAR document; a HAR with a
+	// 500 MB SBOM in it would be useless to every viewer.
+	harBodyLimit = 64 << 10
+)
+
+// traceHeaders are the headers shown in the text log. The HAR gets all
+// of them.
+var traceHeaders = []string{
+	"Authorization", "Content-Type", "Content-Encoding", "Content-Length",
+	"Idempotency-Key", "X-Request-ID", "Retry-After", "Location",
+	"Deprecation", "Sunset",
+}
+
+// redactedHeaders never appear in clear.
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"X-Api-Key":     true,
+}
+
+// secretField matches a JSON string member whose name suggests a
+// credential.
+var secretField = regexp.MustCompile(`(?i)("[a-z_]*(?:api_?key|token|secret|password|device_code)[a-z_]*"\s*:\s*)"[^"]*"`)
+
+// secretFieldTail is secretField for a body that ends inside the secret:
+// a streamed body is only captured up to harBodyLimit, and the cut can
+// fall anywhere.
+var secretFieldTail = regexp.MustCompile(`(?i)("[a-z_]*(?:api_?key|token|secret|password|device_code)[a-z_]*"\s*:\s*)"[^"]*$`)
+
+// secretFormField is secretField for form-encoded bodies (the OAuth
+// token endpoint: refresh_token=..., device_code=...).
+var secretFormField = regexp.MustCompile(`(?i)((?:^|&)[a-z_]*(?:api_?key|token|secret|password|device_code)[a-z_]*=)[^&]*`)
+
+// redactSecrets masks credential-looking JSON members and form fields.
+func redactSecrets(s string) string {
+	s = secretField.ReplaceAllString(s, `$1"[REDACTED]"`)
+	s = secretFieldTail.ReplaceAllString(s, `$1"[REDACTED]`)
+	return secretFormField.ReplaceAllString(s, `${1}[REDACTED]`)
+}
+
+// Tracer records request / response pairs. It is safe for concurrent
+// use (check sends chunks in parallel).
+type Tracer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	har     bool
+	creator string
+	entries []harEntry
+	now     func() time.Time
+}
+
+// NewTracer returns a tracer writing the text log to w (nil for none).
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{w: w, now: time.Now}
+}
+
+// EnableHAR makes the tracer keep every exchange for WriteHAR. creator
+// is recorded as the HAR creator version.
+func (t *Tracer) EnableHAR(creator string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.har = true
+	t.creator = creator
+}
+
+// SetTracer attaches t to the client; nil detaches.
+func (c *Client) SetTracer(t *Tracer) {
+	c.tracer = t
+}
+
+// traceRecord is one attempt in flight.
+type traceRecord struct {
+	r       apiRequest
+	info    attemptInfo
+	start   time.Time
+	capture *cappedBuffer
+}
+
+// begin starts recording an attempt. A nil tracer returns nil, and every
+// traceRecord method accepts nil, so the pipeline calls them
+// unconditionally.
+func (t *Tracer) begin(r apiRequest, info attemptInfo) *traceRecord {
+	if t == nil {
+		return nil
+	}
+	rec := &traceRecord{r: r, info: info, start: t.now()}
+	if r.open != nil || r.gzip || r.progress != nil {
+		rec.capture = &cappedBuffer{limit: harBodyLimit}
+	}
+	return rec
+}
+
+// bodyCapture returns the writer newBody tees a streamed body into.
+func (rec *traceRecord) bodyCapture() io.Writer {
+	if rec == nil || rec.capture == nil {
+		return nil
+	}
+	return rec.capture
+}
+
+// requestBody returns up to limit bytes of what was sent, and the full
+// uncompressed size (-1 if unknown).
+func (rec *traceRecord) requestBody() ([]byte, int64) {
+	if rec.capture != nil {
+		b, n := rec.capture.snapshot()
+		if rec.r.open != nil {
+			return b, rec.r.size
+		}
+		return b, n
+	}
+	return rec.r.body, int64(len(rec.r.body))
+}
+
+// finish records the outcome of an attempt: a response (with its body
+// read, readErr set if that failed) or a transport error.
+func (t *Tracer) finish(rec *traceRecord, req *http.Request, resp *http.Response, respBody []byte, err error) {
+	if t == nil || rec == nil {
+		return
+	}
+	elapsed := t.now().Sub(rec.start)
+	reqBody, reqSize := rec.requestBody()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.w != nil {
+		t.writeText(rec, req, resp, reqBody, reqSize, respBody, elapsed, err)
+	}
+	if t.har {
+		t.entries = append(t.entries, newHAREntry(rec, req, resp, reqBody, reqSize, respBody, elapsed, err))
+	}
+}
+
+func (t *Tracer) writeText(rec *traceRecord, req *http.Request, resp *http.Response, reqBody []byte, reqSize int64, respBody []byte, elapsed time.Duration, err error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[trace] → %s %s (attempt %d, request-id %s)\n", rec.r.method, rec.r.url, rec.info.n, rec.info.requestID)
+	writeTraceHeaders(&b, req.Header)
+	if reqSize != 0 {
+		fmt.Fprintf(&b, "[trace]   body: %s\n", traceBody(reqBody, reqSize, traceBodyLimit))
+	}
+	switch {
+	case resp == nil:
+		fmt.Fprintf(&b, "[trace] ← error after %s: %v\n", elapsed.Round(time.Millisecond), err)
+	default:
+		fmt.Fprintf(&b, "[trace] ← %s in %s\n", resp.Status, elapsed.Round(time.Millisecond))
+		writeTraceHeaders(&b, resp.Header)
+		if len(respBody) > 0 {
+			fmt.Fprintf(&b, "[trace]   body: %s\n", traceBody(respBody, int64(len(respBody)), traceBodyLimit))
+		}
+		if err != nil {
+			fmt.Fprintf(&b, "[trace]   body read error: %v\n", err)
+		}
+	}
+	_, _ = io.WriteString(t.w, b.String())
+}
+
+func writeTraceHeaders(b *strings.Builder, h http.Header) {
+	for _, name := range traceHeaders {
+		if v := h.Get(name); v != "" {
+			fmt.Fprintf(b, "[trace]   %s: %s\n", name, headerValue(name, v))
+		}
+	}
+}
+
+// headerValue redacts credential headers, keeping the auth scheme so a
+// missing "Bearer" is still visible.
+func headerValue(name, v string) string {
+	if !redactedHeaders[http.CanonicalHeaderKey(name)] {
+		return v
+	}
+	if scheme, _, ok := strings.Cut(v, " "); ok && name == "Authorization" {
+		return scheme + " [REDACTED]"
+	}
+	return "[REDACTED]"
+}
+
+// traceBody renders body (the first bytes of a size-byte payload)
+// truncated to limit, with secrets masked.
+func traceBody(body []byte, size int64, limit int) string {
+	s, truncated := redactedPrefix(body, size, limit)
+	if truncated {
+		return fmt.Sprintf("%s… (truncated, %d bytes)", s, size)
+	}
+	return s
+}
+
+// redactedPrefix masks the secrets in body, the first bytes of a
+// size-byte payload, and only then cuts it to limit: a cut made first
+// could land inside a secret, leaving an unterminated string that
+// secretField no longer matches. truncated reports whether anything of
+// the payload is missing from the result.
+func redactedPrefix(body []byte, size int64, limit int) (s string, truncated bool) {
+	s = redactSecrets(string(body))
+	truncated = size > int64(len(body))
+	if len(s) > limit {
+		s, truncated = s[:limit], true
+	}
+	return s, truncated
+}
+
+// WriteHAR writes the recorded exchanges as a HAR 1.2 document.
+func (t *Tracer) WriteHAR(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	doc := harDocument{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "sbomhub-cli", Version: t.creator},
+		Entries: t.entries,
+	}}
+	if doc.Log.Entries == nil {
+		doc.Log.Entries = []harEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// HAR 1.2 (http://www.softwareishard.com/blog/har-12-spec/), only the
+// members viewers require plus _requestId / _attempt / _error.
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	RequestID       string      `json:"_requestId"`
+	Attempt         int         `json:"_attempt"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []harNV     `json:"headers"`
+	QueryString []harNV     `json:"queryString"`
+	PostData    *harContent `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	Headers     []harNV    `json:"headers"`
+	Content     harContent `json:"content"`
+	RedirectURL string     `json:"redirectURL"`
+	HeadersSize int        `json:"headersSize"`
+	BodySize    int64      `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harNV struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func newHAREntry(rec *traceRecord, req *http.Request, resp *http.Response, reqBody []byte, reqSize int64, respBody []byte, elapsed time.Duration, err error) harEntry {
+	ms := float64(elapsed.Microseconds()) / 1000
+	e := harEntry{
+		StartedDateTime: rec.start.Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: harRequest{
+			Method:      rec.r.method,
+			URL:         rec.r.url,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNV{},
+			HeadersSize: -1,
+			BodySize:    reqSize,
+		},
+		Response: harResponse{
+			HTTPVersion: "HTTP/1.1",
+			Headers:     []harNV{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings:   harTimings{Wait: ms},
+		RequestID: rec.info.requestID,
+		Attempt:   rec.info.n,
+	}
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			e.Request.QueryString = append(e.Request.QueryString, harNV{Name: k, Value: v})
+		}
+	}
+	if reqSize != 0 {
+		e.Request.PostData = harBody(reqBody, reqSize, req.Header.Get("Content-Type"))
+	}
+	if resp != nil {
+		e.Response.Status = resp.StatusCode
+		e.Response.StatusText = strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode)))
+		e.Response.HTTPVersion = resp.Proto
+		e.Response.Headers = harHeaders(resp.Header)
+		e.Response.BodySize = int64(len(respBody))
+		e.Response.Content = *harBody(respBody, int64(len(respBody)), resp.Header.Get("Content-Type"))
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+func harBody(body []byte, size int64, mime string) *harContent {
+	c := &harContent{Size: size, MimeType: mime}
+	s, truncated := redactedPrefix(body, size, harBodyLimit)
+	c.Text = s
+	if truncated {
+		c.Comment = fmt.Sprintf("truncated to %d of %d bytes", len(s), size)
+	}
+	return c
+}
+
+func harHeaders(h http.Header) []harNV {
+	names := make([]string, 0, len(h))
+	for k := range h {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	out := []harNV{}
+	for _, k := range names {
+		for _, v := range h[k] {
+			out = append(out, harNV{Name: k, Value: headerValue(k, v)})
+		}
+	}
+	return out
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the
+// rest. Writes never fail, so a tee into it cannot break the upload.
+type cappedBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+	n     int64
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if room := c.limit - len(c.buf); room > 0 {
+		if room > len(p) {
+			room = len(p)
+		}
+		c.buf = append(c.buf, p[:room]...)
+	}
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+func (c *cappedBuffer) snapshot() ([]byte, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]byte(nil), c.buf...), c.n
+}
+
+// teeReadCloser copies what is read through it to w.
+type teeReadCloser struct {
+	io.ReadCloser
+	w io.Writer
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = t.w.Write(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSend_RequestIDSharedAcrossRetries(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		http.Error(w, `{"error":"down"}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "k")
+	recordSleeps(client)
+	client.SetRetryPolicy(RetryPolicy{MaxRetries: 1})
+	_, err := client.ListProjects(context.Background())
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("X-Request-ID per attempt = %q, want one non-empty ID reused", ids)
+	}
+	var ae *Error
+	if !errors.As(err, &ae) || ae.RequestID != ids[0] {
+		t.Fatalf("err = %v, want *Error carrying request ID %s", err, ids[0])
+	}
+	if !strings.Contains(err.Error(), "[request-id: "+ids[0]+"]") {
+		t.Errorf("Error() = %q, want the request ID in the message", err)
+	}
+}
+
+func TestSend_PrefersEchoedRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "srv-42")
+		http.Error(w, `{"error":"nope"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "k").ListProjects(context.Background())
+	var ae *Error
+	if !errors.As(err, &ae) || ae.RequestID != "srv-42" {
+		t.Errorf("err = %v, want request ID srv-42 from the response", err)
+	}
+}
+
+func TestTracer_TextLogRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"projects":[],"access_token":"tok-secret"}`))
+	}))
+	defer server.Close()
+
+	var log bytes.Buffer
+	client := NewClient(server.URL, "sbh_supersecret")
+	client.SetTracer(NewTracer(&log))
+	_, err := client.send(context.Background(), apiRequest{
+		method: http.MethodPost,
+		url:    server.URL + "/api/v1/cli/projects",
+		body:   []byte(`{"name":"demo","provider":{"api_key":"llm-secret"}}`),
+	})
+	if err != nil {
+		t.Fatalf("send() = %v", err)
+	}
+	got := log.String()
+	for _, want := range []string{"[trace] → POST " + server.URL + "/api/v1/cli/projects", "Authorization: Bearer [REDACTED]", "X-Request-ID: ", "[trace] ← 200 OK in "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("trace missing %q:\n%s", want, got)
+		}
+	}
+	for _, leak := range []string{"sbh_supersecret", "tok-secret", "llm-secret"} {
+		if strings.Contains(got, leak) {
+			t.Errorf("trace leaks %q:\n%s", leak, got)
+		}
+	}
+}
+
+func TestTracer_HARRecordsEveryAttempt(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"projects":[]}`))
+	}))
+	defer server.Close()
+
+	tracer := NewTracer(nil)
+	tracer.EnableHAR("1.2.3")
+	client := NewClient(server.URL, "sbh_supersecret")
+	recordSleeps(client)
+	client.SetTracer(tracer)
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects() = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tracer.WriteHAR(&buf); err != nil {
+		t.Fatalf("WriteHAR() = %v", err)
+	}
+	if strings.Contains(buf.String(), "sbh_supersecret") {
+		t.Error("HAR leaks the API key")
+	}
+	var doc harDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("HAR is not JSON: %v", err)
+	}
+	if doc.Log.Version != "1.2" || doc.Log.Creator.Version != "1.2.3" {
+		t.Errorf("HAR log header = %+v", doc.Log)
+	}
+	entries := doc.Log.Entries
+	if len(entries) != 2 {
+		t.Fatalf("HAR has %d entries, want one per attempt", len(entries))
+	}
+	if entries[0].Response.Status != 502 || entries[1].Response.Status != 200 {
+		t.Errorf("statuses = %d, %d; want 502, 200", entries[0].Response.Status, entries[1].Response.Status)
+	}
+	if entries[0].RequestID == "" || entries[0].RequestID != entries[1].RequestID || entries[1].Attempt != 2 {
+		t.Errorf("entries = %+v / %+v, want a shared request ID and attempt numbers", entries[0], entries[1])
+	}
+}
+
+func TestTracer_CapturesStreamedUploadBeforeCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"` + uploadProjectID + `"}`))
+	}))
+	defer server.Close()
+
+	var log bytes.Buffer
+	client := NewClient(server.URL, "k")
+	client.SetTracer(NewTracer(&log))
+	sbom := `{"bomFormat":"CycloneDX","components":[` + strings.Repeat(`{"name":"x"},`, 1000) + `{}]}`
+	if _, err := client.UploadSBOMFrom(context.Background(), uploadProjectID, true, SBOMBytes([]byte(sbom)), "cyclonedx", UploadOptions{Gzip: true}); err != nil {
+		t.Fatalf("UploadSBOMFrom() = %v", err)
+	}
+	got := log.String()
+	if !strings.Contains(got, `body: {"bomFormat":"CycloneDX"`) || !strings.Contains(got, "truncated, ") {
+		t.Errorf("trace should show the truncated uncompressed SBOM:\n%s", got)
+	}
+	if !strings.Contains(got, "Content-Encoding: gzip") {
+		t.Errorf("trace should show the gzip encoding:\n%s", got)
+	}
+}
+
+func TestTraceRedactsOAuthForm(t *testing.T) {
+	got := redactSecrets("grant_type=refresh_token&refresh_token=abc&client_id=sbomhub-cli&device_code=xyz")
+	if strings.Contains(got, "abc") || strings.Contains(got, "xyz") || !strings.Contains(got, "client_id=sbomhub-cli") {
+		t.Errorf("redactSecrets() = %q", got)
+	}
+}
+
+func TestTraceBody_RedactsSecretAcrossLimit(t *testing.T) {
+	const token = "sk_live_0123456789abcdef"
+	body := []byte(`{"name":"ci","api_key":"` + token + `"}`)
+	// Cut inside the token: the part before the cut must not survive.
+	limit := strings.Index(string(body), token) + 10
+	for name, got := range map[string]string{
+		"text": traceBody(body, int64(len(body)), limit),
+		"har":  harBody(body, int64(len(body)), "application/json").Text,
+	} {
+		if strings.Contains(got, token[:10]) {
+			t.Errorf("%s: %q leaks the start of the token", name, got)
+		}
+	}
+	if got := traceBody(body, int64(len(body)), limit); !strings.Contains(got, "truncated") {
+		t.Errorf("traceBody() = %q, want it marked truncated", got)
+	}
+
+	// A streamed body captured only up to its cap ends inside the secret.
+	captured := body[:limit]
+	if got := traceBody(captured, 1<<20, traceBodyLimit); strings.Contains(got, token[:10]) {
+		t.Errorf("traceBody(captured prefix) = %q leaks the start of the token", got)
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+// HTTP transport for self-hosted servers behind an internal CA and / or
+// a corporate proxy.
+//
+// Since the SaaS sunset every deployment is self-host, and the common
+// enterprise shape is "API behind a TLS-terminating gateway signed by an
+// internal CA, reachable only through an HTTP(S) proxy, sometimes with
+// mTLS". NewTransport builds one *http.Transport for that shape so the
+// API client, `doctor` and `llm bench` downloads all dial the same way.
+//
+// The zero TransportOptions means "Go defaults": system roots and
+// HTTPS_PROXY / HTTP_PROXY / NO_PROXY from the environment. Callers
+// should keep the default transport in that case (IsZero) rather than
+// building an equivalent one.
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// TransportOptions configures NewTransport.
+type TransportOptions struct {
+	// CACertFile is a PEM bundle appended to the system roots.
+	CACertFile string
+	// ClientCertFile / ClientKeyFile are the PEM client certificate and
+	// key for mTLS. Both or neither must be set.
+	ClientCertFile string
+	ClientKeyFile  string
+	// Proxy is an explicit proxy URL (http://, https:// or socks5://)
+	// that overrides the HTTPS_PROXY / HTTP_PROXY environment variables.
+	Proxy string
+	// NoProxy is a comma-separated list of hosts that bypass the proxy,
+	// in the same syntax as NO_PROXY: exact hosts, domain suffixes
+	// (".corp.example" or "corp.example"), IPs, CIDRs, or "*". It
+	// applies on top of the environment's NO_PROXY.
+	NoProxy string
+	// InsecureSkipVerify disables server certificate verification. Only
+	// for diagnosing a broken chain; callers must warn loudly.
+	InsecureSkipVerify bool
+}
+
+// IsZero reports whether o leaves every setting at the Go default.
+func (o TransportOptions) IsZero() bool {
+	return o == TransportOptions{}
+}
+
+// NewTransport builds a transport applying o on top of a clone of
+// http.DefaultTransport (keeping its dial / idle / HTTP/2 settings).
+func NewTransport(o TransportOptions) (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if o.CACertFile != "" {
+		pem, err := os.ReadFile(o.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA 証明書を読み込めません: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			// Windows before Go 1.18 / minimal containers: fall back to
+			// the bundle alone rather than failing.
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 証明書に PEM 形式の証明書が含まれていません: %s", o.CACertFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	switch {
+	case o.ClientCertFile != "" && o.ClientKeyFile != "":
+		cert, err := tls.LoadX509KeyPair(o.ClientCertFile, o.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("クライアント証明書 / 鍵を読み込めません: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	case o.ClientCertFile != "" || o.ClientKeyFile != "":
+		return nil, fmt.Errorf("クライアント証明書と鍵は両方指定してください (client_cert / client_key)")
+	}
+
+	tlsCfg.InsecureSkipVerify = o.InsecureSkipVerify
+	tr.TLSClientConfig = tlsCfg
+
+	proxy := http.ProxyFromEnvironment
+	if o.Proxy != "" {
+		u, err := url.Parse(o.Proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("プロキシ URL が不正です: %q", o.Proxy)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	if o.NoProxy != "" {
+		bypass := parseNoProxy(o.NoProxy)
+		next := proxy
+		proxy = func(req *http.Request) (*url.URL, error) {
+			if bypass.matches(req.URL.Hostname()) {
+				return nil, nil
+			}
+			return next(req)
+		}
+	}
+	tr.Proxy = proxy
+	return tr, nil
+}
+
+// SetTransport replaces the round tripper used for API requests.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.httpClient.Transport = rt
+}
+
+// noProxyList is a parsed NO_PROXY value.
+type noProxyList struct {
+	all     bool
+	hosts   []string // lower-cased, leading "." stripped
+	ips     []net.IP
+	subnets []*net.IPNet
+}
+
+func parseNoProxy(v string) noProxyList {
+	var l noProxyList
+	for _, e := range strings.Split(v, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if e == "*" {
+			l.all = true
+			continue
+		}
+		if _, n, err := net.ParseCIDR(e); err == nil {
+			l.subnets = append(l.subnets, n)
+			continue
+		}
+		if h, _, err := net.SplitHostPort(e); err == nil {
+			// Ports are ignored: the entry bypasses every port of the host.
+			e = h
+		}
+		if ip := net.ParseIP(e); ip != nil {
+			l.ips = append(l.ips, ip)
+			continue
+		}
+		l.hosts = append(l.hosts, strings.TrimPrefix(e, "."))
+	}
+	return l
+}
+
+func (l noProxyList) matches(host string) bool {
+	if l.all {
+		return true
+	}
+	host = strings.ToLower(host)
+	if ip := net.ParseIP(host); ip != nil {
+		for _, x := range l.ips {
+			if x.Equal(ip) {
+				return true
+			}
+		}
+		for _, n := range l.subnets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, h := range l.hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
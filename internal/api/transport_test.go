@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePEM(t *testing.T, name, typ string, der []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestNewTransport_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"projects":[]}`))
+	}))
+	defer server.Close()
+
+	// Without the bundle the test server's self-signed cert is rejected.
+	plain := NewClient(server.URL, "k")
+	plain.SetRetryPolicy(NoRetry)
+	if _, err := plain.ListProjects(context.Background()); err == nil {
+		t.Fatal("ListProjects() succeeded against an untrusted certificate")
+	}
+
+	ca := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	tr, err := NewTransport(TransportOptions{CACertFile: ca})
+	if err != nil {
+		t.Fatalf("NewTransport() = %v", err)
+	}
+	client := NewClient(server.URL, "k")
+	client.SetTransport(tr)
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Errorf("ListProjects() with CA bundle = %v", err)
+	}
+}
+
+func TestNewTransport_ClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sbomhub-cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := writePEM(t, "client.pem", "CERTIFICATE", der)
+	keyFile := writePEM(t, "client-key.pem", "EC PRIVATE KEY", keyDER)
+
+	var gotCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			gotCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		_, _ = w.Write([]byte(`{"projects":[]}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	tr, err := NewTransport(TransportOptions{
+		CACertFile:     writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw),
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	})
+	if err != nil {
+		t.Fatalf("NewTransport() = %v", err)
+	}
+	client := NewClient(server.URL, "k")
+	client.SetTransport(tr)
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects() with client cert = %v", err)
+	}
+	if gotCN != "sbomhub-cli-test" {
+		t.Errorf("server saw client CN %q, want sbomhub-cli-test", gotCN)
+	}
+
+	if _, err := NewTransport(TransportOptions{ClientCertFile: certFile}); err == nil {
+		t.Error("NewTransport() accepted a client cert without a key")
+	}
+}
+
+func TestNewTransport_ProxyAndNoProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL.
+		proxied = append(proxied, r.URL.String())
+		_, _ = w.Write([]byte(`{"projects":[]}`))
+	}))
+	defer proxy.Close()
+
+	tr, err := NewTransport(TransportOptions{Proxy: proxy.URL, NoProxy: ".internal.example, 10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("NewTransport() = %v", err)
+	}
+
+	for _, tc := range []struct {
+		target string
+		want   bool
+	}{
+		{"http://api.example.com/x", true},
+		{"http://sbomhub.internal.example/x", false},
+		{"http://internal.example/x", false},
+		{"http://10.1.2.3/x", false},
+		{"http://192.168.0.1/x", true},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, tc.target, nil)
+		u, err := tr.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s) = %v", tc.target, err)
+		}
+		if got := u != nil; got != tc.want {
+			t.Errorf("Proxy(%s) proxied = %v, want %v", tc.target, got, tc.want)
+		}
+	}
+
+	client := NewClient("http://api.example.com", "k")
+	client.SetTransport(tr)
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatalf("ListProjects() via proxy = %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://api.example.com/api/v1/cli/projects?limit=100" {
+		t.Errorf("proxy saw %q, want the API request", proxied)
+	}
+
+	if _, err := NewTransport(TransportOptions{Proxy: "not a url"}); err == nil {
+		t.Error("NewTransport() accepted a malformed proxy URL")
+	}
+}
@@ -22,14 +22,11 @@ package api
 // disabled" reason text from llm.DisabledError end-to-end.
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"strings"
 )
 
 // ----------------------------------------------------------------------------
@@ -92,17 +89,17 @@ type ParsedDecision struct {
 // not currently surface them but they would round-trip cleanly into a
 // debug `--json` flag.
 type VEXDraft struct {
-	ID              string  `json:"id"`
-	ProjectID       string  `json:"project_id"`
-	ComponentID     string  `json:"component_id"`
-	VulnerabilityID string  `json:"vulnerability_id"`
-	CVEID           string  `json:"cve_id"`
-	State           string  `json:"state"`
-	Justification   string  `json:"justification"`
-	Detail          string  `json:"detail"`
+	ID              string   `json:"id"`
+	ProjectID       string   `json:"project_id"`
+	ComponentID     string   `json:"component_id"`
+	VulnerabilityID string   `json:"vulnerability_id"`
+	CVEID           string   `json:"cve_id"`
+	State           string   `json:"state"`
+	Justification   string   `json:"justification"`
+	Detail          string   `json:"detail"`
 	Confidence      *float64 `json:"confidence,omitempty"`
-	Provider        string  `json:"provider,omitempty"`
-	Model           string  `json:"model,omitempty"`
+	Provider        string   `json:"provider,omitempty"`
+	Model           string   `json:"model,omitempty"`
 	// Evidence here is the server-persisted JSONB array — same shape
 	// as ParsedDecision.Evidence but the field name in the DB row is
 	// `evidence` per repository.VEXDraft.
@@ -110,6 +107,14 @@ type VEXDraft struct {
 	Decision  string          `json:"decision"`
 	CreatedAt string          `json:"created_at,omitempty"`
 	UpdatedAt string          `json:"updated_at,omitempty"`
+	// DecidedBy / DecidedAt record who approved the draft and when, so
+	// `scan` / `check` can attribute a VEX-suppressed finding to a person.
+	//
+	// ※要確認: field names assumed from the decision audit columns
+	// (vex_drafts.decided_by / decided_at). Older servers omit them; the
+	// CLI then reports the decider as unknown rather than failing.
+	DecidedBy string `json:"decided_by,omitempty"`
+	DecidedAt string `json:"decided_at,omitempty"`
 }
 
 // TriageRunResult is what POST /triage/run returns on success (201).
@@ -144,7 +149,7 @@ type TriageRunResult struct {
 	// the F23 protocol-violation signal: the handler returned HTTP 200
 	// but logically failed, almost always because the upstream LLM
 	// provider raised an exception that the handler papered over. The
-	// CLI promotes this to a transient *TriageError in RunTriage so
+	// CLI promotes this to a transient *Error in RunTriage so
 	// the exit-code path can flag the failure (M1 Codex review #F23).
 	Error string `json:"error,omitempty"`
 }
@@ -152,6 +157,9 @@ type TriageRunResult struct {
 // vexDraftListResponse mirrors handler.vexDraftListResponse.
 type vexDraftListResponse struct {
 	Drafts []VEXDraft `json:"drafts"`
+	// NextCursor is followed only when the server declares
+	// FeatureCursorPagination (see Client.pageCursor).
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // VEXDraftListFilter narrows ListVEXDrafts. Empty fields are not sent
@@ -178,191 +186,6 @@ type DecisionRequest struct {
 	Note                string `json:"note,omitempty"`
 }
 
-// ----------------------------------------------------------------------------
-// Error decoding
-// ----------------------------------------------------------------------------
-
-// TriageError is the typed error returned by the triage helpers when the
-// server emits a non-2xx response. It carries the parsed JSON body
-// (`error` + optional `reason`) so the CLI can branch on:
-//
-//   - 503 + reason  → llm.DisabledError ("AI features are disabled") →
-//     fall back to under_investigation
-//   - 4xx           → permanent user / config error
-//   - 5xx (non-503) → transient server error
-//
-// Callers test category with the helpers IsAIDisabled / IsPermanent
-// rather than reaching into StatusCode directly, so the classification
-// stays in one place.
-type TriageError struct {
-	StatusCode int
-	URL        string
-	Method     string
-	Message    string // top-level "error" field
-	Reason     string // "reason" field (only populated for 503 DisabledError)
-	Raw        string // original body, preserved for debug logging
-
-	// ProtocolError flags a synthesised TriageError raised for a 2xx
-	// response that violated the success contract — server returned
-	// HTTP 200/201 but the body had no draft (and was not flagged
-	// ai_disabled), or carried an explicit "error" field. Callers
-	// inspect this via IsTransient (always true when set) so the CLI
-	// triage loop surfaces such bugs through the exit-4 transient path
-	// instead of silently bucketing the vuln as `skipped` (M1 Codex
-	// review #F23).
-	ProtocolError bool
-}
-
-func (e *TriageError) Error() string {
-	if e.Reason != "" {
-		return fmt.Sprintf("triage API %s %s -> %d: %s (%s)", e.Method, e.URL, e.StatusCode, e.Message, e.Reason)
-	}
-	if e.Message != "" {
-		return fmt.Sprintf("triage API %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
-	}
-	return fmt.Sprintf("triage API %s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Raw)
-}
-
-// aiDisabledReasonMarkers are the substrings (case-insensitive) we
-// recognise as the legacy BYOK-not-configured signal on a 503 response.
-// F19 moved the canonical AI-disabled path onto 2xx + ai_disabled=true,
-// so this list only catches OLD servers that still emit
-// llm.DisabledError on 503. Anything else on 503 is a real outage and
-// must classify as transient (see IsTransient).
-//
-// ※要確認: kept in lower-case for case-insensitive substring match.
-// The exact server reason text was `"AI features are disabled"` (with
-// reason `"no LLM provider configured"`) per the legacy DisabledError.
-// Synonyms ("BYOK key not configured") guard against minor copy edits.
-var aiDisabledReasonMarkers = []string{
-	"ai features are disabled",
-	"byok key not configured",
-	"byok not configured",
-}
-
-// IsAIDisabled reports whether the server signalled the BYOK-not-configured
-// fallback path. The canonical M1 server returns this on a 2xx response
-// with TriageRunResult.AIDisabled=true — the CLI does not consult this
-// helper on the 2xx success path (it inspects AIDisabled directly).
-//
-// M1 Codex review #F22: legacy server compatibility — a server that
-// has not yet shipped the F19 2xx+ai_disabled change still returns 503
-// from llm.DisabledError. We accept that legacy shape only when the
-// response carries the known reason text. Any other 503 is treated as
-// a transient outage (gateway timeout, pgx connection refused, server
-// overload) and surfaces through IsTransient → exit code 4. Previously
-// every 503 was silently swallowed as "AI off" and CI exited 0 with
-// zero persisted drafts on a real outage.
-func (e *TriageError) IsAIDisabled() bool {
-	if e == nil || e.StatusCode != http.StatusServiceUnavailable {
-		return false
-	}
-	// Lower-case once so the substring match is case-insensitive across
-	// Message + Reason + Raw — the server has historically emitted the
-	// reason text in any of the three slots depending on whether the
-	// JSON body parsed cleanly.
-	hay := strings.ToLower(e.Message + " " + e.Reason + " " + e.Raw)
-	for _, m := range aiDisabledReasonMarkers {
-		if strings.Contains(hay, m) {
-			return true
-		}
-	}
-	return false
-}
-
-// IsPermanent reports whether the error is a permanent 4xx that the
-// caller cannot retry without fixing config (auth / input validation /
-// missing draft). 429 is intentionally treated as transient (not
-// permanent) to match the same R13 classification the scan polling
-// loop uses.
-//
-// 503 is never permanent — it is either AI-disabled (handled by
-// IsAIDisabled / the per-vuln short-circuit) or a transient outage
-// (handled by IsTransient). Treating it as permanent would inflate the
-// permanent-failure exit code on a legitimate retry case (M1 Codex
-// review #F21, #F22).
-func (e *TriageError) IsPermanent() bool {
-	if e == nil {
-		return false
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return false
-	}
-	if e.StatusCode == http.StatusServiceUnavailable {
-		// AI-disabled (legacy) vs transient outage — both handled
-		// elsewhere, never as permanent.
-		return false
-	}
-	return e.StatusCode >= 400 && e.StatusCode < 500
-}
-
-// IsTransient reports whether the error is a transient failure the
-// operator can resolve by retrying — 429 (rate limit) and 5xx (server
-// error / upstream blip). The bucket is symmetric with IsPermanent so
-// runTriageLoop can categorise each failure into exactly one of:
-// AI-disabled (caller-handled), permanent, transient, or otherwise.
-//
-// M1 Codex review #F21: this helper exists so the CLI's triage loop
-// can distinguish "the server is broken right now, retry tomorrow"
-// (transient → exit 4) from "the operator's API key cannot do this
-// thing, fix config" (permanent → exit 3) — silently swallowing both
-// as "skipped" used to make CI green on 403/429 storms.
-//
-// M1 Codex review #F22: a 503 with no recognised AI-disabled marker
-// is now classified transient. Previously the helper returned false
-// for every 503 because IsAIDisabled was assumed to swallow them all;
-// after the strict IsAIDisabled check, real upstream outages (pgx
-// connection refused, gateway timeout, server overload) reach this
-// helper and must surface as exit 4.
-//
-// M1 Codex review #F23: ProtocolError signals a synthesised TriageError
-// for a 2xx response that violated the success contract (missing draft
-// or error field present). The operator's correct response is the same
-// as for any transient — retry, possibly after a server redeploy — so
-// it joins the transient bucket here.
-func (e *TriageError) IsTransient() bool {
-	if e == nil {
-		return false
-	}
-	if e.ProtocolError {
-		return true
-	}
-	if e.StatusCode == http.StatusTooManyRequests {
-		return true
-	}
-	if e.StatusCode == http.StatusServiceUnavailable {
-		// AI-disabled (legacy) wins via IsAIDisabled() and the per-vuln
-		// short-circuit; everything else on 503 is a real outage that
-		// the operator can resolve by retrying. F22: previously this
-		// returned false unconditionally for 503 and the failure was
-		// silently dropped.
-		return !e.IsAIDisabled()
-	}
-	return e.StatusCode >= 500 && e.StatusCode < 600
-}
-
-// decodeTriageError builds a TriageError from a non-2xx response body.
-// We tolerate non-JSON bodies (some intermediate gateways send HTML
-// 502 pages) — Raw always carries the original text so the operator
-// can still see what came back.
-func decodeTriageError(method, url string, status int, body []byte) *TriageError {
-	te := &TriageError{
-		StatusCode: status,
-		URL:        url,
-		Method:     method,
-		Raw:        string(body),
-	}
-	var parsed struct {
-		Error  string `json:"error"`
-		Reason string `json:"reason"`
-	}
-	if err := json.Unmarshal(body, &parsed); err == nil {
-		te.Message = parsed.Error
-		te.Reason = parsed.Reason
-	}
-	return te
-}
-
 // ----------------------------------------------------------------------------
 // RunTriage — POST /api/v1/projects/:id/triage/run
 // ----------------------------------------------------------------------------
@@ -371,7 +194,7 @@ func decodeTriageError(method, url string, status int, body []byte) *TriageError
 // on the server and returns the persisted draft + parsed decision +
 // the clamping outcome.
 //
-// 503 from llm.DisabledError is returned as a *TriageError where
+// 503 from llm.DisabledError is returned as a *Error where
 // IsAIDisabled() == true — the CLI inspects that with errors.As and
 // short-circuits the interactive loop into the under_investigation
 // fallback.
@@ -383,24 +206,18 @@ func (c *Client) RunTriage(ctx context.Context, projectID string, req TriageRunR
 		return nil, fmt.Errorf("triage リクエストのシリアライズに失敗: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	resp, err := c.send(ctx, apiRequest{
+		method:         http.MethodPost,
+		url:            endpoint,
+		body:           body,
+		okStatus:       []int{http.StatusCreated, http.StatusOK},
+		decodeError:    serviceDecoder("triage"),
+		idempotencyKey: true,
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("triage リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, decodeTriageError(http.MethodPost, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	var out TriageRunResult
 	if err := json.Unmarshal(respBody, &out); err != nil {
@@ -417,7 +234,8 @@ func (c *Client) RunTriage(ctx context.Context, projectID string, req TriageRunR
 	// through IsTransient → exit code 4 so CI can retry rather than
 	// silently green-light an empty run.
 	if out.Error != "" {
-		return nil, &TriageError{
+		return nil, &Error{
+			Service:       "triage",
 			StatusCode:    resp.StatusCode,
 			URL:           endpoint,
 			Method:        http.MethodPost,
@@ -433,7 +251,8 @@ func (c *Client) RunTriage(ctx context.Context, projectID string, req TriageRunR
 		} else {
 			msg = "triage success response missing draft (server protocol error — F23 contract violation)"
 		}
-		return nil, &TriageError{
+		return nil, &Error{
+			Service:       "triage",
 			StatusCode:    resp.StatusCode,
 			URL:           endpoint,
 			Method:        http.MethodPost,
@@ -449,10 +268,19 @@ func (c *Client) RunTriage(ctx context.Context, projectID string, req TriageRunR
 // ListVEXDrafts — GET /api/v1/projects/:id/vex-drafts
 // ----------------------------------------------------------------------------
 
-// ListVEXDrafts returns the project's existing VEX drafts, optionally
-// filtered by CVE ID / decision / paginated. Returns an empty slice
-// (not nil) when there are no drafts so callers can `range` safely.
+// ListVEXDrafts returns one page of the project's VEX drafts, optionally
+// filtered by CVE ID / decision, at filter.Limit / filter.Offset (both
+// left to the server's defaults when zero). Returns an empty slice (not
+// nil) when there are no drafts so callers can `range` safely.
 func (c *Client) ListVEXDrafts(ctx context.Context, projectID string, filter VEXDraftListFilter) ([]VEXDraft, error) {
+	page, err := c.listVEXDraftsPage(ctx, projectID, filter, PageRequest{Limit: filter.Limit, Offset: filter.Offset})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func (c *Client) listVEXDraftsPage(ctx context.Context, projectID string, filter VEXDraftListFilter, req PageRequest) (Page[VEXDraft], error) {
 	endpoint := fmt.Sprintf("%s/api/v1/projects/%s/vex-drafts", c.baseURL, projectID)
 
 	q := url.Values{}
@@ -462,41 +290,59 @@ func (c *Client) ListVEXDrafts(ctx context.Context, projectID string, filter VEX
 	if filter.Decision != "" {
 		q.Set("decision", filter.Decision)
 	}
-	if filter.Limit > 0 {
-		q.Set("limit", fmt.Sprintf("%d", filter.Limit))
-	}
-	if filter.Offset > 0 {
-		q.Set("offset", fmt.Sprintf("%d", filter.Offset))
-	}
+	setPageQuery(q, req)
 	if encoded := q.Encode(); encoded != "" {
 		endpoint = endpoint + "?" + encoded
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodGet,
+		url:         endpoint,
+		decodeError: serviceDecoder("triage"),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("vex-drafts リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, decodeTriageError(http.MethodGet, endpoint, resp.StatusCode, respBody)
+		return Page[VEXDraft]{}, err
 	}
+	respBody := resp.Body
 
 	var out vexDraftListResponse
 	if err := json.Unmarshal(respBody, &out); err != nil {
-		return nil, fmt.Errorf("vex-drafts レスポンス解析エラー: %w", err)
+		return Page[VEXDraft]{}, fmt.Errorf("vex-drafts レスポンス解析エラー: %w", err)
 	}
 	if out.Drafts == nil {
-		return []VEXDraft{}, nil
+		out.Drafts = []VEXDraft{}
 	}
-	return out.Drafts, nil
+	return Page[VEXDraft]{Items: out.Drafts, Total: totalCount(resp.Header), NextCursor: c.pageCursor(out.NextCursor)}, nil
+}
+
+// VEXDraftsPageSize / listVEXDraftsMaxPages bound the vex-drafts walk
+// the same way VulnerabilitiesPageSize / listVulnerabilitiesMaxPages
+// bound the vulnerabilities one.
+//
+// ※要確認: 100 is the server's documented default page size for
+// vex-drafts; the handler's upper clamp is not published, so we stay at
+// the default rather than risk a 400 on the first page.
+const (
+	VEXDraftsPageSize     = 100
+	listVEXDraftsMaxPages = 200
+)
+
+// ListAllVEXDrafts returns every draft matching filter. Used by the
+// `scan` / `check` gates, which must see every approved decision:
+// stopping at the first page would silently re-fail findings that were
+// triaged long ago.
+func (c *Client) ListAllVEXDrafts(ctx context.Context, projectID string, filter VEXDraftListFilter) ([]VEXDraft, error) {
+	return c.PaginateVEXDrafts(projectID, filter).All(ctx)
+}
+
+// PaginateVEXDrafts walks the drafts matching filter lazily.
+// filter.Limit / filter.Offset are ignored — the paging contract lives
+// in the Paginator.
+func (c *Client) PaginateVEXDrafts(projectID string, filter VEXDraftListFilter) *Paginator[VEXDraft] {
+	return NewPaginator("vex-drafts", VEXDraftsPageSize, listVEXDraftsMaxPages,
+		func(ctx context.Context, req PageRequest) (Page[VEXDraft], error) {
+			return c.listVEXDraftsPage(ctx, projectID, filter, req)
+		})
 }
 
 // ----------------------------------------------------------------------------
@@ -515,23 +361,16 @@ func (c *Client) DecideDraft(ctx context.Context, projectID, draftID string, dec
 		return nil, fmt.Errorf("decision リクエストのシリアライズに失敗: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodPut,
+		url:         endpoint,
+		body:        body,
+		decodeError: serviceDecoder("triage"),
+	})
 	if err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("decision リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, decodeTriageError(http.MethodPut, endpoint, resp.StatusCode, respBody)
-	}
+	respBody := resp.Body
 
 	var out VEXDraft
 	if err := json.Unmarshal(respBody, &out); err != nil {
@@ -569,7 +408,7 @@ type VulnerabilityRecord struct {
 	Source      string  `json:"source,omitempty"`
 }
 
-// listVulnerabilitiesPageSize is the per-page request size the CLI uses
+// VulnerabilitiesPageSize is the per-page request size the CLI uses
 // when paging /api/v1/projects/:id/vulnerabilities. M1 Codex review #F26:
 // the server now caps `?limit=` at 500 (handler.VulnsMaxLimit) and returns
 // 400 on out-of-band values, so the CLI requests pages at the maximum
@@ -580,7 +419,7 @@ type VulnerabilityRecord struct {
 // VulnsMaxLimit (500). If the server raises the cap, the CLI can be
 // updated independently, but a value above the server's cap will start
 // returning 400 on the first request.
-const listVulnerabilitiesPageSize = 500
+const VulnerabilitiesPageSize = 500
 
 // listVulnerabilitiesMaxPages is a defensive ceiling on the number of
 // pages the CLI is willing to fetch before erroring out. At 500 rows per
@@ -600,67 +439,43 @@ const listVulnerabilitiesMaxPages = 200
 // triage CLI can iterate over them.
 //
 // M1 Codex review #F26: the server now paginates responses with
-// `?limit=&offset=` (default 100, max 500). The CLI pages through using
-// listVulnerabilitiesPageSize per request and stops when either:
-//   - a page returns fewer rows than the requested page size (canonical
-//     "no more rows" signal for offset-based pagination), or
-//   - listVulnerabilitiesMaxPages is reached (defensive ceiling).
-//
-// The server returns a bare JSON array per page (no envelope) so the
-// existing Web UI fetch path keeps working unchanged. We retain the
-// enveloped-shape fallback for forward compatibility with a future
-// server that may switch to `{ "vulnerabilities": [...] }` — but the
-// fallback only applies to single-page responses (a server that
-// switches to envelopes would also have to add a paging cursor, at
-// which point this client needs an update).
+// `?limit=&offset=` (default 100, max 500). The walk is
+// PaginateVulnerabilities drained to the end; see Paginator for when it
+// stops.
 func (c *Client) ListVulnerabilities(ctx context.Context, projectID string) ([]VulnerabilityRecord, error) {
-	all := make([]VulnerabilityRecord, 0, listVulnerabilitiesPageSize)
-	offset := 0
-	for page := 0; page < listVulnerabilitiesMaxPages; page++ {
-		batch, err := c.listVulnerabilitiesPage(ctx, projectID, listVulnerabilitiesPageSize, offset)
-		if err != nil {
-			return nil, err
-		}
-		all = append(all, batch...)
-		// Server returned fewer rows than the page size → end of the
-		// dataset. This is the canonical offset-pagination "no more
-		// rows" signal because the server does not (yet) emit a
-		// has_more / next_cursor envelope.
-		if len(batch) < listVulnerabilitiesPageSize {
-			return all, nil
-		}
-		offset += len(batch)
-	}
-	// Defensive ceiling — should be unreachable in practice for realistic
-	// inventories. We return the partial result + an error so the operator
-	// can decide whether to act on what was fetched.
-	return all, fmt.Errorf("vulnerabilities ページング上限 (%d ページ × %d 件) 到達: サーバーがページング終端を返していない可能性があります",
-		listVulnerabilitiesMaxPages, listVulnerabilitiesPageSize)
+	return c.PaginateVulnerabilities(projectID).All(ctx)
 }
 
-// listVulnerabilitiesPage fetches a single (limit, offset) page from the
-// server. Extracted from ListVulnerabilities so the paging loop stays
-// readable and so tests can drive the per-page decode independently.
-func (c *Client) listVulnerabilitiesPage(ctx context.Context, projectID string, limit, offset int) ([]VulnerabilityRecord, error) {
-	endpoint := fmt.Sprintf("%s/api/v1/projects/%s/vulnerabilities?limit=%d&offset=%d",
-		c.baseURL, projectID, limit, offset)
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+// PaginateVulnerabilities walks the project's vulnerabilities lazily,
+// VulnerabilitiesPageSize rows per request.
+func (c *Client) PaginateVulnerabilities(projectID string) *Paginator[VulnerabilityRecord] {
+	return NewPaginator("vulnerabilities", VulnerabilitiesPageSize, listVulnerabilitiesMaxPages,
+		func(ctx context.Context, req PageRequest) (Page[VulnerabilityRecord], error) {
+			return c.ListVulnerabilitiesPage(ctx, projectID, req)
+		})
+}
 
-	resp, err := c.httpClient.Do(httpReq)
+// ListVulnerabilitiesPage fetches the single page req describes.
+//
+// The server returns a bare JSON array per page (no envelope) so the
+// existing Web UI fetch path keeps working unchanged. We retain the
+// enveloped-shape fallback for forward compatibility with a future
+// server that may switch to `{ "vulnerabilities": [...] }`; such a
+// server would page with next_cursor, which the envelope carries.
+func (c *Client) ListVulnerabilitiesPage(ctx context.Context, projectID string, req PageRequest) (Page[VulnerabilityRecord], error) {
+	q := url.Values{}
+	setPageQuery(q, req)
+	endpoint := fmt.Sprintf("%s/api/v1/projects/%s/vulnerabilities?%s", c.baseURL, projectID, q.Encode())
+
+	resp, err := c.send(ctx, apiRequest{
+		method:      http.MethodGet,
+		url:         endpoint,
+		decodeError: serviceDecoder("triage"),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("vulnerabilities リクエスト送信エラー: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, decodeTriageError(http.MethodGet, endpoint, resp.StatusCode, respBody)
+		return Page[VulnerabilityRecord]{}, err
 	}
+	respBody := resp.Body
 
 	// Accept both shapes — the canonical handler returns a bare array,
 	// but defensive decoding for an enveloped `{ "vulnerabilities":
@@ -668,13 +483,18 @@ func (c *Client) listVulnerabilitiesPage(ctx context.Context, projectID string,
 	// without a coordinated release.
 	var bare []VulnerabilityRecord
 	if err := json.Unmarshal(respBody, &bare); err == nil {
-		return bare, nil
+		return Page[VulnerabilityRecord]{Items: bare, Total: totalCount(resp.Header)}, nil
 	}
 	var enveloped struct {
 		Vulnerabilities []VulnerabilityRecord `json:"vulnerabilities"`
+		NextCursor      string                `json:"next_cursor"`
 	}
 	if err := json.Unmarshal(respBody, &enveloped); err != nil {
-		return nil, fmt.Errorf("vulnerabilities レスポンス解析エラー: %w", err)
+		return Page[VulnerabilityRecord]{}, fmt.Errorf("vulnerabilities レスポンス解析エラー: %w", err)
 	}
-	return enveloped.Vulnerabilities, nil
+	return Page[VulnerabilityRecord]{
+		Items:      enveloped.Vulnerabilities,
+		Total:      totalCount(resp.Header),
+		NextCursor: c.pageCursor(enveloped.NextCursor),
+	}, nil
 }
@@ -81,7 +81,7 @@ func TestRunTriage_HappyPath(t *testing.T) {
 	}
 }
 
-// TestRunTriage_AIDisabled verifies the 503 → TriageError +
+// TestRunTriage_AIDisabled verifies the 503 → Error +
 // IsAIDisabled() == true path. This is the canary that catches a
 // regression where the BYOK fallback would be silently treated as a
 // generic server error.
@@ -100,9 +100,9 @@ func TestRunTriage_AIDisabled(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for 503 response")
 	}
-	var te *TriageError
+	var te *Error
 	if !errors.As(err, &te) {
-		t.Fatalf("err = %v (%T), want *TriageError", err, err)
+		t.Fatalf("err = %v (%T), want *Error", err, err)
 	}
 	if !te.IsAIDisabled() {
 		t.Errorf("IsAIDisabled = false, want true")
@@ -137,9 +137,9 @@ func TestRunTriage_PermanentClientError(t *testing.T) {
 			defer server.Close()
 			client := NewClient(server.URL, "k")
 			_, err := client.RunTriage(context.Background(), "p", TriageRunRequest{VulnerabilityID: "v", CVEID: "c"})
-			var te *TriageError
+			var te *Error
 			if !errors.As(err, &te) {
-				t.Fatalf("err = %v, want *TriageError", err)
+				t.Fatalf("err = %v, want *Error", err)
 			}
 			if got := te.IsPermanent(); got != tc.want {
 				t.Errorf("IsPermanent() = %v, want %v", got, tc.want)
@@ -181,6 +181,43 @@ func TestListVEXDrafts_FilterEncoding(t *testing.T) {
 	}
 }
 
+// TestListAllVEXDrafts_Pages verifies the paging loop walks offsets
+// until a short page and forwards the decision filter on every request.
+func TestListAllVEXDrafts_Pages(t *testing.T) {
+	var offsets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("decision"); got != "approved" {
+			t.Errorf("decision = %q, want approved", got)
+		}
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		n := VEXDraftsPageSize
+		if r.URL.Query().Get("offset") != "" {
+			n = 3
+		}
+		drafts := make([]VEXDraft, n)
+		for i := range drafts {
+			drafts[i] = VEXDraft{ID: fmt.Sprintf("d-%d", i), DecidedBy: "alice@example.com"}
+		}
+		_ = json.NewEncoder(w).Encode(vexDraftListResponse{Drafts: drafts})
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "k")
+
+	got, err := client.ListAllVEXDrafts(context.Background(), "pid", VEXDraftListFilter{Decision: "approved", Limit: 5})
+	if err != nil {
+		t.Fatalf("ListAllVEXDrafts error: %v", err)
+	}
+	if len(got) != VEXDraftsPageSize+3 {
+		t.Errorf("len = %d, want %d", len(got), VEXDraftsPageSize+3)
+	}
+	if want := []string{"", fmt.Sprint(VEXDraftsPageSize)}; strings.Join(offsets, ",") != strings.Join(want, ",") {
+		t.Errorf("offsets = %v, want %v", offsets, want)
+	}
+	if got[0].DecidedBy != "alice@example.com" {
+		t.Errorf("DecidedBy = %q, want decoded", got[0].DecidedBy)
+	}
+}
+
 // TestDecideDraft_BodyShape verifies the PUT body the CLI sends.
 func TestDecideDraft_BodyShape(t *testing.T) {
 	var seenBody []byte
@@ -239,7 +276,7 @@ func TestTriageError_IsAIDisabled_503GenericNotMatch_F22(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			te := &TriageError{
+			te := &Error{
 				StatusCode: http.StatusServiceUnavailable,
 				Message:    tc.message,
 			}
@@ -271,7 +308,7 @@ func TestTriageError_IsAIDisabled_503KnownReasonMatch_F22(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			te := &TriageError{
+			te := &Error{
 				StatusCode: http.StatusServiceUnavailable,
 				Message:    tc.message,
 			}
@@ -287,7 +324,7 @@ func TestTriageError_IsAIDisabled_503KnownReasonMatch_F22(t *testing.T) {
 
 // ----------------------------------------------------------------------------
 // F23 regression — a 2xx response with a malformed body (error field, or
-// no draft) must surface as a TriageError so the CLI's exit-code path
+// no draft) must surface as a Error so the CLI's exit-code path
 // can flag the problem instead of silently incrementing `skipped` and
 // exiting 0.
 // ----------------------------------------------------------------------------
@@ -320,9 +357,9 @@ func TestRunTriage_2xxEmptyDraft_ReturnsError_F23(t *testing.T) {
 			if err == nil {
 				t.Fatalf("F23: expected error for 2xx response with no draft, got result %+v", res)
 			}
-			var te *TriageError
+			var te *Error
 			if !errors.As(err, &te) {
-				t.Fatalf("F23: err = %v (%T), want *TriageError", err, err)
+				t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 			}
 			if !te.IsTransient() {
 				t.Errorf("F23: protocol error must be classified transient (server bug, retry recommended)")
@@ -333,7 +370,7 @@ func TestRunTriage_2xxEmptyDraft_ReturnsError_F23(t *testing.T) {
 
 // TestRunTriage_2xxWithErrorField_ReturnsError_F23 — a 200 response
 // carrying an "error" field in the body is a server protocol violation;
-// it must surface as a TriageError so the CLI does not bucket the vuln
+// it must surface as a Error so the CLI does not bucket the vuln
 // as skipped + exit 0.
 func TestRunTriage_2xxWithErrorField_ReturnsError_F23(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -347,9 +384,9 @@ func TestRunTriage_2xxWithErrorField_ReturnsError_F23(t *testing.T) {
 	if err == nil {
 		t.Fatalf("F23: expected error for 2xx response with error field, got result %+v", res)
 	}
-	var te *TriageError
+	var te *Error
 	if !errors.As(err, &te) {
-		t.Fatalf("F23: err = %v (%T), want *TriageError", err, err)
+		t.Fatalf("F23: err = %v (%T), want *Error", err, err)
 	}
 	if !strings.Contains(te.Message, "upstream LLM provider failure") {
 		t.Errorf("F23: error message must round-trip server error field, got %q", te.Message)
@@ -425,7 +462,7 @@ func TestListVulnerabilities_EnvelopedShape(t *testing.T) {
 // contract: when the server paginates responses (default 100, max 500
 // — handler.VulnsMaxLimit), the CLI MUST page through transparently
 // rather than only fetching the first page. Without this loop, a
-// project with > listVulnerabilitiesPageSize matched vulns would be
+// project with > VulnerabilitiesPageSize matched vulns would be
 // silently truncated and `sbomhub triage` would skip the tail.
 //
 // Server behaviour: page 1 returns 500 rows + offset=0, page 2 returns
@@ -516,7 +553,7 @@ func TestListVulnerabilities_SinglePage_NoExtraRequest(t *testing.T) {
 	var requestCount int
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestCount++
-		// Return a tiny page (< listVulnerabilitiesPageSize) so the CLI
+		// Return a tiny page (< VulnerabilitiesPageSize) so the CLI
 		// knows there's nothing more to fetch.
 		_, _ = w.Write([]byte(`[{"id":"v1","cve_id":"CVE-2024-1"},{"id":"v2","cve_id":"CVE-2024-2"}]`))
 	}))
@@ -0,0 +1,172 @@
+package api
+
+// Streaming SBOM upload.
+//
+// UploadSBOM used to take the SBOM as []byte, and the request pipeline
+// re-sent that slice on every attempt. Container SBOMs reach hundreds of
+// MB, and a small CI runner then held the scanner output, the upload
+// buffer and the retry copy at once. An SBOMSource instead re-opens the
+// payload for each attempt (a file on disk in practice), and the body is
+// gzip-compressed on the fly through a pipe, so memory stays flat in the
+// SBOM size.
+//
+// Compression fallback: a server that does not accept the encoding
+// answers 415, or a 400 whose error names Content-Encoding. The upload is
+// then repeated once uncompressed, and the client remembers the refusal
+// for later uploads. Any other 400 is the SBOM itself being rejected and
+// is returned as is: resending it uncompressed would only double the
+// upload and turn compression off for the rest of the run.
+//
+// ※要確認: whether the server decodes `Content-Encoding: gzip` request
+// bodies (or an ingress in front of it does). Until confirmed, the
+// fallback above is what keeps uploads working against servers that do
+// not.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+)
+
+// SBOMSource is an SBOM payload that can be read more than once. Open is
+// called once per upload attempt; Size is the uncompressed length, or -1
+// when unknown.
+type SBOMSource struct {
+	Open func() (io.ReadCloser, error)
+	Size int64
+}
+
+// SBOMBytes wraps an in-memory SBOM.
+func SBOMBytes(data []byte) SBOMSource {
+	return SBOMSource{
+		Open: func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil },
+		Size: int64(len(data)),
+	}
+}
+
+// SBOMFile streams the SBOM at path. The size is taken now; a file that
+// changes between attempts is the caller's problem.
+func SBOMFile(path string) (SBOMSource, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return SBOMSource{}, fmt.Errorf("SBOMファイルを開けません: %w", err)
+	}
+	return SBOMSource{
+		Open: func() (io.ReadCloser, error) { return os.Open(path) },
+		Size: st.Size(),
+	}, nil
+}
+
+// UploadOptions tunes UploadSBOMFrom. The zero value sends the SBOM
+// uncompressed with no progress reporting, as UploadSBOM always did.
+type UploadOptions struct {
+	// Gzip compresses the body with Content-Encoding: gzip, falling back
+	// to identity if the server refuses it.
+	Gzip bool
+	// Progress, when set, is called as the uncompressed SBOM is read
+	// for sending, with the bytes read so far and SBOMSource.Size. A
+	// retried attempt starts again from zero.
+	Progress func(sent, total int64)
+}
+
+// uploadBody POSTs src to url and returns the response together with the
+// Content-Encoding that was accepted.
+func (c *Client) uploadBody(ctx context.Context, url string, src SBOMSource, opts UploadOptions) (*apiResponse, string, error) {
+	req := func(compress bool) apiRequest {
+		return apiRequest{
+			method:         http.MethodPost,
+			url:            url,
+			open:           src.Open,
+			size:           src.Size,
+			gzip:           compress,
+			progress:       opts.Progress,
+			okStatus:       []int{http.StatusOK, http.StatusCreated},
+			idempotencyKey: true,
+			// Client.Timeout counts the body transfer, which for a
+			// large SBOM on a slow runner legitimately exceeds it. The
+			// upload is bounded by ctx (Ctrl-C, CI job timeout) instead.
+			noTimeout: true,
+		}
+	}
+
+	if opts.Gzip && !c.gzipRejected.Load() {
+		resp, err := c.send(ctx, req(true))
+		if err == nil {
+			return resp, "gzip", nil
+		}
+		if !rejectsCompression(err) {
+			return nil, "", err
+		}
+		c.gzipRejected.Store(true)
+		// The fallback is a new send, and so carries a new
+		// Idempotency-Key if any: the refused request created nothing, and
+		// reusing its key with a different body could be reported as a
+		// key conflict.
+	}
+	resp, err := c.send(ctx, req(false))
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, "", nil
+}
+
+// rejectsCompression reports whether err is the answer of a server that
+// could not read a gzip-encoded body (see the file comment).
+func rejectsCompression(err error) bool {
+	var ae *Error
+	if !errors.As(err, &ae) {
+		return false
+	}
+	switch ae.StatusCode {
+	case http.StatusUnsupportedMediaType:
+		return true
+	case http.StatusBadRequest:
+		return namesContentEncoding.MatchString(ae.Raw)
+	}
+	return false
+}
+
+// namesContentEncoding matches an error body that blames the request's
+// Content-Encoding ("unsupported content-encoding", a
+// "content_encoding_not_supported" code, ...).
+var namesContentEncoding = regexp.MustCompile(`(?i)content[-_ ]encoding`)
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	io.ReadCloser
+	sent  int64
+	total int64
+	fn    func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.fn(p.sent, p.total)
+	}
+	return n, err
+}
+
+// gzipStream compresses src into the returned reader as it is consumed.
+// The transport closes the returned reader when it stops sending (done
+// or failed), which makes the pending write fail and ends the goroutine.
+func gzipStream(src io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer src.Close()
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, src)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const uploadProjectID = "00000000-0000-0000-0000-000000000abc"
+
+func TestUploadSBOMFrom_GzipStreamsFile(t *testing.T) {
+	sbom := `{"bomFormat":"CycloneDX","components":[` + strings.Repeat(`{"name":"x"},`, 5000) + `{"name":"y"}]}`
+	path := filepath.Join(t.TempDir(), "sbom.json")
+	if err := os.WriteFile(path, []byte(sbom), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []byte
+	var encoding string
+	var length int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding, length = r.Header.Get("Content-Encoding"), r.ContentLength
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("body is not gzip: %v", err)
+			return
+		}
+		got, _ = io.ReadAll(zr)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"` + uploadProjectID + `"}`))
+	}))
+	defer server.Close()
+
+	src, err := SBOMFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lastSent, lastTotal int64
+	client := NewClient(server.URL, "k")
+	res, err := client.UploadSBOMFrom(context.Background(), uploadProjectID, true, src, "cyclonedx", UploadOptions{
+		Gzip:     true,
+		Progress: func(sent, total int64) { lastSent, lastTotal = sent, total },
+	})
+	if err != nil {
+		t.Fatalf("UploadSBOMFrom() = %v", err)
+	}
+	if encoding != "gzip" || length != -1 {
+		t.Errorf("Content-Encoding = %q, ContentLength = %d; want gzip, chunked", encoding, length)
+	}
+	if string(got) != sbom {
+		t.Errorf("server decoded %d bytes, want the %d-byte SBOM", len(got), len(sbom))
+	}
+	if res.Encoding != "gzip" {
+		t.Errorf("Encoding = %q, want gzip", res.Encoding)
+	}
+	if lastSent != int64(len(sbom)) || lastTotal != int64(len(sbom)) {
+		t.Errorf("last progress = %d/%d, want %d/%d", lastSent, lastTotal, len(sbom), len(sbom))
+	}
+}
+
+func TestUploadSBOMFrom_FallsBackWhenGzipRejected(t *testing.T) {
+	const sbom = `{"bomFormat":"CycloneDX"}`
+	type seen struct{ encoding, key, body string }
+	var reqs []seen
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqs = append(reqs, seen{r.Header.Get("Content-Encoding"), r.Header.Get("Idempotency-Key"), string(body)})
+		if r.Header.Get("Content-Encoding") != "" {
+			http.Error(w, `{"error":"unsupported content encoding"}`, http.StatusUnsupportedMediaType)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"` + uploadProjectID + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "k")
+	client.SetCapabilities(&Capabilities{Published: true, Features: []string{FeatureIdempotencyKey}})
+	res, err := client.UploadSBOMFrom(context.Background(), uploadProjectID, true, SBOMBytes([]byte(sbom)), "cyclonedx", UploadOptions{Gzip: true})
+	if err != nil {
+		t.Fatalf("UploadSBOMFrom() = %v, want success after the identity fallback", err)
+	}
+	if len(reqs) != 2 || reqs[0].encoding != "gzip" || reqs[1].encoding != "" || reqs[1].body != sbom {
+		t.Fatalf("requests = %+v, want gzip then identity", reqs)
+	}
+	if reqs[0].key == reqs[1].key {
+		t.Error("the identity fallback reused the refused request's Idempotency-Key")
+	}
+	if res.Encoding != "" {
+		t.Errorf("Encoding = %q, want identity", res.Encoding)
+	}
+
+	// The refusal is remembered: the next upload goes straight to identity.
+	reqs = nil
+	if _, err := client.UploadSBOMFrom(context.Background(), uploadProjectID, true, SBOMBytes([]byte(sbom)), "cyclonedx", UploadOptions{Gzip: true}); err != nil {
+		t.Fatalf("second UploadSBOMFrom() = %v", err)
+	}
+	if len(reqs) != 1 || reqs[0].encoding != "" {
+		t.Errorf("second upload requests = %+v, want a single identity request", reqs)
+	}
+}
+
+func TestUploadSBOMFrom_ValidationErrorIsNotRetried(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		fallback bool
+	}{
+		{"schema error", `{"error":"invalid SBOM: components[0].name is required"}`, false},
+		{"encoding refused", `{"error":"unsupported Content-Encoding: gzip"}`, true},
+		{"encoding code", `{"error":"bad request","code":"content_encoding_not_supported"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var encodings []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encodings = append(encodings, r.Header.Get("Content-Encoding"))
+				if r.Header.Get("Content-Encoding") == "" {
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"` + uploadProjectID + `"}`))
+					return
+				}
+				http.Error(w, tt.body, http.StatusBadRequest)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "k")
+			_, err := client.UploadSBOMFrom(context.Background(), uploadProjectID, true, SBOMBytes([]byte(`{}`)), "cyclonedx", UploadOptions{Gzip: true})
+			if tt.fallback {
+				if err != nil || len(encodings) != 2 || !client.gzipRejected.Load() {
+					t.Errorf("err = %v, requests %q, gzipRejected %v; want one identity fallback", err, encodings, client.gzipRejected.Load())
+				}
+				return
+			}
+			var ae *Error
+			if !errors.As(err, &ae) || ae.StatusCode != http.StatusBadRequest || !strings.Contains(ae.Error(), "components[0].name") {
+				t.Errorf("err = %v, want the validation 400", err)
+			}
+			if len(encodings) != 1 || client.gzipRejected.Load() {
+				t.Errorf("requests %q, gzipRejected %v; want the single gzip attempt and compression kept", encodings, client.gzipRejected.Load())
+			}
+		})
+	}
+}
+
+func TestUploadSBOMFrom_RetryReopensSource(t *testing.T) {
+	const sbom = `{"bomFormat":"CycloneDX"}`
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"sbom-1","project_id":"` + uploadProjectID + `"}`))
+	}))
+	defer server.Close()
+
+	opens := 0
+	src := SBOMSource{
+		Open: func() (io.ReadCloser, error) {
+			opens++
+			return io.NopCloser(strings.NewReader(sbom)), nil
+		},
+		Size: int64(len(sbom)),
+	}
+	client := NewClient(server.URL, "k")
+	client.SetCapabilities(&Capabilities{Published: true, Features: []string{FeatureIdempotencyKey}})
+	recordSleeps(client)
+	if _, err := client.UploadSBOMFrom(context.Background(), uploadProjectID, true, src, "cyclonedx", UploadOptions{}); err != nil {
+		t.Fatalf("UploadSBOMFrom() = %v", err)
+	}
+	if opens != 2 || len(bodies) != 2 || bodies[1] != sbom {
+		t.Errorf("opens = %d, bodies = %q; want the source re-opened for the retry", opens, bodies)
+	}
+}
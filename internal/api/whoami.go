@@ -0,0 +1,105 @@
+package api
+
+// Credential introspection.
+//
+// Triage, CRA and METI writes answer 403 when the key is read-only, and
+// the triage loop used to find that out one vulnerability at a time —
+// after paying for the LLM run on each. GET /api/v1/cli/whoami describes
+// the caller (tenant, user, key, role, scopes, expiry) so `sbomhub
+// whoami` can show it and write commands can check up front.
+//
+// The commands only ask a server that declares FeatureWhoami; against
+// any other server they go ahead as before and the server's own check
+// still applies. A 404 from WhoAmI is answered the same way: it returns
+// Published=false and Can reports "unknown".
+//
+// Scopes are "<family>:<read|write>" (triage, vex, cra, meti, projects,
+// sbom), with "<family>:*" and "*" as wildcards; a key without scopes is
+// judged by its role (owner / admin / member / editor may write, viewer
+// / read_only may not).
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Scopes the CLI checks before write operations.
+const (
+	ScopeTriageWrite   = "triage:write"
+	ScopeCRAWrite      = "cra:write"
+	ScopeMETIWrite     = "meti:write"
+	ScopeProjectsWrite = "projects:write"
+	ScopeSBOMWrite     = "sbom:write"
+)
+
+// Identity is the server's description of the credential in use.
+type Identity struct {
+	TenantID   string     `json:"tenant_id"`
+	TenantName string     `json:"tenant_name,omitempty"`
+	UserID     string     `json:"user_id,omitempty"`
+	UserEmail  string     `json:"user_email,omitempty"`
+	KeyID      string     `json:"key_id,omitempty"`
+	KeyName    string     `json:"key_name,omitempty"`
+	Role       string     `json:"role,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+
+	// Published is false when the server has no whoami endpoint (404);
+	// every other field is then empty.
+	Published bool `json:"published"`
+}
+
+// Can reports whether the credential may use scope. known is false when
+// the server did not say enough to tell (no endpoint, or neither scopes
+// nor a recognised role), in which case callers should just try.
+func (id *Identity) Can(scope string) (allowed, known bool) {
+	if id == nil || !id.Published {
+		return false, false
+	}
+	if len(id.Scopes) > 0 {
+		family, _, _ := strings.Cut(scope, ":")
+		for _, s := range id.Scopes {
+			if s == scope || s == "*" || s == family+":*" {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	switch strings.ToLower(id.Role) {
+	case "owner", "admin", "member", "editor":
+		return true, true
+	case "viewer", "read_only", "readonly":
+		return strings.HasSuffix(scope, ":read"), true
+	}
+	return false, false
+}
+
+// Expired reports whether the credential has an expiry that has passed.
+func (id *Identity) Expired(now time.Time) bool {
+	return id != nil && id.ExpiresAt != nil && !now.Before(*id.ExpiresAt)
+}
+
+// WhoAmI fetches GET /api/v1/cli/whoami. A 404 yields Published=false and
+// no error.
+func (c *Client) WhoAmI(ctx context.Context) (*Identity, error) {
+	url := fmt.Sprintf("%s/api/v1/cli/whoami", c.baseURL)
+	resp, err := c.send(ctx, apiRequest{method: http.MethodGet, url: url})
+	if err != nil {
+		var ae *Error
+		if errors.As(err, &ae) && ae.StatusCode == http.StatusNotFound {
+			return &Identity{}, nil
+		}
+		return nil, err
+	}
+	var id Identity
+	if err := json.Unmarshal(resp.Body, &id); err != nil {
+		return nil, fmt.Errorf("whoami レスポンス解析エラー: %w", err)
+	}
+	id.Published = true
+	return &id, nil
+}
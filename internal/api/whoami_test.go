@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWhoAmI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/cli/whoami" || r.Header.Get("Authorization") != "Bearer sbh_ro" {
+			t.Errorf("%s with Authorization %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"tenant_id":"t-1","tenant_name":"Acme","key_name":"ci","role":"viewer","scopes":["projects:read","triage:read"],"expires_at":"2030-01-02T03:04:05Z"}`))
+	}))
+	defer server.Close()
+
+	id, err := NewClient(server.URL, "sbh_ro").WhoAmI(context.Background())
+	if err != nil {
+		t.Fatalf("WhoAmI() = %v", err)
+	}
+	if !id.Published || id.TenantName != "Acme" || id.KeyName != "ci" || id.ExpiresAt == nil {
+		t.Errorf("identity = %+v", id)
+	}
+	if allowed, known := id.Can(ScopeTriageWrite); allowed || !known {
+		t.Errorf("Can(triage:write) = %v, %v; want a known denial", allowed, known)
+	}
+	if id.Expired(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expired() before expires_at")
+	}
+}
+
+func TestWhoAmI_NotFoundIsUnpublished(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	id, err := NewClient(server.URL, "k").WhoAmI(context.Background())
+	if err != nil {
+		t.Fatalf("WhoAmI() = %v, want no error for a 404", err)
+	}
+	if _, known := id.Can(ScopeCRAWrite); id.Published || known {
+		t.Errorf("identity = %+v, known = %v; want unpublished and unknown", id, known)
+	}
+}
+
+func TestIdentityCan(t *testing.T) {
+	tests := []struct {
+		id             Identity
+		scope          string
+		allowed, known bool
+	}{
+		{Identity{Scopes: []string{"cra:*"}}, ScopeCRAWrite, true, true},
+		{Identity{Scopes: []string{"*"}}, ScopeMETIWrite, true, true},
+		{Identity{Scopes: []string{"cra:read"}}, ScopeCRAWrite, false, true},
+		{Identity{Role: "admin"}, ScopeTriageWrite, true, true},
+		{Identity{Role: "read_only"}, ScopeTriageWrite, false, true},
+		{Identity{Role: "auditor"}, ScopeTriageWrite, false, false},
+		{Identity{}, ScopeTriageWrite, false, false},
+	}
+	for _, tt := range tests {
+		tt.id.Published = true
+		allowed, known := tt.id.Can(tt.scope)
+		if allowed != tt.allowed || known != tt.known {
+			t.Errorf("%+v.Can(%s) = %v, %v; want %v, %v", tt.id, tt.scope, allowed, known, tt.allowed, tt.known)
+		}
+	}
+}
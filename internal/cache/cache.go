@@ -0,0 +1,235 @@
+// Package cache is the local result cache behind `sbomhub check` and
+// `sbomhub scan`. Two things are slow and usually unchanged between runs
+// on the same checkout: generating the SBOM (syft / trivy / cdxgen walk
+// the whole tree) and the server-side vulnerability check. Both are
+// cached as opaque blobs under the user cache directory:
+//
+//	<UserCacheDir>/sbomhub/<kind>/<key[:2]>/<key>
+//
+// Keys are SHA-256 hex digests built by the caller with Key from every
+// input that can change the result — for SBOM generation the manifest /
+// lockfile contents (InputsHash), tool and tool version, format and
+// path; for check results the SBOM bytes and the server URL. Freshness
+// is the file's mtime against a caller-supplied TTL, so there is no
+// index to corrupt and an interrupted write never leaves a half entry
+// (writes go through a temp file + rename).
+//
+// The cache is strictly best-effort: every failure to read or write is
+// reported to the caller as a miss / error it may ignore, never as a
+// reason to fail the command.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry kinds. Each lives in its own subdirectory so prune statistics and
+// manual cleanup can tell them apart.
+const (
+	KindSBOM         = "sbom"
+	KindCheck        = "check"
+	KindCapabilities = "capabilities"
+	KindProjects     = "projects"
+)
+
+// Default TTLs. Generated SBOMs only depend on the inputs in their key,
+// so they can live for a day; check results go stale as advisories are
+// published, so they default to an hour. A server's capabilities only
+// change when it is upgraded; an hour bounds how long a CLI keeps gating
+// on the old feature set. The project list only maps names to IDs, and
+// every name taken from it is confirmed against the server before use,
+// so its TTL only bounds how long a stale list costs an extra request.
+const (
+	DefaultSBOMTTL         = 24 * time.Hour
+	DefaultCheckTTL        = time.Hour
+	DefaultCapabilitiesTTL = time.Hour
+	DefaultProjectsTTL     = time.Hour
+)
+
+// DirEnv overrides the cache location (CI runners that persist a
+// specific directory between jobs).
+const DirEnv = "SBOMHUB_CACHE_DIR"
+
+// Cache is a directory of content-addressed entries.
+type Cache struct {
+	dir string
+	now func() time.Time
+}
+
+// PruneStats reports what Prune removed.
+type PruneStats struct {
+	Removed int
+	Bytes   int64
+}
+
+// DefaultDir returns $SBOMHUB_CACHE_DIR, or <os.UserCacheDir>/sbomhub.
+func DefaultDir() (string, error) {
+	if d := os.Getenv(DirEnv); d != "" {
+		return d, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "sbomhub"), nil
+}
+
+// New returns a cache rooted at dir. The directory is created lazily on
+// the first Put.
+func New(dir string) *Cache {
+	return &Cache{dir: dir, now: time.Now}
+}
+
+// Dir returns the cache root.
+func (c *Cache) Dir() string { return c.dir }
+
+// Key hashes parts into a cache key. Parts are length-prefixed so
+// ("ab", "c") and ("a", "bc") cannot collide.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		var n [8]byte
+		binary.LittleEndian.PutUint64(n[:], uint64(len(p)))
+		h.Write(n[:])
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the entry for (kind, key) if it exists and is younger than
+// ttl.
+func (c *Cache) Get(kind, key string, ttl time.Duration) ([]byte, bool) {
+	p, ok := c.GetFile(kind, key, ttl)
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// GetFile is Get for entries too large to read into memory (generated
+// SBOMs): it returns the entry's path instead of its contents. Callers
+// must treat the file as read-only; it stays valid until the next Put or
+// Prune of the same entry.
+func (c *Cache) GetFile(kind, key string, ttl time.Duration) (string, bool) {
+	p := c.path(kind, key)
+	st, err := os.Stat(p)
+	if err != nil || c.now().Sub(st.ModTime()) > ttl {
+		return "", false
+	}
+	return p, true
+}
+
+// Put stores data under (kind, key), replacing any existing entry.
+// Entries are written 0600 in 0700 directories: check results and SBOMs
+// describe a private codebase.
+func (c *Cache) Put(kind, key string, data []byte) error {
+	return c.write(kind, key, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// PutFile stores a copy of the file at src under (kind, key), streaming
+// it rather than reading it into memory.
+func (c *Cache) PutFile(kind, key, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.write(kind, key, func(w io.Writer) error {
+		_, err := io.Copy(w, f)
+		return err
+	})
+}
+
+// write fills a temp file with fill and renames it over the entry.
+func (c *Cache) write(kind, key string, fill func(io.Writer) error) error {
+	p := c.path(kind, key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if err := fill(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Prune removes entries older than olderThan; zero removes everything.
+// Temp files left by interrupted writes are removed once they are an
+// hour old. A missing cache directory is not an error.
+func (c *Cache) Prune(olderThan time.Duration) (PruneStats, error) {
+	var stats PruneStats
+	now := c.now()
+	err := filepath.WalkDir(c.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		age := now.Sub(info.ModTime())
+		stale := olderThan <= 0 || age > olderThan
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			// Temp files are only ever leftovers once no write could
+			// still be in flight.
+			stale = stale || age > time.Hour
+		}
+		if !stale {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		stats.Removed++
+		stats.Bytes += info.Size()
+		return nil
+	})
+	return stats, err
+}
+
+func (c *Cache) path(kind, key string) string {
+	shard := key
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+	return filepath.Join(c.dir, kind, shard, key)
+}
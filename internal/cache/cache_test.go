@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetPut_TTL(t *testing.T) {
+	c := New(t.TempDir())
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	key := Key("check", "https://api.example", "abc")
+	if _, ok := c.Get(KindCheck, key, time.Hour); ok {
+		t.Fatal("Get() hit on an empty cache")
+	}
+	if err := c.Put(KindCheck, key, []byte("payload")); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+	if got, ok := c.Get(KindCheck, key, time.Hour); !ok || string(got) != "payload" {
+		t.Errorf("Get() = %q, %v; want payload, true", got, ok)
+	}
+
+	c.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, ok := c.Get(KindCheck, key, time.Hour); ok {
+		t.Error("Get() hit after the TTL elapsed")
+	}
+}
+
+func TestPutFile_GetFile(t *testing.T) {
+	c := New(t.TempDir())
+	src := filepath.Join(t.TempDir(), "sbom.json")
+	if err := os.WriteFile(src, []byte(`{"components":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	key := Key("sbom", "syft", "1.0")
+	if err := c.PutFile(KindSBOM, key, src); err != nil {
+		t.Fatalf("PutFile() = %v", err)
+	}
+	p, ok := c.GetFile(KindSBOM, key, time.Hour)
+	if !ok {
+		t.Fatal("GetFile() missed after PutFile")
+	}
+	if got, _ := os.ReadFile(p); string(got) != `{"components":[]}` {
+		t.Errorf("entry = %q, want the source contents", got)
+	}
+	if p == src {
+		t.Error("GetFile() returned the source path, want a copy inside the cache")
+	}
+}
+
+func TestKey_LengthPrefixed(t *testing.T) {
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key() must not collide across part boundaries")
+	}
+	if Key("a", "b") != Key("a", "b") {
+		t.Error("Key() must be deterministic")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+	if err := c.Put(KindSBOM, Key("old"), []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(KindSBOM, Key("new"), []byte("y")); err != nil {
+		t.Fatal(err)
+	}
+	old := c.path(KindSBOM, Key("old"))
+	past := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := c.Prune(24 * time.Hour)
+	if err != nil || stats.Removed != 1 {
+		t.Fatalf("Prune(24h) = %+v, %v; want 1 removed", stats, err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("stale entry still present")
+	}
+
+	stats, err = c.Prune(0)
+	if err != nil || stats.Removed != 1 {
+		t.Errorf("Prune(0) = %+v, %v; want the remaining entry removed", stats, err)
+	}
+
+	if _, err := New(filepath.Join(dir, "missing")).Prune(0); err != nil {
+		t.Errorf("Prune() on a missing dir = %v, want nil", err)
+	}
+}
+
+func TestInputsHash(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("main.go", "package main")
+	if _, ok, err := InputsHash(dir); err != nil || ok {
+		t.Fatalf("InputsHash() without manifests = ok %v, err %v; want not cacheable", ok, err)
+	}
+
+	write("go.mod", "module x")
+	write("web/package-lock.json", `{"lockfileVersion":3}`)
+	first, ok, err := InputsHash(dir)
+	if err != nil || !ok {
+		t.Fatalf("InputsHash() = %v, %v", ok, err)
+	}
+
+	// Source edits and installed dependency trees do not change the key.
+	write("main.go", "package main // edited")
+	write("web/node_modules/left-pad/package.json", `{"version":"1.0.0"}`)
+	if again, _, _ := InputsHash(dir); again != first {
+		t.Error("hash changed for a non-manifest edit")
+	}
+
+	write("web/package-lock.json", `{"lockfileVersion":3,"packages":{}}`)
+	if changed, _, _ := InputsHash(dir); changed == first {
+		t.Error("hash unchanged after a lockfile edit")
+	}
+}
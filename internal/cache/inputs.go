@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// manifestNames are the dependency manifests and lockfiles whose
+// contents determine what an SBOM generator reports for a source tree.
+var manifestNames = map[string]bool{
+	// JavaScript
+	"package.json": true, "package-lock.json": true, "npm-shrinkwrap.json": true,
+	"yarn.lock": true, "pnpm-lock.yaml": true, "bun.lockb": true,
+	// Go
+	"go.mod": true, "go.sum": true,
+	// Rust
+	"Cargo.toml": true, "Cargo.lock": true,
+	// Python
+	"Pipfile": true, "Pipfile.lock": true, "poetry.lock": true, "pyproject.toml": true,
+	"uv.lock": true, "setup.py": true, "setup.cfg": true,
+	// Ruby
+	"Gemfile": true, "Gemfile.lock": true,
+	// PHP
+	"composer.json": true, "composer.lock": true,
+	// JVM
+	"pom.xml": true, "build.gradle": true, "build.gradle.kts": true,
+	"settings.gradle": true, "settings.gradle.kts": true, "gradle.lockfile": true,
+	// .NET
+	"packages.lock.json": true, "packages.config": true, "Directory.Packages.props": true,
+	// Swift / CocoaPods / Dart / Elixir / C++
+	"Package.swift": true, "Package.resolved": true, "Podfile": true, "Podfile.lock": true,
+	"pubspec.yaml": true, "pubspec.lock": true, "mix.exs": true, "mix.lock": true,
+	"conanfile.txt": true, "vcpkg.json": true,
+}
+
+// manifestSuffixes cover manifests with project-specific names.
+var manifestSuffixes = []string{".csproj", ".fsproj", ".vbproj", ".gemspec"}
+
+// skippedDirs are not walked: VCS metadata, and installed-dependency
+// trees whose content is already pinned by a lockfile that IS hashed.
+var skippedDirs = map[string]bool{
+	".git": true, ".hg": true, ".svn": true,
+	"node_modules": true, "vendor": true, ".venv": true, "venv": true, "__pycache__": true,
+}
+
+func isManifest(name string) bool {
+	if manifestNames[name] {
+		return true
+	}
+	if strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt") {
+		return true
+	}
+	for _, s := range manifestSuffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// InputsHash fingerprints what an SBOM generator will read at path.
+//
+// For a file (an SBOM, a container image tarball) it is the file's
+// content hash. For a directory it hashes the relative path and content
+// of every dependency manifest / lockfile in the tree. ok is false when
+// a directory holds no recognised manifest: the SBOM would then depend
+// on files we do not fingerprint (vendored binaries, jars), so the
+// result must not be cached.
+//
+// Binaries inside a tree that also has manifests are not fingerprinted
+// either; --no-cache is the escape hatch for such trees.
+func InputsHash(path string) (sum string, ok bool, err error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return "", false, err
+	}
+	h := sha256.New()
+	if !st.IsDir() {
+		if err := hashFile(h, path); err != nil {
+			return "", false, err
+		}
+		return hex.EncodeToString(h.Sum(nil)), true, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && isManifest(d.Name()) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if len(files) == 0 {
+		return "", false, nil
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		rel, _ := filepath.Rel(path, f)
+		io.WriteString(h, filepath.ToSlash(rel)+"\x00")
+		if err := hashFile(h, f); err != nil {
+			return "", false, err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), true, nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	inner := sha256.New()
+	if _, err := io.Copy(inner, f); err != nil {
+		return err
+	}
+	_, err = w.Write(inner.Sum(nil))
+	return err
+}
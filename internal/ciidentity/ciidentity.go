@@ -0,0 +1,144 @@
+// Package ciidentity finds the identity token a CI job can present
+// instead of a static API key.
+//
+// Each supported runner hands jobs a signed OIDC token describing the
+// job (repository, ref, pipeline); the CLI exchanges it at the SBOMHub
+// token endpoint for a short-lived API token (see api.ExchangeToken).
+// Sources, in order:
+//
+//   - SBOMHUB_OIDC_TOKEN_FILE: a file holding the token (a Kubernetes
+//     projected service account token, or anything a wrapper writes).
+//   - SBOMHUB_OIDC_TOKEN: the token itself — GitLab's id_tokens keyword
+//     puts it in a variable of the job's choosing, so name it this.
+//   - GitHub Actions: fetched from ACTIONS_ID_TOKEN_REQUEST_URL, which is
+//     only set for workflows granted `permissions: id-token: write`.
+//   - GitLab CI_JOB_JWT_V2 / CI_JOB_JWT (deprecated by GitLab, still set
+//     on older instances).
+package ciidentity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Provider names, as reported by Detect and in Token.Provider.
+const (
+	ProviderTokenFile     = "token-file"
+	ProviderEnv           = "env"
+	ProviderGitHubActions = "github-actions"
+	ProviderGitLab        = "gitlab"
+)
+
+// ErrNotFound is returned by Fetch outside a CI job that offers an
+// identity token.
+var ErrNotFound = errors.New("CI の ID トークンが見つかりません")
+
+// Token is a CI identity token and where it came from.
+type Token struct {
+	Provider string
+	Value    string
+}
+
+// Detect names the source Fetch would use, or "" when there is none. It
+// only looks at the environment; nothing is read or fetched.
+func Detect(getenv func(string) string) string {
+	switch {
+	case getenv("SBOMHUB_OIDC_TOKEN_FILE") != "":
+		return ProviderTokenFile
+	case getenv("SBOMHUB_OIDC_TOKEN") != "":
+		if getenv("GITLAB_CI") != "" {
+			return ProviderGitLab
+		}
+		return ProviderEnv
+	case getenv("ACTIONS_ID_TOKEN_REQUEST_URL") != "" && getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN") != "":
+		return ProviderGitHubActions
+	case getenv("CI_JOB_JWT_V2") != "" || getenv("CI_JOB_JWT") != "":
+		return ProviderGitLab
+	}
+	return ""
+}
+
+// Fetch returns the job's identity token. audience is requested where
+// the provider mints tokens on demand (GitHub Actions); the other
+// sources carry whatever audience the job configured. client is used for
+// that request only; nil means http.DefaultClient.
+func Fetch(ctx context.Context, getenv func(string) string, client *http.Client, audience string) (*Token, error) {
+	provider := Detect(getenv)
+	var (
+		value string
+		err   error
+	)
+	switch provider {
+	case "":
+		return nil, ErrNotFound
+	case ProviderTokenFile:
+		var data []byte
+		path := getenv("SBOMHUB_OIDC_TOKEN_FILE")
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("ID トークンファイルを読み込めませんでした (SBOMHUB_OIDC_TOKEN_FILE): %w", err)
+		}
+		value = string(data)
+	case ProviderGitHubActions:
+		if value, err = fetchGitHub(ctx, getenv, client, audience); err != nil {
+			return nil, err
+		}
+	default:
+		for _, name := range []string{"SBOMHUB_OIDC_TOKEN", "CI_JOB_JWT_V2", "CI_JOB_JWT"} {
+			if value = getenv(name); value != "" {
+				break
+			}
+		}
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("CI の ID トークンが空です (%s)", provider)
+	}
+	return &Token{Provider: provider, Value: value}, nil
+}
+
+// fetchGitHub requests a token from the Actions runtime: GET the request
+// URL (with an audience parameter) authenticated by the request token,
+// answer {"value": "<jwt>"}.
+func fetchGitHub(ctx context.Context, getenv func(string) string, client *http.Client, audience string) (string, error) {
+	u, err := url.Parse(getenv("ACTIONS_ID_TOKEN_REQUEST_URL"))
+	if err != nil {
+		return "", fmt.Errorf("ACTIONS_ID_TOKEN_REQUEST_URL が不正です: %w", err)
+	}
+	if audience != "" {
+		q := u.Query()
+		q.Set("audience", audience)
+		u.RawQuery = q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN"))
+	req.Header.Set("Accept", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("GitHub Actions の ID トークンを取得できませんでした: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub Actions の ID トークンを取得できませんでした (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var out struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("GitHub Actions の ID トークンの解析に失敗しました: %w", err)
+	}
+	return out.Value, nil
+}
@@ -0,0 +1,78 @@
+package ciidentity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func envOf(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"none", map[string]string{"CI": "true"}, ""},
+		{"github", map[string]string{"ACTIONS_ID_TOKEN_REQUEST_URL": "u", "ACTIONS_ID_TOKEN_REQUEST_TOKEN": "t"}, ProviderGitHubActions},
+		{"github without permission", map[string]string{"ACTIONS_ID_TOKEN_REQUEST_URL": "u"}, ""},
+		{"gitlab id_tokens", map[string]string{"SBOMHUB_OIDC_TOKEN": "jwt", "GITLAB_CI": "true"}, ProviderGitLab},
+		{"gitlab legacy", map[string]string{"CI_JOB_JWT": "jwt"}, ProviderGitLab},
+		{"explicit env", map[string]string{"SBOMHUB_OIDC_TOKEN": "jwt"}, ProviderEnv},
+		{"file wins", map[string]string{"SBOMHUB_OIDC_TOKEN_FILE": "/f", "CI_JOB_JWT_V2": "jwt"}, ProviderTokenFile},
+	}
+	for _, tt := range tests {
+		if got := Detect(envOf(tt.env)); got != tt.want {
+			t.Errorf("%s: Detect() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetch_GitHubActions(t *testing.T) {
+	var gotAuth, gotAudience string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotAudience = r.URL.Query().Get("audience")
+		_, _ = w.Write([]byte(`{"value":"gh-jwt"}`))
+	}))
+	defer srv.Close()
+
+	tok, err := Fetch(context.Background(), envOf(map[string]string{
+		"ACTIONS_ID_TOKEN_REQUEST_URL":   srv.URL + "/token?api-version=2.0",
+		"ACTIONS_ID_TOKEN_REQUEST_TOKEN": "runtime-token",
+	}), srv.Client(), "https://sbomhub.example.com")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if tok.Value != "gh-jwt" || tok.Provider != ProviderGitHubActions {
+		t.Errorf("token = %+v", tok)
+	}
+	if gotAuth != "bearer runtime-token" || gotAudience != "https://sbomhub.example.com" {
+		t.Errorf("request: Authorization = %q, audience = %q", gotAuth, gotAudience)
+	}
+}
+
+func TestFetch_TokenFileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := Fetch(context.Background(), envOf(map[string]string{"SBOMHUB_OIDC_TOKEN_FILE": path}), nil, "")
+	if err != nil || tok.Value != "file-jwt" {
+		t.Errorf("token file: Fetch() = %+v, %v", tok, err)
+	}
+	tok, err = Fetch(context.Background(), envOf(map[string]string{"CI_JOB_JWT_V2": "gl-jwt"}), nil, "")
+	if err != nil || tok.Value != "gl-jwt" || tok.Provider != ProviderGitLab {
+		t.Errorf("gitlab: Fetch() = %+v, %v", tok, err)
+	}
+	if _, err := Fetch(context.Background(), envOf(nil), nil, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("no CI: Fetch() error = %v, want ErrNotFound", err)
+	}
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,18 +16,81 @@ import (
 // Config represents CLI configuration
 type Config struct {
 	APIURL string `yaml:"api_url"`
-	APIKey string `yaml:"api_key"`
+	APIKey string `yaml:"api_key,omitempty"`
+
+	// APIKeyRef points at a key kept outside this file, as
+	// "<backend>:<account>" (see internal/credential); APIKey is then
+	// empty. CredentialHelper is the command run for the "helper"
+	// backend, git-credential style.
+	APIKeyRef        string `yaml:"api_key_ref,omitempty"`
+	CredentialHelper string `yaml:"credential_helper,omitempty"`
+
+	// Device login (`sbomhub login --device`) instead of an API key.
+	// OAuthTokenURL / OAuthClientID are where and as whom the refresh
+	// token is redeemed. The tokens are either stored here or, with
+	// TokenRef set (same format as APIKeyRef), kept together as one JSON
+	// entry in that store.
+	OAuthTokenURL string    `yaml:"oauth_token_url,omitempty"`
+	OAuthClientID string    `yaml:"oauth_client_id,omitempty"`
+	AccessToken   string    `yaml:"access_token,omitempty"`
+	RefreshToken  string    `yaml:"refresh_token,omitempty"`
+	TokenExpiry   time.Time `yaml:"token_expiry,omitempty"`
+	TokenRef      string    `yaml:"token_ref,omitempty"`
+
+	// TLS / proxy settings for self-hosted servers behind an internal CA
+	// or a corporate proxy. All optional; empty means Go defaults (system
+	// roots, HTTPS_PROXY / NO_PROXY from the environment). Relative paths
+	// are resolved against the config directory by the CLI.
+	CACert             string `yaml:"ca_cert,omitempty"`
+	ClientCert         string `yaml:"client_cert,omitempty"`
+	ClientKey          string `yaml:"client_key,omitempty"`
+	Proxy              string `yaml:"proxy,omitempty"`
+	NoProxy            string `yaml:"no_proxy,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+
+	// Command defaults (`sbomhub config set fail_on high`). Empty / nil
+	// means the command's built-in default; flags, SBOMHUB_* env vars and
+	// the repository's .sbomhub.yaml take precedence. MaxRetries is a
+	// pointer because 0 (no retries) is a meaningful value.
+	Project      string        `yaml:"project,omitempty"`
+	Tool         string        `yaml:"tool,omitempty"`
+	Format       string        `yaml:"format,omitempty"`
+	FailOn       string        `yaml:"fail_on,omitempty"`
+	WaitTimeout  time.Duration `yaml:"wait_timeout,omitempty"`
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"`
+	MaxRetries   *int          `yaml:"max_retries,omitempty"`
 }
 
 // DefaultAPIURL is the URL used when neither config nor CLI/env provides
 // one. Exported so callers (login, doctor) can format identical hints.
 const DefaultAPIURL = "https://api.sbomhub.app"
 
-// Load loads configuration from the specified directory. Missing files are
-// reported as an error — callers that want to operate without a config
-// file (e.g. CI runners that pass credentials via flags or environment
-// variables only) should use LoadOrDefault instead.
-func Load(configDir string) (*Config, error) {
+// DefaultProfile is the name of the profile stored in the top-level
+// api_url / api_key / TLS keys of config.yaml — the layout every config
+// written before profiles existed uses, so those files keep working
+// unchanged as a single "default" profile.
+const DefaultProfile = "default"
+
+// File is the on-disk shape of config.yaml: the default profile inline
+// at the top level, any number of named profiles under `profiles:`, and
+// the profile to use when neither --profile nor SBOMHUB_PROFILE says.
+//
+//	current_context: staging
+//	api_url: https://sbomhub.example.com
+//	api_key: sbh_prod...
+//	profiles:
+//	  staging:
+//	    api_url: https://staging.sbomhub.example.com
+//	    api_key: sbh_stg...
+type File struct {
+	CurrentContext string `yaml:"current_context,omitempty"`
+	Config         `yaml:",inline"`
+	Profiles       map[string]*Config `yaml:"profiles,omitempty"`
+}
+
+// ReadFile parses <configDir>/config.yaml as is: no DefaultAPIURL is
+// applied, so callers can tell "unset" from "set to the default".
+func ReadFile(configDir string) (*File, error) {
 	configPath := filepath.Join(configDir, "config.yaml")
 
 	data, err := os.ReadFile(configPath)
@@ -33,17 +101,198 @@ func Load(configDir string) (*Config, error) {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
 		return nil, fmt.Errorf("設定ファイルの解析に失敗しました: %w", err)
 	}
+	return &f, nil
+}
+
+// CheckFile parses data as config.yaml the way `config edit` accepts it:
+// unknown keys are errors (a misspelt key would otherwise be ignored
+// without a word), every profile must pass Validate, and current_context
+// must name a defined profile — one of this file's or, for the user's
+// file of a layered setup, one of inherited (the profiles the other
+// config.yaml layers define).
+func CheckFile(data []byte, inherited ...string) (*File, error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("設定ファイルの解析に失敗しました: %w", err)
+	}
+	var errs []string
+	for _, name := range f.Names() {
+		if name != DefaultProfile && !ValidProfileName(name) {
+			errs = append(errs, fmt.Sprintf("プロファイル名が不正です: %q", name))
+			continue
+		}
+		cfg, _ := f.Profile(name)
+		if err := cfg.Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if f.CurrentContext != "" && !f.HasProfile(f.CurrentContext) && !contains(inherited, f.CurrentContext) {
+		errs = append(errs, fmt.Sprintf("current_context のプロファイル %q が定義されていません", f.CurrentContext))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return &f, nil
+}
+
+// ReadFileOrEmpty is ReadFile with a missing file treated as an empty
+// one, for the commands that create config.yaml (login, config set).
+func ReadFileOrEmpty(configDir string) (*File, error) {
+	f, err := ReadFile(configDir)
+	if err == nil {
+		return f, nil
+	}
+	if _, statErr := os.Stat(filepath.Join(configDir, "config.yaml")); os.IsNotExist(statErr) {
+		return &File{}, nil
+	}
+	return nil, err
+}
+
+// ResolveName returns the profile an empty name stands for: the file's
+// current_context, else DefaultProfile. A non-empty name is returned as
+// is.
+func (f *File) ResolveName(name string) string {
+	if name != "" {
+		return name
+	}
+	if f.CurrentContext != "" {
+		return f.CurrentContext
+	}
+	return DefaultProfile
+}
+
+// Profile returns a copy of the named profile (see ResolveName for "").
+// Naming a profile the file does not define is an error rather than a
+// silent fall back to the default: sending production credentials to a
+// server meant for a customer tenant is exactly what profiles prevent.
+func (f *File) Profile(name string) (*Config, error) {
+	name = f.ResolveName(name)
+	if name == DefaultProfile {
+		cfg := f.Config
+		return &cfg, nil
+	}
+	p, ok := f.Profiles[name]
+	if !ok || p == nil {
+		return nil, fmt.Errorf("プロファイル %q が見つかりません (定義済み: %s)", name, strings.Join(f.Names(), ", "))
+	}
+	cfg := *p
+	return &cfg, nil
+}
+
+// HasProfile reports whether name is defined. DefaultProfile always is.
+func (f *File) HasProfile(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	p, ok := f.Profiles[name]
+	return ok && p != nil
+}
+
+// SetProfile stores a copy of cfg as the named profile (see ResolveName
+// for ""), creating it if needed.
+func (f *File) SetProfile(name string, cfg *Config) {
+	name = f.ResolveName(name)
+	if name == DefaultProfile {
+		f.Config = *cfg
+		return
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]*Config{}
+	}
+	copied := *cfg
+	f.Profiles[name] = &copied
+}
+
+// Names lists the defined profiles, DefaultProfile first and the rest
+// sorted. The default profile is left out when it is empty and named
+// profiles exist, so a file that only uses `profiles:` does not list a
+// phantom entry.
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Profiles)+1)
+	for name, p := range f.Profiles {
+		if p != nil && name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if f.Config != (Config{}) || len(names) == 0 || f.ResolveName("") == DefaultProfile {
+		names = append([]string{DefaultProfile}, names...)
+	}
+	return names
+}
+
+// ValidProfileName reports whether name can be used as a profile name:
+// letters, digits, '.', '_' and '-', so it stays a plain YAML key and
+// survives being typed into a shell or an environment variable.
+func ValidProfileName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WriteFile writes f to <configDir>/config.yaml (0600, directory 0700).
+func WriteFile(f *File, configDir string) error {
+	// ディレクトリ作成
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return fmt.Errorf("ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	configPath := filepath.Join(configDir, "config.yaml")
+
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("設定のシリアライズに失敗しました: %w", err)
+	}
+
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("設定ファイルの書き込みに失敗しました: %w", err)
+	}
+
+	return nil
+}
+
+// Load loads configuration from the specified directory. Missing files are
+// reported as an error — callers that want to operate without a config
+// file (e.g. CI runners that pass credentials via flags or environment
+// variables only) should use LoadOrDefault instead.
+//
+// The profile loaded is the file's current_context; LoadProfile selects
+// one explicitly.
+func Load(configDir string) (*Config, error) {
+	return LoadProfile(configDir, "")
+}
+
+// LoadProfile is Load for the named profile ("" for the current one).
+func LoadProfile(configDir, profile string) (*Config, error) {
+	f, err := ReadFile(configDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := f.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
 
 	// デフォルト値
 	if cfg.APIURL == "" {
 		cfg.APIURL = DefaultAPIURL
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
 
 // LoadOrDefault is the fail-soft variant of Load: when the config file is
@@ -56,7 +305,14 @@ func Load(configDir string) (*Config, error) {
 // runners. Callers are expected to layer their own flag/env overrides on
 // top of the returned config and validate the final result themselves.
 func LoadOrDefault(configDir string) (*Config, error) {
-	cfg, err := Load(configDir)
+	return LoadProfileOrDefault(configDir, "")
+}
+
+// LoadProfileOrDefault is LoadOrDefault for the named profile. Without
+// a config file only the default profile exists, so naming any other is
+// still an error.
+func LoadProfileOrDefault(configDir, profile string) (*Config, error) {
+	cfg, err := LoadProfile(configDir, profile)
 	if err == nil {
 		return cfg, nil
 	}
@@ -66,28 +322,26 @@ func LoadOrDefault(configDir string) (*Config, error) {
 		// Treat "no file" as "empty cfg" so callers can still honour
 		// CLI flags / env vars. Any other Load error (parse failure,
 		// permission denied on an existing file, etc.) bubbles up.
+		if profile != "" && profile != DefaultProfile {
+			return nil, fmt.Errorf("プロファイル %q が見つかりません (設定ファイルがありません: %s)", profile, configPath)
+		}
 		return &Config{APIURL: DefaultAPIURL}, nil
 	}
 	return nil, err
 }
 
-// Save saves configuration to the specified directory
+// Save saves configuration to the specified directory as the current
+// profile, leaving the other profiles in the file untouched.
 func Save(cfg *Config, configDir string) error {
-	// ディレクトリ作成
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return fmt.Errorf("ディレクトリの作成に失敗しました: %w", err)
-	}
-
-	configPath := filepath.Join(configDir, "config.yaml")
+	return SaveProfile(cfg, configDir, "")
+}
 
-	data, err := yaml.Marshal(cfg)
+// SaveProfile is Save for the named profile ("" for the current one).
+func SaveProfile(cfg *Config, configDir, profile string) error {
+	f, err := ReadFileOrEmpty(configDir)
 	if err != nil {
-		return fmt.Errorf("設定のシリアライズに失敗しました: %w", err)
+		return err
 	}
-
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return fmt.Errorf("設定ファイルの書き込みに失敗しました: %w", err)
-	}
-
-	return nil
+	f.SetProfile(profile, cfg)
+	return WriteFile(f, configDir)
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -208,3 +209,368 @@ func TestSaveConfigPermissions(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configContent := `current_context: staging
+api_url: https://prod.example.com
+api_key: prod-key
+profiles:
+  staging:
+    api_url: https://staging.example.com
+    api_key: staging-key
+  customer-a:
+    api_key: customer-key
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.yaml"), []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	for _, tc := range []struct {
+		profile string
+		wantURL string
+		wantKey string
+	}{
+		{profile: "", wantURL: "https://staging.example.com", wantKey: "staging-key"},
+		{profile: "default", wantURL: "https://prod.example.com", wantKey: "prod-key"},
+		{profile: "customer-a", wantURL: DefaultAPIURL, wantKey: "customer-key"},
+	} {
+		cfg, err := LoadProfile(tmpDir, tc.profile)
+		if err != nil {
+			t.Fatalf("LoadProfile(%q) error = %v", tc.profile, err)
+		}
+		if cfg.APIURL != tc.wantURL || cfg.APIKey != tc.wantKey {
+			t.Errorf("LoadProfile(%q) = %q / %q, want %q / %q", tc.profile, cfg.APIURL, cfg.APIKey, tc.wantURL, tc.wantKey)
+		}
+	}
+
+	if _, err := LoadProfile(tmpDir, "missing"); err == nil {
+		t.Error("LoadProfile(missing) error = nil, want error")
+	}
+}
+
+func TestLoadProfileOrDefaultMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg, err := LoadProfileOrDefault(tmpDir, DefaultProfile)
+	if err != nil {
+		t.Fatalf("LoadProfileOrDefault(default) error = %v", err)
+	}
+	if cfg.APIURL != DefaultAPIURL {
+		t.Errorf("APIURL = %q, want %q", cfg.APIURL, DefaultAPIURL)
+	}
+
+	// A named profile cannot exist without a file; falling back to the
+	// default credentials would be the silent mix-up profiles prevent.
+	if _, err := LoadProfileOrDefault(tmpDir, "staging"); err == nil {
+		t.Error("LoadProfileOrDefault(staging) error = nil, want error")
+	}
+}
+
+func TestSaveProfileKeepsOtherProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Legacy single-profile file: top-level keys are the default profile.
+	if err := Save(&Config{APIURL: "https://prod.example.com", APIKey: "prod-key"}, tmpDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := SaveProfile(&Config{APIURL: "https://staging.example.com", APIKey: "staging-key"}, tmpDir, "staging"); err != nil {
+		t.Fatalf("SaveProfile() error = %v", err)
+	}
+
+	f, err := ReadFile(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got := f.Names(); len(got) != 2 || got[0] != DefaultProfile || got[1] != "staging" {
+		t.Errorf("Names() = %v, want [default staging]", got)
+	}
+	if f.APIKey != "prod-key" {
+		t.Errorf("default APIKey = %q, want prod-key (SaveProfile must not touch it)", f.APIKey)
+	}
+
+	// Save without a name writes the current profile.
+	f.CurrentContext = "staging"
+	if err := WriteFile(f, tmpDir); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := Save(&Config{APIURL: "https://staging.example.com", APIKey: "rotated"}, tmpDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cfg, err := LoadProfile(tmpDir, "staging")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if cfg.APIKey != "rotated" {
+		t.Errorf("staging APIKey = %q, want rotated", cfg.APIKey)
+	}
+	if cfg, _ := LoadProfile(tmpDir, DefaultProfile); cfg.APIKey != "prod-key" {
+		t.Errorf("default APIKey = %q, want prod-key", cfg.APIKey)
+	}
+}
+
+func TestValidProfileName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"default":    true,
+		"customer-a": true,
+		"prod_v2.1":  true,
+		"":           false,
+		"a b":        false,
+		"x/y":        false,
+		"ステージング":     false,
+	} {
+		if got := ValidProfileName(name); got != want {
+			t.Errorf("ValidProfileName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestKeyParse(t *testing.T) {
+	for _, tc := range []struct {
+		key, value, want string
+		wantErr          bool
+	}{
+		{key: "fail_on", value: "HIGH", want: "high"},
+		{key: "fail_on", value: "severe", wantErr: true},
+		{key: "wait_timeout", value: "90s", want: "1m30s"},
+		{key: "wait_timeout", value: "-1s", wantErr: true},
+		{key: "max_retries", value: "0", want: "0"},
+		{key: "max_retries", value: "-1", wantErr: true},
+		{key: "api_url", value: "https://sbomhub.example.com/", want: "https://sbomhub.example.com"},
+		{key: "api_url", value: "sbomhub.example.com", wantErr: true},
+		{key: "proxy", value: "socks5://proxy:1080", want: "socks5://proxy:1080"},
+		{key: "insecure_skip_verify", value: "1", want: "true"},
+		{key: "tool", value: "", wantErr: true},
+	} {
+		got, err := LookupKey(tc.key).Parse(tc.value)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("%s.Parse(%q) = %q, %v; want %q (error %v)", tc.key, tc.value, got, err, tc.want, tc.wantErr)
+		}
+	}
+	if LookupKey("Fail-On") == nil || LookupKey("nope") != nil {
+		t.Error("LookupKey should accept Fail-On and reject unknown names")
+	}
+}
+
+func TestKeysRoundTripThroughYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{}
+	for key, value := range map[string]string{"fail_on": "high", "wait_timeout": "2m0s", "max_retries": "0"} {
+		LookupKey(key).Set(cfg, value)
+	}
+	if err := Save(cfg, tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"fail_on": "high", "wait_timeout": "2m0s", "max_retries": "0", "poll_interval": ""} {
+		if got := LookupKey(key).Get(loaded); got != want {
+			t.Errorf("%s = %q after a round trip, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadProjectFile(t *testing.T) {
+	dir := t.TempDir()
+	if cfg, err := LoadProjectFile(dir); cfg != nil || err != nil {
+		t.Fatalf("missing file: LoadProjectFile() = %+v, %v; want nil, nil", cfg, err)
+	}
+
+	path := filepath.Join(dir, ProjectFileName)
+	if err := os.WriteFile(path, []byte("project: my-app\nfail_on: High\nwait_timeout: 10m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadProjectFile(dir)
+	if err != nil {
+		t.Fatalf("LoadProjectFile() error = %v", err)
+	}
+	if cfg.Project != "my-app" || cfg.FailOn != "high" || cfg.WaitTimeout.String() != "10m0s" {
+		t.Errorf("LoadProjectFile() = %+v", cfg)
+	}
+
+	// A repository must not be able to point the CLI at another server.
+	if err := os.WriteFile(path, []byte("api_url: https://evil.example.com\nfail_on: urgent\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadProjectFile(dir)
+	if err == nil || !strings.Contains(err.Error(), "api_url") || !strings.Contains(err.Error(), "fail_on") {
+		t.Errorf("LoadProjectFile() error = %v, want api_url and fail_on rejected", err)
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	for _, tc := range []struct {
+		name, data string
+		inherited  []string
+		wantErr    string
+	}{
+		{name: "valid", data: "api_url: https://a.example.com\nprofiles:\n  staging:\n    fail_on: low\ncurrent_context: staging\n"},
+		{name: "empty", data: ""},
+		{name: "unknown key", data: "api_ulr: https://a.example.com\n", wantErr: "api_ulr"},
+		{name: "bad value in profile", data: "profiles:\n  staging:\n    fail_on: urgent\n", wantErr: "staging"},
+		{name: "dangling current_context", data: "current_context: prod\n", wantErr: "prod"},
+		{name: "half mTLS pair", data: "client_cert: c.pem\n", wantErr: "client_key"},
+		{name: "profile from another layer", data: "current_context: corp\n", inherited: []string{"corp"}},
+	} {
+		_, err := CheckFile([]byte(tc.data), tc.inherited...)
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("%s: CheckFile() error = %v", tc.name, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%s: CheckFile() error = %v, want mention of %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+// writeLayer writes content as <dir>/config.yaml and returns dir.
+func writeLayer(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadStack_Merge(t *testing.T) {
+	root := t.TempDir()
+	system := writeLayer(t, filepath.Join(root, "etc"), `api_url: https://sbomhub.corp.example
+ca_cert: corp-ca.pem
+api_key: sbh_shared_host_key
+`)
+	user := writeLayer(t, filepath.Join(root, "home"), `api_key_ref: keyring:default
+fail_on: high
+current_context: staging
+profiles:
+  staging:
+    api_url: https://staging.corp.example
+    api_key: sbh_staging
+`)
+	xdg := writeLayer(t, filepath.Join(root, "xdg"), `fail_on: critical
+profiles:
+  staging:
+    fail_on: low
+`)
+	s, err := LoadStack(
+		Scope{Name: ScopeSystem, Dir: system},
+		Scope{Name: ScopeUser, Dir: user},
+		Scope{Name: ScopeXDG, Dir: xdg},
+		Scope{Name: ScopeXDG, Dir: filepath.Join(root, "missing")},
+	)
+	if err != nil {
+		t.Fatalf("LoadStack() error = %v", err)
+	}
+	if len(s.Layers) != 3 || len(s.Searched) != 4 {
+		t.Fatalf("LoadStack() layers = %d, searched = %d; want 3, 4", len(s.Layers), len(s.Searched))
+	}
+
+	// The default profile: system URL and CA (resolved against /etc),
+	// the user's credential as a whole, the XDG fail_on.
+	def, err := s.LoadProfile(DefaultProfile)
+	if err != nil {
+		t.Fatalf("LoadProfile(default) error = %v", err)
+	}
+	if def.APIURL != "https://sbomhub.corp.example" || def.CACert != filepath.Join(system, "corp-ca.pem") ||
+		def.FailOn != "critical" || def.APIKeyRef != "keyring:default" || def.APIKey != "" {
+		t.Errorf("default profile = %+v", def)
+	}
+
+	// A named profile starts from the system file minus its credential.
+	if got := s.File().ResolveName(""); got != "staging" {
+		t.Errorf("current profile = %q, want staging", got)
+	}
+	st, err := s.LoadProfile("")
+	if err != nil {
+		t.Fatalf("LoadProfile(staging) error = %v", err)
+	}
+	if st.APIURL != "https://staging.corp.example" || st.CACert != filepath.Join(system, "corp-ca.pem") ||
+		st.FailOn != "low" || st.APIKey != "sbh_staging" {
+		t.Errorf("staging profile = %+v", st)
+	}
+
+	for _, tc := range []struct {
+		profile, key, want string
+	}{
+		{DefaultProfile, "api_url", filepath.Join(system, "config.yaml")},
+		{DefaultProfile, "api_key", filepath.Join(user, "config.yaml")},
+		{DefaultProfile, "fail_on", filepath.Join(xdg, "config.yaml")},
+		{"staging", "ca_cert", filepath.Join(system, "config.yaml")},
+		{"staging", "api_key", filepath.Join(user, "config.yaml")},
+		{"staging", "proxy", ""},
+	} {
+		if got := s.Source(tc.profile, LookupKey(tc.key)); got != tc.want {
+			t.Errorf("Source(%s, %s) = %q, want %q", tc.profile, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestLoadStack_NoFiles(t *testing.T) {
+	s, err := LoadStack(Scope{Name: ScopeSystem, Dir: t.TempDir()}, Scope{Name: ScopeUser, Dir: ""})
+	if err != nil {
+		t.Fatalf("LoadStack() error = %v", err)
+	}
+	cfg, err := s.LoadProfile("")
+	if err != nil || cfg.APIURL != DefaultAPIURL {
+		t.Errorf("LoadProfile(\"\") = %+v, %v; want the built-in defaults", cfg, err)
+	}
+	if _, err := s.LoadProfile("prod"); err == nil {
+		t.Error("LoadProfile(prod) without any file: want an error")
+	}
+}
+
+func TestLoadStack_ParseErrorNamesFile(t *testing.T) {
+	dir := writeLayer(t, t.TempDir(), "api_url: [\n")
+	_, err := LoadStack(Scope{Name: ScopeSystem, Dir: dir})
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "config.yaml")) {
+		t.Errorf("LoadStack() error = %v, want one naming the file", err)
+	}
+}
+
+func TestFindProjectDir(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindProjectDir(sub); got != "" {
+		t.Errorf("no file: FindProjectDir() = %q, want \"\"", got)
+	}
+	// Above the repository root a file is never picked up.
+	if err := os.WriteFile(filepath.Join(root, ProjectFileName), []byte("tool: syft\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindProjectDir(sub); got != "" {
+		t.Errorf("file above repo: FindProjectDir() = %q, want \"\"", got)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ProjectFileName), []byte("tool: trivy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindProjectDir(sub); got != repo {
+		t.Errorf("FindProjectDir(subdir) = %q, want %q", got, repo)
+	}
+	if err := os.WriteFile(filepath.Join(sub, ProjectFileName), []byte("tool: cdxgen\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindProjectDir(sub); got != sub {
+		t.Errorf("FindProjectDir(subdir with file) = %q, want %q", got, sub)
+	}
+	// Outside a repository only the directory itself counts.
+	outside := filepath.Join(root, "work")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindProjectDir(outside); got != "" {
+		t.Errorf("FindProjectDir(outside repo) = %q, want \"\"", got)
+	}
+	if got := FindProjectDir(root); got != root {
+		t.Errorf("FindProjectDir(dir with file) = %q, want %q", got, root)
+	}
+}
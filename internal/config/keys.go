@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the value type of a setting; Key.Parse validates against it.
+type Kind string
+
+const (
+	KindString   Kind = "string"
+	KindURL      Kind = "url"
+	KindPath     Kind = "path"
+	KindSecret   Kind = "secret"
+	KindEnum     Kind = "enum"
+	KindDuration Kind = "duration"
+	KindInt      Kind = "int"
+	KindBool     Kind = "bool"
+)
+
+// Key describes one setting of a profile: how `sbomhub config` names,
+// validates and stores it, and where else the CLI reads it from.
+type Key struct {
+	Name string
+	Kind Kind
+	// Values lists the accepted values of a KindEnum key, or the schemes
+	// of a KindURL key (http and https when empty).
+	Values []string
+	// Description is the one-line help shown by `config list`.
+	Description string
+	// Env is the SBOMHUB_* variable that overrides the profile, and Flag
+	// the global flag that overrides both; either may be empty.
+	Env  string
+	Flag string
+	// Project is true for the keys a repository's .sbomhub.yaml may set.
+	Project bool
+	// Default is the value in effect when nothing sets the key, as shown
+	// to the operator ("" when there is none).
+	Default string
+
+	get func(*Config) string
+	set func(*Config, string)
+}
+
+// Get returns the key's value in cfg, formatted as `config set` accepts
+// it, or "" when unset.
+func (k *Key) Get(cfg *Config) string { return k.get(cfg) }
+
+// Set stores value (already accepted by Parse) in cfg; "" unsets it.
+func (k *Key) Set(cfg *Config, value string) { k.set(cfg, value) }
+
+// Parse validates value for the key and returns it normalised (enum
+// values lower-cased, durations and booleans in canonical form).
+func (k *Key) Parse(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s: 値が空です (削除するには 'sbomhub config unset %s')", k.Name, k.Name)
+	}
+	switch k.Kind {
+	case KindURL:
+		schemes := k.Values
+		if len(schemes) == 0 {
+			schemes = []string{"http", "https"}
+		}
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" || !contains(schemes, u.Scheme) {
+			return "", fmt.Errorf("%s: %s:// で始まる URL を指定してください: %q", k.Name, strings.Join(schemes, ":// / "), value)
+		}
+		return strings.TrimRight(value, "/"), nil
+	case KindEnum:
+		if v := strings.ToLower(value); contains(k.Values, v) {
+			return v, nil
+		}
+		return "", fmt.Errorf("%s: 値が不正です: %q (有効値: %s)", k.Name, value, strings.Join(k.Values, "/"))
+	case KindDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("%s: 正の期間を指定してください (例: 30s, 5m): %q", k.Name, value)
+		}
+		return d.String(), nil
+	case KindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("%s: 0 以上の整数を指定してください: %q", k.Name, value)
+		}
+		return strconv.Itoa(n), nil
+	case KindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: true または false を指定してください: %q", k.Name, value)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return value, nil
+}
+
+// LookupKey returns the setting called name (case-insensitive; '-' is
+// accepted for '_'), or nil.
+func LookupKey(name string) *Key {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+	for i := range keys {
+		if keys[i].Name == name {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// Keys returns every setting, in the order `config list` shows them.
+func Keys() []*Key {
+	out := make([]*Key, len(keys))
+	for i := range keys {
+		out[i] = &keys[i]
+	}
+	return out
+}
+
+// KeyNames lists the setting names, for error messages.
+func KeyNames() []string {
+	names := make([]string, len(keys))
+	for i := range keys {
+		names[i] = keys[i].Name
+	}
+	return names
+}
+
+// Validate checks every set key of cfg as `config set` would, so a
+// hand-edited file is held to the same rules. Paths are not checked for
+// existence here; the transport reports a missing CA bundle when used.
+func (cfg *Config) Validate() error {
+	var errs []string
+	for i := range keys {
+		k := &keys[i]
+		v := k.get(cfg)
+		if v == "" || k.Kind == KindPath || k.Kind == KindSecret {
+			continue
+		}
+		if _, err := k.Parse(v); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		errs = append(errs, "client_cert と client_key は両方指定してください")
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func stringField(field func(*Config) *string) (func(*Config) string, func(*Config, string)) {
+	return func(c *Config) string { return *field(c) },
+		func(c *Config, v string) { *field(c) = v }
+}
+
+func durationField(field func(*Config) *time.Duration) (func(*Config) string, func(*Config, string)) {
+	return func(c *Config) string {
+			if d := *field(c); d != 0 {
+				return d.String()
+			}
+			return ""
+		}, func(c *Config, v string) {
+			d, _ := time.ParseDuration(v)
+			*field(c) = d
+		}
+}
+
+// keys is the registry behind `sbomhub config`. api_key is listed for
+// completeness, but the command routes it through the credential store
+// rather than Set.
+var keys = func() []Key {
+	str := func(k Key, field func(*Config) *string) Key {
+		k.get, k.set = stringField(field)
+		return k
+	}
+	dur := func(k Key, field func(*Config) *time.Duration) Key {
+		k.get, k.set = durationField(field)
+		return k
+	}
+	return []Key{
+		str(Key{Name: "api_url", Kind: KindURL, Env: "SBOMHUB_API_URL", Flag: "api-url", Default: DefaultAPIURL,
+			Description: "SBOMHub API URL"}, func(c *Config) *string { return &c.APIURL }),
+		str(Key{Name: "api_key", Kind: KindSecret, Env: "SBOMHUB_API_KEY", Flag: "api-key",
+			Description: "API Key (キーチェーン / credential_helper に保存済みならそちらを更新)"}, func(c *Config) *string { return &c.APIKey }),
+		str(Key{Name: "credential_helper", Kind: KindString,
+			Description: "API Key を保存する外部コマンド (git-credential 形式)"}, func(c *Config) *string { return &c.CredentialHelper }),
+
+		str(Key{Name: "project", Kind: KindString, Env: "SBOMHUB_PROJECT", Project: true,
+			Description: "scan / triage / cra / meti / sboms の対象プロジェクト名または ID (scan のデフォルト: ディレクトリ名)"}, func(c *Config) *string { return &c.Project }),
+		str(Key{Name: "tool", Kind: KindEnum, Values: []string{"syft", "trivy", "cdxgen"}, Env: "SBOMHUB_TOOL", Project: true,
+			Description: "scan で使う SBOM 生成ツール (デフォルト: 自動検出)"}, func(c *Config) *string { return &c.Tool }),
+		str(Key{Name: "format", Kind: KindEnum, Values: []string{"cyclonedx", "spdx"}, Env: "SBOMHUB_FORMAT", Project: true, Default: "cyclonedx",
+			Description: "scan の SBOM フォーマット"}, func(c *Config) *string { return &c.Format }),
+		str(Key{Name: "fail_on", Kind: KindEnum, Values: []string{"critical", "high", "medium", "low", "kev"}, Env: "SBOMHUB_FAIL_ON", Project: true,
+			Description: "scan / check で exit 1 にする重大度"}, func(c *Config) *string { return &c.FailOn }),
+		dur(Key{Name: "wait_timeout", Kind: KindDuration, Env: "SBOMHUB_WAIT_TIMEOUT", Project: true, Default: "5m0s",
+			Description: "scan がサーバ側スキャン完了を待つ最大時間"}, func(c *Config) *time.Duration { return &c.WaitTimeout }),
+		dur(Key{Name: "poll_interval", Kind: KindDuration, Env: "SBOMHUB_POLL_INTERVAL", Project: true, Default: "5s",
+			Description: "scan のスキャン状態 polling 間隔"}, func(c *Config) *time.Duration { return &c.PollInterval }),
+		{Name: "max_retries", Kind: KindInt, Env: "SBOMHUB_MAX_RETRIES", Flag: "max-retries", Default: "3",
+			Description: "API リクエストの自動リトライ回数 (0 で無効)",
+			get: func(c *Config) string {
+				if c.MaxRetries == nil {
+					return ""
+				}
+				return strconv.Itoa(*c.MaxRetries)
+			},
+			set: func(c *Config, v string) {
+				if v == "" {
+					c.MaxRetries = nil
+					return
+				}
+				n, _ := strconv.Atoi(v)
+				c.MaxRetries = &n
+			}},
+
+		str(Key{Name: "ca_cert", Kind: KindPath, Env: "SBOMHUB_CA_CERT", Flag: "ca-cert",
+			Description: "追加で信頼する CA 証明書 (PEM。 相対パスは設定ディレクトリ基準)"}, func(c *Config) *string { return &c.CACert }),
+		str(Key{Name: "client_cert", Kind: KindPath, Env: "SBOMHUB_CLIENT_CERT", Flag: "client-cert",
+			Description: "mTLS 用クライアント証明書 (PEM)"}, func(c *Config) *string { return &c.ClientCert }),
+		str(Key{Name: "client_key", Kind: KindPath, Env: "SBOMHUB_CLIENT_KEY", Flag: "client-key",
+			Description: "mTLS 用クライアント秘密鍵 (PEM)"}, func(c *Config) *string { return &c.ClientKey }),
+		str(Key{Name: "proxy", Kind: KindURL, Values: []string{"http", "https", "socks5"}, Env: "SBOMHUB_PROXY", Flag: "proxy",
+			Description: "HTTP(S) プロキシ URL"}, func(c *Config) *string { return &c.Proxy }),
+		str(Key{Name: "no_proxy", Kind: KindString, Env: "SBOMHUB_NO_PROXY", Flag: "no-proxy",
+			Description: "プロキシを経由しないホスト (カンマ区切り)"}, func(c *Config) *string { return &c.NoProxy }),
+		{Name: "insecure_skip_verify", Kind: KindBool, Env: "SBOMHUB_INSECURE_SKIP_VERIFY", Flag: "insecure-skip-verify", Default: "false",
+			Description: "サーバ証明書の検証を無効化 (危険: 診断目的のみ)",
+			get: func(c *Config) string {
+				if c.InsecureSkipVerify {
+					return "true"
+				}
+				return ""
+			},
+			set: func(c *Config, v string) { c.InsecureSkipVerify = v == "true" }},
+	}
+}()
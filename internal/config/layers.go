@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Layered configuration.
+//
+// A run reads up to three config.yaml files and merges them, lowest
+// precedence first:
+//
+//   - system: /etc/sbomhub/config.yaml, written by an administrator for
+//     every user of the host (server URL, CA bundle, proxy).
+//   - user:   ~/.sbomhub/config.yaml, the location every CLI version so
+//     far has written.
+//   - xdg:    $XDG_CONFIG_HOME/sbomhub/config.yaml (~/.config/sbomhub),
+//     for hosts that keep dotfiles there or have a read-only HOME.
+//
+// The repository's .sbomhub.yaml, environment variables and flags are
+// layered on top by the CLI; see LoadProjectFile.
+//
+// Merge rules, per profile:
+//
+//   - A key set in a higher layer replaces the same key of a lower one;
+//     keys a layer does not set are inherited. insecure_skip_verify can
+//     only be switched on by a layer, never off.
+//   - The credential (api_key, api_key_ref or the device-login tokens) is
+//     taken as a whole from the highest layer that has one, so a key from
+//     one file is never paired with a token_ref from another.
+//   - The top-level keys of the system file, except the credential, are
+//     the base of every named profile too: an administrator's CA bundle
+//     applies whichever profile a user selects.
+//   - current_context is taken from the highest layer that sets it, and a
+//     profile defined in any layer can be selected.
+//   - Relative certificate paths are resolved against the directory of
+//     the file that names them.
+//
+// Commands that write (login, config set, logout) only ever write the
+// user's own file, never the merged result.
+
+// Scope names of the config.yaml layers.
+const (
+	ScopeSystem = "system"
+	ScopeUser   = "user"
+	ScopeXDG    = "xdg"
+)
+
+// Scope is one place a config.yaml may live.
+type Scope struct {
+	Name string
+	Dir  string
+}
+
+// Layer is a config.yaml that exists, with its scope.
+type Layer struct {
+	Scope string
+	Path  string
+	File  *File
+}
+
+// Stack is the merged view of the config.yaml layers.
+type Stack struct {
+	// Layers holds the files found, lowest precedence first.
+	Layers []Layer
+	// Searched lists every path looked at, found or not, for messages.
+	Searched []string
+
+	merged *File
+}
+
+// LoadStack reads the config.yaml of each scope (lowest precedence
+// first) and merges them. Missing files are skipped; a file that exists
+// but cannot be read or parsed is an error naming it.
+func LoadStack(scopes ...Scope) (*Stack, error) {
+	s := &Stack{}
+	seen := map[string]bool{}
+	for _, sc := range scopes {
+		if sc.Dir == "" {
+			continue
+		}
+		path := filepath.Join(sc.Dir, "config.yaml")
+		if abs, err := filepath.Abs(path); err == nil {
+			if seen[abs] {
+				continue
+			}
+			seen[abs] = true
+		}
+		s.Searched = append(s.Searched, path)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		f, err := ReadFile(sc.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s): %w", path, sc.Name, err)
+		}
+		f.absolutePaths(sc.Dir)
+		s.Layers = append(s.Layers, Layer{Scope: sc.Name, Path: path, File: f})
+	}
+	s.merged = s.merge()
+	return s, nil
+}
+
+// File returns the merged configuration. It is for reading only.
+func (s *Stack) File() *File { return s.merged }
+
+// LoadProfile is LoadProfileOrDefault over the stack: the named profile
+// ("" for the current one) with DefaultAPIURL applied. With no files at
+// all only the default profile exists.
+func (s *Stack) LoadProfile(profile string) (*Config, error) {
+	if len(s.Layers) == 0 && profile != "" && profile != DefaultProfile {
+		return nil, fmt.Errorf("プロファイル %q が見つかりません (設定ファイルがありません: %s)", profile, strings.Join(s.Searched, ", "))
+	}
+	cfg, err := s.merged.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	return cfg, nil
+}
+
+// Source returns the path of the file that supplies k for the named
+// profile, or "" when no layer sets it.
+func (s *Stack) Source(profile string, k *Key) string {
+	name := s.merged.ResolveName(profile)
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		l := s.Layers[i]
+		var cfgs []*Config
+		if name == DefaultProfile {
+			cfgs = append(cfgs, &l.File.Config)
+		} else {
+			if p := l.File.Profiles[name]; p != nil {
+				cfgs = append(cfgs, p)
+			}
+			if l.Scope == ScopeSystem && k.Name != "api_key" {
+				cfgs = append(cfgs, &l.File.Config)
+			}
+		}
+		for _, c := range cfgs {
+			if k.Get(c) != "" || (k.Name == "api_key" && c.hasCredential()) {
+				return l.Path
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Stack) merge() *File {
+	merged := &File{}
+	var base Config
+	for _, l := range s.Layers {
+		f := l.File
+		if l.Scope == ScopeSystem {
+			base = f.Config
+			base.setCredential(Config{})
+		}
+		if f.CurrentContext != "" {
+			merged.CurrentContext = f.CurrentContext
+		}
+		merged.Config.overlay(&f.Config)
+		for name, p := range f.Profiles {
+			if p == nil {
+				continue
+			}
+			if merged.Profiles == nil {
+				merged.Profiles = map[string]*Config{}
+			}
+			dst := merged.Profiles[name]
+			if dst == nil {
+				b := base
+				dst = &b
+				merged.Profiles[name] = dst
+			}
+			dst.overlay(p)
+		}
+	}
+	return merged
+}
+
+// overlay applies the keys src sets on top of cfg (see the merge rules
+// above).
+func (cfg *Config) overlay(src *Config) {
+	for i := range keys {
+		k := &keys[i]
+		if k.Name == "api_key" {
+			continue
+		}
+		if v := k.get(src); v != "" {
+			k.set(cfg, v)
+		}
+	}
+	if src.hasCredential() {
+		cfg.setCredential(*src)
+	}
+}
+
+// hasCredential reports whether cfg holds any part of a credential.
+func (cfg *Config) hasCredential() bool {
+	return cfg.APIKey != "" || cfg.APIKeyRef != "" || cfg.AccessToken != "" || cfg.RefreshToken != "" || cfg.TokenRef != ""
+}
+
+// setCredential replaces the credential fields of cfg with those of src.
+func (cfg *Config) setCredential(src Config) {
+	cfg.APIKey, cfg.APIKeyRef = src.APIKey, src.APIKeyRef
+	cfg.OAuthTokenURL, cfg.OAuthClientID = src.OAuthTokenURL, src.OAuthClientID
+	cfg.AccessToken, cfg.RefreshToken = src.AccessToken, src.RefreshToken
+	cfg.TokenExpiry, cfg.TokenRef = src.TokenExpiry, src.TokenRef
+}
+
+// absolutePaths resolves the relative certificate paths of every profile
+// against dir, the directory of the file they were read from.
+func (f *File) absolutePaths(dir string) {
+	cfgs := []*Config{&f.Config}
+	for _, p := range f.Profiles {
+		if p != nil {
+			cfgs = append(cfgs, p)
+		}
+	}
+	for _, c := range cfgs {
+		for _, p := range []*string{&c.CACert, &c.ClientCert, &c.ClientKey} {
+			if *p != "" && !filepath.IsAbs(*p) {
+				*p = filepath.Join(dir, *p)
+			}
+		}
+	}
+}
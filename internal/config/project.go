@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the repository-level settings file. It holds the
+// command defaults of one repository (project, tool, format, fail_on,
+// timeouts) so a checkout scans the same way on every machine. It never
+// holds credentials or server settings: a cloned repository must not be
+// able to redirect the operator's API key.
+//
+//	project: my-app
+//	tool: syft
+//	format: cyclonedx
+//	fail_on: high
+const ProjectFileName = ".sbomhub.yaml"
+
+// LoadProjectFile reads <dir>/.sbomhub.yaml into the keys it sets. A
+// missing file yields (nil, nil). Every key is validated like `config
+// set`, and keys that are not project settings are rejected by name so a
+// typo does not silently do nothing.
+func LoadProjectFile(dir string) (*Config, error) {
+	path := filepath.Join(dir, ProjectFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s の解析に失敗しました: %w", path, err)
+	}
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cfg := &Config{}
+	var errs []string
+	for _, name := range names {
+		k := LookupKey(name)
+		if k == nil || !k.Project {
+			errs = append(errs, fmt.Sprintf("%s は %s に書けないキーです", name, ProjectFileName))
+			continue
+		}
+		if raw[name] == nil {
+			continue
+		}
+		v, err := k.Parse(fmt.Sprint(raw[name]))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		k.Set(cfg, v)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(errs, "; "))
+	}
+	return cfg, nil
+}
+
+// FindProjectDir returns the directory of the .sbomhub.yaml that applies
+// to start, or "" when there is none. Inside a repository (a directory
+// with a .git entry, at start or above) that is the nearest file between
+// start and the repository root, so a command run from a subdirectory
+// still finds the repository's settings. Outside one only start itself
+// is looked at: a stray file in a home directory or CI workspace must not
+// configure everything below it.
+func FindProjectDir(start string) string {
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return ""
+	}
+	has := func(dir, name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+	if has(start, ProjectFileName) {
+		return start
+	}
+	// Find the repository root first; only then is walking up safe.
+	root := start
+	for !has(root, ".git") {
+		parent := filepath.Dir(root)
+		if parent == root {
+			return ""
+		}
+		root = parent
+	}
+	for dir := start; dir != root; {
+		dir = filepath.Dir(dir)
+		if has(dir, ProjectFileName) {
+			return dir
+		}
+	}
+	return ""
+}
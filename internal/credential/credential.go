@@ -0,0 +1,131 @@
+// Package credential keeps API keys out of config.yaml.
+//
+// A profile that stores its key elsewhere carries only a reference,
+//
+//	api_key_ref: keyring:default@sbomhub.example.com
+//
+// naming a backend and the account the key is filed under. Two backends
+// exist: the OS keychain (keyring.go) and an external credential helper
+// speaking git-credential's get / store / erase protocol (helper.go).
+// Both shell out rather than link a keychain library, the same way the
+// scanner package drives syft / trivy: the CLI stays a single static
+// binary with no cgo.
+package credential
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// Service is the keychain service name API keys are filed under.
+const Service = "sbomhub-cli"
+
+// Backend names as they appear in an api_key_ref.
+const (
+	BackendKeyring = "keyring"
+	BackendHelper  = "helper"
+)
+
+// ErrNotFound is returned by Store.Get when no key is filed under the
+// account.
+var ErrNotFound = errors.New("認証情報が見つかりません")
+
+// ErrUnsupported is returned by the keyring backend on platforms without
+// a supported keychain tool.
+var ErrUnsupported = errors.New("この環境の OS キーチェーンには対応していません")
+
+// Store is one place an API key can live.
+type Store interface {
+	Get(ctx context.Context, account string) (string, error)
+	Store(ctx context.Context, account, secret string) error
+	// Erase removes the key. A key that is already gone is not an error,
+	// so logout can be repeated safely.
+	Erase(ctx context.Context, account string) error
+}
+
+// Ref is a parsed api_key_ref.
+type Ref struct {
+	Backend string
+	Account string
+}
+
+// ParseRef parses "<backend>:<account>".
+func ParseRef(s string) (Ref, error) {
+	backend, account, ok := strings.Cut(s, ":")
+	if !ok || account == "" {
+		return Ref{}, fmt.Errorf("api_key_ref の形式が不正です: %q (<backend>:<account> 形式)", s)
+	}
+	switch backend {
+	case BackendKeyring, BackendHelper:
+	default:
+		return Ref{}, fmt.Errorf("api_key_ref のバックエンドが不明です: %q (keyring / helper)", backend)
+	}
+	return Ref{Backend: backend, Account: account}, nil
+}
+
+func (r Ref) String() string {
+	return r.Backend + ":" + r.Account
+}
+
+// Account is the account name a profile's key is filed under. The host is
+// part of it so two config files (--config) that reuse a profile name for
+// different servers do not overwrite each other's keychain entry.
+func Account(profile, apiURL string) string {
+	if u, err := url.Parse(apiURL); err == nil && u.Host != "" {
+		return profile + "@" + u.Host
+	}
+	return profile
+}
+
+// Open returns the Store ref points at. helperCommand and apiURL come
+// from the same profile as ref; the helper is told which server the key
+// belongs to, the keychain is not.
+func Open(ref Ref, helperCommand, apiURL string) (Store, error) {
+	switch ref.Backend {
+	case BackendKeyring:
+		return Keyring(), nil
+	case BackendHelper:
+		return Helper(helperCommand, apiURL)
+	}
+	return nil, fmt.Errorf("api_key_ref のバックエンドが不明です: %q", ref.Backend)
+}
+
+// result is the outcome of a tool run that got as far as exiting.
+type result struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// runFunc runs name with stdin and reports how it exited. err is only set
+// when the tool could not be run at all (missing binary, ctx cancelled).
+// Tests substitute it to check the exact invocation.
+type runFunc func(ctx context.Context, stdin, name string, args ...string) (result, error)
+
+func runCommand(ctx context.Context, stdin, name string, args ...string) (result, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	res := result{stdout: stdout.String(), stderr: strings.TrimSpace(stderr.String())}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		res.code = exitErr.ExitCode()
+		return res, nil
+	}
+	return res, err
+}
+
+// toolError formats a non-zero exit, preferring the tool's own message.
+func toolError(name string, res result) error {
+	if res.stderr != "" {
+		return fmt.Errorf("%s 実行エラー (exit %d): %s", name, res.code, res.stderr)
+	}
+	return fmt.Errorf("%s 実行エラー (exit %d)", name, res.code)
+}
@@ -0,0 +1,214 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseRef(t *testing.T) {
+	ref, err := ParseRef("keyring:default@sbomhub.example.com")
+	if err != nil {
+		t.Fatalf("ParseRef() error = %v", err)
+	}
+	if ref.Backend != BackendKeyring || ref.Account != "default@sbomhub.example.com" {
+		t.Errorf("ParseRef() = %+v", ref)
+	}
+	if ref.String() != "keyring:default@sbomhub.example.com" {
+		t.Errorf("String() = %q", ref.String())
+	}
+
+	for _, bad := range []string{"", "keyring", "keyring:", "vault:x"} {
+		if _, err := ParseRef(bad); err == nil {
+			t.Errorf("ParseRef(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestAccount(t *testing.T) {
+	if got := Account("staging", "https://staging.example.com:8443/api"); got != "staging@staging.example.com:8443" {
+		t.Errorf("Account() = %q", got)
+	}
+	if got := Account("default", ""); got != "default" {
+		t.Errorf("Account() without URL = %q, want default", got)
+	}
+}
+
+// call records one fake tool invocation.
+type call struct {
+	stdin string
+	argv  []string
+}
+
+// fakeRun returns a runFunc that records calls and answers with res.
+func fakeRun(calls *[]call, res result) runFunc {
+	return func(_ context.Context, stdin, name string, args ...string) (result, error) {
+		*calls = append(*calls, call{stdin: stdin, argv: append([]string{name}, args...)})
+		return res, nil
+	}
+}
+
+func foundTool(string) (string, error) { return "/usr/bin/tool", nil }
+
+func TestKeyring_Linux(t *testing.T) {
+	var calls []call
+	k := newKeyring("linux")
+	k.lookPath = foundTool
+	k.run = fakeRun(&calls, result{stdout: "sbh_secret\n"})
+	ctx := context.Background()
+
+	if err := k.Store(ctx, "default@h", "sbh_secret"); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	got, err := k.Get(ctx, "default@h")
+	if err != nil || got != "sbh_secret" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if err := k.Erase(ctx, "default@h"); err != nil {
+		t.Fatalf("Erase() error = %v", err)
+	}
+
+	// The secret goes over stdin, never argv.
+	if calls[0].stdin != "sbh_secret" || strings.Contains(strings.Join(calls[0].argv, " "), "sbh_secret") {
+		t.Errorf("store call = %+v, want the secret on stdin only", calls[0])
+	}
+	want := []string{"secret-tool lookup service sbomhub-cli account default@h", "secret-tool clear service sbomhub-cli account default@h"}
+	for i, w := range want {
+		if got := strings.Join(calls[i+1].argv, " "); got != w {
+			t.Errorf("call %d = %q, want %q", i+1, got, w)
+		}
+	}
+}
+
+func TestKeyring_DarwinStoreKeepsSecretOffArgv(t *testing.T) {
+	var calls []call
+	k := newKeyring("darwin")
+	k.lookPath = foundTool
+	k.run = fakeRun(&calls, result{})
+	secret := `sbh_"odd\secret`
+
+	if err := k.Store(context.Background(), "default@h", secret); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if argv := strings.Join(calls[0].argv, " "); argv != "security -i" {
+		t.Errorf("store argv = %q, want security -i", argv)
+	}
+	want := `"add-generic-password" "-U" "-s" "sbomhub-cli" "-a" "default@h" "-l" "SBOMHub CLI (default@h)" "-w" "sbh_\"odd\\secret"` + "\n"
+	if calls[0].stdin != want {
+		t.Errorf("store stdin = %q, want %q", calls[0].stdin, want)
+	}
+
+	// -i can exit 0 after a failed command; its message still counts.
+	k.run = fakeRun(&calls, result{stderr: "security: SecKeychainItemCreateFromContent: User interaction is not allowed."})
+	if err := k.Store(context.Background(), "default@h", "sbh_x"); err == nil {
+		t.Error("Store() with an error on stderr = nil, want the error")
+	}
+	if err := k.Store(context.Background(), "default@h", "sbh_x\n"); err == nil {
+		t.Error("Store() of a secret with a line break = nil, want an error")
+	}
+}
+
+func TestKeyring_NotFound(t *testing.T) {
+	for goos, res := range map[string]result{
+		"darwin": {code: 44, stderr: "The specified item could not be found in the keychain."},
+		"linux":  {code: 1},
+	} {
+		var calls []call
+		k := newKeyring(goos)
+		k.lookPath = foundTool
+		k.run = fakeRun(&calls, res)
+
+		if _, err := k.Get(context.Background(), "a"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: Get() error = %v, want ErrNotFound", goos, err)
+		}
+		if err := k.Erase(context.Background(), "a"); err != nil {
+			t.Errorf("%s: Erase() of a missing item = %v, want nil", goos, err)
+		}
+	}
+}
+
+func TestKeyring_Unsupported(t *testing.T) {
+	k := newKeyring("windows")
+	if _, err := k.Get(context.Background(), "a"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("windows Get() error = %v, want ErrUnsupported", err)
+	}
+
+	k = newKeyring("linux")
+	k.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	if err := k.Store(context.Background(), "a", "s"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Store() without secret-tool = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestHelper_Protocol(t *testing.T) {
+	store, err := Helper("my-helper --vault team", "https://sbomhub.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := store.(*helper)
+	var calls []call
+	h.run = fakeRun(&calls, result{stdout: "username=default@sbomhub.example.com\npassword=sbh_from_helper\n"})
+	ctx := context.Background()
+
+	got, err := h.Get(ctx, "default@sbomhub.example.com")
+	if err != nil || got != "sbh_from_helper" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if err := h.Store(ctx, "default@sbomhub.example.com", "sbh_new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if argv := strings.Join(calls[0].argv, " "); argv != "my-helper --vault team get" {
+		t.Errorf("get argv = %q", argv)
+	}
+	wantIn := "protocol=https\nhost=sbomhub.example.com\nusername=default@sbomhub.example.com\n\n"
+	if calls[0].stdin != wantIn {
+		t.Errorf("get stdin = %q, want %q", calls[0].stdin, wantIn)
+	}
+	if !strings.Contains(calls[1].stdin, "password=sbh_new\n") || calls[1].argv[len(calls[1].argv)-1] != "store" {
+		t.Errorf("store call = %+v", calls[1])
+	}
+}
+
+// TestHelper_Script runs a real helper script end to end: store, get,
+// erase, then get again reports ErrNotFound.
+func TestHelper_Script(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script helper")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	secret := filepath.Join(dir, "secret")
+	body := `#!/bin/sh
+case "$1" in
+get)   [ -f "` + secret + `" ] && printf 'password=%s\n' "$(cat "` + secret + `")" ;;
+store) sed -n 's/^password=//p' > "` + secret + `" ;;
+erase) rm -f "` + secret + `" ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(body), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	store, err := Helper(script, "https://sbomhub.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := store.Store(ctx, "default", "sbh_script"); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if got, err := store.Get(ctx, "default"); err != nil || got != "sbh_script" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if err := store.Erase(ctx, "default"); err != nil {
+		t.Fatalf("Erase() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "default"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Erase = %v, want ErrNotFound", err)
+	}
+}
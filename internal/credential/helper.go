@@ -0,0 +1,106 @@
+package credential
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// helper delegates to an external program speaking git-credential's
+// protocol, so existing helpers (pass, 1Password / Vault wrappers, a
+// company secret broker) can hold the key. The command line from
+// credential_helper is split on whitespace and the action is appended:
+//
+//	<credential_helper...> get|store|erase
+//
+// with the request on stdin as key=value lines ended by a blank line:
+//
+//	protocol=https
+//	host=sbomhub.example.com
+//	username=default@sbomhub.example.com
+//	password=sbh_...          (store only)
+//
+// For get, the helper prints the same format and the CLI reads
+// password=. Printing nothing (or no password=) means "not found".
+type helper struct {
+	argv     []string
+	protocol string
+	host     string
+	run      runFunc
+}
+
+// Helper returns the credential-helper backend for command, telling it
+// the key belongs to apiURL.
+func Helper(command, apiURL string) (Store, error) {
+	argv := strings.Fields(command)
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("credential_helper が設定されていません")
+	}
+	h := &helper{argv: argv, run: runCommand}
+	if u, err := url.Parse(apiURL); err == nil {
+		h.protocol, h.host = u.Scheme, u.Host
+	}
+	return h, nil
+}
+
+func (h *helper) Get(ctx context.Context, account string) (string, error) {
+	out, res, err := h.call(ctx, "get", account, "")
+	if err != nil {
+		// Like git, a silent failure of get is "nothing stored" — a
+		// shell helper's last test command often decides its exit code.
+		if res.code != 0 && res.stderr == "" && res.stdout == "" {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			break
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && k == "password" && v != "" {
+			return v, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (h *helper) Store(ctx context.Context, account, secret string) error {
+	_, _, err := h.call(ctx, "store", account, secret)
+	return err
+}
+
+func (h *helper) Erase(ctx context.Context, account string) error {
+	_, _, err := h.call(ctx, "erase", account, "")
+	return err
+}
+
+// call runs the helper; a non-zero exit is returned as an error along
+// with the result so Get can inspect it.
+func (h *helper) call(ctx context.Context, action, account, secret string) (string, result, error) {
+	var in strings.Builder
+	if h.protocol != "" {
+		fmt.Fprintf(&in, "protocol=%s\n", h.protocol)
+	}
+	if h.host != "" {
+		fmt.Fprintf(&in, "host=%s\n", h.host)
+	}
+	fmt.Fprintf(&in, "username=%s\n", account)
+	if secret != "" {
+		fmt.Fprintf(&in, "password=%s\n", secret)
+	}
+	in.WriteString("\n")
+
+	args := append(append([]string{}, h.argv[1:]...), action)
+	res, err := h.run(ctx, in.String(), h.argv[0], args...)
+	if err != nil {
+		return "", res, fmt.Errorf("credential_helper (%s) を実行できません: %w", h.argv[0], err)
+	}
+	if res.code != 0 {
+		return "", res, toolError("credential_helper ("+h.argv[0]+" "+action+")", res)
+	}
+	return res.stdout, res, nil
+}
@@ -0,0 +1,170 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyring drives the platform's keychain CLI:
+//
+//   - macOS: security(1), the login keychain
+//   - Linux: secret-tool(1) from libsecret, i.e. GNOME Keyring / KWallet
+//     via the Secret Service D-Bus API
+//
+// Windows Credential Manager has no command-line tool that can read a
+// secret back, so Windows users get ErrUnsupported and are pointed at
+// credential_helper instead.
+type keyring struct {
+	goos     string
+	run      runFunc
+	lookPath func(string) (string, error)
+}
+
+// Keyring returns the OS keychain backend.
+func Keyring() Store {
+	return newKeyring(runtime.GOOS)
+}
+
+func newKeyring(goos string) *keyring {
+	return &keyring{goos: goos, run: runCommand, lookPath: exec.LookPath}
+}
+
+// KeyringAvailable reports whether this platform's keychain tool is
+// installed. It does not prove the keychain is unlocked or, on Linux,
+// that a Secret Service is running; login falls back to the config file
+// when the first Store fails.
+func KeyringAvailable() bool {
+	_, err := newKeyring(runtime.GOOS).tool()
+	return err == nil
+}
+
+func (k *keyring) tool() (string, error) {
+	var name string
+	switch k.goos {
+	case "darwin":
+		name = "security"
+	case "linux", "freebsd", "openbsd", "netbsd":
+		name = "secret-tool"
+	default:
+		return "", ErrUnsupported
+	}
+	if _, err := k.lookPath(name); err != nil {
+		return "", fmt.Errorf("%w (%s が見つかりません)", ErrUnsupported, name)
+	}
+	return name, nil
+}
+
+func (k *keyring) Get(ctx context.Context, account string) (string, error) {
+	name, err := k.tool()
+	if err != nil {
+		return "", err
+	}
+	var res result
+	if name == "security" {
+		res, err = k.run(ctx, "", name, "find-generic-password", "-s", Service, "-a", account, "-w")
+	} else {
+		res, err = k.run(ctx, "", name, "lookup", "service", Service, "account", account)
+	}
+	if err != nil {
+		return "", err
+	}
+	if k.notFound(name, res) {
+		return "", ErrNotFound
+	}
+	if res.code != 0 {
+		return "", toolError(name, res)
+	}
+	secret := strings.TrimRight(res.stdout, "\r\n")
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k *keyring) Store(ctx context.Context, account, secret string) error {
+	name, err := k.tool()
+	if err != nil {
+		return err
+	}
+	var res result
+	if name == "security" {
+		// security(1) only takes the password as an argument (-w), which
+		// any local user could read from the process list. Its
+		// interactive mode (-i) reads the same command line from stdin
+		// instead, so the key never reaches argv. -U updates an existing
+		// item instead of failing with "already exists".
+		line, err := securityCommandLine("add-generic-password", "-U",
+			"-s", Service, "-a", account, "-l", "SBOMHub CLI ("+account+")", "-w", secret)
+		if err != nil {
+			return err
+		}
+		res, err = k.run(ctx, line, name, "-i")
+		if err != nil {
+			return err
+		}
+		// -i carries on after a failed command and may still exit 0, so
+		// anything it reports on stderr is a failure too.
+		if res.code == 0 && res.stderr != "" {
+			res.code = 1
+		}
+	} else {
+		res, err = k.run(ctx, secret, name, "store", "--label", "SBOMHub CLI ("+account+")",
+			"service", Service, "account", account)
+	}
+	if err != nil {
+		return err
+	}
+	if res.code != 0 {
+		return toolError(name, res)
+	}
+	return nil
+}
+
+func (k *keyring) Erase(ctx context.Context, account string) error {
+	name, err := k.tool()
+	if err != nil {
+		return err
+	}
+	var res result
+	if name == "security" {
+		res, err = k.run(ctx, "", name, "delete-generic-password", "-s", Service, "-a", account)
+	} else {
+		res, err = k.run(ctx, "", name, "clear", "service", Service, "account", account)
+	}
+	if err != nil {
+		return err
+	}
+	if res.code != 0 && !k.notFound(name, res) {
+		return toolError(name, res)
+	}
+	return nil
+}
+
+// securityCommandLine renders args as one line for `security -i`, which
+// splits its input like a shell: every argument is double-quoted with
+// backslashes and quotes escaped. A line break would end the command
+// early, so arguments containing one are refused.
+func securityCommandLine(args ...string) (string, error) {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if strings.ContainsAny(a, "\r\n\x00") {
+			return "", fmt.Errorf("改行を含む値は macOS キーチェーンに保存できません")
+		}
+		a = strings.ReplaceAll(a, `\`, `\\`)
+		a = strings.ReplaceAll(a, `"`, `\"`)
+		quoted[i] = `"` + a + `"`
+	}
+	return strings.Join(quoted, " ") + "\n", nil
+}
+
+// notFound recognises each tool's "no such item" exit: security(1) uses
+// errSecItemNotFound (44); secret-tool exits 1 without a message.
+func (k *keyring) notFound(name string, res result) bool {
+	if name == "security" {
+		return res.code == 44
+	}
+	return res.code == 1 && res.stderr == ""
+}
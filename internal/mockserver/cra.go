@@ -0,0 +1,246 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// ----------------------------------------------------------------------------
+// CRA reports — /api/v1/projects/{id}/cra-reports
+// ----------------------------------------------------------------------------
+
+var (
+	craReportTypes = map[string]bool{"early_warning": true, "detailed_notification": true, "final_report": true}
+	craLangs       = map[string]bool{"ja": true, "en": true}
+)
+
+// handleRunReport drafts a report from the approved VEX draft for the
+// CVE. Like the real runner it refuses with 409 when no approved (or
+// edited) draft exists, which is what sends operators back to
+// `sbomhub triage` first.
+func (s *Server) handleRunReport(w http.ResponseWriter, r *http.Request) {
+	var req api.CRARunReportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Lang == "" {
+		req.Lang = "ja"
+	}
+	if !craReportTypes[req.ReportType] || !craLangs[req.Lang] {
+		writeError(w, http.StatusBadRequest, "report_type must be early_warning, detailed_notification or final_report and lang ja or en")
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	res, status, msg := s.draftReport(p, req)
+	if res == nil {
+		writeError(w, status, msg)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// draftReport creates a report row for req. On failure it returns the
+// HTTP status and message to answer with. Callers hold s.mu.
+func (s *Server) draftReport(p *api.Project, req api.CRARunReportRequest) (*api.CRARunReportResult, int, string) {
+	v := s.vulnerability(p.ID, req.VulnerabilityID, req.CVEID)
+	if v == nil {
+		return nil, http.StatusNotFound, "vulnerability not found"
+	}
+	var source *api.VEXDraft
+	for _, d := range s.drafts[p.ID] {
+		if d.VulnerabilityID != v.ID || (d.Decision != "approved" && d.Decision != "edited") {
+			continue
+		}
+		if req.SourceVEXDraftID == "" || d.ID == req.SourceVEXDraftID {
+			source = d
+		}
+	}
+	if source == nil {
+		return nil, http.StatusConflict, "no approved VEX draft for this vulnerability"
+	}
+
+	now := s.timestamp()
+	sourceID := source.ID
+	report := &api.CRAReport{
+		ID:               s.nextID(),
+		TenantID:         "mock-tenant",
+		ProjectID:        p.ID,
+		VulnerabilityID:  v.ID,
+		CVEID:            v.CVEID,
+		ReportType:       req.ReportType,
+		Lang:             req.Lang,
+		State:            "draft",
+		DraftText:        reportText(req, v, source),
+		SourceVEXDraftID: &sourceID,
+		Decision:         "pending",
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	res := &api.CRARunReportResult{Report: report}
+	evidence := []map[string]string{{"kind": "vex_draft", "ref": source.ID}}
+	if s.opts.AIDisabled {
+		evidence = append(evidence, map[string]string{"kind": "ai_disabled", "ref": "no LLM provider configured"})
+		res.AIDisabled = true
+	} else {
+		callID := s.nextID()
+		report.Provider, report.Model, report.LLMCallID = "mock", "mock-model", &callID
+		res.LLMCallID = callID
+	}
+	report.Evidence, _ = json.Marshal(evidence)
+	s.reports[p.ID] = append(s.reports[p.ID], report)
+	return res, 0, ""
+}
+
+// reportText renders the canned report body in the requested language.
+func reportText(req api.CRARunReportRequest, v *vulnRecord, source *api.VEXDraft) string {
+	product := req.ProductName
+	if product == "" {
+		product = v.pkg
+	}
+	if req.Lang == "en" {
+		return fmt.Sprintf("[%s] %s in %s %s\nSeverity: %s\nVEX status: %s\n%s\n",
+			req.ReportType, v.CVEID, product, v.version, v.Severity, source.State, v.Description)
+	}
+	return fmt.Sprintf("[%s] %s (%s %s)\n深刻度: %s\nVEX ステータス: %s\n%s\n",
+		req.ReportType, v.CVEID, product, v.version, v.Severity, source.State, v.Description)
+}
+
+func (s *Server) handleListReports(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	matched := make([]api.CRAReport, 0)
+	for _, rep := range s.reports[p.ID] {
+		if !matchQuery(q.Get("cve_id"), rep.CVEID) || !matchQuery(q.Get("report_type"), rep.ReportType) ||
+			!matchQuery(q.Get("lang"), rep.Lang) || !matchQuery(q.Get("state"), rep.State) ||
+			!matchQuery(q.Get("decision"), rep.Decision) {
+			continue
+		}
+		matched = append(matched, *rep)
+	}
+	s.mu.Unlock()
+	start, end := page(len(matched), limit, offset)
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(matched)))
+	writeJSON(w, http.StatusOK, map[string][]api.CRAReport{"reports": matched[start:end]})
+}
+
+// matchQuery reports whether a row value passes an optional query filter.
+func matchQuery(filter, value string) bool {
+	return filter == "" || filter == value
+}
+
+// report returns the project's report with id. Callers hold s.mu.
+func (s *Server) report(projectID, id string) *api.CRAReport {
+	for _, rep := range s.reports[projectID] {
+		if rep.ID == id {
+			return rep
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleGetReport(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	rep := s.report(p.ID, r.PathValue("report"))
+	if rep == nil {
+		writeError(w, http.StatusNotFound, "cra report not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, *rep)
+}
+
+// handleDecideReport applies approve / edit / reject. Approved and
+// edited reports move to state "approved"; rejected ones stay drafts.
+func (s *Server) handleDecideReport(w http.ResponseWriter, r *http.Request) {
+	var req api.CRADecisionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	switch req.Decision {
+	case "approved", "edited", "rejected":
+	default:
+		writeError(w, http.StatusBadRequest, "decision must be one of approved, edited, rejected")
+		return
+	}
+	if req.Decision == "edited" && req.EditedDraftText == nil {
+		writeError(w, http.StatusBadRequest, "edited_draft_text is required for decision=edited")
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	rep := s.report(p.ID, r.PathValue("report"))
+	if rep == nil {
+		writeError(w, http.StatusNotFound, "cra report not found")
+		return
+	}
+	if req.Decision == "edited" {
+		rep.DraftText = *req.EditedDraftText
+	}
+	if req.Decision != "rejected" {
+		rep.State = "approved"
+	}
+	now := s.timestamp()
+	by := mockUser
+	rep.Decision, rep.DecisionNote = req.Decision, req.DecisionNote
+	rep.DecisionBy, rep.DecisionAt, rep.UpdatedAt = &by, &now, now
+	writeJSON(w, http.StatusOK, *rep)
+}
+
+// handleReanalyseReport drafts a new row for the same vulnerability,
+// report type and language; non-empty fields of the body override the
+// original's.
+func (s *Server) handleReanalyseReport(w http.ResponseWriter, r *http.Request) {
+	var req api.CRARunReportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	orig := s.report(p.ID, r.PathValue("report"))
+	if orig == nil {
+		writeError(w, http.StatusNotFound, "cra report not found")
+		return
+	}
+	if req.VulnerabilityID == "" {
+		req.VulnerabilityID = orig.VulnerabilityID
+	}
+	if req.ReportType == "" {
+		req.ReportType = orig.ReportType
+	}
+	if req.Lang == "" {
+		req.Lang = orig.Lang
+	}
+	if req.SourceVEXDraftID == "" && orig.SourceVEXDraftID != nil {
+		req.SourceVEXDraftID = *orig.SourceVEXDraftID
+	}
+	res, status, msg := s.draftReport(p, req)
+	if res == nil {
+		writeError(w, status, msg)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
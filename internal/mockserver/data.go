@@ -0,0 +1,83 @@
+package mockserver
+
+import "strings"
+
+// Advisory is one entry of the mock's vulnerability database. A
+// component matches when its name equals Package (case-insensitive) and
+// its version equals Version.
+type Advisory struct {
+	Package   string
+	Version   string
+	ID        string
+	Severity  string // CRITICAL | HIGH | MEDIUM | LOW
+	Summary   string
+	FixedIn   string
+	CVSSScore float64
+	InKEV     bool
+}
+
+// DefaultAdvisories is a handful of well-known CVEs against versions
+// real SBOMs still contain, so a demo scan of an ordinary project has
+// something to report and --fail-on has something to trip on.
+var DefaultAdvisories = []Advisory{
+	{
+		Package: "log4j-core", Version: "2.14.1", ID: "CVE-2021-44228", Severity: "CRITICAL",
+		Summary: "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP and other JNDI related endpoints",
+		FixedIn: "2.15.0", CVSSScore: 10.0, InKEV: true,
+	},
+	{
+		Package: "minimist", Version: "1.2.5", ID: "CVE-2021-44906", Severity: "CRITICAL",
+		Summary: "Prototype pollution in minimist",
+		FixedIn: "1.2.6", CVSSScore: 9.8,
+	},
+	{
+		Package: "lodash", Version: "4.17.20", ID: "CVE-2021-23337", Severity: "HIGH",
+		Summary: "Command injection in lodash template",
+		FixedIn: "4.17.21", CVSSScore: 7.2,
+	},
+	{
+		Package: "jquery", Version: "3.4.1", ID: "CVE-2020-11022", Severity: "MEDIUM",
+		Summary: "Potential XSS vulnerability in jQuery.htmlPrefilter",
+		FixedIn: "3.5.0", CVSSScore: 6.1,
+	},
+}
+
+// match returns the advisories that apply to name@version.
+func match(advisories []Advisory, name, version string) []Advisory {
+	var out []Advisory
+	for _, a := range advisories {
+		if strings.EqualFold(a.Package, name) && a.Version == version {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// criterion is one entry of the mock's METI self-assessment catalog.
+// The real catalog is much longer; these cover each phase and each
+// status the evaluator can produce.
+type criterion struct {
+	id      string
+	phase   string
+	titleJA string
+	titleEN string
+}
+
+var criteria = []criterion{
+	{"env_setup.policy_documented", "env_setup", "SBOM 適用方針が文書化されている", "SBOM policy is documented"},
+	{"env_setup.owner_assigned", "env_setup", "SBOM 管理の責任者が決まっている", "An owner for SBOM management is assigned"},
+	{"sbom_creation.tool_selected", "sbom_creation", "SBOM 作成ツールが選定されている", "An SBOM generation tool is selected"},
+	{"sbom_creation.sbom_generated", "sbom_creation", "対象ソフトウェアの SBOM が作成されている", "An SBOM exists for the software"},
+	{"sbom_operation.vulnerabilities_triaged", "sbom_operation", "検出された脆弱性が評価されている", "Detected vulnerabilities are triaged"},
+	{"sbom_operation.review_cadence", "sbom_operation", "SBOM を定期的に見直している", "SBOMs are reviewed periodically"},
+}
+
+// criterionByID returns the catalog entry for id.
+func criterionByID(id string) (criterion, bool) {
+	for _, c := range criteria {
+		if c.id == id {
+			return c, true
+		}
+	}
+	return criterion{}, false
+}
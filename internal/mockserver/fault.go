@@ -0,0 +1,176 @@
+package mockserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault is a failure the server injects into matching requests before
+// the endpoint handler runs. Faults are matched in injection order; the
+// first live match wins.
+type Fault struct {
+	// Method restricts the fault to one HTTP method; empty matches any.
+	Method string
+	// Path is a URL path prefix; empty matches every path.
+	Path string
+	// Status is the HTTP status to answer with. Zero with Delay set
+	// means "slow but otherwise normal": the handler runs after the
+	// delay.
+	Status int
+	// RetryAfter is sent as a Retry-After header (whole seconds) when
+	// non-zero, as the servers do on 429 / 503.
+	RetryAfter time.Duration
+	// AIDisabled answers with the legacy BYOK-not-configured 503 — the
+	// body older servers sent before the ai_disabled=true 2xx path. It
+	// implies Status 503.
+	AIDisabled bool
+	// Delay holds the response back; a client timeout shorter than
+	// Delay sees a timeout.
+	Delay time.Duration
+	// Times limits how many requests the fault fires for; zero means
+	// every matching request.
+	Times int
+
+	hits int
+}
+
+// Inject adds f. It applies to requests received after the call.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// matchFault returns the first live fault matching r and counts the hit.
+// Callers hold s.mu.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for _, f := range s.faults {
+		if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 && f.hits >= f.Times {
+			continue
+		}
+		f.hits++
+		matched := *f
+		return &matched
+	}
+	return nil
+}
+
+// apply writes the fault's response. It returns false when the request
+// should still reach its handler (a pure delay) and true when the fault
+// answered it, including when the client went away during the delay.
+func (f *Fault) apply(w http.ResponseWriter, r *http.Request) bool {
+	if f.Delay > 0 {
+		t := time.NewTimer(f.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			return true
+		}
+	}
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+	}
+	switch {
+	case f.AIDisabled:
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error":  "AI features are disabled",
+			"reason": "no LLM provider configured",
+		})
+		return true
+	case f.Status != 0:
+		writeError(w, f.Status, fmt.Sprintf("injected fault: %d %s", f.Status, http.StatusText(f.Status)))
+		return true
+	}
+	return false
+}
+
+// ParseFault parses the `--fault` syntax of `sbomhub dev mock-server`:
+// comma-separated key=value pairs, or the bare words "ai-disabled" and
+// a status code as shorthands. Examples:
+//
+//	429
+//	status=429,path=/api/v1/cli/check,times=2,retry-after=1s
+//	ai-disabled,path=/api/v1/projects/
+//	delay=2s,method=GET
+func ParseFault(spec string) (Fault, error) {
+	var f Fault
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(part, "=")
+		if !hasValue {
+			switch {
+			case key == "ai-disabled":
+				f.AIDisabled = true
+				continue
+			case isDigits(key):
+				value, key = key, "status"
+			default:
+				return Fault{}, fmt.Errorf("fault %q: %q は key=value ではありません", spec, part)
+			}
+		}
+		var err error
+		switch key {
+		case "status":
+			f.Status, err = strconv.Atoi(value)
+			if err == nil && (f.Status < 100 || f.Status > 599) {
+				err = fmt.Errorf("out of range")
+			}
+		case "path":
+			f.Path = value
+		case "method":
+			f.Method = strings.ToUpper(value)
+		case "times":
+			f.Times, err = strconv.Atoi(value)
+			if err == nil && f.Times < 0 {
+				err = fmt.Errorf("negative")
+			}
+		case "retry-after":
+			f.RetryAfter, err = time.ParseDuration(value)
+		case "delay":
+			f.Delay, err = time.ParseDuration(value)
+		case "ai-disabled":
+			f.AIDisabled, err = strconv.ParseBool(value)
+		default:
+			return Fault{}, fmt.Errorf("fault %q: 未知のキー %q (status / path / method / times / retry-after / delay / ai-disabled)", spec, key)
+		}
+		if err != nil {
+			return Fault{}, fmt.Errorf("fault %q: %s の値 %q が不正です: %w", spec, key, value, err)
+		}
+	}
+	if f.Status == 0 && !f.AIDisabled && f.Delay == 0 {
+		return Fault{}, fmt.Errorf("fault %q: status / ai-disabled / delay のいずれかが必要です", spec)
+	}
+	return f, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package mockserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+func TestFault_TransientThenRecovers(t *testing.T) {
+	srv, client := newTestServer(t, Options{})
+	srv.Inject(Fault{Path: "/api/v1/cli/check", Status: http.StatusTooManyRequests, Times: 2})
+
+	if _, err := client.CheckVulnerabilities(context.Background(), []byte(testSBOM)); err != nil {
+		t.Fatalf("CheckVulnerabilities() = %v, want success after two retried 429s", err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("server saw %d requests, want 3 (two faults + success)", n)
+	}
+}
+
+func TestFault_RetryAfterHeader(t *testing.T) {
+	srv := New(Options{})
+	srv.Inject(Fault{Status: http.StatusServiceUnavailable, RetryAfter: 1500 * time.Millisecond})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/v1/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("got %d Retry-After=%q, want 503 with the delay rounded up to 2", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+}
+
+func TestFault_LegacyAIDisabled(t *testing.T) {
+	srv, client := newTestServer(t, Options{})
+	projectID := uploadTestSBOM(t, client).ProjectID
+	srv.Inject(Fault{Method: http.MethodPost, Path: "/api/v1/projects/" + projectID + "/triage", AIDisabled: true})
+
+	_, err := client.RunTriage(context.Background(), projectID, api.TriageRunRequest{CVEID: "CVE-2021-44228"})
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || !apiErr.IsAIDisabled() {
+		t.Fatalf("RunTriage() = %v, want the legacy AI-disabled 503", err)
+	}
+
+	srv.ClearFaults()
+	if _, err := client.RunTriage(context.Background(), projectID, api.TriageRunRequest{CVEID: "CVE-2021-44228"}); err != nil {
+		t.Errorf("RunTriage() after ClearFaults = %v", err)
+	}
+}
+
+func TestFault_DelayHitsClientTimeout(t *testing.T) {
+	srv, client := newTestServer(t, Options{})
+	srv.Inject(Fault{Delay: time.Second})
+	client.SetRetryPolicy(api.RetryPolicy{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.ListProjects(ctx); err == nil {
+		t.Fatal("ListProjects() succeeded through a 1s delay with a 50ms deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("timed-out request took %s", elapsed)
+	}
+}
+
+func TestParseFault(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    Fault
+		wantErr bool
+	}{
+		{spec: "429", want: Fault{Status: 429}},
+		{spec: "status=429,path=/api/v1/cli/check,times=2,retry-after=1s",
+			want: Fault{Status: 429, Path: "/api/v1/cli/check", Times: 2, RetryAfter: time.Second}},
+		{spec: "ai-disabled,method=post", want: Fault{AIDisabled: true, Method: "POST"}},
+		{spec: "delay=2s", want: Fault{Delay: 2 * time.Second}},
+		{spec: "path=/api", wantErr: true},
+		{spec: "status=42", wantErr: true},
+		{spec: "colour=red,status=500", wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := ParseFault(c.spec)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ParseFault(%q) error = %v, wantErr %v", c.spec, err, c.wantErr)
+			continue
+		}
+		if !c.wantErr && got != c.want {
+			t.Errorf("ParseFault(%q) = %+v, want %+v", c.spec, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// evaluatorVersion is what the mock reports as its METI evaluator.
+const evaluatorVersion = "mock-1"
+
+var metiStatuses = map[string]bool{"achieved": true, "not_achieved": true, "needs_review": true, "not_applicable": true}
+
+// ----------------------------------------------------------------------------
+// METI — /api/v1/projects/{id}/meti/...
+// ----------------------------------------------------------------------------
+
+// evaluate derives each criterion's status from the project's state:
+// SBOM criteria are achieved once an SBOM was uploaded, the triage
+// criterion once every vulnerability has a decided draft, and the
+// organisational ones always need review. Callers hold s.mu.
+func (s *Server) evaluate(p *api.Project, c criterion) (status string, evidence []map[string]string) {
+	hasSBOM := false
+	for _, sb := range s.sboms {
+		if sb.projectID == p.ID {
+			hasSBOM = true
+			evidence = append(evidence, map[string]string{"kind": "sbom", "ref": sb.id})
+		}
+	}
+	sort.Slice(evidence, func(i, j int) bool { return evidence[i]["ref"] < evidence[j]["ref"] })
+	switch c.id {
+	case "sbom_creation.tool_selected", "sbom_creation.sbom_generated":
+		if hasSBOM {
+			return "achieved", evidence
+		}
+		return "not_achieved", []map[string]string{}
+	case "sbom_operation.vulnerabilities_triaged":
+		if !hasSBOM {
+			return "not_achieved", []map[string]string{}
+		}
+		decided := map[string]bool{}
+		for _, d := range s.drafts[p.ID] {
+			if d.Decision != "pending" {
+				decided[d.VulnerabilityID] = true
+			}
+		}
+		for _, v := range s.vulns[p.ID] {
+			if !decided[v.ID] {
+				return "not_achieved", []map[string]string{{"kind": "vulnerability", "ref": v.CVEID}}
+			}
+		}
+		return "achieved", evidence
+	}
+	return "needs_review", []map[string]string{}
+}
+
+// refresh re-evaluates every criterion, keeping operator overrides and
+// improvement actions as the repository Upsert does. Callers hold s.mu.
+func (s *Server) refresh(p *api.Project) []*api.MetiAssessment {
+	existing := map[string]*api.MetiAssessment{}
+	for _, a := range s.assessments[p.ID] {
+		existing[a.CriterionID] = a
+	}
+	now := s.timestamp()
+	rows := make([]*api.MetiAssessment, 0, len(criteria))
+	for _, c := range criteria {
+		a := existing[c.id]
+		if a == nil {
+			a = &api.MetiAssessment{
+				ID:             s.nextID(),
+				TenantID:       "mock-tenant",
+				ProjectID:      p.ID,
+				CriterionID:    c.id,
+				CriterionPhase: c.phase,
+				CreatedAt:      now,
+			}
+		}
+		status, evidence := s.evaluate(p, c)
+		a.Status = status
+		a.Evidence, _ = json.Marshal(evidence)
+		a.EvaluatorVersion, a.EvaluatedAt, a.UpdatedAt = evaluatorVersion, now, now
+		rows = append(rows, a)
+	}
+	s.assessments[p.ID] = rows
+	return rows
+}
+
+// assessment returns the project's rows, evaluating on first access so
+// a fresh mock has something to list and override without an explicit
+// refresh. Callers hold s.mu.
+func (s *Server) assessment(p *api.Project) []*api.MetiAssessment {
+	if rows := s.assessments[p.ID]; rows != nil {
+		return rows
+	}
+	return s.refresh(p)
+}
+
+func (s *Server) handleListAssessment(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	hasOverride := q.Get("has_override")
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	matched := make([]api.MetiAssessment, 0)
+	for _, a := range s.assessment(p) {
+		if !matchQuery(q.Get("phase"), a.CriterionPhase) || !matchQuery(q.Get("status"), a.Status) {
+			continue
+		}
+		if hasOverride != "" && strconv.FormatBool(a.OverrideStatus != "") != hasOverride {
+			continue
+		}
+		matched = append(matched, *a)
+	}
+	s.mu.Unlock()
+	start, end := page(len(matched), limit, offset)
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(matched)))
+	writeJSON(w, http.StatusOK, map[string][]api.MetiAssessment{"assessments": matched[start:end]})
+}
+
+func (s *Server) handleRefreshAssessment(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	rows := s.refresh(p)
+	res := api.MetiRefreshResult{Assessments: make([]api.MetiAssessment, 0, len(rows)), EvaluatorVersion: evaluatorVersion, Refreshed: len(rows)}
+	for _, a := range rows {
+		res.Assessments = append(res.Assessments, *a)
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, res)
+}
+
+// criterionRow returns the project's row for the {criterion} path value,
+// answering 404 itself for ids outside the catalog. Callers hold s.mu.
+func (s *Server) criterionRow(w http.ResponseWriter, r *http.Request, p *api.Project) *api.MetiAssessment {
+	id := r.PathValue("criterion")
+	if _, ok := criterionByID(id); ok {
+		for _, a := range s.assessment(p) {
+			if a.CriterionID == id {
+				return a
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "assessment not found")
+	return nil
+}
+
+// handleOverrideCriterion applies an operator override. A row that is
+// already overridden is a 409, as in the server's state machine: the
+// override has to be cleared (with a note) first.
+func (s *Server) handleOverrideCriterion(w http.ResponseWriter, r *http.Request) {
+	var req api.MetiOverrideRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !metiStatuses[req.OverrideStatus] {
+		writeError(w, http.StatusBadRequest, "override_status must be one of achieved, not_achieved, needs_review, not_applicable")
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	a := s.criterionRow(w, r, p)
+	if a == nil {
+		return
+	}
+	if a.OverrideStatus != "" {
+		writeError(w, http.StatusConflict, "criterion is already overridden; clear the override first")
+		return
+	}
+	now := s.timestamp()
+	by := mockUser
+	a.OverrideStatus, a.OverrideNote = req.OverrideStatus, req.OverrideNote
+	a.OverrideBy, a.OverrideAt, a.UpdatedAt = &by, &now, now
+	if req.ImprovementAction != nil {
+		a.ImprovementAction = *req.ImprovementAction
+	}
+	writeJSON(w, http.StatusOK, *a)
+}
+
+func (s *Server) handleClearOverride(w http.ResponseWriter, r *http.Request) {
+	var req api.MetiClearOverrideRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if note := strings.TrimSpace(req.Note); note == "" || len(note) > 4096 {
+		writeError(w, http.StatusBadRequest, "note is required and must be 1-4096 characters")
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	a := s.criterionRow(w, r, p)
+	if a == nil {
+		return
+	}
+	if a.OverrideStatus == "" {
+		writeError(w, http.StatusNotFound, "assessment not found")
+		return
+	}
+	a.OverrideStatus, a.OverrideNote, a.OverrideBy, a.OverrideAt = "", "", nil, nil
+	a.UpdatedAt = s.timestamp()
+	writeJSON(w, http.StatusOK, *a)
+}
+
+// handleImprovementActions lists the rows whose effective status
+// (override over evaluator verdict) is neither achieved nor
+// not_applicable.
+func (s *Server) handleImprovementActions(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	actions := make([]api.ImprovementAction, 0)
+	for _, a := range s.assessment(p) {
+		effective := a.Status
+		if a.OverrideStatus != "" {
+			effective = a.OverrideStatus
+		}
+		if effective == "achieved" || effective == "not_applicable" {
+			continue
+		}
+		c, _ := criterionByID(a.CriterionID)
+		actions = append(actions, api.ImprovementAction{
+			CriterionID:       a.CriterionID,
+			CriterionPhase:    a.CriterionPhase,
+			CriterionTitleJA:  c.titleJA,
+			CriterionTitleEN:  c.titleEN,
+			Status:            a.Status,
+			OverrideStatus:    a.OverrideStatus,
+			EffectiveStatus:   effective,
+			Evidence:          a.Evidence,
+			ImprovementAction: a.ImprovementAction,
+		})
+	}
+	s.mu.Unlock()
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(actions)))
+	writeJSON(w, http.StatusOK, map[string][]api.ImprovementAction{"actions": actions})
+}
@@ -0,0 +1,56 @@
+package mockserver
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// tokenExchangeGrant is the RFC 8693 grant the CLI's keyless CI login
+// uses.
+const tokenExchangeGrant = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// exchangedTokenTTL is the expires_in of an exchanged token. Nothing
+// enforces it; it only gives the client a realistic value.
+const exchangedTokenTTL = 900
+
+// handleOAuthMetadata serves the RFC 8414 document pointing at the
+// mock's own token endpoint.
+func (s *Server) handleOAuthMetadata(w http.ResponseWriter, r *http.Request) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	base := fmt.Sprintf("%s://%s", scheme, r.Host)
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":         base,
+		"token_endpoint": base + "/oauth/token",
+	})
+}
+
+// handleToken is a fake token issuer: a token-exchange request whose
+// subject_token is Options.IDToken gets Options.APIKey back as a
+// short-lived access token; anything else is invalid_grant.
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != tokenExchangeGrant {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if r.PostForm.Get("subject_token") != s.opts.IDToken {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "unknown identity token"})
+		return
+	}
+	token := s.opts.APIKey
+	if token == "" {
+		token = "sbh_mock_exchanged"
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":      token,
+		"token_type":        "Bearer",
+		"expires_in":        exchangedTokenTTL,
+		"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+	})
+}
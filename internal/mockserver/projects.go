@@ -0,0 +1,553 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// ----------------------------------------------------------------------------
+// projects — /api/v1/cli/projects
+// ----------------------------------------------------------------------------
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	projects := s.Projects()
+	start, end := page(len(projects), limit, offset)
+	writeJSON(w, http.StatusOK, api.ProjectsListResponse{Projects: projects[start:end], Total: len(projects)})
+}
+
+// handleCreateProject is get-or-create by name, like the real CLI
+// endpoint: 201 + created=true for a new name, 200 + created=false for
+// an existing one.
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateProjectRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	s.mu.Lock()
+	p, created := s.getOrCreateProject(req.Name, req.Description)
+	res := api.CreateProjectResponse{Project: p, Created: created}
+	s.mu.Unlock()
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, res)
+}
+
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	res := *p
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, res)
+}
+
+// handleUpdateProject renames and/or re-describes a project; a name
+// another project already has is a 409.
+func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateProjectRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		writeError(w, http.StatusBadRequest, "name must not be empty")
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	if req.Name != nil {
+		for _, other := range s.projects {
+			if other != p && other.Name == *req.Name {
+				writeError(w, http.StatusConflict, "project name already exists")
+				return
+			}
+		}
+		p.Name = *req.Name
+	}
+	if req.Description != nil {
+		p.Description = *req.Description
+	}
+	p.UpdatedAt = s.timestamp()
+	writeJSON(w, http.StatusOK, *p)
+}
+
+// handleArchiveProject / handleUnarchiveProject set the archived flag;
+// repeating either is a no-op 200.
+func (s *Server) handleArchiveProject(w http.ResponseWriter, r *http.Request) {
+	s.setArchived(w, r, true)
+}
+
+func (s *Server) handleUnarchiveProject(w http.ResponseWriter, r *http.Request) {
+	s.setArchived(w, r, false)
+}
+
+func (s *Server) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	if p.Archived != archived {
+		now := s.timestamp()
+		p.Archived, p.UpdatedAt = archived, now
+		p.ArchivedAt = ""
+		if archived {
+			p.ArchivedAt = now
+		}
+	}
+	writeJSON(w, http.StatusOK, *p)
+}
+
+// handleDeleteProject drops the project and everything stored under it.
+func (s *Server) handleDeleteProject(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	for i, other := range s.projects {
+		if other == p {
+			s.projects = append(s.projects[:i], s.projects[i+1:]...)
+			break
+		}
+	}
+	for id, sbom := range s.sboms {
+		if sbom.projectID == p.ID {
+			delete(s.sboms, id)
+		}
+	}
+	delete(s.vulns, p.ID)
+	delete(s.drafts, p.ID)
+	delete(s.reports, p.ID)
+	delete(s.assessments, p.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getOrCreateProject returns the project called name, creating it when
+// missing. Callers hold s.mu.
+func (s *Server) getOrCreateProject(name, description string) (*api.Project, bool) {
+	for _, p := range s.projects {
+		if p.Name == name {
+			return p, false
+		}
+	}
+	now := s.timestamp()
+	p := &api.Project{ID: s.nextID(), Name: name, Description: description, CreatedAt: now, UpdatedAt: now}
+	s.projects = append(s.projects, p)
+	return p, true
+}
+
+// ----------------------------------------------------------------------------
+// SBOM upload / scan-status — /api/v1/projects/{id}/sbom(s)
+// ----------------------------------------------------------------------------
+
+// uploadResponse is the saved-SBOM body of POST /projects/{id}/sbom.
+// internal/api decodes it into an unexported type, so it is spelled out
+// here.
+type uploadResponse struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	Format    string `json:"format"`
+	Version   string `json:"version"`
+	CreatedAt string `json:"created_at"`
+}
+
+// handleUploadSBOM stores the SBOM, matches its components against the
+// advisory table and starts the (simulated) background scan. A repeated
+// Idempotency-Key replays the first response instead of storing a
+// second SBOM, which is what the client's retry of a keyed upload
+// relies on.
+func (s *Server) handleUploadSBOM(w http.ResponseWriter, r *http.Request) {
+	data, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	format, version, ok := detectFormat(data)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "unsupported SBOM format (expected CycloneDX or SPDX JSON)")
+		return
+	}
+	components, err := api.ExtractComponents(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid SBOM: "+err.Error())
+		return
+	}
+
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	if p.Archived {
+		writeError(w, http.StatusConflict, "project is archived")
+		return
+	}
+
+	replayKey := ""
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		replayKey = p.ID + "\x00" + key
+		if prev, ok := s.idempotent[replayKey]; ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(prev.status)
+			_, _ = w.Write(prev.body)
+			return
+		}
+	}
+
+	sbom := s.addSBOM(p, format, version, data, components)
+	body, _ := json.Marshal(uploadResponse{
+		ID:        sbom.id,
+		ProjectID: p.ID,
+		Format:    sbom.format,
+		Version:   sbom.version,
+		CreatedAt: sbom.uploadedAt.UTC().Format(time.RFC3339),
+	})
+	if replayKey != "" {
+		s.idempotent[replayKey] = recordedResponse{status: http.StatusCreated, body: body}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+// addSBOM records an SBOM for p and merges its matched advisories into
+// the project's vulnerability list. document is what `sboms download`
+// returns; nil for the seeded SBOM, which was never uploaded. Callers
+// hold s.mu.
+func (s *Server) addSBOM(p *api.Project, format, version string, document []byte, components []api.ComponentInput) *sbomRecord {
+	now := s.now()
+	sbom := &sbomRecord{
+		id:         s.nextID(),
+		projectID:  p.ID,
+		format:     format,
+		version:    version,
+		uploadedAt: now,
+		scannedAt:  now,
+		document:   document,
+		components: components,
+	}
+	s.scan(sbom)
+	s.sboms[sbom.id] = sbom
+	return sbom
+}
+
+// scan matches sbom's components against the advisory table afresh.
+// Callers hold s.mu.
+func (s *Server) scan(sbom *sbomRecord) {
+	sbom.summary = api.VulnerabilitySummary{}
+	for _, c := range sbom.components {
+		for _, a := range match(s.opts.Advisories, c.Name, c.Version) {
+			countSeverity(&sbom.summary, a)
+			s.addVulnerability(sbom.projectID, c, a)
+		}
+	}
+}
+
+// addVulnerability adds a to the project's list unless the same finding
+// (CVE on the same component version) is already there. Callers hold s.mu.
+func (s *Server) addVulnerability(projectID string, c api.ComponentInput, a Advisory) {
+	for _, v := range s.vulns[projectID] {
+		if v.CVEID == a.ID && v.pkg == c.Name && v.version == c.Version {
+			return
+		}
+	}
+	s.vulns[projectID] = append(s.vulns[projectID], &vulnRecord{
+		VulnerabilityRecord: api.VulnerabilityRecord{
+			ID:          s.nextID(),
+			CVEID:       a.ID,
+			Description: a.Summary,
+			Severity:    a.Severity,
+			CVSSScore:   a.CVSSScore,
+			InKEV:       a.InKEV,
+			Source:      "mock",
+		},
+		pkg:     c.Name,
+		version: c.Version,
+	})
+}
+
+func countSeverity(sum *api.VulnerabilitySummary, a Advisory) {
+	switch strings.ToUpper(a.Severity) {
+	case "CRITICAL":
+		sum.Critical++
+	case "HIGH":
+		sum.High++
+	case "MEDIUM":
+		sum.Medium++
+	case "LOW":
+		sum.Low++
+	default:
+		sum.Unknown++
+	}
+	if a.InKEV {
+		sum.KEV++
+	}
+	sum.Total++
+}
+
+// detectFormat tells CycloneDX from SPDX JSON the way the server does:
+// by the top-level bomFormat / spdxVersion keys.
+func detectFormat(data []byte) (format, version string, ok bool) {
+	var head struct {
+		BOMFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return "", "", false
+	}
+	switch {
+	case strings.EqualFold(head.BOMFormat, "CycloneDX"):
+		return "cyclonedx", head.SpecVersion, true
+	case head.SPDXVersion != "":
+		return "spdx", strings.TrimPrefix(head.SPDXVersion, "SPDX-"), true
+	}
+	return "", "", false
+}
+
+// handleScanStatus reports "running" until ScanDuration has passed since
+// the upload or the latest rescan, then "completed" with the matched
+// counts. Counts while running are zero, i.e. partial, as on the real
+// server.
+func (s *Server) handleScanStatus(w http.ResponseWriter, r *http.Request) {
+	sbom, ok := s.lockSBOM(w, r)
+	if !ok {
+		return
+	}
+	res := s.scanStatus(sbom)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, res)
+}
+
+// scanStatus is the scan-status body of sbom. Callers hold s.mu.
+func (s *Server) scanStatus(sbom *sbomRecord) api.ScanStatusResponse {
+	res := api.ScanStatusResponse{Status: "completed", SbomID: sbom.id, ProjectID: sbom.projectID}
+	if s.now().Before(sbom.scannedAt.Add(s.opts.ScanDuration)) {
+		res.Status = "running"
+	} else {
+		res.Vulnerabilities = sbom.summary
+	}
+	return res
+}
+
+// lockSBOM is lockProject for /projects/{id}/sboms/{sbom}: the SBOM with
+// s.mu held, or a 404 when either does not exist.
+func (s *Server) lockSBOM(w http.ResponseWriter, r *http.Request) (*sbomRecord, bool) {
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return nil, false
+	}
+	sbom := s.sboms[r.PathValue("sbom")]
+	if sbom == nil || sbom.projectID != p.ID {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "sbom not found")
+		return nil, false
+	}
+	return sbom, true
+}
+
+// ----------------------------------------------------------------------------
+// SBOM history — /api/v1/projects/{id}/sboms
+// ----------------------------------------------------------------------------
+
+// handleListSBOMs pages through the project's SBOMs, newest first.
+func (s *Server) handleListSBOMs(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	all := make([]api.SBOM, 0)
+	for _, sbom := range s.sboms {
+		if sbom.projectID != p.ID {
+			continue
+		}
+		all = append(all, api.SBOM{
+			ID:             sbom.id,
+			ProjectID:      p.ID,
+			Format:         sbom.format,
+			Version:        sbom.version,
+			CreatedAt:      sbom.uploadedAt.UTC().Format(time.RFC3339),
+			ComponentCount: len(sbom.components),
+			ScanStatus:     s.scanStatus(sbom).Status,
+		})
+	}
+	s.mu.Unlock()
+	// IDs are sequential, so they order by upload even within a second.
+	sort.Slice(all, func(i, j int) bool { return all[i].ID > all[j].ID })
+	start, end := page(len(all), limit, offset)
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(all)))
+	writeJSON(w, http.StatusOK, api.SBOMsListResponse{SBOMs: all[start:end], Total: len(all)})
+}
+
+// handleDownloadSBOM returns the document as uploaded. The seeded SBOM
+// has none and answers 404 like a purged document would.
+func (s *Server) handleDownloadSBOM(w http.ResponseWriter, r *http.Request) {
+	sbom, ok := s.lockSBOM(w, r)
+	if !ok {
+		return
+	}
+	doc := sbom.document
+	s.mu.Unlock()
+	if doc == nil {
+		writeError(w, http.StatusNotFound, "sbom document not stored")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(doc)
+}
+
+// handleDeleteSBOM deletes the SBOM and the project's findings that no
+// remaining SBOM was matched on.
+func (s *Server) handleDeleteSBOM(w http.ResponseWriter, r *http.Request) {
+	sbom, ok := s.lockSBOM(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	delete(s.sboms, sbom.id)
+	type pkgVersion struct{ name, version string }
+	remaining := map[pkgVersion]bool{}
+	for _, other := range s.sboms {
+		if other.projectID != sbom.projectID {
+			continue
+		}
+		for _, c := range other.components {
+			remaining[pkgVersion{c.Name, c.Version}] = true
+		}
+	}
+	kept := s.vulns[sbom.projectID][:0]
+	for _, v := range s.vulns[sbom.projectID] {
+		if remaining[pkgVersion{v.pkg, v.version}] {
+			kept = append(kept, v)
+		}
+	}
+	s.vulns[sbom.projectID] = kept
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRescanSBOM rematches the SBOM against the advisory table and
+// restarts its simulated scan. While a scan is running it only reports
+// the running state, so a retried POST does not restart the clock.
+func (s *Server) handleRescanSBOM(w http.ResponseWriter, r *http.Request) {
+	sbom, ok := s.lockSBOM(w, r)
+	if !ok {
+		return
+	}
+	if s.scanStatus(sbom).Status != "running" {
+		sbom.scannedAt = s.now()
+		s.scan(sbom)
+	}
+	res := s.scanStatus(sbom)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusAccepted, res)
+}
+
+// ----------------------------------------------------------------------------
+// check — POST /api/v1/cli/check
+// ----------------------------------------------------------------------------
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	var req api.CheckVulnerabilitiesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	res := api.CheckResult{
+		TotalComponents: len(req.Components),
+		BySeverity:      map[string]int{},
+		Vulnerabilities: []api.VulnerabilityItem{},
+	}
+	for _, c := range req.Components {
+		for _, a := range match(s.opts.Advisories, c.Name, c.Version) {
+			inKEV := a.InKEV
+			res.Vulnerabilities = append(res.Vulnerabilities, api.VulnerabilityItem{
+				Package:    c.Name,
+				Version:    c.Version,
+				ID:         a.ID,
+				Severity:   a.Severity,
+				Summary:    a.Summary,
+				FixedIn:    a.FixedIn,
+				Aliases:    []string{},
+				References: []string{"https://nvd.nist.gov/vuln/detail/" + a.ID},
+				CVSSScore:  a.CVSSScore,
+				InKEV:      &inKEV,
+			})
+			res.BySeverity[strings.ToUpper(a.Severity)]++
+			res.Total++
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ----------------------------------------------------------------------------
+// vulnerabilities — GET /api/v1/projects/{id}/vulnerabilities
+// ----------------------------------------------------------------------------
+
+// handleListVulnerabilities answers with a bare JSON array page, the
+// shape the Web UI and the CLI both read.
+func (s *Server) handleListVulnerabilities(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	all := s.vulns[p.ID]
+	start, end := page(len(all), limit, offset)
+	out := make([]api.VulnerabilityRecord, 0, end-start)
+	for _, v := range all[start:end] {
+		out = append(out, v.VulnerabilityRecord)
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, out)
+}
+
+// vulnerability returns the project's vulnerability with id, or the
+// first one with cveID when id is empty. Callers hold s.mu.
+func (s *Server) vulnerability(projectID, id, cveID string) *vulnRecord {
+	for _, v := range s.vulns[projectID] {
+		if (id != "" && v.ID == id) || (id == "" && cveID != "" && v.CVEID == cveID) {
+			return v
+		}
+	}
+	return nil
+}
+
+// seed creates the "demo" project with one SBOM carrying two of the
+// default advisories.
+func (s *Server) seed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, _ := s.getOrCreateProject("demo", "sbomhub dev mock-server のデモプロジェクト")
+	s.addSBOM(p, "cyclonedx", "1.5", nil, []api.ComponentInput{
+		{Name: "log4j-core", Version: "2.14.1", Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+		{Name: "lodash", Version: "4.17.20", Purl: "pkg:npm/lodash@4.17.20"},
+		{Name: "express", Version: "4.18.2", Purl: "pkg:npm/express@4.18.2"},
+	})
+}
@@ -0,0 +1,400 @@
+// Package mockserver is an in-memory stand-in for the SBOMHub API.
+//
+// It implements the endpoints the CLI calls — projects, SBOM upload,
+// scan-status, check, vulnerabilities, triage / VEX drafts, CRA reports,
+// METI self-assessment, health and capabilities, plus an optional fake
+// token issuer for keyless CI login — against maps held in memory, so
+// the CLI's own tests, `sbomhub dev mock-server` and the CI templates can
+// run the full flow offline:
+//
+//	srv := mockserver.New(mockserver.Options{APIKey: "sbh_test"})
+//	ts := httptest.NewServer(srv)
+//	defer ts.Close()
+//	client := api.NewClient(ts.URL, "sbh_test")
+//
+// Wire shapes are the internal/api DTOs, so a field the client decodes is
+// a field the mock emits. That makes the mock useless for catching drift
+// between the CLI and the real server — the handler tests in internal/api
+// still pin those against recorded payloads — but it keeps the mock from
+// drifting away from the client.
+//
+// Behaviour is deliberately simple and deterministic:
+//   - Vulnerabilities come from a fixed advisory table (Options.Advisories,
+//     DefaultAdvisories otherwise) matched on component name and version.
+//   - An uploaded SBOM's scan reports "running" for Options.ScanDuration
+//     after the upload (or a rescan), then "completed" with the matched
+//     counts.
+//   - Triage and CRA runs produce canned drafts instead of calling an
+//     LLM; with Options.AIDisabled they take the BYOK-not-configured path
+//     (2xx + ai_disabled=true) the real server uses.
+//
+// Faults (429, the legacy AI-disabled 503, slow responses, ...) are
+// injected per request with Inject; see fault.go.
+package mockserver
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// Version is what the mock reports as its server version in the
+// capabilities document.
+const Version = "0.0.0-mock"
+
+// maxListLimit mirrors the servers' ?limit= clamp on paginated list
+// endpoints (vulnerabilities, cra-reports, meti/assessment).
+const maxListLimit = 500
+
+// Options configures a Server. The zero value is a server without
+// authentication, no seeded data and instantly completing scans.
+type Options struct {
+	// APIKey, when set, is the only Bearer token the tenant-scoped
+	// endpoints accept. health and capabilities are public, as on the
+	// real server.
+	APIKey string
+	// ScanDuration is how long an uploaded SBOM's scan stays "running".
+	ScanDuration time.Duration
+	// AIDisabled makes triage / CRA runs answer as a server without a
+	// BYOK provider: under_investigation drafts with ai_disabled=true,
+	// and health reporting the LLM as disconnected.
+	AIDisabled bool
+	// Advisories replaces DefaultAdvisories as the vulnerability source.
+	Advisories []Advisory
+	// IDToken, when set, turns on a fake token issuer: the server
+	// declares token_exchange, publishes OAuth metadata and exchanges
+	// this CI identity token (RFC 8693) for APIKey, so keyless CI login
+	// can be tested offline.
+	IDToken string
+	// Seed creates a "demo" project with one uploaded SBOM so list and
+	// triage flows have something to show straight away.
+	Seed bool
+}
+
+// RecordedRequest is one request the server received, in arrival order.
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	// Encoding is the request's Content-Encoding.
+	Encoding string
+	// IdempotencyKey is the request's Idempotency-Key header.
+	IdempotencyKey string
+}
+
+// Server is the mock API. It is an http.Handler; wrap it in httptest or
+// an http.Server to listen. All methods are safe for concurrent use.
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+	now  func() time.Time
+
+	mu          sync.Mutex
+	seq         int
+	projects    []*api.Project
+	sboms       map[string]*sbomRecord
+	vulns       map[string][]*vulnRecord
+	drafts      map[string][]*api.VEXDraft
+	reports     map[string][]*api.CRAReport
+	assessments map[string][]*api.MetiAssessment
+	idempotent  map[string]recordedResponse
+	faults      []*Fault
+	requests    []RecordedRequest
+}
+
+// sbomRecord is an uploaded SBOM and the findings matched against it.
+type sbomRecord struct {
+	id         string
+	projectID  string
+	format     string
+	version    string
+	uploadedAt time.Time
+	// scannedAt starts the (simulated) scan: the upload, or the latest
+	// rescan.
+	scannedAt  time.Time
+	document   []byte
+	components []api.ComponentInput
+	summary    api.VulnerabilitySummary
+}
+
+// vulnRecord is a project vulnerability row plus the component it was
+// matched on (the real API keeps that relation in a join table).
+type vulnRecord struct {
+	api.VulnerabilityRecord
+	pkg     string
+	version string
+}
+
+// recordedResponse is a response replayed for a repeated Idempotency-Key.
+type recordedResponse struct {
+	status int
+	body   []byte
+}
+
+// New returns a Server configured by opts.
+func New(opts Options) *Server {
+	if opts.Advisories == nil {
+		opts.Advisories = DefaultAdvisories
+	}
+	s := &Server{
+		opts:        opts,
+		mux:         http.NewServeMux(),
+		now:         time.Now,
+		sboms:       map[string]*sbomRecord{},
+		vulns:       map[string][]*vulnRecord{},
+		drafts:      map[string][]*api.VEXDraft{},
+		reports:     map[string][]*api.CRAReport{},
+		assessments: map[string][]*api.MetiAssessment{},
+		idempotent:  map[string]recordedResponse{},
+	}
+	s.routes()
+	if opts.Seed {
+		s.seed()
+	}
+	return s
+}
+
+func (s *Server) routes() {
+	public := func(pattern string, h http.HandlerFunc) { s.mux.HandleFunc(pattern, h) }
+	tenant := func(pattern string, h http.HandlerFunc) { s.mux.HandleFunc(pattern, s.requireAuth(h)) }
+
+	public("GET /api/v1/health", s.handleHealth)
+	public("GET /api/v1/capabilities", s.handleCapabilities)
+	if s.opts.IDToken != "" {
+		public("GET /.well-known/oauth-authorization-server", s.handleOAuthMetadata)
+		public("POST /oauth/token", s.handleToken)
+	}
+
+	tenant("GET /api/v1/cli/projects", s.handleListProjects)
+	tenant("POST /api/v1/cli/projects", s.handleCreateProject)
+	tenant("GET /api/v1/cli/projects/{id}", s.handleGetProject)
+	tenant("PATCH /api/v1/cli/projects/{id}", s.handleUpdateProject)
+	tenant("POST /api/v1/cli/projects/{id}/archive", s.handleArchiveProject)
+	tenant("POST /api/v1/cli/projects/{id}/unarchive", s.handleUnarchiveProject)
+	tenant("DELETE /api/v1/cli/projects/{id}", s.handleDeleteProject)
+	tenant("POST /api/v1/cli/check", s.handleCheck)
+	tenant("POST /api/v1/projects/{id}/sbom", s.handleUploadSBOM)
+	tenant("GET /api/v1/projects/{id}/sboms", s.handleListSBOMs)
+	tenant("GET /api/v1/projects/{id}/sboms/{sbom}/document", s.handleDownloadSBOM)
+	tenant("DELETE /api/v1/projects/{id}/sboms/{sbom}", s.handleDeleteSBOM)
+	tenant("POST /api/v1/projects/{id}/sboms/{sbom}/rescan", s.handleRescanSBOM)
+	tenant("GET /api/v1/projects/{id}/sboms/{sbom}/scan-status", s.handleScanStatus)
+	tenant("GET /api/v1/projects/{id}/vulnerabilities", s.handleListVulnerabilities)
+
+	tenant("POST /api/v1/projects/{id}/triage/run", s.handleRunTriage)
+	tenant("GET /api/v1/projects/{id}/vex-drafts", s.handleListDrafts)
+	tenant("PUT /api/v1/projects/{id}/vex-drafts/{draft}/decision", s.handleDecideDraft)
+
+	tenant("POST /api/v1/projects/{id}/cra-reports/run", s.handleRunReport)
+	tenant("GET /api/v1/projects/{id}/cra-reports", s.handleListReports)
+	tenant("GET /api/v1/projects/{id}/cra-reports/{report}", s.handleGetReport)
+	tenant("PUT /api/v1/projects/{id}/cra-reports/{report}/decision", s.handleDecideReport)
+	tenant("POST /api/v1/projects/{id}/cra-reports/{report}/reanalyse", s.handleReanalyseReport)
+
+	tenant("GET /api/v1/projects/{id}/meti/assessment", s.handleListAssessment)
+	tenant("POST /api/v1/projects/{id}/meti/assessment/refresh", s.handleRefreshAssessment)
+	tenant("PUT /api/v1/projects/{id}/meti/assessment/{criterion}/override", s.handleOverrideCriterion)
+	tenant("DELETE /api/v1/projects/{id}/meti/assessment/{criterion}/override", s.handleClearOverride)
+	tenant("GET /api/v1/projects/{id}/meti/improvement-actions", s.handleImprovementActions)
+}
+
+// ServeHTTP records the request, applies any matching fault and then
+// dispatches to the endpoint handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if id := r.Header.Get("X-Request-ID"); id != "" {
+		w.Header().Set("X-Request-ID", id)
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method:         r.Method,
+		Path:           r.URL.Path,
+		Query:          r.URL.RawQuery,
+		Encoding:       r.Header.Get("Content-Encoding"),
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	})
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault != nil && fault.apply(w, r) {
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Requests returns a copy of every request received so far.
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// Projects returns a copy of the projects in creation order.
+func (s *Server) Projects() []api.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]api.Project, 0, len(s.projects))
+	for _, p := range s.projects {
+		out = append(out, *p)
+	}
+	return out
+}
+
+// requireAuth rejects requests without the configured Bearer token.
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.APIKey != "" && r.Header.Get("Authorization") != "Bearer "+s.opts.APIKey {
+			writeError(w, http.StatusUnauthorized, "invalid api key")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// health / capabilities
+// ----------------------------------------------------------------------------
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	connected := !s.opts.AIDisabled
+	res := api.LLMHealthResponse{Status: "ok", Mode: "byok", Connected: &connected}
+	if connected {
+		res.Provider, res.Model = "mock", "mock-model"
+	} else {
+		res.Reason = "no LLM provider configured"
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	features := []string{
+		api.FeatureSBOMUpload, api.FeatureScanStatus, api.FeatureGzipUpload,
+		api.FeatureLLMHealth, api.FeatureTriage, api.FeatureVEX,
+		api.FeatureCRA, api.FeatureMETI, api.FeatureIdempotencyKey,
+	}
+	if s.opts.IDToken != "" {
+		features = append(features, api.FeatureTokenExchange)
+	}
+	writeJSON(w, http.StatusOK, api.Capabilities{Version: Version, Features: features})
+}
+
+// ----------------------------------------------------------------------------
+// helpers
+// ----------------------------------------------------------------------------
+
+// nextID returns a UUID-shaped identifier (the CLI treats explicit
+// UUID-shaped --project values as IDs). Callers hold s.mu.
+func (s *Server) nextID() string {
+	s.seq++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.seq)
+}
+
+// timestamp formats the current time the way the API does. Callers hold
+// s.mu or do not care about ordering.
+func (s *Server) timestamp() string {
+	return s.now().UTC().Format(time.RFC3339)
+}
+
+// project returns the project with id. Callers hold s.mu.
+func (s *Server) project(id string) *api.Project {
+	for _, p := range s.projects {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// lockProject locks s.mu and resolves the {id} path value, answering 404
+// itself when the project does not exist. On success the caller owns
+// the lock and must unlock it.
+func (s *Server) lockProject(w http.ResponseWriter, r *http.Request) (*api.Project, bool) {
+	s.mu.Lock()
+	p := s.project(r.PathValue("id"))
+	if p == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "project not found")
+		return nil, false
+	}
+	return p, true
+}
+
+// readBody reads the request body, undoing Content-Encoding: gzip.
+func readBody(r *http.Request) ([]byte, error) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		body = zr
+	}
+	return io.ReadAll(body)
+}
+
+// decodeJSON decodes the request body into v, answering 400 itself on
+// failure.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	data, err := readBody(r)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// pageParams parses ?limit=&offset= with the server's defaults and clamp.
+// An out-of-range value is a 400, as on the real endpoints.
+func pageParams(w http.ResponseWriter, r *http.Request) (limit, offset int, ok bool) {
+	limit, offset = 100, 0
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+			return 0, 0, false
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
+
+// page returns the [offset, offset+limit) window of n items.
+func page(n, limit, offset int) (start, end int) {
+	if offset > n {
+		offset = n
+	}
+	end = offset + limit
+	if end > n {
+		end = n
+	}
+	return offset, end
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
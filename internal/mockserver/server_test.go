@@ -0,0 +1,381 @@
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+const testSBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"name": "log4j-core", "version": "2.14.1"},
+    {"name": "lodash", "version": "4.17.20"},
+    {"name": "express", "version": "4.18.2"}
+  ]
+}`
+
+// newTestServer starts srv behind httptest and returns a client for it
+// with fast retries.
+func newTestServer(t *testing.T, opts Options) (*Server, *api.Client) {
+	t.Helper()
+	srv := New(opts)
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	client := api.NewClient(ts.URL, opts.APIKey)
+	client.SetRetryPolicy(api.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, MaxRetryAfter: time.Second})
+	return srv, client
+}
+
+// uploadTestSBOM uploads testSBOM into project "app" and returns the result.
+func uploadTestSBOM(t *testing.T, client *api.Client) *api.UploadResult {
+	t.Helper()
+	res, err := client.UploadSBOMFrom(context.Background(), "app", false, api.SBOMBytes([]byte(testSBOM)), "", api.UploadOptions{Gzip: true})
+	if err != nil {
+		t.Fatalf("UploadSBOMFrom() = %v", err)
+	}
+	return res
+}
+
+func TestServer_ProjectsUploadAndScanStatus(t *testing.T) {
+	srv, client := newTestServer(t, Options{APIKey: "sbh_test", ScanDuration: time.Minute})
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return now }
+
+	res := uploadTestSBOM(t, client)
+	if !res.ProjectCreated || res.Format != "cyclonedx" || res.Encoding != "gzip" {
+		t.Errorf("upload = %+v", res)
+	}
+	if _, created, err := client.CreateProject(ctx, "app", ""); err != nil || created {
+		t.Errorf("second CreateProject(app) = created %v, %v; want the existing project", created, err)
+	}
+	projects, err := client.ListProjects(ctx)
+	if err != nil || len(projects) != 1 || projects[0].ID != res.ProjectID {
+		t.Fatalf("ListProjects() = %+v, %v", projects, err)
+	}
+
+	status, err := client.GetScanStatus(ctx, res.ProjectID, res.SBOMID)
+	if err != nil || status.Status != "running" {
+		t.Fatalf("scan-status right after upload = %+v, %v; want running", status, err)
+	}
+	now = now.Add(time.Minute)
+	status, err = client.GetScanStatus(ctx, res.ProjectID, res.SBOMID)
+	if err != nil || status.Status != "completed" {
+		t.Fatalf("scan-status after ScanDuration = %+v, %v; want completed", status, err)
+	}
+	want := api.VulnerabilitySummary{Critical: 1, High: 1, KEV: 1, Total: 2}
+	if status.Vulnerabilities != want {
+		t.Errorf("counts = %+v, want %+v", status.Vulnerabilities, want)
+	}
+
+	vulns, err := client.ListVulnerabilities(ctx, res.ProjectID)
+	if err != nil || len(vulns) != 2 || vulns[0].CVEID != "CVE-2021-44228" || !vulns[0].InKEV {
+		t.Errorf("ListVulnerabilities() = %+v, %v", vulns, err)
+	}
+
+	var apiErr *api.Error
+	_, err = client.GetScanStatus(ctx, res.ProjectID, "no-such-sbom")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown sbom = %v, want 404", err)
+	}
+}
+
+func TestServer_ProjectLifecycle(t *testing.T) {
+	_, client := newTestServer(t, Options{})
+	ctx := context.Background()
+	res := uploadTestSBOM(t, client)
+	if _, _, err := client.CreateProject(ctx, "other", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var apiErr *api.Error
+	taken := "other"
+	if _, err := client.UpdateProject(ctx, res.ProjectID, api.UpdateProjectRequest{Name: &taken}); !errors.As(err, &apiErr) || !apiErr.IsConflict() {
+		t.Errorf("rename onto an existing name = %v, want 409", err)
+	}
+	name, desc := "renamed", "the app"
+	p, err := client.UpdateProject(ctx, res.ProjectID, api.UpdateProjectRequest{Name: &name, Description: &desc})
+	if err != nil || p.Name != name || p.Description != desc {
+		t.Errorf("UpdateProject() = %+v, %v", p, err)
+	}
+
+	if p, err = client.ArchiveProject(ctx, res.ProjectID); err != nil || !p.Archived || p.ArchivedAt == "" {
+		t.Errorf("ArchiveProject() = %+v, %v", p, err)
+	}
+	_, err = client.UploadSBOMFrom(ctx, name, false, api.SBOMBytes([]byte(testSBOM)), "", api.UploadOptions{})
+	if !errors.As(err, &apiErr) || !apiErr.IsConflict() {
+		t.Errorf("upload to an archived project = %v, want 409", err)
+	}
+	if p, err = client.UnarchiveProject(ctx, res.ProjectID); err != nil || p.Archived {
+		t.Errorf("UnarchiveProject() = %+v, %v", p, err)
+	}
+
+	if err := client.DeleteProject(ctx, res.ProjectID); err != nil {
+		t.Fatalf("DeleteProject() = %v", err)
+	}
+	if _, err := client.GetScanStatus(ctx, res.ProjectID, res.SBOMID); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("scan-status after delete = %v, want 404", err)
+	}
+	if err := client.DeleteProject(ctx, res.ProjectID); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("second DeleteProject() = %v, want 404", err)
+	}
+}
+
+func TestServer_SBOMHistory(t *testing.T) {
+	srv, client := newTestServer(t, Options{ScanDuration: time.Minute})
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return now }
+
+	first := uploadTestSBOM(t, client)
+	clean := `{"bomFormat":"CycloneDX","specVersion":"1.6","components":[{"name":"express","version":"4.18.2"}]}`
+	second, err := client.UploadSBOMFrom(ctx, "app", false, api.SBOMBytes([]byte(clean)), "", api.UploadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+
+	sboms, err := client.ListSBOMs(ctx, first.ProjectID)
+	if err != nil || len(sboms) != 2 || sboms[0].ID != second.SBOMID || sboms[1].ComponentCount != 3 || sboms[1].ScanStatus != "completed" {
+		t.Fatalf("ListSBOMs() = %+v, %v; want newest first", sboms, err)
+	}
+	var doc bytes.Buffer
+	if _, err := client.DownloadSBOM(ctx, first.ProjectID, first.SBOMID, &doc); err != nil || doc.String() != testSBOM {
+		t.Errorf("DownloadSBOM() = %q, %v; want the uploaded document", doc.String(), err)
+	}
+
+	status, err := client.RescanSBOM(ctx, first.ProjectID, first.SBOMID)
+	if err != nil || status.Status != "running" {
+		t.Fatalf("RescanSBOM() = %+v, %v; want running", status, err)
+	}
+	now = now.Add(time.Minute)
+	if status, err = client.GetScanStatus(ctx, first.ProjectID, first.SBOMID); err != nil || status.Vulnerabilities.Total != 2 {
+		t.Errorf("scan-status after the rescan = %+v, %v", status, err)
+	}
+
+	if err := client.DeleteSBOM(ctx, first.ProjectID, first.SBOMID); err != nil {
+		t.Fatalf("DeleteSBOM() = %v", err)
+	}
+	if vulns, err := client.ListVulnerabilities(ctx, first.ProjectID); err != nil || len(vulns) != 0 {
+		t.Errorf("vulnerabilities after deleting the only vulnerable SBOM = %+v, %v", vulns, err)
+	}
+	var apiErr *api.Error
+	if _, err := client.DownloadSBOM(ctx, first.ProjectID, first.SBOMID, io.Discard); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("DownloadSBOM() after delete = %v, want 404", err)
+	}
+}
+
+func TestServer_UploadReplaysIdempotencyKey(t *testing.T) {
+	srv, client := newTestServer(t, Options{})
+	p, _, err := client.CreateProject(context.Background(), "app", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/projects/"+p.ID+"/sbom", bytes.NewReader([]byte(testSBOM)))
+		req.Header.Set("Idempotency-Key", "key-1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(resp.Body)
+		resp.Body.Close()
+		ids = append(ids, buf.String())
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("replayed upload differs:\n%s\n%s", ids[0], ids[1])
+	}
+	if n := len(srv.sboms); n != 1 {
+		t.Errorf("stored %d SBOMs, want 1 for a repeated Idempotency-Key", n)
+	}
+}
+
+func TestServer_Check(t *testing.T) {
+	_, client := newTestServer(t, Options{})
+	res, err := client.CheckVulnerabilities(context.Background(), []byte(testSBOM))
+	if err != nil {
+		t.Fatalf("CheckVulnerabilities() = %v", err)
+	}
+	if res.TotalComponents != 3 || res.Total != 2 || res.Critical != 1 || res.High != 1 {
+		t.Errorf("check = %+v", res)
+	}
+	if v := res.Vulnerabilities[0]; v.ID != "CVE-2021-44228" || v.FixedIn != "2.15.0" || v.InKEV == nil || !*v.InKEV {
+		t.Errorf("first finding = %+v", v)
+	}
+}
+
+func TestServer_RequiresAPIKey(t *testing.T) {
+	srv := New(Options{APIKey: "sbh_right"})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	client := api.NewClient(ts.URL, "sbh_wrong")
+	client.SetRetryPolicy(api.RetryPolicy{})
+
+	_, err := client.ListProjects(context.Background())
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || !apiErr.IsPermanent() {
+		t.Errorf("wrong key = %v, want a permanent 401", err)
+	}
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Errorf("health is public; Health() = %v", err)
+	}
+	if caps, err := client.GetCapabilities(context.Background()); err != nil || caps.Lacks(api.FeatureMETI) {
+		t.Errorf("GetCapabilities() = %+v, %v", caps, err)
+	}
+}
+
+func TestServer_TriageAndCRAFlow(t *testing.T) {
+	_, client := newTestServer(t, Options{})
+	ctx := context.Background()
+	projectID := uploadTestSBOM(t, client).ProjectID
+
+	log4j, err := client.RunTriage(ctx, projectID, api.TriageRunRequest{CVEID: "CVE-2021-44228"})
+	if err != nil {
+		t.Fatalf("RunTriage(log4j) = %v", err)
+	}
+	if log4j.Draft.State != "affected" || log4j.Clamped || log4j.AIDisabled {
+		t.Errorf("log4j triage = %+v", log4j)
+	}
+	lodash, err := client.RunTriage(ctx, projectID, api.TriageRunRequest{CVEID: "CVE-2021-23337"})
+	if err != nil || !lodash.Clamped || lodash.Draft.State != "under_investigation" {
+		t.Errorf("low-confidence triage = %+v, %v; want clamped to under_investigation", lodash, err)
+	}
+
+	_, err = client.RunReport(ctx, projectID, api.CRARunReportRequest{CVEID: "CVE-2021-44228", ReportType: "early_warning", Lang: "ja"})
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("RunReport before approval = %v, want 409", err)
+	}
+
+	if _, err := client.DecideDraft(ctx, projectID, log4j.Draft.ID, api.DecisionRequest{Decision: "approved"}); err != nil {
+		t.Fatalf("DecideDraft() = %v", err)
+	}
+	approved, err := client.ListAllVEXDrafts(ctx, projectID, api.VEXDraftListFilter{Decision: "approved"})
+	if err != nil || len(approved) != 1 || approved[0].DecidedBy == "" {
+		t.Errorf("approved drafts = %+v, %v", approved, err)
+	}
+
+	run, err := client.RunReport(ctx, projectID, api.CRARunReportRequest{CVEID: "CVE-2021-44228", ReportType: "early_warning", Lang: "en"})
+	if err != nil {
+		t.Fatalf("RunReport() = %v", err)
+	}
+	if run.Report.State != "draft" || len(run.Report.Evidence) == 0 || run.Report.SourceVEXDraftID == nil {
+		t.Errorf("report = %+v", run.Report)
+	}
+	again, err := client.ReanalyseReport(ctx, projectID, run.Report.ID, api.CRARunReportRequest{ReportType: "final_report"})
+	if err != nil || again.Report.ID == run.Report.ID || again.Report.ReportType != "final_report" || again.Report.Lang != "en" {
+		t.Errorf("ReanalyseReport() = %+v, %v", again, err)
+	}
+	text := "edited"
+	decided, err := client.DecideReport(ctx, projectID, run.Report.ID, api.CRADecisionRequest{Decision: "edited", EditedDraftText: &text})
+	if err != nil || decided.State != "approved" || decided.DraftText != text {
+		t.Errorf("DecideReport() = %+v, %v", decided, err)
+	}
+	reports, total, err := client.ListReports(ctx, projectID, api.CRAReportListFilter{State: "draft"})
+	if err != nil || total != 1 || len(reports) != 1 || reports[0].ID != again.Report.ID {
+		t.Errorf("ListReports(state=draft) = %d rows, total %d, %v", len(reports), total, err)
+	}
+}
+
+func TestServer_AIDisabled(t *testing.T) {
+	_, client := newTestServer(t, Options{AIDisabled: true, Seed: true})
+	ctx := context.Background()
+	projects, err := client.ListProjects(ctx)
+	if err != nil || len(projects) != 1 || projects[0].Name != "demo" {
+		t.Fatalf("seeded projects = %+v, %v", projects, err)
+	}
+
+	res, err := client.RunTriage(ctx, projects[0].ID, api.TriageRunRequest{CVEID: "CVE-2021-44228"})
+	if err != nil || !res.AIDisabled || res.Draft.State != "under_investigation" || res.Draft.Confidence != nil {
+		t.Errorf("RunTriage() with AI disabled = %+v, %v", res, err)
+	}
+	health, err := client.Health(ctx)
+	if err != nil || health.Connected == nil || *health.Connected || health.Reason == "" {
+		t.Errorf("Health() = %+v, %v; want disconnected with a reason", health, err)
+	}
+}
+
+func TestServer_METI(t *testing.T) {
+	_, client := newTestServer(t, Options{})
+	ctx := context.Background()
+	projectID := uploadTestSBOM(t, client).ProjectID
+	const criterionID = "env_setup.policy_documented"
+
+	rows, total, err := client.GetAssessment(ctx, projectID, api.MetiAssessmentListFilter{Phase: "sbom_creation"})
+	if err != nil || total != 2 || len(rows) != 2 || rows[0].Status != "achieved" {
+		t.Fatalf("GetAssessment(sbom_creation) = %+v, %d, %v", rows, total, err)
+	}
+
+	action := "方針を文書化する"
+	row, err := client.OverrideCriterion(ctx, projectID, criterionID, api.MetiOverrideRequest{OverrideStatus: "achieved", OverrideNote: "社内規程で対応済み", ImprovementAction: &action})
+	if err != nil || row.OverrideStatus != "achieved" || row.ImprovementAction != action {
+		t.Fatalf("OverrideCriterion() = %+v, %v", row, err)
+	}
+	_, err = client.OverrideCriterion(ctx, projectID, criterionID, api.MetiOverrideRequest{OverrideStatus: "not_achieved"})
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("second override = %v, want 409", err)
+	}
+
+	refreshed, err := client.RefreshAssessment(ctx, projectID)
+	if err != nil || refreshed.Refreshed != len(criteria) {
+		t.Fatalf("RefreshAssessment() = %+v, %v", refreshed, err)
+	}
+	overridden := true
+	rows, _, err = client.GetAssessment(ctx, projectID, api.MetiAssessmentListFilter{HasOverride: &overridden})
+	if err != nil || len(rows) != 1 || rows[0].CriterionID != criterionID {
+		t.Errorf("refresh must keep the override; has_override rows = %+v, %v", rows, err)
+	}
+
+	actions, _, err := client.GetImprovementActions(ctx, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range actions {
+		if a.CriterionID == criterionID || a.EffectiveStatus == "achieved" {
+			t.Errorf("achieved criterion listed as an action: %+v", a)
+		}
+	}
+
+	if err := client.ClearOverrideCriterion(ctx, projectID, criterionID, api.MetiClearOverrideRequest{Note: "誤って上書き"}); err != nil {
+		t.Fatalf("ClearOverrideCriterion() = %v", err)
+	}
+	err = client.ClearOverrideCriterion(ctx, projectID, criterionID, api.MetiClearOverrideRequest{Note: "again"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("clearing a row without an override = %v, want 404", err)
+	}
+}
+
+func TestServer_TokenExchange(t *testing.T) {
+	_, client := newTestServer(t, Options{APIKey: "sbh_test", IDToken: "ci-jwt"})
+	ctx := context.Background()
+
+	ep, err := client.DiscoverOAuth(ctx, "")
+	if err != nil {
+		t.Fatalf("DiscoverOAuth() = %v", err)
+	}
+	if _, err := client.ExchangeToken(ctx, ep.TokenEndpoint, api.DefaultOAuthClientID, "other-jwt", api.JWTTokenType, ""); err == nil {
+		t.Error("ExchangeToken() with an unknown identity token succeeded")
+	}
+	tok, err := client.ExchangeToken(ctx, ep.TokenEndpoint, api.DefaultOAuthClientID, "ci-jwt", api.JWTTokenType, "")
+	if err != nil {
+		t.Fatalf("ExchangeToken() = %v", err)
+	}
+	if tok.AccessToken != "sbh_test" || tok.Expiry.IsZero() {
+		t.Errorf("token = %+v, want the server's API key with an expiry", tok)
+	}
+}
@@ -0,0 +1,174 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// confidenceThreshold is the SBOMHUB_AI_CONFIDENCE_THRESHOLD the mock
+// runs with; drafts below it are demoted to under_investigation.
+const confidenceThreshold = 0.7
+
+// mockUser is who the mock records as the decider of drafts / reports.
+const mockUser = "mock-user"
+
+// ----------------------------------------------------------------------------
+// triage — POST /api/v1/projects/{id}/triage/run
+// ----------------------------------------------------------------------------
+
+// handleRunTriage produces a canned draft instead of calling an LLM:
+//   - KEV-listed or CRITICAL findings are "affected" at 0.9 confidence;
+//   - everything else is "not_affected" at 0.6, below the threshold, so
+//     the draft comes back clamped to under_investigation.
+//
+// That gives the CLI's approve path and its clamped / non-interactive
+// path something to exercise. With Options.AIDisabled the draft is an
+// unscored under_investigation row with ai_disabled=true.
+func (s *Server) handleRunTriage(w http.ResponseWriter, r *http.Request) {
+	var req api.TriageRunRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	v := s.vulnerability(p.ID, req.VulnerabilityID, req.CVEID)
+	if v == nil {
+		writeError(w, http.StatusNotFound, "vulnerability not found")
+		return
+	}
+
+	now := s.timestamp()
+	evidence := []api.TriageEvidence{{
+		Kind:        "sbom_component",
+		Description: v.pkg + "@" + v.version,
+		Source:      "mock",
+	}}
+	evidenceJSON, _ := json.Marshal(evidence)
+	draft := &api.VEXDraft{
+		ID:              s.nextID(),
+		ProjectID:       p.ID,
+		ComponentID:     req.ComponentID,
+		VulnerabilityID: v.ID,
+		CVEID:           v.CVEID,
+		Evidence:        evidenceJSON,
+		Decision:        "pending",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if draft.ComponentID == "" {
+		draft.ComponentID = "component-" + v.pkg
+	}
+	res := api.TriageRunResult{Draft: draft, Drafts: []*api.VEXDraft{draft}, Threshold: confidenceThreshold}
+
+	if s.opts.AIDisabled {
+		draft.State = "under_investigation"
+		draft.Detail = "AI triage skipped: no LLM provider configured"
+		res.AIDisabled = true
+		res.Parsed = &api.ParsedDecision{State: draft.State, Detail: draft.Detail}
+	} else {
+		parsed := &api.ParsedDecision{Evidence: evidence}
+		if v.InKEV || strings.EqualFold(v.Severity, "CRITICAL") {
+			parsed.State = "affected"
+			parsed.Detail = v.pkg + " " + v.version + " is in the affected range and reachable"
+			parsed.Confidence = 0.9
+		} else {
+			parsed.State = "not_affected"
+			parsed.Justification = "vulnerable_code_not_in_execute_path"
+			parsed.Detail = "The vulnerable function of " + v.pkg + " is not referenced"
+			parsed.Confidence = 0.6
+		}
+		if parsed.Confidence < confidenceThreshold {
+			parsed.State, parsed.Justification = "under_investigation", ""
+			res.Clamped = true
+		}
+		confidence := parsed.Confidence
+		draft.State = parsed.State
+		draft.Justification = parsed.Justification
+		draft.Detail = parsed.Detail
+		draft.Confidence = &confidence
+		draft.Provider, draft.Model = "mock", "mock-model"
+		res.Parsed = parsed
+		res.LLMCallID = s.nextID()
+	}
+
+	s.drafts[p.ID] = append(s.drafts[p.ID], draft)
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// ----------------------------------------------------------------------------
+// VEX drafts — /api/v1/projects/{id}/vex-drafts
+// ----------------------------------------------------------------------------
+
+func (s *Server) handleListDrafts(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	matched := make([]api.VEXDraft, 0)
+	for _, d := range s.drafts[p.ID] {
+		if cve := q.Get("cve_id"); cve != "" && d.CVEID != cve {
+			continue
+		}
+		if dec := q.Get("decision"); dec != "" && d.Decision != dec {
+			continue
+		}
+		matched = append(matched, *d)
+	}
+	s.mu.Unlock()
+	start, end := page(len(matched), limit, offset)
+	writeJSON(w, http.StatusOK, map[string][]api.VEXDraft{"drafts": matched[start:end]})
+}
+
+func (s *Server) handleDecideDraft(w http.ResponseWriter, r *http.Request) {
+	var req api.DecisionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	switch req.Decision {
+	case "approved", "edited", "rejected":
+	default:
+		writeError(w, http.StatusBadRequest, "decision must be one of approved, edited, rejected")
+		return
+	}
+	p, ok := s.lockProject(w, r)
+	if !ok {
+		return
+	}
+	defer s.mu.Unlock()
+	var draft *api.VEXDraft
+	for _, d := range s.drafts[p.ID] {
+		if d.ID == r.PathValue("draft") {
+			draft = d
+		}
+	}
+	if draft == nil {
+		writeError(w, http.StatusNotFound, "vex draft not found")
+		return
+	}
+	if req.Decision == "edited" {
+		if req.EditedState != "" {
+			draft.State = req.EditedState
+		}
+		if req.EditedJustification != "" {
+			draft.Justification = req.EditedJustification
+		}
+		if req.EditedDetail != "" {
+			draft.Detail = req.EditedDetail
+		}
+	}
+	now := s.timestamp()
+	draft.Decision = req.Decision
+	draft.DecidedBy, draft.DecidedAt, draft.UpdatedAt = mockUser, now, now
+	writeJSON(w, http.StatusOK, *draft)
+}
@@ -1,11 +1,6 @@
 package scanner
 
-import (
-	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-)
+import "context"
 
 // CdxgenScanner implements Scanner using cdxgen
 type CdxgenScanner struct{}
@@ -18,34 +13,22 @@ func (s *CdxgenScanner) Available() bool {
 	return commandExists("cdxgen")
 }
 
-func (s *CdxgenScanner) Scan(path string, format string) ([]byte, error) {
+func (s *CdxgenScanner) Version() (string, error) {
+	return toolVersion("cdxgen", "--version")
+}
+
+func (s *CdxgenScanner) Scan(ctx context.Context, path, format, outFile string) error {
 	// Note: cdxgen doesn't natively support SPDX output.
 	// Format parameter is ignored; CycloneDX is always used.
 	_ = format
 
-	// Use temporary file instead of stdout (-o -)
-	// cdxgen's stdout mode includes ANSI escape codes which corrupts JSON output
-	tempDir, err := os.MkdirTemp("", "sbomhub-cdxgen-*")
-	if err != nil {
-		return nil, fmt.Errorf("一時ディレクトリ作成エラー: %w", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	outputFile := filepath.Join(tempDir, "sbom.json")
-	args := []string{"-o", outputFile, path}
+	// Write to a file instead of stdout (-o -): cdxgen's stdout mode
+	// includes ANSI escape codes which corrupt the JSON output.
+	args := []string{"-o", outFile, path}
 
-	cmd := exec.Command("cdxgen", args...)
+	cmd := command(ctx, "cdxgen", args...)
 	if err := cmd.Run(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("cdxgen 実行エラー: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("cdxgen 実行エラー: %w", err)
+		return runError(ctx, "cdxgen", err)
 	}
-
-	output, err := os.ReadFile(outputFile)
-	if err != nil {
-		return nil, fmt.Errorf("SBOM読み込みエラー: %w", err)
-	}
-
-	return output, nil
+	return nil
 }
@@ -1,8 +1,13 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"runtime"
+	"strings"
+	"time"
 )
 
 // Scanner interface for SBOM generation tools
@@ -11,8 +16,16 @@ type Scanner interface {
 	Name() string
 	// Available checks if the scanner is available on the system
 	Available() bool
-	// Scan generates an SBOM from the given path
-	Scan(path string, format string) ([]byte, error)
+	// Scan generates an SBOM from the given path and writes it to
+	// outFile, which it creates or truncates. The SBOM goes straight from
+	// the tool to disk: container SBOMs run to hundreds of MB and are
+	// streamed from there on upload rather than held in memory.
+	// Cancelling ctx stops the tool's subprocess.
+	Scan(ctx context.Context, path, format, outFile string) error
+	// Version returns the tool's self-reported version output. It is
+	// only compared for equality (the SBOM cache keys on it), so the raw
+	// output is returned rather than parsed.
+	Version() (string, error)
 }
 
 // New creates a new scanner based on the tool name
@@ -64,3 +77,50 @@ func commandExists(name string) bool {
 	_, err := exec.LookPath(name)
 	return err == nil
 }
+
+// toolVersion runs a version subcommand and returns its trimmed stdout.
+func toolVersion(name string, args ...string) (string, error) {
+	output, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s バージョン取得エラー: %w", name, err)
+	}
+	v := strings.TrimSpace(string(output))
+	if v == "" {
+		return "", fmt.Errorf("%s バージョン取得エラー: 出力が空です", name)
+	}
+	return v, nil
+}
+
+// cancelGrace is how long a cancelled tool gets to exit after the
+// interrupt before it is killed.
+const cancelGrace = 5 * time.Second
+
+// command builds a ctx-bound tool invocation. On cancellation the tool
+// is sent an interrupt first (so syft / trivy / cdxgen can remove their
+// temp files) and killed if it is still running after cancelGrace.
+// Windows cannot deliver os.Interrupt to a child process, so it is
+// killed straight away there.
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Cancel = func() error {
+		if runtime.GOOS == "windows" {
+			return cmd.Process.Kill()
+		}
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = cancelGrace
+	return cmd
+}
+
+// runError formats a tool failure. A cancellation is reported as such
+// (wrapping ctx.Err() for errors.Is) instead of as the interrupted
+// process's exit status.
+func runError(ctx context.Context, name string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s の実行を中断しました: %w", name, ctxErr)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return fmt.Errorf("%s 実行エラー: %s", name, string(exitErr.Stderr))
+	}
+	return fmt.Errorf("%s 実行エラー: %w", name, err)
+}
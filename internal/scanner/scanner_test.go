@@ -1,7 +1,11 @@
 package scanner
 
 import (
+	"context"
+	"errors"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewScanner(t *testing.T) {
@@ -122,7 +126,7 @@ func TestScanWithUnavailableTool(t *testing.T) {
 				t.Skipf("%s is installed, skipping unavailable test", tt.name)
 			}
 
-			_, err := tt.scanner.Scan(".", "cyclonedx")
+			err := tt.scanner.Scan(context.Background(), ".", "cyclonedx", filepath.Join(t.TempDir(), "sbom.json"))
 			if err == nil {
 				t.Errorf("%s.Scan() expected error when tool unavailable", tt.name)
 			}
@@ -136,3 +140,23 @@ func TestScannerInterface(t *testing.T) {
 	var _ Scanner = &TrivyScanner{}
 	var _ Scanner = &CdxgenScanner{}
 }
+
+func TestCommandCancellationStopsProcess(t *testing.T) {
+	if !commandExists("sleep") {
+		t.Skip("sleep not available")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := command(ctx, "sleep", "30").Run()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("cancelled command ran for %v", elapsed)
+	}
+	if err == nil {
+		t.Fatal("Run() = nil, want an error after cancellation")
+	}
+	if got := runError(ctx, "sleep", err); !errors.Is(got, context.Canceled) {
+		t.Errorf("runError() = %v, want it to wrap context.Canceled", got)
+	}
+}
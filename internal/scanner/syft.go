@@ -1,9 +1,6 @@
 package scanner
 
-import (
-	"fmt"
-	"os/exec"
-)
+import "context"
 
 // SyftScanner implements Scanner using Syft
 type SyftScanner struct{}
@@ -16,20 +13,20 @@ func (s *SyftScanner) Available() bool {
 	return commandExists("syft")
 }
 
-func (s *SyftScanner) Scan(path string, format string) ([]byte, error) {
+func (s *SyftScanner) Version() (string, error) {
+	return toolVersion("syft", "version")
+}
+
+func (s *SyftScanner) Scan(ctx context.Context, path, format, outFile string) error {
 	outputFormat := "cyclonedx-json"
 	if format == "spdx" {
 		outputFormat = "spdx-json"
 	}
 
-	cmd := exec.Command("syft", path, "-o", outputFormat, "--quiet")
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("syft 実行エラー: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("syft 実行エラー: %w", err)
+	// syft writes the SBOM itself with `-o <format>=<file>`.
+	cmd := command(ctx, "syft", path, "-o", outputFormat+"="+outFile, "--quiet")
+	if _, err := cmd.Output(); err != nil {
+		return runError(ctx, "syft", err)
 	}
-
-	return output, nil
+	return nil
 }
@@ -1,9 +1,6 @@
 package scanner
 
-import (
-	"fmt"
-	"os/exec"
-)
+import "context"
 
 // TrivyScanner implements Scanner using Trivy
 type TrivyScanner struct{}
@@ -16,20 +13,19 @@ func (s *TrivyScanner) Available() bool {
 	return commandExists("trivy")
 }
 
-func (s *TrivyScanner) Scan(path string, format string) ([]byte, error) {
+func (s *TrivyScanner) Version() (string, error) {
+	return toolVersion("trivy", "--version")
+}
+
+func (s *TrivyScanner) Scan(ctx context.Context, path, format, outFile string) error {
 	outputFormat := "cyclonedx"
 	if format == "spdx" {
 		outputFormat = "spdx-json"
 	}
 
-	cmd := exec.Command("trivy", "fs", path, "--format", outputFormat, "--quiet")
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("trivy 実行エラー: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("trivy 実行エラー: %w", err)
+	cmd := command(ctx, "trivy", "fs", path, "--format", outputFormat, "--output", outFile, "--quiet")
+	if _, err := cmd.Output(); err != nil {
+		return runError(ctx, "trivy", err)
 	}
-
-	return output, nil
+	return nil
 }
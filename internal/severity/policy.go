@@ -0,0 +1,308 @@
+package severity
+
+// Policy rules — a small YAML rule set for gates that a single --fail-on
+// threshold cannot express, e.g. "fail if KEV, or CVSS >= 9 with a fix
+// available; warn on High":
+//
+//	rules:
+//	  - name: kev
+//	    when: {kev: true}
+//	    action: fail
+//	    exit_code: 10
+//	  - name: critical-with-fix
+//	    when: {cvss_gte: 9, fix_available: true}
+//	    action: fail
+//	  - name: high
+//	    when: {severity_gte: high}
+//	    action: warn
+//
+// A rule's `when` is a conjunction of the fields it sets; `any:` / `all:`
+// nest further conditions for OR / AND. Rules are evaluated against every
+// finding; the exit code of the run is that of the FIRST failing rule in
+// file order, so operators order rules by the signal they want CI to see.
+//
+// Conditions only see what the data source provides. `scan` works from
+// the project's vulnerability rows (severity, CVSS, KEV — no fix or scope
+// data); `check` works from the per-component check result (severity,
+// fix, scope — no CVSS or KEV). A condition on a field the source does
+// not carry never matches, so a rule never fires on missing data.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule actions.
+const (
+	ActionFail = "fail"
+	ActionWarn = "warn"
+)
+
+// DefaultPolicyExitCode is used by fail rules that do not set exit_code.
+// It matches the --fail-on threshold exit code.
+const DefaultPolicyExitCode = 1
+
+// reservedExitCodes are the codes of the CLI's shared exit-code table that
+// mean something other than a gate failure. A rule may not return them: a
+// CI wrapper that retries on 4 (transient API error) must not retry a
+// policy failure. 130 (interrupted) is above the allowed range anyway.
+var reservedExitCodes = map[int]string{
+	2: "scan タイムアウト",
+	3: "恒久エラー",
+	4: "一時エラー",
+}
+
+// Policy is a parsed rule set.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+
+	// Path is the file the policy was loaded from, for messages.
+	Path string `yaml:"-"`
+}
+
+// Rule is a single named condition with an action.
+type Rule struct {
+	Name     string    `yaml:"name"`
+	When     Condition `yaml:"when"`
+	Action   string    `yaml:"action"`
+	ExitCode int       `yaml:"exit_code,omitempty"`
+}
+
+// Condition is a conjunction of field predicates. Pointer fields
+// distinguish "not set" from the zero value.
+type Condition struct {
+	SeverityGTE  string      `yaml:"severity_gte,omitempty"`
+	CVSSGTE      *float64    `yaml:"cvss_gte,omitempty"`
+	KEV          *bool       `yaml:"kev,omitempty"`
+	FixAvailable *bool       `yaml:"fix_available,omitempty"`
+	Scope        []string    `yaml:"scope,omitempty"`
+	Any          []Condition `yaml:"any,omitempty"`
+	All          []Condition `yaml:"all,omitempty"`
+
+	severityLevel Level
+}
+
+// Finding is the policy view of one vulnerability finding. HasCVSS /
+// HasFix / Scope=="" mark fields the data source did not provide.
+type Finding struct {
+	ID           string
+	Package      string
+	Version      string
+	Severity     Level
+	CVSSScore    float64
+	HasCVSS      bool
+	InKEV        bool
+	HasKEV       bool
+	FixAvailable bool
+	HasFix       bool
+	Scope        string
+}
+
+// RuleHit is one rule that matched at least one finding.
+type RuleHit struct {
+	Rule     string   `json:"rule"`
+	Action   string   `json:"action"`
+	ExitCode int      `json:"exit_code"`
+	Findings []string `json:"findings"`
+}
+
+// Outcome is the result of evaluating a policy.
+type Outcome struct {
+	Failed []RuleHit `json:"failed"`
+	Warned []RuleHit `json:"warned"`
+	// ExitCode is the exit code of the first failing rule, 0 when no
+	// fail rule matched.
+	ExitCode int `json:"exit_code"`
+}
+
+// HasFailure reports whether any fail rule matched.
+func (o Outcome) HasFailure() bool { return len(o.Failed) > 0 }
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p.Path = path
+	return p, nil
+}
+
+// ParsePolicy decodes and validates policy content, reporting every
+// invalid rule at once.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("ポリシーの解析に失敗しました: %w", err)
+	}
+	if len(p.Rules) == 0 {
+		return nil, errors.New("ポリシーに rules がありません")
+	}
+
+	var errs []error
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		where := fmt.Sprintf("rules[%d]", i)
+		if r.Name != "" {
+			where += " (" + r.Name + ")"
+		} else {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		switch strings.ToLower(r.Action) {
+		case ActionFail:
+			r.Action = ActionFail
+			if r.ExitCode == 0 {
+				r.ExitCode = DefaultPolicyExitCode
+			}
+			if use, ok := reservedExitCodes[r.ExitCode]; ok {
+				errs = append(errs, fmt.Errorf("%s: exit_code %d は%s用に予約されています。 1 か 5〜125 で指定してください", where, r.ExitCode, use))
+			} else if r.ExitCode < 1 || r.ExitCode > 125 {
+				errs = append(errs, fmt.Errorf("%s: exit_code は 1 か 5〜125 で指定してください (指定値: %d)", where, r.ExitCode))
+			}
+		case ActionWarn:
+			r.Action = ActionWarn
+			if r.ExitCode != 0 {
+				errs = append(errs, fmt.Errorf("%s: warn ルールに exit_code は指定できません", where))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s: action は fail / warn のいずれかです (指定値: %q)", where, r.Action))
+		}
+		if err := r.When.compile(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &p, nil
+}
+
+// compile validates the condition tree and resolves severity names.
+func (c *Condition) compile() error {
+	empty := true
+	if c.SeverityGTE != "" {
+		empty = false
+		c.severityLevel = Parse(c.SeverityGTE)
+		if c.severityLevel == LevelNone || c.severityLevel == LevelKEV {
+			return fmt.Errorf("severity_gte の値が不正です: %q (critical/high/medium/low)。 KEV は kev: true で指定してください", c.SeverityGTE)
+		}
+	}
+	if c.CVSSGTE != nil {
+		empty = false
+		if *c.CVSSGTE < 0 || *c.CVSSGTE > 10 {
+			return fmt.Errorf("cvss_gte は 0〜10 で指定してください (指定値: %g)", *c.CVSSGTE)
+		}
+	}
+	if c.KEV != nil || c.FixAvailable != nil || len(c.Scope) > 0 {
+		empty = false
+	}
+	for i := range c.Any {
+		empty = false
+		if err := c.Any[i].compile(); err != nil {
+			return err
+		}
+	}
+	for i := range c.All {
+		empty = false
+		if err := c.All[i].compile(); err != nil {
+			return err
+		}
+	}
+	if empty {
+		// An empty `when` would match every finding — almost certainly
+		// an indentation mistake rather than intent.
+		return errors.New("when に条件がありません")
+	}
+	return nil
+}
+
+// Matches reports whether the finding satisfies every predicate set on c.
+func (c Condition) Matches(f Finding) bool {
+	if c.SeverityGTE != "" && f.Severity < c.severityLevel {
+		return false
+	}
+	if c.CVSSGTE != nil && (!f.HasCVSS || f.CVSSScore < *c.CVSSGTE) {
+		return false
+	}
+	if c.KEV != nil && (!f.HasKEV || f.InKEV != *c.KEV) {
+		return false
+	}
+	if c.FixAvailable != nil && (!f.HasFix || f.FixAvailable != *c.FixAvailable) {
+		return false
+	}
+	if len(c.Scope) > 0 {
+		if f.Scope == "" {
+			return false
+		}
+		ok := false
+		for _, s := range c.Scope {
+			if strings.EqualFold(s, f.Scope) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(c.Any) > 0 {
+		ok := false
+		for _, sub := range c.Any {
+			if sub.Matches(f) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	for _, sub := range c.All {
+		if !sub.Matches(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate runs every rule against the findings.
+func (p *Policy) Evaluate(findings []Finding) Outcome {
+	out := Outcome{Failed: []RuleHit{}, Warned: []RuleHit{}}
+	if p == nil {
+		return out
+	}
+	for _, r := range p.Rules {
+		hit := RuleHit{Rule: r.Name, Action: r.Action, ExitCode: r.ExitCode}
+		for _, f := range findings {
+			if r.When.Matches(f) {
+				label := f.ID
+				if f.Package != "" {
+					label += " (" + f.Package + "@" + f.Version + ")"
+				}
+				hit.Findings = append(hit.Findings, label)
+			}
+		}
+		if len(hit.Findings) == 0 {
+			continue
+		}
+		if r.Action == ActionFail {
+			if out.ExitCode == 0 {
+				out.ExitCode = r.ExitCode
+			}
+			out.Failed = append(out.Failed, hit)
+		} else {
+			out.Warned = append(out.Warned, hit)
+		}
+	}
+	return out
+}
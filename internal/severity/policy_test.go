@@ -0,0 +1,157 @@
+package severity
+
+import (
+	"strings"
+	"testing"
+)
+
+// examplePolicy is the rule set from the policy.go package comment:
+// "fail if KEV, or CVSS >= 9 with a fix available; warn on High".
+const examplePolicy = `
+rules:
+  - name: kev
+    when: {kev: true}
+    action: fail
+    exit_code: 10
+  - name: critical-with-fix
+    when: {cvss_gte: 9, fix_available: true}
+    action: fail
+  - name: high
+    when: {severity_gte: high}
+    action: warn
+`
+
+func TestParsePolicy_Defaults(t *testing.T) {
+	p, err := ParsePolicy([]byte(examplePolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	if len(p.Rules) != 3 {
+		t.Fatalf("len(Rules) = %d, want 3", len(p.Rules))
+	}
+	if p.Rules[1].ExitCode != DefaultPolicyExitCode {
+		t.Errorf("fail rule without exit_code = %d, want %d", p.Rules[1].ExitCode, DefaultPolicyExitCode)
+	}
+}
+
+func TestParsePolicy_RejectsInvalidRules(t *testing.T) {
+	cases := map[string]string{
+		"no rules":        `rules: []`,
+		"bad action":      `rules: [{when: {kev: true}, action: block}]`,
+		"warn exit code":  `rules: [{when: {kev: true}, action: warn, exit_code: 3}]`,
+		"exit code 0..":   `rules: [{when: {kev: true}, action: fail, exit_code: 200}]`,
+		"exit code 130":   `rules: [{when: {kev: true}, action: fail, exit_code: 130}]`,
+		"empty when":      `rules: [{action: fail}]`,
+		"severity kev":    `rules: [{when: {severity_gte: kev}, action: fail}]`,
+		"cvss range":      `rules: [{when: {cvss_gte: 11}, action: fail}]`,
+		"unknown field":   `rules: [{when: {cvss_over: 9}, action: fail}]`,
+		"bad nested cond": `rules: [{when: {any: [{severity_gte: bogus}]}, action: fail}]`,
+	}
+	for name, src := range cases {
+		if _, err := ParsePolicy([]byte(src)); err == nil {
+			t.Errorf("%s: ParsePolicy() = nil error, want rejection", name)
+		}
+	}
+}
+
+// TestParsePolicy_RejectsReservedExitCodes verifies that a fail rule
+// cannot return a code the shared table gives to a scan timeout or an API
+// error, and that the error says why.
+func TestParsePolicy_RejectsReservedExitCodes(t *testing.T) {
+	for _, code := range []string{"2", "3", "4"} {
+		_, err := ParsePolicy([]byte(`rules: [{when: {kev: true}, action: fail, exit_code: ` + code + `}]`))
+		if err == nil || !strings.Contains(err.Error(), "予約") {
+			t.Errorf("exit_code %s: ParsePolicy() = %v, want a reserved-code error", code, err)
+		}
+	}
+	for _, code := range []string{"1", "5", "125"} {
+		if _, err := ParsePolicy([]byte(`rules: [{when: {kev: true}, action: fail, exit_code: ` + code + `}]`)); err != nil {
+			t.Errorf("exit_code %s: ParsePolicy() = %v, want it accepted", code, err)
+		}
+	}
+}
+
+func TestPolicyEvaluate_Example(t *testing.T) {
+	p, err := ParsePolicy([]byte(examplePolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+
+	// CVSS 9.8 with a fix → fail (exit 1). High without KEV → warn.
+	findings := []Finding{
+		{ID: "CVE-A", Severity: LevelCritical, CVSSScore: 9.8, HasCVSS: true, FixAvailable: true, HasFix: true},
+		{ID: "CVE-B", Severity: LevelHigh, CVSSScore: 7.5, HasCVSS: true, HasKEV: true},
+	}
+	o := p.Evaluate(findings)
+	if !o.HasFailure() || o.ExitCode != 1 {
+		t.Fatalf("outcome = %+v, want failure with exit 1", o)
+	}
+	if len(o.Failed) != 1 || o.Failed[0].Rule != "critical-with-fix" {
+		t.Errorf("Failed = %+v, want only critical-with-fix", o.Failed)
+	}
+	if len(o.Warned) != 1 || len(o.Warned[0].Findings) != 2 {
+		t.Errorf("Warned = %+v, want the high rule matching both findings", o.Warned)
+	}
+
+	// Adding a KEV finding makes the first rule in file order decide the
+	// exit code.
+	findings = append(findings, Finding{ID: "CVE-C", Severity: LevelMedium, InKEV: true, HasKEV: true})
+	if o := p.Evaluate(findings); o.ExitCode != 10 {
+		t.Errorf("ExitCode = %d, want 10 from the kev rule", o.ExitCode)
+	}
+}
+
+func TestConditionMatches_MissingDataNeverMatches(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+rules:
+  - {name: fix, when: {fix_available: false}, action: fail}
+  - {name: cvss, when: {cvss_gte: 0}, action: fail}
+  - {name: scope, when: {scope: [required]}, action: fail}
+  - {name: kev, when: {kev: false}, action: fail}
+`))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	// A finding from a source with no fix / CVSS / scope / KEV data must
+	// not trip rules on those fields, even "negative" ones.
+	o := p.Evaluate([]Finding{{ID: "CVE-X", Severity: LevelCritical}})
+	if o.HasFailure() {
+		t.Errorf("outcome = %+v, want no failures on missing data", o)
+	}
+}
+
+func TestConditionMatches_AnyAllScope(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+rules:
+  - name: prod-critical-or-kev
+    when:
+      scope: [required]
+      any:
+        - {severity_gte: critical}
+        - {kev: true}
+    action: fail
+    exit_code: 11
+`))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	cases := []struct {
+		f    Finding
+		want bool
+	}{
+		{Finding{ID: "1", Severity: LevelCritical, Scope: "required"}, true},
+		{Finding{ID: "2", Severity: LevelLow, InKEV: true, HasKEV: true, Scope: "REQUIRED"}, true},
+		{Finding{ID: "3", Severity: LevelCritical, Scope: "optional"}, false},
+		{Finding{ID: "4", Severity: LevelHigh, Scope: "required"}, false},
+	}
+	for _, tc := range cases {
+		got := p.Rules[0].When.Matches(tc.f)
+		if got != tc.want {
+			t.Errorf("finding %s: Matches() = %v, want %v", tc.f.ID, got, tc.want)
+		}
+	}
+	o := p.Evaluate([]Finding{cases[0].f})
+	if o.ExitCode != 11 || !strings.Contains(o.Failed[0].Findings[0], "1") {
+		t.Errorf("outcome = %+v, want exit 11 naming finding 1", o)
+	}
+}
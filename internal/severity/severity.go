@@ -1,6 +1,9 @@
 // Package severity provides ordered severity comparison used by both
 // `sbomhub scan --fail-on` and `sbomhub check --fail-on`. Centralising
 // the comparison means both commands enforce thresholds identically.
+//
+// policy.go layers a YAML rule set (`--policy`) on top for gates that a
+// single threshold cannot express; see the comment there.
 package severity
 
 import "strings"
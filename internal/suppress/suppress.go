@@ -0,0 +1,337 @@
+// Package suppress implements the `.sbomhubignore` suppression file used
+// by `sbomhub scan` and `sbomhub check` to accept specific risks for a
+// bounded period without disabling --fail-on altogether.
+//
+// File format (YAML):
+//
+//	version: 1
+//	expired: warn            # warn (default) | fail
+//	suppressions:
+//	  - id: CVE-2024-12345
+//	    purl: pkg:npm/lodash*  # optional glob (`*` / `?`)
+//	    version: "4.17.*"      # optional glob
+//	    reason: "not reachable: only used in build tooling"
+//	    owner: security@example.com
+//	    expires: 2026-12-31
+//
+// Every entry must carry a reason, an owner and an expiry date: a
+// suppression without an owner is nobody's problem, and one without an
+// expiry quietly becomes permanent. Entries past their expiry are never
+// applied; the `expired` policy only decides whether a stale entry that
+// still matches a live finding is a warning or a gate failure.
+package suppress
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFileName is the file discovered automatically when no explicit
+// path is given.
+const DefaultFileName = ".sbomhubignore"
+
+// Expired-entry policies.
+const (
+	ExpiredWarn = "warn"
+	ExpiredFail = "fail"
+)
+
+// expiresLayout is the only accepted expiry format. A bare date is what
+// humans write in review; the entry stays valid through the end of that
+// day (UTC).
+const expiresLayout = "2006-01-02"
+
+// File is a parsed suppression file.
+type File struct {
+	Version      int     `yaml:"version"`
+	Expired      string  `yaml:"expired"`
+	Suppressions []Entry `yaml:"suppressions"`
+
+	// Path is the file the entries were loaded from, for messages.
+	Path string `yaml:"-"`
+}
+
+// Entry is a single accepted risk.
+type Entry struct {
+	ID      string `yaml:"id" json:"id"`
+	Purl    string `yaml:"purl,omitempty" json:"purl,omitempty"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	Reason  string `yaml:"reason" json:"reason"`
+	Owner   string `yaml:"owner" json:"owner"`
+	Expires string `yaml:"expires" json:"expires"`
+
+	expiresAt time.Time
+	purlRe    *regexp.Regexp
+	versionRe *regexp.Regexp
+}
+
+// Finding is the minimal view of a vulnerability finding the matcher
+// needs. Package / Version / Purl may be empty when the data source does
+// not carry component information (see HasComponentScope).
+type Finding struct {
+	ID       string
+	Aliases  []string
+	Package  string
+	Version  string
+	Purl     string
+	Severity string
+	InKEV    bool
+}
+
+// Suppressed pairs a finding with the entry that suppressed it.
+type Suppressed struct {
+	Finding Finding
+	Entry   Entry
+}
+
+// Result is the outcome of Apply.
+type Result struct {
+	// Kept are findings that still count towards the gate.
+	Kept []Finding
+	// Suppressed are findings removed by a live entry.
+	Suppressed []Suppressed
+	// Expired lists every expired entry in the file, so stale entries
+	// surface even when the finding they covered has since been fixed.
+	Expired []Entry
+	// ExpiredMatched lists expired entries that would still match a
+	// live finding — the case the `expired: fail` policy gates on.
+	ExpiredMatched []Entry
+	// Unevaluated lists live entries that were skipped because they
+	// constrain purl/version but the findings carry no component data.
+	Unevaluated []Entry
+}
+
+// Load reads and validates a suppression file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f.Path = path
+	return f, nil
+}
+
+// Parse decodes and validates suppression file content. All validation
+// errors are collected so an operator fixing a file sees every problem
+// in one run rather than one per CI round-trip.
+func Parse(data []byte) (*File, error) {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("YAML の解析に失敗しました: %w", err)
+	}
+	if f.Version != 0 && f.Version != 1 {
+		return nil, fmt.Errorf("サポートされていない version: %d (1 のみ対応)", f.Version)
+	}
+	switch strings.ToLower(strings.TrimSpace(f.Expired)) {
+	case "", ExpiredWarn:
+		f.Expired = ExpiredWarn
+	case ExpiredFail:
+		f.Expired = ExpiredFail
+	default:
+		return nil, fmt.Errorf("expired の値が不正です: %q (warn/fail)", f.Expired)
+	}
+
+	var errs []error
+	for i := range f.Suppressions {
+		e := &f.Suppressions[i]
+		where := fmt.Sprintf("suppressions[%d]", i)
+		if e.ID != "" {
+			where += " (" + e.ID + ")"
+		}
+		if strings.TrimSpace(e.ID) == "" {
+			errs = append(errs, fmt.Errorf("%s: id は必須です", where))
+		}
+		if strings.TrimSpace(e.Reason) == "" {
+			errs = append(errs, fmt.Errorf("%s: reason は必須です", where))
+		}
+		if strings.TrimSpace(e.Owner) == "" {
+			errs = append(errs, fmt.Errorf("%s: owner は必須です", where))
+		}
+		if strings.TrimSpace(e.Expires) == "" {
+			errs = append(errs, fmt.Errorf("%s: expires は必須です (YYYY-MM-DD)", where))
+		} else if t, err := time.Parse(expiresLayout, strings.TrimSpace(e.Expires)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: expires の形式が不正です: %q (YYYY-MM-DD)", where, e.Expires))
+		} else {
+			// Valid through the end of the stated day.
+			e.expiresAt = t.Add(24 * time.Hour)
+		}
+		if e.Purl != "" {
+			e.purlRe = globToRegexp(e.Purl)
+		}
+		if e.Version != "" {
+			e.versionRe = globToRegexp(e.Version)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &f, nil
+}
+
+// Discover returns the first DefaultFileName found in dirs, or "" when
+// none exists. Callers typically pass the working directory and the scan
+// target so both `sbomhub check ./sbom.json` from the repo root and
+// `sbomhub scan ./service` pick up the file that lives next to the code.
+func Discover(dirs ...string) string {
+	for _, d := range dirs {
+		if d == "" {
+			continue
+		}
+		p := filepath.Join(d, DefaultFileName)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// IsExpired reports whether the entry is past its expiry at now.
+func (e Entry) IsExpired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// HasComponentScope reports whether the entry narrows the match to a
+// purl and/or version.
+func (e Entry) HasComponentScope() bool {
+	return e.Purl != "" || e.Version != ""
+}
+
+// Matches reports whether the entry covers the finding. The ID matches
+// the finding's ID or any alias (so a GHSA-keyed finding is covered by
+// its CVE entry and vice versa), case-insensitively.
+func (e Entry) Matches(f Finding) bool {
+	if !idMatches(e.ID, f) {
+		return false
+	}
+	if e.purlRe != nil && !purlMatches(e.purlRe, e.Purl, f.Purl) {
+		return false
+	}
+	if e.versionRe != nil && !e.versionRe.MatchString(f.Version) {
+		return false
+	}
+	return true
+}
+
+// Apply partitions findings into kept and suppressed at time now.
+//
+// componentData says whether findings carry package/version/purl. When
+// false (the project-level vulnerability rows `scan` works from), entries
+// with a purl/version scope cannot be evaluated safely — matching them
+// on ID alone would widen an intentionally narrow acceptance — so they
+// are skipped and reported in Result.Unevaluated instead.
+func (f *File) Apply(findings []Finding, now time.Time, componentData bool) Result {
+	var res Result
+	if f == nil {
+		res.Kept = findings
+		return res
+	}
+
+	var live []Entry
+	var expired []Entry
+	for _, e := range f.Suppressions {
+		switch {
+		case e.IsExpired(now):
+			expired = append(expired, e)
+		case !componentData && e.HasComponentScope():
+			res.Unevaluated = append(res.Unevaluated, e)
+		default:
+			live = append(live, e)
+		}
+	}
+	res.Expired = expired
+
+	expiredHit := make(map[int]bool)
+	for _, fd := range findings {
+		var by *Entry
+		for i := range live {
+			if live[i].Matches(fd) {
+				by = &live[i]
+				break
+			}
+		}
+		if by != nil {
+			res.Suppressed = append(res.Suppressed, Suppressed{Finding: fd, Entry: *by})
+			continue
+		}
+		res.Kept = append(res.Kept, fd)
+		for i, e := range expired {
+			if !componentData && e.HasComponentScope() {
+				continue
+			}
+			if e.Matches(fd) {
+				expiredHit[i] = true
+			}
+		}
+	}
+	for i, e := range expired {
+		if expiredHit[i] {
+			res.ExpiredMatched = append(res.ExpiredMatched, e)
+		}
+	}
+	return res
+}
+
+// FailOnExpired reports whether the result violates the file's expired
+// policy.
+func (f *File) FailOnExpired(r Result) bool {
+	return f != nil && f.Expired == ExpiredFail && len(r.ExpiredMatched) > 0
+}
+
+func idMatches(id string, f Finding) bool {
+	if strings.EqualFold(id, f.ID) {
+		return true
+	}
+	for _, a := range f.Aliases {
+		if strings.EqualFold(id, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// purlMatches matches a purl glob. A pattern without an `@` is compared
+// against the purl with its version (and qualifiers) stripped, so
+// `pkg:npm/lodash` covers every lodash version without the author
+// having to remember the trailing `@*`.
+func purlMatches(re *regexp.Regexp, pattern, purl string) bool {
+	if purl == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "@") {
+		if i := strings.IndexAny(purl, "@?#"); i >= 0 {
+			purl = purl[:i]
+		}
+	}
+	return re.MatchString(purl)
+}
+
+// globToRegexp converts a `*` / `?` glob into an anchored regexp. `*`
+// crosses `/` on purpose: purl namespaces (`pkg:maven/org.apache/...`)
+// would otherwise need one wildcard per path segment.
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
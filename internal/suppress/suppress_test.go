@@ -0,0 +1,164 @@
+package suppress
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func mustParse(t *testing.T, src string) *File {
+	t.Helper()
+	f, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return f
+}
+
+func TestParse_RequiresReasonOwnerExpiry(t *testing.T) {
+	_, err := Parse([]byte(`
+suppressions:
+  - id: CVE-2024-0001
+  - reason: r
+    owner: o
+    expires: 31/12/2026
+`))
+	if err == nil {
+		t.Fatal("Parse() = nil error, want validation failure")
+	}
+	msg := err.Error()
+	// Every problem is reported in one pass.
+	for _, want := range []string{"reason は必須", "owner は必須", "expires は必須", "id は必須", "expires の形式が不正"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestParse_ExpiredPolicy(t *testing.T) {
+	if f := mustParse(t, "suppressions: []"); f.Expired != ExpiredWarn {
+		t.Errorf("default Expired = %q, want %q", f.Expired, ExpiredWarn)
+	}
+	if f := mustParse(t, "expired: FAIL"); f.Expired != ExpiredFail {
+		t.Errorf("Expired = %q, want %q", f.Expired, ExpiredFail)
+	}
+	if _, err := Parse([]byte("expired: ignore")); err == nil {
+		t.Error("Parse(expired: ignore) = nil error, want rejection")
+	}
+	if _, err := Parse([]byte("version: 2")); err == nil {
+		t.Error("Parse(version: 2) = nil error, want rejection")
+	}
+}
+
+func TestEntry_IsExpiredInclusiveOfExpiryDay(t *testing.T) {
+	f := mustParse(t, `
+suppressions:
+  - {id: CVE-1, reason: r, owner: o, expires: 2026-06-01}
+`)
+	e := f.Suppressions[0]
+	if e.IsExpired(now) {
+		t.Error("entry expiring today must still be live")
+	}
+	if !e.IsExpired(now.Add(24 * time.Hour)) {
+		t.Error("entry must be expired the day after its expiry date")
+	}
+}
+
+func TestEntry_Matches(t *testing.T) {
+	f := mustParse(t, `
+suppressions:
+  - {id: CVE-1, reason: r, owner: o, expires: 2999-01-01}
+  - {id: cve-2, purl: "pkg:npm/lodash", reason: r, owner: o, expires: 2999-01-01}
+  - {id: CVE-3, purl: "pkg:maven/org.apache.*", version: "2.14.*", reason: r, owner: o, expires: 2999-01-01}
+`)
+	cases := []struct {
+		name  string
+		entry int
+		f     Finding
+		want  bool
+	}{
+		{"id only", 0, Finding{ID: "CVE-1"}, true},
+		{"alias", 0, Finding{ID: "GHSA-xxxx", Aliases: []string{"CVE-1"}}, true},
+		{"other id", 0, Finding{ID: "CVE-9"}, false},
+		{"case-insensitive id, versionless purl", 1, Finding{ID: "CVE-2", Purl: "pkg:npm/lodash@4.17.20"}, true},
+		{"purl mismatch", 1, Finding{ID: "CVE-2", Purl: "pkg:npm/lodash-es@4.17.20"}, false},
+		{"purl required but missing", 1, Finding{ID: "CVE-2"}, false},
+		{"glob purl + version", 2, Finding{ID: "CVE-3", Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", Version: "2.14.1"}, true},
+		{"version mismatch", 2, Finding{ID: "CVE-3", Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.15.0", Version: "2.15.0"}, false},
+	}
+	for _, tc := range cases {
+		if got := f.Suppressions[tc.entry].Matches(tc.f); got != tc.want {
+			t.Errorf("%s: Matches() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestApply_PartitionsAndReportsExpired(t *testing.T) {
+	f := mustParse(t, `
+expired: fail
+suppressions:
+  - {id: CVE-LIVE, reason: r, owner: o, expires: 2999-01-01}
+  - {id: CVE-OLD, reason: r, owner: o, expires: 2020-01-01}
+  - {id: CVE-GONE, reason: r, owner: o, expires: 2020-01-01}
+  - {id: CVE-SCOPED, purl: "pkg:npm/x", reason: r, owner: o, expires: 2999-01-01}
+`)
+	findings := []Finding{
+		{ID: "CVE-LIVE", Severity: "HIGH"},
+		{ID: "CVE-OLD", Severity: "CRITICAL"},
+		{ID: "CVE-SCOPED", Severity: "LOW"},
+	}
+
+	res := f.Apply(findings, now, false)
+
+	if len(res.Suppressed) != 1 || res.Suppressed[0].Finding.ID != "CVE-LIVE" {
+		t.Errorf("Suppressed = %+v, want only CVE-LIVE", res.Suppressed)
+	}
+	if len(res.Kept) != 2 {
+		t.Errorf("Kept = %+v, want CVE-OLD and CVE-SCOPED", res.Kept)
+	}
+	if len(res.Expired) != 2 {
+		t.Errorf("Expired = %+v, want both expired entries", res.Expired)
+	}
+	if len(res.ExpiredMatched) != 1 || res.ExpiredMatched[0].ID != "CVE-OLD" {
+		t.Errorf("ExpiredMatched = %+v, want only CVE-OLD (CVE-GONE has no live finding)", res.ExpiredMatched)
+	}
+	if len(res.Unevaluated) != 1 || res.Unevaluated[0].ID != "CVE-SCOPED" {
+		t.Errorf("Unevaluated = %+v, want CVE-SCOPED (no component data)", res.Unevaluated)
+	}
+	if !f.FailOnExpired(res) {
+		t.Error("FailOnExpired() = false, want true under expired: fail")
+	}
+}
+
+func TestApply_NilFileKeepsEverything(t *testing.T) {
+	var f *File
+	res := f.Apply([]Finding{{ID: "CVE-1"}}, now, true)
+	if len(res.Kept) != 1 || len(res.Suppressed) != 0 {
+		t.Errorf("Apply on nil file = %+v, want all findings kept", res)
+	}
+}
+
+func TestDiscoverAndLoad(t *testing.T) {
+	empty, withFile := t.TempDir(), t.TempDir()
+	path := filepath.Join(withFile, DefaultFileName)
+	if err := os.WriteFile(path, []byte("suppressions: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Discover(empty, withFile); got != path {
+		t.Fatalf("Discover() = %q, want %q", got, path)
+	}
+	if got := Discover(empty); got != "" {
+		t.Errorf("Discover(empty) = %q, want \"\"", got)
+	}
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if f.Path != path {
+		t.Errorf("Path = %q, want %q", f.Path, path)
+	}
+}
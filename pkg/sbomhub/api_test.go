@@ -0,0 +1,137 @@
+package sbomhub
+
+import (
+	"flag"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var updateAPI = flag.Bool("update", false, "rewrite testdata/api.golden from the current package")
+
+// TestPublicAPI pins the package's exported surface to testdata/api.golden.
+// The DTOs are aliases of internal/api types, so a field renamed there
+// changes this package without touching a file in it; the golden file
+// spells out every exported field and method, wherever it is declared, so
+// such a change fails here until the golden file is regenerated (go test
+// ./pkg/sbomhub -run TestPublicAPI -update) and the diff reviewed.
+func TestPublicAPI(t *testing.T) {
+	got := publicAPI(t)
+	golden := filepath.Join("testdata", "api.golden")
+	if *updateAPI {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got == string(want) {
+		return
+	}
+	gotLines, wantLines := lineSet(got), lineSet(string(want))
+	for _, l := range strings.Split(string(want), "\n") {
+		if !gotLines[l] {
+			t.Errorf("removed or changed: %s", l)
+		}
+	}
+	for _, l := range strings.Split(got, "\n") {
+		if !wantLines[l] {
+			t.Errorf("added: %s", l)
+		}
+	}
+	t.Error("the exported API changed; if intended, rerun with -update and note it in the release notes")
+}
+
+func lineSet(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, l := range strings.Split(s, "\n") {
+		m[l] = true
+	}
+	return m
+}
+
+// publicAPI renders one line per exported identifier, struct field and
+// method of the package, sorted.
+func publicAPI(t *testing.T) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp := importer.ForCompiler(token.NewFileSet(), "source", nil).(types.ImporterFrom)
+	pkg, err := imp.ImportFrom("github.com/youichi-uda/sbomhub-cli/pkg/sbomhub", dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Types reached through the aliases print unqualified, as the SDK's
+	// users name them; anything else keeps its package name.
+	qualify := func(p *types.Package) string {
+		if p == pkg || strings.HasSuffix(p.Path(), "/internal/api") {
+			return ""
+		}
+		return p.Name()
+	}
+
+	var lines []string
+	add := func(format string, args ...interface{}) { lines = append(lines, fmt.Sprintf(format, args...)) }
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Const:
+			add("const %s %s = %s", name, types.TypeString(obj.Type(), qualify), obj.Val().ExactString())
+		case *types.Var:
+			add("var %s %s", name, types.TypeString(obj.Type(), qualify))
+		case *types.Func:
+			add("func %s%s", name, strings.TrimPrefix(types.TypeString(obj.Type(), qualify), "func"))
+		case *types.TypeName:
+			describeType(name, obj.Type(), qualify, add)
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func describeType(name string, typ types.Type, qualify types.Qualifier, add func(string, ...interface{})) {
+	switch u := typ.Underlying().(type) {
+	case *types.Struct:
+		add("type %s struct", name)
+		for i := 0; i < u.NumFields(); i++ {
+			if f := u.Field(i); f.Exported() {
+				field := f.Name() + " " + types.TypeString(f.Type(), qualify)
+				if tag := u.Tag(i); tag != "" {
+					field += " `" + tag + "`"
+				}
+				add("type %s struct, %s", name, field)
+			}
+		}
+	case *types.Interface:
+		add("type %s interface", name)
+		for i := 0; i < u.NumMethods(); i++ {
+			if m := u.Method(i); m.Exported() {
+				add("type %s interface, %s%s", name, m.Name(), strings.TrimPrefix(types.TypeString(m.Type(), qualify), "func"))
+			}
+		}
+		return
+	default:
+		add("type %s %s", name, types.TypeString(u, qualify))
+	}
+	mset := types.NewMethodSet(types.NewPointer(typ))
+	for i := 0; i < mset.Len(); i++ {
+		if m := mset.At(i).Obj(); m.Exported() {
+			add("method (%s) %s%s", name, m.Name(), strings.TrimPrefix(types.TypeString(m.Type(), qualify), "func"))
+		}
+	}
+}
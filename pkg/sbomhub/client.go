@@ -0,0 +1,327 @@
+package sbomhub
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// DefaultUserAgent is sent when no WithUserAgent option is given, so
+// server logs can tell SDK traffic from the CLI (which identifies itself
+// as sbomhub-cli/<version>).
+const DefaultUserAgent = "sbomhub-go"
+
+// RetryPolicy bounds the automatic retries applied to 429 / 5xx /
+// network failures. Against a server that declares
+// FeatureIdempotencyKey (see SetCapabilities), uploads and other writes
+// are retried with the same Idempotency-Key; otherwise non-idempotent
+// POSTs are only retried when the request provably never reached the
+// server.
+type RetryPolicy = api.RetryPolicy
+
+var (
+	// DefaultRetryPolicy is what NewClient uses: a few attempts with
+	// jittered exponential backoff that honours Retry-After.
+	DefaultRetryPolicy = api.DefaultRetryPolicy
+	// NoRetry makes every call a single attempt.
+	NoRetry = api.NoRetry
+)
+
+// TransportOptions describes the TLS and proxy settings NewTransport
+// builds an *http.Transport from (internal CA bundle, mTLS pair, proxy,
+// NO_PROXY).
+type TransportOptions = api.TransportOptions
+
+// NewTransport builds an *http.Transport for o, for use with
+// WithTransport.
+func NewTransport(o TransportOptions) (*http.Transport, error) {
+	return api.NewTransport(o)
+}
+
+// Tracer records every request / response exchange with credentials
+// redacted, as text and optionally as a HAR file.
+type Tracer = api.Tracer
+
+// NewTracer returns a Tracer for WithTracer; see Tracer for the formats.
+var NewTracer = api.NewTracer
+
+// Option configures a Client in NewClient. Options are applied in order,
+// so WithTransport after WithHTTPClient replaces the transport of the
+// supplied client (without modifying the caller's *http.Client).
+type Option func(*Client)
+
+// WithHTTPClient makes the client send requests through hc instead of a
+// fresh *http.Client with a 60 second timeout. hc is copied, not shared.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.c.SetHTTPClient(hc) }
+}
+
+// WithTransport replaces the round tripper, e.g. with one from
+// NewTransport or an instrumented wrapper.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.c.SetTransport(rt) }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.c.SetRetryPolicy(p) }
+}
+
+// WithUserAgent sets the User-Agent header, replacing DefaultUserAgent.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.c.SetUserAgent(ua) }
+}
+
+// WithTracer records every exchange to t.
+func WithTracer(t *Tracer) Option {
+	return func(c *Client) { c.c.SetTracer(t) }
+}
+
+// Client talks to one SBOMHub server with one API key. It is safe for
+// concurrent use once constructed.
+type Client struct {
+	c *api.Client
+}
+
+// NewClient returns a client for the server at baseURL (scheme and host,
+// no /api/v1 suffix) authenticating with apiKey.
+func NewClient(baseURL, apiKey string, opts ...Option) *Client {
+	c := &Client{c: api.NewClient(baseURL, apiKey)}
+	c.c.SetRetryPolicy(DefaultRetryPolicy)
+	c.c.SetUserAgent(DefaultUserAgent)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GetCapabilities fetches the server's version and feature list. A
+// server that predates the endpoint yields Published=false and no error.
+func (c *Client) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	return c.c.GetCapabilities(ctx)
+}
+
+// SetCapabilities hands the client a document from GetCapabilities so it
+// can use the opt-in features the server declares (Capabilities.Declares),
+// such as keyed retries of uploads. Without it those stay off.
+func (c *Client) SetCapabilities(caps *Capabilities) {
+	c.c.SetCapabilities(caps)
+}
+
+// WhoAmI describes the credential in use: tenant, user, key, role,
+// scopes and expiry. A server that predates the endpoint yields
+// Published=false and no error.
+func (c *Client) WhoAmI(ctx context.Context) (*Identity, error) {
+	return c.c.WhoAmI(ctx)
+}
+
+// Health returns the server health, including the configured LLM
+// provider where the server publishes it.
+func (c *Client) Health(ctx context.Context) (*LLMHealthResponse, error) {
+	return c.c.Health(ctx)
+}
+
+// ListProjects returns every project visible to the API key.
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	return c.c.ListProjects(ctx)
+}
+
+// GetProject fetches one project by ID.
+func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
+	return c.c.GetProject(ctx, id)
+}
+
+// CreateProject creates name, or returns the existing project of that
+// name; created reports which happened.
+func (c *Client) CreateProject(ctx context.Context, name, description string) (project *Project, created bool, err error) {
+	return c.c.CreateProject(ctx, name, description)
+}
+
+// ResolveProjectID returns the ID of the project ref names: a UUID as
+// is, otherwise the one project whose name is exactly ref. A reference
+// that matches no project or several returns *ProjectRefError.
+func (c *Client) ResolveProjectID(ctx context.Context, ref string) (string, error) {
+	return c.c.ResolveProjectID(ctx, ref)
+}
+
+// FindProject picks the project ref names out of an already fetched
+// list, with the matching rules of ResolveProjectID.
+func FindProject(projects []Project, ref string) (*Project, error) {
+	return api.FindProject(projects, ref)
+}
+
+// IsProjectID reports whether ref is a project ID (a UUID) rather than a
+// name.
+func IsProjectID(ref string) bool {
+	return api.IsProjectID(ref)
+}
+
+// BaseURL returns the API URL the client talks to.
+func (c *Client) BaseURL() string {
+	return c.c.BaseURL()
+}
+
+// UpdateProject changes a project's name and/or description; nil fields
+// of req are left as they are.
+func (c *Client) UpdateProject(ctx context.Context, id string, req UpdateProjectRequest) (*Project, error) {
+	return c.c.UpdateProject(ctx, id, req)
+}
+
+// ArchiveProject archives a project: still readable, but closed to new
+// SBOMs.
+func (c *Client) ArchiveProject(ctx context.Context, id string) (*Project, error) {
+	return c.c.ArchiveProject(ctx, id)
+}
+
+// UnarchiveProject reverses ArchiveProject.
+func (c *Client) UnarchiveProject(ctx context.Context, id string) (*Project, error) {
+	return c.c.UnarchiveProject(ctx, id)
+}
+
+// DeleteProject permanently deletes a project and everything in it.
+func (c *Client) DeleteProject(ctx context.Context, id string) error {
+	return c.c.DeleteProject(ctx, id)
+}
+
+// UploadSBOM uploads an in-memory SBOM to projectRef (a project name,
+// or an ID when allowAsID is set). format may be empty to let the server
+// detect it.
+func (c *Client) UploadSBOM(ctx context.Context, projectRef string, allowAsID bool, sbomData []byte, format string) (*UploadResult, error) {
+	return c.c.UploadSBOM(ctx, projectRef, allowAsID, sbomData, format)
+}
+
+// UploadSBOMFrom is UploadSBOM for a streamed source (see SBOMFile),
+// with gzip and size limits controlled by opts.
+func (c *Client) UploadSBOMFrom(ctx context.Context, projectRef string, allowAsID bool, src SBOMSource, format string, opts UploadOptions) (*UploadResult, error) {
+	return c.c.UploadSBOMFrom(ctx, projectRef, allowAsID, src, format, opts)
+}
+
+// GetScanStatus reports the vulnerability scan state of an uploaded SBOM.
+func (c *Client) GetScanStatus(ctx context.Context, projectID, sbomID string) (*ScanStatusResponse, error) {
+	return c.c.GetScanStatus(ctx, projectID, sbomID)
+}
+
+// ListSBOMs returns every stored SBOM of the project, newest first. Use
+// SBOMs to page lazily instead.
+func (c *Client) ListSBOMs(ctx context.Context, projectID string) ([]SBOM, error) {
+	return c.c.ListSBOMs(ctx, projectID)
+}
+
+// DownloadSBOM streams a stored SBOM document to w exactly as it was
+// uploaded and returns its size. A failure after the first byte reached
+// w is not retried, so w may then hold a partial document.
+func (c *Client) DownloadSBOM(ctx context.Context, projectID, sbomID string, w io.Writer) (int64, error) {
+	return c.c.DownloadSBOM(ctx, projectID, sbomID, w)
+}
+
+// DeleteSBOM permanently deletes a stored SBOM.
+func (c *Client) DeleteSBOM(ctx context.Context, projectID, sbomID string) error {
+	return c.c.DeleteSBOM(ctx, projectID, sbomID)
+}
+
+// RescanSBOM rescans a stored SBOM against current advisory data; poll
+// GetScanStatus for the result.
+func (c *Client) RescanSBOM(ctx context.Context, projectID, sbomID string) (*ScanStatusResponse, error) {
+	return c.c.RescanSBOM(ctx, projectID, sbomID)
+}
+
+// CheckVulnerabilities matches the SBOM's components against the
+// server's advisory database without storing anything.
+func (c *Client) CheckVulnerabilities(ctx context.Context, sbomData []byte) (*CheckResult, error) {
+	return c.c.CheckVulnerabilities(ctx, sbomData)
+}
+
+// CheckVulnerabilitiesWithOptions is CheckVulnerabilities with explicit
+// chunking and concurrency.
+func (c *Client) CheckVulnerabilitiesWithOptions(ctx context.Context, sbomData []byte, opts CheckOptions) (*CheckResult, error) {
+	return c.c.CheckVulnerabilitiesWithOptions(ctx, sbomData, opts)
+}
+
+// ListVulnerabilities returns every vulnerability of the project. Use
+// Vulnerabilities to page lazily instead.
+func (c *Client) ListVulnerabilities(ctx context.Context, projectID string) ([]VulnerabilityRecord, error) {
+	return c.c.ListVulnerabilities(ctx, projectID)
+}
+
+// RunTriage asks the server's LLM triage for a VEX draft.
+func (c *Client) RunTriage(ctx context.Context, projectID string, req TriageRunRequest) (*TriageRunResult, error) {
+	return c.c.RunTriage(ctx, projectID, req)
+}
+
+// ListVEXDrafts fetches one page of drafts as selected by
+// filter.Limit / filter.Offset.
+func (c *Client) ListVEXDrafts(ctx context.Context, projectID string, filter VEXDraftListFilter) ([]VEXDraft, error) {
+	return c.c.ListVEXDrafts(ctx, projectID, filter)
+}
+
+// ListAllVEXDrafts returns every draft matching filter, ignoring its
+// Limit / Offset. Use VEXDrafts to page lazily instead.
+func (c *Client) ListAllVEXDrafts(ctx context.Context, projectID string, filter VEXDraftListFilter) ([]VEXDraft, error) {
+	return c.c.ListAllVEXDrafts(ctx, projectID, filter)
+}
+
+// DecideDraft records a human decision on a VEX draft.
+func (c *Client) DecideDraft(ctx context.Context, projectID, draftID string, dec DecisionRequest) (*VEXDraft, error) {
+	return c.c.DecideDraft(ctx, projectID, draftID, dec)
+}
+
+// RunReport drafts a CRA report for one CVE. The server needs an
+// approved VEX draft for it first and answers 409 (a permanent *Error)
+// otherwise.
+func (c *Client) RunReport(ctx context.Context, projectID string, req CRARunReportRequest) (*CRARunReportResult, error) {
+	return c.c.RunReport(ctx, projectID, req)
+}
+
+// ListReports returns every report matching filter and the server's
+// total count. Use Reports to page lazily instead.
+func (c *Client) ListReports(ctx context.Context, projectID string, filter CRAReportListFilter) ([]CRAReport, int, error) {
+	return c.c.ListReports(ctx, projectID, filter)
+}
+
+// GetReport fetches one CRA report.
+func (c *Client) GetReport(ctx context.Context, projectID, reportID string) (*CRAReport, error) {
+	return c.c.GetReport(ctx, projectID, reportID)
+}
+
+// DecideReport approves, edits or rejects a CRA report draft.
+func (c *Client) DecideReport(ctx context.Context, projectID, reportID string, dec CRADecisionRequest) (*CRAReport, error) {
+	return c.c.DecideReport(ctx, projectID, reportID, dec)
+}
+
+// ReanalyseReport re-runs drafting for an existing report. The server
+// keeps the original and stores the result as a new report; fields left
+// empty in override reuse the source report's values.
+func (c *Client) ReanalyseReport(ctx context.Context, projectID, reportID string, override CRARunReportRequest) (*CRARunReportResult, error) {
+	return c.c.ReanalyseReport(ctx, projectID, reportID, override)
+}
+
+// GetAssessment returns every METI criterion row matching filter and the
+// server's total count. Use Assessments to page lazily instead.
+func (c *Client) GetAssessment(ctx context.Context, projectID string, filter MetiAssessmentListFilter) ([]MetiAssessment, int, error) {
+	return c.c.GetAssessment(ctx, projectID, filter)
+}
+
+// RefreshAssessment re-evaluates the automatic METI criteria; manual
+// overrides are kept.
+func (c *Client) RefreshAssessment(ctx context.Context, projectID string) (*MetiRefreshResult, error) {
+	return c.c.RefreshAssessment(ctx, projectID)
+}
+
+// OverrideCriterion sets a manual status on one METI criterion.
+func (c *Client) OverrideCriterion(ctx context.Context, projectID, criterionID string, override MetiOverrideRequest) (*MetiAssessment, error) {
+	return c.c.OverrideCriterion(ctx, projectID, criterionID, override)
+}
+
+// ClearOverrideCriterion removes a manual override, returning the
+// criterion to its automatic evaluation.
+func (c *Client) ClearOverrideCriterion(ctx context.Context, projectID, criterionID string, req MetiClearOverrideRequest) error {
+	return c.c.ClearOverrideCriterion(ctx, projectID, criterionID, req)
+}
+
+// GetImprovementActions lists the METI criteria that are not yet
+// achieved, with the server's total count.
+func (c *Client) GetImprovementActions(ctx context.Context, projectID string) ([]ImprovementAction, int, error) {
+	return c.c.GetImprovementActions(ctx, projectID)
+}
@@ -0,0 +1,110 @@
+package sbomhub
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/mockserver"
+)
+
+func TestNewClient_UserAgent(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("User-Agent"))
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	for _, c := range []*Client{
+		NewClient(server.URL, "k"),
+		NewClient(server.URL, "k", WithUserAgent("my-tool/1.0")),
+	} {
+		if _, err := c.ListProjects(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 2 || got[0] != DefaultUserAgent || got[1] != "my-tool/1.0" {
+		t.Errorf("User-Agent = %q, want [%s my-tool/1.0]", got, DefaultUserAgent)
+	}
+}
+
+// roundTripFunc counts requests on their way to the default transport.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewClient_OptionsApplyInOrder(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	shared := &http.Client{Timeout: 5 * time.Second}
+	seen := 0
+	client := NewClient(server.URL, "k",
+		WithHTTPClient(shared),
+		WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			seen++
+			return http.DefaultTransport.RoundTrip(r)
+		})),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+
+	_, err := client.ListProjects(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !apiErr.IsTransient() || !IsRetryable(err) {
+		t.Fatalf("ListProjects() = %v, want a transient *Error", err)
+	}
+	if attempts != 2 || seen != 2 {
+		t.Errorf("server saw %d attempts, transport %d; want 2 (one retry) through the custom transport", attempts, seen)
+	}
+	if shared.Transport != nil {
+		t.Error("WithTransport modified the *http.Client passed to WithHTTPClient")
+	}
+}
+
+// TestClient_AgainstMockServer drives the upload → triage → CRA flow
+// through the public surface only, the way an SDK consumer would.
+func TestClient_AgainstMockServer(t *testing.T) {
+	server := httptest.NewServer(mockserver.New(mockserver.Options{APIKey: "sbh_sdk"}))
+	defer server.Close()
+	ctx := context.Background()
+	client := NewClient(server.URL, "sbh_sdk")
+
+	sbom := []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","components":[{"name":"log4j-core","version":"2.14.1"}]}`)
+	up, err := client.UploadSBOMFrom(ctx, "sdk", false, SBOMBytes(sbom), "", UploadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vulns, err := client.Vulnerabilities(up.ProjectID).All(ctx)
+	if err != nil || len(vulns) != 1 || vulns[0].CVEID != "CVE-2021-44228" {
+		t.Fatalf("Vulnerabilities() = %+v, %v; want the log4j finding", vulns, err)
+	}
+	if _, err := client.RunTriage(ctx, up.ProjectID, TriageRunRequest{CVEID: "CVE-2021-44228"}); err != nil {
+		t.Fatal(err)
+	}
+	drafts, err := client.VEXDrafts(up.ProjectID, VEXDraftListFilter{}).All(ctx)
+	if err != nil || len(drafts) != 1 {
+		t.Fatalf("VEXDrafts() = %+v, %v", drafts, err)
+	}
+
+	_, err = client.RunReport(ctx, up.ProjectID, CRARunReportRequest{CVEID: "CVE-2021-44228", ReportType: "early_warning"})
+	if apiErr, ok := AsError(err); !ok || apiErr.StatusCode != http.StatusConflict || !apiErr.IsPermanent() {
+		t.Fatalf("RunReport() before approval = %v, want a permanent 409", err)
+	}
+	if _, err := client.DecideDraft(ctx, up.ProjectID, drafts[0].ID, DecisionRequest{Decision: "approved"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RunReport(ctx, up.ProjectID, CRARunReportRequest{CVEID: "CVE-2021-44228", ReportType: "early_warning"}); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := client.Reports(up.ProjectID, CRAReportListFilter{}).All(ctx)
+	if err != nil || len(reports) != 1 {
+		t.Errorf("Reports() = %+v, %v; want the one drafted report", reports, err)
+	}
+}
@@ -0,0 +1,39 @@
+// Package sbomhub is the Go SDK for the SBOMHub API: the same client the
+// sbomhub CLI is built on, published for tools that want to upload SBOMs,
+// check components or drive triage / CRA / METI workflows without
+// shelling out to the CLI.
+//
+// # Stability
+//
+// The package is v0: it tracks the CLI's own client, and a release may
+// still change it incompatibly. The DTOs (CRAReport, VEXDraft,
+// MetiAssessment …) are type aliases of the CLI's internal wire types, so
+// a change to one of those is a change to this package. None of that is
+// silent: testdata/api.golden lists every exported identifier, struct
+// field and method, the aliased types' included, and TestPublicAPI fails
+// in CI until it is regenerated. Every change to the surface is therefore
+// a reviewed diff, and the release notes list it.
+//
+// Error messages (Error.Error, PageLimitError, fmt.Errorf wrapping) are
+// the CLI's Japanese text and not part of the API; they may be reworded
+// in any release. Branch on the typed errors instead: see Error,
+// RequestError and AsError.
+//
+// # Usage
+//
+//	client := sbomhub.NewClient("https://sbomhub.example.com", apiKey,
+//		sbomhub.WithUserAgent("my-tool/1.0"),
+//		sbomhub.WithRetryPolicy(sbomhub.DefaultRetryPolicy))
+//
+//	it := client.Vulnerabilities(projectID)
+//	for it.Next(ctx) {
+//		v := it.Value()
+//		fmt.Println(v.CVEID, v.Severity)
+//	}
+//	if err := it.Err(); err != nil {
+//		var apiErr *sbomhub.Error
+//		if errors.As(err, &apiErr) && apiErr.IsTransient() {
+//			// try again later
+//		}
+//	}
+package sbomhub
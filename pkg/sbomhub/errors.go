@@ -0,0 +1,59 @@
+package sbomhub
+
+import (
+	"errors"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// Error is returned for any response the server answered with a
+// non-success status, or a 2xx that broke the endpoint's contract. Use
+// Kind (or the IsTransient / IsPermanent / IsAIDisabled shortcuts) to
+// decide whether to retry; the CLI derives its exit codes the same way.
+type Error = api.Error
+
+// RequestError is returned when no HTTP response arrived at all (DNS,
+// refused or reset connection, TLS failure, client timeout). It unwraps
+// to the underlying net / url error.
+type RequestError = api.RequestError
+
+// PageLimitError ends an Iterator (or a List* call) whose server kept
+// returning full pages past the page ceiling. The rows fetched before it
+// are still returned.
+type PageLimitError = api.PageLimitError
+
+// ErrorKind classifies an *Error.
+type ErrorKind = api.ErrorKind
+
+// Error kinds. New kinds may be added in minor releases; treat an
+// unknown kind like KindPermanent.
+const (
+	KindUnclassified = api.KindUnclassified
+	KindPermanent    = api.KindPermanent
+	KindTransient    = api.KindTransient
+	KindAIDisabled   = api.KindAIDisabled
+	KindProtocol     = api.KindProtocol
+)
+
+// AsError reports whether err wraps an *Error and returns it. It is
+// shorthand for errors.As for the common "what did the server say?"
+// check; errors.As on *Error or *RequestError works just as well.
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// IsRetryable reports whether err is worth retrying as-is: a transient
+// *Error (429 / 5xx) or a *RequestError, and in neither case because the
+// caller's context ended. The client already retries these according to
+// its RetryPolicy, so seeing one means the retry budget ran out.
+func IsRetryable(err error) bool {
+	if e, ok := AsError(err); ok {
+		return e.IsRetryable()
+	}
+	var re *RequestError
+	return errors.As(err, &re) && re.IsRetryable()
+}
@@ -0,0 +1,97 @@
+package sbomhub
+
+import (
+	"context"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// Iterator walks a paginated list endpoint one page at a time, so a
+// caller streaming a large project never holds more than one page in
+// memory. It is not safe for concurrent use.
+//
+//	it := client.Reports(projectID, sbomhub.CRAReportListFilter{State: "draft"})
+//	for it.Next(ctx) {
+//		r := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// A server that keeps returning full pages past a generous ceiling ends
+// the walk with *PageLimitError rather than looping forever.
+type Iterator[T any] struct {
+	p *api.Paginator[T]
+}
+
+// Next advances to the next item, fetching a page when the current one
+// is exhausted. It returns false at the end of the list or on error;
+// check Err to tell the two apart. ctx bounds each page request, so a
+// cancelled ctx stops the iteration with ctx's error.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	return it.p.Next(ctx)
+}
+
+// Value returns the item Next just advanced to.
+func (it *Iterator[T]) Value() T {
+	return it.p.Value()
+}
+
+// NextPage fetches the next whole page, for callers that render or
+// batch page by page. Do not mix it with Next on the same iterator
+// unless the current page has been fully consumed.
+func (it *Iterator[T]) NextPage(ctx context.Context) ([]T, bool) {
+	return it.p.NextPage(ctx)
+}
+
+// Err returns the error that stopped the iteration, or nil if it ran to
+// the end (or has not finished yet).
+func (it *Iterator[T]) Err() error {
+	return it.p.Err()
+}
+
+// Total returns the size of the whole result as last reported by the
+// server, or 0 when the endpoint does not publish one.
+func (it *Iterator[T]) Total() int {
+	return it.p.Total()
+}
+
+// All drains the remaining items into a slice. On error it returns what
+// was fetched so far together with the error.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	return it.p.All(ctx)
+}
+
+// Projects iterates over the projects visible to the API key.
+func (c *Client) Projects() *Iterator[Project] {
+	return &Iterator[Project]{p: c.c.PaginateProjects()}
+}
+
+// Vulnerabilities iterates over the project's vulnerabilities.
+func (c *Client) Vulnerabilities(projectID string) *Iterator[VulnerabilityRecord] {
+	return &Iterator[VulnerabilityRecord]{p: c.c.PaginateVulnerabilities(projectID)}
+}
+
+// SBOMs iterates over the project's stored SBOMs, newest first.
+func (c *Client) SBOMs(projectID string) *Iterator[SBOM] {
+	return &Iterator[SBOM]{p: c.c.PaginateSBOMs(projectID)}
+}
+
+// VEXDrafts iterates over the project's VEX drafts matching filter;
+// filter.Limit and filter.Offset are ignored.
+func (c *Client) VEXDrafts(projectID string, filter VEXDraftListFilter) *Iterator[VEXDraft] {
+	return &Iterator[VEXDraft]{p: c.c.PaginateVEXDrafts(projectID, filter)}
+}
+
+// Reports iterates over the project's CRA reports matching filter;
+// filter.Limit and filter.Offset are ignored.
+func (c *Client) Reports(projectID string, filter CRAReportListFilter) *Iterator[CRAReport] {
+	return &Iterator[CRAReport]{p: c.c.PaginateReports(projectID, filter)}
+}
+
+// Assessments iterates over the project's METI criterion rows matching
+// filter.
+func (c *Client) Assessments(projectID string, filter MetiAssessmentListFilter) *Iterator[MetiAssessment] {
+	return &Iterator[MetiAssessment]{p: c.c.PaginateAssessment(projectID, filter)}
+}
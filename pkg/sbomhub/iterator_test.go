@@ -0,0 +1,114 @@
+package sbomhub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pagedServer serves n rows of path in offset / limit pages, recording
+// the offsets it was asked for.
+func pagedServer(t *testing.T, n int, row func(i int) any, envelope string, withTotal bool) (*httptest.Server, *[]int) {
+	t.Helper()
+	var offsets []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, offset)
+		rows := []any{}
+		for i := offset; i < n && i < offset+limit; i++ {
+			rows = append(rows, row(i))
+		}
+		if withTotal {
+			w.Header().Set("X-Total-Count", strconv.Itoa(n))
+		}
+		var body any = rows
+		if envelope != "" {
+			body = map[string]any{envelope: rows}
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &offsets
+}
+
+func TestVulnerabilities_PagesUntilShortPage(t *testing.T) {
+	server, offsets := pagedServer(t, 1050, func(i int) any {
+		return map[string]any{"id": fmt.Sprint(i), "cve_id": fmt.Sprintf("CVE-2024-%05d", i)}
+	}, "", false)
+	client := NewClient(server.URL, "k")
+
+	it := client.Vulnerabilities("p1")
+	n := 0
+	for it.Next(context.Background()) {
+		if got := it.Value().ID; got != fmt.Sprint(n) {
+			t.Fatalf("item %d has ID %q", n, got)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1050 || fmt.Sprint(*offsets) != "[0 500 1000]" {
+		t.Errorf("got %d items from offsets %v, want 1050 from [0 500 1000]", n, *offsets)
+	}
+}
+
+func TestReports_StopsAtTotalCount(t *testing.T) {
+	server, offsets := pagedServer(t, 500, func(i int) any {
+		return map[string]any{"id": fmt.Sprint(i)}
+	}, "reports", true)
+	client := NewClient(server.URL, "k")
+
+	all, err := client.Reports("p1", CRAReportListFilter{State: "draft"}).All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 500 || len(*offsets) != 1 {
+		t.Errorf("got %d reports in %d requests, want 500 in one (X-Total-Count ends the walk)", len(all), len(*offsets))
+	}
+}
+
+func TestVEXDrafts_OverridesFilterPaging(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(`{"drafts":[{"id":"d1"}]}`))
+	}))
+	defer server.Close()
+
+	all, err := NewClient(server.URL, "k").
+		VEXDrafts("p1", VEXDraftListFilter{CVEID: "CVE-2021-44228", Limit: 3, Offset: 7}).
+		All(context.Background())
+	if err != nil || len(all) != 1 {
+		t.Fatalf("All() = %v, %v", all, err)
+	}
+	if len(queries) != 1 || !strings.Contains(queries[0], "limit=100") || strings.Contains(queries[0], "offset=7") {
+		t.Errorf("queries = %q, want one page at the iterator's own limit / offset", queries)
+	}
+}
+
+func TestIterator_StopsOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"forbidden"}`))
+	}))
+	defer server.Close()
+
+	it := NewClient(server.URL, "k").Assessments("p1", MetiAssessmentListFilter{})
+	if it.Next(context.Background()) {
+		t.Fatal("Next() = true on a 403")
+	}
+	apiErr, ok := AsError(it.Err())
+	if !ok || !apiErr.IsPermanent() || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Err() = %v, want a permanent *Error for the 403", it.Err())
+	}
+	if it.Next(context.Background()) {
+		t.Error("Next() after an error should keep returning false")
+	}
+}
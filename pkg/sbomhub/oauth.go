@@ -0,0 +1,87 @@
+package sbomhub
+
+import (
+	"context"
+
+	"github.com/youichi-uda/sbomhub-cli/internal/api"
+)
+
+// Device login. Instead of a long-lived API key, a client can hold an
+// OAuth access token obtained with the device authorization grant
+// (RFC 8628) and renew it with its refresh token:
+//
+//	c := sbomhub.NewClient(url, "")
+//	ep, _ := c.DiscoverOAuth(ctx, "")
+//	da, _ := c.StartDeviceAuthorization(ctx, ep, sbomhub.DefaultOAuthClientID, "")
+//	fmt.Println("open", da.VerificationURI, "and enter", da.UserCode)
+//	tok, _ := c.PollDeviceToken(ctx, ep, sbomhub.DefaultOAuthClientID, da)
+//
+//	c = sbomhub.NewClient(url, "", sbomhub.WithOAuthToken(ep.TokenEndpoint,
+//		sbomhub.DefaultOAuthClientID, *tok, save))
+
+type (
+	// OAuthEndpoints is the authorization server metadata the device
+	// flow needs.
+	OAuthEndpoints = api.OAuthEndpoints
+	// DeviceAuthorization is the code the operator confirms in a browser.
+	DeviceAuthorization = api.DeviceAuthorization
+	// Token is an access token with its refresh token and expiry.
+	Token = api.Token
+	// OAuthError is an error response from an OAuth endpoint; Code holds
+	// the RFC 6749 error code (invalid_grant, access_denied, ...).
+	OAuthError = api.OAuthError
+	// TokenSource supplies bearer tokens; see WithTokenSource.
+	TokenSource = api.TokenSource
+)
+
+// DefaultOAuthClientID is the public OAuth client the CLI uses.
+const DefaultOAuthClientID = api.DefaultOAuthClientID
+
+// WithTokenSource authenticates with tokens from ts instead of the API
+// key. After a 401 the client asks ts for a fresh token and replays the
+// request once before returning the error.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) { c.c.SetTokenSource(ts) }
+}
+
+// WithOAuthToken authenticates with tok, refreshing it through tokenURL
+// when it expires or is rejected. onRefresh, when non-nil, receives each
+// new token so it can be stored for the next run.
+func WithOAuthToken(tokenURL, clientID string, tok Token, onRefresh func(Token)) Option {
+	return func(c *Client) {
+		c.c.SetTokenSource(api.NewOAuthTokenSource(c.c, tokenURL, clientID, tok, onRefresh))
+	}
+}
+
+// DiscoverOAuth reads the authorization server metadata of issuer, or of
+// the SBOMHub server when issuer is empty.
+func (c *Client) DiscoverOAuth(ctx context.Context, issuer string) (*OAuthEndpoints, error) {
+	return c.c.DiscoverOAuth(ctx, issuer)
+}
+
+// StartDeviceAuthorization requests a device code and the user code to
+// show the operator.
+func (c *Client) StartDeviceAuthorization(ctx context.Context, ep *OAuthEndpoints, clientID, scope string) (*DeviceAuthorization, error) {
+	return c.c.StartDeviceAuthorization(ctx, ep, clientID, scope)
+}
+
+// PollDeviceToken waits for the operator to approve da and returns the
+// issued token.
+func (c *Client) PollDeviceToken(ctx context.Context, ep *OAuthEndpoints, clientID string, da *DeviceAuthorization) (*Token, error) {
+	return c.c.PollDeviceToken(ctx, ep, clientID, da)
+}
+
+// RefreshOAuthToken exchanges a refresh token for a new access token.
+func (c *Client) RefreshOAuthToken(ctx context.Context, tokenURL, clientID, refreshToken string) (*Token, error) {
+	return c.c.RefreshOAuthToken(ctx, tokenURL, clientID, refreshToken)
+}
+
+// JWTTokenType is the subject token type of a CI identity token.
+const JWTTokenType = api.JWTTokenType
+
+// ExchangeToken trades a CI job's identity token for a short-lived
+// SBOMHub access token (RFC 8693 token exchange), so the job needs no
+// stored API key. scope may be empty.
+func (c *Client) ExchangeToken(ctx context.Context, tokenURL, clientID, subjectToken, subjectTokenType, scope string) (*Token, error) {
+	return c.c.ExchangeToken(ctx, tokenURL, clientID, subjectToken, subjectTokenType, scope)
+}
@@ -0,0 +1,116 @@
+package sbomhub
+
+import "github.com/youichi-uda/sbomhub-cli/internal/api"
+
+// Request and response types. These are aliases rather than copies so
+// that values flow between the SDK and the CLI's internal client without
+// conversion; see the package documentation for what that means for
+// compatibility.
+type (
+	// Projects and uploads.
+	Project               = api.Project
+	ProjectsListResponse  = api.ProjectsListResponse
+	CreateProjectRequest  = api.CreateProjectRequest
+	CreateProjectResponse = api.CreateProjectResponse
+	UpdateProjectRequest  = api.UpdateProjectRequest
+	ProjectRefError       = api.ProjectRefError
+	UploadResult          = api.UploadResult
+	UploadOptions         = api.UploadOptions
+	SBOMSource            = api.SBOMSource
+	ScanStatusResponse    = api.ScanStatusResponse
+	VulnerabilitySummary  = api.VulnerabilitySummary
+	SBOM                  = api.SBOM
+	SBOMsListResponse     = api.SBOMsListResponse
+
+	// Component check.
+	CheckOptions                = api.CheckOptions
+	CheckResult                 = api.CheckResult
+	CheckVulnerabilitiesRequest = api.CheckVulnerabilitiesRequest
+	ComponentInput              = api.ComponentInput
+	VulnerabilityItem           = api.VulnerabilityItem
+	VulnerabilityRecord         = api.VulnerabilityRecord
+
+	// Triage and VEX drafts.
+	TriageRunRequest   = api.TriageRunRequest
+	TriageRunResult    = api.TriageRunResult
+	TriageEvidence     = api.TriageEvidence
+	ParsedDecision     = api.ParsedDecision
+	VEXDraft           = api.VEXDraft
+	VEXDraftListFilter = api.VEXDraftListFilter
+	DecisionRequest    = api.DecisionRequest
+
+	// CRA reports.
+	CRAReport           = api.CRAReport
+	CRAReportListFilter = api.CRAReportListFilter
+	CRARunReportRequest = api.CRARunReportRequest
+	CRARunReportResult  = api.CRARunReportResult
+	CRADecisionRequest  = api.CRADecisionRequest
+
+	// METI assessment.
+	MetiAssessment           = api.MetiAssessment
+	MetiAssessmentListFilter = api.MetiAssessmentListFilter
+	MetiOverrideRequest      = api.MetiOverrideRequest
+	MetiClearOverrideRequest = api.MetiClearOverrideRequest
+	MetiRefreshResult        = api.MetiRefreshResult
+	ImprovementAction        = api.ImprovementAction
+
+	// Server metadata.
+	Capabilities      = api.Capabilities
+	LLMHealthResponse = api.LLMHealthResponse
+	Identity          = api.Identity
+)
+
+// Feature names a server may advertise in Capabilities.Features. Use
+// Capabilities.Lacks to gate calls a given server cannot serve;
+// FeatureIdempotencyKey and the other opt-in features only take effect
+// once declared (Capabilities.Declares, Client.SetCapabilities).
+const (
+	FeatureSBOMUpload   = api.FeatureSBOMUpload
+	FeatureScanStatus   = api.FeatureScanStatus
+	FeatureGzipUpload   = api.FeatureGzipUpload
+	FeatureLLMHealth    = api.FeatureLLMHealth
+	FeatureLegacyUpload = api.FeatureLegacyUpload
+	FeatureVEX          = api.FeatureVEX
+	FeatureTriage       = api.FeatureTriage
+	FeatureCRA          = api.FeatureCRA
+	FeatureMETI         = api.FeatureMETI
+
+	FeatureIdempotencyKey   = api.FeatureIdempotencyKey
+	FeatureCursorPagination = api.FeatureCursorPagination
+	FeatureWhoami           = api.FeatureWhoami
+	FeatureTokenExchange    = api.FeatureTokenExchange
+)
+
+// Scopes write operations need; check them with Identity.Can.
+const (
+	ScopeTriageWrite   = api.ScopeTriageWrite
+	ScopeCRAWrite      = api.ScopeCRAWrite
+	ScopeMETIWrite     = api.ScopeMETIWrite
+	ScopeProjectsWrite = api.ScopeProjectsWrite
+	ScopeSBOMWrite     = api.ScopeSBOMWrite
+)
+
+// Defaults for CheckOptions; a zero field in CheckOptions means "use the
+// default".
+const (
+	DefaultCheckChunkSize   = api.DefaultCheckChunkSize
+	DefaultCheckConcurrency = api.DefaultCheckConcurrency
+	DefaultCheckMaxRetries  = api.DefaultCheckMaxRetries
+)
+
+// SBOMBytes wraps an in-memory SBOM for UploadSBOMFrom.
+func SBOMBytes(data []byte) SBOMSource {
+	return api.SBOMBytes(data)
+}
+
+// SBOMFile opens path as an upload source that is streamed from disk
+// (and re-opened on retry) instead of being read into memory.
+func SBOMFile(path string) (SBOMSource, error) {
+	return api.SBOMFile(path)
+}
+
+// ExtractComponents parses a CycloneDX or SPDX JSON document into the
+// component list CheckVulnerabilities sends.
+func ExtractComponents(sbomData []byte) ([]ComponentInput, error) {
+	return api.ExtractComponents(sbomData)
+}